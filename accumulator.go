@@ -74,6 +74,17 @@ type DeliveryInfo interface {
 	Delivered() bool
 }
 
+// OutputDeliveryInfo provides the results of a delivered metric group per
+// output in addition to the overall result.
+type OutputDeliveryInfo interface {
+	DeliveryInfo
+
+	// Outputs returns the delivery result per output name or alias for all
+	// outputs handling the metrics. The result is false if the output
+	// rejected any of the metrics of the group.
+	Outputs() map[string]bool
+}
+
 // TrackingAccumulator is an Accumulator that provides a signal when the
 // metric has been fully processed.  Sending more metrics than the accumulator
 // has been allocated for without reading status from the Accepted or Rejected
@@ -13,6 +13,12 @@ type MetricMaker interface {
 	Log() telegraf.Logger
 }
 
+// deliveryRequirer is implemented by metric makers restricting the delivery
+// status of tracking metrics to a set of outputs
+type deliveryRequirer interface {
+	DeliveryOutputs() []string
+}
+
 type accumulator struct {
 	maker     MetricMaker
 	metrics   chan<- telegraf.Metric
@@ -120,25 +126,36 @@ func (ac *accumulator) getTime(t []time.Time) time.Time {
 }
 
 func (ac *accumulator) WithTracking(maxTracked int) telegraf.TrackingAccumulator {
+	var required []string
+	if r, ok := ac.maker.(deliveryRequirer); ok {
+		required = r.DeliveryOutputs()
+	}
+
 	return &trackingAccumulator{
 		Accumulator: ac,
 		delivered:   make(chan telegraf.DeliveryInfo, maxTracked),
+		required:    required,
 	}
 }
 
 type trackingAccumulator struct {
 	telegraf.Accumulator
 	delivered chan telegraf.DeliveryInfo
+	required  []string
 }
 
 func (a *trackingAccumulator) AddTrackingMetric(m telegraf.Metric) telegraf.TrackingID {
 	dm, id := metric.WithTracking(m, a.onDelivery)
+	metric.RequireOutputs(dm, a.required)
 	a.AddMetric(dm)
 	return id
 }
 
 func (a *trackingAccumulator) AddTrackingMetricGroup(group []telegraf.Metric) telegraf.TrackingID {
 	db, id := metric.WithGroupTracking(group, a.onDelivery)
+	if len(db) > 0 {
+		metric.RequireOutputs(db[0], a.required)
+	}
 	for _, m := range db {
 		a.AddMetric(m)
 	}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -140,7 +141,36 @@ func TestAddTrackingMetricGroupEmpty(t *testing.T) {
 	}
 }
 
+func TestAddTrackingMetricRequiredOutputs(t *testing.T) {
+	ch := make(chan telegraf.Metric, 10)
+	maker := &TestMetricMaker{deliveryOutputs: []string{"archive"}}
+	acc := NewAccumulator(maker, ch).WithTracking(1)
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	id := acc.AddTrackingMetric(m)
+
+	tm := <-ch
+	m2 := tm.Copy()
+	metric.AssignOutput(tm, "archive")
+	metric.AssignOutput(m2, "influxdb")
+	tm.Accept()
+	m2.Reject()
+
+	select {
+	case tracking := <-acc.Delivered():
+		require.Equal(t, id, tracking.ID())
+		require.True(t, tracking.Delivered())
+	default:
+		t.Fatal("metric should be delivered")
+	}
+}
+
 type TestMetricMaker struct {
+	deliveryOutputs []string
+}
+
+func (tm *TestMetricMaker) DeliveryOutputs() []string {
+	return tm.deliveryOutputs
 }
 
 func (*TestMetricMaker) Name() string {
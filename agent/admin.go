@@ -0,0 +1,228 @@
+package agent
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+)
+
+// adminServer serves the debugging facilities of the agent such as profiling
+// data, expvars, the output buffer statistics and the list of plugins.
+type adminServer struct {
+	cfg    *config.Config
+	health *healthServer
+
+	address   string
+	tlsConfig *tls.Config
+	server    *http.Server
+}
+
+type bufferStatus struct {
+	Name            string     `json:"name"`
+	ID              string     `json:"id"`
+	Buffered        int        `json:"buffered"`
+	Limit           int        `json:"limit"`
+	Strategy        string     `json:"strategy"`
+	LastSuccess     *time.Time `json:"last_success,omitempty"`
+	LastWriteFailed bool       `json:"last_write_failed"`
+}
+
+type pluginStatus struct {
+	Type          string `json:"type"`
+	Name          string `json:"name"`
+	Alias         string `json:"alias,omitempty"`
+	ID            string `json:"id"`
+	State         string `json:"state"`
+	StartupErrors int64  `json:"startup_errors,omitempty"`
+}
+
+func newAdminServer(cfg *config.Config, health *healthServer) (*adminServer, error) {
+	agentCfg := cfg.Agent
+
+	tlsServerCfg := &common_tls.ServerConfig{
+		TLSCert:           agentCfg.AdminTLSCert,
+		TLSKey:            agentCfg.AdminTLSKey,
+		TLSAllowedCACerts: agentCfg.AdminTLSAllowedCACerts,
+	}
+	tlsConfig, err := tlsServerCfg.TLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("creating TLS config failed: %w", err)
+	}
+
+	// Only allow to serve the endpoints on non-loopback interfaces if the
+	// clients are authenticated as the endpoints might expose sensitive data.
+	authenticated := agentCfg.AdminUsername != "" || len(agentCfg.AdminTLSAllowedCACerts) > 0
+	if !authenticated {
+		loopback, err := isLoopbackAddress(agentCfg.AdminAddress)
+		if err != nil {
+			return nil, err
+		}
+		if !loopback {
+			return nil, fmt.Errorf("refusing to serve on non-loopback address %q without authentication", agentCfg.AdminAddress)
+		}
+	}
+
+	a := &adminServer{
+		cfg:       cfg,
+		health:    health,
+		address:   agentCfg.AdminAddress,
+		tlsConfig: tlsConfig,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/buffers", a.serveBuffers)
+	mux.HandleFunc("/plugins", a.servePlugins)
+	if health != nil {
+		mux.HandleFunc("/healthz", health.serveHealth)
+		mux.HandleFunc("/readyz", health.serveReady)
+	}
+
+	authHandler := internal.BasicAuthHandler(agentCfg.AdminUsername, agentCfg.AdminPassword, "telegraf", func(_ http.ResponseWriter) {
+		log.Printf("W! [agent] Unauthorized request to admin endpoints")
+	})
+	a.server = &http.Server{
+		Handler:           authHandler(mux),
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return a, nil
+}
+
+func (a *adminServer) start() error {
+	listener, err := net.Listen("tcp", a.address)
+	if err != nil {
+		return err
+	}
+	if a.tlsConfig != nil {
+		listener = tls.NewListener(listener, a.tlsConfig)
+	}
+
+	go func() {
+		if err := a.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("E! [agent] Serving admin endpoints failed: %v", err)
+		}
+	}()
+	log.Printf("I! [agent] Serving admin endpoints on %s", listener.Addr())
+
+	return nil
+}
+
+func (a *adminServer) stop() {
+	if err := a.server.Close(); err != nil {
+		log.Printf("E! [agent] Closing admin server failed: %v", err)
+	}
+}
+
+func (a *adminServer) serveBuffers(w http.ResponseWriter, _ *http.Request) {
+	buffers := make([]bufferStatus, 0, len(a.cfg.Outputs))
+	for _, output := range a.cfg.Outputs {
+		status := bufferStatus{
+			Name:            output.LogName(),
+			ID:              output.ID(),
+			Buffered:        output.BufferLength(),
+			Limit:           output.MetricBufferLimit,
+			Strategy:        output.Config.BufferStrategy,
+			LastWriteFailed: output.LastWriteFailed(),
+		}
+		if last := output.LastSuccess(); !last.IsZero() {
+			status.LastSuccess = &last
+		}
+		buffers = append(buffers, status)
+	}
+	writeAdminResponse(w, buffers)
+}
+
+func (a *adminServer) servePlugins(w http.ResponseWriter, _ *http.Request) {
+	plugins := make([]pluginStatus, 0, len(a.cfg.Inputs)+len(a.cfg.Processors)+len(a.cfg.Aggregators)+len(a.cfg.Outputs))
+	for _, input := range a.cfg.Inputs {
+		state := "running"
+		if !input.Started() {
+			state = "not started"
+		}
+		plugins = append(plugins, pluginStatus{
+			Type:          "input",
+			Name:          input.Config.Name,
+			Alias:         input.Config.Alias,
+			ID:            input.ID(),
+			State:         state,
+			StartupErrors: input.StartupErrors.Get(),
+		})
+	}
+	for _, processor := range a.cfg.Processors {
+		plugins = append(plugins, pluginStatus{
+			Type:  "processor",
+			Name:  processor.Config.Name,
+			Alias: processor.Config.Alias,
+			ID:    processor.ID(),
+			State: "running",
+		})
+	}
+	for _, aggregator := range a.cfg.Aggregators {
+		plugins = append(plugins, pluginStatus{
+			Type:  "aggregator",
+			Name:  aggregator.Config.Name,
+			Alias: aggregator.Config.Alias,
+			ID:    aggregator.ID(),
+			State: "running",
+		})
+	}
+	for _, output := range a.cfg.Outputs {
+		var state string
+		switch {
+		case output.LastSuccess().IsZero():
+			state = "not connected"
+		case output.LastWriteFailed():
+			state = "write failed"
+		default:
+			state = "running"
+		}
+		plugins = append(plugins, pluginStatus{
+			Type:          "output",
+			Name:          output.Config.Name,
+			Alias:         output.Config.Alias,
+			ID:            output.ID(),
+			State:         state,
+			StartupErrors: output.StartupErrors.Get(),
+		})
+	}
+	writeAdminResponse(w, plugins)
+}
+
+func writeAdminResponse(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("E! [agent] Writing admin response failed: %v", err)
+	}
+}
+
+// isLoopbackAddress checks if the given address only binds to loopback
+// interfaces. An empty host binds to all interfaces.
+func isLoopbackAddress(address string) (bool, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false, fmt.Errorf("invalid address %q: %w", address, err)
+	}
+	if host == "localhost" {
+		return true, nil
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback(), nil
+}
@@ -0,0 +1,160 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type adminInput struct{}
+
+func (*adminInput) SampleConfig() string {
+	return ""
+}
+
+func (*adminInput) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func TestAdminAddressRequiresAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  string
+		username string
+		expected string
+	}{
+		{
+			name:    "localhost",
+			address: "localhost:0",
+		},
+		{
+			name:    "loopback",
+			address: "127.0.0.1:0",
+		},
+		{
+			name:     "all interfaces",
+			address:  ":0",
+			expected: `refusing to serve on non-loopback address ":0" without authentication`,
+		},
+		{
+			name:     "public address",
+			address:  "192.0.2.1:6060",
+			expected: `refusing to serve on non-loopback address "192.0.2.1:6060" without authentication`,
+		},
+		{
+			name:     "all interfaces with authentication",
+			address:  ":0",
+			username: "admin",
+		},
+		{
+			name:     "invalid address",
+			address:  "localhost",
+			expected: `invalid address "localhost"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewConfig()
+			cfg.Agent.AdminAddress = tt.address
+			cfg.Agent.AdminUsername = tt.username
+			cfg.Agent.AdminPassword = "secret"
+
+			_, err := newAdminServer(cfg, nil)
+			if tt.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAdminEndpoints(t *testing.T) {
+	output := models.NewRunningOutput(&healthOutput{}, &models.OutputConfig{Name: "test", ID: "out"}, 1000, 10000)
+	require.NoError(t, output.Init())
+	require.NoError(t, output.Connect())
+	output.AddMetric(testutil.TestMetric(42.0))
+
+	input := models.NewRunningInput(&adminInput{}, &models.InputConfig{Name: "test", Alias: "in", ID: "in"})
+
+	cfg := config.NewConfig()
+	cfg.Agent.AdminAddress = "localhost:0"
+	cfg.Agent.AdminUsername = "admin"
+	cfg.Agent.AdminPassword = "secret"
+	cfg.Inputs = append(cfg.Inputs, input)
+	cfg.Outputs = append(cfg.Outputs, output)
+
+	admin, err := newAdminServer(cfg, nil)
+	require.NoError(t, err)
+
+	// All endpoints require authentication
+	for _, path := range []string{"/plugins", "/buffers", "/debug/vars", "/debug/pprof/"} {
+		rec := httptest.NewRecorder()
+		admin.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusUnauthorized, rec.Code, path)
+	}
+
+	request := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.SetBasicAuth("admin", "secret")
+		rec := httptest.NewRecorder()
+		admin.server.Handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request("/debug/vars")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "memstats")
+
+	rec = request("/debug/pprof/")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// Health endpoints are only served if a health server is available
+	rec = request("/healthz")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = request("/plugins")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var plugins []pluginStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &plugins))
+	require.Equal(t, []pluginStatus{
+		{Type: "input", Name: "test", Alias: "in", ID: "in", State: "running"},
+		{Type: "output", Name: "test", ID: "out", State: "running"},
+	}, plugins)
+
+	rec = request("/buffers")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var buffers []bufferStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &buffers))
+	require.Len(t, buffers, 1)
+	require.Equal(t, "outputs.test", buffers[0].Name)
+	require.Equal(t, 1, buffers[0].Buffered)
+	require.Equal(t, 10000, buffers[0].Limit)
+	require.NotNil(t, buffers[0].LastSuccess)
+	require.False(t, buffers[0].LastWriteFailed)
+}
+
+func TestAdminHealthEndpoints(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Agent.AdminAddress = "localhost:0"
+
+	health := newHealthServer(nil, 0, 3)
+	health.ready.Store(true)
+	admin, err := newAdminServer(cfg, health)
+	require.NoError(t, err)
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		rec := httptest.NewRecorder()
+		admin.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, rec.Code, path)
+	}
+}
@@ -15,15 +15,22 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/handoff"
 	"github.com/influxdata/telegraf/internal/snmp"
 	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/processors"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 )
 
+// Interval between write attempts when draining the outputs on shutdown
+var drainRetryInterval = time.Second
+
 // Agent runs a set of plugins.
 type Agent struct {
 	Config *config.Config
+
+	handoff  *handoff.Server
+	shutdown shutdownState
 }
 
 // NewAgent returns an Agent for the given Config.
@@ -165,18 +172,87 @@ func (a *Agent) Run(ctx context.Context) error {
 		}
 	}
 
+	// Take over the listeners of a running predecessor right before starting
+	// the inputs as the predecessor shuts down once the handoff happened.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var predecessor *handoff.Predecessor
+	if a.Config.Agent.HandoffSocket != "" {
+		predecessor, err = handoff.Connect(a.Config.Agent.HandoffSocket)
+		if err != nil {
+			log.Printf("E! [agent] Taking over from predecessor failed: %v", err)
+		} else if predecessor != nil {
+			log.Printf("I! [agent] Received %d listeners from predecessor", handoff.Inherited())
+		}
+	}
+
 	iu, err := a.startInputs(next, a.Config.Inputs)
 	if err != nil {
+		if predecessor != nil {
+			predecessor.Close()
+		}
 		return err
 	}
 
+	var health *healthServer
+	if a.Config.Agent.HealthServiceAddress != "" || a.Config.Agent.AdminAddress != "" {
+		health = newHealthServer(ou.outputs, time.Duration(a.Config.Agent.FlushInterval), a.Config.Agent.HealthMaxMissedFlushes)
+		health.ready.Store(true)
+	}
+	if a.Config.Agent.HealthServiceAddress != "" {
+		if err := health.start(a.Config.Agent.HealthServiceAddress); err != nil {
+			log.Printf("E! [agent] Starting health server failed: %v", err)
+		}
+	}
+
+	var admin *adminServer
+	if a.Config.Agent.AdminAddress != "" {
+		admin, err = newAdminServer(a.Config, health)
+		if err == nil {
+			err = admin.start()
+		}
+		if err != nil {
+			log.Printf("E! [agent] Starting admin server failed: %v", err)
+			admin = nil
+		}
+	}
+
 	var wg sync.WaitGroup
+	if a.Config.Agent.HandoffSocket != "" {
+		handoff.ReleaseInherited()
+
+		if predecessor != nil {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				a.receiveMetrics(ctx, predecessor, ou.outputs)
+			}()
+		}
+
+		a.handoff, err = handoff.NewServer(a.Config.Agent.HandoffSocket, func() {
+			log.Println("I! [agent] Handed over listeners to successor, shutting down")
+			cancel()
+		})
+		if err != nil {
+			log.Printf("E! [agent] Serving handoff failed: %v", err)
+		}
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		a.runOutputs(ou)
 	}()
 
+	if a.Config.Agent.SecretRotationInterval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.watchSecrets(ctx, time.Duration(a.Config.Agent.SecretRotationInterval))
+		}()
+	}
+
 	if au != nil {
 		wg.Add(1)
 		go func() {
@@ -203,10 +279,27 @@ func (a *Agent) Run(ctx context.Context) error {
 	go func() {
 		defer wg.Done()
 		a.runInputs(ctx, startTime, iu)
+		if health != nil {
+			health.ready.Store(false)
+		}
 	}()
 
 	wg.Wait()
 
+	if admin != nil {
+		admin.stop()
+	}
+	if health != nil && a.Config.Agent.HealthServiceAddress != "" {
+		health.stop()
+	}
+
+	if a.handoff != nil {
+		if err := a.handoff.Close(); err != nil {
+			log.Printf("E! [agent] Closing handoff failed: %v", err)
+		}
+		a.handoff = nil
+	}
+
 	if a.Config.Persister != nil {
 		log.Printf("D! [agent] Persisting plugin states")
 		if err := a.Config.Persister.Store(); err != nil {
@@ -218,6 +311,22 @@ func (a *Agent) Run(ctx context.Context) error {
 	return err
 }
 
+// watchSecrets periodically checks the secrets referenced by plugins for
+// rotation until the context is cancelled.
+func (a *Agent) watchSecrets(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.Config.CheckSecretRotation()
+		}
+	}
+}
+
 // InitPlugins runs the Init function on plugins.
 func (a *Agent) InitPlugins() error {
 	for _, input := range a.Config.Inputs {
@@ -229,6 +338,16 @@ func (a *Agent) InitPlugins() error {
 		if err != nil {
 			return fmt.Errorf("could not initialize input %s: %w", input.LogName(), err)
 		}
+		for _, required := range input.Config.DeliveryOutputs {
+			if !a.hasOutput(required) {
+				return fmt.Errorf("input %s requires delivery by unknown output %q", input.LogName(), required)
+			}
+		}
+		if pool := input.Config.WorkerPool; pool != "" {
+			if _, found := a.Config.Agent.WorkerPools[pool]; !found {
+				return fmt.Errorf("input %s uses unknown worker pool %q", input.LogName(), pool)
+			}
+		}
 	}
 	for _, processor := range a.Config.Processors {
 		err := processor.Init()
@@ -259,12 +378,26 @@ func (a *Agent) InitPlugins() error {
 	return nil
 }
 
+// hasOutput checks if an output with the given alias or name is configured.
+func (a *Agent) hasOutput(name string) bool {
+	for _, output := range a.Config.Outputs {
+		if output.Config.Alias == name || (output.Config.Alias == "" && output.Config.Name == name) {
+			return true
+		}
+	}
+	return false
+}
+
 // initPersister initializes the persister and registers the plugins.
 func (a *Agent) initPersister() error {
 	if err := a.Config.Persister.Init(); err != nil {
 		return err
 	}
 
+	if err := a.Config.Persister.Register("agent.shutdown", &a.shutdown); err != nil {
+		return fmt.Errorf("could not register shutdown report: %w", err)
+	}
+
 	for _, input := range a.Config.Inputs {
 		plugin, ok := input.Input.(telegraf.StatefulPlugin)
 		if !ok {
@@ -399,7 +532,18 @@ func (a *Agent) runInputs(
 ) {
 	var wg sync.WaitGroup
 	tickers := make([]Ticker, 0, len(unit.inputs))
+
+	// Limit the concurrent gathers of inputs in a worker pool
+	workers := make(map[string]chan struct{}, len(a.Config.Agent.WorkerPools))
+	for name, pool := range a.Config.Agent.WorkerPools {
+		if pool.Workers > 0 {
+			workers[name] = make(chan struct{}, pool.Workers)
+		}
+	}
+
 	for _, input := range unit.inputs {
+		pool := a.Config.Agent.WorkerPools[input.Config.WorkerPool]
+
 		// Overwrite agent interval if this plugin has its own.
 		interval := time.Duration(a.Config.Agent.Interval)
 		if input.Config.Interval != 0 {
@@ -412,20 +556,34 @@ func (a *Agent) runInputs(
 			precision = input.Config.Precision
 		}
 
-		// Overwrite agent collection_jitter if this plugin has its own.
+		// Overwrite agent collection_jitter if the worker pool or this plugin
+		// has its own.
 		jitter := time.Duration(a.Config.Agent.CollectionJitter)
+		if pool.CollectionJitter != 0 {
+			jitter = time.Duration(pool.CollectionJitter)
+		}
 		if input.Config.CollectionJitter != 0 {
 			jitter = input.Config.CollectionJitter
 		}
 
-		// Overwrite agent collection_offset if this plugin has its own.
+		// Overwrite agent collection_offset if the worker pool or this plugin
+		// has its own.
 		offset := time.Duration(a.Config.Agent.CollectionOffset)
+		if pool.CollectionOffset != 0 {
+			offset = time.Duration(pool.CollectionOffset)
+		}
 		if input.Config.CollectionOffset != 0 {
 			offset = input.Config.CollectionOffset
 		}
 
+		// Overwrite agent round_interval if the worker pool has its own.
+		roundInterval := a.Config.Agent.RoundInterval
+		if pool.RoundInterval != nil {
+			roundInterval = *pool.RoundInterval
+		}
+
 		var ticker Ticker
-		if a.Config.Agent.RoundInterval {
+		if roundInterval {
 			ticker = NewAlignedTicker(startTime, interval, jitter, offset)
 		} else {
 			ticker = NewUnalignedTicker(interval, jitter, offset)
@@ -436,10 +594,10 @@ func (a *Agent) runInputs(
 		acc.SetPrecision(getPrecision(precision, interval))
 
 		wg.Add(1)
-		go func(input *models.RunningInput) {
+		go func(input *models.RunningInput, workers chan struct{}) {
 			defer wg.Done()
-			a.gatherLoop(ctx, acc, input, ticker, interval)
-		}(input)
+			a.gatherLoop(ctx, acc, input, ticker, interval, workers)
+		}(input, workers[input.Config.WorkerPool])
 	}
 	defer stopTickers(tickers)
 	wg.Wait()
@@ -568,11 +726,12 @@ func (a *Agent) gatherLoop(
 	input *models.RunningInput,
 	ticker Ticker,
 	interval time.Duration,
+	workers chan struct{},
 ) {
 	for {
 		select {
 		case <-ticker.Elapsed():
-			err := a.gatherOnce(acc, input, ticker, interval)
+			err := a.gatherOnce(acc, input, ticker, interval, workers)
 			if err != nil {
 				acc.AddError(err)
 			}
@@ -583,10 +742,15 @@ func (a *Agent) gatherLoop(
 }
 
 // gatherOnce runs the input's Gather function once, logging a warning each interval it fails to complete before.
-func (*Agent) gatherOnce(acc telegraf.Accumulator, input *models.RunningInput, ticker Ticker, interval time.Duration) error {
+// If the input belongs to a worker pool with limited workers, the gather waits for a free worker.
+func (*Agent) gatherOnce(acc telegraf.Accumulator, input *models.RunningInput, ticker Ticker, interval time.Duration, workers chan struct{}) error {
 	done := make(chan error)
 	go func() {
 		defer panicRecover(input)
+		if workers != nil {
+			workers <- struct{}{}
+			defer func() { <-workers }()
+		}
 		done <- input.Gather(acc)
 	}()
 
@@ -875,10 +1039,80 @@ func (a *Agent) runOutputs(
 	cancel()
 	wg.Wait()
 
+	var handedOff map[string]int
+	if a.handoff != nil && a.handoff.HandedOff() {
+		handedOff = a.handoffMetrics(unit.outputs)
+	}
+
+	report := newShutdownReport(unit.outputs, handedOff)
+	report.log()
+	if a.Config.Agent.ShutdownReportFile != "" {
+		if err := report.writeFile(a.Config.Agent.ShutdownReportFile); err != nil {
+			log.Printf("E! [agent] %v", err)
+		}
+	}
+	a.shutdown.report = report
+
 	log.Println("I! [agent] Stopping running outputs")
 	stopRunningOutputs(unit.outputs)
 }
 
+// handoffMetrics passes the metrics that could not be written before shutdown
+// to the successor process and returns the number of metrics passed per
+// output ID.
+func (a *Agent) handoffMetrics(outputs []*models.RunningOutput) map[string]int {
+	passed := make(map[string]int, len(outputs))
+	for _, output := range outputs {
+		// Disk buffers keep their metrics on shutdown anyway
+		if output.Config.BufferStrategy != "" && output.Config.BufferStrategy != "memory" {
+			continue
+		}
+
+		metrics := output.DrainBuffer()
+		if len(metrics) == 0 {
+			continue
+		}
+		if err := a.handoff.SendMetrics(output.ID(), metrics); err != nil {
+			log.Printf("E! [agent] Passing %d metrics of %s to successor failed: %v", len(metrics), output.LogName(), err)
+			continue
+		}
+		log.Printf("I! [agent] Passed %d metrics of %s to successor", len(metrics), output.LogName())
+		passed[output.ID()] = len(metrics)
+	}
+	return passed
+}
+
+// receiveMetrics adds the metrics passed by the predecessor process to the
+// buffers of the corresponding outputs.
+func (*Agent) receiveMetrics(ctx context.Context, predecessor *handoff.Predecessor, outputs []*models.RunningOutput) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		predecessor.Close()
+	}()
+
+	err := predecessor.ReceiveMetrics(func(id string, metrics []telegraf.Metric) {
+		for _, output := range outputs {
+			if output.ID() != id {
+				continue
+			}
+			for _, m := range metrics {
+				output.AddMetricNoCopy(m)
+			}
+			log.Printf("I! [agent] Received %d metrics of %s from predecessor", len(metrics), output.LogName())
+			return
+		}
+		log.Printf("W! [agent] Dropping %d metrics from predecessor for unknown output %q", len(metrics), id)
+	})
+	if err != nil && ctx.Err() == nil {
+		log.Printf("E! [agent] Receiving metrics from predecessor failed: %v", err)
+	}
+}
+
 // flushLoop runs an output's flush function periodically until the context is
 // done.
 func (a *Agent) flushLoop(
@@ -901,14 +1135,14 @@ func (a *Agent) flushLoop(
 		// Favor shutdown over other methods.
 		select {
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			logError(a.drain(output, ticker))
 			return
 		default:
 		}
 
 		select {
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			logError(a.drain(output, ticker))
 			return
 		case <-ticker.Elapsed():
 			logError(a.flushOnce(output, ticker, output.Write))
@@ -920,6 +1154,33 @@ func (a *Agent) flushLoop(
 	}
 }
 
+// drain flushes the output on shutdown. If a drain timeout is configured, the
+// write is retried until all buffered metrics are written or the timeout
+// elapsed. The timeout of the output takes precedence over the agent setting.
+func (a *Agent) drain(output *models.RunningOutput, ticker Ticker) error {
+	timeout := time.Duration(a.Config.Agent.ShutdownDrainTimeout)
+	if output.Config.ShutdownDrainTimeout != 0 {
+		timeout = output.Config.ShutdownDrainTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		err := a.flushOnce(output, ticker, output.Write)
+		if timeout <= 0 || output.BufferLength() == 0 {
+			return err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			log.Printf("W! [agent] Draining %s timed out with %d metrics remaining", output.LogName(), output.BufferLength())
+			return err
+		}
+		if err != nil {
+			log.Printf("D! [agent] Retrying to drain %s: %v", output.LogName(), err)
+		}
+		time.Sleep(min(drainRetryInterval, remaining))
+	}
+}
+
 // flushOnce runs the output's Write function once, logging a warning each interval it fails to complete before the flush interval elapses.
 func (*Agent) flushOnce(output *models.RunningOutput, ticker Ticker, writeFunc func() error) error {
 	done := make(chan error)
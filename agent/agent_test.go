@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -257,3 +258,153 @@ func collect(ctx context.Context, a *Agent, wait time.Duration) ([]telegraf.Metr
 	}
 	return received, nil
 }
+
+func TestDrainOnShutdown(t *testing.T) {
+	drainRetryInterval = 10 * time.Millisecond
+	defer func() { drainRetryInterval = time.Second }()
+
+	tests := []struct {
+		name          string
+		timeout       time.Duration
+		outputTimeout time.Duration
+		failures      int
+		remaining     int
+	}{
+		{
+			name:      "flush once",
+			failures:  2,
+			remaining: 1,
+		},
+		{
+			name:     "drained",
+			timeout:  10 * time.Second,
+			failures: 2,
+		},
+		{
+			name:      "timeout",
+			timeout:   50 * time.Millisecond,
+			failures:  1000,
+			remaining: 1,
+		},
+		{
+			name:          "output timeout",
+			outputTimeout: 10 * time.Second,
+			failures:      2,
+		},
+		{
+			name:          "output timeout precedence",
+			timeout:       10 * time.Second,
+			outputTimeout: 50 * time.Millisecond,
+			failures:      1000,
+			remaining:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewConfig()
+			cfg.Agent.ShutdownDrainTimeout = config.Duration(tt.timeout)
+			a := NewAgent(cfg)
+
+			plugin := &flakyOutput{failures: tt.failures}
+			output := models.NewRunningOutput(plugin, &models.OutputConfig{Name: "flaky", ShutdownDrainTimeout: tt.outputTimeout}, 1000, 10000)
+			require.NoError(t, output.Init())
+			require.NoError(t, output.Connect())
+			output.AddMetric(testutil.TestMetric(42.0))
+
+			ticker := NewRollingTicker(time.Hour, 0)
+			defer ticker.Stop()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			a.flushLoop(ctx, output, ticker)
+			require.Equal(t, tt.remaining, output.BufferLength())
+		})
+	}
+}
+
+type flakyOutput struct {
+	failures int
+}
+
+func (*flakyOutput) SampleConfig() string {
+	return ""
+}
+
+func (*flakyOutput) Connect() error {
+	return nil
+}
+
+func (*flakyOutput) Close() error {
+	return nil
+}
+
+func (o *flakyOutput) Write([]telegraf.Metric) error {
+	if o.failures > 0 {
+		o.failures--
+		return fmt.Errorf("%d failures remaining", o.failures)
+	}
+	return nil
+}
+
+func TestWorkerPoolUnknown(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Agent.WorkerPools = map[string]config.WorkerPool{"snmp": {Workers: 1}}
+	input := models.NewRunningInput(&slowInput{}, &models.InputConfig{Name: "slow", WorkerPool: "sql"})
+	cfg.Inputs = append(cfg.Inputs, input)
+
+	a := NewAgent(cfg)
+	require.ErrorContains(t, a.InitPlugins(), `input inputs.slow uses unknown worker pool "sql"`)
+}
+
+func TestWorkerPoolLimit(t *testing.T) {
+	a := NewAgent(config.NewConfig())
+
+	ticker := NewRollingTicker(time.Hour, 0)
+	defer ticker.Stop()
+
+	// Use a separate running input per gather as the running input's state
+	// must not be accessed concurrently, the plugin itself counts the active
+	// gathers across all inputs
+	plugin := &slowInput{delay: 50 * time.Millisecond}
+	workers := make(chan struct{}, 2)
+	var wg sync.WaitGroup
+	for range 5 {
+		input := models.NewRunningInput(plugin, &models.InputConfig{Name: "slow"})
+		acc := NewAccumulator(input, make(chan telegraf.Metric, 10))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, a.gatherOnce(acc, input, ticker, time.Hour, workers))
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int64(5), plugin.gathers.Load())
+	require.Equal(t, int64(2), plugin.maxActive.Load())
+}
+
+type slowInput struct {
+	delay     time.Duration
+	active    atomic.Int64
+	maxActive atomic.Int64
+	gathers   atomic.Int64
+}
+
+func (*slowInput) SampleConfig() string {
+	return ""
+}
+
+func (i *slowInput) Gather(telegraf.Accumulator) error {
+	active := i.active.Add(1)
+	defer i.active.Add(-1)
+	for {
+		current := i.maxActive.Load()
+		if active <= current || i.maxActive.CompareAndSwap(current, active) {
+			break
+		}
+	}
+	time.Sleep(i.delay)
+	i.gathers.Add(1)
+	return nil
+}
@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// healthServer serves the liveness and readiness endpoints of the agent.
+// Liveness only reflects a running agent while readiness additionally
+// requires all outputs to have written successfully recently.
+type healthServer struct {
+	outputs       []*models.RunningOutput
+	flushInterval time.Duration
+	maxMissed     int
+
+	ready  atomic.Bool
+	server *http.Server
+}
+
+type outputHealth struct {
+	Name        string     `json:"name"`
+	Ready       bool       `json:"ready"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	Buffered    int        `json:"buffered"`
+}
+
+type healthStatus struct {
+	Status  string         `json:"status"`
+	Outputs []outputHealth `json:"outputs,omitempty"`
+}
+
+func newHealthServer(outputs []*models.RunningOutput, flushInterval time.Duration, maxMissed int) *healthServer {
+	h := &healthServer{
+		outputs:       outputs,
+		flushInterval: flushInterval,
+		maxMissed:     maxMissed,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", h.serveHealth)
+	mux.HandleFunc("/readyz", h.serveReady)
+	h.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return h
+}
+
+func (h *healthServer) start(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := h.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("E! [agent] Serving health endpoints failed: %v", err)
+		}
+	}()
+	log.Printf("I! [agent] Serving health endpoints on %s", listener.Addr())
+
+	return nil
+}
+
+func (h *healthServer) stop() {
+	if err := h.server.Close(); err != nil {
+		log.Printf("E! [agent] Closing health server failed: %v", err)
+	}
+}
+
+func (*healthServer) serveHealth(w http.ResponseWriter, _ *http.Request) {
+	writeHealthStatus(w, http.StatusOK, &healthStatus{Status: "ok"})
+}
+
+func (h *healthServer) serveReady(w http.ResponseWriter, _ *http.Request) {
+	status := h.status(time.Now())
+	code := http.StatusOK
+	if status.Status != "ready" {
+		code = http.StatusServiceUnavailable
+	}
+	writeHealthStatus(w, code, status)
+}
+
+// status determines the readiness of the agent. An output is considered ready
+// if it connected successfully and either has no buffered metrics or wrote
+// successfully within the maximum number of missed flush intervals.
+func (h *healthServer) status(now time.Time) *healthStatus {
+	ready := h.ready.Load()
+
+	outputs := make([]outputHealth, 0, len(h.outputs))
+	for _, output := range h.outputs {
+		interval := h.flushInterval
+		if output.Config.FlushInterval != 0 {
+			interval = output.Config.FlushInterval
+		}
+
+		oh := outputHealth{
+			Name:     output.LogName(),
+			Buffered: output.BufferLength(),
+		}
+		if last := output.LastSuccess(); !last.IsZero() {
+			oh.LastSuccess = &last
+			oh.Ready = oh.Buffered == 0 || now.Sub(last) <= time.Duration(h.maxMissed)*interval
+		}
+		ready = ready && oh.Ready
+		outputs = append(outputs, oh)
+	}
+
+	status := &healthStatus{Status: "ready", Outputs: outputs}
+	if !ready {
+		status.Status = "not ready"
+	}
+	return status
+}
+
+func writeHealthStatus(w http.ResponseWriter, code int, status *healthStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.Printf("E! [agent] Writing health status failed: %v", err)
+	}
+}
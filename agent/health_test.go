@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type healthOutput struct {
+	fail bool
+}
+
+func (*healthOutput) SampleConfig() string {
+	return ""
+}
+
+func (*healthOutput) Connect() error {
+	return nil
+}
+
+func (*healthOutput) Close() error {
+	return nil
+}
+
+func (o *healthOutput) Write([]telegraf.Metric) error {
+	if o.fail {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	plugin := &healthOutput{}
+	output := models.NewRunningOutput(plugin, &models.OutputConfig{Name: "test"}, 1000, 10000)
+	require.NoError(t, output.Init())
+	require.NoError(t, output.Connect())
+
+	health := newHealthServer([]*models.RunningOutput{output}, time.Second, 3)
+
+	// The agent is alive but not ready before the inputs are started
+	rec := httptest.NewRecorder()
+	health.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	health.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	health.ready.Store(true)
+	rec = httptest.NewRecorder()
+	health.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var status healthStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	require.Equal(t, "ready", status.Status)
+	require.Len(t, status.Outputs, 1)
+	require.Equal(t, "outputs.test", status.Outputs[0].Name)
+	require.True(t, status.Outputs[0].Ready)
+}
+
+func TestHealthOutputReadiness(t *testing.T) {
+	plugin := &healthOutput{}
+	output := models.NewRunningOutput(plugin, &models.OutputConfig{Name: "test"}, 1000, 10000)
+	require.NoError(t, output.Init())
+
+	health := newHealthServer([]*models.RunningOutput{output}, time.Second, 3)
+	health.ready.Store(true)
+
+	// Outputs not being connected are not ready
+	status := health.status(time.Now())
+	require.Equal(t, "not ready", status.Status)
+	require.False(t, status.Outputs[0].Ready)
+
+	// Connected outputs without buffered metrics are ready
+	require.NoError(t, output.Connect())
+	status = health.status(time.Now())
+	require.Equal(t, "ready", status.Status)
+
+	// Failing writes keep metrics in the buffer and the output becomes not
+	// ready after missing the configured number of flushes
+	plugin.fail = true
+	output.AddMetric(testutil.TestMetric(42.0))
+	require.Error(t, output.Write())
+	require.Equal(t, 1, output.BufferLength())
+	require.Equal(t, "ready", health.status(time.Now()).Status)
+	status = health.status(time.Now().Add(4 * time.Second))
+	require.Equal(t, "not ready", status.Status)
+	require.Equal(t, 1, status.Outputs[0].Buffered)
+
+	// A successful write recovers the readiness
+	plugin.fail = false
+	require.NoError(t, output.Write())
+	status = health.status(time.Now().Add(time.Second))
+	require.Equal(t, "ready", status.Status)
+}
@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// outputReport summarizes the metrics of an output over the run of the agent
+type outputReport struct {
+	Output    string `json:"output"`
+	ID        string `json:"id"`
+	Written   int64  `json:"metrics_written"`
+	Rejected  int64  `json:"metrics_rejected"`
+	Dropped   int64  `json:"metrics_dropped"`
+	Unflushed int    `json:"metrics_unflushed"`
+	HandedOff int    `json:"metrics_handed_off"`
+	// Persisted is true if the unflushed metrics are kept in a disk buffer
+	// and are written after the next start
+	Persisted bool `json:"persisted"`
+}
+
+// lost returns the number of metrics which were not and will not be written
+func (r *outputReport) lost() int64 {
+	if r.Persisted {
+		return r.Dropped
+	}
+	return r.Dropped + int64(r.Unflushed)
+}
+
+// shutdownReport summarizes the metrics not written by the outputs on
+// shutdown to quantify the data loss of restarts
+type shutdownReport struct {
+	Time    time.Time      `json:"time"`
+	Outputs []outputReport `json:"outputs"`
+}
+
+func newShutdownReport(outputs []*models.RunningOutput, handedOff map[string]int) shutdownReport {
+	report := shutdownReport{
+		Time:    time.Now(),
+		Outputs: make([]outputReport, 0, len(outputs)),
+	}
+	for _, output := range outputs {
+		stats := output.BufferStats()
+		report.Outputs = append(report.Outputs, outputReport{
+			Output:    output.LogName(),
+			ID:        output.ID(),
+			Written:   stats.MetricsWritten.Get(),
+			Rejected:  stats.MetricsRejected.Get(),
+			Dropped:   stats.MetricsDropped.Get(),
+			Unflushed: output.BufferLength(),
+			HandedOff: handedOff[output.ID()],
+			Persisted: output.Config.BufferStrategy != "" && output.Config.BufferStrategy != "memory",
+		})
+	}
+	return report
+}
+
+func (r *shutdownReport) log() {
+	for _, o := range r.Outputs {
+		msg := fmt.Sprintf("[agent] Shutdown report for %s: %d metrics written, %d rejected, %d dropped, %d unflushed",
+			o.Output, o.Written, o.Rejected, o.Dropped, o.Unflushed)
+		if o.HandedOff > 0 {
+			msg += fmt.Sprintf(", %d handed off to successor", o.HandedOff)
+		}
+		if o.Unflushed > 0 && o.Persisted {
+			msg += " (kept in buffer)"
+		}
+		if o.lost() > 0 {
+			log.Printf("W! %s; %d metrics lost", msg, o.lost())
+		} else {
+			log.Printf("I! %s", msg)
+		}
+	}
+}
+
+func (r *shutdownReport) writeFile(filename string) error {
+	buf, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling shutdown report failed: %w", err)
+	}
+	if err := os.WriteFile(filename, buf, 0640); err != nil {
+		return fmt.Errorf("writing shutdown report failed: %w", err)
+	}
+	return nil
+}
+
+// shutdownState persists the shutdown report in the statefile to report the
+// metrics left unflushed by the previous run on startup
+type shutdownState struct {
+	report shutdownReport
+}
+
+func (s *shutdownState) GetState() interface{} {
+	return s.report
+}
+
+func (*shutdownState) SetState(state interface{}) error {
+	previous, ok := state.(shutdownReport)
+	if !ok {
+		return errors.New("invalid shutdown report")
+	}
+
+	for _, o := range previous.Outputs {
+		if o.Unflushed == 0 && o.Dropped == 0 {
+			continue
+		}
+		if o.Persisted && o.Unflushed > 0 {
+			log.Printf("I! [agent] Previous shutdown at %s left %d unflushed metrics in the buffer of %s",
+				previous.Time.Format(time.RFC3339), o.Unflushed, o.Output)
+		}
+		if lost := o.lost(); lost > 0 {
+			log.Printf("W! [agent] Previous run until %s lost %d metrics of %s",
+				previous.Time.Format(time.RFC3339), lost, o.Output)
+		}
+	}
+	return nil
+}
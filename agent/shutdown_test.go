@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/persister"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestShutdownReport(t *testing.T) {
+	plugin := &flakyOutput{failures: 1}
+	memory := models.NewRunningOutput(plugin, &models.OutputConfig{Name: "memory", ID: "memory-id"}, 1000, 10000)
+	require.NoError(t, memory.Init())
+	require.NoError(t, memory.Connect())
+
+	disk := models.NewRunningOutput(
+		&flakyOutput{failures: 1000},
+		&models.OutputConfig{
+			Name:            "disk",
+			ID:              "disk-id",
+			BufferStrategy:  "disk_write_through",
+			BufferDirectory: t.TempDir(),
+		},
+		1000, 10000,
+	)
+	require.NoError(t, disk.Init())
+	require.NoError(t, disk.Connect())
+	defer disk.Close()
+
+	for range 3 {
+		memory.AddMetric(testutil.TestMetric(42.0))
+		disk.AddMetric(testutil.TestMetric(42.0))
+	}
+	require.Error(t, memory.Write())
+	memory.AddMetric(testutil.TestMetric(42.0))
+	require.NoError(t, memory.Write())
+	memory.AddMetric(testutil.TestMetric(42.0))
+	require.Error(t, disk.Write())
+
+	report := newShutdownReport([]*models.RunningOutput{memory, disk}, map[string]int{"memory-id": 2})
+	require.Len(t, report.Outputs, 2)
+
+	expected := outputReport{
+		Output:    "outputs.memory",
+		ID:        "memory-id",
+		Written:   4,
+		Unflushed: 1,
+		HandedOff: 2,
+	}
+	require.Equal(t, expected, report.Outputs[0])
+	require.Equal(t, int64(1), report.Outputs[0].lost())
+
+	expected = outputReport{
+		Output:    "outputs.disk",
+		ID:        "disk-id",
+		Unflushed: 3,
+		Persisted: true,
+	}
+	require.Equal(t, expected, report.Outputs[1])
+	require.Zero(t, report.Outputs[1].lost())
+
+	// The report is written to the file as JSON
+	filename := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, report.writeFile(filename))
+	buf, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	var actual shutdownReport
+	require.NoError(t, json.Unmarshal(buf, &actual))
+	require.Equal(t, report.Outputs, actual.Outputs)
+	require.True(t, report.Time.Equal(actual.Time))
+}
+
+func TestShutdownReportState(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "states.json")
+
+	state := &shutdownState{
+		report: shutdownReport{
+			Outputs: []outputReport{{Output: "outputs.memory", ID: "memory-id", Written: 10, Unflushed: 5}},
+		},
+	}
+	p := &persister.Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("agent.shutdown", state))
+	require.NoError(t, p.Store())
+
+	// The previous report is restored from the statefile
+	restored := &shutdownState{}
+	p = &persister.Persister{Filename: filename}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("agent.shutdown", restored))
+	require.NoError(t, p.Load())
+	require.Error(t, restored.SetState("invalid"))
+}
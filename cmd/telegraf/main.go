@@ -218,6 +218,7 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 		}
 
 		if cCtx.String("pprof-addr") != "" {
+			log.Println("W! The '--pprof-addr' flag is deprecated, use the 'admin_address' agent setting instead!")
 			pprof.Start(cCtx.String("pprof-addr"))
 		}
 
@@ -293,7 +294,7 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 				},
 				&cli.StringFlag{
 					Name:  "pprof-addr",
-					Usage: "pprof host/IP and port to listen on (e.g. 'localhost:6060')",
+					Usage: "DEPRECATED: pprof host/IP and port to listen on (e.g. 'localhost:6060'), use the 'admin_address' agent setting instead",
 				},
 				&cli.StringFlag{
 					Name: "watch-config",
@@ -23,6 +23,7 @@ import (
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -493,6 +494,11 @@ func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool) error {
 	}
 	log.Printf("I! Tags enabled: %s", c.ListTags())
 
+	metric.EnablePooling(c.Agent.MetricPooling)
+	if c.Agent.MetricPooling {
+		log.Print("I! Metric pooling enabled")
+	}
+
 	if count, found := c.Deprecations["inputs"]; found && (count[0] > 0 || count[1] > 0) {
 		log.Printf("W! Deprecated inputs: %d and %d options", count[0], count[1])
 	}
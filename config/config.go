@@ -26,6 +26,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/limiter"
 	logging "github.com/influxdata/telegraf/logger"
 	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/persister"
@@ -37,6 +38,7 @@ import (
 	"github.com/influxdata/telegraf/plugins/processors"
 	"github.com/influxdata/telegraf/plugins/secretstores"
 	"github.com/influxdata/telegraf/plugins/serializers"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 var (
@@ -81,6 +83,7 @@ type Config struct {
 
 	SecretStores      map[string]telegraf.SecretStore
 	secretStoreSource map[string][]string
+	secretUsers       []secretUser
 
 	Agent       *AgentConfig
 	Inputs      []*models.RunningInput
@@ -97,6 +100,12 @@ type Config struct {
 
 	Deprecations map[string][]int64
 
+	// bandwidthLimiter is the agent-wide egress limiter shared by all outputs
+	bandwidthLimiter *limiter.BandwidthLimiter
+
+	// priorityRules are the compiled agent priority rules shared by all outputs
+	priorityRules []*models.PriorityRule
+
 	Persister *persister.Persister
 
 	NumberSecrets uint64
@@ -130,6 +139,7 @@ func NewConfig() *Config {
 			RoundInterval:              true,
 			FlushInterval:              Duration(10 * time.Second),
 			LogfileRotationMaxArchives: 5,
+			HealthMaxMissedFlushes:     3,
 		},
 
 		Tags:               make(map[string]string),
@@ -204,6 +214,15 @@ type AgentConfig struct {
 	// ie, a jitter of 5s and interval 10s means flushes will happen every 10-15s
 	FlushJitter Duration
 
+	// ShutdownDrainTimeout is the maximum time to retry writing the buffered
+	// metrics of the outputs on shutdown. If zero, outputs are flushed once.
+	ShutdownDrainTimeout Duration `toml:"shutdown_drain_timeout"`
+
+	// ShutdownReportFile is the name of the file to write the report of the
+	// metrics written, dropped and left unflushed per output to on shutdown.
+	// The report is logged in any case.
+	ShutdownReportFile string `toml:"shutdown_report_file"`
+
 	// MetricBatchSize is the maximum number of metrics that is written to an
 	// output plugin in one call.
 	MetricBatchSize int
@@ -275,6 +294,41 @@ type AgentConfig struct {
 	// the state in the file will be restored for the plugins.
 	Statefile string `toml:"statefile"`
 
+	// Interval for checking the secrets referenced by plugins for changes in
+	// their secret-stores. Plugins supporting secret rotation are notified on
+	// changes and can reconnect using the new credentials. A zero value
+	// disables the check.
+	SecretRotationInterval Duration `toml:"secret_rotation_interval"`
+
+	// Path of the unix socket used to hand over listening sockets and buffered
+	// metrics between an old and a new Telegraf process, e.g. during a binary
+	// upgrade. If empty, no handoff is performed.
+	HandoffSocket string `toml:"handoff_socket"`
+
+	// Address to serve the '/healthz' and '/readyz' endpoints on, e.g.
+	// ':8080'. If empty, the endpoints are disabled.
+	HealthServiceAddress string `toml:"health_service_address"`
+
+	// Number of flush intervals an output with buffered metrics may go without
+	// a successful write before the agent is reported as not ready.
+	HealthMaxMissedFlushes int `toml:"health_max_missed_flushes"`
+
+	// Address to serve the admin endpoints for debugging the agent on, e.g.
+	// 'localhost:6060'. If empty, the endpoints are disabled.
+	AdminAddress string `toml:"admin_address"`
+
+	// Credentials required for accessing the admin endpoints using HTTP
+	// basic authentication.
+	AdminUsername string `toml:"admin_username"`
+	AdminPassword string `toml:"admin_password"`
+
+	// TLS certificate and key for serving the admin endpoints via HTTPS.
+	// Client certificates are required and verified against the given CA
+	// certificates if any.
+	AdminTLSCert           string   `toml:"admin_tls_cert"`
+	AdminTLSKey            string   `toml:"admin_tls_key"`
+	AdminTLSAllowedCACerts []string `toml:"admin_tls_allowed_cacerts"`
+
 	// Flag to always keep tags explicitly defined in the plugin itself and
 	// ensure those tags always pass filtering.
 	AlwaysIncludeLocalTags bool `toml:"always_include_local_tags"`
@@ -299,6 +353,48 @@ type AgentConfig struct {
 	// BufferDirectory is the directory to store buffer files for serialized
 	// to disk metrics when using the "disk_write_through" buffer strategy.
 	BufferDirectory string `toml:"buffer_directory"`
+
+	// EgressBandwidthLimit is the maximum number of bytes per second sent by
+	// all outputs supporting bandwidth limiting together. Zero means unlimited.
+	EgressBandwidthLimit Size `toml:"egress_bandwidth_limit"`
+
+	// MetricPooling enables recycling of metrics and sharing of tags between
+	// metric copies to reduce the allocations in high-throughput pipelines.
+	MetricPooling bool `toml:"metric_pooling"`
+
+	// WorkerPools are named pools of inputs gathered independently of inputs
+	// in other pools. Inputs are assigned using the 'worker_pool' setting.
+	WorkerPools map[string]WorkerPool `toml:"worker_pools"`
+
+	// PriorityRules assign priority classes to metrics matching the rule's
+	// filters. Output buffers evict metrics of lower priority first when full.
+	PriorityRules []PriorityRule `toml:"priority_rules"`
+}
+
+// PriorityRule assigns the priority class to all metrics matching the name
+// and tag filters. The first matching rule wins.
+type PriorityRule struct {
+	// Class is the priority class, one of "low", "normal", "high" or
+	// "critical".
+	Class string `toml:"class"`
+
+	// NamePass and TagPass select the metrics the rule applies to.
+	NamePass []string            `toml:"namepass"`
+	TagPass  map[string][]string `toml:"tagpass"`
+}
+
+// WorkerPool contains the settings of a pool of inputs with its own limit of
+// concurrent gathers and interval alignment overriding the agent settings.
+type WorkerPool struct {
+	// Workers is the maximum number of inputs of the pool gathering at the
+	// same time. Zero means unlimited.
+	Workers int `toml:"workers"`
+
+	// RoundInterval, CollectionJitter and CollectionOffset override the
+	// respective agent settings for inputs of the pool if set.
+	RoundInterval    *bool    `toml:"round_interval"`
+	CollectionJitter Duration `toml:"collection_jitter"`
+	CollectionOffset Duration `toml:"collection_offset"`
 }
 
 // InputNames returns a list of strings of the configured inputs.
@@ -1350,9 +1446,11 @@ func (c *Config) addOutput(name, source string, table *ast.Table) error {
 		return err
 	}
 
+	firstSecret := len(unlinkedSecrets)
 	if err := c.toml.UnmarshalTable(table, output); err != nil {
 		return err
 	}
+	c.trackSecrets("outputs."+name, output, firstSecret)
 
 	if err := c.printUserDeprecation("outputs", name, output); err != nil {
 		return err
@@ -1442,9 +1540,11 @@ func (c *Config) addInput(name, source string, table *ast.Table) error {
 		return err
 	}
 
+	firstSecret := len(unlinkedSecrets)
 	if err := c.toml.UnmarshalTable(table, input); err != nil {
 		return err
 	}
+	c.trackSecrets("inputs."+name, input, firstSecret)
 
 	if err := c.printUserDeprecation("inputs", name, input); err != nil {
 		return err
@@ -1624,6 +1724,11 @@ func (c *Config) buildFilter(plugin string, tbl *ast.Table) (models.Filter, erro
 
 	f.MetricPass = c.getFieldString(tbl, "metricpass")
 
+	// Inputs assign routes via the 'route' setting instead
+	if !strings.HasPrefix(plugin, "inputs.") {
+		f.Routes = c.getFieldStringSlice(tbl, "routes")
+	}
+
 	if c.hasErrs() {
 		return f, c.firstErr()
 	}
@@ -1651,6 +1756,16 @@ func (c *Config) buildInput(name, source string, tbl *ast.Table) (*models.InputC
 	cp.CollectionOffset, _ = c.getFieldDuration(tbl, "collection_offset")
 	cp.StartupErrorBehavior = c.getFieldString(tbl, "startup_error_behavior")
 	cp.TimeSource = c.getFieldString(tbl, "time_source")
+	cp.Route = c.getFieldString(tbl, "route")
+	if priority, err := models.ParsePriority(c.getFieldString(tbl, "priority")); err != nil {
+		c.addError(tbl, err)
+	} else {
+		cp.Priority = priority
+	}
+	cp.DeliveryOutputs = c.getFieldStringSlice(tbl, "delivery_outputs")
+	cp.Watermark = c.getFieldBool(tbl, "watermark")
+	cp.WatermarkSourceTag = c.getFieldString(tbl, "watermark_source_tag")
+	cp.WorkerPool = c.getFieldString(tbl, "worker_pool")
 
 	cp.MeasurementPrefix = c.getFieldString(tbl, "name_prefix")
 	cp.MeasurementSuffix = c.getFieldString(tbl, "name_suffix")
@@ -1708,6 +1823,7 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 
 	oc.FlushInterval, _ = c.getFieldDuration(tbl, "flush_interval")
 	oc.FlushJitter, _ = c.getFieldDuration(tbl, "flush_jitter")
+	oc.ShutdownDrainTimeout, _ = c.getFieldDuration(tbl, "shutdown_drain_timeout")
 	oc.MetricBufferLimit = c.getFieldInt(tbl, "metric_buffer_limit")
 	oc.MetricBatchSize = c.getFieldInt(tbl, "metric_batch_size")
 	oc.Alias = c.getFieldString(tbl, "alias")
@@ -1716,6 +1832,25 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 	oc.NamePrefix = c.getFieldString(tbl, "name_prefix")
 	oc.StartupErrorBehavior = c.getFieldString(tbl, "startup_error_behavior")
 	oc.LogLevel = c.getFieldString(tbl, "log_level")
+	oc.FieldTypeConflict = c.getFieldString(tbl, "field_type_conflict")
+	oc.EgressBandwidthLimit = c.getFieldSize(tbl, "egress_bandwidth_limit")
+
+	if c.Agent.EgressBandwidthLimit > 0 {
+		if c.bandwidthLimiter == nil {
+			throttled := selfstat.Register("agent", "egress_throttle_ns", make(map[string]string))
+			c.bandwidthLimiter = limiter.NewBandwidthLimiter(int64(c.Agent.EgressBandwidthLimit), nil, throttled)
+		}
+		oc.GlobalBandwidthLimiter = c.bandwidthLimiter
+	}
+
+	if len(c.Agent.PriorityRules) > 0 && c.priorityRules == nil {
+		rules, err := buildPriorityRules(c.Agent.PriorityRules)
+		if err != nil {
+			return nil, err
+		}
+		c.priorityRules = rules
+	}
+	oc.PriorityRules = c.priorityRules
 
 	if c.hasErrs() {
 		return nil, c.firstErr()
@@ -1730,22 +1865,54 @@ func (c *Config) buildOutput(name, source string, tbl *ast.Table) (*models.Outpu
 	return oc, err
 }
 
+// buildPriorityRules compiles the agent priority rules
+func buildPriorityRules(cfg []PriorityRule) ([]*models.PriorityRule, error) {
+	rules := make([]*models.PriorityRule, 0, len(cfg))
+	for i, rc := range cfg {
+		priority, err := models.ParsePriority(rc.Class)
+		if err != nil {
+			return nil, fmt.Errorf("priority rule %d: %w", i+1, err)
+		}
+
+		rule := &models.PriorityRule{
+			Priority: priority,
+			NamePass: rc.NamePass,
+		}
+		for name, values := range rc.TagPass {
+			rule.TagPassFilters = append(rule.TagPassFilters, models.TagFilter{Name: name, Values: values})
+		}
+		sort.Slice(rule.TagPassFilters, func(i, j int) bool {
+			return rule.TagPassFilters[i].Name < rule.TagPassFilters[j].Name
+		})
+
+		if err := rule.Compile(); err != nil {
+			return nil, fmt.Errorf("priority rule %d: %w", i+1, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
 func (c *Config) missingTomlField(_ reflect.Type, key string) error {
 	switch key {
 	// General options to ignore
 	case "alias", "always_include_local_tags",
 		"buffer_strategy", "buffer_directory",
 		"collection_jitter", "collection_offset",
-		"data_format", "delay", "drop", "drop_original",
-		"fielddrop", "fieldexclude", "fieldinclude", "fieldpass", "flush_interval", "flush_jitter",
+		"data_format", "delay", "delivery_outputs", "drop", "drop_original",
+		"egress_bandwidth_limit",
+		"field_type_conflict", "fielddrop", "fieldexclude", "fieldinclude", "fieldpass", "flush_interval", "flush_jitter",
 		"grace",
 		"interval",
 		"log_level", "lvm", // What is this used for?
 		"metric_batch_size", "metric_buffer_limit", "metricpass",
 		"name_override", "name_prefix", "name_suffix", "namedrop", "namedrop_separator", "namepass", "namepass_separator",
 		"order",
-		"pass", "period", "precision",
-		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags", "startup_error_behavior", "labels":
+		"pass", "period", "precision", "priority",
+		"route", "routes",
+		"shutdown_drain_timeout",
+		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags", "startup_error_behavior", "labels",
+		"watermark", "watermark_source_tag", "worker_pool":
 
 	// Secret-store options to ignore
 	case "id":
@@ -1824,6 +1991,32 @@ func (c *Config) getFieldDuration(tbl *ast.Table, fieldName string) (time.Durati
 	return 0, false
 }
 
+func (c *Config) getFieldSize(tbl *ast.Table, fieldName string) int64 {
+	if node, ok := tbl.Fields[fieldName]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			var size Size
+			switch v := kv.Value.(type) {
+			case *ast.String:
+				if err := size.UnmarshalText([]byte(v.Value)); err != nil {
+					c.addError(tbl, fmt.Errorf("error parsing size: %w", err))
+					return 0
+				}
+			case *ast.Integer:
+				if err := size.UnmarshalText([]byte(v.Value)); err != nil {
+					c.addError(tbl, fmt.Errorf("error parsing size: %w", err))
+					return 0
+				}
+			default:
+				c.addError(tbl, fmt.Errorf("found unexpected format while parsing %q, expecting size", fieldName))
+				return 0
+			}
+			return int64(size)
+		}
+	}
+
+	return 0
+}
+
 func (c *Config) getFieldBool(tbl *ast.Table, fieldName string) bool {
 	if node, ok := tbl.Fields[fieldName]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
@@ -518,6 +518,29 @@ func TestConfig_AzureMonitorNamespacePrefix(t *testing.T) {
 	}
 }
 
+func TestConfig_EgressBandwidthLimit(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/egress_bandwidth.toml"))
+	require.Equal(t, config.Size(1024*1024), c.Agent.EgressBandwidthLimit)
+	require.Len(t, c.Outputs, 2)
+
+	// All outputs share the same global limiter
+	require.NotNil(t, c.Outputs[0].Config.GlobalBandwidthLimiter)
+	require.Same(t, c.Outputs[0].Config.GlobalBandwidthLimiter, c.Outputs[1].Config.GlobalBandwidthLimiter)
+	require.Equal(t, int64(64*1024), c.Outputs[0].Config.EgressBandwidthLimit)
+	require.Equal(t, int64(1000), c.Outputs[1].Config.EgressBandwidthLimit)
+}
+
+func TestConfig_Shutdown(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/shutdown.toml"))
+	require.Equal(t, config.Duration(30*time.Second), c.Agent.ShutdownDrainTimeout)
+	require.Equal(t, "/var/lib/telegraf/shutdown.json", c.Agent.ShutdownReportFile)
+	require.Len(t, c.Outputs, 2)
+	require.Equal(t, 5*time.Minute, c.Outputs[0].Config.ShutdownDrainTimeout)
+	require.Zero(t, c.Outputs[1].Config.ShutdownDrainTimeout)
+}
+
 func TestGetDefaultConfigPathFromEnvURL(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -554,6 +577,89 @@ func TestConfig_URLLikeFileName(t *testing.T) {
 	}
 }
 
+func TestConfig_Routes(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll("./testdata/routes.toml"))
+	require.Len(t, c.Inputs, 1)
+	require.Len(t, c.Processors, 1)
+	require.Len(t, c.Outputs, 1)
+
+	require.Equal(t, "secure", c.Inputs[0].Config.Route)
+	require.Equal(t, []string{"default", "secure"}, c.Processors[0].Config.Filter.Routes)
+	require.Equal(t, []string{"secure"}, c.Outputs[0].Config.Filter.Routes)
+}
+
+func TestConfig_Watermark(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll("./testdata/watermark.toml"))
+	require.Len(t, c.Inputs, 1)
+
+	require.True(t, c.Inputs[0].Config.Watermark)
+	require.Equal(t, "server", c.Inputs[0].Config.WatermarkSourceTag)
+}
+
+func TestConfig_WorkerPools(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll("./testdata/worker_pools.toml"))
+	require.Len(t, c.Inputs, 1)
+
+	roundInterval := false
+	expected := map[string]config.WorkerPool{
+		"snmp": {
+			Workers:          4,
+			RoundInterval:    &roundInterval,
+			CollectionOffset: config.Duration(5 * time.Second),
+		},
+	}
+	require.Equal(t, expected, c.Agent.WorkerPools)
+	require.Equal(t, "snmp", c.Inputs[0].Config.WorkerPool)
+}
+
+func TestConfig_Priority(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadAll("./testdata/priority.toml"))
+	require.Len(t, c.Inputs, 1)
+	require.Len(t, c.Outputs, 2)
+
+	require.Equal(t, models.PriorityHigh, c.Inputs[0].Config.Priority)
+
+	// All outputs share the same rules
+	rules := c.Outputs[0].Config.PriorityRules
+	require.Len(t, rules, 2)
+	require.Equal(t, rules, c.Outputs[1].Config.PriorityRules)
+	require.Equal(t, models.PriorityCritical, rules[0].Priority)
+	require.Equal(t, models.PriorityLow, rules[1].Priority)
+
+	internal := metric.New("internal_agent", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.True(t, rules[0].Match(internal))
+	require.False(t, rules[1].Match(internal))
+
+	bulk := metric.New("cpu", map[string]string{"source": "bulk-import"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.False(t, rules[0].Match(bulk))
+	require.True(t, rules[1].Match(bulk))
+}
+
+func TestConfig_PriorityInvalid(t *testing.T) {
+	c := config.NewConfig()
+	err := c.LoadConfigData([]byte(`
+[[inputs.memcached]]
+  servers = ["localhost"]
+  priority = "urgent"
+`), config.EmptySourcePath)
+	require.ErrorContains(t, err, `invalid priority class "urgent"`)
+
+	c = config.NewConfig()
+	err = c.LoadConfigData([]byte(`
+[agent]
+  [[agent.priority_rules]]
+    class = "high"
+
+[[outputs.http]]
+  url = "http://localhost:8080"
+`), config.EmptySourcePath)
+	require.ErrorContains(t, err, "priority rule 1: priority rule without 'namepass' or 'tagpass'")
+}
+
 func TestConfig_Filtering(t *testing.T) {
 	c := config.NewConfig()
 	require.NoError(t, c.LoadAll("./testdata/filter_metricpass.toml"))
@@ -5,6 +5,7 @@ import (
 	"log"
 	"regexp"
 	"strings"
+	"sync"
 	"sync/atomic"
 
 	"github.com/influxdata/telegraf"
@@ -30,6 +31,10 @@ var secretCandidatePattern = regexp.MustCompile(`@\{.+?:.+?}`)
 // secretCount is the number of secrets use in Telegraf
 var secretCount atomic.Int64
 
+// rotationLock protects the content of secrets against concurrent access
+// while refreshing rotated secrets
+var rotationLock sync.RWMutex
+
 // selectedImpl is the configured implementation for secrets
 var selectedImpl secretImpl = &protectedSecretImpl{}
 
@@ -95,6 +100,12 @@ type Secret struct {
 	// linked to the corresponding secret store.
 	unlinked []string
 
+	// template holds the secret including the references replaced by static
+	// values during linking and sources the resolvers of all references.
+	// Both are required to check for rotated secrets in the secret-stores.
+	template secretContainer
+	sources  map[string]telegraf.ResolveFunc
+
 	// notempty denotes if the secret is completely empty
 	notempty bool
 }
@@ -151,6 +162,12 @@ func (s *Secret) Destroy() {
 	s.resolvers = nil
 	s.unlinked = nil
 	s.notempty = false
+	s.sources = nil
+
+	if s.template != nil {
+		s.template.Destroy()
+		s.template = nil
+	}
 
 	if s.container != nil {
 		s.container.Destroy()
@@ -168,6 +185,9 @@ func (s *Secret) Empty() bool {
 
 // EqualTo performs a constant-time comparison of the secret to the given reference
 func (s *Secret) EqualTo(ref []byte) (bool, error) {
+	rotationLock.RLock()
+	defer rotationLock.RUnlock()
+
 	if s.container == nil {
 		return false, nil
 	}
@@ -181,29 +201,36 @@ func (s *Secret) EqualTo(ref []byte) (bool, error) {
 
 // Get return the string representation of the secret
 func (s *Secret) Get() (SecretBuffer, error) {
-	if s.container == nil {
+	// Only hold the lock while accessing the content as resolvers might
+	// access other secrets
+	rotationLock.RLock()
+	container, resolvers := s.container, s.resolvers
+	if container == nil {
+		rotationLock.RUnlock()
 		return selectedImpl.EmptyBuffer(), nil
 	}
 
 	if len(s.unlinked) > 0 {
+		rotationLock.RUnlock()
 		return nil, fmt.Errorf("unlinked parts in secret: %v", strings.Join(s.unlinked, ";"))
 	}
 
 	// Decrypt the secret so we can return it
-	buffer, err := s.container.Buffer()
+	buffer, err := container.Buffer()
+	rotationLock.RUnlock()
 	if err != nil {
 		return nil, err
 	}
 
 	// We've got a static secret so simply return the buffer
-	if len(s.resolvers) == 0 {
+	if len(resolvers) == 0 {
 		return buffer, nil
 	}
 	defer buffer.Destroy()
 
 	replaceErrs := make([]string, 0)
 	newsecret := secretPattern.ReplaceAllFunc(buffer.Bytes(), func(match []byte) []byte {
-		resolver, found := s.resolvers[string(match)]
+		resolver, found := resolvers[string(match)]
 		if !found {
 			replaceErrs = append(replaceErrs, fmt.Sprintf("no resolver for %q", match))
 			return match
@@ -221,7 +248,7 @@ func (s *Secret) Get() (SecretBuffer, error) {
 		return nil, fmt.Errorf("replacing secrets failed: %s", strings.Join(replaceErrs, ";"))
 	}
 
-	return s.container.AsBuffer(newsecret), nil
+	return container.AsBuffer(newsecret), nil
 }
 
 // Set overwrites the secret's value with a new one. Please note, the secret
@@ -239,6 +266,13 @@ func (s *Secret) Set(value []byte) error {
 	s.resolvers = res
 	s.notempty = len(value) > 0
 
+	// The new value is not tracked for rotated secrets anymore
+	if s.template != nil {
+		s.template.Destroy()
+		s.template = nil
+	}
+	s.sources = nil
+
 	return nil
 }
 
@@ -268,9 +302,12 @@ func (s *Secret) Link(resolvers map[string]telegraf.ResolveFunc) error {
 	}
 	s.resolvers = res
 
-	// Store the secret if it has changed
+	// Store the secret if it has changed and keep the original for checking
+	// the static parts for rotation later
 	if buffer.TemporaryString() != string(newsecret) {
-		s.container.Replace(newsecret)
+		s.template = s.container
+		s.sources = resolvers
+		s.container = selectedImpl.Container(newsecret)
 	}
 
 	// All linked now
@@ -279,6 +316,49 @@ func (s *Secret) Link(resolvers map[string]telegraf.ResolveFunc) error {
 	return nil
 }
 
+// refresh resolves the static references of the secret again and replaces
+// the secret content if any of the referenced secrets changed in their
+// secret-store. The function returns true if the content was replaced.
+func (s *Secret) refresh() (bool, error) {
+	// Resolve the new value without holding the lock as resolvers might
+	// access other secrets
+	rotationLock.RLock()
+	template, sources := s.template, s.sources
+	rotationLock.RUnlock()
+	if template == nil {
+		return false, nil
+	}
+	buffer, err := template.Buffer()
+	if err != nil {
+		return false, err
+	}
+	defer buffer.Destroy()
+
+	newsecret, res, replaceErrs := resolve(buffer.Bytes(), sources)
+	if len(replaceErrs) > 0 {
+		selectedImpl.Wipe(newsecret)
+		return false, fmt.Errorf("resolving secrets failed: %s", strings.Join(replaceErrs, ";"))
+	}
+
+	// Only lock for swapping in the new value
+	rotationLock.Lock()
+	defer rotationLock.Unlock()
+
+	if s.container == nil {
+		selectedImpl.Wipe(newsecret)
+		return false, nil
+	}
+	equal, err := s.container.Equals(newsecret)
+	if err != nil || equal {
+		selectedImpl.Wipe(newsecret)
+		return false, err
+	}
+	s.container.Replace(newsecret)
+	s.resolvers = res
+
+	return true, nil
+}
+
 func resolve(secret []byte, resolvers map[string]telegraf.ResolveFunc) ([]byte, map[string]telegraf.ResolveFunc, []string) {
 	// Iterate through the parts and try to resolve them. For static parts
 	// we directly replace them, while for dynamic ones we store the resolver.
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"log"
+
+	"github.com/influxdata/telegraf"
+)
+
+// secretUser is a plugin to be notified about rotated secrets referenced in
+// its configuration
+type secretUser struct {
+	name    string
+	handler telegraf.SecretRotationHandler
+	secrets []*Secret
+}
+
+// trackSecrets registers the secrets referencing secret-stores added since
+// the given index if the plugin implements telegraf.SecretRotationHandler
+func (c *Config) trackSecrets(name string, plugin interface{}, first int) {
+	handler, ok := plugin.(telegraf.SecretRotationHandler)
+	if !ok || first >= len(unlinkedSecrets) {
+		return
+	}
+	secrets := make([]*Secret, len(unlinkedSecrets)-first)
+	copy(secrets, unlinkedSecrets[first:])
+	c.secretUsers = append(c.secretUsers, secretUser{name: name, handler: handler, secrets: secrets})
+}
+
+// CheckSecretRotation resolves the static secret-store references of the
+// tracked plugins again and notifies the plugins if any of their secrets
+// changed. Dynamic secrets are resolved on each access and are not checked.
+func (c *Config) CheckSecretRotation() {
+	for _, u := range c.secretUsers {
+		var changed bool
+		for _, s := range u.secrets {
+			replaced, err := s.refresh()
+			if err != nil {
+				log.Printf("E! [%s] Checking secrets for rotation failed: %v", u.name, err)
+				continue
+			}
+			changed = changed || replaced
+		}
+		if !changed {
+			continue
+		}
+
+		log.Printf("I! [%s] Referenced secrets changed, notifying plugin", u.name)
+		if err := u.handler.SecretsRotated(); err != nil {
+			log.Printf("E! [%s] Handling rotated secrets failed: %v", u.name, err)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"testing"
+	"time"
 
 	"github.com/awnumar/memguard"
 	"github.com/stretchr/testify/require"
@@ -617,6 +618,104 @@ func (tsuite *SecretImplTestSuite) TestSecretStoreStaticChanging() {
 	}
 }
 
+func (tsuite *SecretImplTestSuite) TestSecretStoreStaticRotation() {
+	t := tsuite.T()
+
+	cfg := []byte(
+		`
+[[inputs.mockup]]
+	secret = "@{mock:secret}"
+[[inputs.mockup_rotating]]
+	secret = "user:@{mock:secret}"
+`)
+
+	c := NewConfig()
+	require.NoError(t, c.LoadConfigData(cfg, EmptySourcePath))
+	require.Len(t, c.Inputs, 2)
+
+	// Only plugins implementing the handler are tracked
+	require.Len(t, c.secretUsers, 1)
+
+	// Create a mockup secretstore
+	store := &MockupSecretStore{
+		Secrets: map[string][]byte{"secret": []byte("Ood Bnar")},
+		Dynamic: false,
+	}
+	require.NoError(t, store.Init())
+	c.SecretStores["mock"] = store
+	require.NoError(t, c.LinkSecrets())
+
+	plugin := c.Inputs[1].Input.(*MockupRotatingPlugin)
+
+	// Unchanged secrets should not notify the plugin
+	c.CheckSecretRotation()
+	require.Zero(t, plugin.rotated)
+
+	// Changed secrets should be updated and the plugin notified once
+	store.Secrets["secret"] = []byte("Thon")
+	c.CheckSecretRotation()
+	require.Equal(t, 1, plugin.rotated)
+	secret, err := plugin.Secret.Get()
+	require.NoError(t, err)
+	require.EqualValues(t, "user:Thon", secret.TemporaryString())
+	secret.Destroy()
+
+	c.CheckSecretRotation()
+	require.Equal(t, 1, plugin.rotated)
+
+	// Errors in the secret-store should keep the current secret
+	delete(store.Secrets, "secret")
+	c.CheckSecretRotation()
+	require.Equal(t, 1, plugin.rotated)
+	secret, err = plugin.Secret.Get()
+	require.NoError(t, err)
+	require.EqualValues(t, "user:Thon", secret.TemporaryString())
+	secret.Destroy()
+}
+
+func (tsuite *SecretImplTestSuite) TestSecretStoreRotationResolverWithSecret() {
+	t := tsuite.T()
+
+	cfg := []byte(
+		`
+[[inputs.mockup_rotating]]
+	secret = "@{mock:secret}"
+`)
+
+	c := NewConfig()
+	require.NoError(t, c.LoadConfigData(cfg, EmptySourcePath))
+	require.Len(t, c.Inputs, 1)
+
+	// Create a secretstore accessing its own secret when resolving like
+	// e.g. the TPM store does
+	store := &MockupProtectedSecretStore{
+		Password: NewSecret([]byte("Yoda")),
+		Secrets:  map[string][]byte{"secret": []byte("Ood Bnar")},
+	}
+	c.SecretStores["mock"] = store
+	require.NoError(t, c.LinkSecrets())
+
+	plugin := c.Inputs[0].Input.(*MockupRotatingPlugin)
+	store.Secrets["secret"] = []byte("Thon")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.CheckSecretRotation()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "checking for rotated secrets deadlocked")
+	}
+	require.Equal(t, 1, plugin.rotated)
+
+	secret, err := plugin.Secret.Get()
+	require.NoError(t, err)
+	require.EqualValues(t, "Thon", secret.TemporaryString())
+	secret.Destroy()
+}
+
 func (tsuite *SecretImplTestSuite) TestSecretStoreDynamic() {
 	t := tsuite.T()
 
@@ -796,6 +895,19 @@ type MockupSecretPlugin struct {
 func (*MockupSecretPlugin) SampleConfig() string                { return "Mockup test secret plugin" }
 func (*MockupSecretPlugin) Gather(_ telegraf.Accumulator) error { return nil }
 
+// Mockup (input) plugin supporting secret rotation
+type MockupRotatingPlugin struct {
+	Secret  Secret `toml:"secret"`
+	rotated int
+}
+
+func (*MockupRotatingPlugin) SampleConfig() string                { return "Mockup test secret plugin" }
+func (*MockupRotatingPlugin) Gather(_ telegraf.Accumulator) error { return nil }
+func (p *MockupRotatingPlugin) SecretsRotated() error {
+	p.rotated++
+	return nil
+}
+
 type MockupSecretStore struct {
 	Secrets map[string][]byte
 	Dynamic bool
@@ -835,10 +947,39 @@ func (s *MockupSecretStore) GetResolver(key string) (telegraf.ResolveFunc, error
 	}, nil
 }
 
+// Mockup secret-store requiring its own secret for resolving
+type MockupProtectedSecretStore struct {
+	MockupSecretStore
+	Password Secret
+	Secrets  map[string][]byte
+}
+
+func (s *MockupProtectedSecretStore) Get(key string) ([]byte, error) {
+	passwd, err := s.Password.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer passwd.Destroy()
+
+	v, found := s.Secrets[key]
+	if !found {
+		return nil, errors.New("not found")
+	}
+	return v, nil
+}
+
+func (s *MockupProtectedSecretStore) GetResolver(key string) (telegraf.ResolveFunc, error) {
+	return func() ([]byte, bool, error) {
+		v, err := s.Get(key)
+		return v, false, err
+	}, nil
+}
+
 // Register the mockup plugin on loading
 func init() {
 	// Register the mockup input plugin for the required names
 	inputs.Add("mockup", func() telegraf.Input { return &MockupSecretPlugin{} })
+	inputs.Add("mockup_rotating", func() telegraf.Input { return &MockupRotatingPlugin{} })
 	secretstores.Add("mockup", func(string) telegraf.SecretStore {
 		return &MockupSecretStore{}
 	})
@@ -13,6 +13,7 @@ func CombinedOutputTimeout(c *exec.Cmd, timeout time.Duration) ([]byte, error) {
 	var b bytes.Buffer
 	c.Stdout = &b
 	c.Stderr = &b
+	prepareCmd(c)
 	if err := c.Start(); err != nil {
 		return nil, err
 	}
@@ -27,6 +28,7 @@ func StdOutputTimeout(c *exec.Cmd, timeout time.Duration) ([]byte, error) {
 	var b bytes.Buffer
 	c.Stdout = &b
 	c.Stderr = nil
+	prepareCmd(c)
 	if err := c.Start(); err != nil {
 		return nil, err
 	}
@@ -37,6 +39,7 @@ func StdOutputTimeout(c *exec.Cmd, timeout time.Duration) ([]byte, error) {
 // RunTimeout runs the given command with the given timeout.
 // If the command times out, it attempts to kill the process.
 func RunTimeout(c *exec.Cmd, timeout time.Duration) error {
+	prepareCmd(c)
 	if err := c.Start(); err != nil {
 		return err
 	}
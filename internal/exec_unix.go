@@ -13,6 +13,8 @@ import (
 // sending a SIGKILL.
 const KillGrace = 5 * time.Second
 
+func prepareCmd(*exec.Cmd) {}
+
 // WaitTimeout waits for the given command to finish with a timeout.
 // It assumes the command has already been started.
 // If the command times out, it attempts to kill the process.
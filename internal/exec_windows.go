@@ -3,16 +3,52 @@
 package internal
 
 import (
+	"errors"
 	"log"
 	"os/exec"
+	"syscall"
 	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
 )
 
+// prepareCmd creates the process suspended so it cannot spawn any children
+// before being assigned to the job object in WaitTimeout
+func prepareCmd(c *exec.Cmd) {
+	if c.SysProcAttr == nil {
+		c.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	c.SysProcAttr.CreationFlags |= windows.CREATE_SUSPENDED
+}
+
 // WaitTimeout waits for the given command to finish with a timeout.
 // It assumes the command has already been started.
-// If the command times out, it attempts to kill the process.
+// If the command times out, it attempts to kill the process and all of its
+// child processes.
 func WaitTimeout(c *exec.Cmd, timeout time.Duration) error {
+	// Group the process in a job object to be able to terminate the whole
+	// process tree as killing the process leaves its children running
+	job, err := AssignJobObject(c.Process.Pid)
+	if err != nil {
+		log.Printf("W! [agent] Error assigning process to job object, child processes will not be terminated: %s", err)
+	} else {
+		defer windows.CloseHandle(job) //nolint:errcheck // Ignore the error as we cannot act on it
+	}
+	if c.SysProcAttr != nil && c.SysProcAttr.CreationFlags&windows.CREATE_SUSPENDED != 0 {
+		if err := ResumeProcess(c.Process.Pid); err != nil {
+			log.Printf("E! [agent] Error resuming process: %s", err)
+		}
+	}
+
 	timer := time.AfterFunc(timeout, func() {
+		if job != 0 {
+			err := windows.TerminateJobObject(job, 1)
+			if err == nil {
+				return
+			}
+			log.Printf("E! [agent] Error terminating process tree: %s", err)
+		}
 		err := c.Process.Kill()
 		if err != nil {
 			log.Printf("E! [agent] Error killing process: %s", err)
@@ -20,7 +56,7 @@ func WaitTimeout(c *exec.Cmd, timeout time.Duration) error {
 		}
 	})
 
-	err := c.Wait()
+	err = c.Wait()
 
 	// Shutdown all timers
 	termSent := !timer.Stop()
@@ -39,3 +75,60 @@ func WaitTimeout(c *exec.Cmd, timeout time.Duration) error {
 	// Otherwise there was an error unrelated to termination.
 	return err
 }
+
+// AssignJobObject creates a new job object and assigns the process with the
+// given PID to it. Child processes started afterwards are part of the job, so
+// the whole process tree can be terminated using the returned handle. The
+// process should be created suspended and resumed using ResumeProcess after
+// the assignment to not miss any children. The caller is responsible for
+// closing the handle.
+func AssignJobObject(pid int) (windows.Handle, error) {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	process, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(pid))
+	if err != nil {
+		windows.CloseHandle(job) //nolint:errcheck // Ignore the error as we are already failing
+		return 0, err
+	}
+	defer windows.CloseHandle(process) //nolint:errcheck // Ignore the error as we cannot act on it
+
+	if err := windows.AssignProcessToJobObject(job, process); err != nil {
+		windows.CloseHandle(job) //nolint:errcheck // Ignore the error as we are already failing
+		return 0, err
+	}
+
+	return job, nil
+}
+
+// ResumeProcess resumes all threads of a process created with the
+// CREATE_SUSPENDED flag.
+func ResumeProcess(pid int) error {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPTHREAD, 0)
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(snapshot) //nolint:errcheck // Ignore the error as we cannot act on it
+
+	entry := windows.ThreadEntry32{Size: uint32(unsafe.Sizeof(windows.ThreadEntry32{}))}
+	for err = windows.Thread32First(snapshot, &entry); err == nil; err = windows.Thread32Next(snapshot, &entry) {
+		if entry.OwnerProcessID != uint32(pid) {
+			continue
+		}
+		thread, err := windows.OpenThread(windows.THREAD_SUSPEND_RESUME, false, entry.ThreadID)
+		if err != nil {
+			return err
+		}
+		_, err = windows.ResumeThread(thread)
+		windows.CloseHandle(thread) //nolint:errcheck // Ignore the error as we cannot act on it
+		if err != nil {
+			return err
+		}
+	}
+	if !errors.Is(err, windows.ERROR_NO_MORE_FILES) {
+		return err
+	}
+	return nil
+}
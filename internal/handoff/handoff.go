@@ -0,0 +1,360 @@
+// Package handoff allows a new Telegraf process to take over the listening
+// sockets and the buffered metrics of a running Telegraf process, e.g. during a
+// binary upgrade. The running process offers its listeners on a unix socket
+// and the new process receives them as file descriptors (SCM_RIGHTS), so
+// service inputs keep accepting data while the processes are swapped.
+package handoff
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// ErrNotSupported is returned if socket handoff is not available on the
+// current platform.
+var ErrNotSupported = errors.New("socket handoff is not supported on this platform")
+
+var (
+	mu sync.Mutex
+
+	// Listeners received from a predecessor process not yet claimed by a plugin
+	inherited = make(map[string]*os.File)
+
+	// Listeners opened by plugins of this process offered for handoff
+	active = make(map[string]filer)
+)
+
+type filer interface {
+	File() (*os.File, error)
+}
+
+// batch contains the serialized metrics buffered for a single output
+type batch struct {
+	Output  string
+	Metrics [][]byte
+}
+
+func key(network, address string) string {
+	return network + "://" + address
+}
+
+// Listen announces on the local network address like net.Listen. If a
+// predecessor process handed over a listener for the same network and address,
+// this listener is used instead of creating a new one. TCP listeners are
+// registered to be handed over to a successor process.
+func Listen(network, address string) (net.Listener, error) {
+	k := key(network, address)
+	if f := claim(k); f != nil {
+		l, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("using inherited listener for %q failed: %w", k, err)
+		}
+		register(k, l)
+		return l, nil
+	}
+
+	l, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	register(k, l)
+	return l, nil
+}
+
+// ListenPacket announces on the local network address like net.ListenPacket.
+// If a predecessor process handed over a connection for the same network and
+// address, this connection is used instead of creating a new one. UDP
+// connections are registered to be handed over to a successor process.
+func ListenPacket(network, address string) (net.PacketConn, error) {
+	k := key(network, address)
+	if f := claim(k); f != nil {
+		conn, err := net.FilePacketConn(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("using inherited connection for %q failed: %w", k, err)
+		}
+		register(k, conn)
+		return conn, nil
+	}
+
+	conn, err := net.ListenPacket(network, address)
+	if err != nil {
+		return nil, err
+	}
+	register(k, conn)
+	return conn, nil
+}
+
+// Inherited returns the number of listeners received from a predecessor and
+// not yet claimed by a plugin.
+func Inherited() int {
+	mu.Lock()
+	defer mu.Unlock()
+	return len(inherited)
+}
+
+// ReleaseInherited closes all listeners received from a predecessor that were
+// not claimed by any plugin, e.g. because the configuration changed.
+func ReleaseInherited() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for k, f := range inherited {
+		f.Close()
+		delete(inherited, k)
+	}
+}
+
+func claim(k string) *os.File {
+	mu.Lock()
+	defer mu.Unlock()
+
+	f, found := inherited[k]
+	if found {
+		delete(inherited, k)
+	}
+	return f
+}
+
+func register(k string, l interface{}) {
+	// Unix sockets are removed from the filesystem when being closed by the
+	// predecessor, so we cannot hand them over.
+	switch l.(type) {
+	case *net.TCPListener, *net.UDPConn:
+	default:
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	active[k] = l.(filer)
+}
+
+// snapshot returns duplicates of the file descriptors of all open listeners
+// registered for handoff, ordered by their key.
+func snapshot() ([]string, []*os.File) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	keys := make([]string, 0, len(active))
+	for k := range active {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	names := make([]string, 0, len(keys))
+	files := make([]*os.File, 0, len(keys))
+	for _, k := range keys {
+		// Closed listeners fail to return a file and are skipped
+		f, err := active[k].File()
+		if err != nil {
+			delete(active, k)
+			continue
+		}
+		names = append(names, k)
+		files = append(files, f)
+	}
+	return names, files
+}
+
+func inherit(k string, f *os.File) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if prev, found := inherited[k]; found {
+		prev.Close()
+	}
+	inherited[k] = f
+}
+
+// Server offers the listeners of this process to a successor process. Only a
+// single handoff is served, afterwards the process is expected to shut down
+// and to pass its remaining buffered metrics using SendMetrics.
+type Server struct {
+	path      string
+	listener  *net.UnixListener
+	onHandoff func()
+
+	mu      sync.Mutex
+	conn    *net.UnixConn
+	encoder *gob.Encoder
+	done    chan struct{}
+}
+
+// NewServer listens on the unix socket at the given path and calls onHandoff
+// once the listeners were passed to a successor process. An existing socket
+// file at the path is replaced.
+func NewServer(path string, onHandoff func()) (*Server, error) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("removing stale handoff socket failed: %w", err)
+	}
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, fmt.Errorf("listening on handoff socket failed: %w", err)
+	}
+	// The socket file is owned by the successor after a handoff so we must not
+	// remove it on close.
+	listener.SetUnlinkOnClose(false)
+
+	s := &Server{
+		path:      path,
+		listener:  listener,
+		onHandoff: onHandoff,
+		done:      make(chan struct{}),
+	}
+	go s.serve()
+
+	return s, nil
+}
+
+func (s *Server) serve() {
+	defer close(s.done)
+
+	for {
+		conn, err := s.listener.AcceptUnix()
+		if err != nil {
+			return
+		}
+
+		names, files := snapshot()
+		err = sendListeners(conn, names, files)
+		for _, f := range files {
+			f.Close()
+		}
+		if err != nil {
+			conn.Close()
+			continue
+		}
+
+		s.mu.Lock()
+		s.conn = conn
+		s.encoder = gob.NewEncoder(conn)
+		s.mu.Unlock()
+
+		s.listener.Close()
+		if s.onHandoff != nil {
+			s.onHandoff()
+		}
+		return
+	}
+}
+
+// HandedOff returns true if the listeners were passed to a successor process.
+func (s *Server) HandedOff() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn != nil
+}
+
+// SendMetrics passes the given metrics buffered by an output to the successor
+// process. Tracking information is not transferred.
+func (s *Server) SendMetrics(output string, metrics []telegraf.Metric) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return errors.New("no successor connected")
+	}
+
+	b := batch{Output: output, Metrics: make([][]byte, 0, len(metrics))}
+	for _, m := range metrics {
+		buf, err := metric.ToBytes(metric.FromMetric(m))
+		if err != nil {
+			return err
+		}
+		b.Metrics = append(b.Metrics, buf)
+	}
+	return s.encoder.Encode(&b)
+}
+
+// Close stops serving and closes the connection to the successor.
+func (s *Server) Close() error {
+	s.listener.Close()
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+
+	// Only remove the socket file if no successor took it over
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Predecessor is the connection to the process which handed over its
+// listeners to the current process.
+type Predecessor struct {
+	conn *net.UnixConn
+}
+
+// Connect requests the listeners of the process serving at the given path.
+// The received listeners are used by Listen and ListenPacket. If no process
+// is serving at the path, nil is returned without error.
+func Connect(path string) (*Predecessor, error) {
+	conn, err := net.DialUnix("unix", nil, &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		if isNotServing(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("connecting to handoff socket failed: %w", err)
+	}
+
+	names, files, err := receiveListeners(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("receiving listeners failed: %w", err)
+	}
+	for i, name := range names {
+		inherit(name, files[i])
+	}
+
+	return &Predecessor{conn: conn}, nil
+}
+
+// ReceiveMetrics calls the given function for each batch of buffered metrics
+// sent by the predecessor until the predecessor closes the connection.
+func (p *Predecessor) ReceiveMetrics(fn func(output string, metrics []telegraf.Metric)) error {
+	decoder := gob.NewDecoder(p.conn)
+	for {
+		var b batch
+		if err := decoder.Decode(&b); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("receiving metrics failed: %w", err)
+		}
+
+		metrics := make([]telegraf.Metric, 0, len(b.Metrics))
+		for _, buf := range b.Metrics {
+			m, err := metric.FromBytes(buf)
+			if err != nil {
+				return fmt.Errorf("decoding metric failed: %w", err)
+			}
+			metrics = append(metrics, m)
+		}
+		fn(b.Output, metrics)
+	}
+}
+
+// Close closes the connection to the predecessor.
+func (p *Predecessor) Close() error {
+	return p.conn.Close()
+}
+
+func init() {
+	metric.Init()
+}
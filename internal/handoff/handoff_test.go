@@ -0,0 +1,94 @@
+//go:build !windows
+
+package handoff
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestConnectWithoutPredecessor(t *testing.T) {
+	predecessor, err := Connect(filepath.Join(t.TempDir(), "handoff.sock"))
+	require.NoError(t, err)
+	require.Nil(t, predecessor)
+}
+
+func TestHandoff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "handoff.sock")
+
+	// Setup the listeners of the "old" process
+	listener, err := Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+
+	conn, err := ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	handedOff := make(chan struct{})
+	server, err := NewServer(path, func() { close(handedOff) })
+	require.NoError(t, err)
+
+	// Take over the listeners in the "new" process
+	predecessor, err := Connect(path)
+	require.NoError(t, err)
+	require.NotNil(t, predecessor)
+	defer predecessor.Close()
+
+	select {
+	case <-handedOff:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "handoff callback not called")
+	}
+	require.True(t, server.HandedOff())
+	require.Equal(t, 2, Inherited())
+
+	// The old process closes its listener but the inherited one still accepts
+	// connections on the same address
+	require.NoError(t, listener.Close())
+	inherited, err := Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer inherited.Close()
+	require.Equal(t, address, inherited.Addr().String())
+	require.Equal(t, 1, Inherited())
+
+	go func() {
+		c, err := net.Dial("tcp", address)
+		if err == nil {
+			c.Close()
+		}
+	}()
+	c, err := inherited.Accept()
+	require.NoError(t, err)
+	c.Close()
+
+	// Unclaimed listeners are released
+	ReleaseInherited()
+	require.Zero(t, Inherited())
+
+	// Pass the buffered metrics
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"usage": 42.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"cpu": "cpu1"}, map[string]interface{}{"usage": 23.0}, time.Unix(0, 0)),
+	}
+	require.NoError(t, server.SendMetrics("output-id", expected))
+	require.NoError(t, server.Close())
+
+	var actual []telegraf.Metric
+	require.NoError(t, predecessor.ReceiveMetrics(func(output string, metrics []telegraf.Metric) {
+		require.Equal(t, "output-id", output)
+		actual = append(actual, metrics...)
+	}))
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// The socket file belongs to the successor and must not be removed
+	require.FileExists(t, path)
+}
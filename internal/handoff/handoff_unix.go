@@ -0,0 +1,90 @@
+//go:build !windows
+
+package handoff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Maximum number of file descriptors passed in a single message (SCM_MAX_FD)
+const maxFiles = 253
+
+func sendListeners(conn *net.UnixConn, names []string, files []*os.File) error {
+	if len(files) > maxFiles {
+		return fmt.Errorf("too many listeners (%d) for handoff", len(files))
+	}
+
+	header, err := json.Marshal(names)
+	if err != nil {
+		return err
+	}
+
+	fds := make([]int, 0, len(files))
+	for _, f := range files {
+		fds = append(fds, int(f.Fd()))
+	}
+	var rights []byte
+	if len(fds) > 0 {
+		rights = unix.UnixRights(fds...)
+	}
+
+	_, _, err = conn.WriteMsgUnix(header, rights, nil)
+	return err
+}
+
+func receiveListeners(conn *net.UnixConn) ([]string, []*os.File, error) {
+	buf := make([]byte, 64*1024)
+	oob := make([]byte, unix.CmsgSpace(maxFiles*4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fds []int
+	if oobn > 0 {
+		msgs, err := unix.ParseSocketControlMessage(oob[:oobn])
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing control message failed: %w", err)
+		}
+		for i := range msgs {
+			rights, err := unix.ParseUnixRights(&msgs[i])
+			if err != nil {
+				return nil, nil, fmt.Errorf("parsing rights failed: %w", err)
+			}
+			fds = append(fds, rights...)
+		}
+	}
+
+	closeAll := func() {
+		for _, fd := range fds {
+			unix.Close(fd)
+		}
+	}
+
+	var names []string
+	if err := json.Unmarshal(buf[:n], &names); err != nil {
+		closeAll()
+		return nil, nil, fmt.Errorf("decoding header failed: %w", err)
+	}
+	if len(names) != len(fds) {
+		closeAll()
+		return nil, nil, fmt.Errorf("received %d listeners but %d descriptors", len(names), len(fds))
+	}
+
+	files := make([]*os.File, 0, len(fds))
+	for i, fd := range fds {
+		files = append(files, os.NewFile(uintptr(fd), names[i]))
+	}
+	return names, files, nil
+}
+
+func isNotServing(err error) bool {
+	return errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ECONNREFUSED)
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package handoff
+
+import (
+	"net"
+	"os"
+)
+
+func sendListeners(*net.UnixConn, []string, []*os.File) error {
+	return ErrNotSupported
+}
+
+func receiveListeners(*net.UnixConn) ([]string, []*os.File, error) {
+	return nil, nil, ErrNotSupported
+}
+
+func isNotServing(error) bool {
+	return false
+}
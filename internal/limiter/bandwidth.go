@@ -0,0 +1,159 @@
+package limiter
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// BandwidthLimitedPlugin is implemented by plugins supporting a limit of the
+// egress bandwidth.
+type BandwidthLimitedPlugin interface {
+	SetBandwidthLimiter(l *BandwidthLimiter)
+}
+
+// BandwidthLimiter is a token bucket limiting the number of bytes per second.
+// The limiter can be chained to a parent limiter, e.g. a global one, in which
+// case both limits are enforced.
+type BandwidthLimiter struct {
+	limit     float64
+	tokens    float64
+	last      time.Time
+	parent    *BandwidthLimiter
+	throttled selfstat.Stat
+
+	now   func() time.Time
+	sleep func(time.Duration)
+
+	sync.Mutex
+}
+
+// NewBandwidthLimiter creates a limiter for the given number of bytes per
+// second, a limit of zero means unlimited. The time spent waiting is recorded
+// in the given statistic, if any.
+func NewBandwidthLimiter(limit int64, parent *BandwidthLimiter, throttled selfstat.Stat) *BandwidthLimiter {
+	return &BandwidthLimiter{
+		limit:     float64(limit),
+		tokens:    float64(limit),
+		last:      time.Now(),
+		parent:    parent,
+		throttled: throttled,
+		now:       time.Now,
+		sleep:     time.Sleep,
+	}
+}
+
+// Wait blocks until sending n bytes is allowed by this and all parent limiters
+// and returns the time spent waiting.
+func (b *BandwidthLimiter) Wait(n int) time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	var delay time.Duration
+	for l := b; l != nil; l = l.parent {
+		delay = max(delay, l.reserve(n))
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	b.sleep(delay)
+	for l := b; l != nil; l = l.parent {
+		if l.throttled != nil {
+			l.throttled.Incr(int64(delay))
+		}
+	}
+	return delay
+}
+
+// reserve takes the tokens for n bytes from the bucket and returns the delay
+// until the bucket recovers. The bucket may go into debt so concurrent users
+// are served in order of their requests.
+func (b *BandwidthLimiter) reserve(n int) time.Duration {
+	if b.limit <= 0 {
+		return 0
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	now := b.now()
+	b.tokens = min(b.limit, b.tokens+now.Sub(b.last).Seconds()*b.limit)
+	b.last = now
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.limit * float64(time.Second))
+}
+
+// chunkSize returns the maximum number of bytes sent at once, i.e. the
+// bytes allowed within 100ms, to smoothen the traffic
+func (b *BandwidthLimiter) chunkSize() int {
+	size := 0
+	for l := b; l != nil; l = l.parent {
+		if l.limit <= 0 {
+			continue
+		}
+		if s := max(int(l.limit/10), 1); size == 0 || s < size {
+			size = s
+		}
+	}
+	return size
+}
+
+// Conn wraps the connection to limit the bandwidth of writes
+func (b *BandwidthLimiter) Conn(c net.Conn) net.Conn {
+	if b == nil {
+		return c
+	}
+
+	// Splitting writes on packet connections would split the datagrams
+	_, packet := c.(net.PacketConn)
+	return &limitedConn{Conn: c, limiter: b, packet: packet}
+}
+
+// DialContext wraps the dial function to limit the bandwidth of the
+// connections created
+func (b *BandwidthLimiter) DialContext(
+	dial func(ctx context.Context, network, address string) (net.Conn, error),
+) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		c, err := dial(ctx, network, address)
+		if err != nil {
+			return nil, err
+		}
+		return b.Conn(c), nil
+	}
+}
+
+type limitedConn struct {
+	net.Conn
+	limiter *BandwidthLimiter
+	packet  bool
+}
+
+func (c *limitedConn) Write(p []byte) (int, error) {
+	size := c.limiter.chunkSize()
+	if size == 0 || c.packet {
+		c.limiter.Wait(len(p))
+		return c.Conn.Write(p)
+	}
+
+	var written int
+	for len(p) > 0 {
+		chunk := p[:min(size, len(p))]
+		c.limiter.Wait(len(chunk))
+		n, err := c.Conn.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
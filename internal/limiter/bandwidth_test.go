@@ -0,0 +1,85 @@
+package limiter
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+func TestBandwidthLimiterWait(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	var slept time.Duration
+
+	l := NewBandwidthLimiter(1000, nil, nil)
+	l.now = func() time.Time { return now }
+	l.sleep = func(d time.Duration) { slept += d }
+	l.last = now
+
+	// The initial bucket allows a full second of traffic
+	require.Zero(t, l.Wait(1000))
+
+	// Further data has to wait for the bucket to refill
+	require.Equal(t, 500*time.Millisecond, l.Wait(500))
+	require.Equal(t, time.Second, l.Wait(500))
+	require.Equal(t, 1500*time.Millisecond, slept)
+
+	// Waiting time refills the bucket
+	now = now.Add(2 * time.Second)
+	require.Zero(t, l.Wait(1000))
+}
+
+func TestBandwidthLimiterParent(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	stat := selfstat.Register("test", "egress_throttle_ns", map[string]string{"limiter": "global"})
+
+	global := NewBandwidthLimiter(100, nil, stat)
+	global.now = func() time.Time { return now }
+	global.last = now
+
+	l := NewBandwidthLimiter(0, global, nil)
+	l.now = func() time.Time { return now }
+	l.sleep = func(time.Duration) {}
+
+	require.Zero(t, l.Wait(100))
+	require.Equal(t, time.Second, l.Wait(100))
+	require.Equal(t, int64(time.Second), stat.Get())
+	require.Equal(t, 10, l.chunkSize())
+}
+
+func TestBandwidthLimiterConn(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	l := NewBandwidthLimiter(10, nil, nil)
+	l.sleep = func(time.Duration) {}
+	conn := l.Conn(client)
+
+	// Writes must be split into chunks of the allowed bytes per 100ms
+	go func() {
+		n, err := conn.Write([]byte("hello world"))
+		if err != nil || n != 11 {
+			t.Errorf("unexpected write result %d: %v", n, err)
+		}
+	}()
+
+	buf := make([]byte, 16)
+	for _, expected := range []string{"h", "e", "l", "l", "o"} {
+		n, err := server.Read(buf)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(buf[:n]))
+	}
+	rest := make([]byte, 6)
+	_, err := io.ReadFull(server, rest)
+	require.NoError(t, err)
+	require.Equal(t, " world", string(rest))
+
+	// A nil limiter does not wrap the connection
+	var unlimited *BandwidthLimiter
+	require.Equal(t, client, unlimited.Conn(client))
+}
@@ -30,6 +30,7 @@ type Process struct {
 	args       []string
 	envs       []string
 	pid        int32
+	tree       processTree
 	cancel     context.CancelFunc
 	mainLoopWg sync.WaitGroup
 
@@ -104,6 +105,7 @@ func (p *Process) State() (state *os.ProcessState, running bool) {
 
 func (p *Process) cmdStart() error {
 	p.Cmd = exec.Command(p.name, p.args...)
+	p.tree.prepare(p.Cmd)
 
 	if len(p.envs) > 0 {
 		p.Cmd.Env = append(os.Environ(), p.envs...)
@@ -131,6 +133,11 @@ func (p *Process) cmdStart() error {
 		return fmt.Errorf("error starting process: %w", err)
 	}
 	atomic.StoreInt32(&p.pid, int32(p.Cmd.Process.Pid))
+
+	if err := p.tree.attach(p.Cmd); err != nil {
+		p.Log.Warnf("Tracking child processes failed, they might not be terminated on stop: %v", err)
+	}
+
 	return nil
 }
 
@@ -202,6 +209,7 @@ func (p *Process) cmdWait(ctx context.Context) error {
 	p.Unlock()
 	processCancel()
 	wg.Wait()
+	p.tree.release()
 	return err
 }
 
@@ -9,16 +9,37 @@ import (
 	"time"
 )
 
+// processTree runs the process in its own process group so the process and
+// all of its children can be signaled at once
+type processTree struct{}
+
+func (*processTree) prepare(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func (*processTree) attach(*exec.Cmd) error {
+	return nil
+}
+
+func (*processTree) release() {}
+
 func (p *Process) gracefulStop(ctx context.Context, cmd *exec.Cmd, timeout time.Duration) {
 	select {
 	case <-time.After(timeout):
 		if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
 			p.Log.Errorf("Error after sending SIGTERM signal to process: %v", err)
 		}
+		// Also terminate the children of the process
+		if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM); err != nil {
+			p.Log.Debugf("Error after sending SIGTERM signal to process group: %v", err)
+		}
 	case <-ctx.Done():
 	}
 	select {
 	case <-time.After(timeout):
+		if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+			p.Log.Debugf("Error after killing process group: %v", err)
+		}
 		if err := cmd.Process.Kill(); err != nil {
 			p.Log.Errorf("Error after killing process: %v", err)
 		}
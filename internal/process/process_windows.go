@@ -4,13 +4,62 @@ package process
 
 import (
 	"context"
+	"fmt"
 	"os/exec"
+	"syscall"
 	"time"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/influxdata/telegraf/internal"
 )
 
+// processTree groups the process and all of its children in a job object so
+// the whole tree can be terminated at once
+type processTree struct {
+	job windows.Handle
+}
+
+// prepare creates the process suspended so it cannot spawn any children
+// before being assigned to the job object in attach
+func (*processTree) prepare(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: windows.CREATE_SUSPENDED}
+}
+
+func (t *processTree) attach(cmd *exec.Cmd) error {
+	job, err := internal.AssignJobObject(cmd.Process.Pid)
+	if rerr := internal.ResumeProcess(cmd.Process.Pid); rerr != nil {
+		// Kill the process as it would never run otherwise
+		cmd.Process.Kill() //nolint:errcheck // Ignore the error as we are already failing
+		if job != 0 {
+			windows.CloseHandle(job) //nolint:errcheck // Ignore the error as we are already failing
+		}
+		return fmt.Errorf("resuming process failed: %w", rerr)
+	}
+	if err != nil {
+		return err
+	}
+	t.job = job
+	return nil
+}
+
+func (t *processTree) release() {
+	if t.job != 0 {
+		windows.CloseHandle(t.job) //nolint:errcheck // Ignore the error as we cannot act on it
+		t.job = 0
+	}
+}
+
 func (p *Process) gracefulStop(ctx context.Context, cmd *exec.Cmd, timeout time.Duration) {
 	select {
 	case <-time.After(timeout):
+		if p.tree.job != 0 {
+			err := windows.TerminateJobObject(p.tree.job, 1)
+			if err == nil {
+				return
+			}
+			p.Log.Errorf("Error after terminating process tree: %v", err)
+		}
 		if err := cmd.Process.Kill(); err != nil {
 			p.Log.Errorf("Error after killing process: %v", err)
 		}
@@ -19,7 +19,8 @@ type ClientConfig struct {
 	Community string `toml:"community"`
 
 	// Parameters for Version 2 & 3
-	MaxRepetitions uint32 `toml:"max_repetitions"`
+	MaxRepetitions  uint32 `toml:"max_repetitions"`
+	BulkWalkColumns int    `toml:"bulk_walk_columns"`
 
 	// Parameters for Version 3
 	ContextName  string        `toml:"context_name"`
@@ -38,6 +39,40 @@ type ClientConfig struct {
 	Translator string   `toml:"-"`
 }
 
+// Credential is a set of SNMPv3 security parameters
+type Credential struct {
+	SecLevel     string        `toml:"sec_level"`
+	SecName      string        `toml:"sec_name"`
+	AuthProtocol string        `toml:"auth_protocol"`
+	AuthPassword config.Secret `toml:"auth_password"`
+	PrivProtocol string        `toml:"priv_protocol"`
+	PrivPassword config.Secret `toml:"priv_password"`
+}
+
+// Credential returns the SNMPv3 security parameters of the config
+func (c *ClientConfig) Credential() Credential {
+	return Credential{
+		SecLevel:     c.SecLevel,
+		SecName:      c.SecName,
+		AuthProtocol: c.AuthProtocol,
+		AuthPassword: c.AuthPassword,
+		PrivProtocol: c.PrivProtocol,
+		PrivPassword: c.PrivPassword,
+	}
+}
+
+// WithCredential returns a copy of the config using the given SNMPv3 security
+// parameters
+func (c ClientConfig) WithCredential(cred Credential) ClientConfig {
+	c.SecLevel = cred.SecLevel
+	c.SecName = cred.SecName
+	c.AuthProtocol = cred.AuthProtocol
+	c.AuthPassword = cred.AuthPassword
+	c.PrivProtocol = cred.PrivProtocol
+	c.PrivPassword = cred.PrivPassword
+	return c
+}
+
 func DefaultClientConfig() *ClientConfig {
 	return &ClientConfig{
 		Timeout:        config.Duration(5 * time.Second),
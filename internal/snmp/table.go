@@ -17,6 +17,10 @@ type Table struct {
 	// Which tags to inherit from the top-level config.
 	InheritTags []string
 
+	// SNMPv3 context to query the table in, overriding the context of the
+	// connection.
+	ContextName string `toml:"context_name"`
+
 	// Adds each row's table index as a tag.
 	IndexAsTag bool
 
@@ -118,6 +122,13 @@ func (t *Table) initBuild() error {
 
 // Build retrieves all the fields specified in the table and constructs the RTable.
 func (t Table) Build(gs Connection, walk bool) (*RTable, error) {
+	if t.ContextName != "" {
+		if cs, ok := gs.(ContextSwitcher); ok {
+			prev := cs.SetContextName(t.ContextName)
+			defer cs.SetContextName(prev)
+		}
+	}
+
 	rows := make(map[string]RTableRow)
 
 	// translation table for secondary index (when performing join on two tables)
@@ -134,6 +145,7 @@ func (t Table) Build(gs Connection, walk bool) (*RTable, error) {
 	}
 
 	tagCount := 0
+	oids := make([]string, 0, len(t.Fields))
 	for _, f := range t.Fields {
 		if f.IsTag {
 			tagCount++
@@ -142,78 +154,49 @@ func (t Table) Build(gs Connection, walk bool) (*RTable, error) {
 		if len(f.Oid) == 0 {
 			return nil, fmt.Errorf("cannot have empty OID on field %s", f.Name)
 		}
-		var oid string
 		if f.Oid[0] == '.' {
-			oid = f.Oid
+			oids = append(oids, f.Oid)
 		} else {
 			// make sure OID has "." because the BulkWalkAll results do, and the prefix needs to match
-			oid = "." + f.Oid
+			oids = append(oids, "."+f.Oid)
 		}
+	}
 
-		// ifv contains a mapping of table OID index to field value
-		ifv := make(map[string]interface{})
-
-		if !walk {
-			// This is used when fetching non-table fields. Fields configured a the top
-			// scope of the plugin.
-			// We fetch the fields directly, and add them to ifv as if the index were an
-			// empty string. This results in all the non-table fields sharing the same
-			// index, and being added on the same row.
-			if pkt, err := gs.Get([]string{oid}); err != nil {
-				if errors.Is(err, gosnmp.ErrUnknownSecurityLevel) {
-					return nil, errors.New("unknown security level (sec_level)")
-				} else if errors.Is(err, gosnmp.ErrUnknownUsername) {
-					return nil, errors.New("unknown username (sec_name)")
-				} else if errors.Is(err, gosnmp.ErrWrongDigest) {
-					return nil, errors.New("wrong digest (auth_protocol, auth_password)")
-				} else if errors.Is(err, gosnmp.ErrDecryption) {
-					return nil, errors.New("decryption error (priv_protocol, priv_password)")
-				}
-				return nil, fmt.Errorf("performing get on field %s: %w", f.Name, err)
-			} else if pkt != nil && len(pkt.Variables) > 0 && pkt.Variables[0].Type != gosnmp.NoSuchObject && pkt.Variables[0].Type != gosnmp.NoSuchInstance {
-				ent := pkt.Variables[0]
-				fv, err := f.Convert(ent)
-				if err != nil {
-					return nil, fmt.Errorf("converting %q (OID %s) for field %s: %w", ent.Value, ent.Name, f.Name, err)
-				}
-				ifv[""] = fv
-			}
-		} else {
-			err := gs.Walk(oid, func(ent gosnmp.SnmpPDU) error {
-				if len(ent.Name) <= len(oid) || ent.Name[:len(oid)+1] != oid+"." {
-					return &walkError{} // break the walk
-				}
-
-				idx := ent.Name[len(oid):]
-				if f.OidIndexSuffix != "" {
-					if !strings.HasSuffix(idx, f.OidIndexSuffix) {
-						// this entry doesn't match our OidIndexSuffix. skip it
-						return nil
-					}
-					idx = idx[:len(idx)-len(f.OidIndexSuffix)]
-				}
-				if f.OidIndexLength != 0 {
-					i := f.OidIndexLength + 1 // leading separator
-					idx = strings.Map(func(r rune) rune {
-						if r == '.' {
-							i--
-						}
-						if i < 1 {
-							return -1
-						}
-						return r
-					}, idx)
-				}
+	// values contains a mapping of table OID index to field value for each field
+	values := make([]map[string]interface{}, len(t.Fields))
+	for i := range values {
+		values[i] = make(map[string]interface{})
+	}
 
-				fv, err := f.Convert(ent)
-				if err != nil {
-					return &walkError{
-						msg: fmt.Sprintf("converting %q (OID %s) for field %s", ent.Value, ent.Name, f.Name),
-						err: err,
-					}
-				}
-				ifv[idx] = fv
-				return nil
+	switch {
+	case !walk:
+		// This is used when fetching non-table fields. Fields configured a the top
+		// scope of the plugin.
+		// We fetch the fields directly, and add them to the values as if the index
+		// were an empty string. This results in all the non-table fields sharing
+		// the same index, and being added on the same row.
+		for i, f := range t.Fields {
+			fv, found, err := getField(gs, f, oids[i])
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				values[i][""] = fv
+			}
+		}
+	default:
+		walkFn := func(i int, ent gosnmp.SnmpPDU) error {
+			return t.Fields[i].addWalkEntry(values[i], oids[i], ent)
+		}
+		if mw, ok := gs.(MultiWalker); ok {
+			if err := mw.MultiWalk(oids, walkFn); err != nil {
+				return nil, fmt.Errorf("performing bulk walk for table %s: %w", t.Name, err)
+			}
+			break
+		}
+		for i, f := range t.Fields {
+			err := gs.Walk(oids[i], func(ent gosnmp.SnmpPDU) error {
+				return walkFn(i, ent)
 			})
 			if err != nil {
 				// Our callback always wraps errors in a walkError.
@@ -225,7 +208,10 @@ func (t Table) Build(gs Connection, walk bool) (*RTable, error) {
 				}
 			}
 		}
+	}
 
+	for i, f := range t.Fields {
+		ifv := values[i]
 		for idx, v := range ifv {
 			if f.SecondaryIndexUse {
 				if newidx, ok := secIdxTab[idx]; ok {
@@ -290,6 +276,72 @@ func (t Table) Build(gs Connection, walk bool) (*RTable, error) {
 	return &rt, nil
 }
 
+// getField fetches the value of a non-table field
+func getField(gs Connection, f Field, oid string) (interface{}, bool, error) {
+	pkt, err := gs.Get([]string{oid})
+	if err != nil {
+		if errors.Is(err, gosnmp.ErrUnknownSecurityLevel) {
+			return nil, false, errors.New("unknown security level (sec_level)")
+		} else if errors.Is(err, gosnmp.ErrUnknownUsername) {
+			return nil, false, errors.New("unknown username (sec_name)")
+		} else if errors.Is(err, gosnmp.ErrWrongDigest) {
+			return nil, false, errors.New("wrong digest (auth_protocol, auth_password)")
+		} else if errors.Is(err, gosnmp.ErrDecryption) {
+			return nil, false, errors.New("decryption error (priv_protocol, priv_password)")
+		}
+		return nil, false, fmt.Errorf("performing get on field %s: %w", f.Name, err)
+	}
+	if pkt == nil || len(pkt.Variables) == 0 || pkt.Variables[0].Type == gosnmp.NoSuchObject || pkt.Variables[0].Type == gosnmp.NoSuchInstance {
+		return nil, false, nil
+	}
+
+	ent := pkt.Variables[0]
+	fv, err := f.Convert(ent)
+	if err != nil {
+		return nil, false, fmt.Errorf("converting %q (OID %s) for field %s: %w", ent.Value, ent.Name, f.Name, err)
+	}
+	return fv, true, nil
+}
+
+// addWalkEntry adds the value of a walked entry to the mapping of table OID
+// index to field value. A walkError is returned to stop walking the field.
+func (f Field) addWalkEntry(ifv map[string]interface{}, oid string, ent gosnmp.SnmpPDU) error {
+	if len(ent.Name) <= len(oid) || ent.Name[:len(oid)+1] != oid+"." {
+		return &walkError{} // break the walk
+	}
+
+	idx := ent.Name[len(oid):]
+	if f.OidIndexSuffix != "" {
+		if !strings.HasSuffix(idx, f.OidIndexSuffix) {
+			// this entry doesn't match our OidIndexSuffix. skip it
+			return nil
+		}
+		idx = idx[:len(idx)-len(f.OidIndexSuffix)]
+	}
+	if f.OidIndexLength != 0 {
+		i := f.OidIndexLength + 1 // leading separator
+		idx = strings.Map(func(r rune) rune {
+			if r == '.' {
+				i--
+			}
+			if i < 1 {
+				return -1
+			}
+			return r
+		}, idx)
+	}
+
+	fv, err := f.Convert(ent)
+	if err != nil {
+		return &walkError{
+			msg: fmt.Sprintf("converting %q (OID %s) for field %s", ent.Value, ent.Name, f.Name),
+			err: err,
+		}
+	}
+	ifv[idx] = fv
+	return nil
+}
+
 type walkError struct {
 	msg string
 	err error
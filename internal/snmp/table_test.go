@@ -3,6 +3,7 @@ package snmp
 import (
 	"testing"
 
+	"github.com/gosnmp/gosnmp"
 	"github.com/stretchr/testify/require"
 )
 
@@ -244,3 +245,43 @@ func TestTableJoinNoIndexAsTag_walk(t *testing.T) {
 	require.Contains(t, tb.Rows, rtr2)
 	require.Contains(t, tb.Rows, rtr3)
 }
+
+// contextSNMPConnection records the context name used for each request
+type contextSNMPConnection struct {
+	*testSNMPConnection
+	context  string
+	contexts []string
+}
+
+func (c *contextSNMPConnection) SetContextName(name string) string {
+	prev := c.context
+	c.context = name
+	return prev
+}
+
+func (c *contextSNMPConnection) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	c.contexts = append(c.contexts, c.context)
+	return c.testSNMPConnection.Get(oids)
+}
+
+func TestTableBuild_contextName(t *testing.T) {
+	tbl := Table{
+		Name:        "mytable",
+		ContextName: "vlan-10",
+		Fields: []Field{
+			{
+				Name: "myfield1",
+				Oid:  ".1.0.0.1.1",
+			},
+		},
+	}
+
+	conn := &contextSNMPConnection{testSNMPConnection: tsc, context: "default"}
+	tb, err := tbl.Build(conn, false)
+	require.NoError(t, err)
+	require.Len(t, tb.Rows, 1)
+
+	// The context is only changed while building the table
+	require.Equal(t, []string{"vlan-10"}, conn.contexts)
+	require.Equal(t, "default", conn.context)
+}
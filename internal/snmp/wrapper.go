@@ -23,9 +23,24 @@ type Connection interface {
 	Reconnect() error
 }
 
+// MultiWalker is implemented by connections able to walk multiple subtrees
+// at once.
+type MultiWalker interface {
+	MultiWalk(oids []string, fn func(int, gosnmp.SnmpPDU) error) error
+}
+
+// ContextSwitcher is implemented by connections able to change the SNMPv3
+// context of subsequent requests.
+type ContextSwitcher interface {
+	SetContextName(name string) string
+}
+
 // GosnmpWrapper wraps a *gosnmp.GoSNMP object so we can use it as a snmpConnection.
 type GosnmpWrapper struct {
 	*gosnmp.GoSNMP
+
+	// Number of subtrees to walk with a single GETBULK request
+	bulkColumns int
 }
 
 // Host returns the value of GoSNMP.Target.
@@ -33,6 +48,14 @@ func (gs GosnmpWrapper) Host() string {
 	return gs.Target
 }
 
+// SetContextName sets the SNMPv3 context name of subsequent requests and
+// returns the previous context name.
+func (gs GosnmpWrapper) SetContextName(name string) string {
+	prev := gs.ContextName
+	gs.ContextName = name
+	return prev
+}
+
 // Walk wraps GoSNMP.Walk() or GoSNMP.BulkWalk(), depending on whether the
 // connection is using SNMPv1 or newer.
 func (gs GosnmpWrapper) Walk(oid string, fn gosnmp.WalkFunc) error {
@@ -42,6 +65,127 @@ func (gs GosnmpWrapper) Walk(oid string, fn gosnmp.WalkFunc) error {
 	return gs.GoSNMP.BulkWalk(oid, fn)
 }
 
+// MultiWalk walks the subtrees of all given OIDs and calls the given function
+// with the index of the OID the entry belongs to. For SNMPv2c and v3, up to
+// BulkWalkColumns subtrees are walked in lock-step by requesting the next
+// entries of all those subtrees with a single GETBULK request. Returning a
+// walkError from the function stops walking the respective subtree only.
+func (gs GosnmpWrapper) MultiWalk(oids []string, fn func(int, gosnmp.SnmpPDU) error) error {
+	columns := gs.bulkColumns
+	if maxOids := gs.MaxOids; maxOids > 0 && columns > maxOids {
+		columns = maxOids
+	}
+
+	if gs.Version == gosnmp.Version1 || columns <= 1 {
+		for i, oid := range oids {
+			err := gs.Walk(oid, func(ent gosnmp.SnmpPDU) error {
+				return fn(i, ent)
+			})
+			var walkErr *walkError
+			if err != nil && !errors.As(err, &walkErr) {
+				return fmt.Errorf("walking %s: %w", oid, err)
+			}
+		}
+		return nil
+	}
+
+	for start := 0; start < len(oids); start += columns {
+		end := min(start+columns, len(oids))
+		if err := gs.walkLockstep(oids, start, end, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkLockstep walks the subtrees of oids[start:end] by requesting the next
+// entries of all unfinished subtrees at once
+func (gs GosnmpWrapper) walkLockstep(oids []string, start, end int, fn func(int, gosnmp.SnmpPDU) error) error {
+	type column struct {
+		index int
+		root  string
+		next  string
+	}
+
+	active := make([]column, 0, end-start)
+	for i := start; i < end; i++ {
+		root := oids[i]
+		if !strings.HasPrefix(root, ".") {
+			root = "." + root
+		}
+		active = append(active, column{index: i, root: root, next: root})
+	}
+
+	repetitions := gs.MaxRepetitions
+	if repetitions == 0 {
+		repetitions = 10
+	}
+
+	for len(active) > 0 {
+		request := make([]string, 0, len(active))
+		for _, c := range active {
+			request = append(request, c.next)
+		}
+
+		pkt, err := gs.GetBulk(request, 0, repetitions)
+		if err != nil {
+			return fmt.Errorf("walking %s: %w", strings.Join(request, ","), err)
+		}
+		if pkt.Error == gosnmp.TooBig && repetitions > 1 {
+			// Retry with fewer entries per subtree
+			repetitions /= 2
+			continue
+		}
+		if pkt.Error != gosnmp.NoError {
+			return fmt.Errorf("walking %s: %s", strings.Join(request, ","), pkt.Error)
+		}
+		if len(pkt.Variables) == 0 {
+			return nil
+		}
+
+		// The response contains the entries of the repetitions in order,
+		// each repetition containing one entry per requested subtree.
+		finished := make([]bool, len(active))
+		for i, ent := range pkt.Variables {
+			n := i % len(active)
+			if finished[n] {
+				continue
+			}
+
+			c := &active[n]
+			switch {
+			case ent.Type == gosnmp.EndOfMibView, ent.Type == gosnmp.NoSuchObject, ent.Type == gosnmp.NoSuchInstance:
+				finished[n] = true
+				continue
+			case !strings.HasPrefix(ent.Name, c.root+"."), ent.Name == c.next:
+				// Left the subtree or the agent does not make progress
+				finished[n] = true
+				continue
+			}
+
+			if err := fn(c.index, ent); err != nil {
+				var walkErr *walkError
+				if !errors.As(err, &walkErr) {
+					return err
+				}
+				finished[n] = true
+				continue
+			}
+			c.next = ent.Name
+		}
+
+		remaining := active[:0]
+		for n, c := range active {
+			if !finished[n] {
+				remaining = append(remaining, c)
+			}
+		}
+		active = remaining
+	}
+
+	return nil
+}
+
 type debugLogger struct {
 	telegraf.Logger
 }
@@ -54,7 +198,7 @@ func (l *debugLogger) Printf(format string, v ...interface{}) {
 }
 
 func NewWrapper(s ClientConfig) (GosnmpWrapper, error) {
-	gs := GosnmpWrapper{&gosnmp.GoSNMP{}}
+	gs := GosnmpWrapper{GoSNMP: &gosnmp.GoSNMP{}}
 
 	gs.Timeout = time.Duration(s.Timeout)
 
@@ -86,6 +230,7 @@ func NewWrapper(s ClientConfig) (GosnmpWrapper, error) {
 	}
 
 	gs.MaxRepetitions = s.MaxRepetitions
+	gs.bulkColumns = s.BulkWalkColumns
 
 	if s.Version == 3 {
 		gs.ContextName = s.ContextName
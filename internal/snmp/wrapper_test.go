@@ -1,6 +1,213 @@
 package snmp
 
-import "github.com/gosnmp/gosnmp"
+import (
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+func TestMultiWalk(t *testing.T) {
+	tests := []struct {
+		name     string
+		columns  int
+		requests int
+	}{
+		{
+			name:     "sequential",
+			columns:  1,
+			requests: 6,
+		},
+		{
+			name:     "lock-step",
+			columns:  10,
+			requests: 2,
+		},
+		{
+			name:     "lock-step chunked",
+			columns:  2,
+			requests: 4,
+		},
+	}
+
+	tbl := Table{
+		Name:       "mytable",
+		IndexAsTag: true,
+		Fields: []Field{
+			{
+				Name:  "myfield1",
+				Oid:   ".1.0.0.3.1.1",
+				IsTag: true,
+			},
+			{
+				Name: "myfield2",
+				Oid:  ".1.0.0.3.1.2",
+			},
+			{
+				Name: "myfield3",
+				Oid:  "1.0.0.3.1.3",
+			},
+		},
+	}
+
+	expected := []RTableRow{
+		{
+			Tags:   map[string]string{"myfield1": "instance", "index": "10"},
+			Fields: map[string]interface{}{"myfield2": 10, "myfield3": 1},
+		},
+		{
+			Tags:   map[string]string{"myfield1": "instance2", "index": "11"},
+			Fields: map[string]interface{}{"myfield2": 20, "myfield3": 2},
+		},
+		{
+			Tags:   map[string]string{"myfield1": "instance3", "index": "12"},
+			Fields: map[string]interface{}{"myfield2": 20, "myfield3": 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := newTestAgent(t, tsc.values)
+
+			gs, err := NewWrapper(ClientConfig{
+				Version:         2,
+				Community:       "public",
+				Timeout:         config.Duration(5 * time.Second),
+				MaxRepetitions:  2,
+				BulkWalkColumns: tt.columns,
+			})
+			require.NoError(t, err)
+			require.NoError(t, gs.SetAgent("udp://"+agent.addr))
+			require.NoError(t, gs.Connect())
+			defer gs.Conn.Close()
+
+			rt, err := tbl.Build(gs, true)
+			require.NoError(t, err)
+			require.ElementsMatch(t, expected, rt.Rows)
+			require.Equal(t, tt.requests, agent.count())
+		})
+	}
+}
+
+// testAgent is a minimal SNMP agent answering GETBULK requests on UDP
+type testAgent struct {
+	addr string
+	oids []string
+	vals map[string]interface{}
+
+	requests int
+	sync.Mutex
+}
+
+func newTestAgent(t *testing.T, values map[string]interface{}) *testAgent {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	a := &testAgent{addr: conn.LocalAddr().String(), vals: values}
+	for oid := range values {
+		a.oids = append(a.oids, oid)
+	}
+	slices.SortFunc(a.oids, compareOID)
+
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			pkt, err := gosnmp.Default.SnmpDecodePacket(buf[:n])
+			if err != nil {
+				continue
+			}
+			resp, err := a.handle(pkt).MarshalMsg()
+			if err != nil {
+				continue
+			}
+			if _, err := conn.WriteTo(resp, addr); err != nil {
+				return
+			}
+		}
+	}()
+
+	return a
+}
+
+func (a *testAgent) count() int {
+	a.Lock()
+	defer a.Unlock()
+	return a.requests
+}
+
+func (a *testAgent) handle(pkt *gosnmp.SnmpPacket) *gosnmp.SnmpPacket {
+	a.Lock()
+	a.requests++
+	a.Unlock()
+
+	current := make([]string, 0, len(pkt.Variables))
+	for _, v := range pkt.Variables {
+		current = append(current, v.Name)
+	}
+
+	var variables []gosnmp.SnmpPDU
+	for r := uint32(0); r < pkt.MaxRepetitions; r++ {
+		for i, oid := range current {
+			idx, _ := slices.BinarySearchFunc(a.oids, oid, compareOID)
+			if idx < len(a.oids) && a.oids[idx] == oid {
+				idx++
+			}
+			if idx >= len(a.oids) {
+				variables = append(variables, gosnmp.SnmpPDU{Name: oid, Type: gosnmp.EndOfMibView})
+				continue
+			}
+			next := a.oids[idx]
+			variables = append(variables, testPDU(next, a.vals[next]))
+			current[i] = next
+		}
+	}
+
+	return &gosnmp.SnmpPacket{
+		Version:   pkt.Version,
+		Community: pkt.Community,
+		PDUType:   gosnmp.GetResponse,
+		RequestID: pkt.RequestID,
+		Variables: variables,
+	}
+}
+
+func testPDU(oid string, v interface{}) gosnmp.SnmpPDU {
+	switch v := v.(type) {
+	case int:
+		return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.Integer, Value: v}
+	case string:
+		return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.OctetString, Value: []byte(v)}
+	case []byte:
+		return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.OctetString, Value: v}
+	}
+	return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.Null}
+}
+
+func compareOID(a, b string) int {
+	pa := strings.Split(strings.TrimPrefix(a, "."), ".")
+	pb := strings.Split(strings.TrimPrefix(b, "."), ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, _ := strconv.Atoi(pa[i])
+		nb, _ := strconv.Atoi(pb[i])
+		if na != nb {
+			return na - nb
+		}
+	}
+	return len(pa) - len(pb)
+}
 
 type testSNMPConnection struct {
 	host   string
@@ -25,6 +25,12 @@ func (e *Engine) Apply(line string) (measurementName string, tags map[string]str
 	return e.matcher.match(line).Apply(line, e.joiner)
 }
 
+// Match returns the template matching the given line. If no template matches,
+// the default template is returned which might be nil.
+func (e *Engine) Match(line string) *Template {
+	return e.matcher.match(line)
+}
+
 // NewEngine creates a new templating engine
 func NewEngine(joiner string, defaultTemplate *Template, templates []string) (*Engine, error) {
 	engine := Engine{
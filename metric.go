@@ -151,6 +151,26 @@ type UnwrappableMetric interface {
 	Unwrap() Metric
 }
 
+// RoutedMetric is implemented by metrics carrying the name of the route they
+// were assigned to by the input plugin's `route` setting.
+type RoutedMetric interface {
+	// Route returns the name of the route or an empty string if the metric
+	// is not assigned to a route
+	Route() string
+	// SetRoute assigns the metric to the given route
+	SetRoute(route string)
+}
+
+// PrioritizedMetric is implemented by metrics carrying a priority class used
+// by the output buffers to decide which metrics to evict first when full.
+type PrioritizedMetric interface {
+	// Priority returns the priority class of the metric with zero being the
+	// normal priority
+	Priority() int
+	// SetPriority assigns the metric to the given priority class
+	SetPriority(priority int)
+}
+
 type TrackingMetric interface {
 	// TrackingID returns the ID used for tracking the metric
 	TrackingID() TrackingID
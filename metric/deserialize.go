@@ -25,8 +25,9 @@ var (
 )
 
 type serializedMetric struct {
-	M   telegraf.Metric
-	TID telegraf.TrackingID
+	M      telegraf.Metric
+	TID    telegraf.TrackingID
+	Output string
 }
 
 func ToBytes(m telegraf.Metric) ([]byte, error) {
@@ -39,6 +40,9 @@ func ToBytes(m telegraf.Metric) ([]byte, error) {
 
 	if tm, ok := m.(telegraf.TrackingMetric); ok {
 		sm.TID = tm.TrackingID()
+		if dm, ok := m.(*trackingMetric); ok {
+			sm.Output = dm.output
+		}
 		mu.Lock()
 		trackingStore[sm.TID] = tm.TrackingData()
 		mu.Unlock()
@@ -77,5 +81,5 @@ func FromBytes(b []byte) (telegraf.Metric, error) {
 	}
 
 	// Add back the tracking information to the metric
-	return &trackingMetric{Metric: sm.M, d: td.(*trackingData)}, nil
+	return &trackingMetric{Metric: sm.M, d: td.(*trackingData), output: sm.Output}, nil
 }
@@ -6,6 +6,7 @@ import (
 	"slices"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -18,6 +19,14 @@ type metric struct {
 	MetricTime   time.Time
 
 	MetricType telegraf.ValueType
+
+	route    string
+	priority int
+
+	// pooled is set for metrics allocated from the pool and sharedTags holds
+	// the number of copies referencing the same tag list; see EnablePooling.
+	pooled     bool
+	sharedTags *atomic.Int32
 }
 
 func New(
@@ -34,16 +43,10 @@ func New(
 		vtype = telegraf.Untyped
 	}
 
-	m := &metric{
-		MetricName:   name,
-		MetricTags:   nil,
-		MetricFields: nil,
-		MetricTime:   tm,
-		MetricType:   vtype,
-	}
+	m := newMetric(name, tm, vtype)
 
 	if len(tags) > 0 {
-		m.MetricTags = make([]*telegraf.Tag, 0, len(tags))
+		m.MetricTags = slices.Grow(m.MetricTags, len(tags))
 		for k, v := range tags {
 			m.MetricTags = append(m.MetricTags,
 				&telegraf.Tag{Key: k, Value: v})
@@ -52,7 +55,7 @@ func New(
 	}
 
 	if len(fields) > 0 {
-		m.MetricFields = make([]*telegraf.Field, 0, len(fields))
+		m.MetricFields = slices.Grow(m.MetricFields, len(fields))
 		for k, v := range fields {
 			v := convertField(v)
 			if v == nil {
@@ -84,6 +87,13 @@ func FromMetric(other telegraf.Metric) telegraf.Metric {
 	for i, field := range other.FieldList() {
 		m.MetricFields[i] = &telegraf.Field{Key: field.Key, Value: field.Value}
 	}
+
+	if rm, ok := other.(telegraf.RoutedMetric); ok {
+		m.route = rm.Route()
+	}
+	if pm, ok := other.(telegraf.PrioritizedMetric); ok {
+		m.priority = pm.Priority()
+	}
 	return m
 }
 
@@ -104,6 +114,8 @@ func (m *metric) Tags() map[string]string {
 }
 
 func (m *metric) TagList() []*telegraf.Tag {
+	// The returned tags might be modified in place
+	m.ownTags()
 	return m.MetricTags
 }
 
@@ -124,6 +136,14 @@ func (m *metric) Time() time.Time {
 	return m.MetricTime
 }
 
+func (m *metric) Route() string {
+	return m.route
+}
+
+func (m *metric) Priority() int {
+	return m.priority
+}
+
 func (m *metric) Type() telegraf.ValueType {
 	return m.MetricType
 }
@@ -141,6 +161,7 @@ func (m *metric) AddSuffix(suffix string) {
 }
 
 func (m *metric) AddTag(key, value string) {
+	m.ownTags()
 	for i, tag := range m.MetricTags {
 		if key > tag.Key {
 			continue
@@ -184,6 +205,7 @@ func (m *metric) Tag(key string) string {
 }
 
 func (m *metric) RemoveTag(key string) {
+	m.ownTags()
 	for i, tag := range m.MetricTags {
 		if tag.Key == key {
 			copy(m.MetricTags[i:], m.MetricTags[i+1:])
@@ -244,17 +266,31 @@ func (m *metric) SetTime(t time.Time) {
 	m.MetricTime = t
 }
 
+func (m *metric) SetRoute(route string) {
+	m.route = route
+}
+
+func (m *metric) SetPriority(priority int) {
+	m.priority = priority
+}
+
 func (m *metric) SetType(t telegraf.ValueType) {
 	m.MetricType = t
 }
 
 func (m *metric) Copy() telegraf.Metric {
+	if pooling.Load() {
+		return m.pooledCopy()
+	}
+
 	m2 := &metric{
 		MetricName:   m.MetricName,
 		MetricTags:   make([]*telegraf.Tag, len(m.MetricTags)),
 		MetricFields: make([]*telegraf.Field, len(m.MetricFields)),
 		MetricTime:   m.MetricTime,
 		MetricType:   m.MetricType,
+		route:        m.route,
+		priority:     m.priority,
 	}
 
 	for i, tag := range m.MetricTags {
@@ -267,6 +303,31 @@ func (m *metric) Copy() telegraf.Metric {
 	return m2
 }
 
+// pooledCopy returns a copy of the metric allocated from the pool. The copy
+// shares the tag list with the original metric until either of them accesses
+// the tags for modification.
+func (m *metric) pooledCopy() telegraf.Metric {
+	m2 := newMetric(m.MetricName, m.MetricTime, m.MetricType)
+	m2.route = m.route
+	m2.priority = m.priority
+
+	if len(m.MetricTags) > 0 {
+		if m.sharedTags == nil {
+			m.sharedTags = &atomic.Int32{}
+			m.sharedTags.Store(1)
+		}
+		m.sharedTags.Add(1)
+		m2.sharedTags = m.sharedTags
+		m2.MetricTags = m.MetricTags
+	}
+
+	m2.MetricFields = slices.Grow(m2.MetricFields, len(m.MetricFields))
+	for _, field := range m.MetricFields {
+		m2.MetricFields = append(m2.MetricFields, &telegraf.Field{Key: field.Key, Value: field.Value})
+	}
+	return m2
+}
+
 func (m *metric) HashID() uint64 {
 	h := fnv.New64a()
 	h.Write([]byte(m.MetricName))
@@ -306,13 +367,16 @@ func (m *metric) HashIDWithFieldsFiltered(excludedTags, excludedFields []string)
 	return h.Sum64()
 }
 
-func (*metric) Accept() {
+func (m *metric) Accept() {
+	m.release()
 }
 
-func (*metric) Reject() {
+func (m *metric) Reject() {
+	m.release()
 }
 
-func (*metric) Drop() {
+func (m *metric) Drop() {
+	m.release()
 }
 
 // Convert field to a supported type or nil if inconvertible
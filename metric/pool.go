@@ -0,0 +1,82 @@
+package metric
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+var (
+	pooling atomic.Bool
+	pool    = sync.Pool{New: func() interface{} { return &metric{} }}
+)
+
+// EnablePooling switches the metric allocation to a pool-backed mode where
+// metrics are recycled once they are accepted, rejected or dropped. In this
+// mode copies of a metric share the tag list until one of the copies modifies
+// its tags or requests the tag list. Therefore, metrics must not be used after
+// being accepted, rejected or dropped.
+func EnablePooling(enable bool) {
+	pooling.Store(enable)
+}
+
+// PoolingEnabled returns true if metrics are allocated from the pool.
+func PoolingEnabled() bool {
+	return pooling.Load()
+}
+
+func newMetric(name string, tm time.Time, tp telegraf.ValueType) *metric {
+	if !pooling.Load() {
+		return &metric{MetricName: name, MetricTime: tm, MetricType: tp}
+	}
+
+	m := pool.Get().(*metric)
+	m.MetricName = name
+	m.MetricTime = tm
+	m.MetricType = tp
+	m.pooled = true
+	return m
+}
+
+// ownTags makes sure the tag list is exclusively owned by the metric by
+// copying the list if it is shared with other copies. The list is copied
+// before releasing the reference, so other copies cannot take ownership and
+// modify the list while it is being copied.
+func (m *metric) ownTags() {
+	if m.sharedTags == nil {
+		return
+	}
+
+	if m.sharedTags.Load() > 1 {
+		tags := make([]*telegraf.Tag, len(m.MetricTags))
+		for i, tag := range m.MetricTags {
+			tags[i] = &telegraf.Tag{Key: tag.Key, Value: tag.Value}
+		}
+		m.sharedTags.Add(-1)
+		m.MetricTags = tags
+	}
+	m.sharedTags = nil
+}
+
+// release returns the metric to the pool if it was allocated from there.
+func (m *metric) release() {
+	if !m.pooled {
+		return
+	}
+
+	// Only reuse the tag list if no other copy is referencing it
+	tags := m.MetricTags
+	if m.sharedTags != nil && m.sharedTags.Add(-1) > 0 {
+		tags = nil
+	}
+	clear(tags)
+	clear(m.MetricFields)
+
+	*m = metric{
+		MetricTags:   tags[:0],
+		MetricFields: m.MetricFields[:0],
+	}
+	pool.Put(m)
+}
@@ -0,0 +1,203 @@
+package metric
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+)
+
+func enablePooling(t *testing.T) {
+	EnablePooling(true)
+	t.Cleanup(func() { EnablePooling(false) })
+}
+
+func TestPoolingCopySharesTags(t *testing.T) {
+	enablePooling(t)
+
+	m := New("cpu", map[string]string{"host": "localhost", "cpu": "cpu0"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	c := m.Copy()
+	require.Same(t, m.(*metric).MetricTags[0], c.(*metric).MetricTags[0])
+	require.Equal(t, c.Tags(), m.Tags())
+
+	// Modifying the copy must not affect the original
+	c.AddTag("host", "remote")
+	c.RemoveTag("cpu")
+	require.Equal(t, map[string]string{"host": "localhost", "cpu": "cpu0"}, m.Tags())
+	require.Equal(t, map[string]string{"host": "remote"}, c.Tags())
+
+	// The original exclusively owns the tags again
+	m.AddTag("dc", "west")
+	require.Equal(t, map[string]string{"host": "localhost", "cpu": "cpu0", "dc": "west"}, m.Tags())
+	require.Equal(t, map[string]string{"host": "remote"}, c.Tags())
+	require.Nil(t, m.(*metric).sharedTags)
+
+	// Modifying fields of the copy must not affect the original
+	c.AddField("value", 23)
+	require.Equal(t, map[string]interface{}{"value": int64(42)}, m.Fields())
+}
+
+func TestPoolingMultipleCopies(t *testing.T) {
+	enablePooling(t)
+
+	m := New("cpu", map[string]string{"host": "localhost"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	copies := []telegraf.Metric{m.Copy(), m.Copy(), m.Copy()}
+	for i, c := range copies {
+		c.AddTag("index", string(rune('a'+i)))
+	}
+	require.Equal(t, map[string]string{"host": "localhost"}, m.Tags())
+	for i, c := range copies {
+		require.Equal(t, map[string]string{"host": "localhost", "index": string(rune('a' + i))}, c.Tags())
+	}
+}
+
+func TestPoolingCopyModifyTagsInPlace(t *testing.T) {
+	enablePooling(t)
+
+	// Simulate multiple outputs receiving a copy of the same metric where one
+	// of the outputs rewrites the tags in place, e.g. for sanitizing keys
+	m := New("cpu", map[string]string{"host.name": "localhost", "cpu.id": "cpu0"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	copies := make([]telegraf.Metric, 0, 4)
+	for range cap(copies) {
+		copies = append(copies, m.Copy())
+	}
+	m.Accept()
+
+	var wg sync.WaitGroup
+	for i, c := range copies {
+		wg.Add(1)
+		go func(modify bool) {
+			defer wg.Done()
+			for _, tag := range c.TagList() {
+				if modify {
+					tag.Key = strings.ReplaceAll(tag.Key, ".", "_")
+				}
+			}
+		}(i == 0)
+	}
+	wg.Wait()
+
+	require.Equal(t, map[string]string{"host_name": "localhost", "cpu_id": "cpu0"}, copies[0].Tags())
+	for _, c := range copies[1:] {
+		require.Equal(t, map[string]string{"host.name": "localhost", "cpu.id": "cpu0"}, c.Tags())
+	}
+	for _, c := range copies {
+		c.Accept()
+	}
+}
+
+func TestPoolingCopyConcurrent(t *testing.T) {
+	enablePooling(t)
+
+	// Copies sharing the tags are handed to different goroutines reading and
+	// modifying their tags at the same time
+	for range 100 {
+		m := New("cpu", map[string]string{"host": "localhost", "cpu": "cpu0"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+		copies := make([]telegraf.Metric, 0, 8)
+		for range cap(copies) {
+			copies = append(copies, m.Copy())
+		}
+
+		var wg sync.WaitGroup
+		for i, c := range append(copies, m) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				switch i % 4 {
+				case 0:
+					c.AddTag("host", "remote")
+				case 1:
+					c.RemoveTag("cpu")
+				case 2:
+					for _, tag := range c.TagList() {
+						tag.Value = strings.ToUpper(tag.Value)
+					}
+				default:
+					_ = c.Tags()
+				}
+				_ = c.HashID()
+			}()
+		}
+		wg.Wait()
+
+		for i, c := range copies {
+			switch i % 4 {
+			case 0:
+				require.Equal(t, map[string]string{"host": "remote", "cpu": "cpu0"}, c.Tags())
+			case 1:
+				require.Equal(t, map[string]string{"host": "localhost"}, c.Tags())
+			case 2:
+				require.Equal(t, map[string]string{"host": "LOCALHOST", "cpu": "CPU0"}, c.Tags())
+			default:
+				require.Equal(t, map[string]string{"host": "localhost", "cpu": "cpu0"}, c.Tags())
+			}
+		}
+
+		wg.Add(len(copies) + 1)
+		for _, c := range append(copies, m) {
+			go func() {
+				defer wg.Done()
+				c.Accept()
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+func TestPoolingRelease(t *testing.T) {
+	enablePooling(t)
+
+	m := New("cpu", map[string]string{"host": "localhost"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	c := m.Copy()
+	m.Accept()
+
+	// Releasing the original must not affect the copy sharing the tags
+	require.Equal(t, map[string]string{"host": "localhost"}, c.Tags())
+	require.Equal(t, map[string]interface{}{"value": int64(42)}, c.Fields())
+
+	// Recycled metrics must not contain any residue
+	m = New("mem", nil, map[string]interface{}{"free": 1}, time.Unix(0, 0))
+	require.Empty(t, m.TagList())
+	require.Equal(t, map[string]interface{}{"free": int64(1)}, m.Fields())
+	require.Equal(t, map[string]string{"host": "localhost"}, c.Tags())
+	c.Drop()
+}
+
+func TestPoolingDisabled(t *testing.T) {
+	m := New("cpu", map[string]string{"host": "localhost"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	c := m.Copy()
+	require.NotSame(t, m.TagList()[0], c.TagList()[0])
+	require.False(t, m.(*metric).pooled)
+
+	// Metrics not allocated from the pool are still usable after release
+	m.Accept()
+	require.Equal(t, map[string]string{"host": "localhost"}, m.Tags())
+}
+
+func BenchmarkCopy(b *testing.B) {
+	tags := map[string]string{"host": "localhost", "cpu": "cpu0", "dc": "west", "rack": "r1"}
+	fields := map[string]interface{}{"usage_user": 1.0, "usage_system": 2.0}
+
+	for _, enable := range []bool{false, true} {
+		name := "default"
+		if enable {
+			name = "pooling"
+		}
+		b.Run(name, func(b *testing.B) {
+			EnablePooling(enable)
+			defer EnablePooling(false)
+
+			b.ReportAllocs()
+			for range b.N {
+				m := New("cpu", tags, fields, time.Unix(0, 0))
+				c := m.Copy()
+				c.Accept()
+				m.Accept()
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package metric
 
 import (
+	"maps"
 	"runtime"
+	"slices"
+	"sync"
 	"sync/atomic"
 
 	"github.com/influxdata/telegraf"
@@ -23,6 +26,24 @@ func WithGroupTracking(metric []telegraf.Metric, fn NotifyFunc) ([]telegraf.Metr
 	return newTrackingMetricGroup(metric, fn)
 }
 
+// RequireOutputs restricts the delivery status of the tracking metric, and all
+// metrics of its group, to the results of the given outputs. Rejections by
+// other outputs are not taken into account. This must be called before the
+// metric is passed on.
+func RequireOutputs(metric telegraf.Metric, outputs []string) {
+	if m, ok := metric.(*trackingMetric); ok && len(outputs) > 0 {
+		m.d.required = slices.Clone(outputs)
+	}
+}
+
+// AssignOutput associates the tracking metric with the output handling it so
+// the delivery status is recorded per output.
+func AssignOutput(metric telegraf.Metric, output string) {
+	if m, ok := metric.(*trackingMetric); ok {
+		m.output = output
+	}
+}
+
 var (
 	lastID    uint64
 	finalizer func(*trackingData)
@@ -39,6 +60,11 @@ type trackingData struct {
 	AcceptCount int32
 	RejectCount int32
 	notifyFunc  NotifyFunc
+
+	// Delivery results per output, false if any metric was rejected
+	required []string
+	outputs  map[string]bool
+	lock     sync.Mutex
 }
 
 func (d *trackingData) incr() {
@@ -53,27 +79,52 @@ func (d *trackingData) decr() int32 {
 	return atomic.AddInt32(&d.Rc, -1)
 }
 
-func (d *trackingData) accept() {
+func (d *trackingData) accept(output string) {
 	atomic.AddInt32(&d.AcceptCount, 1)
+	d.record(output, true)
 }
 
-func (d *trackingData) reject() {
+func (d *trackingData) reject(output string) {
 	atomic.AddInt32(&d.RejectCount, 1)
+	d.record(output, false)
+}
+
+func (d *trackingData) record(output string, accepted bool) {
+	if output == "" {
+		return
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.outputs == nil {
+		d.outputs = make(map[string]bool)
+	}
+	if previous, found := d.outputs[output]; found {
+		accepted = accepted && previous
+	}
+	d.outputs[output] = accepted
 }
 
 func (d *trackingData) notify() {
+	d.lock.Lock()
+	outputs := maps.Clone(d.outputs)
+	d.lock.Unlock()
+
 	d.notifyFunc(
 		&deliveryInfo{
 			id:       d.Id,
 			accepted: int(d.AcceptCount),
 			rejected: int(d.RejectCount),
+			required: d.required,
+			outputs:  outputs,
 		},
 	)
 }
 
 type trackingMetric struct {
 	telegraf.Metric
-	d *trackingData
+	d      *trackingData
+	output string
 }
 
 func newTrackingMetric(metric telegraf.Metric, fn NotifyFunc) (telegraf.Metric, telegraf.TrackingID) {
@@ -127,16 +178,17 @@ func (m *trackingMetric) Copy() telegraf.Metric {
 	return &trackingMetric{
 		Metric: m.Metric.Copy(),
 		d:      m.d,
+		output: m.output,
 	}
 }
 
 func (m *trackingMetric) Accept() {
-	m.d.accept()
+	m.d.accept(m.output)
 	m.decr()
 }
 
 func (m *trackingMetric) Reject() {
-	m.d.reject()
+	m.d.reject(m.output)
 	m.decr()
 }
 
@@ -167,6 +219,32 @@ func (m *trackingMetric) TrackingData() telegraf.TrackingData {
 	return m.d
 }
 
+func (m *trackingMetric) Route() string {
+	if rm, ok := m.Metric.(telegraf.RoutedMetric); ok {
+		return rm.Route()
+	}
+	return ""
+}
+
+func (m *trackingMetric) SetRoute(route string) {
+	if rm, ok := m.Metric.(telegraf.RoutedMetric); ok {
+		rm.SetRoute(route)
+	}
+}
+
+func (m *trackingMetric) Priority() int {
+	if pm, ok := m.Metric.(telegraf.PrioritizedMetric); ok {
+		return pm.Priority()
+	}
+	return 0
+}
+
+func (m *trackingMetric) SetPriority(priority int) {
+	if pm, ok := m.Metric.(telegraf.PrioritizedMetric); ok {
+		pm.SetPriority(priority)
+	}
+}
+
 // Unwrap allows to access the underlying metric directly e.g. for go-templates
 func (m *trackingMetric) Unwrap() telegraf.Metric {
 	return m.Metric
@@ -176,14 +254,32 @@ type deliveryInfo struct {
 	id       telegraf.TrackingID
 	accepted int
 	rejected int
+	required []string
+	outputs  map[string]bool
 }
 
 func (r *deliveryInfo) ID() telegraf.TrackingID {
 	return r.id
 }
 
+// Delivered returns true if no output rejected any of the metrics. If outputs
+// are required, only rejections of those outputs are taken into account.
+// Required outputs not receiving the metrics, e.g. due to filtering, do not
+// affect the delivery status.
 func (r *deliveryInfo) Delivered() bool {
-	return r.rejected == 0
+	if len(r.required) == 0 {
+		return r.rejected == 0
+	}
+	for _, output := range r.required {
+		if accepted, found := r.outputs[output]; found && !accepted {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *deliveryInfo) Outputs() map[string]bool {
+	return r.outputs
 }
 
 func (d *trackingData) ID() telegraf.TrackingID {
@@ -299,3 +299,121 @@ func TestGroupTracking(t *testing.T) {
 		})
 	}
 }
+
+func TestRequiredOutputTracking(t *testing.T) {
+	tests := []struct {
+		name      string
+		required  []string
+		actions   func(metric telegraf.Metric)
+		delivered bool
+		outputs   map[string]bool
+	}{
+		{
+			name:     "required accepted",
+			required: []string{"archive"},
+			actions: func(m telegraf.Metric) {
+				m2 := m.Copy()
+				AssignOutput(m, "archive")
+				AssignOutput(m2, "influxdb")
+				m.Accept()
+				m2.Reject()
+			},
+			delivered: true,
+			outputs:   map[string]bool{"archive": true, "influxdb": false},
+		},
+		{
+			name:     "required rejected",
+			required: []string{"archive"},
+			actions: func(m telegraf.Metric) {
+				m2 := m.Copy()
+				AssignOutput(m, "archive")
+				AssignOutput(m2, "influxdb")
+				m.Reject()
+				m2.Accept()
+			},
+			delivered: false,
+			outputs:   map[string]bool{"archive": false, "influxdb": true},
+		},
+		{
+			name:     "required filtered",
+			required: []string{"archive"},
+			actions: func(m telegraf.Metric) {
+				AssignOutput(m, "influxdb")
+				m.Accept()
+			},
+			delivered: true,
+			outputs:   map[string]bool{"influxdb": true},
+		},
+		{
+			name: "no requirement",
+			actions: func(m telegraf.Metric) {
+				m2 := m.Copy()
+				AssignOutput(m, "archive")
+				AssignOutput(m2, "influxdb")
+				m.Accept()
+				m2.Reject()
+			},
+			delivered: false,
+			outputs:   map[string]bool{"archive": true, "influxdb": false},
+		},
+		{
+			name:     "serialized",
+			required: []string{"archive"},
+			actions: func(m telegraf.Metric) {
+				Init()
+				AssignOutput(m, "archive")
+				buf, err := ToBytes(m)
+				require.NoError(t, err)
+				m2, err := FromBytes(buf)
+				require.NoError(t, err)
+				m2.Reject()
+			},
+			delivered: false,
+			outputs:   map[string]bool{"archive": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &deliveries{
+				Info: make(map[telegraf.TrackingID]telegraf.DeliveryInfo),
+			}
+			m := mustMetric(
+				"memory",
+				map[string]string{},
+				map[string]interface{}{
+					"value": 42,
+				},
+				time.Unix(0, 0),
+			)
+			metric, id := WithTracking(m, d.onDelivery)
+			RequireOutputs(metric, tt.required)
+			tt.actions(metric)
+
+			info, ok := d.Info[id].(telegraf.OutputDeliveryInfo)
+			require.True(t, ok)
+			require.Equal(t, tt.delivered, info.Delivered())
+			require.Equal(t, tt.outputs, info.Outputs())
+		})
+	}
+}
+
+func TestRequiredOutputGroupTracking(t *testing.T) {
+	d := &deliveries{
+		Info: make(map[telegraf.TrackingID]telegraf.DeliveryInfo),
+	}
+	group := []telegraf.Metric{
+		mustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
+		mustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 23}, time.Unix(0, 0)),
+	}
+	metrics, id := WithGroupTracking(group, d.onDelivery)
+	RequireOutputs(metrics[0], []string{"archive"})
+
+	AssignOutput(metrics[0], "archive")
+	AssignOutput(metrics[1], "archive")
+	metrics[0].Accept()
+	metrics[1].Reject()
+
+	info := d.Info[id].(telegraf.OutputDeliveryInfo)
+	require.False(t, info.Delivered())
+	require.Equal(t, map[string]bool{"archive": false}, info.Outputs())
+}
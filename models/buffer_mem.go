@@ -6,27 +6,42 @@ import (
 	"github.com/influxdata/telegraf"
 )
 
-// MemoryBuffer stores metrics in a circular buffer.
+// MemoryBuffer stores metrics in one FIFO queue per priority class.
 type MemoryBuffer struct {
 	sync.Mutex
 	BufferStats
 
-	buf   []telegraf.Metric
-	first int // index of the first/oldest metric
-	last  int // one after the index of the last/newest metric
-	size  int // number of metrics currently in the buffer
-	cap   int // the capacity of the buffer
+	queues [numPriorities]metricQueue
+	seq    uint64 // sequence number of the next metric to restore the order across queues
+	size   int    // number of metrics currently in the buffer
+	cap    int    // the capacity of the buffer
 
-	batchFirst int // index of the first metric in the batch
-	batchSize  int // number of metrics currently in the batch
+	batchSize int      // number of metrics currently in the batch
+	batchSeqs []uint64 // sequence numbers of the metrics in the batch
+}
+
+// numPriorities is the number of priority classes and therefore queues
+const numPriorities = PriorityCritical - PriorityLow + 1
+
+// queueEntry is a buffered metric with its sequence number
+type queueEntry struct {
+	metric telegraf.Metric
+	seq    uint64
 }
 
 func NewMemoryBuffer(capacity int, stats BufferStats) (*MemoryBuffer, error) {
-	return &MemoryBuffer{
+	b := &MemoryBuffer{
 		BufferStats: stats,
-		buf:         make([]telegraf.Metric, capacity),
 		cap:         capacity,
-	}, nil
+	}
+	for i := range b.queues {
+		b.queues[i].limit = capacity
+		b.queues[i].priority = PriorityLow + i
+	}
+
+	// Most metrics are of normal priority so preallocate the queue
+	b.queue(PriorityNormal).entries = make([]queueEntry, capacity)
+	return b, nil
 }
 
 func (b *MemoryBuffer) Len() int {
@@ -60,17 +75,15 @@ func (b *MemoryBuffer) BeginTransaction(batchSize int) *Transaction {
 		return &Transaction{}
 	}
 
-	b.batchFirst = b.first
 	b.batchSize = outLen
-	batchIndex := b.batchFirst
+	b.batchSeqs = make([]uint64, outLen)
 	batch := make([]telegraf.Metric, outLen)
 	for i := range batch {
-		batch[i] = b.buf[batchIndex]
-		b.buf[batchIndex] = nil
-		batchIndex = b.next(batchIndex)
+		e := b.oldest().popFront()
+		batch[i] = e.metric
+		b.batchSeqs[i] = e.seq
 	}
 
-	b.first = b.nextby(b.first, b.batchSize)
 	b.size -= outLen
 	return &Transaction{Batch: batch, valid: true}
 }
@@ -99,14 +112,13 @@ func (b *MemoryBuffer) EndTransaction(tx *Transaction) {
 	keep := tx.InferKeep()
 	if len(keep) > 0 {
 		restore := min(len(keep), b.cap-b.size)
-		b.first = b.prevby(b.first, restore)
-		b.size = min(b.size+restore, b.cap)
-
-		// Restore the metrics that fit into the buffer
-		current := b.first
-		for i := 0; i < restore; i++ {
-			b.buf[current] = tx.Batch[keep[i]]
-			current = b.next(current)
+		b.size += restore
+
+		// Restore the metrics that fit into the buffer. All of them are older
+		// than the buffered metrics, so put them in front of their queues.
+		for i := restore - 1; i >= 0; i-- {
+			m := tx.Batch[keep[i]]
+			b.queue(metricPriority(m)).pushFront(queueEntry{metric: m, seq: b.batchSeqs[keep[i]]})
 		}
 
 		// Drop all remaining metrics
@@ -132,59 +144,114 @@ func (b *MemoryBuffer) length() int {
 }
 
 func (b *MemoryBuffer) addMetric(m telegraf.Metric) int {
+	q := b.queue(metricPriority(m))
+
 	dropped := 0
 	// Check if Buffer is full
 	if b.size == b.cap {
-		b.metricDropped(b.buf[b.last])
+		// Evict the oldest metric of the lowest priority or drop the new metric
+		// if all buffered metrics have a higher priority
+		lowest := b.lowest()
+		if lowest == nil || lowest.priority > q.priority {
+			b.metricAdded()
+			b.metricDropped(m)
+			return 1
+		}
+		b.metricDropped(lowest.popFront().metric)
+		b.size--
 		dropped++
 
 		if b.batchSize > 0 {
 			b.batchSize--
-			b.batchFirst = b.next(b.batchFirst)
 		}
 	}
 
 	b.metricAdded()
 
-	b.buf[b.last] = m
-	b.last = b.next(b.last)
+	q.pushBack(queueEntry{metric: m, seq: b.seq})
+	b.seq++
+	b.size++
+	return dropped
+}
 
-	if b.size == b.cap {
-		b.first = b.next(b.first)
-	}
+// queue returns the queue of the given priority with priorities outside of
+// the known classes being assigned to the closest class.
+func (b *MemoryBuffer) queue(priority int) *metricQueue {
+	priority = min(max(priority, PriorityLow), PriorityCritical)
+	return &b.queues[priority-PriorityLow]
+}
 
-	b.size = min(b.size+1, b.cap)
-	return dropped
+// oldest returns the non-empty queue containing the oldest metric.
+func (b *MemoryBuffer) oldest() *metricQueue {
+	var oldest *metricQueue
+	for i := range b.queues {
+		q := &b.queues[i]
+		if q.size > 0 && (oldest == nil || q.front().seq < oldest.front().seq) {
+			oldest = q
+		}
+	}
+	return oldest
 }
 
-// next returns the next index with wrapping.
-func (b *MemoryBuffer) next(index int) int {
-	index++
-	if index == b.cap {
-		return 0
+// lowest returns the non-empty queue with the lowest priority.
+func (b *MemoryBuffer) lowest() *metricQueue {
+	for i := range b.queues {
+		if b.queues[i].size > 0 {
+			return &b.queues[i]
+		}
 	}
-	return index
+	return nil
+}
+
+func (b *MemoryBuffer) resetBatch() {
+	b.batchSize = 0
+	b.batchSeqs = nil
 }
 
-// nextby returns the index that is count newer with wrapping.
-func (b *MemoryBuffer) nextby(index, count int) int {
-	index += count
-	index %= b.cap
-	return index
+// metricQueue is a FIFO queue of metrics backed by a ring buffer growing on
+// demand up to the given limit.
+type metricQueue struct {
+	entries  []queueEntry
+	head     int // index of the first/oldest entry
+	size     int // number of entries currently in the queue
+	limit    int // maximum number of entries
+	priority int // priority of the metrics in the queue
 }
 
-// prevby returns the index that is count older with wrapping.
-func (b *MemoryBuffer) prevby(index, count int) int {
-	index -= count
-	for index < 0 {
-		index += b.cap
+func (q *metricQueue) front() queueEntry {
+	return q.entries[q.head]
+}
+
+func (q *metricQueue) pushBack(e queueEntry) {
+	if q.size == len(q.entries) {
+		q.grow()
 	}
+	q.entries[(q.head+q.size)%len(q.entries)] = e
+	q.size++
+}
 
-	index %= b.cap
-	return index
+func (q *metricQueue) pushFront(e queueEntry) {
+	if q.size == len(q.entries) {
+		q.grow()
+	}
+	q.head = (q.head + len(q.entries) - 1) % len(q.entries)
+	q.entries[q.head] = e
+	q.size++
 }
 
-func (b *MemoryBuffer) resetBatch() {
-	b.batchFirst = 0
-	b.batchSize = 0
+func (q *metricQueue) popFront() queueEntry {
+	e := q.entries[q.head]
+	q.entries[q.head] = queueEntry{}
+	q.head = (q.head + 1) % len(q.entries)
+	q.size--
+	return e
+}
+
+// grow doubles the size of the full queue without exceeding the limit.
+func (q *metricQueue) grow() {
+	entries := make([]queueEntry, min(max(2*len(q.entries), 16), max(q.limit, 1)))
+	n := copy(entries, q.entries[q.head:])
+	copy(entries[n:], q.entries[:q.head])
+	q.entries = entries
+	q.head = 0
 }
@@ -6,7 +6,9 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
 )
 
 func TestMemoryBufferAcceptCallsMetricAccept(t *testing.T) {
@@ -31,6 +33,122 @@ func TestMemoryBufferAcceptCallsMetricAccept(t *testing.T) {
 	require.Equal(t, 2, accept)
 }
 
+func TestMemoryBufferPriorityEviction(t *testing.T) {
+	buf, err := NewBuffer("test", "123", "", 3, "memory", "")
+	require.NoError(t, err)
+	buf.Stats().MetricsAdded.Set(0)
+	buf.Stats().MetricsDropped.Set(0)
+	defer buf.Close()
+
+	newMetric := func(name string, priority int) telegraf.Metric {
+		m := metric.New(name, map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+		setMetricPriority(m, priority)
+		return m
+	}
+
+	buf.Add(
+		newMetric("health", PriorityCritical),
+		newMetric("bulk1", PriorityLow),
+		newMetric("cpu", PriorityNormal),
+	)
+
+	// The oldest metric of the lowest priority is evicted
+	require.Equal(t, 1, buf.Add(newMetric("bulk2", PriorityLow)))
+	// Normal metrics evict the remaining low priority metric
+	require.Equal(t, 1, buf.Add(newMetric("mem", PriorityNormal)))
+	// The oldest normal metric is evicted as there are no low priority ones
+	require.Equal(t, 1, buf.Add(newMetric("disk", PriorityNormal)))
+	// New metrics with a lower priority than all buffered ones are dropped
+	require.Equal(t, 1, buf.Add(newMetric("bulk3", PriorityLow)))
+	require.Equal(t, int64(7), buf.Stats().MetricsAdded.Get())
+	require.Equal(t, int64(4), buf.Stats().MetricsDropped.Get())
+
+	expected := []telegraf.Metric{
+		newMetric("health", PriorityCritical),
+		newMetric("mem", PriorityNormal),
+		newMetric("disk", PriorityNormal),
+	}
+	tx := buf.BeginTransaction(3)
+	testutil.RequireMetricsEqual(t, expected, tx.Batch)
+	require.Equal(t, PriorityCritical, metricPriority(tx.Batch[0]))
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.Equal(t, 0, buf.Len())
+}
+
+func TestMemoryBufferPriorityEvictionWrapped(t *testing.T) {
+	buf, err := NewBuffer("test", "123", "", 4, "memory", "")
+	require.NoError(t, err)
+	defer buf.Close()
+
+	newMetric := func(value float64, priority int) telegraf.Metric {
+		m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": value}, time.Unix(0, 0))
+		setMetricPriority(m, priority)
+		return m
+	}
+
+	// Wrap the buffer by writing a batch and adding more metrics
+	buf.Add(newMetric(1, PriorityNormal), newMetric(2, PriorityNormal), newMetric(3, PriorityHigh))
+	tx := buf.BeginTransaction(2)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	buf.Add(newMetric(4, PriorityLow), newMetric(5, PriorityNormal), newMetric(6, PriorityHigh))
+
+	require.Equal(t, 1, buf.Add(newMetric(7, PriorityHigh)))
+	require.Equal(t, 1, buf.Add(newMetric(8, PriorityHigh)))
+
+	expected := []telegraf.Metric{
+		newMetric(3, PriorityHigh),
+		newMetric(6, PriorityHigh),
+		newMetric(7, PriorityHigh),
+		newMetric(8, PriorityHigh),
+	}
+	tx = buf.BeginTransaction(4)
+	testutil.RequireMetricsEqual(t, expected, tx.Batch)
+}
+
+func TestMemoryBufferPriorityKeepOrder(t *testing.T) {
+	buf, err := NewBuffer("test", "123", "", 5, "memory", "")
+	require.NoError(t, err)
+	defer buf.Close()
+
+	newMetric := func(value float64, priority int) telegraf.Metric {
+		m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": value}, time.Unix(0, 0))
+		setMetricPriority(m, priority)
+		return m
+	}
+
+	buf.Add(newMetric(1, PriorityHigh), newMetric(2, PriorityLow), newMetric(3, PriorityNormal))
+	tx := buf.BeginTransaction(3)
+	buf.Add(newMetric(4, PriorityLow), newMetric(5, PriorityHigh))
+	buf.EndTransaction(tx)
+
+	// Kept metrics must be restored in front of the newer ones independent
+	// of their priority
+	expected := []telegraf.Metric{
+		newMetric(1, PriorityHigh),
+		newMetric(2, PriorityLow),
+		newMetric(3, PriorityNormal),
+		newMetric(4, PriorityLow),
+		newMetric(5, PriorityHigh),
+	}
+	tx = buf.BeginTransaction(5)
+	testutil.RequireMetricsEqual(t, expected, tx.Batch)
+	buf.EndTransaction(tx)
+
+	// The full buffer evicts the oldest low priority metric
+	require.Equal(t, 1, buf.Add(newMetric(6, PriorityNormal)))
+	expected = []telegraf.Metric{
+		newMetric(1, PriorityHigh),
+		newMetric(3, PriorityNormal),
+		newMetric(4, PriorityLow),
+		newMetric(5, PriorityHigh),
+		newMetric(6, PriorityNormal),
+	}
+	tx = buf.BeginTransaction(5)
+	testutil.RequireMetricsEqual(t, expected, tx.Batch)
+}
+
 func BenchmarkMemoryBufferAddMetrics(b *testing.B) {
 	buf, err := NewBuffer("test", "123", "", 10000, "memory", "")
 	require.NoError(b, err)
@@ -44,3 +162,21 @@ func BenchmarkMemoryBufferAddMetrics(b *testing.B) {
 		buf.Add(m)
 	}
 }
+
+func BenchmarkMemoryBufferPriorityEviction(b *testing.B) {
+	buf, err := NewBuffer("test", "123", "", 10000, "memory", "")
+	require.NoError(b, err)
+	defer buf.Close()
+
+	// Fill the buffer with high priority metrics so each new metric has to
+	// evict the only normal priority metric behind all high priority ones
+	high := metric.New("health", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+	setMetricPriority(high, PriorityHigh)
+	for range 9999 {
+		buf.Add(high)
+	}
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+	for n := 0; n < b.N; n++ {
+		buf.Add(m)
+	}
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// fieldTypes remembers the first-seen type of each field per measurement and
+// converts or drops values of conflicting types. This avoids rejected batches
+// for type-strict backends if the type of a field changes, e.g. between
+// integer and float.
+type fieldTypes struct {
+	mode      string
+	types     map[string]map[string]interface{}
+	converted selfstat.Stat
+	dropped   selfstat.Stat
+	log       telegraf.Logger
+	sync.Mutex
+}
+
+func newFieldTypes(mode string, tags map[string]string, log telegraf.Logger) *fieldTypes {
+	return &fieldTypes{
+		mode:      mode,
+		types:     make(map[string]map[string]interface{}),
+		converted: selfstat.Register("write", "fields_converted", tags),
+		dropped:   selfstat.Register("write", "fields_dropped", tags),
+		log:       log,
+	}
+}
+
+// reconcile converts or removes the fields of the metric not matching the
+// first-seen type of the field.
+func (f *fieldTypes) reconcile(m telegraf.Metric) {
+	f.Lock()
+	defer f.Unlock()
+
+	known, found := f.types[m.Name()]
+	if !found {
+		known = make(map[string]interface{})
+		f.types[m.Name()] = known
+	}
+
+	var conflicts []telegraf.Field
+	for _, field := range m.FieldList() {
+		first, found := known[field.Key]
+		if !found {
+			known[field.Key] = field.Value
+			continue
+		}
+		if !sameType(first, field.Value) {
+			conflicts = append(conflicts, *field)
+		}
+	}
+
+	for _, field := range conflicts {
+		if f.mode == "convert" {
+			if v, err := convertTo(known[field.Key], field.Value); err == nil {
+				m.AddField(field.Key, v)
+				f.converted.Incr(1)
+				continue
+			}
+		}
+		f.log.Tracef("Dropping field %q of %q with conflicting type %T", field.Key, m.Name(), field.Value)
+		m.RemoveField(field.Key)
+		f.dropped.Incr(1)
+	}
+}
+
+func sameType(a, b interface{}) bool {
+	switch a.(type) {
+	case int64:
+		_, ok := b.(int64)
+		return ok
+	case uint64:
+		_, ok := b.(uint64)
+		return ok
+	case float64:
+		_, ok := b.(float64)
+		return ok
+	case string:
+		_, ok := b.(string)
+		return ok
+	case bool:
+		_, ok := b.(bool)
+		return ok
+	}
+	return false
+}
+
+// convertTo converts the value to the type of the reference value
+func convertTo(reference, value interface{}) (interface{}, error) {
+	switch reference.(type) {
+	case int64:
+		return internal.ToInt64(value)
+	case uint64:
+		return internal.ToUint64(value)
+	case float64:
+		return internal.ToFloat64(value)
+	case string:
+		return internal.ToString(value)
+	case bool:
+		return internal.ToBool(value)
+	}
+	return nil, fmt.Errorf("unsupported type %T", reference)
+}
@@ -15,6 +15,10 @@ import (
 	"github.com/influxdata/telegraf/filter"
 )
 
+// DefaultRoute is the route of all metrics not explicitly assigned to a route
+// by their input plugin
+const DefaultRoute = "default"
+
 // TagFilter is the name of a tag, and the values on which to filter
 type TagFilter struct {
 	Name   string
@@ -57,6 +61,11 @@ type Filter struct {
 	MetricPass   string
 	metricFilter cel.Program
 
+	// Routes the plugin is part of, plugins without routes are only part of
+	// the default route
+	Routes []string
+	routes map[string]bool
+
 	selectActive bool
 	modifyActive bool
 
@@ -65,6 +74,16 @@ type Filter struct {
 
 // Compile all Filter lists into filter.Filter objects.
 func (f *Filter) Compile() error {
+	if len(f.Routes) > 0 {
+		f.routes = make(map[string]bool, len(f.Routes))
+		for _, r := range f.Routes {
+			if r == "" {
+				return errors.New("empty route name in 'routes'")
+			}
+			f.routes[r] = true
+		}
+	}
+
 	f.selectActive = len(f.NamePass) > 0 || len(f.NameDrop) > 0
 	f.selectActive = f.selectActive || len(f.TagPassFilters) > 0 || len(f.TagDropFilters) > 0
 	f.selectActive = f.selectActive || f.MetricPass != ""
@@ -129,6 +148,10 @@ func (f *Filter) Compile() error {
 // namepass/namedrop, tagpass/tagdrop and metric filters.
 // The metric is not modified.
 func (f *Filter) Select(metric telegraf.Metric) (bool, error) {
+	if !f.shouldRoutePass(metric) {
+		return false, nil
+	}
+
 	if !f.selectActive {
 		return true, nil
 	}
@@ -160,6 +183,16 @@ func (f *Filter) Select(metric telegraf.Metric) (bool, error) {
 	return true, nil
 }
 
+// shouldRoutePass returns true if the route of the metric is one of the
+// routes of the plugin.
+func (f *Filter) shouldRoutePass(metric telegraf.Metric) bool {
+	route := metricRoute(metric)
+	if len(f.routes) == 0 {
+		return route == DefaultRoute
+	}
+	return f.routes[route]
+}
+
 // Modify removes any tags and fields from the metric according to the
 // fieldinclude/fieldexclude and taginclude/tagexclude filters.
 func (f *Filter) Modify(metric telegraf.Metric) {
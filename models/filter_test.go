@@ -742,3 +742,40 @@ func BenchmarkFilter(b *testing.B) {
 		})
 	}
 }
+
+func TestFilterRoutes(t *testing.T) {
+	unrouted := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Now())
+	routed := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Now())
+	routed.(telegraf.RoutedMetric).SetRoute("secure")
+
+	// Plugins without routes only see metrics of the default route
+	f := Filter{}
+	require.NoError(t, f.Compile())
+	selected, err := f.Select(unrouted)
+	require.NoError(t, err)
+	require.True(t, selected)
+	selected, err = f.Select(routed)
+	require.NoError(t, err)
+	require.False(t, selected)
+
+	f = Filter{Routes: []string{"secure"}}
+	require.NoError(t, f.Compile())
+	selected, err = f.Select(unrouted)
+	require.NoError(t, err)
+	require.False(t, selected)
+	selected, err = f.Select(routed)
+	require.NoError(t, err)
+	require.True(t, selected)
+
+	f = Filter{Routes: []string{"default", "secure"}}
+	require.NoError(t, f.Compile())
+	selected, err = f.Select(unrouted)
+	require.NoError(t, err)
+	require.True(t, selected)
+	selected, err = f.Select(routed)
+	require.NoError(t, err)
+	require.True(t, selected)
+
+	f = Filter{Routes: []string{""}}
+	require.ErrorContains(t, f.Compile(), "empty route name")
+}
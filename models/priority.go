@@ -0,0 +1,105 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+)
+
+// Priority classes of metrics, metrics of lower classes are evicted first
+// from full output buffers
+const (
+	PriorityLow      = -1
+	PriorityNormal   = 0
+	PriorityHigh     = 1
+	PriorityCritical = 2
+)
+
+// ParsePriority returns the priority of the given class name with an empty
+// name denoting the normal priority
+func ParsePriority(class string) (int, error) {
+	switch class {
+	case "low":
+		return PriorityLow, nil
+	case "", "normal":
+		return PriorityNormal, nil
+	case "high":
+		return PriorityHigh, nil
+	case "critical":
+		return PriorityCritical, nil
+	}
+	return PriorityNormal, fmt.Errorf("invalid priority class %q", class)
+}
+
+// metricPriority returns the priority of the metric or the normal priority if
+// the metric does not support priorities
+func metricPriority(m telegraf.Metric) int {
+	if pm, ok := m.(telegraf.PrioritizedMetric); ok {
+		return pm.Priority()
+	}
+	return PriorityNormal
+}
+
+// setMetricPriority assigns the metric to the given priority class if the
+// metric supports priorities
+func setMetricPriority(m telegraf.Metric, priority int) {
+	if priority == PriorityNormal {
+		return
+	}
+	if pm, ok := m.(telegraf.PrioritizedMetric); ok {
+		pm.SetPriority(priority)
+	}
+}
+
+// PriorityRule assigns the priority to all metrics matching the name and tag
+// filters of the rule
+type PriorityRule struct {
+	Priority       int
+	NamePass       []string
+	TagPassFilters []TagFilter
+
+	nameFilter filter.Filter
+}
+
+func (r *PriorityRule) Compile() error {
+	if len(r.NamePass) == 0 && len(r.TagPassFilters) == 0 {
+		return errors.New("priority rule without 'namepass' or 'tagpass'")
+	}
+
+	var err error
+	r.nameFilter, err = filter.Compile(r.NamePass)
+	if err != nil {
+		return fmt.Errorf("error compiling 'namepass': %w", err)
+	}
+
+	for i := range r.TagPassFilters {
+		if err := r.TagPassFilters[i].Compile(); err != nil {
+			return fmt.Errorf("error compiling 'tagpass': %w", err)
+		}
+	}
+	return nil
+}
+
+// Match returns true if the metric matches all filters of the rule
+func (r *PriorityRule) Match(m telegraf.Metric) bool {
+	if r.nameFilter != nil && !r.nameFilter.Match(m.Name()) {
+		return false
+	}
+	return len(r.TagPassFilters) == 0 || ShouldTagsPass(r.TagPassFilters, nil, m.TagList())
+}
+
+// applyPriorityRules assigns the priority of the first matching rule to
+// metrics of normal priority, so priorities set by the input take precedence
+func applyPriorityRules(rules []*PriorityRule, m telegraf.Metric) {
+	if len(rules) == 0 || metricPriority(m) != PriorityNormal {
+		return
+	}
+	for _, r := range rules {
+		if r.Match(m) {
+			setMetricPriority(m, r.Priority)
+			return
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"github.com/influxdata/telegraf"
+)
+
+// metricRoute returns the route of the metric or the default route if the
+// metric is not assigned to any route
+func metricRoute(m telegraf.Metric) string {
+	if rm, ok := m.(telegraf.RoutedMetric); ok && rm.Route() != "" {
+		return rm.Route()
+	}
+	return DefaultRoute
+}
+
+// setMetricRoute assigns the metric to the given route if the metric supports
+// routing
+func setMetricRoute(m telegraf.Metric, route string) {
+	if route == "" || route == DefaultRoute {
+		return
+	}
+	if rm, ok := m.(telegraf.RoutedMetric); ok {
+		rm.SetRoute(route)
+	}
+}
+
+// routeAccumulator assigns all metrics added without a route to the given
+// route. This keeps metrics created by processors e.g. from parsing a field
+// on the route of the metric they originate from.
+type routeAccumulator struct {
+	telegraf.Accumulator
+	route string
+}
+
+func (a *routeAccumulator) AddMetric(m telegraf.Metric) {
+	if metricRoute(m) == DefaultRoute {
+		setMetricRoute(m, a.route)
+	}
+	a.Accumulator.AddMetric(m)
+}
@@ -133,6 +133,12 @@ func (r *RunningAggregator) MakeMetric(telegrafMetric telegraf.Metric) telegraf.
 		r.Config.Tags,
 		nil)
 
+	// Aggregates of an aggregator being part of a single route stay in that
+	// route, all others belong to the default route
+	if len(r.Config.Filter.Routes) == 1 {
+		setMetricRoute(m, r.Config.Filter.Routes[0])
+	}
+
 	r.MetricsPushed.Incr(1)
 
 	return m
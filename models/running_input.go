@@ -3,11 +3,14 @@ package models
 import (
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	logging "github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -25,11 +28,14 @@ type RunningInput struct {
 	defaultTags map[string]string
 
 	startAcc    telegraf.Accumulator
-	started     bool
+	started     atomic.Bool
 	retries     uint64
 	gatherStart time.Time
 	gatherEnd   time.Time
 
+	watermarks     map[string]*watermark
+	watermarksLock sync.Mutex
+
 	MetricsGathered selfstat.Stat
 	GatherTime      selfstat.Stat
 	GatherTimeouts  selfstat.Stat
@@ -96,6 +102,12 @@ type InputConfig struct {
 	TimeSource           string
 	StartupErrorBehavior string
 	LogLevel             string
+	Route                string
+	Priority             int
+	DeliveryOutputs      []string
+	Watermark            bool
+	WatermarkSourceTag   string
+	WorkerPool           string
 
 	NameOverride            string
 	MeasurementPrefix       string
@@ -106,10 +118,28 @@ type InputConfig struct {
 	AlwaysIncludeGlobalTags bool
 }
 
+// watermark keeps the latest timestamp of the metrics collected for a source
+type watermark struct {
+	latest  time.Time
+	metrics uint64
+}
+
+// watermarkMetric marks the watermark metrics emitted by the running input
+// itself to bypass the metric modifications of the input settings
+type watermarkMetric struct {
+	telegraf.Metric
+}
+
 func (*RunningInput) metricFiltered(metric telegraf.Metric) {
 	metric.Drop()
 }
 
+// DeliveryOutputs returns the outputs required to accept tracking metrics of
+// the input for the metrics to be reported as delivered.
+func (r *RunningInput) DeliveryOutputs() []string {
+	return r.Config.DeliveryOutputs
+}
+
 func (r *RunningInput) LogName() string {
 	return logName("inputs", r.Config.Name, r.Config.Alias)
 }
@@ -145,7 +175,7 @@ func (r *RunningInput) Start(acc telegraf.Accumulator) error {
 	r.startAcc = acc
 	err := plugin.Start(acc)
 	if err == nil {
-		r.started = true
+		r.started.Store(true)
 		return nil
 	}
 	r.StartupErrors.Incr(1)
@@ -174,6 +204,15 @@ func (r *RunningInput) Start(acc telegraf.Accumulator) error {
 	return err
 }
 
+// Started returns true if the input is ready to gather metrics, i.e. service
+// inputs were started successfully.
+func (r *RunningInput) Started() bool {
+	if _, ok := r.Input.(telegraf.ServiceInput); !ok {
+		return true
+	}
+	return r.started.Load()
+}
+
 func (r *RunningInput) Probe() error {
 	p, ok := r.Input.(telegraf.ProbePlugin)
 	if !ok || r.Config.StartupErrorBehavior != "probe" {
@@ -196,6 +235,13 @@ func (r *RunningInput) ID() string {
 }
 
 func (r *RunningInput) MakeMetric(metric telegraf.Metric) telegraf.Metric {
+	if wm, ok := metric.(*watermarkMetric); ok {
+		makeMetric(wm.Metric, "", "", "", r.Config.Tags, r.defaultTags)
+		setMetricRoute(wm.Metric, r.Config.Route)
+		setMetricPriority(wm.Metric, r.Config.Priority)
+		return wm.Metric
+	}
+
 	ok, err := r.Config.Filter.Select(metric)
 	if err != nil {
 		r.log.Errorf("filtering failed: %v", err)
@@ -237,6 +283,13 @@ func (r *RunningInput) MakeMetric(metric telegraf.Metric) telegraf.Metric {
 	default:
 	}
 
+	setMetricRoute(metric, r.Config.Route)
+	setMetricPriority(metric, r.Config.Priority)
+
+	if r.Config.Watermark {
+		r.updateWatermark(metric)
+	}
+
 	r.MetricsGathered.Incr(1)
 	GlobalMetricsGathered.Incr(1)
 	return metric
@@ -244,7 +297,7 @@ func (r *RunningInput) MakeMetric(metric telegraf.Metric) telegraf.Metric {
 
 func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
 	// Try to connect if we are not yet started up
-	if plugin, ok := r.Input.(telegraf.ServiceInput); ok && !r.started {
+	if plugin, ok := r.Input.(telegraf.ServiceInput); ok && !r.started.Load() {
 		r.retries++
 		if err := plugin.Start(r.startAcc); err != nil {
 			var serr *internal.StartupError
@@ -254,7 +307,7 @@ func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
 			}
 			r.log.Debugf("Partially connected after %d attempts", r.retries)
 		} else {
-			r.started = true
+			r.started.Store(true)
 			r.log.Debugf("Successfully connected after %d attempts", r.retries)
 		}
 	}
@@ -264,9 +317,65 @@ func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
 	r.gatherEnd = time.Now()
 
 	r.GatherTime.Incr(r.gatherEnd.Sub(r.gatherStart).Nanoseconds())
+
+	if r.Config.Watermark {
+		r.emitWatermarks(acc)
+	}
 	return err
 }
 
+func (r *RunningInput) updateWatermark(metric telegraf.Metric) {
+	var source string
+	if r.Config.WatermarkSourceTag != "" {
+		source, _ = metric.GetTag(r.Config.WatermarkSourceTag)
+	}
+
+	r.watermarksLock.Lock()
+	defer r.watermarksLock.Unlock()
+
+	if r.watermarks == nil {
+		r.watermarks = make(map[string]*watermark)
+	}
+	w, found := r.watermarks[source]
+	if !found {
+		w = &watermark{}
+		r.watermarks[source] = w
+	}
+	if metric.Time().After(w.latest) {
+		w.latest = metric.Time()
+	}
+	w.metrics++
+}
+
+// emitWatermarks adds a metric containing the latest timestamp collected for
+// each source. Sources without new data keep reporting their last watermark
+// so the growing lag can be detected downstream.
+func (r *RunningInput) emitWatermarks(acc telegraf.Accumulator) {
+	r.watermarksLock.Lock()
+	metrics := make([]telegraf.Metric, 0, len(r.watermarks))
+	for source, w := range r.watermarks {
+		tags := map[string]string{"input": r.Config.Name}
+		if r.Config.Alias != "" {
+			tags["alias"] = r.Config.Alias
+		}
+		if r.Config.WatermarkSourceTag != "" {
+			tags["source"] = source
+		}
+		fields := map[string]interface{}{
+			"latest":  w.latest.UnixNano(),
+			"lag_ns":  r.gatherEnd.Sub(w.latest).Nanoseconds(),
+			"metrics": w.metrics,
+		}
+		metrics = append(metrics, metric.New("watermark", tags, fields, r.gatherEnd))
+		w.metrics = 0
+	}
+	r.watermarksLock.Unlock()
+
+	for _, m := range metrics {
+		acc.AddMetric(&watermarkMetric{m})
+	}
+}
+
 func (r *RunningInput) SetDefaultTags(tags map[string]string) {
 	r.defaultTags = tags
 }
@@ -548,3 +548,109 @@ func (m *mockInput) Probe() error {
 func (*mockInput) Gather(telegraf.Accumulator) error {
 	return nil
 }
+
+func TestRunningInputMakeMetricWithRoute(t *testing.T) {
+	ri := NewRunningInput(&mockInput{}, &InputConfig{
+		Name:  "TestRunningInput",
+		Route: "secure",
+	})
+	require.NoError(t, ri.Config.Filter.Compile())
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Now())
+	actual := ri.MakeMetric(m)
+	require.Equal(t, "secure", actual.(telegraf.RoutedMetric).Route())
+}
+
+func TestRunningInputMakeMetricWithPriority(t *testing.T) {
+	ri := NewRunningInput(&mockInput{}, &InputConfig{
+		Name:     "TestRunningInput",
+		Priority: PriorityCritical,
+	})
+	require.NoError(t, ri.Config.Filter.Compile())
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Now())
+	actual := ri.MakeMetric(m)
+	require.Equal(t, PriorityCritical, actual.(telegraf.PrioritizedMetric).Priority())
+}
+
+func TestRunningInputWatermark(t *testing.T) {
+	ri := NewRunningInput(&mockInput{}, &InputConfig{
+		Name:               "TestRunningInput",
+		NameOverride:       "foobar",
+		Watermark:          true,
+		WatermarkSourceTag: "agent_host",
+		Tags:               map[string]string{"dc": "west"},
+	})
+	require.NoError(t, ri.Config.Filter.Compile())
+
+	earlier := time.Unix(1700000000, 0)
+	later := time.Unix(1700000010, 0)
+	for _, m := range []telegraf.Metric{
+		metric.New("snmp", map[string]string{"agent_host": "a"}, map[string]interface{}{"value": 1}, earlier),
+		metric.New("snmp", map[string]string{"agent_host": "a"}, map[string]interface{}{"value": 2}, later),
+		metric.New("snmp", map[string]string{"agent_host": "b"}, map[string]interface{}{"value": 3}, earlier),
+	} {
+		require.NotNil(t, ri.MakeMetric(m))
+	}
+
+	// The watermark metrics must not be modified by the plugin settings
+	// other than the tags
+	var acc testutil.Accumulator
+	require.NoError(t, ri.Gather(&acc))
+	actual := make([]telegraf.Metric, 0, len(acc.GetTelegrafMetrics()))
+	for _, m := range acc.GetTelegrafMetrics() {
+		actual = append(actual, ri.MakeMetric(m))
+	}
+	for _, m := range actual {
+		lag, found := m.GetField("lag_ns")
+		require.True(t, found)
+		latest, found := m.GetField("latest")
+		require.True(t, found)
+		require.Equal(t, m.Time().UnixNano()-latest.(int64), lag)
+		m.RemoveField("lag_ns")
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"watermark",
+			map[string]string{"input": "TestRunningInput", "source": "a", "dc": "west"},
+			map[string]interface{}{"latest": later.UnixNano(), "metrics": uint64(2)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"watermark",
+			map[string]string{"input": "TestRunningInput", "source": "b", "dc": "west"},
+			map[string]interface{}{"latest": earlier.UnixNano(), "metrics": uint64(1)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// Sources without new data keep their watermark
+	require.NotNil(t, ri.MakeMetric(
+		metric.New("snmp", map[string]string{"agent_host": "b"}, map[string]interface{}{"value": 4}, later),
+	))
+	acc.ClearMetrics()
+	require.NoError(t, ri.Gather(&acc))
+	actual = actual[:0]
+	for _, m := range acc.GetTelegrafMetrics() {
+		m = ri.MakeMetric(m)
+		m.RemoveField("lag_ns")
+		actual = append(actual, m)
+	}
+	expected = []telegraf.Metric{
+		metric.New(
+			"watermark",
+			map[string]string{"input": "TestRunningInput", "source": "a", "dc": "west"},
+			map[string]interface{}{"latest": later.UnixNano(), "metrics": uint64(0)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"watermark",
+			map[string]string{"input": "TestRunningInput", "source": "b", "dc": "west"},
+			map[string]interface{}{"latest": later.UnixNano(), "metrics": uint64(1)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+}
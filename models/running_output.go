@@ -9,7 +9,9 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/limiter"
 	logging "github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -35,6 +37,10 @@ type OutputConfig struct {
 	MetricBufferLimit int
 	MetricBatchSize   int
 
+	// Maximum time to retry writing the buffered metrics on shutdown,
+	// overriding the agent setting if non-zero
+	ShutdownDrainTimeout time.Duration
+
 	NameOverride string
 	NamePrefix   string
 	NameSuffix   string
@@ -42,7 +48,17 @@ type OutputConfig struct {
 	BufferStrategy  string
 	BufferDirectory string
 
+	// Rules assigning priorities to metrics before adding them to the buffer
+	PriorityRules []*PriorityRule
+
+	FieldTypeConflict string
+
 	LogLevel string
+
+	// Egress bandwidth limit in bytes per second for this output and the
+	// agent-wide limiter shared by all outputs
+	EgressBandwidthLimit   int64
+	GlobalBandwidthLimiter *limiter.BandwidthLimiter
 }
 
 // RunningOutput contains the output configuration
@@ -51,6 +67,7 @@ type RunningOutput struct {
 	droppedMetrics  atomic.Int64
 	writeInFlight   atomic.Bool
 	lastWriteFailed atomic.Bool
+	lastSuccess     atomic.Int64
 
 	Output            telegraf.Output
 	Config            *OutputConfig
@@ -63,8 +80,9 @@ type RunningOutput struct {
 
 	BatchReady chan time.Time
 
-	buffer Buffer
-	log    telegraf.Logger
+	buffer     Buffer
+	log        telegraf.Logger
+	fieldTypes *fieldTypes
 
 	started bool
 	retries uint64
@@ -134,6 +152,18 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 		),
 		log: logger,
 	}
+	if config.FieldTypeConflict != "" {
+		ro.fieldTypes = newFieldTypes(config.FieldTypeConflict, tags, logger)
+	}
+
+	if config.EgressBandwidthLimit > 0 || config.GlobalBandwidthLimiter != nil {
+		if p, ok := output.(limiter.BandwidthLimitedPlugin); ok {
+			throttled := selfstat.Register("write", "egress_throttle_ns", tags)
+			p.SetBandwidthLimiter(limiter.NewBandwidthLimiter(config.EgressBandwidthLimit, config.GlobalBandwidthLimiter, throttled))
+		} else if config.EgressBandwidthLimit > 0 {
+			logger.Warn("Plugin does not support limiting the egress bandwidth, ignoring the limit")
+		}
+	}
 
 	return ro
 }
@@ -161,6 +191,12 @@ func (r *RunningOutput) Init() error {
 		return fmt.Errorf("invalid 'startup_error_behavior' setting %q", r.Config.StartupErrorBehavior)
 	}
 
+	switch r.Config.FieldTypeConflict {
+	case "", "convert", "drop":
+	default:
+		return fmt.Errorf("invalid 'field_type_conflict' setting %q", r.Config.FieldTypeConflict)
+	}
+
 	if p, ok := r.Output.(telegraf.Initializer); ok {
 		err := p.Init()
 		if err != nil {
@@ -175,6 +211,7 @@ func (r *RunningOutput) Connect() error {
 	err := r.Output.Connect()
 	if err == nil {
 		r.started = true
+		r.markSuccess()
 		return nil
 	}
 	r.StartupErrors.Incr(1)
@@ -222,7 +259,9 @@ func (r *RunningOutput) AddMetric(metric telegraf.Metric) {
 		return
 	}
 
-	r.add(metric.Copy())
+	m := metric.Copy()
+	r.assign(m)
+	r.add(m)
 }
 
 // AddMetricNoCopy adds a metric to the output.
@@ -236,11 +275,25 @@ func (r *RunningOutput) AddMetricNoCopy(metric telegraf.Metric) {
 		return
 	}
 
+	r.assign(metric)
 	r.add(metric)
 }
 
+// assign associates tracking metrics with the output to record the delivery
+// results per output
+func (r *RunningOutput) assign(m telegraf.Metric) {
+	name := r.Config.Alias
+	if name == "" {
+		name = r.Config.Name
+	}
+	metric.AssignOutput(m, name)
+}
+
 func (r *RunningOutput) add(metric telegraf.Metric) {
 	r.Config.Filter.Modify(metric)
+	if r.fieldTypes != nil {
+		r.fieldTypes.reconcile(metric)
+	}
 	if len(metric.FieldList()) == 0 {
 		r.metricFiltered(metric)
 		return
@@ -265,6 +318,8 @@ func (r *RunningOutput) add(metric telegraf.Metric) {
 		metric.AddSuffix(r.Config.NameSuffix)
 	}
 
+	applyPriorityRules(r.Config.PriorityRules, metric)
+
 	r.droppedMetrics.Add(int64(r.buffer.Add(metric)))
 
 	r.triggerBatchCheck()
@@ -303,6 +358,7 @@ func (r *RunningOutput) Write() error {
 			r.log.Debugf("Partially connected after %d attempts", r.retries)
 		} else {
 			r.started = true
+			r.markSuccess()
 			r.log.Debugf("Successfully connected after %d attempts", r.retries)
 		}
 	}
@@ -346,6 +402,7 @@ func (r *RunningOutput) WriteBatch() error {
 			return internal.ErrNotConnected
 		}
 		r.started = true
+		r.markSuccess()
 		r.log.Debugf("Successfully connected after %d attempts", r.retries)
 	}
 
@@ -393,6 +450,7 @@ func (r *RunningOutput) updateTransaction(tx *Transaction, err error) {
 	// No error indicates all metrics were written successfully
 	if err == nil {
 		r.lastWriteFailed.Store(false)
+		r.markSuccess()
 		tx.AcceptAll()
 		return
 	}
@@ -410,10 +468,53 @@ func (r *RunningOutput) updateTransaction(tx *Transaction, err error) {
 	// values. Only allow to retrigger before the flush interval if at least
 	// one metric was accepted in order to avoid
 	r.lastWriteFailed.Store(len(writeErr.MetricsAccept) == 0)
+	if len(writeErr.MetricsAccept) > 0 {
+		r.markSuccess()
+	}
 	tx.Accept = writeErr.MetricsAccept
 	tx.Reject = writeErr.MetricsReject
 }
 
+func (r *RunningOutput) markSuccess() {
+	r.lastSuccess.Store(time.Now().UnixNano())
+}
+
+// LastSuccess returns the time of the last successful connection or write of
+// the output. The returned time is zero if the output never succeeded.
+func (r *RunningOutput) LastSuccess() time.Time {
+	ts := r.lastSuccess.Load()
+	if ts == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ts)
+}
+
+// LastWriteFailed returns true if the last write of the output failed
+// completely.
+func (r *RunningOutput) LastWriteFailed() bool {
+	return r.lastWriteFailed.Load()
+}
+
+// DrainBuffer removes all metrics from the buffer and returns copies of them.
+// The metrics are accepted as if they were written, so this should only be
+// used to pass the metrics on to another process.
+func (r *RunningOutput) DrainBuffer() []telegraf.Metric {
+	n := r.buffer.Len()
+	if n == 0 {
+		return nil
+	}
+
+	tx := r.buffer.BeginTransaction(n)
+	metrics := make([]telegraf.Metric, 0, len(tx.Batch))
+	for _, m := range tx.Batch {
+		metrics = append(metrics, m.Copy())
+	}
+	tx.AcceptAll()
+	r.buffer.EndTransaction(tx)
+
+	return metrics
+}
+
 func (r *RunningOutput) LogBufferStatus() {
 	nBuffer := r.buffer.Len()
 	if r.Config.BufferStrategy == "disk_write_through" {
@@ -430,3 +531,8 @@ func (r *RunningOutput) Log() telegraf.Logger {
 func (r *RunningOutput) BufferLength() int {
 	return r.buffer.Len()
 }
+
+// BufferStats returns the statistics of the output's buffer
+func (r *RunningOutput) BufferStats() BufferStats {
+	return r.buffer.Stats()
+}
@@ -12,6 +12,8 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/limiter"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -82,6 +84,27 @@ func TestRunningOutputPassFilter(t *testing.T) {
 	require.Len(t, m.Metrics(), 10)
 }
 
+// Test that draining the buffer returns all metrics and empties the buffer.
+func TestRunningOutputDrainBuffer(t *testing.T) {
+	conf := &OutputConfig{}
+	require.NoError(t, conf.Filter.Compile())
+
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, conf, 1000, 10000)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	require.Equal(t, 5, ro.BufferLength())
+
+	testutil.RequireMetricsEqual(t, first5, ro.DrainBuffer())
+	require.Equal(t, 0, ro.BufferLength())
+	require.Empty(t, ro.DrainBuffer())
+
+	require.NoError(t, ro.Write())
+	require.Empty(t, m.Metrics())
+}
+
 // Test that tags are properly included
 func TestRunningOutputTagIncludeNoMatch(t *testing.T) {
 	conf := &OutputConfig{
@@ -221,6 +244,28 @@ func TestRunningOutputNameSuffix(t *testing.T) {
 }
 
 // Test that we can write metrics with simple default setup.
+// Test that priority rules evict unimportant metrics from a full buffer
+func TestRunningOutputPriorityRules(t *testing.T) {
+	rule := &PriorityRule{Priority: PriorityCritical, NamePass: []string{"health"}}
+	require.NoError(t, rule.Compile())
+	conf := &OutputConfig{
+		PriorityRules: []*PriorityRule{rule},
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, conf, 1000, 2)
+
+	ro.AddMetric(testutil.TestMetric(101, "health"))
+	ro.AddMetric(testutil.TestMetric(102, "metric1"))
+	ro.AddMetric(testutil.TestMetric(103, "metric2"))
+	ro.AddMetric(testutil.TestMetric(104, "metric3"))
+
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 2)
+	require.Equal(t, "health", m.Metrics()[0].Name())
+	require.Equal(t, "metric3", m.Metrics()[1].Name())
+}
+
 func TestRunningOutputDefault(t *testing.T) {
 	conf := &OutputConfig{
 		Filter: Filter{},
@@ -836,6 +881,72 @@ func TestRunningOutputStartupBehaviorInvalid(t *testing.T) {
 	require.ErrorContains(t, ro.Init(), "invalid 'startup_error_behavior'")
 }
 
+func TestRunningOutputFieldTypeConflictInvalid(t *testing.T) {
+	ro := NewRunningOutput(
+		&mockOutput{},
+		&OutputConfig{
+			Filter:            Filter{},
+			Name:              "test_name",
+			FieldTypeConflict: "foo",
+		},
+		5, 10,
+	)
+	require.ErrorContains(t, ro.Init(), "invalid 'field_type_conflict'")
+}
+
+func TestRunningOutputFieldTypeConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		expected []telegraf.Metric
+	}{
+		{
+			name: "convert",
+			mode: "convert",
+			expected: []telegraf.Metric{
+				metric.New("m", map[string]string{}, map[string]interface{}{"value": 1.5, "state": "ok"}, time.Unix(0, 0)),
+				metric.New("m", map[string]string{}, map[string]interface{}{"value": 2.0, "state": "ok"}, time.Unix(0, 0)),
+				metric.New("m", map[string]string{}, map[string]interface{}{"value": 3.0, "state": "42"}, time.Unix(0, 0)),
+				metric.New("n", map[string]string{}, map[string]interface{}{"value": int64(4)}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name: "drop",
+			mode: "drop",
+			expected: []telegraf.Metric{
+				metric.New("m", map[string]string{}, map[string]interface{}{"value": 1.5, "state": "ok"}, time.Unix(0, 0)),
+				metric.New("m", map[string]string{}, map[string]interface{}{"state": "ok"}, time.Unix(0, 0)),
+				metric.New("m", map[string]string{}, map[string]interface{}{"value": 3.0}, time.Unix(0, 0)),
+				metric.New("n", map[string]string{}, map[string]interface{}{"value": int64(4)}, time.Unix(0, 0)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := []telegraf.Metric{
+				metric.New("m", map[string]string{}, map[string]interface{}{"value": 1.5, "state": "ok"}, time.Unix(0, 0)),
+				metric.New("m", map[string]string{}, map[string]interface{}{"value": int64(2), "state": "ok"}, time.Unix(0, 0)),
+				metric.New("m", map[string]string{}, map[string]interface{}{"value": 3.0, "state": int64(42)}, time.Unix(0, 0)),
+				metric.New("n", map[string]string{}, map[string]interface{}{"value": int64(4)}, time.Unix(0, 0)),
+			}
+
+			plugin := &mockOutput{}
+			ro := NewRunningOutput(plugin, &OutputConfig{
+				Name:              "field_types_" + tt.name,
+				FieldTypeConflict: tt.mode,
+			}, 1000, 10000)
+			require.NoError(t, ro.Init())
+
+			for _, m := range input {
+				ro.AddMetric(m)
+			}
+			require.NoError(t, ro.Write())
+			testutil.RequireMetricsEqual(t, tt.expected, plugin.Metrics())
+		})
+	}
+}
+
 func TestRunningOutputRetryableStartupBehaviorDefault(t *testing.T) {
 	serr := &internal.StartupError{
 		Err:   errors.New("retryable err"),
@@ -1243,6 +1354,25 @@ func BenchmarkRunningOutputAddWrite(b *testing.B) {
 	}
 }
 
+func TestRunningOutputBandwidthLimiter(t *testing.T) {
+	global := limiter.NewBandwidthLimiter(1000, nil, nil)
+
+	// Outputs supporting the limit get a limiter chained to the global one
+	m := &bandwidthOutput{}
+	NewRunningOutput(m, &OutputConfig{Name: "limited", EgressBandwidthLimit: 100}, 1000, 10000)
+	require.NotNil(t, m.limiter)
+
+	// The global limit alone also results in a limiter
+	m = &bandwidthOutput{}
+	NewRunningOutput(m, &OutputConfig{Name: "global", GlobalBandwidthLimiter: global}, 1000, 10000)
+	require.NotNil(t, m.limiter)
+
+	// Without limits no limiter is set
+	m = &bandwidthOutput{}
+	NewRunningOutput(m, &OutputConfig{Name: "unlimited"}, 1000, 10000)
+	require.Nil(t, m.limiter)
+}
+
 // Benchmark adding metrics.
 func BenchmarkRunningOutputAddWriteEvery100(b *testing.B) {
 	conf := &OutputConfig{
@@ -1271,6 +1401,15 @@ func BenchmarkRunningOutputAddFailWrites(b *testing.B) {
 	}
 }
 
+type bandwidthOutput struct {
+	mockOutput
+	limiter *limiter.BandwidthLimiter
+}
+
+func (m *bandwidthOutput) SetBandwidthLimiter(l *limiter.BandwidthLimiter) {
+	m.limiter = l
+}
+
 type mockOutput struct {
 	sync.Mutex
 
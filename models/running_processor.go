@@ -51,6 +51,14 @@ func NewRunningProcessor(processor telegraf.StreamingProcessor, config *Processo
 	}
 	SetLoggerOnPlugin(processor, logger)
 
+	// Processors are usually wrapped into a streaming processor so the
+	// statistics have to be set on the underlying plugin
+	if p, ok := processor.(interface{ Unwrap() telegraf.Processor }); ok {
+		SetStatisticsOnPlugin(p.Unwrap(), logger, tags)
+	} else {
+		SetStatisticsOnPlugin(processor, logger, tags)
+	}
+
 	return &RunningProcessor{
 		Processor: processor,
 		Config:    config,
@@ -112,6 +120,11 @@ func (rp *RunningProcessor) Add(m telegraf.Metric, acc telegraf.Accumulator) err
 		return nil
 	}
 
+	// Keep metrics emitted by the processor on the route of the original
+	if route := metricRoute(m); route != DefaultRoute {
+		acc = &routeAccumulator{Accumulator: acc, route: route}
+	}
+
 	return rp.Processor.Add(m, acc)
 }
 
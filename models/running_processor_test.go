@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/processors"
 	"github.com/influxdata/telegraf/testutil"
@@ -182,6 +183,45 @@ func TestRunningProcessorApply(t *testing.T) {
 	}
 }
 
+func TestRunningProcessorRoutes(t *testing.T) {
+	// The processor emits a new metric for each input metric
+	split := processors.NewStreamingProcessorFromProcessor(
+		&mockProcessor{
+			applyF: func(in ...telegraf.Metric) []telegraf.Metric {
+				out := make([]telegraf.Metric, 0, len(in))
+				for _, m := range in {
+					out = append(out, metric.New("split", m.Tags(), m.Fields(), m.Time()))
+				}
+				return out
+			},
+		},
+	)
+	rp := &models.RunningProcessor{
+		Processor: split,
+		Config:    &models.ProcessorConfig{Filter: models.Filter{Routes: []string{"secure"}}},
+	}
+	require.NoError(t, rp.Config.Filter.Compile())
+
+	routed := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	routed.(telegraf.RoutedMetric).SetRoute("secure")
+	unrouted := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+
+	var acc testutil.Accumulator
+	require.NoError(t, rp.Start(&acc))
+	require.NoError(t, rp.Add(routed, &acc))
+	require.NoError(t, rp.Add(unrouted, &acc))
+	rp.Stop()
+
+	// Metrics created by the processor keep the route, metrics of other
+	// routes pass the processor unchanged
+	actual := acc.GetTelegrafMetrics()
+	require.Len(t, actual, 2)
+	require.Equal(t, "split", actual[0].Name())
+	require.Equal(t, "secure", actual[0].(telegraf.RoutedMetric).Route())
+	require.Equal(t, "cpu", actual[1].Name())
+	require.Empty(t, actual[1].(telegraf.RoutedMetric).Route())
+}
+
 func TestRunningProcessorOrder(t *testing.T) {
 	rp1 := &models.RunningProcessor{
 		Config: &models.ProcessorConfig{
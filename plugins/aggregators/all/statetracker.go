@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.statetracker
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/statetracker" // register plugin
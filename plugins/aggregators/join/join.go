@@ -0,0 +1,115 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package join
+
+import (
+	_ "embed"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Join struct {
+	Tags           []string `toml:"tags"`
+	Name           string   `toml:"name"`
+	Measurements   []string `toml:"measurements"`
+	CompleteOnly   bool     `toml:"complete_only"`
+	FieldSeparator string   `toml:"field_separator"`
+
+	cache map[string]*joined
+}
+
+// joined holds the fields collected for one combination of join tag values
+type joined struct {
+	tags         map[string]string
+	fields       map[string]interface{}
+	measurements map[string]bool
+	timestamp    time.Time
+}
+
+func (*Join) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *Join) Init() error {
+	if len(a.Tags) == 0 {
+		return errors.New("no join tags specified")
+	}
+	if a.CompleteOnly && len(a.Measurements) == 0 {
+		return errors.New("'complete_only' requires 'measurements' to be set")
+	}
+
+	a.cache = make(map[string]*joined)
+	return nil
+}
+
+func (a *Join) Add(m telegraf.Metric) {
+	if len(a.Measurements) > 0 && !slices.Contains(a.Measurements, m.Name()) {
+		return
+	}
+
+	values := make([]string, 0, len(a.Tags))
+	for _, key := range a.Tags {
+		value, found := m.GetTag(key)
+		if !found {
+			return
+		}
+		values = append(values, value)
+	}
+	id := strings.Join(values, "\x00")
+
+	entry, found := a.cache[id]
+	if !found {
+		entry = &joined{
+			tags:         make(map[string]string, len(a.Tags)),
+			fields:       make(map[string]interface{}),
+			measurements: make(map[string]bool),
+		}
+		for i, key := range a.Tags {
+			entry.tags[key] = values[i]
+		}
+		a.cache[id] = entry
+	}
+
+	// Later metrics overwrite the values of earlier ones in the window
+	for _, field := range m.FieldList() {
+		entry.fields[m.Name()+a.FieldSeparator+field.Key] = field.Value
+	}
+	entry.measurements[m.Name()] = true
+	if m.Time().After(entry.timestamp) {
+		entry.timestamp = m.Time()
+	}
+}
+
+func (a *Join) Push(acc telegraf.Accumulator) {
+	// Always use nanosecond precision to avoid rounding metrics that were
+	// produced at a precision higher than the agent default.
+	acc.SetPrecision(time.Nanosecond)
+
+	for _, entry := range a.cache {
+		if a.CompleteOnly && len(entry.measurements) < len(a.Measurements) {
+			continue
+		}
+		acc.AddMetric(metric.New(a.Name, entry.tags, entry.fields, entry.timestamp))
+	}
+}
+
+func (a *Join) Reset() {
+	a.cache = make(map[string]*joined)
+}
+
+func init() {
+	aggregators.Add("join", func() telegraf.Aggregator {
+		return &Join{
+			Name:           "join",
+			FieldSeparator: "_",
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package join
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newJoin() *Join {
+	return &Join{
+		Name:           "join",
+		FieldSeparator: "_",
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := newJoin()
+	require.ErrorContains(t, plugin.Init(), "no join tags specified")
+
+	plugin = newJoin()
+	plugin.Tags = []string{"host"}
+	plugin.CompleteOnly = true
+	require.ErrorContains(t, plugin.Init(), "'complete_only' requires 'measurements' to be set")
+}
+
+func TestJoin(t *testing.T) {
+	plugin := newJoin()
+	plugin.Tags = []string{"host"}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a", "cpu": "cpu-total"}, map[string]interface{}{"usage_idle": 92.5}, time.Unix(10, 0)),
+		metric.New("net", map[string]string{"host": "a", "interface": "eth0"}, map[string]interface{}{"bytes_recv": int64(1000)}, time.Unix(11, 0)),
+		metric.New("cpu", map[string]string{"host": "b", "cpu": "cpu-total"}, map[string]interface{}{"usage_idle": 50.0}, time.Unix(10, 0)),
+		metric.New("net", map[string]string{"host": "a", "interface": "eth0"}, map[string]interface{}{"bytes_recv": int64(2000)}, time.Unix(12, 0)),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"used": int64(1)}, time.Unix(10, 0)),
+	}
+	for _, m := range input {
+		plugin.Add(m)
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"join",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"cpu_usage_idle": 92.5, "net_bytes_recv": int64(2000)},
+			time.Unix(12, 0),
+		),
+		metric.New(
+			"join",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"cpu_usage_idle": 50.0},
+			time.Unix(10, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+
+	// The window is cleared on reset
+	plugin.Reset()
+	acc.ClearMetrics()
+	plugin.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestJoinCompleteOnly(t *testing.T) {
+	plugin := newJoin()
+	plugin.Tags = []string{"host", "region"}
+	plugin.Name = "host_stats"
+	plugin.Measurements = []string{"cpu", "net"}
+	plugin.CompleteOnly = true
+	plugin.FieldSeparator = "."
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a", "region": "eu"}, map[string]interface{}{"usage_idle": 92.5}, time.Unix(10, 0)),
+		metric.New("net", map[string]string{"host": "a", "region": "eu"}, map[string]interface{}{"bytes_recv": int64(1000)}, time.Unix(10, 0)),
+		metric.New("disk", map[string]string{"host": "a", "region": "eu"}, map[string]interface{}{"used": int64(1)}, time.Unix(10, 0)),
+		metric.New("cpu", map[string]string{"host": "a", "region": "us"}, map[string]interface{}{"usage_idle": 50.0}, time.Unix(10, 0)),
+	}
+	for _, m := range input {
+		plugin.Add(m)
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"host_stats",
+			map[string]string{"host": "a", "region": "eu"},
+			map[string]interface{}{"cpu.usage_idle": 92.5, "net.bytes_recv": int64(1000)},
+			time.Unix(10, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
@@ -0,0 +1,163 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package statetracker
+
+import (
+	_ "embed"
+	"errors"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type StateTracker struct {
+	StateTag         string          `toml:"state_tag"`
+	FlapWindow       config.Duration `toml:"flap_window"`
+	FlapThreshold    int             `toml:"flap_threshold"`
+	SuppressFlapping bool            `toml:"suppress_flapping"`
+	SeriesTimeout    config.Duration `toml:"series_timeout"`
+	Log              telegraf.Logger `toml:"-"`
+
+	series map[uint64]*series
+}
+
+// series holds the state information of a series identified by the metric
+// name and all tags except the state tag
+type series struct {
+	name string
+	tags map[string]string
+
+	state  string
+	stable string
+	since  time.Time
+	last   time.Time
+	seen   time.Time
+
+	// Times of the state changes within the flap window
+	changes     []time.Time
+	transitions int
+	flapping    bool
+}
+
+func (*StateTracker) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *StateTracker) Init() error {
+	if s.StateTag == "" {
+		return errors.New("state_tag required")
+	}
+	if s.FlapThreshold < 0 {
+		return errors.New("flap_threshold must not be negative")
+	}
+	if s.FlapThreshold > 0 && s.FlapWindow <= 0 {
+		return errors.New("flap_window must be positive for flap detection")
+	}
+
+	s.series = make(map[uint64]*series)
+
+	return nil
+}
+
+func (s *StateTracker) Add(in telegraf.Metric) {
+	state, found := in.GetTag(s.StateTag)
+	if !found {
+		return
+	}
+
+	key := in.Copy()
+	key.RemoveTag(s.StateTag)
+	id := key.HashID()
+	ts := in.Time()
+
+	entry, found := s.series[id]
+	if !found {
+		entry = &series{
+			name:   in.Name(),
+			tags:   key.Tags(),
+			state:  state,
+			stable: state,
+			since:  ts,
+		}
+		s.series[id] = entry
+	} else if state != entry.state {
+		entry.state = state
+		entry.since = ts
+		entry.transitions++
+		if s.FlapThreshold > 0 {
+			entry.changes = append(entry.changes, ts)
+		}
+	}
+	entry.last = ts
+	entry.seen = time.Now()
+
+	if s.FlapThreshold == 0 {
+		entry.stable = entry.state
+		return
+	}
+
+	// Forget about state changes outside the flap window
+	cutoff := ts.Add(-time.Duration(s.FlapWindow))
+	var expired int
+	for expired < len(entry.changes) && !entry.changes[expired].After(cutoff) {
+		expired++
+	}
+	entry.changes = entry.changes[expired:]
+
+	// Keep the state before starting to flap as stable state
+	entry.flapping = len(entry.changes) >= s.FlapThreshold
+	if !entry.flapping {
+		entry.stable = entry.state
+	}
+}
+
+func (s *StateTracker) Push(acc telegraf.Accumulator) {
+	now := time.Now()
+	for id, entry := range s.series {
+		if s.SeriesTimeout > 0 && now.Sub(entry.seen) > time.Duration(s.SeriesTimeout) {
+			delete(s.series, id)
+			continue
+		}
+
+		state := entry.state
+		if s.SuppressFlapping && entry.flapping {
+			state = entry.stable
+		}
+
+		tags := make(map[string]string, len(entry.tags)+1)
+		for k, v := range entry.tags {
+			tags[k] = v
+		}
+		tags[s.StateTag] = state
+
+		fields := map[string]interface{}{
+			"state_duration":    entry.last.Sub(entry.since).Seconds(),
+			"state_transitions": entry.transitions,
+		}
+		if s.FlapThreshold > 0 {
+			fields["flap_count"] = len(entry.changes)
+			fields["flapping"] = entry.flapping
+		}
+		acc.AddFields(entry.name, fields, tags)
+	}
+}
+
+func (s *StateTracker) Reset() {
+	for _, entry := range s.series {
+		entry.transitions = 0
+	}
+}
+
+func init() {
+	aggregators.Add("statetracker", func() telegraf.Aggregator {
+		return &StateTracker{
+			FlapWindow:    config.Duration(10 * time.Minute),
+			FlapThreshold: 5,
+			SeriesTimeout: config.Duration(time.Hour),
+		}
+	})
+}
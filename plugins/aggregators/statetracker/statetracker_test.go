@@ -0,0 +1,210 @@
+package statetracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *StateTracker
+		expected string
+	}{
+		{
+			name:     "no state tag",
+			plugin:   &StateTracker{},
+			expected: "state_tag required",
+		},
+		{
+			name:     "negative threshold",
+			plugin:   &StateTracker{StateTag: "status", FlapThreshold: -1},
+			expected: "flap_threshold must not be negative",
+		},
+		{
+			name:     "no flap window",
+			plugin:   &StateTracker{StateTag: "status", FlapThreshold: 3},
+			expected: "flap_window must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestTransitions(t *testing.T) {
+	plugin := &StateTracker{StateTag: "status"}
+	require.NoError(t, plugin.Init())
+
+	start := time.Unix(1700000000, 0)
+	input := []telegraf.Metric{
+		newCheck("web01", "OK", start),
+		newCheck("web02", "OK", start),
+		newCheck("web01", "OK", start.Add(30*time.Second)),
+		newCheck("web01", "CRITICAL", start.Add(60*time.Second)),
+		newCheck("web02", "OK", start.Add(60*time.Second)),
+		newCheck("web01", "CRITICAL", start.Add(90*time.Second)),
+		metric.New("check", map[string]string{"host": "web03"}, map[string]interface{}{"value": 1}, start),
+	}
+	for _, m := range input {
+		plugin.Add(m)
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"check",
+			map[string]string{"host": "web01", "status": "CRITICAL"},
+			map[string]interface{}{"state_duration": 30.0, "state_transitions": 1},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"check",
+			map[string]string{"host": "web02", "status": "OK"},
+			map[string]interface{}{"state_duration": 60.0, "state_transitions": 0},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// The transition count is reset for each period while the state is kept
+	plugin.Reset()
+	plugin.Add(newCheck("web01", "CRITICAL", start.Add(120*time.Second)))
+
+	expected = []telegraf.Metric{
+		metric.New(
+			"check",
+			map[string]string{"host": "web01", "status": "CRITICAL"},
+			map[string]interface{}{"state_duration": 60.0, "state_transitions": 0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"check",
+			map[string]string{"host": "web02", "status": "OK"},
+			map[string]interface{}{"state_duration": 60.0, "state_transitions": 0},
+			time.Unix(0, 0),
+		),
+	}
+
+	acc.ClearMetrics()
+	plugin.Push(&acc)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestFlapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		suppress bool
+		state    string
+	}{
+		{
+			name:  "report current state",
+			state: "CRITICAL",
+		},
+		{
+			name:     "suppress flapping",
+			suppress: true,
+			state:    "OK",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &StateTracker{
+				StateTag:         "status",
+				FlapWindow:       config.Duration(10 * time.Minute),
+				FlapThreshold:    3,
+				SuppressFlapping: tt.suppress,
+			}
+			require.NoError(t, plugin.Init())
+
+			start := time.Unix(1700000000, 0)
+			for i, state := range []string{"OK", "CRITICAL", "OK", "CRITICAL"} {
+				plugin.Add(newCheck("web01", state, start.Add(time.Duration(i)*time.Minute)))
+			}
+
+			expected := []telegraf.Metric{
+				metric.New(
+					"check",
+					map[string]string{"host": "web01", "status": tt.state},
+					map[string]interface{}{
+						"state_duration":    0.0,
+						"state_transitions": 3,
+						"flap_count":        3,
+						"flapping":          true,
+					},
+					time.Unix(0, 0),
+				),
+			}
+
+			var acc testutil.Accumulator
+			plugin.Push(&acc)
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+			plugin.Reset()
+
+			// The series stops flapping after the transitions left the window
+			plugin.Add(newCheck("web01", "CRITICAL", start.Add(20*time.Minute)))
+
+			expected = []telegraf.Metric{
+				metric.New(
+					"check",
+					map[string]string{"host": "web01", "status": "CRITICAL"},
+					map[string]interface{}{
+						"state_duration":    1020.0,
+						"state_transitions": 0,
+						"flap_count":        0,
+						"flapping":          false,
+					},
+					time.Unix(0, 0),
+				),
+			}
+
+			acc.ClearMetrics()
+			plugin.Push(&acc)
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestSeriesTimeout(t *testing.T) {
+	plugin := &StateTracker{
+		StateTag:      "status",
+		SeriesTimeout: config.Duration(time.Minute),
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.Add(newCheck("web01", "OK", time.Now()))
+	plugin.Add(newCheck("web02", "OK", time.Now()))
+	for _, entry := range plugin.series {
+		if entry.tags["host"] == "web02" {
+			entry.seen = time.Now().Add(-2 * time.Minute)
+		}
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	require.Len(t, plugin.series, 1)
+	require.Equal(t, "web01", acc.GetTelegrafMetrics()[0].Tags()["host"])
+}
+
+func newCheck(host, state string, ts time.Time) telegraf.Metric {
+	return metric.New(
+		"check",
+		map[string]string{"host": host, "status": state},
+		map[string]interface{}{"value": 1},
+		ts,
+	)
+}
@@ -0,0 +1,182 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package topk
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type TopK struct {
+	K           int    `toml:"k"`
+	Field       string `toml:"field"`
+	Aggregation string `toml:"aggregation"`
+	BottomK     bool   `toml:"bottomk"`
+	RankField   string `toml:"rank_field"`
+
+	// The aggregated state of all series seen within the current period,
+	// grouped by measurement name
+	cache map[string]map[uint64]*series
+}
+
+type series struct {
+	last  telegraf.Metric
+	count int64
+	sum   float64
+	min   float64
+	max   float64
+	value float64
+}
+
+func (*TopK) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *TopK) Init() error {
+	if t.K < 1 {
+		return errors.New("'k' has to be larger than zero")
+	}
+	if t.Field == "" {
+		return errors.New("'field' is required")
+	}
+
+	switch t.Aggregation {
+	case "":
+		t.Aggregation = "mean"
+	case "last", "mean", "min", "max", "sum":
+		// Do nothing, those are valid
+	default:
+		return fmt.Errorf("invalid 'aggregation': %q", t.Aggregation)
+	}
+
+	t.Reset()
+
+	return nil
+}
+
+func (t *TopK) Add(in telegraf.Metric) {
+	raw, found := in.GetField(t.Field)
+	if !found {
+		return
+	}
+	value, ok := convert(raw)
+	if !ok {
+		return
+	}
+
+	measurement, found := t.cache[in.Name()]
+	if !found {
+		measurement = make(map[uint64]*series)
+		t.cache[in.Name()] = measurement
+	}
+
+	id := in.HashID()
+	s, found := measurement[id]
+	if !found {
+		measurement[id] = &series{
+			last:  in,
+			count: 1,
+			sum:   value,
+			min:   value,
+			max:   value,
+			value: value,
+		}
+		return
+	}
+
+	s.last = in
+	s.count++
+	s.sum += value
+	s.value = value
+	if value < s.min {
+		s.min = value
+	}
+	if value > s.max {
+		s.max = value
+	}
+}
+
+func (t *TopK) Push(acc telegraf.Accumulator) {
+	// Preserve timestamp of original metric
+	acc.SetPrecision(time.Nanosecond)
+
+	for _, measurement := range t.cache {
+		ranked := make([]*series, 0, len(measurement))
+		for _, s := range measurement {
+			ranked = append(ranked, s)
+		}
+
+		// Sort by the aggregated value and use the series hash to get a
+		// stable result for equal values
+		sort.SliceStable(ranked, func(i, j int) bool {
+			vi, vj := t.aggregate(ranked[i]), t.aggregate(ranked[j])
+			if vi == vj {
+				return ranked[i].last.HashID() < ranked[j].last.HashID()
+			}
+			if t.BottomK {
+				return vi < vj
+			}
+			return vi > vj
+		})
+
+		if len(ranked) > t.K {
+			ranked = ranked[:t.K]
+		}
+
+		for i, s := range ranked {
+			fields := s.last.Fields()
+			if t.RankField != "" {
+				fields[t.RankField] = int64(i + 1)
+			}
+			acc.AddFields(s.last.Name(), fields, s.last.Tags(), s.last.Time())
+		}
+	}
+}
+
+func (t *TopK) Reset() {
+	t.cache = make(map[string]map[uint64]*series)
+}
+
+func (t *TopK) aggregate(s *series) float64 {
+	switch t.Aggregation {
+	case "last":
+		return s.value
+	case "min":
+		return s.min
+	case "max":
+		return s.max
+	case "sum":
+		return s.sum
+	}
+	return s.sum / float64(s.count)
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("topk", func() telegraf.Aggregator {
+		return &TopK{
+			K:           10,
+			Aggregation: "mean",
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package topk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var testMetrics = []telegraf.Metric{
+	metric.New("procstat", map[string]string{"process_name": "nginx"}, map[string]interface{}{"cpu_usage": 12.5}, time.Unix(0, 0)),
+	metric.New("procstat", map[string]string{"process_name": "postgres"}, map[string]interface{}{"cpu_usage": 40.1}, time.Unix(0, 0)),
+	metric.New("procstat", map[string]string{"process_name": "telegraf"}, map[string]interface{}{"cpu_usage": 1.2}, time.Unix(0, 0)),
+	metric.New("procstat", map[string]string{"process_name": "nginx"}, map[string]interface{}{"cpu_usage": 44.5}, time.Unix(10, 0)),
+	metric.New("procstat", map[string]string{"process_name": "postgres"}, map[string]interface{}{"cpu_usage": 38.1}, time.Unix(10, 0)),
+	metric.New("procstat", map[string]string{"process_name": "telegraf"}, map[string]interface{}{"cpu_usage": int64(1)}, time.Unix(10, 0)),
+	metric.New("procstat", map[string]string{"process_name": "idle"}, map[string]interface{}{"cpu_usage": "none"}, time.Unix(10, 0)),
+	metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"cpu_usage": 5.0}, time.Unix(10, 0)),
+	metric.New("mem", map[string]string{}, map[string]interface{}{"used": 5.0}, time.Unix(10, 0)),
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *TopK
+		expected string
+	}{
+		{
+			name:     "zero k",
+			plugin:   &TopK{Field: "value"},
+			expected: "'k' has to be larger than zero",
+		},
+		{
+			name:     "no field",
+			plugin:   &TopK{K: 1},
+			expected: "'field' is required",
+		},
+		{
+			name:     "invalid aggregation",
+			plugin:   &TopK{K: 1, Field: "value", Aggregation: "median"},
+			expected: `invalid 'aggregation': "median"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestAggregations(t *testing.T) {
+	tests := []struct {
+		name        string
+		aggregation string
+		bottomk     bool
+		expected    []telegraf.Metric
+	}{
+		{
+			name:        "mean",
+			aggregation: "mean",
+			expected: []telegraf.Metric{
+				metric.New("procstat",
+					map[string]string{"process_name": "postgres"},
+					map[string]interface{}{"cpu_usage": 38.1, "rank": int64(1)},
+					time.Unix(10, 0),
+				),
+				metric.New("procstat",
+					map[string]string{"process_name": "nginx"},
+					map[string]interface{}{"cpu_usage": 44.5, "rank": int64(2)},
+					time.Unix(10, 0),
+				),
+				metric.New("cpu",
+					map[string]string{"cpu": "cpu0"},
+					map[string]interface{}{"cpu_usage": 5.0, "rank": int64(1)},
+					time.Unix(10, 0),
+				),
+			},
+		},
+		{
+			name:        "last",
+			aggregation: "last",
+			expected: []telegraf.Metric{
+				metric.New("procstat",
+					map[string]string{"process_name": "nginx"},
+					map[string]interface{}{"cpu_usage": 44.5, "rank": int64(1)},
+					time.Unix(10, 0),
+				),
+				metric.New("procstat",
+					map[string]string{"process_name": "postgres"},
+					map[string]interface{}{"cpu_usage": 38.1, "rank": int64(2)},
+					time.Unix(10, 0),
+				),
+				metric.New("cpu",
+					map[string]string{"cpu": "cpu0"},
+					map[string]interface{}{"cpu_usage": 5.0, "rank": int64(1)},
+					time.Unix(10, 0),
+				),
+			},
+		},
+		{
+			name:        "bottom min",
+			aggregation: "min",
+			bottomk:     true,
+			expected: []telegraf.Metric{
+				metric.New("procstat",
+					map[string]string{"process_name": "telegraf"},
+					map[string]interface{}{"cpu_usage": int64(1), "rank": int64(1)},
+					time.Unix(10, 0),
+				),
+				metric.New("procstat",
+					map[string]string{"process_name": "nginx"},
+					map[string]interface{}{"cpu_usage": 44.5, "rank": int64(2)},
+					time.Unix(10, 0),
+				),
+				metric.New("cpu",
+					map[string]string{"cpu": "cpu0"},
+					map[string]interface{}{"cpu_usage": 5.0, "rank": int64(1)},
+					time.Unix(10, 0),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &TopK{
+				K:           2,
+				Field:       "cpu_usage",
+				Aggregation: tt.aggregation,
+				BottomK:     tt.bottomk,
+				RankField:   "rank",
+			}
+			require.NoError(t, plugin.Init())
+
+			for _, m := range testMetrics {
+				plugin.Add(m)
+			}
+
+			var acc testutil.Accumulator
+			plugin.Push(&acc)
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+		})
+	}
+}
+
+func TestReset(t *testing.T) {
+	plugin := &TopK{K: 1, Field: "cpu_usage"}
+	require.NoError(t, plugin.Init())
+
+	for _, m := range testMetrics {
+		plugin.Add(m)
+	}
+	plugin.Reset()
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
@@ -3,6 +3,7 @@ package httpconfig
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/limiter"
 	"github.com/influxdata/telegraf/plugins/common/cookie"
 	"github.com/influxdata/telegraf/plugins/common/oauth"
 	"github.com/influxdata/telegraf/plugins/common/proxy"
@@ -29,6 +31,13 @@ type HTTPClientConfig struct {
 	tls.ClientConfig
 	oauth.OAuth2Config
 	cookie.CookieAuthConfig
+
+	bandwidthLimiter *limiter.BandwidthLimiter
+}
+
+// SetBandwidthLimiter limits the egress bandwidth of the created clients
+func (h *HTTPClientConfig) SetBandwidthLimiter(l *limiter.BandwidthLimiter) {
+	h.bandwidthLimiter = l
 }
 
 func (h *HTTPClientConfig) CreateClient(ctx context.Context, log telegraf.Logger) (*http.Client, error) {
@@ -51,6 +60,11 @@ func (h *HTTPClientConfig) CreateClient(ctx context.Context, log telegraf.Logger
 		ResponseHeaderTimeout: time.Duration(h.ResponseHeaderTimeout),
 	}
 
+	if h.bandwidthLimiter != nil {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		transport.DialContext = h.bandwidthLimiter.DialContext(dialer.DialContext)
+	}
+
 	// Register "http+unix" and "https+unix" protocol handler.
 	unixtransport.Register(transport)
 
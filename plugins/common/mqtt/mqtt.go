@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	paho "github.com/eclipse/paho.mqtt.golang"
 
@@ -20,6 +21,22 @@ type PublishProperties struct {
 	MessageExpiry  config.Duration   `toml:"message_expiry"`
 	TopicAlias     *uint16           `toml:"topic_alias"`
 	UserProperties map[string]string `toml:"user_properties"`
+
+	// Automatically assign topic aliases up to the given number of topics
+	TopicAliasMaximum uint16 `toml:"topic_alias_maximum"`
+
+	// Settings for deriving per-message properties, used by outputs
+	MessageExpiryFromMetric bool     `toml:"message_expiry_from_metric"`
+	UserPropertyTags        []string `toml:"user_property_tags"`
+}
+
+// MessageProperties are per-message mqtt v5 publish properties complementing
+// the static publish properties of the client. They are ignored for mqtt v3.
+type MessageProperties struct {
+	// MessageExpiry overrides the static message expiry if not nil
+	MessageExpiry *time.Duration
+	// UserProperties are added to the static user properties
+	UserProperties map[string]string
 }
 
 type MqttConfig struct {
@@ -49,6 +66,7 @@ type MqttConfig struct {
 type Client interface {
 	Connect() (bool, error)
 	Publish(topic string, data []byte) error
+	PublishWithProperties(topic string, data []byte, properties *MessageProperties) error
 	SubscribeMultiple(filters map[string]byte, callback paho.MessageHandler) error
 	AddRoute(topic string, callback paho.MessageHandler)
 	Close() error
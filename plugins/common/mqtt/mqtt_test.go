@@ -2,8 +2,12 @@ package mqtt
 
 import (
 	"testing"
+	"time"
 
+	mqttv5 "github.com/eclipse/paho.golang/paho"
 	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
 )
 
 // Test that default client has random ID
@@ -24,3 +28,74 @@ func TestRandomClientID(t *testing.T) {
 	options2 := client2.client.OptionsReader()
 	require.NotEqual(t, options1.ClientID(), options2.ClientID())
 }
+
+func TestMQTTv5TopicAliases(t *testing.T) {
+	maxAliases := uint16(3)
+	cfg := &MqttConfig{
+		Servers:             []string{"tcp://localhost:1883"},
+		PublishPropertiesV5: &PublishProperties{TopicAliasMaximum: 5},
+	}
+	client, err := NewMQTTv5Client(cfg)
+	require.NoError(t, err)
+
+	// The broker limits the number of aliases
+	client.options.OnConnectionUp(nil, &mqttv5.Connack{
+		Properties: &mqttv5.ConnackProperties{TopicAliasMaximum: &maxAliases},
+	})
+
+	for i, topic := range []string{"a/long/topic", "another/long/topic", "third/topic"} {
+		alias, known := client.topicAlias(topic)
+		require.Equal(t, uint16(i+1), alias)
+		require.False(t, known)
+	}
+	alias, known := client.topicAlias("another/long/topic")
+	require.Equal(t, uint16(2), alias)
+	require.True(t, known)
+
+	// No more aliases are available
+	alias, _ = client.topicAlias("fourth/topic")
+	require.Zero(t, alias)
+
+	// Reconnecting resets the aliases
+	client.options.OnConnectionUp(nil, &mqttv5.Connack{
+		Properties: &mqttv5.ConnackProperties{TopicAliasMaximum: &maxAliases},
+	})
+	alias, known = client.topicAlias("fourth/topic")
+	require.Equal(t, uint16(1), alias)
+	require.False(t, known)
+
+	// Brokers not announcing a maximum do not accept aliases
+	client.options.OnConnectionUp(nil, &mqttv5.Connack{})
+	alias, _ = client.topicAlias("a/long/topic")
+	require.Zero(t, alias)
+}
+
+func TestMQTTv5MessageProperties(t *testing.T) {
+	cfg := &MqttConfig{
+		Servers: []string{"tcp://localhost:1883"},
+		PublishPropertiesV5: &PublishProperties{
+			ContentType:    "text/plain",
+			MessageExpiry:  config.Duration(time.Hour),
+			UserProperties: map[string]string{"static": "value"},
+		},
+	}
+	client, err := NewMQTTv5Client(cfg)
+	require.NoError(t, err)
+
+	// Without per-message properties the static properties are used
+	require.Same(t, client.properties, client.messageProperties(nil))
+
+	expiry := 1500 * time.Millisecond
+	properties := client.messageProperties(&MessageProperties{
+		MessageExpiry:  &expiry,
+		UserProperties: map[string]string{"host": "localhost"},
+	})
+	require.Equal(t, "text/plain", properties.ContentType)
+	require.Equal(t, uint32(2), *properties.MessageExpiry)
+	require.Equal(t, "value", properties.User.Get("static"))
+	require.Equal(t, "localhost", properties.User.Get("host"))
+
+	// The static properties must not be modified
+	require.Equal(t, uint32(3600), *client.properties.MessageExpiry)
+	require.Len(t, client.properties.User, 1)
+}
@@ -121,6 +121,12 @@ func (m *mqttv311Client) Publish(topic string, body []byte) error {
 	return token.Error()
 }
 
+// PublishWithProperties publishes the data ignoring the properties as those
+// are not supported by mqtt v3
+func (m *mqttv311Client) PublishWithProperties(topic string, body []byte, _ *MessageProperties) error {
+	return m.Publish(topic, body)
+}
+
 func (m *mqttv311Client) SubscribeMultiple(filters map[string]byte, callback mqttv3.MessageHandler) error {
 	token := m.client.SubscribeMultiple(filters, callback)
 	token.Wait()
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"sync"
 	"time"
 
 	mqttv5auto "github.com/eclipse/paho.golang/autopaho"
@@ -25,6 +26,12 @@ type mqttv5Client struct {
 	retain      bool
 	clientTrace bool
 	properties  *mqttv5.PublishProperties
+
+	// Automatic topic aliases valid for the current connection
+	topicAliasMaximum uint16
+	aliasLimit        uint16
+	aliases           map[string]uint16
+	aliasLock         sync.Mutex
 }
 
 func NewMQTTv5Client(cfg *MqttConfig) (*mqttv5Client, error) {
@@ -74,6 +81,16 @@ func NewMQTTv5Client(cfg *MqttConfig) (*mqttv5Client, error) {
 	}
 	opts.BrokerUrls = brokers
 
+	client := &mqttv5Client{
+		options:     opts,
+		timeout:     time.Duration(cfg.Timeout),
+		username:    cfg.Username,
+		password:    cfg.Password,
+		qos:         cfg.QoS,
+		retain:      cfg.Retain,
+		clientTrace: cfg.ClientTrace,
+	}
+
 	// Build the v5 specific publish properties if they are present in the config.
 	// These should not change during the lifecycle of the client.
 	var properties *mqttv5.PublishProperties
@@ -93,18 +110,29 @@ func NewMQTTv5Client(cfg *MqttConfig) (*mqttv5Client, error) {
 		for k, v := range cfg.PublishPropertiesV5.UserProperties {
 			properties.User.Add(k, v)
 		}
+
+		// A static topic alias takes precedence over automatic aliases
+		if properties.TopicAlias == nil {
+			client.topicAliasMaximum = cfg.PublishPropertiesV5.TopicAliasMaximum
+		}
 	}
+	client.properties = properties
 
-	return &mqttv5Client{
-		options:     opts,
-		timeout:     time.Duration(cfg.Timeout),
-		username:    cfg.Username,
-		password:    cfg.Password,
-		qos:         cfg.QoS,
-		retain:      cfg.Retain,
-		properties:  properties,
-		clientTrace: cfg.ClientTrace,
-	}, nil
+	// Topic aliases are only valid for a single connection and the broker
+	// announces the number of aliases it accepts when connecting
+	client.options.OnConnectionUp = func(_ *mqttv5auto.ConnectionManager, connack *mqttv5.Connack) {
+		var limit uint16
+		if connack != nil && connack.Properties != nil && connack.Properties.TopicAliasMaximum != nil {
+			limit = min(client.topicAliasMaximum, *connack.Properties.TopicAliasMaximum)
+		}
+
+		client.aliasLock.Lock()
+		client.aliasLimit = limit
+		client.aliases = make(map[string]uint16, limit)
+		client.aliasLock.Unlock()
+	}
+
+	return client, nil
 }
 
 func (m *mqttv5Client) Connect() (bool, error) {
@@ -136,18 +164,106 @@ func (m *mqttv5Client) Connect() (bool, error) {
 }
 
 func (m *mqttv5Client) Publish(topic string, body []byte) error {
-	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
-	defer cancel()
+	return m.PublishWithProperties(topic, body, nil)
+}
 
-	_, err := m.client.Publish(ctx, &mqttv5.Publish{
+func (m *mqttv5Client) PublishWithProperties(topic string, body []byte, properties *MessageProperties) error {
+	msg := &mqttv5.Publish{
 		Topic:      topic,
 		QoS:        byte(m.qos),
 		Retain:     m.retain,
 		Payload:    body,
-		Properties: m.properties,
-	})
+		Properties: m.messageProperties(properties),
+	}
+
+	alias, known := m.topicAlias(topic)
+	if alias > 0 {
+		if msg.Properties == nil {
+			msg.Properties = &mqttv5.PublishProperties{}
+		} else if msg.Properties == m.properties {
+			p := *m.properties
+			msg.Properties = &p
+		}
+		msg.Properties.TopicAlias = &alias
+
+		// The topic can be omitted after the alias was announced to the broker
+		if known {
+			msg.Topic = ""
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	if _, err := m.client.Publish(ctx, msg); err != nil {
+		// The broker might not know about the alias, so announce it again
+		// with the next message
+		if alias > 0 && !known {
+			m.aliasLock.Lock()
+			delete(m.aliases, topic)
+			m.aliasLock.Unlock()
+		}
+		return err
+	}
+
+	return nil
+}
+
+// messageProperties merges the per-message properties with the static ones
+func (m *mqttv5Client) messageProperties(properties *MessageProperties) *mqttv5.PublishProperties {
+	if properties == nil || (properties.MessageExpiry == nil && len(properties.UserProperties) == 0) {
+		return m.properties
+	}
+
+	p := &mqttv5.PublishProperties{}
+	if m.properties != nil {
+		*p = *m.properties
+		p.User = append(make([]mqttv5.UserProperty, 0, len(m.properties.User)+len(properties.UserProperties)), m.properties.User...)
+	}
+
+	if properties.MessageExpiry != nil {
+		// The expiry is given in seconds so round up to not expire early
+		expirySeconds := uint32((*properties.MessageExpiry + time.Second - 1) / time.Second)
+		p.MessageExpiry = &expirySeconds
+	}
+	for k, v := range properties.UserProperties {
+		p.User.Add(k, v)
+	}
+
+	return p
+}
+
+// topicAlias returns the alias for the given topic and whether the alias was
+// already announced to the broker. A new alias is assigned to unknown topics
+// as long as the alias limit is not exceeded, otherwise zero is returned.
+func (m *mqttv5Client) topicAlias(topic string) (uint16, bool) {
+	if m.topicAliasMaximum == 0 {
+		return 0, false
+	}
+
+	m.aliasLock.Lock()
+	defer m.aliasLock.Unlock()
+
+	if alias, found := m.aliases[topic]; found {
+		return alias, true
+	}
+	if len(m.aliases) >= int(m.aliasLimit) {
+		return 0, false
+	}
+
+	// Aliases are handed out in sequence but might be released on errors,
+	// so reuse the lowest free alias
+	used := make(map[uint16]bool, len(m.aliases))
+	for _, a := range m.aliases {
+		used[a] = true
+	}
+	alias := uint16(1)
+	for used[alias] {
+		alias++
+	}
+	m.aliases[topic] = alias
 
-	return err
+	return alias, false
 }
 
 func (*mqttv5Client) SubscribeMultiple(filters map[string]byte, callback paho.MessageHandler) error {
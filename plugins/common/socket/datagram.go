@@ -19,6 +19,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/handoff"
 )
 
 type packetListener struct {
@@ -192,10 +193,15 @@ func (l *packetListener) setupUDP(u *url.URL, ifname string, bufferSize int) err
 			return fmt.Errorf("listening (udp multicast) failed: %w", err)
 		}
 	} else {
-		conn, err = net.ListenUDP(u.Scheme, addr)
+		pc, err := handoff.ListenPacket(u.Scheme, u.Host)
 		if err != nil {
 			return fmt.Errorf("listening (udp) failed: %w", err)
 		}
+		var ok bool
+		if conn, ok = pc.(*net.UDPConn); !ok {
+			pc.Close()
+			return fmt.Errorf("unexpected connection type %T", pc)
+		}
 	}
 
 	if bufferSize > 0 {
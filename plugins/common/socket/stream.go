@@ -25,6 +25,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/handoff"
 )
 
 type hasSetReadBuffer interface {
@@ -68,13 +69,15 @@ func newStreamListener(conf Config, splitter bufio.SplitFunc, log telegraf.Logge
 }
 
 func (l *streamListener) setupTCP(u *url.URL, tlsCfg *tls.Config) error {
-	var err error
-	if tlsCfg == nil {
-		l.listener, err = net.Listen(u.Scheme, u.Host)
-	} else {
-		l.listener, err = tls.Listen(u.Scheme, u.Host, tlsCfg)
+	listener, err := handoff.Listen(u.Scheme, u.Host)
+	if err != nil {
+		return err
 	}
-	return err
+	if tlsCfg != nil {
+		listener = tls.NewListener(listener, tlsCfg)
+	}
+	l.listener = listener
+	return nil
 }
 
 func (l *streamListener) setupUnix(u *url.URL, tlsCfg *tls.Config, socketMode string) error {
@@ -18,16 +18,24 @@ const TLSMinVersionDefault = tls.VersionTLS12
 
 // ClientConfig represents the standard client TLS config.
 type ClientConfig struct {
-	TLSCA               string   `toml:"tls_ca"`
-	TLSCert             string   `toml:"tls_cert"`
-	TLSKey              string   `toml:"tls_key"`
-	TLSKeyPwd           string   `toml:"tls_key_pwd"`
-	TLSMinVersion       string   `toml:"tls_min_version"`
-	TLSCipherSuites     []string `toml:"tls_cipher_suites"`
-	InsecureSkipVerify  bool     `toml:"insecure_skip_verify"`
-	ServerName          string   `toml:"tls_server_name"`
-	RenegotiationMethod string   `toml:"tls_renegotiation_method"`
-	Enable              *bool    `toml:"tls_enable"`
+	TLSCA                 string   `toml:"tls_ca"`
+	TLSCert               string   `toml:"tls_cert"`
+	TLSKey                string   `toml:"tls_key"`
+	TLSKeyPwd             string   `toml:"tls_key_pwd"`
+	TLSMinVersion         string   `toml:"tls_min_version"`
+	TLSCipherSuites       []string `toml:"tls_cipher_suites"`
+	InsecureSkipVerify    bool     `toml:"insecure_skip_verify"`
+	ServerName            string   `toml:"tls_server_name"`
+	RenegotiationMethod   string   `toml:"tls_renegotiation_method"`
+	Enable                *bool    `toml:"tls_enable"`
+	TLSSpiffeSocket       string   `toml:"tls_spiffe_socket"`
+	TLSSpiffeServerID     string   `toml:"tls_spiffe_server_id"`
+	TLSCRL                []string `toml:"tls_crl"`
+	TLSOCSP               bool     `toml:"tls_ocsp"`
+	TLSRevocationSoftFail bool     `toml:"tls_revocation_soft_fail"`
+
+	spiffe     *spiffeSource
+	revocation *revocationChecker
 }
 
 // ServerConfig represents the standard server TLS config.
@@ -53,12 +61,15 @@ func (c *ClientConfig) TLSConfig() (*tls.Config, error) {
 	// This check returns a nil (aka "disabled") or an empty config
 	// (aka, "use the default") if no field is set that would have an effect on
 	// a TLS connection. That is, any of:
-	//     * client certificate settings,
+	//     * client certificate settings including SPIFFE,
 	//     * peer certificate authorities,
+	//     * revocation checks,
 	//     * disabled security,
 	//     * an SNI server name, or
 	//     * empty/never renegotiation method
 	empty := c.TLSCA == "" && c.TLSKey == "" && c.TLSCert == ""
+	empty = empty && c.TLSSpiffeSocket == "" && c.TLSSpiffeServerID == ""
+	empty = empty && len(c.TLSCRL) == 0 && !c.TLSOCSP
 	empty = empty && !c.InsecureSkipVerify && c.ServerName == ""
 	empty = empty && (c.RenegotiationMethod == "" || c.RenegotiationMethod == "never")
 
@@ -117,6 +128,30 @@ func (c *ClientConfig) TLSConfig() (*tls.Config, error) {
 		tlsConfig.MinVersion = version
 	}
 
+	if c.TLSSpiffeSocket != "" {
+		if err := c.hookSpiffe(tlsConfig); err != nil {
+			return nil, err
+		}
+	} else if c.TLSSpiffeServerID != "" {
+		return nil, errors.New("'tls_spiffe_server_id' requires 'tls_spiffe_socket'")
+	}
+
+	if len(c.TLSCRL) > 0 || c.TLSOCSP {
+		if c.InsecureSkipVerify {
+			return nil, errors.New("revocation checks cannot be used together with 'insecure_skip_verify'")
+		}
+		// Reuse the checker for multiple calls to share the cached CRLs and
+		// OCSP responses
+		if c.revocation == nil {
+			checker, err := newRevocationChecker(c.TLSCRL, c.TLSOCSP, c.TLSRevocationSoftFail)
+			if err != nil {
+				return nil, err
+			}
+			c.revocation = checker
+		}
+		tlsConfig.VerifyConnection = c.revocation.verifyConnection
+	}
+
 	if c.ServerName != "" {
 		tlsConfig.ServerName = c.ServerName
 	}
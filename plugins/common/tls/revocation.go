@@ -0,0 +1,316 @@
+package tls
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+	"golang.org/x/sync/singleflight"
+)
+
+// Timeout for fetching CRLs and querying OCSP responders
+const revocationTimeout = 10 * time.Second
+
+// Maximum size of CRLs or OCSP responses downloaded from a server
+const maxRevocationResponseSize = 16 * 1024 * 1024
+
+// Time to wait before retrying to fetch a CRL or OCSP response that failed
+// to load in soft-fail mode
+const revocationFailureBackoff = time.Minute
+
+// Tolerated clock difference to the OCSP responder when checking the update
+// time of responses
+const ocspClockSkew = 5 * time.Minute
+
+var errRevocationUnknown = errors.New("revocation status unknown")
+
+// revocationChecker verifies that none of the certificates presented by the
+// server is revoked using CRLs and OCSP. CRLs and OCSP responses are cached
+// until their next update. The lock only protects the caches so fetching
+// does not block connections using already cached data.
+type revocationChecker struct {
+	crlSources []string
+	ocsp       bool
+	softFail   bool
+	client     *http.Client
+	inflight   singleflight.Group
+
+	sync.Mutex
+	crls      map[string]*x509.RevocationList
+	responses map[string]*ocsp.Response
+	failures  map[string]time.Time
+}
+
+func newRevocationChecker(crlSources []string, useOCSP, softFail bool) (*revocationChecker, error) {
+	r := &revocationChecker{
+		crlSources: crlSources,
+		ocsp:       useOCSP,
+		softFail:   softFail,
+		client:     &http.Client{Timeout: revocationTimeout},
+		crls:       make(map[string]*x509.RevocationList, len(crlSources)),
+		responses:  make(map[string]*ocsp.Response),
+		failures:   make(map[string]time.Time),
+	}
+
+	// Load the CRLs upfront to detect configuration issues early
+	for _, source := range crlSources {
+		crl, err := r.loadCRL(source)
+		if err != nil {
+			if softFail {
+				r.failures[crlKey(source)] = time.Now()
+				continue
+			}
+			return nil, err
+		}
+		r.crls[source] = crl
+	}
+
+	return r, nil
+}
+
+func (r *revocationChecker) verifyConnection(state tls.ConnectionState) error {
+	chain := state.PeerCertificates
+	if len(state.VerifiedChains) > 0 {
+		chain = state.VerifiedChains[0]
+	}
+
+	// The root of the chain is trusted and cannot be checked
+	for i := 0; i+1 < len(chain); i++ {
+		if err := r.checkCRL(chain[i], chain[i+1]); err != nil {
+			return err
+		}
+	}
+	if r.ocsp && len(chain) > 1 {
+		if err := r.checkOCSP(chain[0], chain[1], state.OCSPResponse); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *revocationChecker) checkCRL(cert, issuer *x509.Certificate) error {
+	for _, source := range r.crlSources {
+		crl, err := r.crl(source)
+		if err != nil {
+			if r.softFail {
+				continue
+			}
+			return fmt.Errorf("%w: %w", errRevocationUnknown, err)
+		}
+
+		// Only use CRLs issued by the issuer of the certificate
+		if !bytes.Equal(crl.RawIssuer, issuer.RawSubject) || crl.CheckSignatureFrom(issuer) != nil {
+			continue
+		}
+		for _, entry := range crl.RevokedCertificateEntries {
+			if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return fmt.Errorf("certificate %q with serial %s is revoked", cert.Subject, formatSerial(cert.SerialNumber))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *revocationChecker) checkOCSP(cert, issuer *x509.Certificate, stapled []byte) error {
+	resp, err := r.ocspResponse(cert, issuer, stapled)
+	if err != nil {
+		if r.softFail {
+			return nil
+		}
+		return fmt.Errorf("%w: %w", errRevocationUnknown, err)
+	}
+
+	switch resp.Status {
+	case ocsp.Good:
+		return nil
+	case ocsp.Revoked:
+		return fmt.Errorf("certificate %q with serial %s is revoked", cert.Subject, formatSerial(cert.SerialNumber))
+	}
+	if r.softFail {
+		return nil
+	}
+	return fmt.Errorf("%w: OCSP responder does not know certificate %q", errRevocationUnknown, cert.Subject)
+}
+
+// crl returns the cached CRL of the given source or loads the CRL if it is
+// missing or outdated.
+func (r *revocationChecker) crl(source string) (*x509.RevocationList, error) {
+	r.Lock()
+	crl := r.crls[source]
+	r.Unlock()
+	if crl != nil && (crl.NextUpdate.IsZero() || time.Now().Before(crl.NextUpdate)) {
+		return crl, nil
+	}
+
+	v, err := r.load(crlKey(source), func() (interface{}, error) {
+		crl, err := r.loadCRL(source)
+		if err != nil {
+			return nil, err
+		}
+		r.Lock()
+		r.crls[source] = crl
+		r.Unlock()
+		return crl, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*x509.RevocationList), nil
+}
+
+func (r *revocationChecker) ocspResponse(cert, issuer *x509.Certificate, stapled []byte) (*ocsp.Response, error) {
+	// Fall back to querying the responder for outdated stapled responses
+	if len(stapled) > 0 {
+		resp, err := ocsp.ParseResponseForCert(stapled, cert, issuer)
+		if err != nil {
+			return nil, err
+		}
+		if checkOCSPUpdate(resp) == nil {
+			return resp, nil
+		}
+	}
+
+	key := "ocsp:" + string(issuer.RawSubject) + cert.SerialNumber.String()
+	r.Lock()
+	resp, found := r.responses[key]
+	r.Unlock()
+	if found && time.Now().Before(resp.NextUpdate) {
+		return resp, nil
+	}
+
+	if len(cert.OCSPServer) == 0 {
+		return nil, fmt.Errorf("no OCSP responder for certificate %q", cert.Subject)
+	}
+	v, err := r.load(key, func() (interface{}, error) {
+		request, err := ocsp.CreateRequest(cert, issuer, nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating OCSP request failed: %w", err)
+		}
+		raw, err := r.fetch(http.MethodPost, cert.OCSPServer[0], request)
+		if err != nil {
+			return nil, fmt.Errorf("querying OCSP responder failed: %w", err)
+		}
+		resp, err := ocsp.ParseResponseForCert(raw, cert, issuer)
+		if err != nil {
+			return nil, fmt.Errorf("parsing OCSP response failed: %w", err)
+		}
+		if err := checkOCSPUpdate(resp); err != nil {
+			return nil, err
+		}
+		if !resp.NextUpdate.IsZero() {
+			r.Lock()
+			r.responses[key] = resp
+			r.Unlock()
+		}
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ocsp.Response), nil
+}
+
+// load calls the given function only once for concurrent requests of the
+// same key. In soft-fail mode, failures are remembered to avoid delaying
+// connections by retrying for the backoff period.
+func (r *revocationChecker) load(key string, fn func() (interface{}, error)) (interface{}, error) {
+	if r.softFail {
+		r.Lock()
+		failed, found := r.failures[key]
+		r.Unlock()
+		if found && time.Since(failed) < revocationFailureBackoff {
+			return nil, fmt.Errorf("skipping retry after failure at %v", failed)
+		}
+	}
+
+	v, err, _ := r.inflight.Do(key, fn)
+	if r.softFail {
+		r.Lock()
+		if err != nil {
+			r.failures[key] = time.Now()
+		} else {
+			delete(r.failures, key)
+		}
+		r.Unlock()
+	}
+	return v, err
+}
+
+// loadCRL reads a PEM or DER encoded CRL from a file or a HTTP(S) URL
+func (r *revocationChecker) loadCRL(source string) (*x509.RevocationList, error) {
+	var raw []byte
+	var err error
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		raw, err = r.fetch(http.MethodGet, source, nil)
+	} else {
+		raw, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading CRL %q failed: %w", source, err)
+	}
+
+	if block, _ := pem.Decode(raw); block != nil {
+		raw = block.Bytes
+	}
+	crl, err := x509.ParseRevocationList(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parsing CRL %q failed: %w", source, err)
+	}
+
+	return crl, nil
+}
+
+func (r *revocationChecker) fetch(method, address string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, address, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/ocsp-request")
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %q", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxRevocationResponseSize))
+}
+
+// checkOCSPUpdate rejects OCSP responses not yet valid or past their next
+// update, e.g. replayed by an attacker
+func checkOCSPUpdate(resp *ocsp.Response) error {
+	now := time.Now()
+	if resp.ThisUpdate.After(now.Add(ocspClockSkew)) {
+		return fmt.Errorf("OCSP response not valid before %v", resp.ThisUpdate)
+	}
+	if !resp.NextUpdate.IsZero() && now.After(resp.NextUpdate.Add(ocspClockSkew)) {
+		return fmt.Errorf("OCSP response expired at %v", resp.NextUpdate)
+	}
+	return nil
+}
+
+func crlKey(source string) string {
+	return "crl:" + source
+}
+
+func formatSerial(serial *big.Int) string {
+	return fmt.Sprintf("%x", serial)
+}
@@ -0,0 +1,421 @@
+package tls_test
+
+import (
+	"crypto/rand"
+	cryptotls "crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/influxdata/telegraf/plugins/common/tls"
+)
+
+func TestRevocationCRL(t *testing.T) {
+	ca := newTestCA(t)
+	good := ca.sign(t, &x509.Certificate{IPAddresses: []net.IP{net.ParseIP("127.0.0.1")}})
+	revoked := ca.sign(t, &x509.Certificate{IPAddresses: []net.IP{net.ParseIP("127.0.0.1")}})
+
+	// Provide the CRL both as file and via HTTP
+	crl := ca.crl(t, revoked.Leaf)
+	crlFile := filepath.Join(t.TempDir(), "ca.crl")
+	require.NoError(t, os.WriteFile(crlFile, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl}), 0600))
+	crlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(crl) //nolint:errcheck // ignore the error in test
+	}))
+	defer crlServer.Close()
+
+	tests := []struct {
+		name     string
+		source   string
+		cert     cryptotls.Certificate
+		expected string
+	}{
+		{
+			name:   "file good",
+			source: crlFile,
+			cert:   good,
+		},
+		{
+			name:     "file revoked",
+			source:   crlFile,
+			cert:     revoked,
+			expected: "is revoked",
+		},
+		{
+			name:   "url good",
+			source: crlServer.URL,
+			cert:   good,
+		},
+		{
+			name:     "url revoked",
+			source:   crlServer.URL,
+			cert:     revoked,
+			expected: "is revoked",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := tls.ClientConfig{
+				TLSCA:  ca.file(t),
+				TLSCRL: []string{tt.source},
+			}
+			err := connect(t, &client, tt.cert)
+			if tt.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRevocationCRLUnavailable(t *testing.T) {
+	ca := newTestCA(t)
+	cert := ca.sign(t, &x509.Certificate{IPAddresses: []net.IP{net.ParseIP("127.0.0.1")}})
+	crlFile := filepath.Join(t.TempDir(), "ca.crl")
+
+	client := tls.ClientConfig{
+		TLSCA:  ca.file(t),
+		TLSCRL: []string{crlFile},
+	}
+	_, err := client.TLSConfig()
+	require.ErrorContains(t, err, "loading CRL")
+
+	client.TLSRevocationSoftFail = true
+	require.NoError(t, connect(t, &client, cert))
+}
+
+func TestRevocationOCSP(t *testing.T) {
+	ca := newTestCA(t)
+
+	// Setup an OCSP responder reporting the certificates contained in the
+	// status map and unknown for all others
+	status := make(map[string]int)
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		req, err := ocsp.ParseRequest(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s, found := status[req.SerialNumber.String()]
+		if !found {
+			s = ocsp.Unknown
+		}
+		resp, err := ocsp.CreateResponse(ca.cert, ca.cert, ocsp.Response{
+			Status:       s,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+			RevokedAt:    time.Now().Add(-time.Minute),
+		}, ca.key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/ocsp-response")
+		w.Write(resp) //nolint:errcheck // ignore the error in test
+	}))
+	defer responder.Close()
+
+	newCert := func(responder string, s int) cryptotls.Certificate {
+		cert := ca.sign(t, &x509.Certificate{
+			IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+			OCSPServer:  []string{responder},
+		})
+		if s >= 0 {
+			status[cert.Leaf.SerialNumber.String()] = s
+		}
+		return cert
+	}
+
+	tests := []struct {
+		name     string
+		cert     cryptotls.Certificate
+		soft     bool
+		expected string
+	}{
+		{
+			name: "good",
+			cert: newCert(responder.URL, ocsp.Good),
+		},
+		{
+			name:     "revoked",
+			cert:     newCert(responder.URL, ocsp.Revoked),
+			expected: "is revoked",
+		},
+		{
+			name:     "unknown",
+			cert:     newCert(responder.URL, -1),
+			expected: "revocation status unknown",
+		},
+		{
+			name: "unknown soft fail",
+			cert: newCert(responder.URL, -1),
+			soft: true,
+		},
+		{
+			name:     "revoked soft fail",
+			cert:     newCert(responder.URL, ocsp.Revoked),
+			soft:     true,
+			expected: "is revoked",
+		},
+		{
+			name:     "unavailable",
+			cert:     newCert("http://127.0.0.1:1", ocsp.Good),
+			expected: "querying OCSP responder failed",
+		},
+		{
+			name: "unavailable soft fail",
+			cert: newCert("http://127.0.0.1:1", ocsp.Good),
+			soft: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := tls.ClientConfig{
+				TLSCA:                 ca.file(t),
+				TLSOCSP:               true,
+				TLSRevocationSoftFail: tt.soft,
+			}
+			err := connect(t, &client, tt.cert)
+			if tt.expected == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRevocationOCSPStale(t *testing.T) {
+	ca := newTestCA(t)
+
+	// The responder returns an outdated response, e.g. replayed by an attacker
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		req, err := ocsp.ParseRequest(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		resp, err := ocsp.CreateResponse(ca.cert, ca.cert, ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   time.Now().Add(-48 * time.Hour),
+			NextUpdate:   time.Now().Add(-24 * time.Hour),
+		}, ca.key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(resp) //nolint:errcheck // ignore the error in test
+	}))
+	defer responder.Close()
+
+	cert := ca.sign(t, &x509.Certificate{
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		OCSPServer:  []string{responder.URL},
+	})
+	client := tls.ClientConfig{
+		TLSCA:   ca.file(t),
+		TLSOCSP: true,
+	}
+	err := connect(t, &client, cert)
+	require.ErrorContains(t, err, "revocation status unknown")
+	require.ErrorContains(t, err, "OCSP response expired")
+}
+
+func TestRevocationSoftFailBackoff(t *testing.T) {
+	ca := newTestCA(t)
+
+	var requests atomic.Int32
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer responder.Close()
+
+	cert := ca.sign(t, &x509.Certificate{
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		OCSPServer:  []string{responder.URL},
+	})
+	client := tls.ClientConfig{
+		TLSCA:                 ca.file(t),
+		TLSOCSP:               true,
+		TLSRevocationSoftFail: true,
+	}
+
+	// Failures must not be retried for every new connection
+	require.NoError(t, connect(t, &client, cert))
+	require.NoError(t, connect(t, &client, cert))
+	require.Equal(t, int32(1), requests.Load())
+}
+
+func TestRevocationFetchNotBlocking(t *testing.T) {
+	ca := newTestCA(t)
+
+	// The responder hangs for the first certificate until released
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var hanging atomic.Value
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		req, err := ocsp.ParseRequest(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if req.SerialNumber.String() == hanging.Load() {
+			close(started)
+			<-release
+		}
+		resp, err := ocsp.CreateResponse(ca.cert, ca.cert, ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+		}, ca.key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(resp) //nolint:errcheck // ignore the error in test
+	}))
+	defer responder.Close()
+
+	slow := ca.sign(t, &x509.Certificate{
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		OCSPServer:  []string{responder.URL},
+	})
+	hanging.Store(slow.Leaf.SerialNumber.String())
+	fast := ca.sign(t, &x509.Certificate{
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		OCSPServer:  []string{responder.URL},
+	})
+
+	client := tls.ClientConfig{
+		TLSCA:   ca.file(t),
+		TLSOCSP: true,
+	}
+	_, err := client.TLSConfig()
+	require.NoError(t, err)
+
+	slowDone := make(chan error, 1)
+	go func() {
+		slowDone <- connect(t, &client, slow)
+	}()
+	<-started
+
+	// Checking other certificates must not wait for the hanging request
+	done := make(chan error, 1)
+	go func() {
+		done <- connect(t, &client, fast)
+	}()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "connection blocked by pending OCSP request")
+	}
+
+	close(release)
+	require.NoError(t, <-slowDone)
+}
+
+func TestRevocationInsecure(t *testing.T) {
+	client := tls.ClientConfig{
+		TLSOCSP:            true,
+		InsecureSkipVerify: true,
+	}
+	_, err := client.TLSConfig()
+	require.ErrorContains(t, err, "revocation checks cannot be used together with 'insecure_skip_verify'")
+}
+
+// connect performs a request against a server presenting the given
+// certificate and returns the error if any
+func connect(t *testing.T, client *tls.ClientConfig, cert cryptotls.Certificate) error {
+	t.Helper()
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.TLS = &cryptotls.Config{Certificates: []cryptotls.Certificate{cert}}
+	ts.StartTLS()
+	defer ts.Close()
+
+	clientTLSConfig, err := client.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	c := http.Client{
+		Transport: &http.Transport{TLSClientConfig: clientTLSConfig},
+		Timeout:   10 * time.Second,
+	}
+	resp, err := c.Get(ts.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return nil
+}
+
+// file writes the CA certificate to a file in PEM format
+func (ca *testCA) file(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+// crl creates a DER encoded CRL revoking the given certificates
+func (ca *testCA) crl(t *testing.T, revoked ...*x509.Certificate) []byte {
+	t.Helper()
+
+	entries := make([]x509.RevocationListEntry, 0, len(revoked))
+	for _, cert := range revoked {
+		entries = append(entries, x509.RevocationListEntry{
+			SerialNumber:   cert.SerialNumber,
+			RevocationTime: time.Now().Add(-time.Minute),
+		})
+	}
+	template := &x509.RevocationList{
+		Number:                    big.NewInt(1),
+		ThisUpdate:                time.Now().Add(-time.Minute),
+		NextUpdate:                time.Now().Add(time.Hour),
+		RevokedCertificateEntries: entries,
+	}
+	crl, err := x509.CreateRevocationList(rand.Reader, template, ca.cert, ca.key)
+	require.NoError(t, err)
+	return crl
+}
@@ -0,0 +1,133 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+)
+
+const (
+	// Maximum time to wait for the initial certificate of the workload API
+	spiffeFetchTimeout = 30 * time.Second
+	// Maximum time to wait for the workload API when establishing a connection
+	spiffeHandshakeTimeout = 5 * time.Second
+	// Minimum time between two requests to the workload API
+	spiffeRefreshInterval = time.Second
+)
+
+// spiffeSource provides the X509-SVID and trust bundles of a SPIFFE workload
+// API. Instead of keeping a stream to the API open, the current state is
+// fetched when establishing connections, so rotated certificates are used
+// without holding any resources requiring cleanup.
+type spiffeSource struct {
+	addr string
+
+	sync.Mutex
+	svid    *x509svid.SVID
+	bundles *x509bundle.Set
+	fetched time.Time
+}
+
+// hookSpiffe sets up the client certificate to be taken from the SPIFFE
+// workload API. The certificate is fetched on handshakes so rotated
+// certificates are used automatically.
+func (c *ClientConfig) hookSpiffe(tlsConfig *tls.Config) error {
+	if c.TLSCert != "" || c.TLSKey != "" {
+		return errors.New("'tls_spiffe_socket' cannot be used together with 'tls_cert' or 'tls_key'")
+	}
+
+	var serverID spiffeid.ID
+	if c.TLSSpiffeServerID != "" {
+		if c.TLSCA != "" {
+			return errors.New("'tls_spiffe_server_id' cannot be used together with 'tls_ca'")
+		}
+		id, err := spiffeid.FromString(c.TLSSpiffeServerID)
+		if err != nil {
+			return fmt.Errorf("invalid SPIFFE server ID %q: %w", c.TLSSpiffeServerID, err)
+		}
+		serverID = id
+	}
+
+	// Reuse the source for multiple calls and fail early if the workload API
+	// does not provide a certificate
+	if c.spiffe == nil {
+		source := &spiffeSource{addr: c.TLSSpiffeSocket}
+		if err := source.fetch(spiffeFetchTimeout); err != nil {
+			return fmt.Errorf("fetching certificate from SPIFFE workload API %q failed: %w", c.TLSSpiffeSocket, err)
+		}
+		c.spiffe = source
+	}
+
+	if c.TLSSpiffeServerID == "" {
+		// Verify the server using the configured or system root certificates
+		tlsConfig.GetClientCertificate = tlsconfig.GetClientCertificate(c.spiffe)
+		return nil
+	}
+
+	// Verify the server's identity against the trust bundle of the workload
+	// API instead of using the root certificates
+	tlsconfig.HookMTLSClientConfig(tlsConfig, c.spiffe, c.spiffe, tlsconfig.AuthorizeID(serverID))
+	return nil
+}
+
+// GetX509SVID returns the current X509-SVID of the workload API, it
+// implements the x509svid.Source interface
+func (s *spiffeSource) GetX509SVID() (*x509svid.SVID, error) {
+	if err := s.fetch(spiffeHandshakeTimeout); err != nil {
+		return nil, err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	return s.svid, nil
+}
+
+// GetX509BundleForTrustDomain returns the current trust bundle of the given
+// trust domain, it implements the x509bundle.Source interface
+func (s *spiffeSource) GetX509BundleForTrustDomain(trustDomain spiffeid.TrustDomain) (*x509bundle.Bundle, error) {
+	if err := s.fetch(spiffeHandshakeTimeout); err != nil {
+		return nil, err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	return s.bundles.GetX509BundleForTrustDomain(trustDomain)
+}
+
+// fetch updates the SVID and bundles from the workload API if the last update
+// is older than the refresh interval. The previous SVID is kept if the API is
+// not available, as long as the certificate did not expire.
+func (s *spiffeSource) fetch(timeout time.Duration) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.svid != nil && time.Since(s.fetched) < spiffeRefreshInterval {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	x509Context, err := workloadapi.FetchX509Context(ctx, workloadapi.WithAddr(s.addr))
+	if err != nil {
+		if s.svid != nil && time.Now().Before(s.svid.Certificates[0].NotAfter) {
+			// Do not retry on every connection while the API is unavailable
+			s.fetched = time.Now()
+			return nil
+		}
+		return err
+	}
+
+	s.svid = x509Context.DefaultSVID()
+	s.bundles = x509Context.Bundles
+	s.fetched = time.Now()
+	return nil
+}
@@ -0,0 +1,360 @@
+package tls_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	cryptotls "crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/influxdata/telegraf/plugins/common/tls"
+)
+
+func TestSpiffeConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   tls.ClientConfig
+		expected string
+	}{
+		{
+			name: "with certificate",
+			client: tls.ClientConfig{
+				TLSSpiffeSocket: "unix:///run/spire/agent.sock",
+				TLSCert:         pki.ClientCertPath(),
+				TLSKey:          pki.ClientKeyPath(),
+			},
+			expected: "'tls_spiffe_socket' cannot be used together with 'tls_cert' or 'tls_key'",
+		},
+		{
+			name:     "server ID without socket",
+			client:   tls.ClientConfig{TLSSpiffeServerID: "spiffe://example.org/server"},
+			expected: "'tls_spiffe_server_id' requires 'tls_spiffe_socket'",
+		},
+		{
+			name: "server ID with CA",
+			client: tls.ClientConfig{
+				TLSSpiffeSocket:   "unix:///run/spire/agent.sock",
+				TLSSpiffeServerID: "spiffe://example.org/server",
+				TLSCA:             pki.CACertPath(),
+			},
+			expected: "'tls_spiffe_server_id' cannot be used together with 'tls_ca'",
+		},
+		{
+			name: "invalid server ID",
+			client: tls.ClientConfig{
+				TLSSpiffeSocket:   "unix:///run/spire/agent.sock",
+				TLSSpiffeServerID: "https://example.org/server",
+			},
+			expected: "invalid SPIFFE server ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.client.TLSConfig()
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestSpiffeConnect(t *testing.T) {
+	ca := newTestCA(t)
+	api := newFakeWorkloadAPI(t, ca.issue(t, "spiffe://example.org/telegraf"), ca.cert.Raw)
+
+	// Setup a server requiring a client certificate and recording the
+	// serial number of the presented certificate
+	var mu sync.Mutex
+	var serial *big.Int
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		serial = r.TLS.PeerCertificates[0].SerialNumber
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.TLS = &cryptotls.Config{
+		Certificates: []cryptotls.Certificate{ca.issue(t, "spiffe://example.org/server")},
+		ClientAuth:   cryptotls.RequireAndVerifyClientCert,
+		ClientCAs:    ca.pool(),
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	clientConfig := tls.ClientConfig{
+		TLSSpiffeSocket:   "unix://" + api.path,
+		TLSSpiffeServerID: "spiffe://example.org/server",
+	}
+	clientTLSConfig, err := clientConfig.TLSConfig()
+	require.NoError(t, err)
+
+	client := http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   clientTLSConfig,
+			DisableKeepAlives: true,
+		},
+		Timeout: 10 * time.Second,
+	}
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mu.Lock()
+	first := serial
+	mu.Unlock()
+	require.NotNil(t, first)
+
+	// Rotated certificates must be used for new connections
+	rotated := ca.issue(t, "spiffe://example.org/telegraf")
+	api.update(rotated)
+	require.Eventually(t, func() bool {
+		resp, err := client.Get(ts.URL)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+
+		mu.Lock()
+		defer mu.Unlock()
+		return serial.Cmp(rotated.Leaf.SerialNumber) == 0
+	}, 5*time.Second, 50*time.Millisecond)
+	require.NotEqual(t, 0, first.Cmp(rotated.Leaf.SerialNumber))
+}
+
+func TestSpiffeWrongServerID(t *testing.T) {
+	ca := newTestCA(t)
+	api := newFakeWorkloadAPI(t, ca.issue(t, "spiffe://example.org/telegraf"), ca.cert.Raw)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.TLS = &cryptotls.Config{
+		Certificates: []cryptotls.Certificate{ca.issue(t, "spiffe://example.org/other")},
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	clientConfig := tls.ClientConfig{
+		TLSSpiffeSocket:   "unix://" + api.path,
+		TLSSpiffeServerID: "spiffe://example.org/server",
+	}
+	clientTLSConfig, err := clientConfig.TLSConfig()
+	require.NoError(t, err)
+
+	client := http.Client{
+		Transport: &http.Transport{TLSClientConfig: clientTLSConfig},
+		Timeout:   10 * time.Second,
+	}
+	_, err = client.Get(ts.URL) //nolint:bodyclose // the request is expected to fail
+	require.ErrorContains(t, err, `unexpected ID "spiffe://example.org/other"`)
+}
+
+func TestSpiffeWorkloadAPIUnavailable(t *testing.T) {
+	ca := newTestCA(t)
+	api := newFakeWorkloadAPI(t, ca.issue(t, "spiffe://example.org/telegraf"), ca.cert.Raw)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.TLS = &cryptotls.Config{
+		Certificates: []cryptotls.Certificate{ca.issue(t, "spiffe://example.org/server")},
+		ClientAuth:   cryptotls.RequireAndVerifyClientCert,
+		ClientCAs:    ca.pool(),
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	clientConfig := tls.ClientConfig{
+		TLSSpiffeSocket:   "unix://" + api.path,
+		TLSSpiffeServerID: "spiffe://example.org/server",
+	}
+	clientTLSConfig, err := clientConfig.TLSConfig()
+	require.NoError(t, err)
+
+	// The last certificate must still be used if the workload API becomes
+	// unavailable, wait for the refresh interval to force a new request
+	api.server.Stop()
+	time.Sleep(1100 * time.Millisecond)
+
+	client := http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   clientTLSConfig,
+			DisableKeepAlives: true,
+		},
+		Timeout: 10 * time.Second,
+	}
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	id, err := url.Parse("spiffe://example.org")
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "example.org"},
+		URIs:                  []*url.URL{id},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(raw)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// issue creates an X509-SVID for the given SPIFFE ID
+func (ca *testCA) issue(t *testing.T, spiffeID string) cryptotls.Certificate {
+	t.Helper()
+
+	id, err := url.Parse(spiffeID)
+	require.NoError(t, err)
+	return ca.sign(t, &x509.Certificate{URIs: []*url.URL{id}})
+}
+
+// sign creates a certificate based on the given template adding a random
+// serial number, validity and usage
+func (ca *testCA) sign(t *testing.T, template *x509.Certificate) cryptotls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth}
+	raw, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(raw)
+	require.NoError(t, err)
+
+	return cryptotls.Certificate{
+		Certificate: [][]byte{raw},
+		PrivateKey:  key,
+		Leaf:        cert,
+	}
+}
+
+// fakeWorkloadAPI serves X509-SVIDs via the SPIFFE workload API on a unix
+// socket and pushes updates to all connected clients
+type fakeWorkloadAPI struct {
+	workload.UnimplementedSpiffeWorkloadAPIServer
+
+	path   string
+	bundle []byte
+	server *grpc.Server
+
+	sync.Mutex
+	svid    cryptotls.Certificate
+	updates []chan struct{}
+}
+
+func newFakeWorkloadAPI(t *testing.T, svid cryptotls.Certificate, bundle []byte) *fakeWorkloadAPI {
+	t.Helper()
+
+	api := &fakeWorkloadAPI{
+		path:   filepath.Join(t.TempDir(), "agent.sock"),
+		bundle: bundle,
+		svid:   svid,
+	}
+
+	listener, err := net.Listen("unix", api.path)
+	require.NoError(t, err)
+
+	api.server = grpc.NewServer()
+	workload.RegisterSpiffeWorkloadAPIServer(api.server, api)
+	go api.server.Serve(listener) //nolint:errcheck // errors are returned after stopping the server
+	t.Cleanup(api.server.Stop)
+
+	return api
+}
+
+func (api *fakeWorkloadAPI) FetchX509SVID(_ *workload.X509SVIDRequest, stream workload.SpiffeWorkloadAPI_FetchX509SVIDServer) error {
+	notify := make(chan struct{}, 1)
+	api.Lock()
+	api.updates = append(api.updates, notify)
+	api.Unlock()
+
+	for {
+		api.Lock()
+		svid := api.svid
+		api.Unlock()
+
+		key, err := x509.MarshalPKCS8PrivateKey(svid.PrivateKey)
+		if err != nil {
+			return err
+		}
+		response := &workload.X509SVIDResponse{
+			Svids: []*workload.X509SVID{
+				{
+					SpiffeId:    svid.Leaf.URIs[0].String(),
+					X509Svid:    svid.Certificate[0],
+					X509SvidKey: key,
+					Bundle:      api.bundle,
+				},
+			},
+		}
+		if err := stream.Send(response); err != nil {
+			return err
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-notify:
+		}
+	}
+}
+
+func (api *fakeWorkloadAPI) update(svid cryptotls.Certificate) {
+	api.Lock()
+	defer api.Unlock()
+
+	api.svid = svid
+	for _, notify := range api.updates {
+		select {
+		case notify <- struct{}{}:
+		default:
+		}
+	}
+}
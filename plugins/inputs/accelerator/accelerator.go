@@ -0,0 +1,144 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package accelerator
+
+import (
+	_ "embed"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var supportedBackends = []string{"rocm", "habana", "tpu"}
+
+type Accelerator struct {
+	Backends      []string        `toml:"backends"`
+	ROCmSMIPath   string          `toml:"rocm_smi_path"`
+	HLSMIPath     string          `toml:"hl_smi_path"`
+	TPUMetricsURL string          `toml:"tpu_metrics_url"`
+	Timeout       config.Duration `toml:"timeout"`
+	Log           telegraf.Logger `toml:"-"`
+
+	backends []backend
+}
+
+// backend provides the metrics of the accelerator devices of a vendor
+type backend interface {
+	vendor() string
+	devices() ([]device, error)
+}
+
+// device contains the metrics of a single accelerator using the field names
+// of the gpu input, so all accelerators share the same schema
+type device struct {
+	index  int
+	name   string
+	uuid   string
+	fields map[string]interface{}
+}
+
+func (*Accelerator) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *Accelerator) Init() error {
+	for _, b := range a.Backends {
+		if !slices.Contains(supportedBackends, b) {
+			return fmt.Errorf("invalid backend %q", b)
+		}
+	}
+
+	// Probe for the available backends if not configured explicitly
+	names := a.Backends
+	if len(names) == 0 {
+		for _, name := range supportedBackends {
+			if a.available(name) {
+				names = append(names, name)
+			} else {
+				a.Log.Debugf("Backend %q not available", name)
+			}
+		}
+		if len(names) == 0 {
+			a.Log.Warn("No accelerator backend available, no metrics will be collected")
+		}
+	}
+
+	a.backends = make([]backend, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case "rocm":
+			a.backends = append(a.backends, &rocmSMI{binPath: a.ROCmSMIPath, timeout: time.Duration(a.Timeout)})
+		case "habana":
+			a.backends = append(a.backends, &hlSMI{binPath: a.HLSMIPath, timeout: time.Duration(a.Timeout)})
+		case "tpu":
+			a.backends = append(a.backends, &tpuRuntime{url: a.TPUMetricsURL, timeout: time.Duration(a.Timeout)})
+		}
+	}
+
+	return nil
+}
+
+func (a *Accelerator) Gather(acc telegraf.Accumulator) error {
+	for _, b := range a.backends {
+		devices, err := b.devices()
+		if err != nil {
+			acc.AddError(fmt.Errorf("gathering %s devices failed: %w", b.vendor(), err))
+			continue
+		}
+
+		for _, dev := range devices {
+			if len(dev.fields) == 0 {
+				continue
+			}
+			tags := map[string]string{
+				"vendor": b.vendor(),
+				"index":  strconv.Itoa(dev.index),
+			}
+			if dev.name != "" {
+				tags["name"] = dev.name
+			}
+			if dev.uuid != "" {
+				tags["uuid"] = dev.uuid
+			}
+			acc.AddFields("accelerator", dev.fields, tags)
+		}
+	}
+
+	return nil
+}
+
+// available checks if the tools or devices required for the backend exist
+func (a *Accelerator) available(name string) bool {
+	switch name {
+	case "rocm":
+		_, err := exec.LookPath(a.ROCmSMIPath)
+		return err == nil
+	case "habana":
+		_, err := exec.LookPath(a.HLSMIPath)
+		return err == nil
+	case "tpu":
+		matches, err := filepath.Glob("/dev/accel*")
+		return err == nil && len(matches) > 0
+	}
+	return false
+}
+
+func init() {
+	inputs.Add("accelerator", func() telegraf.Input {
+		return &Accelerator{
+			ROCmSMIPath:   "rocm-smi",
+			HLSMIPath:     "hl-smi",
+			TPUMetricsURL: "http://localhost:2112/metrics",
+			Timeout:       config.Duration(5 * time.Second),
+		}
+	})
+}
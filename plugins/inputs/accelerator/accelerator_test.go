@@ -0,0 +1,250 @@
+package accelerator
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type fakeBackend struct {
+	name string
+	devs []device
+	err  error
+}
+
+func (b *fakeBackend) vendor() string {
+	return b.name
+}
+
+func (b *fakeBackend) devices() ([]device, error) {
+	return b.devs, b.err
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &Accelerator{
+		Backends: []string{"rocm", "cuda"},
+		Log:      &testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid backend "cuda"`)
+}
+
+func TestInitProbe(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	plugin := &Accelerator{
+		ROCmSMIPath: missing,
+		HLSMIPath:   missing,
+		Log:         &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	for _, b := range plugin.backends {
+		require.Equal(t, "google", b.vendor(), "unexpected backend %q", b.vendor())
+	}
+
+	// Explicitly configured backends are always used
+	plugin = &Accelerator{
+		Backends:    []string{"rocm", "habana"},
+		ROCmSMIPath: missing,
+		HLSMIPath:   missing,
+		Log:         &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.Len(t, plugin.backends, 2)
+}
+
+func TestGather(t *testing.T) {
+	plugin := &Accelerator{Log: &testutil.Logger{}}
+	plugin.backends = []backend{
+		&fakeBackend{name: "amd", err: errors.New("command failed")},
+		&fakeBackend{
+			name: "habana",
+			devs: []device{
+				{index: 0, name: "HL-225", uuid: "01P0", fields: map[string]interface{}{"utilization_gpu": int64(10)}},
+				{index: 1, name: "HL-225"},
+			},
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "gathering amd devices failed: command failed")
+
+	// Devices without fields are skipped
+	expected := []telegraf.Metric{
+		metric.New(
+			"accelerator",
+			map[string]string{"vendor": "habana", "index": "0", "name": "HL-225", "uuid": "01P0"},
+			map[string]interface{}{"utilization_gpu": int64(10)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestParseROCmSMI(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "rocm-smi.json"))
+	require.NoError(t, err)
+
+	devices, err := parseROCmSMI(data)
+	require.NoError(t, err)
+
+	expected := []device{
+		{
+			index: 0,
+			name:  "AMD Instinct MI210",
+			uuid:  "0x5e8a2c1b93f40d27",
+			fields: map[string]interface{}{
+				"utilization_gpu":      int64(12),
+				"utilization_memory":   int64(3),
+				"memory_total":         int64(68702699520),
+				"memory_used":          int64(150749184),
+				"memory_free":          int64(68551950336),
+				"temperature":          38.0,
+				"temperature_junction": 41.0,
+				"temperature_memory":   45.0,
+				"power_draw":           42.0,
+			},
+		},
+		{
+			index: 1,
+			name:  "AMD Instinct MI210",
+			fields: map[string]interface{}{
+				"utilization_gpu":    int64(0),
+				"utilization_memory": int64(0),
+				"memory_total":       int64(68702699520),
+				"memory_used":        int64(10764288),
+				"memory_free":        int64(68691935232),
+				"temperature":        36.0,
+				"temperature_memory": 43.0,
+				"power_draw":         40.0,
+			},
+		},
+	}
+	require.Equal(t, expected, devices)
+}
+
+func TestParseHLSMI(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "hl-smi.csv"))
+	require.NoError(t, err)
+
+	devices, err := parseHLSMI(data)
+	require.NoError(t, err)
+
+	expected := []device{
+		{
+			index: 0,
+			name:  "HL-225",
+			uuid:  "01P0-HL2080A0-15-TNPS34-04-07-09",
+			fields: map[string]interface{}{
+				"utilization_gpu": int64(87),
+				"memory_total":    int64(103079215104),
+				"memory_used":     int64(68719476736),
+				"memory_free":     int64(34359738368),
+				"temperature":     46.0,
+				"power_draw":      412.0,
+			},
+		},
+		{
+			index: 1,
+			name:  "HL-225",
+			uuid:  "01P0-HL2080A0-15-TNPS34-04-07-10",
+			fields: map[string]interface{}{
+				"utilization_gpu": int64(0),
+				"memory_total":    int64(103079215104),
+				"memory_used":     int64(704643072),
+				"memory_free":     int64(102374572032),
+				"temperature":     27.0,
+			},
+		},
+	}
+	require.Equal(t, expected, devices)
+
+	_, err = parseHLSMI([]byte("0, HL-225\n"))
+	require.ErrorContains(t, err, "decoding output failed")
+}
+
+func TestGatherTPU(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "tpu.txt"))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if _, err := w.Write(data); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Accelerator{
+		Backends:      []string{"tpu"},
+		TPUMetricsURL: server.URL + "/metrics",
+		Timeout:       config.Duration(5 * time.Second),
+		Log:           &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"accelerator",
+			map[string]string{
+				"vendor": "google",
+				"index":  "0",
+				"name":   "tpu-v5-lite-podslice",
+				"uuid":   "4804690994094478883-0",
+			},
+			map[string]interface{}{
+				"utilization_gpu":        92.0,
+				"utilization_tensorcore": 55.5,
+				"memory_total":           int64(17179869184),
+				"memory_used":            int64(12884901888),
+				"memory_free":            int64(4294967296),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"accelerator",
+			map[string]string{
+				"vendor": "google",
+				"index":  "1",
+				"name":   "tpu-v5-lite-podslice",
+				"uuid":   "4804690994094478883-1",
+			},
+			map[string]interface{}{
+				"utilization_gpu":        0.0,
+				"utilization_tensorcore": 0.0,
+				"memory_total":           int64(17179869184),
+				"memory_used":            int64(0),
+				"memory_free":            int64(17179869184),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// Fail on invalid endpoints
+	plugin.backends = []backend{&tpuRuntime{url: server.URL + "/invalid", timeout: time.Second}}
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "404 Not Found")
+}
@@ -0,0 +1,99 @@
+package accelerator
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// Properties queried from hl-smi in the order of the output columns
+var hlSMIQuery = []string{
+	"index",
+	"name",
+	"uuid",
+	"utilization.aip",
+	"memory.total",
+	"memory.used",
+	"memory.free",
+	"temperature.aip",
+	"power.draw",
+}
+
+// hlSMI collects the metrics of Intel Gaudi (Habana) accelerators using the
+// hl-smi tool
+type hlSMI struct {
+	binPath string
+	timeout time.Duration
+}
+
+func (*hlSMI) vendor() string {
+	return "habana"
+}
+
+func (h *hlSMI) devices() ([]device, error) {
+	cmd := exec.Command(h.binPath, "-Q", strings.Join(hlSMIQuery, ","), "-f", "csv,noheader,nounits")
+	data, err := internal.StdOutputTimeout(cmd, h.timeout)
+	if err != nil {
+		return nil, err
+	}
+	return parseHLSMI(data)
+}
+
+func parseHLSMI(data []byte) ([]device, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = len(hlSMIQuery)
+	reader.TrimLeadingSpace = true
+
+	var devices []device
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding output failed: %w", err)
+		}
+
+		index, err := strconv.Atoi(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid device index %q", record[0])
+		}
+		dev := device{
+			index:  index,
+			name:   record[1],
+			uuid:   record[2],
+			fields: make(map[string]interface{}),
+		}
+		if dev.uuid == "N/A" {
+			dev.uuid = ""
+		}
+
+		if v, err := strconv.ParseInt(record[3], 10, 64); err == nil {
+			dev.fields["utilization_gpu"] = v
+		}
+		// Memory is reported in MiB
+		for i, name := range []string{"memory_total", "memory_used", "memory_free"} {
+			if v, err := strconv.ParseInt(record[4+i], 10, 64); err == nil {
+				dev.fields[name] = v * 1024 * 1024
+			}
+		}
+		if v, err := strconv.ParseFloat(record[7], 64); err == nil {
+			dev.fields["temperature"] = v
+		}
+		if v, err := strconv.ParseFloat(record[8], 64); err == nil {
+			dev.fields["power_draw"] = v
+		}
+
+		devices = append(devices, dev)
+	}
+
+	return devices, nil
+}
@@ -0,0 +1,117 @@
+package accelerator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// rocmSMI collects the metrics of AMD GPUs using the rocm-smi tool
+type rocmSMI struct {
+	binPath string
+	timeout time.Duration
+}
+
+func (*rocmSMI) vendor() string {
+	return "amd"
+}
+
+func (r *rocmSMI) devices() ([]device, error) {
+	cmd := exec.Command(r.binPath,
+		"--showproductname",
+		"--showuniqueid",
+		"--showuse",
+		"--showmemuse",
+		"--showmeminfo", "vram",
+		"--showtemp",
+		"--showpower",
+		"--json",
+	)
+	data, err := internal.StdOutputTimeout(cmd, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	return parseROCmSMI(data)
+}
+
+func parseROCmSMI(data []byte) ([]device, error) {
+	var cards map[string]map[string]string
+	if err := json.Unmarshal(data, &cards); err != nil {
+		return nil, fmt.Errorf("decoding output failed: %w", err)
+	}
+
+	devices := make([]device, 0, len(cards))
+	for card, values := range cards {
+		// Skip the system information
+		id, found := strings.CutPrefix(card, "card")
+		if !found {
+			continue
+		}
+		index, err := strconv.Atoi(id)
+		if err != nil {
+			continue
+		}
+
+		dev := device{
+			index:  index,
+			name:   firstOf(values, "Card Series", "Card series"),
+			fields: make(map[string]interface{}),
+		}
+		if uuid := values["Unique ID"]; uuid != "N/A" {
+			dev.uuid = uuid
+		}
+
+		if v, err := strconv.ParseInt(values["GPU use (%)"], 10, 64); err == nil {
+			dev.fields["utilization_gpu"] = v
+		}
+		if v, err := strconv.ParseInt(values["GPU memory use (%)"], 10, 64); err == nil {
+			dev.fields["utilization_memory"] = v
+		}
+		total, errTotal := strconv.ParseInt(values["VRAM Total Memory (B)"], 10, 64)
+		if errTotal == nil {
+			dev.fields["memory_total"] = total
+		}
+		used, errUsed := strconv.ParseInt(values["VRAM Total Used Memory (B)"], 10, 64)
+		if errUsed == nil {
+			dev.fields["memory_used"] = used
+		}
+		if errTotal == nil && errUsed == nil {
+			dev.fields["memory_free"] = total - used
+		}
+		if v, err := strconv.ParseFloat(values["Temperature (Sensor edge) (C)"], 64); err == nil {
+			dev.fields["temperature"] = v
+		}
+		if v, err := strconv.ParseFloat(values["Temperature (Sensor junction) (C)"], 64); err == nil {
+			dev.fields["temperature_junction"] = v
+		}
+		if v, err := strconv.ParseFloat(values["Temperature (Sensor memory) (C)"], 64); err == nil {
+			dev.fields["temperature_memory"] = v
+		}
+		// Newer ROCm versions report the current instead of the average power
+		power := firstOf(values, "Average Graphics Package Power (W)", "Current Socket Graphics Package Power (W)")
+		if v, err := strconv.ParseFloat(power, 64); err == nil {
+			dev.fields["power_draw"] = v
+		}
+
+		devices = append(devices, dev)
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].index < devices[j].index })
+
+	return devices, nil
+}
+
+// firstOf returns the first existing value of the given keys
+func firstOf(values map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v, found := values[k]; found {
+			return v
+		}
+	}
+	return ""
+}
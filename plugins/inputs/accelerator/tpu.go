@@ -0,0 +1,141 @@
+package accelerator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// Mapping of the TPU runtime metrics to the accelerator fields
+var tpuMetrics = map[string]string{
+	"duty_cycle":             "utilization_gpu",
+	"tensorcore_utilization": "utilization_tensorcore",
+	"memory_used":            "memory_used",
+	"memory_total":           "memory_total",
+}
+
+// tpuRuntime collects the metrics of Google Cloud TPU chips from the
+// Prometheus endpoint of the TPU runtime metrics exporter
+type tpuRuntime struct {
+	url     string
+	timeout time.Duration
+	client  *http.Client
+}
+
+func (*tpuRuntime) vendor() string {
+	return "google"
+}
+
+func (t *tpuRuntime) devices() ([]device, error) {
+	if t.client == nil {
+		t.client = &http.Client{Timeout: t.timeout}
+	}
+
+	resp, err := t.client.Get(t.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("querying %q failed: %s", t.url, resp.Status)
+	}
+
+	format := expfmt.ResponseFormat(resp.Header)
+	if format.FormatType() == expfmt.TypeUnknown {
+		format = expfmt.NewFormat(expfmt.TypeTextPlain)
+	}
+	return parseTPUMetrics(resp.Body, format)
+}
+
+func parseTPUMetrics(r io.Reader, format expfmt.Format) ([]device, error) {
+	decoder := expfmt.NewDecoder(r, format)
+
+	chips := make(map[string]*device)
+	for {
+		var mf dto.MetricFamily
+		if err := decoder.Decode(&mf); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("decoding metrics failed: %w", err)
+		}
+
+		field, found := tpuMetrics[mf.GetName()]
+		if !found {
+			continue
+		}
+
+		for _, m := range mf.GetMetric() {
+			var id, model string
+			for _, label := range m.GetLabel() {
+				switch label.GetName() {
+				case "accelerator_id":
+					id = label.GetValue()
+				case "model":
+					model = label.GetValue()
+				}
+			}
+			if id == "" {
+				continue
+			}
+
+			var value float64
+			switch {
+			case m.GetGauge() != nil:
+				value = m.GetGauge().GetValue()
+			case m.GetUntyped() != nil:
+				value = m.GetUntyped().GetValue()
+			default:
+				continue
+			}
+
+			chip, found := chips[id]
+			if !found {
+				chip = &device{uuid: id, fields: make(map[string]interface{})}
+				chips[id] = chip
+			}
+			if model != "" {
+				chip.name = model
+			}
+			if strings.HasPrefix(field, "memory_") {
+				chip.fields[field] = int64(value)
+			} else {
+				chip.fields[field] = value
+			}
+		}
+	}
+
+	devices := make([]device, 0, len(chips))
+	for _, chip := range chips {
+		total, hasTotal := chip.fields["memory_total"].(int64)
+		used, hasUsed := chip.fields["memory_used"].(int64)
+		if hasTotal && hasUsed {
+			chip.fields["memory_free"] = total - used
+		}
+		devices = append(devices, *chip)
+	}
+
+	// The accelerator ID ends with the index of the chip on the host, fall
+	// back to the order of the IDs otherwise
+	sort.Slice(devices, func(i, j int) bool { return devices[i].uuid < devices[j].uuid })
+	for i := range devices {
+		devices[i].index = i
+		if pos := strings.LastIndex(devices[i].uuid, "-"); pos >= 0 {
+			if index, err := strconv.Atoi(devices[i].uuid[pos+1:]); err == nil {
+				devices[i].index = index
+			}
+		}
+	}
+	sort.SliceStable(devices, func(i, j int) bool { return devices[i].index < devices[j].index })
+
+	return devices, nil
+}
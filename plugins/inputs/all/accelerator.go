@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.accelerator
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/accelerator" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.beats_listener
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/beats_listener" // register plugin
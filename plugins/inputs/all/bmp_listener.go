@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.bmp_listener
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/bmp_listener" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.cloud_billing
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/cloud_billing" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.dirstats
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/dirstats" // register plugin
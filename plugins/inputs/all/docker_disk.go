@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.docker_disk
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/docker_disk" // register plugin
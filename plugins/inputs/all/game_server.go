@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.game_server
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/game_server" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.gitlab
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/gitlab" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.gpu
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/gpu" // register plugin
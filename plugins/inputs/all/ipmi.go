@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.ipmi
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/ipmi" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.nfsd
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/nfsd" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.openvswitch
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/openvswitch" // register plugin
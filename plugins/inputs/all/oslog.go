@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.oslog
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/oslog" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.pdu
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/pdu" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.webserver
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/webserver" // register plugin
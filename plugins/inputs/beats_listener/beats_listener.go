@@ -0,0 +1,278 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package beats_listener
+
+import (
+	"bytes"
+	"crypto/tls"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type BeatsListener struct {
+	ServiceAddress string          `toml:"service_address"`
+	MaxConnections int             `toml:"max_connections"`
+	MaxWindowSize  uint32          `toml:"max_window_size"`
+	ReadTimeout    config.Duration `toml:"read_timeout"`
+	TagKeys        []string        `toml:"tag_keys"`
+	Log            telegraf.Logger `toml:"-"`
+	common_tls.ServerConfig
+
+	acc       telegraf.Accumulator
+	tagFilter filter.Filter
+	listener  net.Listener
+
+	connections map[net.Conn]bool
+	sync.Mutex
+	wg sync.WaitGroup
+}
+
+func (*BeatsListener) SampleConfig() string {
+	return sampleConfig
+}
+
+func (b *BeatsListener) Init() error {
+	if b.ServiceAddress == "" {
+		b.ServiceAddress = "tcp://:5044"
+	}
+
+	if b.MaxConnections < 0 {
+		return errors.New("max_connections must not be negative")
+	}
+	if b.MaxWindowSize == 0 {
+		return errors.New("max_window_size must be positive")
+	}
+
+	f, err := filter.Compile(b.TagKeys)
+	if err != nil {
+		return fmt.Errorf("compiling tag keys failed: %w", err)
+	}
+	b.tagFilter = f
+
+	return nil
+}
+
+func (b *BeatsListener) Start(acc telegraf.Accumulator) error {
+	b.acc = acc
+
+	protocol, addr, found := strings.Cut(b.ServiceAddress, "://")
+	if !found {
+		return fmt.Errorf("invalid service address %q", b.ServiceAddress)
+	}
+	switch protocol {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return fmt.Errorf("unknown protocol %q in %q", protocol, b.ServiceAddress)
+	}
+
+	tlsCfg, err := b.ServerConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	if tlsCfg == nil {
+		b.listener, err = net.Listen(protocol, addr)
+	} else {
+		b.listener, err = tls.Listen(protocol, addr, tlsCfg)
+	}
+	if err != nil {
+		return err
+	}
+	b.Log.Infof("Listening on %s://%s", protocol, b.listener.Addr())
+
+	b.connections = make(map[net.Conn]bool)
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.listen()
+	}()
+
+	return nil
+}
+
+func (*BeatsListener) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (b *BeatsListener) Stop() {
+	if b.listener != nil {
+		b.listener.Close()
+	}
+
+	b.Lock()
+	for c := range b.connections {
+		c.Close()
+	}
+	b.Unlock()
+
+	b.wg.Wait()
+}
+
+func (b *BeatsListener) listen() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				b.Log.Errorf("Accepting connection failed: %v", err)
+			}
+			return
+		}
+
+		b.Lock()
+		if b.MaxConnections > 0 && len(b.connections) >= b.MaxConnections {
+			b.Unlock()
+			b.Log.Warnf("Refusing connection from %s: too many connections", conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
+		b.connections[conn] = true
+		b.Unlock()
+
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			defer func() {
+				b.Lock()
+				delete(b.connections, conn)
+				b.Unlock()
+				conn.Close()
+			}()
+			if err := b.handle(conn); err != nil {
+				b.Log.Errorf("Handling connection from %s failed: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+func (b *BeatsListener) handle(conn net.Conn) error {
+	r := &reader{r: conn, maxWindow: b.MaxWindowSize}
+	for {
+		if b.ReadTimeout > 0 {
+			if err := conn.SetReadDeadline(time.Now().Add(time.Duration(b.ReadTimeout))); err != nil {
+				return fmt.Errorf("setting read deadline failed: %w", err)
+			}
+		}
+
+		events, err := r.readBatch()
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		if len(events) == 0 {
+			continue
+		}
+
+		for _, e := range events {
+			if err := b.addEvent(e); err != nil {
+				b.acc.AddError(fmt.Errorf("decoding event %d from %s failed: %w", e.seq, conn.RemoteAddr(), err))
+			}
+		}
+
+		// Acknowledge the whole batch to let the client send the next one
+		if err := writeACK(conn, events[len(events)-1].seq); err != nil {
+			return fmt.Errorf("sending acknowledgment failed: %w", err)
+		}
+	}
+}
+
+func (b *BeatsListener) addEvent(e event) error {
+	var doc map[string]interface{}
+	if e.json != nil {
+		decoder := json.NewDecoder(bytes.NewReader(e.json))
+		decoder.UseNumber()
+		if err := decoder.Decode(&doc); err != nil {
+			return err
+		}
+	} else {
+		doc = make(map[string]interface{}, len(e.data))
+		for k, v := range e.data {
+			doc[k] = v
+		}
+	}
+
+	tags := make(map[string]string)
+	fields := make(map[string]interface{})
+
+	// Extract the beat name from the metadata and drop the remaining
+	// internal information
+	if md, ok := doc["@metadata"].(map[string]interface{}); ok {
+		if beat, ok := md["beat"].(string); ok {
+			tags["beat"] = beat
+		}
+	}
+	delete(doc, "@metadata")
+
+	timestamp := time.Now()
+	if v, ok := doc["@timestamp"].(string); ok {
+		if ts, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			timestamp = ts
+		} else {
+			b.Log.Debugf("Invalid timestamp %q, using current time", v)
+		}
+	}
+	delete(doc, "@timestamp")
+
+	flatten("", doc, func(k string, v interface{}) {
+		if b.tagFilter != nil && b.tagFilter.Match(k) {
+			tags[k] = fmt.Sprintf("%v", v)
+			return
+		}
+		fields[k] = v
+	})
+	if len(fields) == 0 {
+		return errors.New("no fields")
+	}
+
+	b.acc.AddFields("beats", fields, tags, timestamp)
+	return nil
+}
+
+// flatten walks the nested document and calls the given function for each
+// leaf using the dot-separated path as key.
+func flatten(prefix string, v interface{}, fn func(string, interface{})) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			flatten(k, child, fn)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flatten(prefix+"."+strconv.Itoa(i), child, fn)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			fn(prefix, i)
+		} else if f, err := v.Float64(); err == nil {
+			fn(prefix, f)
+		}
+	case string, bool:
+		fn(prefix, v)
+	}
+}
+
+func init() {
+	inputs.Add("beats_listener", func() telegraf.Input {
+		return &BeatsListener{MaxWindowSize: 16384}
+	})
+}
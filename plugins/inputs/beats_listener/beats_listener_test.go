@@ -0,0 +1,243 @@
+package beats_listener
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &BeatsListener{MaxConnections: -1}
+	require.ErrorContains(t, plugin.Init(), "max_connections must not be negative")
+
+	plugin = &BeatsListener{}
+	require.ErrorContains(t, plugin.Init(), "max_window_size must be positive")
+}
+
+func TestStartFail(t *testing.T) {
+	plugin := &BeatsListener{
+		ServiceAddress: "udp://127.0.0.1:0",
+		MaxWindowSize:  16384,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Start(&acc), `unknown protocol "udp"`)
+}
+
+func TestReceive(t *testing.T) {
+	plugin := &BeatsListener{
+		ServiceAddress: "tcp://127.0.0.1:0",
+		MaxWindowSize:  16384,
+		TagKeys:        []string{"host.name"},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	conn, err := net.Dial("tcp", plugin.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Send a batch with one plain and two compressed events
+	var inner bytes.Buffer
+	zw := zlib.NewWriter(&inner)
+	_, err = zw.Write(jsonFrame(2, `{"@timestamp":"2024-01-02T03:04:06Z","message":"second","host":{"name":"web02"}}`))
+	require.NoError(t, err)
+	_, err = zw.Write(dataFrame(3, map[string]string{"line": "third"}))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	var batch []byte
+	batch = append(batch, windowFrame(3)...)
+	batch = append(batch, jsonFrame(1, `{
+	  "@timestamp": "2024-01-02T03:04:05.123Z",
+	  "@metadata": {"beat": "filebeat", "type": "_doc", "version": "8.12.0"},
+	  "message": "first",
+	  "host": {"name": "web01"},
+	  "log": {"offset": 42, "flags": ["multiline"]},
+	  "rate": 0.5,
+	  "ignored": null
+	}`)...)
+	batch = append(batch, compressedFrame(inner.Bytes())...)
+	_, err = conn.Write(batch)
+	require.NoError(t, err)
+
+	// The batch must be acknowledged with the last sequence number
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	ack := make([]byte, 6)
+	_, err = conn.Read(ack)
+	require.NoError(t, err)
+	require.Equal(t, []byte{'2', 'A', 0, 0, 0, 3}, ack)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"beats",
+			map[string]string{"beat": "filebeat", "host.name": "web01"},
+			map[string]interface{}{
+				"message":     "first",
+				"log.offset":  int64(42),
+				"log.flags.0": "multiline",
+				"rate":        0.5,
+			},
+			time.Date(2024, 1, 2, 3, 4, 5, 123000000, time.UTC),
+		),
+		metric.New(
+			"beats",
+			map[string]string{"host.name": "web02"},
+			map[string]interface{}{"message": "second"},
+			time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC),
+		),
+		metric.New(
+			"beats",
+			map[string]string{},
+			map[string]interface{}{"line": "third"},
+			time.Unix(0, 0),
+		),
+	}
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= uint64(len(expected))
+	}, 5*time.Second, 10*time.Millisecond)
+
+	actual := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, expected[:2], actual[:2])
+	testutil.RequireMetricsEqual(t, expected[2:], actual[2:], testutil.IgnoreTime())
+}
+
+func TestInvalidFrame(t *testing.T) {
+	plugin := &BeatsListener{
+		ServiceAddress: "tcp://127.0.0.1:0",
+		MaxWindowSize:  16384,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	conn, err := net.Dial("tcp", plugin.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Protocol version 1 is not supported so the connection must be closed
+	_, err = conn.Write([]byte{'1', 'W', 0, 0, 0, 1})
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Read(make([]byte, 6))
+	require.Error(t, err)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestWindowSizeLimit(t *testing.T) {
+	plugin := &BeatsListener{
+		ServiceAddress: "tcp://127.0.0.1:0",
+		MaxWindowSize:  16384,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	conn, err := net.Dial("tcp", plugin.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Windows exceeding the limit must close the connection without
+	// allocating memory for the announced events
+	_, err = conn.Write(windowFrame(0xffffffff))
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Read(make([]byte, 6))
+	require.Error(t, err)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestCompressedFrameLimits(t *testing.T) {
+	compress := func(frames ...[]byte) []byte {
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		for _, frame := range frames {
+			_, err := zw.Write(frame)
+			require.NoError(t, err)
+		}
+		require.NoError(t, zw.Close())
+		return compressedFrame(buf.Bytes())
+	}
+
+	// Events exceeding the announced window must not be returned
+	batch := append(windowFrame(2), compress(
+		jsonFrame(1, `{"message":"first"}`),
+		jsonFrame(2, `{"message":"second"}`),
+		jsonFrame(3, `{"message":"third"}`),
+	)...)
+	r := &reader{r: bytes.NewReader(batch), maxWindow: 16384}
+	events, err := r.readBatch()
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	// Nested compressed frames are not allowed
+	batch = append(windowFrame(1), compress(compress(jsonFrame(1, `{"message":"first"}`)))...)
+	r = &reader{r: bytes.NewReader(batch), maxWindow: 16384}
+	_, err = r.readBatch()
+	require.ErrorContains(t, err, "nested compressed frame")
+
+	// The decompressed data must not exceed the payload limit even if the
+	// compressed frame is small
+	frames := make([][]byte, 0, 16384)
+	frame := jsonFrame(1, string(make([]byte, 4096)))
+	for range 16384 {
+		frames = append(frames, frame)
+	}
+	compressed := compress(frames...)
+	require.Less(t, len(compressed), 1024*1024)
+	batch = append(windowFrame(16384), compressed...)
+	r = &reader{r: bytes.NewReader(batch), maxWindow: 16384}
+	_, err = r.readBatch()
+	require.ErrorContains(t, err, "decompressed frame exceeds limit")
+}
+
+func windowFrame(size uint32) []byte {
+	return binary.BigEndian.AppendUint32([]byte{'2', 'W'}, size)
+}
+
+func jsonFrame(seq uint32, payload string) []byte {
+	buf := binary.BigEndian.AppendUint32([]byte{'2', 'J'}, seq)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(payload)))
+	return append(buf, payload...)
+}
+
+func dataFrame(seq uint32, data map[string]string) []byte {
+	buf := binary.BigEndian.AppendUint32([]byte{'2', 'D'}, seq)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(data)))
+	for k, v := range data {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(k)))
+		buf = append(buf, k...)
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(v)))
+		buf = append(buf, v...)
+	}
+	return buf
+}
+
+func compressedFrame(payload []byte) []byte {
+	buf := binary.BigEndian.AppendUint32([]byte{'2', 'C'}, uint32(len(payload)))
+	return append(buf, payload...)
+}
@@ -0,0 +1,186 @@
+package beats_listener
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Frame types of the lumberjack v2 protocol used by Elastic Beats, see
+// https://github.com/elastic/go-lumber
+const (
+	protocolVersion = '2'
+
+	frameWindowSize = 'W'
+	frameCompressed = 'C'
+	frameJSON       = 'J'
+	frameData       = 'D'
+	frameACK        = 'A'
+)
+
+// maxPayloadSize limits the size of a single frame payload to protect against
+// malformed or malicious clients allocating excessive memory.
+const maxPayloadSize = 64 * 1024 * 1024
+
+// event is a single decoded data frame
+type event struct {
+	seq  uint32
+	json []byte
+	data map[string]string
+}
+
+// reader decodes lumberjack v2 frames
+type reader struct {
+	r         io.Reader
+	maxWindow uint32
+}
+
+// readBatch reads the window-size frame and all data frames of the following
+// batch announced by the window size.
+func (r *reader) readBatch() ([]event, error) {
+	version, kind, err := r.readHeader()
+	if err != nil {
+		return nil, err
+	}
+	if version != protocolVersion {
+		return nil, fmt.Errorf("unsupported protocol version %q", version)
+	}
+	if kind != frameWindowSize {
+		return nil, fmt.Errorf("expected window size frame but got %q", kind)
+	}
+
+	var window uint32
+	if err := binary.Read(r.r, binary.BigEndian, &window); err != nil {
+		return nil, fmt.Errorf("reading window size failed: %w", err)
+	}
+	if window > r.maxWindow {
+		return nil, fmt.Errorf("window size %d exceeds limit of %d", window, r.maxWindow)
+	}
+
+	events := make([]event, 0, min(window, 1024))
+	for uint32(len(events)) < window {
+		batch, err := r.readFrame(r.r, window-uint32(len(events)))
+		if err != nil {
+			return events, err
+		}
+		events = append(events, batch...)
+	}
+	return events, nil
+}
+
+func (r *reader) readHeader() (version, kind byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		return 0, 0, err
+	}
+	return header[0], header[1], nil
+}
+
+// readFrame reads a single data or compressed frame from the given source.
+// Compressed frames return at most the given number of events.
+func (r *reader) readFrame(src io.Reader, remaining uint32) ([]event, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(src, header[:]); err != nil {
+		return nil, err
+	}
+	if header[0] != protocolVersion {
+		return nil, fmt.Errorf("unsupported protocol version %q", header[0])
+	}
+
+	switch header[1] {
+	case frameCompressed:
+		if _, nested := src.(*io.LimitedReader); nested {
+			return nil, errors.New("nested compressed frame")
+		}
+		payload, err := readPayload(src)
+		if err != nil {
+			return nil, fmt.Errorf("reading compressed frame failed: %w", err)
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("decompressing frame failed: %w", err)
+		}
+		defer zr.Close()
+
+		// Limit the decompressed size to protect against compression bombs
+		// and stop at the number of events announced by the window
+		lr := &io.LimitedReader{R: zr, N: maxPayloadSize}
+		var events []event
+		for uint32(len(events)) < remaining {
+			batch, err := r.readFrame(lr, remaining-uint32(len(events)))
+			if lr.N <= 0 {
+				return nil, fmt.Errorf("decompressed frame exceeds limit of %d bytes", maxPayloadSize)
+			}
+			if errors.Is(err, io.EOF) {
+				return events, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, batch...)
+		}
+		return events, nil
+	case frameJSON:
+		var seq uint32
+		if err := binary.Read(src, binary.BigEndian, &seq); err != nil {
+			return nil, fmt.Errorf("reading sequence number failed: %w", err)
+		}
+		payload, err := readPayload(src)
+		if err != nil {
+			return nil, fmt.Errorf("reading JSON frame failed: %w", err)
+		}
+		return []event{{seq: seq, json: payload}}, nil
+	case frameData:
+		var seq, pairs uint32
+		if err := binary.Read(src, binary.BigEndian, &seq); err != nil {
+			return nil, fmt.Errorf("reading sequence number failed: %w", err)
+		}
+		if err := binary.Read(src, binary.BigEndian, &pairs); err != nil {
+			return nil, fmt.Errorf("reading pair count failed: %w", err)
+		}
+		data := make(map[string]string, min(pairs, 1024))
+		for range pairs {
+			k, err := readPayload(src)
+			if err != nil {
+				return nil, fmt.Errorf("reading key failed: %w", err)
+			}
+			v, err := readPayload(src)
+			if err != nil {
+				return nil, fmt.Errorf("reading value failed: %w", err)
+			}
+			data[string(k)] = string(v)
+		}
+		return []event{{seq: seq, data: data}}, nil
+	}
+	return nil, fmt.Errorf("unexpected frame type %q", header[1])
+}
+
+func readPayload(src io.Reader) ([]byte, error) {
+	var size uint32
+	if err := binary.Read(src, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+	if size > maxPayloadSize {
+		return nil, fmt.Errorf("payload size %d exceeds limit", size)
+	}
+	// Do not allocate more than the limit left for decompressed data
+	if lr, ok := src.(*io.LimitedReader); ok && int64(size) > lr.N {
+		return nil, fmt.Errorf("decompressed frame exceeds limit of %d bytes", maxPayloadSize)
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(src, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// writeACK acknowledges all events up to and including the given sequence
+func writeACK(w io.Writer, seq uint32) error {
+	buf := []byte{protocolVersion, frameACK, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(buf[2:], seq)
+	_, err := w.Write(buf)
+	return err
+}
@@ -0,0 +1,337 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package bmp_listener
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type BMPListener struct {
+	ServiceAddress string          `toml:"service_address"`
+	MaxConnections int             `toml:"max_connections"`
+	ReadTimeout    config.Duration `toml:"read_timeout"`
+	Log            telegraf.Logger `toml:"-"`
+
+	acc      telegraf.Accumulator
+	listener net.Listener
+
+	// Monitored routers by connection
+	routers map[net.Conn]*router
+	sync.Mutex
+	wg sync.WaitGroup
+}
+
+type router struct {
+	address string
+	name    string
+	peers   map[peerKey]*peer
+}
+
+type peerKey struct {
+	distinguisher uint64
+	address       string
+}
+
+type peer struct {
+	header *peerHeader
+	up     bool
+
+	// Counters of the route monitoring messages of the pre-policy
+	// Adj-RIB-In or the Loc-RIB and of the post-policy Adj-RIB-In
+	pre, post  counters
+	postPolicy bool
+}
+
+type counters struct {
+	updates   uint64
+	announced uint64
+	withdrawn uint64
+}
+
+func (*BMPListener) SampleConfig() string {
+	return sampleConfig
+}
+
+func (b *BMPListener) Init() error {
+	if b.ServiceAddress == "" {
+		b.ServiceAddress = "tcp://:11019"
+	}
+
+	if b.MaxConnections < 0 {
+		return errors.New("max_connections must not be negative")
+	}
+
+	return nil
+}
+
+func (b *BMPListener) Start(acc telegraf.Accumulator) error {
+	b.acc = acc
+
+	protocol, addr, found := strings.Cut(b.ServiceAddress, "://")
+	if !found {
+		return fmt.Errorf("invalid service address %q", b.ServiceAddress)
+	}
+	switch protocol {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return fmt.Errorf("unknown protocol %q in %q", protocol, b.ServiceAddress)
+	}
+
+	var err error
+	b.listener, err = net.Listen(protocol, addr)
+	if err != nil {
+		return err
+	}
+	b.Log.Infof("Listening on %s://%s", protocol, b.listener.Addr())
+
+	b.routers = make(map[net.Conn]*router)
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.listen()
+	}()
+
+	return nil
+}
+
+// Gather reports the session state and the update counters of all peers of
+// the connected routers
+func (b *BMPListener) Gather(acc telegraf.Accumulator) error {
+	b.Lock()
+	defer b.Unlock()
+
+	now := time.Now()
+	for _, r := range b.routers {
+		for _, p := range r.peers {
+			fields := map[string]interface{}{
+				"up":                 p.up,
+				"update_messages":    p.pre.updates,
+				"announced_prefixes": p.pre.announced,
+				"withdrawn_prefixes": p.pre.withdrawn,
+			}
+			if p.postPolicy {
+				fields["post_policy_update_messages"] = p.post.updates
+				fields["post_policy_announced_prefixes"] = p.post.announced
+				fields["post_policy_withdrawn_prefixes"] = p.post.withdrawn
+			}
+			acc.AddCounter("bmp_peer", fields, r.tags(p.header), now)
+		}
+	}
+
+	return nil
+}
+
+func (b *BMPListener) Stop() {
+	if b.listener != nil {
+		b.listener.Close()
+	}
+
+	b.Lock()
+	for c := range b.routers {
+		c.Close()
+	}
+	b.Unlock()
+
+	b.wg.Wait()
+}
+
+func (b *BMPListener) listen() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				b.Log.Errorf("Accepting connection failed: %v", err)
+			}
+			return
+		}
+
+		address := conn.RemoteAddr().String()
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			address = host
+		}
+
+		b.Lock()
+		if b.MaxConnections > 0 && len(b.routers) >= b.MaxConnections {
+			b.Unlock()
+			b.Log.Warnf("Refusing connection from %s: too many connections", conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
+		b.routers[conn] = &router{address: address, peers: make(map[peerKey]*peer)}
+		b.Unlock()
+
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			// The peers are not monitored anymore when the router
+			// disconnects so drop their state
+			defer func() {
+				b.Lock()
+				delete(b.routers, conn)
+				b.Unlock()
+				conn.Close()
+			}()
+			if err := b.handle(conn); err != nil {
+				b.Log.Errorf("Handling connection from %s failed: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+func (b *BMPListener) handle(conn net.Conn) error {
+	for {
+		if b.ReadTimeout > 0 {
+			if err := conn.SetReadDeadline(time.Now().Add(time.Duration(b.ReadTimeout))); err != nil {
+				return fmt.Errorf("setting read deadline failed: %w", err)
+			}
+		}
+
+		msg, err := readMessage(conn)
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		switch msg.msgType {
+		case msgInitiation:
+			b.Lock()
+			b.routers[conn].name = parseSysName(msg.data)
+			b.Unlock()
+			continue
+		case msgTermination:
+			return nil
+		case msgRouteMonitoring, msgStatisticsReport, msgPeerDown, msgPeerUp:
+		default:
+			continue
+		}
+
+		header, data, err := parsePeerHeader(msg.data)
+		if err != nil {
+			b.acc.AddError(fmt.Errorf("decoding message from %s failed: %w", conn.RemoteAddr(), err))
+			continue
+		}
+		if err := b.process(conn, msg.msgType, header, data); err != nil {
+			b.acc.AddError(fmt.Errorf("decoding message from %s for peer %s failed: %w", conn.RemoteAddr(), header.address, err))
+		}
+	}
+}
+
+func (b *BMPListener) process(conn net.Conn, msgType uint8, header *peerHeader, data []byte) error {
+	b.Lock()
+	defer b.Unlock()
+
+	r := b.routers[conn]
+	key := peerKey{distinguisher: header.distinguisher, address: header.address.String()}
+	p, found := r.peers[key]
+	if !found {
+		p = &peer{}
+		r.peers[key] = p
+	}
+	p.header = header
+
+	timestamp := header.timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	switch msgType {
+	case msgRouteMonitoring:
+		u, err := parseUpdate(data)
+		if err != nil {
+			return err
+		}
+		c := &p.pre
+		if header.postPolicy() {
+			c = &p.post
+			p.postPolicy = true
+		}
+		c.updates++
+		c.announced += uint64(u.announced)
+		c.withdrawn += uint64(u.withdrawn)
+	case msgStatisticsReport:
+		stats, err := parseStatistics(data)
+		if err != nil {
+			return err
+		}
+		if len(stats) > 0 {
+			b.acc.AddFields("bmp_statistics", stats, r.tags(header), timestamp)
+		}
+	case msgPeerUp:
+		// Local address, local port and remote port precede the OPEN messages
+		if len(data) < 20 {
+			return errors.New("peer up notification too short")
+		}
+		local := net.IP(data[0:16])
+		if header.flags&flagIPv6 == 0 {
+			local = net.IP(data[12:16])
+		}
+		p.up = true
+		tags := r.tags(header)
+		tags["event"] = "up"
+		fields := map[string]interface{}{
+			"up":            true,
+			"local_address": local.String(),
+			"local_port":    int(binary.BigEndian.Uint16(data[16:18])),
+			"remote_port":   int(binary.BigEndian.Uint16(data[18:20])),
+		}
+		b.acc.AddFields("bmp_peer_event", fields, tags, timestamp)
+	case msgPeerDown:
+		if len(data) < 1 {
+			return errors.New("peer down notification too short")
+		}
+		p.up = false
+		tags := r.tags(header)
+		tags["event"] = "down"
+		reason, found := peerDownReasons[data[0]]
+		if !found {
+			reason = strconv.Itoa(int(data[0]))
+		}
+		fields := map[string]interface{}{
+			"up":          false,
+			"reason":      reason,
+			"reason_code": int(data[0]),
+		}
+		b.acc.AddFields("bmp_peer_event", fields, tags, timestamp)
+	}
+	return nil
+}
+
+func (r *router) tags(h *peerHeader) map[string]string {
+	tags := map[string]string{
+		"router":       r.address,
+		"peer_address": h.address.String(),
+		"peer_as":      strconv.FormatUint(uint64(h.as), 10),
+		"peer_bgp_id":  h.bgpID.String(),
+		"peer_type":    h.typeName(),
+	}
+	if r.name != "" {
+		tags["router_name"] = r.name
+	}
+	if h.distinguisher != 0 {
+		tags["peer_distinguisher"] = h.distinguisherName()
+	}
+	return tags
+}
+
+func init() {
+	inputs.Add("bmp_listener", func() telegraf.Input {
+		return &BMPListener{}
+	})
+}
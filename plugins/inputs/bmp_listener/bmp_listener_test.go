@@ -0,0 +1,304 @@
+package bmp_listener
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &BMPListener{MaxConnections: -1}
+	require.ErrorContains(t, plugin.Init(), "max_connections must not be negative")
+}
+
+func TestStartFail(t *testing.T) {
+	plugin := &BMPListener{
+		ServiceAddress: "udp://127.0.0.1:0",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Start(&acc), `unknown protocol "udp"`)
+}
+
+func TestReceive(t *testing.T) {
+	plugin := &BMPListener{
+		ServiceAddress: "tcp://127.0.0.1:0",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	conn, err := net.Dial("tcp", plugin.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ts := time.Unix(1718200000, 0)
+	peer := peerHeaderBytes(0, 0, net.ParseIP("192.0.2.2"), 65002, ts)
+	postPolicyPeer := peerHeaderBytes(0, flagPostPolicy, net.ParseIP("192.0.2.2"), 65002, ts)
+
+	// Peer up with local address, local port and remote port
+	peerUp := make([]byte, 20)
+	copy(peerUp[12:16], net.ParseIP("192.0.2.1").To4())
+	binary.BigEndian.PutUint16(peerUp[16:18], 179)
+	binary.BigEndian.PutUint16(peerUp[18:20], 53502)
+
+	// Update announcing two and withdrawing one IPv4 prefix
+	update1 := bgpUpdateBytes(
+		[]byte{24, 198, 51, 100},
+		nil,
+		[]byte{24, 192, 0, 2, 16, 10, 1},
+	)
+	// Update announcing one IPv6 prefix
+	mpReach := []byte{0, 2, 1, 16}
+	mpReach = append(mpReach, net.ParseIP("2001:db8::1")...)
+	mpReach = append(mpReach, 0, 32, 0x20, 0x01, 0x0d, 0xb8)
+	update2 := bgpUpdateBytes(nil, append([]byte{0x80, attrMPReachNLRI, byte(len(mpReach))}, mpReach...), nil)
+
+	// Statistics with a counter, a gauge and a per AFI/SAFI gauge
+	stats := []byte{0, 0, 0, 3}
+	stats = append(stats, 0, 0, 0, 4, 0, 0, 0, 3)
+	stats = append(stats, 0, 7, 0, 8, 0, 0, 0, 0, 0, 0, 0, 5)
+	stats = append(stats, 0, 9, 0, 11, 0, 1, 1, 0, 0, 0, 0, 0, 0, 0, 4)
+
+	var data []byte
+	data = append(data, bmpMessage(msgInitiation, []byte{0, 2, 0, 6, 'e', 'd', 'g', 'e', '0', '1'})...)
+	data = append(data, bmpMessage(msgPeerUp, append(peer, peerUp...))...)
+	data = append(data, bmpMessage(msgRouteMonitoring, append(peer, update1...))...)
+	data = append(data, bmpMessage(msgRouteMonitoring, append(peer, update2...))...)
+	data = append(data, bmpMessage(msgRouteMonitoring, append(postPolicyPeer, update1...))...)
+	data = append(data, bmpMessage(msgStatisticsReport, append(peer, stats...))...)
+	data = append(data, bmpMessage(msgPeerDown, append(peer, 4))...)
+	_, err = conn.Write(data)
+	require.NoError(t, err)
+
+	tags := map[string]string{
+		"router":       "127.0.0.1",
+		"router_name":  "edge01",
+		"peer_address": "192.0.2.2",
+		"peer_as":      "65002",
+		"peer_bgp_id":  "192.0.2.2",
+		"peer_type":    "global",
+	}
+	tagsWithEvent := func(event string) map[string]string {
+		t := make(map[string]string, len(tags)+1)
+		for k, v := range tags {
+			t[k] = v
+		}
+		t["event"] = event
+		return t
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"bmp_peer_event",
+			tagsWithEvent("up"),
+			map[string]interface{}{
+				"up":            true,
+				"local_address": "192.0.2.1",
+				"local_port":    179,
+				"remote_port":   53502,
+			},
+			ts,
+		),
+		metric.New(
+			"bmp_statistics",
+			tags,
+			map[string]interface{}{
+				"rejected_prefixes":              uint64(3),
+				"adj_rib_in_routes":              uint64(5),
+				"adj_rib_in_routes_ipv4_unicast": uint64(4),
+			},
+			ts,
+		),
+		metric.New(
+			"bmp_peer_event",
+			tagsWithEvent("down"),
+			map[string]interface{}{
+				"up":          false,
+				"reason":      "remote_no_notification",
+				"reason_code": 4,
+			},
+			ts,
+		),
+	}
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= uint64(len(expected))
+	}, 5*time.Second, 10*time.Millisecond)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Check the peer counters
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	expected = []telegraf.Metric{
+		metric.New(
+			"bmp_peer",
+			tags,
+			map[string]interface{}{
+				"up":                             false,
+				"update_messages":                uint64(2),
+				"announced_prefixes":             uint64(3),
+				"withdrawn_prefixes":             uint64(1),
+				"post_policy_update_messages":    uint64(1),
+				"post_policy_announced_prefixes": uint64(2),
+				"post_policy_withdrawn_prefixes": uint64(1),
+			},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// The state must be dropped when the router disconnects
+	_, err = conn.Write(bmpMessage(msgTermination, nil))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		plugin.Lock()
+		defer plugin.Unlock()
+		return len(plugin.routers) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestInvalidMessage(t *testing.T) {
+	plugin := &BMPListener{
+		ServiceAddress: "tcp://127.0.0.1:0",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	conn, err := net.Dial("tcp", plugin.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Protocol version 1 is not supported so the connection must be closed
+	_, err = conn.Write([]byte{1, 0, 0, 0, 6, msgInitiation})
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Read(make([]byte, 1))
+	require.Error(t, err)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestParseUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected update
+		err      string
+	}{
+		{
+			name:     "end of RIB",
+			data:     bgpUpdateBytes(nil, nil, nil),
+			expected: update{},
+		},
+		{
+			name: "IPv6 withdraw",
+			data: bgpUpdateBytes(nil, []byte{0x80, attrMPUnreach, 9, 0, 2, 1, 32, 0x20, 0x01, 0x0d, 0xb8, 0}, nil),
+			expected: update{
+				withdrawn: 2,
+			},
+		},
+		{
+			name:     "ignore VPN prefixes",
+			data:     bgpUpdateBytes(nil, []byte{0x80, attrMPUnreach, 4, 0, 1, 128, 0}, nil),
+			expected: update{},
+		},
+		{
+			name: "truncated prefix",
+			data: bgpUpdateBytes(nil, nil, []byte{24, 192, 0}),
+			err:  "prefix truncated",
+		},
+		{
+			name: "not an update",
+			data: append(make([]byte, 16), 0, 19, 4),
+			err:  "unexpected BGP message type 4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := parseUpdate(tt.data)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestParseStatisticsInvalidCount(t *testing.T) {
+	// A huge count must be rejected before allocating memory for it
+	data := []byte{0xff, 0xff, 0xff, 0xff, 0, 1, 0, 4, 0, 0, 0, 1}
+	_, err := parseStatistics(data)
+	require.ErrorContains(t, err, "statistics count 4294967295 exceeds report size")
+
+	data = []byte{0, 0, 0, 1, 0, 1, 0, 4, 0, 0, 0, 1}
+	stats, err := parseStatistics(data)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+}
+
+func TestDistinguisherName(t *testing.T) {
+	h := &peerHeader{peerType: 1, distinguisher: 0x0000_fde8_0000_0064}
+	require.Equal(t, "65000:100", h.distinguisherName())
+	h = &peerHeader{peerType: 1, distinguisher: 0x0001_c000_0201_0064}
+	require.Equal(t, "192.0.2.1:100", h.distinguisherName())
+	h = &peerHeader{peerType: 2, distinguisher: 42}
+	require.Equal(t, "42", h.distinguisherName())
+}
+
+func bmpMessage(msgType uint8, payload []byte) []byte {
+	msg := make([]byte, commonHeaderSize, commonHeaderSize+len(payload))
+	msg[0] = protocolVersion
+	binary.BigEndian.PutUint32(msg[1:5], uint32(commonHeaderSize+len(payload)))
+	msg[5] = msgType
+	return append(msg, payload...)
+}
+
+func peerHeaderBytes(peerType, flags uint8, address net.IP, as uint32, ts time.Time) []byte {
+	h := make([]byte, peerHeaderSize)
+	h[0] = peerType
+	h[1] = flags
+	if ip := address.To4(); ip != nil {
+		copy(h[22:26], ip)
+	} else {
+		copy(h[10:26], address)
+	}
+	binary.BigEndian.PutUint32(h[26:30], as)
+	copy(h[30:34], address.To4())
+	binary.BigEndian.PutUint32(h[34:38], uint32(ts.Unix()))
+	return h
+}
+
+func bgpUpdateBytes(withdrawn, attrs, nlri []byte) []byte {
+	msg := make([]byte, bgpHeaderSize)
+	for i := 0; i < 16; i++ {
+		msg[i] = 0xff
+	}
+	msg[18] = bgpUpdate
+	msg = binary.BigEndian.AppendUint16(msg, uint16(len(withdrawn)))
+	msg = append(msg, withdrawn...)
+	msg = binary.BigEndian.AppendUint16(msg, uint16(len(attrs)))
+	msg = append(msg, attrs...)
+	msg = append(msg, nlri...)
+	binary.BigEndian.PutUint16(msg[16:18], uint16(len(msg)))
+	return msg
+}
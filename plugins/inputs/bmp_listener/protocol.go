@@ -0,0 +1,387 @@
+package bmp_listener
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Message types of the BGP Monitoring Protocol, see RFC 7854
+const (
+	protocolVersion = 3
+
+	msgRouteMonitoring  = 0
+	msgStatisticsReport = 1
+	msgPeerDown         = 2
+	msgPeerUp           = 3
+	msgInitiation       = 4
+	msgTermination      = 5
+	msgRouteMirroring   = 6
+)
+
+const (
+	commonHeaderSize = 6
+	peerHeaderSize   = 42
+	bgpHeaderSize    = 19
+
+	// BGP message type and path attributes required for counting prefixes
+	bgpUpdate       = 2
+	attrMPReachNLRI = 14
+	attrMPUnreach   = 15
+
+	// Information TLV type of the Initiation message carrying the router name
+	infoSysName = 2
+)
+
+// maxMessageSize limits the size of a single message to protect against
+// malformed or malicious routers allocating excessive memory.
+const maxMessageSize = 16 * 1024 * 1024
+
+// Flags of the per-peer header
+const (
+	flagIPv6       = 0x80
+	flagPostPolicy = 0x40
+)
+
+var peerTypes = map[uint8]string{
+	0: "global",
+	1: "rd",
+	2: "local",
+	3: "loc_rib",
+}
+
+var peerDownReasons = map[uint8]string{
+	1: "local_notification",
+	2: "local_no_notification",
+	3: "remote_notification",
+	4: "remote_no_notification",
+	5: "peer_deconfigured",
+	6: "local_system_closed",
+}
+
+// Names of the statistics counters and gauges of the Statistics Report message
+var statNames = map[uint16]string{
+	0:  "rejected_prefixes",
+	1:  "duplicate_prefix_advertisements",
+	2:  "duplicate_withdraws",
+	3:  "cluster_list_loops",
+	4:  "as_path_loops",
+	5:  "originator_id_loops",
+	6:  "as_confed_loops",
+	7:  "adj_rib_in_routes",
+	8:  "loc_rib_routes",
+	9:  "adj_rib_in_routes",
+	10: "loc_rib_routes",
+	11: "treat_as_withdraw_updates",
+	12: "treat_as_withdraw_prefixes",
+	13: "duplicate_updates",
+}
+
+var afiNames = map[uint16]string{
+	1:  "ipv4",
+	2:  "ipv6",
+	25: "l2vpn",
+}
+
+var safiNames = map[uint8]string{
+	1:   "unicast",
+	2:   "multicast",
+	4:   "labeled_unicast",
+	70:  "evpn",
+	128: "vpn",
+	133: "flowspec",
+}
+
+type message struct {
+	msgType uint8
+	data    []byte
+}
+
+type peerHeader struct {
+	peerType      uint8
+	flags         uint8
+	distinguisher uint64
+	address       net.IP
+	as            uint32
+	bgpID         net.IP
+	timestamp     time.Time
+}
+
+// update contains the number of prefixes of a BGP UPDATE message
+type update struct {
+	announced int
+	withdrawn int
+}
+
+// readMessage reads the next message prefixed by the common header
+func readMessage(r io.Reader) (*message, error) {
+	header := make([]byte, commonHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if header[0] != protocolVersion {
+		return nil, fmt.Errorf("unsupported protocol version %d", header[0])
+	}
+	length := binary.BigEndian.Uint32(header[1:5])
+	if length < commonHeaderSize || length > maxMessageSize {
+		return nil, fmt.Errorf("invalid message length %d", length)
+	}
+
+	data := make([]byte, length-commonHeaderSize)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("reading message failed: %w", err)
+	}
+	return &message{msgType: header[5], data: data}, nil
+}
+
+// parsePeerHeader decodes the per-peer header and returns the remaining data
+func parsePeerHeader(data []byte) (*peerHeader, []byte, error) {
+	if len(data) < peerHeaderSize {
+		return nil, nil, errors.New("per-peer header too short")
+	}
+
+	h := &peerHeader{
+		peerType:      data[0],
+		flags:         data[1],
+		distinguisher: binary.BigEndian.Uint64(data[2:10]),
+		as:            binary.BigEndian.Uint32(data[26:30]),
+		bgpID:         net.IP(data[30:34]),
+	}
+	if h.flags&flagIPv6 != 0 {
+		h.address = net.IP(data[10:26])
+	} else {
+		h.address = net.IP(data[22:26])
+	}
+	if sec, usec := binary.BigEndian.Uint32(data[34:38]), binary.BigEndian.Uint32(data[38:42]); sec > 0 {
+		h.timestamp = time.Unix(int64(sec), int64(usec)*int64(time.Microsecond))
+	}
+
+	return h, data[peerHeaderSize:], nil
+}
+
+func (h *peerHeader) postPolicy() bool {
+	return h.flags&flagPostPolicy != 0
+}
+
+func (h *peerHeader) typeName() string {
+	if name, found := peerTypes[h.peerType]; found {
+		return name
+	}
+	return strconv.Itoa(int(h.peerType))
+}
+
+// distinguisherName formats the peer distinguisher using the route
+// distinguisher notation of RFC 4364 for L3VPN peers
+func (h *peerHeader) distinguisherName() string {
+	if h.peerType != 1 {
+		return strconv.FormatUint(h.distinguisher, 10)
+	}
+
+	rdType := h.distinguisher >> 48
+	value := h.distinguisher & 0xffffffffffff
+	switch rdType {
+	case 0:
+		return strconv.FormatUint(value>>32, 10) + ":" + strconv.FormatUint(value&0xffffffff, 10)
+	case 1:
+		ip := net.IPv4(byte(value>>40), byte(value>>32), byte(value>>24), byte(value>>16))
+		return ip.String() + ":" + strconv.FormatUint(value&0xffff, 10)
+	case 2:
+		return strconv.FormatUint(value>>16, 10) + ":" + strconv.FormatUint(value&0xffff, 10)
+	}
+	return strconv.FormatUint(h.distinguisher, 10)
+}
+
+// parseSysName extracts the router name from the information TLVs of an
+// Initiation message
+func parseSysName(data []byte) string {
+	for len(data) >= 4 {
+		tlvType := binary.BigEndian.Uint16(data[0:2])
+		length := int(binary.BigEndian.Uint16(data[2:4]))
+		if len(data) < 4+length {
+			return ""
+		}
+		if tlvType == infoSysName {
+			return string(data[4 : 4+length])
+		}
+		data = data[4+length:]
+	}
+	return ""
+}
+
+// parseStatistics decodes the counters and gauges of a Statistics Report
+// message. Per AFI/SAFI gauges are suffixed with the address family.
+func parseStatistics(data []byte) (map[string]interface{}, error) {
+	if len(data) < 4 {
+		return nil, errors.New("statistics report too short")
+	}
+	count := binary.BigEndian.Uint32(data[0:4])
+	data = data[4:]
+
+	// Each statistic needs at least its type-length header
+	if uint64(count) > uint64(len(data)/4) {
+		return nil, fmt.Errorf("statistics count %d exceeds report size", count)
+	}
+
+	stats := make(map[string]interface{}, min(count, 64))
+	for i := uint32(0); i < count; i++ {
+		if len(data) < 4 {
+			return nil, errors.New("statistics report truncated")
+		}
+		statType := binary.BigEndian.Uint16(data[0:2])
+		length := int(binary.BigEndian.Uint16(data[2:4]))
+		if len(data) < 4+length {
+			return nil, errors.New("statistics report truncated")
+		}
+		value := data[4 : 4+length]
+		data = data[4+length:]
+
+		name, found := statNames[statType]
+		if !found {
+			name = "stat_" + strconv.Itoa(int(statType))
+		}
+		switch length {
+		case 4:
+			stats[name] = uint64(binary.BigEndian.Uint32(value))
+		case 8:
+			stats[name] = binary.BigEndian.Uint64(value)
+		case 11:
+			afi := binary.BigEndian.Uint16(value[0:2])
+			stats[name+"_"+familyName(afi, value[2])] = binary.BigEndian.Uint64(value[3:11])
+		}
+	}
+	return stats, nil
+}
+
+func familyName(afi uint16, safi uint8) string {
+	afiName, found := afiNames[afi]
+	if !found {
+		afiName = "afi" + strconv.Itoa(int(afi))
+	}
+	safiName, found := safiNames[safi]
+	if !found {
+		safiName = "safi" + strconv.Itoa(int(safi))
+	}
+	return afiName + "_" + safiName
+}
+
+// parseUpdate counts the announced and withdrawn prefixes of the BGP UPDATE
+// message in a Route Monitoring message. Prefixes of multiprotocol
+// attributes are only counted for the unicast and multicast address families
+// as other families use a different encoding of the prefixes.
+func parseUpdate(data []byte) (update, error) {
+	var u update
+	if len(data) < bgpHeaderSize {
+		return u, errors.New("BGP message too short")
+	}
+	length := int(binary.BigEndian.Uint16(data[16:18]))
+	if length < bgpHeaderSize || length > len(data) {
+		return u, fmt.Errorf("invalid BGP message length %d", length)
+	}
+	if data[18] != bgpUpdate {
+		return u, fmt.Errorf("unexpected BGP message type %d", data[18])
+	}
+	data = data[bgpHeaderSize:length]
+
+	// Withdrawn routes
+	if len(data) < 2 {
+		return u, errors.New("BGP update truncated")
+	}
+	withdrawnLen := int(binary.BigEndian.Uint16(data[0:2]))
+	if len(data) < 2+withdrawnLen+2 {
+		return u, errors.New("BGP update truncated")
+	}
+	n, err := countPrefixes(data[2 : 2+withdrawnLen])
+	if err != nil {
+		return u, fmt.Errorf("decoding withdrawn routes failed: %w", err)
+	}
+	u.withdrawn += n
+	data = data[2+withdrawnLen:]
+
+	// Path attributes
+	attrsLen := int(binary.BigEndian.Uint16(data[0:2]))
+	if len(data) < 2+attrsLen {
+		return u, errors.New("BGP update truncated")
+	}
+	attrs := data[2 : 2+attrsLen]
+	for len(attrs) > 0 {
+		if len(attrs) < 3 {
+			return u, errors.New("path attribute truncated")
+		}
+		flags, attrType := attrs[0], attrs[1]
+		var attrLen, offset int
+		if flags&0x10 != 0 {
+			if len(attrs) < 4 {
+				return u, errors.New("path attribute truncated")
+			}
+			attrLen, offset = int(binary.BigEndian.Uint16(attrs[2:4])), 4
+		} else {
+			attrLen, offset = int(attrs[2]), 3
+		}
+		if len(attrs) < offset+attrLen {
+			return u, errors.New("path attribute truncated")
+		}
+		value := attrs[offset : offset+attrLen]
+		attrs = attrs[offset+attrLen:]
+
+		switch attrType {
+		case attrMPReachNLRI:
+			// AFI, SAFI, next-hop length and next-hop followed by a reserved
+			// byte and the NLRI
+			if len(value) < 4 || len(value) < 5+int(value[3]) {
+				return u, errors.New("MP_REACH_NLRI attribute truncated")
+			}
+			if !plainPrefixes(value[2]) {
+				continue
+			}
+			n, err := countPrefixes(value[5+int(value[3]):])
+			if err != nil {
+				return u, fmt.Errorf("decoding MP_REACH_NLRI failed: %w", err)
+			}
+			u.announced += n
+		case attrMPUnreach:
+			if len(value) < 3 {
+				return u, errors.New("MP_UNREACH_NLRI attribute truncated")
+			}
+			if !plainPrefixes(value[2]) {
+				continue
+			}
+			n, err := countPrefixes(value[3:])
+			if err != nil {
+				return u, fmt.Errorf("decoding MP_UNREACH_NLRI failed: %w", err)
+			}
+			u.withdrawn += n
+		}
+	}
+
+	// Network layer reachability information
+	n, err = countPrefixes(data[2+attrsLen:])
+	if err != nil {
+		return u, fmt.Errorf("decoding NLRI failed: %w", err)
+	}
+	u.announced += n
+
+	return u, nil
+}
+
+func plainPrefixes(safi uint8) bool {
+	return safi == 1 || safi == 2
+}
+
+// countPrefixes counts the prefixes encoded as length in bits followed by
+// the significant bytes of the prefix
+func countPrefixes(data []byte) (int, error) {
+	var n int
+	for len(data) > 0 {
+		size := 1 + (int(data[0])+7)/8
+		if len(data) < size {
+			return n, errors.New("prefix truncated")
+		}
+		data = data[size:]
+		n++
+	}
+	return n, nil
+}
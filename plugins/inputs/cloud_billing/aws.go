@@ -0,0 +1,234 @@
+package cloud_billing
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_signer "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// Cost Explorer is only available in us-east-1
+const (
+	awsEndpoint = "https://ce.us-east-1.amazonaws.com"
+	awsRegion   = "us-east-1"
+)
+
+var awsDimensions = map[string]string{
+	"service": "SERVICE",
+	"account": "LINKED_ACCOUNT",
+	"region":  "REGION",
+}
+
+// awsProvider queries the AWS Cost Explorer API providing the cost and usage
+// report data
+type awsProvider struct {
+	endpoint string
+	metric   string
+	config   aws.Config
+	signer   *aws_signer.Signer
+	client   *http.Client
+}
+
+type awsGroupDefinition struct {
+	Type string `json:"Type"`
+	Key  string `json:"Key"`
+}
+
+type awsDateInterval struct {
+	Start string `json:"Start"`
+	End   string `json:"End"`
+}
+
+type awsCostRequest struct {
+	TimePeriod    awsDateInterval      `json:"TimePeriod"`
+	Granularity   string               `json:"Granularity"`
+	Metrics       []string             `json:"Metrics"`
+	GroupBy       []awsGroupDefinition `json:"GroupBy,omitempty"`
+	NextPageToken string               `json:"NextPageToken,omitempty"`
+}
+
+type awsMetricValue struct {
+	Amount string `json:"Amount"`
+	Unit   string `json:"Unit"`
+}
+
+type awsCostResponse struct {
+	ResultsByTime []struct {
+		TimePeriod awsDateInterval           `json:"TimePeriod"`
+		Total      map[string]awsMetricValue `json:"Total"`
+		Groups     []struct {
+			Keys    []string                  `json:"Keys"`
+			Metrics map[string]awsMetricValue `json:"Metrics"`
+		} `json:"Groups"`
+	} `json:"ResultsByTime"`
+	NextPageToken string `json:"NextPageToken"`
+}
+
+func (c *CloudBilling) newAWSProvider() (costProvider, error) {
+	// Cost Explorer supports at most two groupings
+	if len(c.GroupBy)+len(c.TagKeys) > 2 {
+		return nil, errors.New("at most two group_by dimensions and tag_keys are supported for AWS")
+	}
+
+	switch c.AWSCostMetric {
+	case "UnblendedCost", "BlendedCost", "AmortizedCost", "NetUnblendedCost", "NetAmortizedCost":
+	default:
+		return nil, fmt.Errorf("invalid aws_cost_metric %q", c.AWSCostMetric)
+	}
+
+	cfg, err := c.CredentialConfig.Credentials()
+	if err != nil {
+		return nil, fmt.Errorf("getting AWS credentials failed: %w", err)
+	}
+
+	endpoint := c.EndpointURL
+	if endpoint == "" {
+		endpoint = awsEndpoint
+	}
+
+	return &awsProvider{
+		endpoint: endpoint,
+		metric:   c.AWSCostMetric,
+		config:   cfg,
+		signer:   aws_signer.NewSigner(),
+		client:   &http.Client{},
+	}, nil
+}
+
+func (p *awsProvider) query(ctx context.Context, q *costQuery) ([]costEntry, error) {
+	layout := time.DateOnly
+	granularity := "DAILY"
+	if q.hourly {
+		layout = "2006-01-02T15:04:05Z"
+		granularity = "HOURLY"
+	}
+
+	request := &awsCostRequest{
+		TimePeriod: awsDateInterval{
+			Start: q.start.Format(layout),
+			End:   q.end.Format(layout),
+		},
+		Granularity: granularity,
+		Metrics:     []string{p.metric},
+	}
+	names := make([]string, 0, len(q.groupBy)+len(q.tagKeys))
+	for _, dim := range q.groupBy {
+		request.GroupBy = append(request.GroupBy, awsGroupDefinition{Type: "DIMENSION", Key: awsDimensions[dim]})
+		names = append(names, dim)
+	}
+	for _, key := range q.tagKeys {
+		request.GroupBy = append(request.GroupBy, awsGroupDefinition{Type: "TAG", Key: key})
+		names = append(names, "tag_"+key)
+	}
+
+	var entries []costEntry
+	for {
+		var response awsCostResponse
+		if err := p.call(ctx, request, &response); err != nil {
+			return nil, err
+		}
+
+		for _, result := range response.ResultsByTime {
+			period, err := time.Parse(layout, result.TimePeriod.Start)
+			if err != nil {
+				return nil, fmt.Errorf("parsing period %q failed: %w", result.TimePeriod.Start, err)
+			}
+
+			if len(request.GroupBy) == 0 {
+				entry, err := p.entry(period, result.Total, nil)
+				if err != nil {
+					return nil, err
+				}
+				entries = append(entries, entry)
+				continue
+			}
+
+			for _, group := range result.Groups {
+				groups := make(map[string]string, len(names))
+				for i, key := range group.Keys {
+					if i >= len(names) {
+						break
+					}
+					// Tag keys are returned as "<key>$<value>"
+					if request.GroupBy[i].Type == "TAG" {
+						_, key, _ = strings.Cut(key, "$")
+					}
+					groups[names[i]] = key
+				}
+				entry, err := p.entry(period, group.Metrics, groups)
+				if err != nil {
+					return nil, err
+				}
+				entries = append(entries, entry)
+			}
+		}
+
+		if response.NextPageToken == "" {
+			break
+		}
+		request.NextPageToken = response.NextPageToken
+	}
+
+	return entries, nil
+}
+
+func (p *awsProvider) entry(period time.Time, metrics map[string]awsMetricValue, groups map[string]string) (costEntry, error) {
+	value := metrics[p.metric]
+	amount, err := strconv.ParseFloat(value.Amount, 64)
+	if err != nil {
+		return costEntry{}, fmt.Errorf("parsing amount %q failed: %w", value.Amount, err)
+	}
+	return costEntry{
+		period:   period,
+		groups:   groups,
+		amount:   amount,
+		currency: value.Unit,
+	}, nil
+}
+
+func (p *awsProvider) call(ctx context.Context, request *awsCostRequest, response *awsCostResponse) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AWSInsightsIndexService.GetCostAndUsage")
+
+	credentials, err := p.config.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("retrieving AWS credentials failed: %w", err)
+	}
+	hash := sha256.Sum256(body)
+	if err := p.signer.SignHTTP(ctx, credentials, req, hex.EncodeToString(hash[:]), "ce", awsRegion, time.Now()); err != nil {
+		return fmt.Errorf("signing request failed: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("received status %q: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(response)
+}
@@ -0,0 +1,225 @@
+package cloud_billing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+const (
+	azureEndpoint   = "https://management.azure.com"
+	azureAPIVersion = "2023-03-01"
+)
+
+var azureDimensions = map[string]string{
+	"service": "ServiceName",
+	"account": "SubscriptionId",
+	"region":  "ResourceLocation",
+}
+
+// azureProvider queries the Azure Cost Management API
+type azureProvider struct {
+	endpoint   string
+	scope      string
+	costType   string
+	credential azcore.TokenCredential
+	client     *http.Client
+}
+
+type azureGrouping struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type azureAggregation struct {
+	Name     string `json:"name"`
+	Function string `json:"function"`
+}
+
+type azureQueryRequest struct {
+	Type       string `json:"type"`
+	Timeframe  string `json:"timeframe"`
+	TimePeriod struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	} `json:"timePeriod"`
+	Dataset struct {
+		Granularity string                      `json:"granularity"`
+		Aggregation map[string]azureAggregation `json:"aggregation"`
+		Grouping    []azureGrouping             `json:"grouping,omitempty"`
+	} `json:"dataset"`
+}
+
+type azureQueryResponse struct {
+	Properties struct {
+		NextLink string `json:"nextLink"`
+		Columns  []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+		} `json:"columns"`
+		Rows [][]interface{} `json:"rows"`
+	} `json:"properties"`
+}
+
+func (c *CloudBilling) newAzureProvider() (costProvider, error) {
+	if c.AzureScope == "" {
+		return nil, errors.New("azure_scope required")
+	}
+	if c.Granularity == "hourly" {
+		return nil, errors.New("hourly granularity is not supported for Azure")
+	}
+	// Cost Management supports at most two groupings with a single tag
+	if len(c.GroupBy)+len(c.TagKeys) > 2 {
+		return nil, errors.New("at most two group_by dimensions and tag_keys are supported for Azure")
+	}
+	if len(c.TagKeys) > 1 {
+		return nil, errors.New("at most one tag key is supported for Azure")
+	}
+	switch c.AzureCostType {
+	case "ActualCost", "AmortizedCost":
+	default:
+		return nil, fmt.Errorf("invalid azure_cost_type %q", c.AzureCostType)
+	}
+
+	var credential azcore.TokenCredential
+	if c.AzureClientID != "" && !c.AzureClientSecret.Empty() {
+		secret, err := c.AzureClientSecret.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting client secret failed: %w", err)
+		}
+		credential, err = azidentity.NewClientSecretCredential(c.AzureTenantID, c.AzureClientID, secret.String(), nil)
+		secret.Destroy()
+		if err != nil {
+			return nil, fmt.Errorf("creating client secret credentials failed: %w", err)
+		}
+	} else {
+		var err error
+		credential, err = azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{TenantID: c.AzureTenantID})
+		if err != nil {
+			return nil, fmt.Errorf("creating default credentials failed: %w", err)
+		}
+	}
+
+	return &azureProvider{
+		endpoint:   azureEndpoint,
+		scope:      strings.Trim(c.AzureScope, "/"),
+		costType:   c.AzureCostType,
+		credential: credential,
+		client:     &http.Client{},
+	}, nil
+}
+
+func (p *azureProvider) query(ctx context.Context, q *costQuery) ([]costEntry, error) {
+	request := &azureQueryRequest{
+		Type:      p.costType,
+		Timeframe: "Custom",
+	}
+	request.TimePeriod.From = q.start.Format(time.RFC3339)
+	// The end of the period is inclusive
+	request.TimePeriod.To = q.end.Add(-time.Second).Format(time.RFC3339)
+	request.Dataset.Granularity = "Daily"
+	request.Dataset.Aggregation = map[string]azureAggregation{
+		"totalCost": {Name: "Cost", Function: "Sum"},
+	}
+
+	// Map the result columns to the tags to add
+	names := make(map[string]string, len(q.groupBy)+len(q.tagKeys))
+	for _, dim := range q.groupBy {
+		request.Dataset.Grouping = append(request.Dataset.Grouping, azureGrouping{Type: "Dimension", Name: azureDimensions[dim]})
+		names[strings.ToLower(azureDimensions[dim])] = dim
+	}
+	for _, key := range q.tagKeys {
+		request.Dataset.Grouping = append(request.Dataset.Grouping, azureGrouping{Type: "TagKey", Name: key})
+		names["tagvalue"] = "tag_" + key
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	address := fmt.Sprintf("%s/%s/providers/Microsoft.CostManagement/query?api-version=%s", p.endpoint, p.scope, azureAPIVersion)
+	var entries []costEntry
+	for address != "" {
+		var response azureQueryResponse
+		if err := p.call(ctx, address, body, &response); err != nil {
+			return nil, err
+		}
+
+		for _, row := range response.Properties.Rows {
+			entry := costEntry{groups: make(map[string]string, len(names))}
+			for i, column := range response.Properties.Columns {
+				if i >= len(row) {
+					break
+				}
+				name := strings.ToLower(column.Name)
+				switch name {
+				case "cost", "pretaxcost":
+					v, ok := row[i].(float64)
+					if !ok {
+						return nil, fmt.Errorf("invalid cost %v", row[i])
+					}
+					entry.amount = v
+				case "usagedate":
+					// Dates are reported as numbers, e.g. 20240131
+					v, ok := row[i].(float64)
+					if !ok {
+						return nil, fmt.Errorf("invalid usage date %v", row[i])
+					}
+					entry.period, err = time.Parse("20060102", strconv.FormatInt(int64(v), 10))
+					if err != nil {
+						return nil, fmt.Errorf("parsing usage date %v failed: %w", v, err)
+					}
+				case "currency":
+					entry.currency, _ = row[i].(string)
+				default:
+					if tag, found := names[name]; found {
+						entry.groups[tag], _ = row[i].(string)
+					}
+				}
+			}
+			entries = append(entries, entry)
+		}
+		address = response.Properties.NextLink
+	}
+
+	return entries, nil
+}
+
+func (p *azureProvider) call(ctx context.Context, address string, body []byte, response *azureQueryResponse) error {
+	token, err := p.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{azureEndpoint + "/.default"}})
+	if err != nil {
+		return fmt.Errorf("getting token failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, address, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("received status %q: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(response)
+}
@@ -0,0 +1,241 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package cloud_billing
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type CloudBilling struct {
+	Provider      string             `toml:"provider"`
+	Granularity   string             `toml:"granularity"`
+	Lookback      config.Duration    `toml:"lookback"`
+	GroupBy       []string           `toml:"group_by"`
+	TagKeys       []string           `toml:"tag_keys"`
+	Currency      string             `toml:"currency"`
+	ExchangeRates map[string]float64 `toml:"exchange_rates"`
+	Timeout       config.Duration    `toml:"timeout"`
+
+	// AWS Cost Explorer
+	AWSCostMetric string `toml:"aws_cost_metric"`
+	common_aws.CredentialConfig
+
+	// Google Cloud billing export to BigQuery
+	GCPProject         string `toml:"gcp_project"`
+	GCPTable           string `toml:"gcp_table"`
+	GCPCredentialsFile string `toml:"gcp_credentials_file"`
+
+	// Azure Cost Management
+	AzureScope        string        `toml:"azure_scope"`
+	AzureCostType     string        `toml:"azure_cost_type"`
+	AzureTenantID     string        `toml:"azure_tenant_id"`
+	AzureClientID     string        `toml:"azure_client_id"`
+	AzureClientSecret config.Secret `toml:"azure_client_secret"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	provider costProvider
+
+	// Last reported cost per series and period used to only report new or
+	// corrected costs
+	reported map[string]reportedCost
+}
+
+// costProvider queries the costs of a cloud provider
+type costProvider interface {
+	query(ctx context.Context, q *costQuery) ([]costEntry, error)
+}
+
+type costQuery struct {
+	start   time.Time
+	end     time.Time
+	hourly  bool
+	groupBy []string
+	tagKeys []string
+}
+
+// costEntry is the cost of a single period and group as reported by the provider
+type costEntry struct {
+	period time.Time
+	// group values keyed by the name of the tag to add
+	groups   map[string]string
+	amount   float64
+	currency string
+}
+
+type reportedCost struct {
+	period time.Time
+	amount float64
+}
+
+func (*CloudBilling) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *CloudBilling) Init() error {
+	switch c.Granularity {
+	case "":
+		c.Granularity = "daily"
+	case "daily", "hourly":
+	default:
+		return fmt.Errorf("invalid granularity %q", c.Granularity)
+	}
+
+	if c.Lookback <= 0 {
+		return errors.New("lookback must be positive")
+	}
+
+	for _, dim := range c.GroupBy {
+		if !slices.Contains([]string{"service", "account", "region"}, dim) {
+			return fmt.Errorf("invalid group_by dimension %q", dim)
+		}
+	}
+	for _, key := range c.TagKeys {
+		if key == "" {
+			return errors.New("empty tag key")
+		}
+	}
+
+	for currency, rate := range c.ExchangeRates {
+		if rate <= 0 {
+			return fmt.Errorf("invalid exchange rate %v for currency %q", rate, currency)
+		}
+	}
+
+	var err error
+	switch c.Provider {
+	case "aws":
+		c.provider, err = c.newAWSProvider()
+	case "gcp":
+		c.provider, err = c.newGCPProvider()
+	case "azure":
+		c.provider, err = c.newAzureProvider()
+	case "":
+		return errors.New("provider required")
+	default:
+		return fmt.Errorf("invalid provider %q", c.Provider)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.reported = make(map[string]reportedCost)
+
+	return nil
+}
+
+func (c *CloudBilling) Gather(acc telegraf.Accumulator) error {
+	// Query the whole lookback window to pick up costs reported late or
+	// corrected by the provider after the initial report
+	step := 24 * time.Hour
+	if c.Granularity == "hourly" {
+		step = time.Hour
+	}
+	now := time.Now().UTC()
+	q := &costQuery{
+		start:   truncate(now.Add(-time.Duration(c.Lookback)), step),
+		end:     truncate(now, step).Add(step),
+		hourly:  c.Granularity == "hourly",
+		groupBy: c.GroupBy,
+		tagKeys: c.TagKeys,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.Timeout))
+	defer cancel()
+
+	entries, err := c.provider.query(ctx, q)
+	if err != nil {
+		return fmt.Errorf("querying costs failed: %w", err)
+	}
+
+	for _, entry := range entries {
+		tags := map[string]string{"provider": c.Provider}
+		for k, v := range entry.groups {
+			// Skip empty values, e.g. for untagged resources
+			if v != "" {
+				tags[k] = v
+			}
+		}
+
+		amount := entry.amount
+		fields := map[string]interface{}{}
+		if c.Currency != "" && entry.currency != c.Currency {
+			rate, found := c.ExchangeRates[entry.currency]
+			if !found {
+				acc.AddError(fmt.Errorf("no exchange rate for currency %q", entry.currency))
+				continue
+			}
+			fields["original_cost"] = amount
+			tags["original_currency"] = entry.currency
+			amount *= rate
+			tags["currency"] = c.Currency
+		} else {
+			tags["currency"] = entry.currency
+		}
+		fields["cost"] = amount
+
+		key := seriesKey(entry.period, tags)
+		if prev, found := c.reported[key]; found && math.Abs(prev.amount-amount) < 1e-9 {
+			continue
+		}
+		c.reported[key] = reportedCost{period: entry.period, amount: amount}
+
+		acc.AddFields("cloud_billing", fields, tags, entry.period)
+	}
+
+	// Forget about periods not queried anymore
+	for key, cost := range c.reported {
+		if cost.period.Before(q.start) {
+			delete(c.reported, key)
+		}
+	}
+
+	return nil
+}
+
+func truncate(t time.Time, step time.Duration) time.Time {
+	if step == time.Hour {
+		return t.Truncate(time.Hour)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func seriesKey(period time.Time, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var sb strings.Builder
+	sb.WriteString(period.Format(time.RFC3339))
+	for _, k := range keys {
+		sb.WriteString("," + k + "=" + tags[k])
+	}
+	return sb.String()
+}
+
+func init() {
+	inputs.Add("cloud_billing", func() telegraf.Input {
+		return &CloudBilling{
+			Lookback:      config.Duration(72 * time.Hour),
+			Timeout:       config.Duration(time.Minute),
+			AWSCostMetric: "UnblendedCost",
+			AzureCostType: "ActualCost",
+		}
+	})
+}
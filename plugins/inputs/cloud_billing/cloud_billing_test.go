@@ -0,0 +1,449 @@
+package cloud_billing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *CloudBilling
+		expected string
+	}{
+		{
+			name:     "missing provider",
+			plugin:   &CloudBilling{},
+			expected: "provider required",
+		},
+		{
+			name:     "invalid provider",
+			plugin:   &CloudBilling{Provider: "foo"},
+			expected: "invalid provider",
+		},
+		{
+			name:     "invalid granularity",
+			plugin:   &CloudBilling{Provider: "aws", Granularity: "weekly"},
+			expected: "invalid granularity",
+		},
+		{
+			name:     "invalid dimension",
+			plugin:   &CloudBilling{Provider: "aws", GroupBy: []string{"foo"}},
+			expected: "invalid group_by dimension",
+		},
+		{
+			name:     "invalid exchange rate",
+			plugin:   &CloudBilling{Provider: "aws", ExchangeRates: map[string]float64{"EUR": 0}},
+			expected: "invalid exchange rate",
+		},
+		{
+			name: "too many AWS groupings",
+			plugin: &CloudBilling{
+				Provider: "aws",
+				GroupBy:  []string{"service", "account"},
+				TagKeys:  []string{"team"},
+			},
+			expected: "at most two group_by dimensions and tag_keys are supported for AWS",
+		},
+		{
+			name:     "missing GCP table",
+			plugin:   &CloudBilling{Provider: "gcp", GCPProject: "my-project"},
+			expected: "gcp_table required",
+		},
+		{
+			name:     "invalid GCP table",
+			plugin:   &CloudBilling{Provider: "gcp", GCPProject: "my-project", GCPTable: "foo` WHERE 1"},
+			expected: "invalid gcp_table",
+		},
+		{
+			name:     "missing Azure scope",
+			plugin:   &CloudBilling{Provider: "azure"},
+			expected: "azure_scope required",
+		},
+		{
+			name:     "hourly Azure costs",
+			plugin:   &CloudBilling{Provider: "azure", AzureScope: "subscriptions/foo", Granularity: "hourly"},
+			expected: "hourly granularity is not supported for Azure",
+		},
+		{
+			name: "multiple Azure tags",
+			plugin: &CloudBilling{
+				Provider:   "azure",
+				AzureScope: "subscriptions/foo",
+				TagKeys:    []string{"team", "env"},
+			},
+			expected: "at most one tag key is supported for Azure",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Lookback = config.Duration(72 * time.Hour)
+			if tt.plugin.AWSCostMetric == "" {
+				tt.plugin.AWSCostMetric = "UnblendedCost"
+			}
+			if tt.plugin.AzureCostType == "" {
+				tt.plugin.AzureCostType = "ActualCost"
+			}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestCorrections(t *testing.T) {
+	today := truncate(time.Now().UTC(), 24*time.Hour)
+	yesterday := today.Add(-24 * time.Hour)
+
+	provider := &fakeProvider{
+		entries: []costEntry{
+			{period: yesterday, groups: map[string]string{"service": "compute"}, amount: 10, currency: "USD"},
+			{period: yesterday, groups: map[string]string{"service": "storage"}, amount: 2, currency: "EUR"},
+			{period: today, groups: map[string]string{"service": "compute"}, amount: 1, currency: "USD"},
+		},
+	}
+	plugin := &CloudBilling{
+		Provider:      "aws",
+		Lookback:      config.Duration(72 * time.Hour),
+		Timeout:       config.Duration(time.Minute),
+		AWSCostMetric: "UnblendedCost",
+		Currency:      "USD",
+		ExchangeRates: map[string]float64{"EUR": 1.5},
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.provider = provider
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cloud_billing",
+			map[string]string{"provider": "aws", "service": "compute", "currency": "USD"},
+			map[string]interface{}{"cost": 10.0},
+			yesterday,
+		),
+		testutil.MustMetric(
+			"cloud_billing",
+			map[string]string{"provider": "aws", "service": "storage", "currency": "USD", "original_currency": "EUR"},
+			map[string]interface{}{"cost": 3.0, "original_cost": 2.0},
+			yesterday,
+		),
+		testutil.MustMetric(
+			"cloud_billing",
+			map[string]string{"provider": "aws", "service": "compute", "currency": "USD"},
+			map[string]interface{}{"cost": 1.0},
+			today,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+
+	// Only the corrected cost of yesterday and the new cost of today are
+	// reported again
+	provider.entries[0].amount = 12
+	provider.entries[2].amount = 3
+	provider.entries = append(provider.entries, costEntry{
+		period:   today,
+		groups:   map[string]string{"service": "network"},
+		amount:   0.5,
+		currency: "USD",
+	})
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected = []telegraf.Metric{
+		testutil.MustMetric(
+			"cloud_billing",
+			map[string]string{"provider": "aws", "service": "compute", "currency": "USD"},
+			map[string]interface{}{"cost": 12.0},
+			yesterday,
+		),
+		testutil.MustMetric(
+			"cloud_billing",
+			map[string]string{"provider": "aws", "service": "compute", "currency": "USD"},
+			map[string]interface{}{"cost": 3.0},
+			today,
+		),
+		testutil.MustMetric(
+			"cloud_billing",
+			map[string]string{"provider": "aws", "service": "network", "currency": "USD"},
+			map[string]interface{}{"cost": 0.5},
+			today,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
+
+func TestMissingExchangeRate(t *testing.T) {
+	plugin := &CloudBilling{
+		Provider:      "aws",
+		Lookback:      config.Duration(72 * time.Hour),
+		Timeout:       config.Duration(time.Minute),
+		AWSCostMetric: "UnblendedCost",
+		Currency:      "USD",
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.provider = &fakeProvider{
+		entries: []costEntry{
+			{period: truncate(time.Now().UTC(), 24*time.Hour), amount: 2, currency: "EUR"},
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `no exchange rate for currency "EUR"`)
+}
+
+func TestAWS(t *testing.T) {
+	var requests []awsCostRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "AWSInsightsIndexService.GetCostAndUsage" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		var request awsCostRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		requests = append(requests, request)
+
+		response := `{
+			"ResultsByTime": [{
+				"TimePeriod": {"Start": "2024-01-31", "End": "2024-02-01"},
+				"Groups": [{
+					"Keys": ["Amazon Elastic Compute Cloud - Compute", "team$backend"],
+					"Metrics": {"UnblendedCost": {"Amount": "12.5", "Unit": "USD"}}
+				}]
+			}],
+			"NextPageToken": "next"
+		}`
+		if request.NextPageToken == "next" {
+			response = `{
+				"ResultsByTime": [{
+					"TimePeriod": {"Start": "2024-01-31", "End": "2024-02-01"},
+					"Groups": [{
+						"Keys": ["Amazon Simple Storage Service", "team$"],
+						"Metrics": {"UnblendedCost": {"Amount": "0.25", "Unit": "USD"}}
+					}]
+				}]
+			}`
+		}
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &CloudBilling{
+		Provider:      "aws",
+		Lookback:      config.Duration(72 * time.Hour),
+		Timeout:       config.Duration(time.Minute),
+		GroupBy:       []string{"service"},
+		TagKeys:       []string{"team"},
+		AWSCostMetric: "UnblendedCost",
+		CredentialConfig: common_aws.CredentialConfig{
+			AccessKey:   "key",
+			SecretKey:   "secret",
+			EndpointURL: server.URL,
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	require.Len(t, requests, 2)
+	require.Equal(t, "DAILY", requests[0].Granularity)
+	require.Equal(t, []string{"UnblendedCost"}, requests[0].Metrics)
+	require.Equal(t, []awsGroupDefinition{{Type: "DIMENSION", Key: "SERVICE"}, {Type: "TAG", Key: "team"}}, requests[0].GroupBy)
+
+	period := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cloud_billing",
+			map[string]string{
+				"provider": "aws",
+				"service":  "Amazon Elastic Compute Cloud - Compute",
+				"tag_team": "backend",
+				"currency": "USD",
+			},
+			map[string]interface{}{"cost": 12.5},
+			period,
+		),
+		testutil.MustMetric(
+			"cloud_billing",
+			map[string]string{
+				"provider": "aws",
+				"service":  "Amazon Simple Storage Service",
+				"currency": "USD",
+			},
+			map[string]interface{}{"cost": 0.25},
+			period,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestAzure(t *testing.T) {
+	var request azureQueryRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/subscriptions/foo/providers/Microsoft.CostManagement/query" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		response := `{
+			"properties": {
+				"columns": [
+					{"name": "Cost", "type": "Number"},
+					{"name": "UsageDate", "type": "Number"},
+					{"name": "ServiceName", "type": "String"},
+					{"name": "TagKey", "type": "String"},
+					{"name": "TagValue", "type": "String"},
+					{"name": "Currency", "type": "String"}
+				],
+				"rows": [
+					[4.2, 20240131, "Virtual Machines", "team", "backend", "EUR"],
+					[1.1, 20240130, "Storage", "team", "frontend", "EUR"]
+				]
+			}
+		}`
+		if _, err := w.Write([]byte(response)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &CloudBilling{
+		Provider:      "azure",
+		Lookback:      config.Duration(72 * time.Hour),
+		Timeout:       config.Duration(time.Minute),
+		GroupBy:       []string{"service"},
+		TagKeys:       []string{"team"},
+		AzureScope:    "/subscriptions/foo",
+		AzureCostType: "ActualCost",
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	provider, ok := plugin.provider.(*azureProvider)
+	require.True(t, ok)
+	provider.endpoint = server.URL
+	provider.credential = &fakeCredential{}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	require.Equal(t, "ActualCost", request.Type)
+	require.Equal(t, "Daily", request.Dataset.Granularity)
+	require.Equal(t, []azureGrouping{{Type: "Dimension", Name: "ServiceName"}, {Type: "TagKey", Name: "team"}}, request.Dataset.Grouping)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"cloud_billing",
+			map[string]string{
+				"provider": "azure",
+				"service":  "Virtual Machines",
+				"tag_team": "backend",
+				"currency": "EUR",
+			},
+			map[string]interface{}{"cost": 4.2},
+			time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		),
+		testutil.MustMetric(
+			"cloud_billing",
+			map[string]string{
+				"provider": "azure",
+				"service":  "Storage",
+				"tag_team": "frontend",
+				"currency": "EUR",
+			},
+			map[string]interface{}{"cost": 1.1},
+			time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestGCPStatement(t *testing.T) {
+	plugin := &CloudBilling{
+		Provider:   "gcp",
+		Lookback:   config.Duration(72 * time.Hour),
+		GroupBy:    []string{"service", "account"},
+		TagKeys:    []string{"team"},
+		GCPProject: "my-project",
+		GCPTable:   "my-project.billing.gcp_billing_export_v1_0000",
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	provider, ok := plugin.provider.(*gcpProvider)
+	require.True(t, ok)
+
+	start := time.Date(2024, 1, 29, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	statement, params := provider.statement(&costQuery{
+		start:   start,
+		end:     end,
+		groupBy: plugin.GroupBy,
+		tagKeys: plugin.TagKeys,
+	})
+
+	expected := "SELECT TIMESTAMP_TRUNC(usage_start_time, DAY) AS period, " +
+		"service.description AS service, project.id AS account, " +
+		"(SELECT value FROM UNNEST(labels) WHERE key = @tag_0) AS tag_0, currency, " +
+		"SUM(cost) + SUM(IFNULL((SELECT SUM(c.amount) FROM UNNEST(credits) AS c), 0)) AS cost " +
+		"FROM `my-project.billing.gcp_billing_export_v1_0000` " +
+		"WHERE usage_start_time >= @start AND usage_start_time < @end " +
+		"GROUP BY period, service, account, tag_0, currency"
+	require.Equal(t, expected, statement)
+	require.Len(t, params, 3)
+	require.Equal(t, start, params[0].Value)
+	require.Equal(t, end, params[1].Value)
+	require.Equal(t, "team", params[2].Value)
+}
+
+type fakeProvider struct {
+	entries []costEntry
+}
+
+func (p *fakeProvider) query(context.Context, *costQuery) ([]costEntry, error) {
+	return p.entries, nil
+}
+
+type fakeCredential struct{}
+
+func (*fakeCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
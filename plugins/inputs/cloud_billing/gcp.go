@@ -0,0 +1,151 @@
+package cloud_billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+var (
+	gcpTableRegexp = regexp.MustCompile(`^[A-Za-z0-9_.:-]+$`)
+
+	gcpDimensions = map[string]string{
+		"service": "service.description",
+		"account": "project.id",
+		"region":  "location.region",
+	}
+)
+
+// gcpProvider queries the Cloud Billing data exported to BigQuery
+type gcpProvider struct {
+	project         string
+	table           string
+	credentialsFile string
+
+	client *bigquery.Client
+}
+
+func (c *CloudBilling) newGCPProvider() (costProvider, error) {
+	if c.GCPProject == "" {
+		return nil, errors.New("gcp_project required")
+	}
+	if c.GCPTable == "" {
+		return nil, errors.New("gcp_table required")
+	}
+	if !gcpTableRegexp.MatchString(c.GCPTable) {
+		return nil, fmt.Errorf("invalid gcp_table %q", c.GCPTable)
+	}
+
+	return &gcpProvider{
+		project:         c.GCPProject,
+		table:           c.GCPTable,
+		credentialsFile: c.GCPCredentialsFile,
+	}, nil
+}
+
+// statement builds the SQL statement summing the cost of each period and
+// group including the credits granted
+func (p *gcpProvider) statement(q *costQuery) (string, []bigquery.QueryParameter) {
+	part := "DAY"
+	if q.hourly {
+		part = "HOUR"
+	}
+
+	columns := []string{"TIMESTAMP_TRUNC(usage_start_time, " + part + ") AS period"}
+	groups := []string{"period"}
+	for _, dim := range q.groupBy {
+		columns = append(columns, gcpDimensions[dim]+" AS "+dim)
+		groups = append(groups, dim)
+	}
+	params := []bigquery.QueryParameter{
+		{Name: "start", Value: q.start},
+		{Name: "end", Value: q.end},
+	}
+	for i, key := range q.tagKeys {
+		name := fmt.Sprintf("tag_%d", i)
+		columns = append(columns, "(SELECT value FROM UNNEST(labels) WHERE key = @"+name+") AS "+name)
+		groups = append(groups, name)
+		params = append(params, bigquery.QueryParameter{Name: name, Value: key})
+	}
+	columns = append(columns,
+		"currency",
+		"SUM(cost) + SUM(IFNULL((SELECT SUM(c.amount) FROM UNNEST(credits) AS c), 0)) AS cost",
+	)
+	groups = append(groups, "currency")
+
+	statement := "SELECT " + strings.Join(columns, ", ") +
+		" FROM `" + p.table + "`" +
+		" WHERE usage_start_time >= @start AND usage_start_time < @end" +
+		" GROUP BY " + strings.Join(groups, ", ")
+
+	return statement, params
+}
+
+func (p *gcpProvider) connect(ctx context.Context) (*bigquery.Client, error) {
+	if p.client != nil {
+		return p.client, nil
+	}
+
+	options := []option.ClientOption{option.WithUserAgent(internal.ProductToken())}
+	if p.credentialsFile != "" {
+		options = append(options, option.WithCredentialsFile(p.credentialsFile))
+	}
+	client, err := bigquery.NewClient(ctx, p.project, options...)
+	if err != nil {
+		return nil, fmt.Errorf("creating BigQuery client failed: %w", err)
+	}
+	p.client = client
+
+	return client, nil
+}
+
+func (p *gcpProvider) query(ctx context.Context, q *costQuery) ([]costEntry, error) {
+	client, err := p.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statement, params := p.statement(q)
+	query := client.Query(statement)
+	query.Parameters = params
+
+	it, err := query.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []costEntry
+	for {
+		var row map[string]bigquery.Value
+		err := it.Next(&row)
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entry := costEntry{groups: make(map[string]string, len(q.groupBy)+len(q.tagKeys))}
+		entry.period, _ = row["period"].(time.Time)
+		entry.amount, _ = row["cost"].(float64)
+		entry.currency, _ = row["currency"].(string)
+		for _, dim := range q.groupBy {
+			entry.groups[dim], _ = row[dim].(string)
+		}
+		for i, key := range q.tagKeys {
+			entry.groups["tag_"+key], _ = row[fmt.Sprintf("tag_%d", i)].(string)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,215 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package dirstats
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/globpath"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type DirStats struct {
+	Directories     []string        `toml:"directories"`
+	OneFilesystem   bool            `toml:"one_filesystem"`
+	UseProjectQuota bool            `toml:"use_project_quota"`
+	Log             telegraf.Logger `toml:"-"`
+
+	globs []*globpath.GlobPath
+}
+
+type inodeKey struct {
+	dev uint64
+	ino uint64
+}
+
+type treeStats struct {
+	inodes      int64
+	files       int64
+	directories int64
+	symlinks    int64
+	hardlinks   int64
+	errors      int64
+}
+
+func (*DirStats) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *DirStats) Init() error {
+	if len(d.Directories) == 0 {
+		return errors.New("no directories specified")
+	}
+
+	d.globs = make([]*globpath.GlobPath, 0, len(d.Directories))
+	for _, dir := range d.Directories {
+		g, err := globpath.Compile(dir)
+		if err != nil {
+			return fmt.Errorf("invalid directory %q: %w", dir, err)
+		}
+		d.globs = append(d.globs, g)
+	}
+
+	return nil
+}
+
+func (d *DirStats) Gather(acc telegraf.Accumulator) error {
+	for _, g := range d.globs {
+		for _, dir := range g.Match() {
+			info, err := os.Stat(dir)
+			if err != nil {
+				acc.AddError(err)
+				continue
+			}
+			if !info.IsDir() {
+				continue
+			}
+			if err := d.gatherDirectory(acc, dir); err != nil {
+				acc.AddError(fmt.Errorf("gathering statistics for %q failed: %w", dir, err))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *DirStats) gatherDirectory(acc telegraf.Accumulator, dir string) error {
+	var fsstat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &fsstat); err != nil {
+		return fmt.Errorf("getting filesystem statistics failed: %w", err)
+	}
+
+	tags := map[string]string{"directory": dir}
+	fields := map[string]interface{}{
+		"fs_inodes_total": int64(fsstat.Files),
+		"fs_inodes_free":  int64(fsstat.Ffree),
+	}
+
+	var inodes int64
+	quota, err := d.projectQuota(dir)
+	if err != nil {
+		d.Log.Debugf("Using project quota for %q failed, walking the tree instead: %v", dir, err)
+	}
+	if quota != nil {
+		tags["source"] = "project_quota"
+		tags["project_id"] = strconv.FormatUint(uint64(quota.project), 10)
+		fields["inodes"] = quota.inodes
+		if quota.softLimit > 0 {
+			fields["inodes_soft_limit"] = quota.softLimit
+		}
+		if quota.hardLimit > 0 {
+			fields["inodes_hard_limit"] = quota.hardLimit
+		}
+		inodes = quota.inodes
+	} else {
+		stats, err := d.walk(dir)
+		if err != nil {
+			return err
+		}
+		tags["source"] = "walk"
+		fields["inodes"] = stats.inodes
+		fields["files"] = stats.files
+		fields["directories"] = stats.directories
+		fields["symlinks"] = stats.symlinks
+		fields["hardlinks"] = stats.hardlinks
+		fields["walk_errors"] = stats.errors
+		inodes = stats.inodes
+	}
+
+	if fsstat.Files > 0 {
+		fields["fs_inodes_used_percent"] = float64(inodes) / float64(fsstat.Files) * 100
+	}
+	acc.AddFields("dirstats", fields, tags)
+
+	return nil
+}
+
+func (d *DirStats) projectQuota(dir string) (*quotaStats, error) {
+	if !d.UseProjectQuota {
+		return nil, nil
+	}
+	return getProjectQuota(dir)
+}
+
+// walk counts the inodes in the directory tree. Inodes referenced by
+// multiple directory entries, i.e. hard links, are only counted once.
+func (d *DirStats) walk(root string) (*treeStats, error) {
+	rootInfo, err := os.Lstat(root)
+	if err != nil {
+		return nil, err
+	}
+	rootStat, ok := rootInfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil, errors.New("unsupported file information")
+	}
+
+	var stats treeStats
+	seen := make(map[inodeKey]bool)
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			d.Log.Debugf("Walking %q failed: %v", path, err)
+			stats.errors++
+			if entry != nil && entry.IsDir() && path != root {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			d.Log.Debugf("Getting information for %q failed: %v", path, err)
+			stats.errors++
+			return nil
+		}
+		st, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			stats.errors++
+			return nil
+		}
+
+		if entry.IsDir() && d.OneFilesystem && st.Dev != rootStat.Dev {
+			return fs.SkipDir
+		}
+
+		key := inodeKey{dev: st.Dev, ino: st.Ino}
+		if seen[key] {
+			stats.hardlinks++
+			return nil
+		}
+		seen[key] = true
+		stats.inodes++
+
+		switch {
+		case entry.IsDir():
+			stats.directories++
+		case entry.Type()&fs.ModeSymlink != 0:
+			stats.symlinks++
+		default:
+			stats.files++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+func init() {
+	inputs.Add("dirstats", func() telegraf.Input {
+		return &DirStats{OneFilesystem: true}
+	})
+}
@@ -0,0 +1,33 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package dirstats
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type DirStats struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*DirStats) SampleConfig() string { return sampleConfig }
+
+func (d *DirStats) Init() error {
+	d.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*DirStats) Gather(telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("dirstats", func() telegraf.Input {
+		return &DirStats{}
+	})
+}
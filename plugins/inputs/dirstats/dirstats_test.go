@@ -0,0 +1,116 @@
+//go:build linux
+
+package dirstats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func createTree(t *testing.T, root string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "a", "b"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "file1"), []byte("foo"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a", "file2"), []byte("bar"), 0600))
+	require.NoError(t, os.Link(filepath.Join(root, "file1"), filepath.Join(root, "a", "b", "link1")))
+	require.NoError(t, os.Symlink("file1", filepath.Join(root, "symlink1")))
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &DirStats{}
+	require.ErrorContains(t, plugin.Init(), "no directories specified")
+}
+
+func TestGather(t *testing.T) {
+	root := t.TempDir()
+	createTree(t, filepath.Join(root, "tree1"))
+	require.NoError(t, os.Mkdir(filepath.Join(root, "tree2"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "not_a_directory"), nil, 0600))
+
+	plugin := &DirStats{
+		Directories:   []string{filepath.Join(root, "*")},
+		OneFilesystem: true,
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"dirstats",
+			map[string]string{"directory": filepath.Join(root, "tree1"), "source": "walk"},
+			map[string]interface{}{
+				"inodes":      int64(6),
+				"files":       int64(2),
+				"directories": int64(3),
+				"symlinks":    int64(1),
+				"hardlinks":   int64(1),
+				"walk_errors": int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"dirstats",
+			map[string]string{"directory": filepath.Join(root, "tree2"), "source": "walk"},
+			map[string]interface{}{
+				"inodes":      int64(1),
+				"files":       int64(0),
+				"directories": int64(1),
+				"symlinks":    int64(0),
+				"hardlinks":   int64(0),
+				"walk_errors": int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	// The filesystem statistics depend on the test machine
+	actual := acc.GetTelegrafMetrics()
+	for _, m := range actual {
+		require.True(t, m.HasField("fs_inodes_total"))
+		require.True(t, m.HasField("fs_inodes_free"))
+	}
+	options := []cmp.Option{
+		testutil.IgnoreTime(),
+		testutil.SortMetrics(),
+		testutil.IgnoreFields("fs_inodes_total", "fs_inodes_free", "fs_inodes_used_percent"),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, options...)
+}
+
+func TestProjectQuotaFallback(t *testing.T) {
+	root := t.TempDir()
+	createTree(t, root)
+
+	// Temporary directories usually have no project quota assigned so the
+	// plugin falls back to walking the tree
+	plugin := &DirStats{
+		Directories:     []string{root},
+		UseProjectQuota: true,
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+
+	m := acc.GetTelegrafMetrics()[0]
+	if m.Tags()["source"] == "walk" {
+		v, found := m.GetField("inodes")
+		require.True(t, found)
+		require.Equal(t, int64(6), v)
+	}
+}
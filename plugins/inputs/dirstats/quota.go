@@ -0,0 +1,150 @@
+//go:build linux
+
+package dirstats
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+const (
+	// _IOR('X', 31, struct fsxattr)
+	fsIOCFSGetXAttr = 0x801c581f
+
+	// QCMD(Q_GETQUOTA, PRJQUOTA)
+	qGetProjectQuota = 0x800007<<8 | 2
+)
+
+// fsxattr corresponds to "struct fsxattr" of <linux/fs.h>
+type fsxattr struct {
+	xflags     uint32
+	extsize    uint32
+	nextents   uint32
+	projid     uint32
+	cowextsize uint32
+	_          [8]byte
+}
+
+// dqblk corresponds to "struct if_dqblk" of <linux/quota.h>
+type dqblk struct {
+	bhardlimit uint64
+	bsoftlimit uint64
+	curspace   uint64
+	ihardlimit uint64
+	isoftlimit uint64
+	curinodes  uint64
+	btime      uint64
+	itime      uint64
+	valid      uint32
+}
+
+type quotaStats struct {
+	project   uint32
+	inodes    int64
+	softLimit int64
+	hardLimit int64
+}
+
+// getProjectQuota returns the inode usage of the project quota assigned to
+// the given directory
+func getProjectQuota(dir string) (*quotaStats, error) {
+	project, err := getProjectID(dir)
+	if err != nil {
+		return nil, fmt.Errorf("getting project ID failed: %w", err)
+	}
+	if project == 0 {
+		return nil, errors.New("no project assigned")
+	}
+
+	device, err := findDevice(dir)
+	if err != nil {
+		return nil, fmt.Errorf("finding device failed: %w", err)
+	}
+	devicePtr, err := syscall.BytePtrFromString(device)
+	if err != nil {
+		return nil, err
+	}
+
+	var quota dqblk
+	_, _, errno := syscall.Syscall6(
+		syscall.SYS_QUOTACTL,
+		uintptr(qGetProjectQuota),
+		uintptr(unsafe.Pointer(devicePtr)),
+		uintptr(project),
+		uintptr(unsafe.Pointer(&quota)),
+		0, 0,
+	)
+	if errno != 0 {
+		return nil, fmt.Errorf("getting quota of project %d on %q failed: %w", project, device, errno)
+	}
+
+	return &quotaStats{
+		project:   project,
+		inodes:    int64(quota.curinodes),
+		softLimit: int64(quota.isoftlimit),
+		hardLimit: int64(quota.ihardlimit),
+	}, nil
+}
+
+func getProjectID(dir string) (uint32, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var attr fsxattr
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), fsIOCFSGetXAttr, uintptr(unsafe.Pointer(&attr)))
+	if errno != 0 {
+		return 0, errno
+	}
+	return attr.projid, nil
+}
+
+// findDevice returns the block device of the filesystem the given directory
+// is located on by matching the device number in the mount information
+func findDevice(dir string) (string, error) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(dir, &st); err != nil {
+		return "", err
+	}
+	//nolint:unconvert // Dev is of different type on different architectures
+	dev := uint64(st.Dev)
+	devID := fmt.Sprintf("%d:%d", unix.Major(dev), unix.Minor(dev))
+
+	f, err := os.Open(filepath.Join(internal.GetProcPath(), "self", "mountinfo"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	// Lines look like
+	//   36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+	// with the device number in the third and the source after the separator
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[2] != devID {
+			continue
+		}
+		for i, field := range fields {
+			if field == "-" && i+2 < len(fields) {
+				return fields[i+2], nil
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no mount found for device %s", devID)
+}
@@ -28,6 +28,7 @@ type DiskIO struct {
 	DeviceTags       []string        `toml:"device_tags"`
 	NameTemplates    []string        `toml:"name_templates"`
 	SkipSerialNumber bool            `toml:"skip_serial_number"`
+	TopologyTags     bool            `toml:"topology_tags"`
 	Log              telegraf.Logger `toml:"-"`
 
 	ps                psutil.PS
@@ -35,6 +36,8 @@ type DiskIO struct {
 	deviceFilter      filter.Filter
 	warnDiskName      map[string]bool
 	warnDiskTags      map[string]bool
+	warnTopology      map[string]bool
+	sysClassBlock     string
 	lastIOCounterStat map[string]disk.IOCountersStat
 	lastCollectTime   time.Time
 }
@@ -57,6 +60,7 @@ func (d *DiskIO) Init() error {
 	d.infoCache = make(map[string]diskInfoCache)
 	d.warnDiskName = make(map[string]bool)
 	d.warnDiskTags = make(map[string]bool)
+	d.warnTopology = make(map[string]bool)
 	d.lastIOCounterStat = make(map[string]disk.IOCountersStat)
 
 	return nil
@@ -105,6 +109,19 @@ func (d *DiskIO) Gather(acc telegraf.Accumulator) error {
 			tags[t] = v
 		}
 
+		if d.TopologyTags {
+			topology, err := d.deviceTopology(io.Name)
+			if err != nil {
+				if !d.warnTopology[io.Name] {
+					d.warnTopology[io.Name] = true
+					d.Log.Warnf("Unable to gather storage topology for %q: %s", io.Name, err)
+				}
+			}
+			for t, v := range topology {
+				tags[t] = v
+			}
+		}
+
 		if !d.SkipSerialNumber {
 			if len(io.SerialNumber) != 0 {
 				tags["serial"] = io.SerialNumber
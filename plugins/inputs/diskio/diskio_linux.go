@@ -8,6 +8,8 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
 	"golang.org/x/sys/unix"
@@ -190,3 +192,125 @@ func getDeviceWWID(name string) string {
 	}
 	return strings.TrimSuffix(string(buf), "\n")
 }
+
+// deviceTopology determines the position of the device in the storage stack,
+// i.e. the devices it is built on and the devices built on top of it.
+func (d *DiskIO) deviceTopology(devName string) (map[string]string, error) {
+	root := d.sysClassBlock
+	if root == "" {
+		root = "/sys/class/block"
+	}
+	name := filepath.Base(devName)
+	path := filepath.Join(root, name)
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	tags := map[string]string{"topology_type": deviceType(path)}
+	if parents := listDevices(filepath.Join(path, "slaves")); len(parents) > 0 {
+		tags["topology_parents"] = strings.Join(parents, ",")
+	}
+	if holders := listDevices(filepath.Join(path, "holders")); len(holders) > 0 {
+		tags["topology_holders"] = strings.Join(holders, ",")
+	}
+	if disks := physicalDisks(root, name, make(map[string]bool)); len(disks) > 0 {
+		tags["topology_disks"] = strings.Join(disks, ",")
+	}
+	if caches := bcacheDevices(path); len(caches) > 0 {
+		tags["topology_cache"] = strings.Join(caches, ",")
+	}
+
+	return tags, nil
+}
+
+func deviceType(path string) string {
+	if _, err := os.Stat(filepath.Join(path, "dm")); err == nil {
+		// Device-mapper devices created by LVM carry a distinct UUID prefix
+		if buf, err := os.ReadFile(filepath.Join(path, "dm", "uuid")); err == nil && bytes.HasPrefix(buf, []byte("LVM-")) {
+			return "lvm"
+		}
+		return "dm"
+	}
+	if _, err := os.Stat(filepath.Join(path, "md")); err == nil {
+		return "md"
+	}
+	if strings.HasPrefix(filepath.Base(path), "bcache") {
+		if _, err := os.Stat(filepath.Join(path, "bcache")); err == nil {
+			return "bcache"
+		}
+	}
+	if _, err := os.Stat(filepath.Join(path, "partition")); err == nil {
+		return "partition"
+	}
+	return "disk"
+}
+
+// physicalDisks follows the stack down to the disks at the bottom, resolving
+// partitions to the disk containing them
+func physicalDisks(root, name string, seen map[string]bool) []string {
+	if seen[name] {
+		return nil
+	}
+	seen[name] = true
+
+	path := filepath.Join(root, name)
+	parents := listDevices(filepath.Join(path, "slaves"))
+	if len(parents) == 0 {
+		if _, err := os.Stat(filepath.Join(path, "partition")); err == nil {
+			// The partition is located within the directory of its disk
+			if resolved, err := filepath.EvalSymlinks(path); err == nil {
+				return []string{filepath.Base(filepath.Dir(resolved))}
+			}
+		}
+		return []string{name}
+	}
+
+	var disks []string
+	for _, parent := range parents {
+		for _, disk := range physicalDisks(root, parent, seen) {
+			if !slices.Contains(disks, disk) {
+				disks = append(disks, disk)
+			}
+		}
+	}
+	sort.Strings(disks)
+	return disks
+}
+
+// bcacheDevices returns the caching devices of a bcache device by following
+// the cache set of the device
+func bcacheDevices(path string) []string {
+	set := filepath.Join(path, "bcache", "cache")
+	entries, err := os.ReadDir(set)
+	if err != nil {
+		return nil
+	}
+
+	var caches []string
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), "cache") {
+			continue
+		}
+		// The entries link to the bcache directory of the caching device
+		resolved, err := filepath.EvalSymlinks(filepath.Join(set, entry.Name()))
+		if err != nil || filepath.Base(resolved) != "bcache" {
+			continue
+		}
+		caches = append(caches, filepath.Base(filepath.Dir(resolved)))
+	}
+	sort.Strings(caches)
+	return caches
+}
+
+func listDevices(path string) []string {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil
+	}
+	devices := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		devices = append(devices, entry.Name())
+	}
+	sort.Strings(devices)
+	return devices
+}
@@ -4,6 +4,8 @@ package diskio
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -82,3 +84,118 @@ func TestDiskIOStats_diskTags(t *testing.T) {
 	dt := plugin.diskTags("null")
 	require.Equal(t, map[string]string{"MY_PARAM_2": "myval2"}, dt)
 }
+
+func TestDeviceTopology(t *testing.T) {
+	// Create a sysfs tree with LVM on MD on NVMe partitions and a bcache
+	// device backed by a SATA disk and cached by a NVMe disk
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "class"), 0750))
+	devices := map[string]string{
+		"nvme0n1":   "nvme0n1",
+		"nvme0n1p1": "nvme0n1/nvme0n1p1",
+		"nvme1n1":   "nvme1n1",
+		"nvme1n1p1": "nvme1n1/nvme1n1p1",
+		"nvme2n1":   "nvme2n1",
+		"md0":       "md0",
+		"dm-0":      "dm-0",
+		"sdb":       "sdb",
+		"bcache0":   "bcache0",
+	}
+	for name, path := range devices {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "devices", path), 0750))
+		require.NoError(t, os.Symlink(filepath.Join(dir, "devices", path), filepath.Join(dir, "class", name)))
+	}
+	for _, dirs := range []string{
+		"class/nvme0n1p1/holders/md0",
+		"class/nvme1n1p1/holders/md0",
+		"class/md0/md",
+		"class/md0/slaves/nvme0n1p1",
+		"class/md0/slaves/nvme1n1p1",
+		"class/md0/holders/dm-0",
+		"class/dm-0/dm",
+		"class/dm-0/slaves/md0",
+		"class/sdb/bcache",
+		"class/sdb/holders/bcache0",
+		"class/nvme2n1/bcache",
+		"class/bcache0/slaves/sdb",
+		"fs/bcache/set",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, dirs), 0750))
+	}
+	for _, name := range []string{"class/nvme0n1p1/partition", "class/nvme1n1p1/partition"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("1\n"), 0600))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "class", "dm-0", "dm", "uuid"), []byte("LVM-x1y2z3\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fs", "bcache", "set", "cache_available_percent"), []byte("90\n"), 0600))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "devices", "nvme2n1", "bcache"), filepath.Join(dir, "fs", "bcache", "set", "cache0")))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "fs", "bcache", "set"), filepath.Join(dir, "class", "sdb", "bcache", "cache")))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "devices", "sdb", "bcache"), filepath.Join(dir, "class", "bcache0", "bcache")))
+
+	tests := []struct {
+		device   string
+		expected map[string]string
+	}{
+		{
+			device: "nvme0n1",
+			expected: map[string]string{
+				"topology_type":  "disk",
+				"topology_disks": "nvme0n1",
+			},
+		},
+		{
+			device: "nvme0n1p1",
+			expected: map[string]string{
+				"topology_type":    "partition",
+				"topology_holders": "md0",
+				"topology_disks":   "nvme0n1",
+			},
+		},
+		{
+			device: "md0",
+			expected: map[string]string{
+				"topology_type":    "md",
+				"topology_parents": "nvme0n1p1,nvme1n1p1",
+				"topology_holders": "dm-0",
+				"topology_disks":   "nvme0n1,nvme1n1",
+			},
+		},
+		{
+			device: "/dev/dm-0",
+			expected: map[string]string{
+				"topology_type":    "lvm",
+				"topology_parents": "md0",
+				"topology_disks":   "nvme0n1,nvme1n1",
+			},
+		},
+		{
+			device: "sdb",
+			expected: map[string]string{
+				"topology_type":    "disk",
+				"topology_holders": "bcache0",
+				"topology_disks":   "sdb",
+				"topology_cache":   "nvme2n1",
+			},
+		},
+		{
+			device: "bcache0",
+			expected: map[string]string{
+				"topology_type":    "bcache",
+				"topology_parents": "sdb",
+				"topology_disks":   "sdb",
+				"topology_cache":   "nvme2n1",
+			},
+		},
+	}
+
+	plugin := &DiskIO{sysClassBlock: filepath.Join(dir, "class")}
+	for _, tt := range tests {
+		t.Run(tt.device, func(t *testing.T) {
+			tags, err := plugin.deviceTopology(tt.device)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tags)
+		})
+	}
+
+	_, err := plugin.deviceTopology("sdz")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -8,6 +8,10 @@ func (*DiskIO) diskInfo(_ string) (map[string]string, error) {
 	return nil, nil
 }
 
+func (*DiskIO) deviceTopology(_ string) (map[string]string, error) {
+	return nil, nil
+}
+
 func resolveName(name string) string {
 	return name
 }
@@ -0,0 +1,445 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package dns_zone
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type DNSZone struct {
+	Zones         []string        `toml:"zones"`
+	Servers       []string        `toml:"servers"`
+	Method        string          `toml:"method"`
+	Records       []string        `toml:"records"`
+	Timeout       config.Duration `toml:"timeout"`
+	TSIGName      string          `toml:"tsig_name"`
+	TSIGSecret    config.Secret   `toml:"tsig_secret"`
+	TSIGAlgorithm string          `toml:"tsig_algorithm"`
+	Log           telegraf.Logger `toml:"-"`
+
+	addresses []string
+	queries   []query
+
+	// Records of the last transfer for incremental transfers by zone and server
+	state     map[string]map[string]*zoneState
+	stateLock sync.Mutex
+}
+
+type query struct {
+	name  string
+	rtype uint16
+}
+
+// zoneState contains the records of a zone as known from a server
+type zoneState struct {
+	serial  uint32
+	records map[string]bool
+}
+
+// result of collecting a zone from a single server
+type result struct {
+	serial  uint32
+	records map[string]bool
+	elapsed time.Duration
+	err     error
+}
+
+func (*DNSZone) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *DNSZone) Init() error {
+	if len(d.Zones) == 0 {
+		return errors.New("no zone configured")
+	}
+	if len(d.Servers) == 0 {
+		return errors.New("no server configured")
+	}
+
+	switch d.Method {
+	case "axfr", "ixfr":
+		if len(d.Records) > 0 {
+			return fmt.Errorf("records cannot be used with method %q", d.Method)
+		}
+	case "query":
+		if len(d.Records) == 0 {
+			return errors.New("records required for method \"query\"")
+		}
+	default:
+		return fmt.Errorf("invalid method %q", d.Method)
+	}
+
+	// Parse the records to query in the form "<name> <type>"
+	d.queries = make([]query, 0, len(d.Records))
+	for _, r := range d.Records {
+		parts := strings.Fields(r)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid record %q, expected \"<name> <type>\"", r)
+		}
+		rtype, found := dns.StringToType[strings.ToUpper(parts[1])]
+		if !found {
+			return fmt.Errorf("invalid record type %q in record %q", parts[1], r)
+		}
+		d.queries = append(d.queries, query{name: parts[0], rtype: rtype})
+	}
+
+	// Add the default port to the servers if necessary
+	d.addresses = make([]string, 0, len(d.Servers))
+	for _, server := range d.Servers {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, "53")
+		}
+		d.addresses = append(d.addresses, server)
+	}
+
+	if d.TSIGName != "" {
+		if d.TSIGSecret.Empty() {
+			return errors.New("tsig_secret required if tsig_name is set")
+		}
+		d.TSIGName = dns.Fqdn(d.TSIGName)
+		d.TSIGAlgorithm = dns.Fqdn(d.TSIGAlgorithm)
+	}
+
+	d.state = make(map[string]map[string]*zoneState, len(d.Zones))
+
+	return nil
+}
+
+func (d *DNSZone) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, zone := range d.Zones {
+		wg.Add(1)
+		go func(zone string) {
+			defer wg.Done()
+			d.gatherZone(acc, dns.Fqdn(zone))
+		}(zone)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// gatherZone collects the zone from all servers and compares the results of
+// secondaries to the primary, i.e. the first server
+func (d *DNSZone) gatherZone(acc telegraf.Accumulator, zone string) {
+	results := make([]result, len(d.Servers))
+	var wg sync.WaitGroup
+	for i := range d.Servers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start := time.Now()
+			results[i] = d.collect(zone, d.Servers[i], d.addresses[i])
+			results[i].elapsed = time.Since(start)
+		}(i)
+	}
+	wg.Wait()
+
+	primary := results[0]
+	for i, r := range results {
+		if r.err != nil {
+			acc.AddError(fmt.Errorf("collecting zone %q from %q failed: %w", zone, d.Servers[i], r.err))
+			continue
+		}
+
+		tags := map[string]string{
+			"zone":   strings.TrimSuffix(zone, "."),
+			"server": d.Servers[i],
+			"role":   "secondary",
+		}
+		fields := map[string]interface{}{
+			"serial":        uint64(r.serial),
+			"record_count":  len(r.records),
+			"query_time_ms": float64(r.elapsed.Nanoseconds()) / 1e6,
+		}
+
+		if i == 0 {
+			tags["role"] = "primary"
+		} else if primary.err == nil {
+			var missing, extra int
+			for record := range primary.records {
+				if !r.records[record] {
+					missing++
+				}
+			}
+			for record := range r.records {
+				if !primary.records[record] {
+					extra++
+				}
+			}
+			// Serial numbers are compared using sequence space arithmetic
+			// as defined in RFC 1982
+			lag := int64(int32(primary.serial - r.serial))
+			fields["serial_lag"] = lag
+			fields["missing_records"] = missing
+			fields["extra_records"] = extra
+			fields["drift"] = lag != 0 || missing > 0 || extra > 0
+		}
+
+		acc.AddFields("dns_zone", fields, tags)
+	}
+}
+
+func (d *DNSZone) collect(zone, server, address string) result {
+	switch d.Method {
+	case "axfr":
+		return d.transfer(zone, address)
+	case "ixfr":
+		return d.incrementalTransfer(zone, server, address)
+	}
+	return d.query(zone, address)
+}
+
+// transfer performs a full zone transfer
+func (d *DNSZone) transfer(zone, address string) result {
+	msg := new(dns.Msg)
+	msg.SetAxfr(zone)
+	rrs, err := d.exchangeTransfer(msg, address)
+	if err != nil {
+		return result{err: err}
+	}
+	if len(rrs) == 0 {
+		return result{err: errors.New("empty transfer")}
+	}
+	soa, ok := rrs[0].(*dns.SOA)
+	if !ok {
+		return result{err: errors.New("transfer does not start with SOA record")}
+	}
+
+	records := make(map[string]bool, len(rrs))
+	for _, rr := range rrs {
+		if _, ok := rr.(*dns.SOA); !ok {
+			records[normalize(rr)] = true
+		}
+	}
+	return result{serial: soa.Serial, records: records}
+}
+
+// incrementalTransfer updates the records known from the last transfer of the
+// zone from the server using an incremental zone transfer. A full transfer is
+// done if there is no previous state.
+func (d *DNSZone) incrementalTransfer(zone, server, address string) result {
+	d.stateLock.Lock()
+	if d.state[zone] == nil {
+		d.state[zone] = make(map[string]*zoneState, len(d.Servers))
+	}
+	state := d.state[zone][server]
+	d.stateLock.Unlock()
+
+	var r result
+	if state == nil {
+		r = d.transfer(zone, address)
+	} else {
+		msg := new(dns.Msg)
+		msg.SetIxfr(zone, state.serial, ".", ".")
+		rrs, err := d.exchangeTransfer(msg, address)
+		if err != nil {
+			return result{err: err}
+		}
+		serial, records, err := applyIXFR(state.records, rrs)
+		if err != nil {
+			return result{err: err}
+		}
+		r = result{serial: serial, records: records}
+	}
+	if r.err != nil {
+		return r
+	}
+
+	d.stateLock.Lock()
+	d.state[zone][server] = &zoneState{serial: r.serial, records: r.records}
+	d.stateLock.Unlock()
+
+	return r
+}
+
+// applyIXFR applies the differences of an incremental zone transfer response
+// as described in RFC 1995 to the records and returns the new serial and the
+// updated records. Responses in the format of a full transfer replace the
+// records.
+func applyIXFR(current map[string]bool, rrs []dns.RR) (uint32, map[string]bool, error) {
+	if len(rrs) == 0 {
+		return 0, nil, errors.New("empty transfer")
+	}
+	soa, ok := rrs[0].(*dns.SOA)
+	if !ok {
+		return 0, nil, errors.New("transfer does not start with SOA record")
+	}
+
+	records := make(map[string]bool, len(current))
+	if len(rrs) == 1 {
+		// Zone is up-to-date
+		for record := range current {
+			records[record] = true
+		}
+		return soa.Serial, records, nil
+	}
+
+	if _, incremental := rrs[1].(*dns.SOA); !incremental {
+		for _, rr := range rrs {
+			if _, ok := rr.(*dns.SOA); !ok {
+				records[normalize(rr)] = true
+			}
+		}
+		return soa.Serial, records, nil
+	}
+
+	for record := range current {
+		records[record] = true
+	}
+	// Each difference sequence consists of the old SOA record followed by
+	// the deleted records and the new SOA record followed by added records
+	var deleting bool
+	for _, rr := range rrs[1 : len(rrs)-1] {
+		if _, ok := rr.(*dns.SOA); ok {
+			deleting = !deleting
+			continue
+		}
+		if deleting {
+			delete(records, normalize(rr))
+		} else {
+			records[normalize(rr)] = true
+		}
+	}
+	return soa.Serial, records, nil
+}
+
+// query collects the configured records and the SOA record of the zone
+func (d *DNSZone) query(zone, address string) result {
+	client := &dns.Client{Timeout: time.Duration(d.Timeout)}
+	secret, err := d.tsigSecret()
+	if err != nil {
+		return result{err: err}
+	}
+	if secret != nil {
+		client.TsigSecret = secret
+	}
+
+	exchange := func(name string, rtype uint16) ([]dns.RR, error) {
+		msg := new(dns.Msg)
+		msg.SetQuestion(name, rtype)
+		msg.RecursionDesired = false
+		if secret != nil {
+			msg.SetTsig(d.TSIGName, d.TSIGAlgorithm, 300, time.Now().Unix())
+		}
+		resp, _, err := client.Exchange(msg, address)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Rcode != dns.RcodeSuccess && resp.Rcode != dns.RcodeNameError {
+			return nil, fmt.Errorf("invalid answer (%s) for %s query of %s", dns.RcodeToString[resp.Rcode], dns.TypeToString[rtype], name)
+		}
+		return resp.Answer, nil
+	}
+
+	answer, err := exchange(zone, dns.TypeSOA)
+	if err != nil {
+		return result{err: err}
+	}
+	var serial uint32
+	var found bool
+	for _, rr := range answer {
+		if soa, ok := rr.(*dns.SOA); ok {
+			serial = soa.Serial
+			found = true
+			break
+		}
+	}
+	if !found {
+		return result{err: errors.New("no SOA record")}
+	}
+
+	records := make(map[string]bool)
+	for _, q := range d.queries {
+		name := zone
+		if q.name != "@" {
+			name = dns.Fqdn(q.name)
+			if !strings.HasSuffix(q.name, ".") {
+				name = q.name + "." + zone
+			}
+		}
+		answer, err := exchange(name, q.rtype)
+		if err != nil {
+			return result{err: err}
+		}
+		for _, rr := range answer {
+			records[normalize(rr)] = true
+		}
+	}
+
+	return result{serial: serial, records: records}
+}
+
+func (d *DNSZone) exchangeTransfer(msg *dns.Msg, address string) ([]dns.RR, error) {
+	transfer := &dns.Transfer{
+		DialTimeout:  time.Duration(d.Timeout),
+		ReadTimeout:  time.Duration(d.Timeout),
+		WriteTimeout: time.Duration(d.Timeout),
+	}
+	secret, err := d.tsigSecret()
+	if err != nil {
+		return nil, err
+	}
+	if secret != nil {
+		transfer.TsigSecret = secret
+		msg.SetTsig(d.TSIGName, d.TSIGAlgorithm, 300, time.Now().Unix())
+	}
+
+	envelopes, err := transfer.In(msg, address)
+	if err != nil {
+		return nil, err
+	}
+
+	var rrs []dns.RR
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			err = envelope.Error
+			continue
+		}
+		rrs = append(rrs, envelope.RR...)
+	}
+	return rrs, err
+}
+
+func (d *DNSZone) tsigSecret() (map[string]string, error) {
+	if d.TSIGName == "" {
+		return nil, nil
+	}
+	secret, err := d.TSIGSecret.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting TSIG secret failed: %w", err)
+	}
+	defer secret.Destroy()
+	return map[string]string{d.TSIGName: secret.String()}, nil
+}
+
+// normalize returns the record in presentation format with the owner name in
+// lowercase to make records comparable
+func normalize(rr dns.RR) string {
+	rr.Header().Name = strings.ToLower(rr.Header().Name)
+	return rr.String()
+}
+
+func init() {
+	inputs.Add("dns_zone", func() telegraf.Input {
+		return &DNSZone{
+			Method:        "axfr",
+			Timeout:       config.Duration(5 * time.Second),
+			TSIGAlgorithm: dns.HmacSHA256,
+		}
+	})
+}
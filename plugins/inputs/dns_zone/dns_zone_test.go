@@ -0,0 +1,424 @@
+package dns_zone
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *DNSZone
+		expected string
+	}{
+		{
+			name:     "no zones",
+			plugin:   &DNSZone{Servers: []string{"127.0.0.1"}, Method: "axfr"},
+			expected: "no zone configured",
+		},
+		{
+			name:     "no servers",
+			plugin:   &DNSZone{Zones: []string{"example.com"}, Method: "axfr"},
+			expected: "no server configured",
+		},
+		{
+			name:     "invalid method",
+			plugin:   &DNSZone{Zones: []string{"example.com"}, Servers: []string{"127.0.0.1"}, Method: "foo"},
+			expected: `invalid method "foo"`,
+		},
+		{
+			name:     "query without records",
+			plugin:   &DNSZone{Zones: []string{"example.com"}, Servers: []string{"127.0.0.1"}, Method: "query"},
+			expected: "records required",
+		},
+		{
+			name: "records with transfer",
+			plugin: &DNSZone{
+				Zones:   []string{"example.com"},
+				Servers: []string{"127.0.0.1"},
+				Method:  "axfr",
+				Records: []string{"www A"},
+			},
+			expected: `records cannot be used with method "axfr"`,
+		},
+		{
+			name: "invalid record",
+			plugin: &DNSZone{
+				Zones:   []string{"example.com"},
+				Servers: []string{"127.0.0.1"},
+				Method:  "query",
+				Records: []string{"www"},
+			},
+			expected: `invalid record "www"`,
+		},
+		{
+			name: "invalid record type",
+			plugin: &DNSZone{
+				Zones:   []string{"example.com"},
+				Servers: []string{"127.0.0.1"},
+				Method:  "query",
+				Records: []string{"www FOO"},
+			},
+			expected: `invalid record type "FOO"`,
+		},
+		{
+			name: "tsig without secret",
+			plugin: &DNSZone{
+				Zones:    []string{"example.com"},
+				Servers:  []string{"127.0.0.1"},
+				Method:   "axfr",
+				TSIGName: "key",
+			},
+			expected: "tsig_secret required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGatherAXFR(t *testing.T) {
+	primary := &mockServer{
+		serial:  2,
+		records: []string{"www 3600 IN A 192.0.2.1", "mail 3600 IN A 192.0.2.2", "@ 3600 IN MX 10 mail"},
+	}
+	secondary := &mockServer{
+		serial:  1,
+		records: []string{"www 3600 IN A 192.0.2.1", "MAIL 3600 IN A 192.0.2.2", "ftp 3600 IN A 192.0.2.3"},
+	}
+	primaryAddr := primary.start(t)
+	secondaryAddr := secondary.start(t)
+
+	plugin := &DNSZone{
+		Zones:   []string{"example.com"},
+		Servers: []string{primaryAddr, secondaryAddr},
+		Method:  "axfr",
+		Timeout: config.Duration(5 * time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"dns_zone",
+			map[string]string{"zone": "example.com", "server": primaryAddr, "role": "primary"},
+			map[string]interface{}{
+				"serial":       uint64(2),
+				"record_count": 3,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"dns_zone",
+			map[string]string{"zone": "example.com", "server": secondaryAddr, "role": "secondary"},
+			map[string]interface{}{
+				"serial":          uint64(1),
+				"record_count":    3,
+				"serial_lag":      int64(1),
+				"missing_records": 1,
+				"extra_records":   1,
+				"drift":           true,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	options := []cmp.Option{
+		testutil.IgnoreTime(),
+		testutil.SortMetrics(),
+		testutil.IgnoreFields("query_time_ms"),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+}
+
+func TestGatherIXFR(t *testing.T) {
+	server := &mockServer{
+		serial:  1,
+		records: []string{"www 3600 IN A 192.0.2.1", "mail 3600 IN A 192.0.2.2"},
+	}
+	addr := server.start(t)
+
+	plugin := &DNSZone{
+		Zones:   []string{"example.com"},
+		Servers: []string{addr},
+		Method:  "ixfr",
+		Timeout: config.Duration(5 * time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	// The first transfer must be a full one
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Equal(t, []uint16{dns.TypeAXFR}, server.requests())
+
+	// Update the zone and provide the differences to the previous serial
+	server.update(t, 2, []string{"www 3600 IN A 192.0.2.1", "ftp 3600 IN A 192.0.2.3"}, map[uint32][]string{
+		1: {
+			"@ 3600 IN SOA ns1 admin 2 7200 3600 1209600 3600",
+			"@ 3600 IN SOA ns1 admin 1 7200 3600 1209600 3600",
+			"mail 3600 IN A 192.0.2.2",
+			"@ 3600 IN SOA ns1 admin 2 7200 3600 1209600 3600",
+			"ftp 3600 IN A 192.0.2.3",
+			"@ 3600 IN SOA ns1 admin 2 7200 3600 1209600 3600",
+		},
+	})
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Equal(t, []uint16{dns.TypeAXFR, dns.TypeIXFR}, server.requests())
+
+	// Zone is up-to-date
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Equal(t, []uint16{dns.TypeAXFR, dns.TypeIXFR, dns.TypeIXFR}, server.requests())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"dns_zone",
+			map[string]string{"zone": "example.com", "server": addr, "role": "primary"},
+			map[string]interface{}{
+				"serial":       uint64(2),
+				"record_count": 2,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	options := []cmp.Option{
+		testutil.IgnoreTime(),
+		testutil.IgnoreFields("query_time_ms"),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+
+	state := plugin.state["example.com."][addr]
+	require.NotNil(t, state)
+	require.Equal(t, map[string]bool{
+		"www.example.com.\t3600\tIN\tA\t192.0.2.1": true,
+		"ftp.example.com.\t3600\tIN\tA\t192.0.2.3": true,
+	}, state.records)
+}
+
+func TestGatherQuery(t *testing.T) {
+	primary := &mockServer{
+		serial:  5,
+		records: []string{"@ 3600 IN NS ns1", "www 3600 IN A 192.0.2.1", "mail 3600 IN A 192.0.2.2"},
+	}
+	secondary := &mockServer{
+		serial:  5,
+		records: []string{"@ 3600 IN NS ns1", "www 3600 IN A 192.0.2.9", "mail 3600 IN A 192.0.2.3"},
+	}
+	primaryAddr := primary.start(t)
+	secondaryAddr := secondary.start(t)
+
+	plugin := &DNSZone{
+		Zones:   []string{"example.com"},
+		Servers: []string{primaryAddr, secondaryAddr},
+		Method:  "query",
+		Records: []string{"@ NS", "www a"},
+		Timeout: config.Duration(5 * time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"dns_zone",
+			map[string]string{"zone": "example.com", "server": primaryAddr, "role": "primary"},
+			map[string]interface{}{
+				"serial":       uint64(5),
+				"record_count": 2,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"dns_zone",
+			map[string]string{"zone": "example.com", "server": secondaryAddr, "role": "secondary"},
+			map[string]interface{}{
+				"serial":          uint64(5),
+				"record_count":    2,
+				"serial_lag":      int64(0),
+				"missing_records": 1,
+				"extra_records":   1,
+				"drift":           true,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	options := []cmp.Option{
+		testutil.IgnoreTime(),
+		testutil.SortMetrics(),
+		testutil.IgnoreFields("query_time_ms"),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+}
+
+func TestGatherError(t *testing.T) {
+	secondary := &mockServer{
+		serial:  1,
+		records: []string{"www 3600 IN A 192.0.2.1"},
+	}
+	secondaryAddr := secondary.start(t)
+
+	// Get a free port without a server for the primary
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	primaryAddr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	plugin := &DNSZone{
+		Zones:   []string{"example.com"},
+		Servers: []string{primaryAddr, secondaryAddr},
+		Method:  "axfr",
+		Timeout: config.Duration(time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], primaryAddr)
+
+	// Secondaries are reported without comparison
+	expected := []telegraf.Metric{
+		metric.New(
+			"dns_zone",
+			map[string]string{"zone": "example.com", "server": secondaryAddr, "role": "secondary"},
+			map[string]interface{}{
+				"serial":       uint64(1),
+				"record_count": 1,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	options := []cmp.Option{
+		testutil.IgnoreTime(),
+		testutil.IgnoreFields("query_time_ms"),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+}
+
+// mockServer is an authoritative server for the "example.com" zone
+type mockServer struct {
+	serial  uint32
+	records []string
+	ixfr    map[uint32][]string
+
+	received []uint16
+	sync.Mutex
+}
+
+func (s *mockServer) start(t *testing.T) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", conn.LocalAddr().String())
+	require.NoError(t, err)
+
+	udpServer := &dns.Server{PacketConn: conn, Handler: s}
+	tcpServer := &dns.Server{Listener: listener, Handler: s}
+	go udpServer.ActivateAndServe() //nolint:errcheck // server is stopped on cleanup
+	go tcpServer.ActivateAndServe() //nolint:errcheck // server is stopped on cleanup
+	t.Cleanup(func() {
+		udpServer.Shutdown() //nolint:errcheck // ignore errors on cleanup
+		tcpServer.Shutdown() //nolint:errcheck // ignore errors on cleanup
+	})
+
+	return conn.LocalAddr().String()
+}
+
+func (s *mockServer) update(t *testing.T, serial uint32, records []string, ixfr map[uint32][]string) {
+	t.Helper()
+
+	s.Lock()
+	defer s.Unlock()
+	s.serial = serial
+	s.records = records
+	s.ixfr = ixfr
+}
+
+func (s *mockServer) requests() []uint16 {
+	s.Lock()
+	defer s.Unlock()
+	return append([]uint16(nil), s.received...)
+}
+
+func (s *mockServer) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	s.Lock()
+	defer s.Unlock()
+
+	resp := new(dns.Msg)
+	resp.SetReply(req)
+	resp.Authoritative = true
+
+	q := req.Question[0]
+	s.received = append(s.received, q.Qtype)
+
+	soa := parseRR(fmt.Sprintf("@ 3600 IN SOA ns1 admin %d 7200 3600 1209600 3600", s.serial))
+	records := make([]dns.RR, 0, len(s.records))
+	for _, r := range s.records {
+		records = append(records, parseRR(r))
+	}
+
+	switch q.Qtype {
+	case dns.TypeIXFR:
+		if clientSOA, ok := req.Ns[0].(*dns.SOA); ok {
+			if clientSOA.Serial == s.serial {
+				resp.Answer = []dns.RR{soa}
+				break
+			}
+			if diff, found := s.ixfr[clientSOA.Serial]; found {
+				for _, r := range diff {
+					resp.Answer = append(resp.Answer, parseRR(r))
+				}
+				break
+			}
+		}
+		fallthrough
+	case dns.TypeAXFR:
+		resp.Answer = append(append([]dns.RR{soa}, records...), soa)
+	case dns.TypeSOA:
+		resp.Answer = []dns.RR{soa}
+	default:
+		for _, rr := range records {
+			if rr.Header().Rrtype == q.Qtype && dns.CanonicalName(rr.Header().Name) == dns.CanonicalName(q.Name) {
+				resp.Answer = append(resp.Answer, rr)
+			}
+		}
+	}
+
+	w.WriteMsg(resp) //nolint:errcheck // ignore errors in test server
+}
+
+func parseRR(s string) dns.RR {
+	rr, err := dns.NewRR("$ORIGIN example.com.\n" + s)
+	if err != nil {
+		panic(err)
+	}
+	return rr
+}
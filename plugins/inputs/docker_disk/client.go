@@ -0,0 +1,58 @@
+package docker_disk
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	docker "github.com/docker/docker/client"
+)
+
+var defaultHeaders = map[string]string{"User-Agent": "engine-api-cli-1.0"}
+
+type dockerClient interface {
+	// DiskUsage retrieves the disk usage of the Docker objects.
+	DiskUsage(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error)
+	// ContainerInspect inspects a specific container and retrieves its details.
+	ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error)
+}
+
+func newEnvClient() (dockerClient, error) {
+	client, err := docker.NewClientWithOpts(docker.FromEnv)
+	if err != nil {
+		return nil, err
+	}
+	return &socketClient{client}, nil
+}
+
+func newClient(host string, tlsConfig *tls.Config) (dockerClient, error) {
+	transport := &http.Transport{
+		TLSClientConfig: tlsConfig,
+	}
+	httpClient := &http.Client{Transport: transport}
+	client, err := docker.NewClientWithOpts(
+		docker.WithHTTPHeaders(defaultHeaders),
+		docker.WithHTTPClient(httpClient),
+		docker.WithAPIVersionNegotiation(),
+		docker.WithHost(host))
+	if err != nil {
+		return nil, err
+	}
+	return &socketClient{client}, nil
+}
+
+type socketClient struct {
+	client *docker.Client
+}
+
+// DiskUsage retrieves the disk usage of the Docker objects.
+func (c *socketClient) DiskUsage(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error) {
+	return c.client.DiskUsage(ctx, options)
+}
+
+// ContainerInspect inspects a specific container and retrieves its details.
+func (c *socketClient) ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error) {
+	return c.client.ContainerInspect(ctx, containerID)
+}
@@ -0,0 +1,229 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package docker_disk
+
+import (
+	"context"
+	"crypto/tls"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal/docker"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const defaultEndpoint = "unix:///var/run/docker.sock"
+
+type DockerDisk struct {
+	Endpoint         string          `toml:"endpoint"`
+	Timeout          config.Duration `toml:"timeout"`
+	ContainerInclude []string        `toml:"container_name_include"`
+	ContainerExclude []string        `toml:"container_name_exclude"`
+	LogSize          bool            `toml:"log_size"`
+	LogPathPrefix    string          `toml:"log_path_prefix"`
+	Volumes          bool            `toml:"volumes"`
+	IncludeSourceTag bool            `toml:"source_tag"`
+	common_tls.ClientConfig
+
+	newEnvClient func() (dockerClient, error)
+	newClient    func(string, *tls.Config) (dockerClient, error)
+
+	client          dockerClient
+	containerFilter filter.Filter
+}
+
+func (*DockerDisk) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *DockerDisk) Init() error {
+	if d.Endpoint == "" {
+		d.Endpoint = defaultEndpoint
+	}
+
+	f, err := filter.NewIncludeExcludeFilter(d.ContainerInclude, d.ContainerExclude)
+	if err != nil {
+		return fmt.Errorf("creating container filter failed: %w", err)
+	}
+	d.containerFilter = f
+
+	if d.Endpoint == "ENV" {
+		d.client, err = d.newEnvClient()
+		if err != nil {
+			return err
+		}
+		return nil
+	}
+
+	tlsConfig, err := d.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	d.client, err = d.newClient(d.Endpoint, tlsConfig)
+	return err
+}
+
+func (d *DockerDisk) Gather(acc telegraf.Accumulator) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(d.Timeout))
+	defer cancel()
+
+	opts := types.DiskUsageOptions{
+		Types: []types.DiskUsageObject{types.ContainerObject, types.VolumeObject},
+	}
+	du, err := d.client.DiskUsage(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("querying disk usage failed: %w", err)
+	}
+	now := time.Now()
+
+	volumes := make(map[string]*volume.Volume, len(du.Volumes))
+	for _, v := range du.Volumes {
+		volumes[v.Name] = v
+	}
+
+	for _, cntnr := range du.Containers {
+		name := containerName(cntnr.Names)
+		if name == "" || !d.containerFilter.Match(name) {
+			continue
+		}
+
+		var volumesSize, volumeCount int64
+		for _, m := range cntnr.Mounts {
+			if m.Type != mount.TypeVolume {
+				continue
+			}
+			volumeCount++
+			if v, found := volumes[m.Name]; found && v.UsageData != nil && v.UsageData.Size > 0 {
+				volumesSize += v.UsageData.Size
+			}
+		}
+
+		imageName, imageVersion := docker.ParseImage(cntnr.Image)
+		tags := map[string]string{
+			"container_name":    name,
+			"container_image":   imageName,
+			"container_version": imageVersion,
+			"container_status":  string(cntnr.State),
+		}
+		if d.IncludeSourceTag {
+			tags["source"] = hostnameFromID(cntnr.ID)
+		}
+		fields := map[string]interface{}{
+			"size_rw":      cntnr.SizeRw,
+			"size_root_fs": cntnr.SizeRootFs,
+			"volumes_size": volumesSize,
+			"volume_count": volumeCount,
+		}
+
+		if d.LogSize {
+			size, err := d.logSize(ctx, cntnr)
+			if err != nil {
+				acc.AddError(fmt.Errorf("determining log size of container %q failed: %w", name, err))
+			} else if size >= 0 {
+				fields["log_size"] = size
+			}
+		}
+
+		acc.AddFields("docker_disk_container", fields, tags, now)
+	}
+
+	if !d.Volumes {
+		return nil
+	}
+
+	for _, v := range du.Volumes {
+		tags := map[string]string{
+			"volume_name": v.Name,
+			"driver":      v.Driver,
+		}
+		fields := make(map[string]interface{}, 2)
+		if v.UsageData != nil {
+			// A negative value indicates that the usage is not available
+			if v.UsageData.Size >= 0 {
+				fields["size"] = v.UsageData.Size
+			}
+			if v.UsageData.RefCount >= 0 {
+				fields["ref_count"] = v.UsageData.RefCount
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		acc.AddFields("docker_disk_volume", fields, tags, now)
+	}
+
+	return nil
+}
+
+// logSize returns the size of the container's log file including rotated
+// files or -1 if the logging driver does not write to a file.
+func (d *DockerDisk) logSize(ctx context.Context, cntnr *container.Summary) (int64, error) {
+	info, err := d.client.ContainerInspect(ctx, cntnr.ID)
+	if err != nil {
+		return 0, fmt.Errorf("inspecting container failed: %w", err)
+	}
+	if info.LogPath == "" {
+		return -1, nil
+	}
+
+	// Rotated files share the name of the current log file with a numeric
+	// (and potentially compression) suffix.
+	path := filepath.Join(d.LogPathPrefix, info.LogPath)
+	matches, err := filepath.Glob(path + "*")
+	if err != nil {
+		return 0, err
+	}
+
+	var size int64
+	for _, fn := range matches {
+		stat, err := os.Stat(fn)
+		if err != nil {
+			return 0, err
+		}
+		if stat.Mode().IsRegular() {
+			size += stat.Size()
+		}
+	}
+	return size, nil
+}
+
+func containerName(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(names[0], "/")
+}
+
+func hostnameFromID(id string) string {
+	if len(id) > 12 {
+		return id[0:12]
+	}
+	return id
+}
+
+func init() {
+	inputs.Add("docker_disk", func() telegraf.Input {
+		return &DockerDisk{
+			Endpoint:     defaultEndpoint,
+			Timeout:      config.Duration(30 * time.Second),
+			Volumes:      true,
+			newEnvClient: newEnvClient,
+			newClient:    newClient,
+		}
+	})
+}
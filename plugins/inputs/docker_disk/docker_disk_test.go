@@ -0,0 +1,232 @@
+package docker_disk
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &DockerDisk{
+		ContainerInclude: []string{"a[b"},
+		newClient:        newFakeClient(&fakeClient{}),
+	}
+	require.ErrorContains(t, plugin.Init(), "creating container filter failed")
+}
+
+func TestGather(t *testing.T) {
+	client := &fakeClient{usage: testUsage}
+	plugin := &DockerDisk{
+		ContainerExclude: []string{"ignored"},
+		Volumes:          true,
+		newClient:        newFakeClient(client),
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"docker_disk_container",
+			map[string]string{
+				"container_name":    "app",
+				"container_image":   "quay.io/example/app",
+				"container_version": "1.2",
+				"container_status":  "running",
+			},
+			map[string]interface{}{
+				"size_rw":      int64(4096),
+				"size_root_fs": int64(104857600),
+				"volumes_size": int64(3072),
+				"volume_count": int64(2),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"docker_disk_container",
+			map[string]string{
+				"container_name":    "db",
+				"container_image":   "postgres",
+				"container_version": "unknown",
+				"container_status":  "exited",
+			},
+			map[string]interface{}{
+				"size_rw":      int64(0),
+				"size_root_fs": int64(52428800),
+				"volumes_size": int64(0),
+				"volume_count": int64(1),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"docker_disk_volume",
+			map[string]string{
+				"volume_name": "data",
+				"driver":      "local",
+			},
+			map[string]interface{}{
+				"size":      int64(1024),
+				"ref_count": int64(1),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"docker_disk_volume",
+			map[string]string{
+				"volume_name": "cache",
+				"driver":      "local",
+			},
+			map[string]interface{}{
+				"size":      int64(2048),
+				"ref_count": int64(1),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"docker_disk_volume",
+			map[string]string{
+				"volume_name": "remote",
+				"driver":      "nfs",
+			},
+			map[string]interface{}{
+				"ref_count": int64(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherLogSize(t *testing.T) {
+	root := t.TempDir()
+	logdir := filepath.Join(root, "var", "lib", "docker", "containers", "abc")
+	require.NoError(t, os.MkdirAll(logdir, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(logdir, "abc-json.log"), make([]byte, 100), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(logdir, "abc-json.log.1"), make([]byte, 200), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(logdir, "config.v2.json"), make([]byte, 400), 0600))
+
+	client := &fakeClient{
+		usage: types.DiskUsage{
+			Containers: []*container.Summary{
+				{ID: "abc", Names: []string{"/app"}, Image: "app:1.0", State: "running", SizeRw: 10, SizeRootFs: 20},
+				{ID: "def", Names: []string{"/journald"}, Image: "app:1.0", State: "running", SizeRw: 10, SizeRootFs: 20},
+			},
+		},
+		logPaths: map[string]string{
+			"abc": "/var/lib/docker/containers/abc/abc-json.log",
+		},
+	}
+	plugin := &DockerDisk{
+		LogSize:       true,
+		LogPathPrefix: root,
+		newClient:     newFakeClient(client),
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 2)
+	for _, m := range metrics {
+		name, _ := m.GetTag("container_name")
+		size, found := m.GetField("log_size")
+		switch name {
+		case "app":
+			require.True(t, found)
+			require.Equal(t, int64(300), size)
+		case "journald":
+			require.False(t, found, "unexpected log size for container without log file")
+		default:
+			require.Failf(t, "unexpected container", "name %q", name)
+		}
+	}
+}
+
+func TestGatherFail(t *testing.T) {
+	client := &fakeClient{err: errors.New("connection refused")}
+	plugin := &DockerDisk{
+		newClient: newFakeClient(client),
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, acc.GatherError(plugin.Gather), "querying disk usage failed")
+}
+
+var testUsage = types.DiskUsage{
+	Containers: []*container.Summary{
+		{
+			ID:         "0123456789abcdef",
+			Names:      []string{"/app"},
+			Image:      "quay.io/example/app:1.2",
+			State:      "running",
+			SizeRw:     4096,
+			SizeRootFs: 104857600,
+			Mounts: []container.MountPoint{
+				{Type: mount.TypeVolume, Name: "data"},
+				{Type: mount.TypeVolume, Name: "cache"},
+				{Type: mount.TypeBind, Source: "/etc/app"},
+			},
+		},
+		{
+			ID:         "fedcba9876543210",
+			Names:      []string{"/db"},
+			Image:      "postgres",
+			State:      "exited",
+			SizeRootFs: 52428800,
+			Mounts: []container.MountPoint{
+				{Type: mount.TypeVolume, Name: "remote"},
+			},
+		},
+		{
+			ID:         "aaaaaaaaaaaaaaaa",
+			Names:      []string{"/ignored"},
+			Image:      "busybox",
+			State:      "running",
+			SizeRw:     1,
+			SizeRootFs: 1,
+		},
+	},
+	Volumes: []*volume.Volume{
+		{Name: "data", Driver: "local", UsageData: &volume.UsageData{Size: 1024, RefCount: 1}},
+		{Name: "cache", Driver: "local", UsageData: &volume.UsageData{Size: 2048, RefCount: 1}},
+		{Name: "remote", Driver: "nfs", UsageData: &volume.UsageData{Size: -1, RefCount: 1}},
+	},
+}
+
+type fakeClient struct {
+	usage    types.DiskUsage
+	logPaths map[string]string
+	err      error
+}
+
+func newFakeClient(c *fakeClient) func(string, *tls.Config) (dockerClient, error) {
+	return func(string, *tls.Config) (dockerClient, error) {
+		return c, nil
+	}
+}
+
+func (c *fakeClient) DiskUsage(context.Context, types.DiskUsageOptions) (types.DiskUsage, error) {
+	return c.usage, c.err
+}
+
+func (c *fakeClient) ContainerInspect(_ context.Context, id string) (container.InspectResponse, error) {
+	return container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{ID: id, LogPath: c.logPaths[id]},
+	}, nil
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/nagios"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 //go:embed sample.conf
@@ -26,12 +27,14 @@ var once sync.Once
 const maxStderrBytes int = 512
 
 type Exec struct {
-	Commands    []string        `toml:"commands"`
-	Command     string          `toml:"command"`
-	Environment []string        `toml:"environment"`
-	IgnoreError bool            `toml:"ignore_error"`
-	Timeout     config.Duration `toml:"timeout"`
-	Log         telegraf.Logger `toml:"-"`
+	Commands      []string        `toml:"commands"`
+	Command       string          `toml:"command"`
+	Environment   []string        `toml:"environment"`
+	IgnoreError   bool            `toml:"ignore_error"`
+	Timeout       config.Duration `toml:"timeout"`
+	MaxStdoutSize config.Size     `toml:"max_stdout_size"`
+	MaxStderrSize config.Size     `toml:"max_stderr_size"`
+	Log           telegraf.Logger `toml:"-"`
 
 	parser telegraf.Parser
 
@@ -52,6 +55,22 @@ type commandRunner struct {
 	environment []string
 	timeout     time.Duration
 	debug       bool
+	maxStdout   int64
+	maxStderr   int64
+	log         telegraf.Logger
+
+	stdoutTruncated      selfstat.Stat
+	stdoutTruncatedBytes selfstat.Stat
+	stderrTruncated      selfstat.Stat
+	stderrTruncatedBytes selfstat.Stat
+}
+
+// limitedBuffer keeps up to limit bytes written and discards the remaining
+// data. The data is still consumed to not block the writing process.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int64
+	discarded int64
 }
 
 func (*Exec) SampleConfig() string {
@@ -68,6 +87,14 @@ func (e *Exec) Init() error {
 		environment: e.Environment,
 		timeout:     time.Duration(e.Timeout),
 		debug:       e.Log.Level().Includes(telegraf.Debug),
+		maxStdout:   int64(e.MaxStdoutSize),
+		maxStderr:   int64(e.MaxStderrSize),
+		log:         e.Log,
+
+		stdoutTruncated:      selfstat.Register("exec", "stdout_truncated", map[string]string{}),
+		stdoutTruncatedBytes: selfstat.Register("exec", "stdout_truncated_bytes", map[string]string{}),
+		stderrTruncated:      selfstat.Register("exec", "stderr_truncated", map[string]string{}),
+		stderrTruncatedBytes: selfstat.Register("exec", "stderr_truncated_bytes", map[string]string{}),
 	}
 
 	return nil
@@ -164,6 +191,44 @@ func (e *Exec) processCommand(acc telegraf.Accumulator, cmd string) error {
 	return nil
 }
 
+// limit accounts for output exceeding the configured sizes. Truncated stdout
+// is cut to the last complete line to avoid parsing partial metrics.
+func (c *commandRunner) limit(command string, stdout, stderr *limitedBuffer) {
+	if stdout.discarded > 0 {
+		c.stdoutTruncated.Incr(1)
+		c.stdoutTruncatedBytes.Incr(stdout.discarded)
+		if i := bytes.LastIndexByte(stdout.buf.Bytes(), '\n'); i >= 0 {
+			stdout.buf.Truncate(i + 1)
+		}
+		c.log.Warnf("Output of command %q exceeded %d bytes, discarded %d bytes", command, stdout.limit, stdout.discarded)
+	}
+	if stderr.discarded > 0 {
+		c.stderrTruncated.Incr(1)
+		c.stderrTruncatedBytes.Incr(stderr.discarded)
+		c.log.Debugf("Error output of command %q exceeded %d bytes, discarded %d bytes", command, stderr.limit, stderr.discarded)
+	}
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit <= 0 {
+		return b.buf.Write(p)
+	}
+
+	available := b.limit - int64(b.buf.Len())
+	if available >= int64(len(p)) {
+		return b.buf.Write(p)
+	}
+	if available > 0 {
+		b.buf.Write(p[:available])
+		b.discarded += int64(len(p)) - available
+	} else {
+		b.discarded += int64(len(p))
+	}
+
+	// Pretend to write all data to not fail the process
+	return len(p), nil
+}
+
 func truncate(buf *bytes.Buffer) {
 	// Limit the number of bytes.
 	didTruncate := false
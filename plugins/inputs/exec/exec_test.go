@@ -20,6 +20,7 @@ import (
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/csv"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
 	"github.com/influxdata/telegraf/plugins/parsers/value"
 	"github.com/influxdata/telegraf/testutil"
@@ -354,6 +355,84 @@ func TestTruncate(t *testing.T) {
 	}
 }
 
+func TestLimitedBuffer(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int64
+		writes    []string
+		expected  string
+		discarded int64
+	}{
+		{
+			name:     "unlimited",
+			writes:   []string{"hello ", "world"},
+			expected: "hello world",
+		},
+		{
+			name:     "within limit",
+			limit:    11,
+			writes:   []string{"hello ", "world"},
+			expected: "hello world",
+		},
+		{
+			name:      "exceeding limit",
+			limit:     8,
+			writes:    []string{"hello ", "world", "!"},
+			expected:  "hello wo",
+			discarded: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &limitedBuffer{limit: tt.limit}
+			for _, w := range tt.writes {
+				n, err := buf.Write([]byte(w))
+				require.NoError(t, err)
+				require.Equal(t, len(w), n)
+			}
+			require.Equal(t, tt.expected, buf.buf.String())
+			require.Equal(t, tt.discarded, buf.discarded)
+		})
+	}
+}
+
+func TestOutputLimits(t *testing.T) {
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+
+	plugin := &Exec{
+		Commands:      []string{`/bin/sh -c 'echo "test value=1i"; echo "test value=2i"; echo "error message" 1>&2'`},
+		Timeout:       config.Duration(5 * time.Second),
+		MaxStdoutSize: config.Size(20),
+		MaxStderrSize: config.Size(5),
+		Log:           testutil.Logger{},
+	}
+	plugin.SetParser(parser)
+	require.NoError(t, plugin.Init())
+
+	// The statistics are shared across instances so check the difference
+	runner := plugin.runner.(*commandRunner)
+	stdoutTruncated := runner.stdoutTruncated.Get()
+	stdoutTruncatedBytes := runner.stdoutTruncatedBytes.Get()
+	stderrTruncated := runner.stderrTruncated.Get()
+	stderrTruncatedBytes := runner.stderrTruncatedBytes.Get()
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	// Only the first complete line must be parsed
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(1)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	require.Equal(t, int64(1), runner.stdoutTruncated.Get()-stdoutTruncated)
+	require.Equal(t, int64(8), runner.stdoutTruncatedBytes.Get()-stdoutTruncatedBytes)
+	require.Equal(t, int64(1), runner.stderrTruncated.Get()-stderrTruncated)
+	require.Equal(t, int64(9), runner.stderrTruncatedBytes.Get()-stderrTruncatedBytes)
+}
+
 func TestCSVBehavior(t *testing.T) {
 	// Setup the CSV parser
 	parser := &csv.Parser{
@@ -3,7 +3,6 @@
 package exec
 
 import (
-	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
@@ -27,15 +26,18 @@ func (c *commandRunner) run(command string) (out, errout []byte, err error) {
 		cmd.Env = append(os.Environ(), c.environment...)
 	}
 
-	var outbuf, stderr bytes.Buffer
-	cmd.Stdout = &outbuf
-	cmd.Stderr = &stderr
+	outbuf := &limitedBuffer{limit: c.maxStdout}
+	stderrbuf := &limitedBuffer{limit: c.maxStderr}
+	cmd.Stdout = outbuf
+	cmd.Stderr = stderrbuf
 
 	runErr := internal.RunTimeout(cmd, c.timeout)
+	c.limit(command, outbuf, stderrbuf)
 
+	stderr := &stderrbuf.buf
 	if stderr.Len() > 0 && !c.debug {
-		truncate(&stderr)
+		truncate(stderr)
 	}
 
-	return outbuf.Bytes(), stderr.Bytes(), runErr
+	return outbuf.buf.Bytes(), stderr.Bytes(), runErr
 }
@@ -31,19 +31,21 @@ func (c *commandRunner) run(command string) (out, errout []byte, err error) {
 		cmd.Env = append(os.Environ(), c.environment...)
 	}
 
-	var outbuf, stderr bytes.Buffer
-	cmd.Stdout = &outbuf
-	cmd.Stderr = &stderr
+	outbuf := &limitedBuffer{limit: c.maxStdout}
+	stderrbuf := &limitedBuffer{limit: c.maxStderr}
+	cmd.Stdout = outbuf
+	cmd.Stderr = stderrbuf
 
 	runErr := internal.RunTimeout(cmd, c.timeout)
+	c.limit(command, outbuf, stderrbuf)
 
-	outbuf = removeWindowsCarriageReturns(outbuf)
-	stderr = removeWindowsCarriageReturns(stderr)
+	stdout := removeWindowsCarriageReturns(outbuf.buf)
+	stderr := removeWindowsCarriageReturns(stderrbuf.buf)
 	if stderr.Len() > 0 && !c.debug {
 		truncate(&stderr)
 	}
 
-	return outbuf.Bytes(), stderr.Bytes(), runErr
+	return stdout.Bytes(), stderr.Bytes(), runErr
 }
 
 func removeWindowsCarriageReturns(b bytes.Buffer) bytes.Buffer {
@@ -4,6 +4,7 @@ package filecount
 import (
 	_ "embed"
 	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -28,11 +29,19 @@ type FileCount struct {
 	FollowSymlinks bool            `toml:"follow_symlinks"`
 	Size           config.Size     `toml:"size"`
 	MTime          config.Duration `toml:"mtime"`
+	ReportMissing  bool            `toml:"report_missing"`
+	WarningCount   int64           `toml:"warning_count"`
+	CriticalCount  int64           `toml:"critical_count"`
+	WarningSize    config.Size     `toml:"warning_size"`
+	CriticalSize   config.Size     `toml:"critical_size"`
+	PathTags       []string        `toml:"path_tags"`
 	Log            telegraf.Logger `toml:"-"`
 
 	fs          fileSystem
 	fileFilters []fileFilterFunc
 	globPaths   []globpath.GlobPath
+	globDirs    []string
+	templates   []*pathTemplate
 }
 
 type fileFilterFunc func(os.FileInfo) (bool, error)
@@ -41,13 +50,44 @@ func (*FileCount) SampleConfig() string {
 	return sampleConfig
 }
 
+func (fc *FileCount) Init() error {
+	if fc.WarningCount < 0 || fc.CriticalCount < 0 {
+		return errors.New("count thresholds must not be negative")
+	}
+	if fc.WarningSize < 0 || fc.CriticalSize < 0 {
+		return errors.New("size thresholds must not be negative")
+	}
+	if fc.WarningCount > 0 && fc.CriticalCount > 0 && fc.WarningCount > fc.CriticalCount {
+		return fmt.Errorf("warning_count %d exceeds critical_count %d", fc.WarningCount, fc.CriticalCount)
+	}
+	if fc.WarningSize > 0 && fc.CriticalSize > 0 && fc.WarningSize > fc.CriticalSize {
+		return fmt.Errorf("warning_size %d exceeds critical_size %d", fc.WarningSize, fc.CriticalSize)
+	}
+
+	fc.templates = make([]*pathTemplate, 0, len(fc.PathTags))
+	for _, template := range fc.PathTags {
+		t, err := newPathTemplate(template)
+		if err != nil {
+			return err
+		}
+		fc.templates = append(fc.templates, t)
+	}
+
+	return nil
+}
+
 func (fc *FileCount) Gather(acc telegraf.Accumulator) error {
 	if fc.globPaths == nil {
 		fc.initGlobPaths(acc)
 	}
 
-	for _, glob := range fc.globPaths {
-		for _, dir := range fc.onlyDirectories(glob.GetRoots()) {
+	for i, glob := range fc.globPaths {
+		dirs := fc.onlyDirectories(glob.GetRoots())
+		if len(dirs) == 0 && fc.ReportMissing {
+			fc.reportMissing(acc, fc.globDirs[i])
+			continue
+		}
+		for _, dir := range dirs {
 			fc.count(acc, dir, glob)
 		}
 	}
@@ -185,10 +225,10 @@ func (fc *FileCount) count(acc telegraf.Accumulator, basedir string, glob globpa
 			}
 			gauge["oldest_file_timestamp"] = oldestFileTimestamp[path]
 			gauge["newest_file_timestamp"] = newestFileTimestamp[path]
-			acc.AddGauge("filecount", gauge,
-				map[string]string{
-					"directory": path,
-				})
+			if fc.hasThresholds() {
+				gauge["status"] = fc.status(childCount[path], childSize[path])
+			}
+			acc.AddGauge("filecount", gauge, fc.tags(path))
 		}
 		parent := filepath.Dir(path)
 		if fc.Recursive {
@@ -226,6 +266,54 @@ func (fc *FileCount) count(acc telegraf.Accumulator, basedir string, glob globpa
 	}
 }
 
+// reportMissing emits a metric with a count of -1 for configured directories
+// not matching any existing directory to distinguish them from empty ones
+func (fc *FileCount) reportMissing(acc telegraf.Accumulator, directory string) {
+	gauge := map[string]interface{}{
+		"count":                 int64(-1),
+		"size_bytes":            int64(0),
+		"oldest_file_timestamp": int64(0),
+		"newest_file_timestamp": int64(0),
+	}
+	if fc.hasThresholds() {
+		gauge["status"] = "unknown"
+	}
+	acc.AddGauge("filecount", gauge, map[string]string{"directory": directory})
+}
+
+// tags returns the tags of the directory including the tags extracted by the
+// first matching path template
+func (fc *FileCount) tags(directory string) map[string]string {
+	tags := map[string]string{"directory": directory}
+	for _, t := range fc.templates {
+		if extracted, ok := t.match(directory); ok {
+			for k, v := range extracted {
+				tags[k] = v
+			}
+			break
+		}
+	}
+	return tags
+}
+
+func (fc *FileCount) hasThresholds() bool {
+	return fc.WarningCount > 0 || fc.CriticalCount > 0 || fc.WarningSize > 0 || fc.CriticalSize > 0
+}
+
+// status evaluates the count and size of a directory against the configured
+// thresholds, a threshold is exceeded if the value reaches the threshold
+func (fc *FileCount) status(count, size int64) string {
+	switch {
+	case fc.CriticalCount > 0 && count >= fc.CriticalCount,
+		fc.CriticalSize > 0 && size >= int64(fc.CriticalSize):
+		return "critical"
+	case fc.WarningCount > 0 && count >= fc.WarningCount,
+		fc.WarningSize > 0 && size >= int64(fc.WarningSize):
+		return "warning"
+	}
+	return "ok"
+}
+
 func (fc *FileCount) filter(file os.FileInfo) (bool, error) {
 	if fc.fileFilters == nil {
 		fc.initFileFilters()
@@ -282,12 +370,14 @@ func (fc *FileCount) getDirs() []string {
 func (fc *FileCount) initGlobPaths(acc telegraf.Accumulator) {
 	dirs := fc.getDirs()
 	fc.globPaths = make([]globpath.GlobPath, 0, len(dirs))
+	fc.globDirs = make([]string, 0, len(dirs))
 	for _, directory := range dirs {
 		glob, err := globpath.Compile(directory)
 		if err != nil {
 			acc.AddError(err)
 		} else {
 			fc.globPaths = append(fc.globPaths, *glob)
+			fc.globDirs = append(fc.globDirs, directory)
 		}
 	}
 }
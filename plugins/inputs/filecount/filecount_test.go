@@ -175,6 +175,215 @@ func TestDirectoryWithTrailingSlash(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
 }
 
+func TestReportMissing(t *testing.T) {
+	fc := getNoFilterFileCount()
+	missing := filepath.Join(getTestdataDir(), "missing")
+	fc.Directories = []string{getTestdataDir() + "/subdir", missing, missing + "/*"}
+	fc.ReportMissing = true
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, acc.GatherError(fc.Gather))
+
+	tags := map[string]string{"directory": getTestdataDir() + "/subdir"}
+	require.True(t, acc.HasPoint("filecount", tags, "count", int64(4)))
+	for _, dir := range []string{missing, missing + "/*"} {
+		tags := map[string]string{"directory": dir}
+		require.True(t, acc.HasPoint("filecount", tags, "count", int64(-1)))
+		require.True(t, acc.HasPoint("filecount", tags, "size_bytes", int64(0)))
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *FileCount
+		expected string
+	}{
+		{
+			name:     "negative count",
+			plugin:   &FileCount{WarningCount: -1},
+			expected: "count thresholds must not be negative",
+		},
+		{
+			name:     "negative size",
+			plugin:   &FileCount{CriticalSize: config.Size(-1)},
+			expected: "size thresholds must not be negative",
+		},
+		{
+			name:     "warning count exceeds critical",
+			plugin:   &FileCount{WarningCount: 10, CriticalCount: 5},
+			expected: "warning_count 10 exceeds critical_count 5",
+		},
+		{
+			name:     "warning size exceeds critical",
+			plugin:   &FileCount{WarningSize: config.Size(2048), CriticalSize: config.Size(1024)},
+			expected: "warning_size 2048 exceeds critical_size 1024",
+		},
+		{
+			name:     "path template without tags",
+			plugin:   &FileCount{PathTags: []string{"/data/queue"}},
+			expected: `no tags in path template "/data/queue"`,
+		},
+		{
+			name:     "path template with invalid tag",
+			plugin:   &FileCount{PathTags: []string{"/data/{tenant}-{queue}"}},
+			expected: `invalid tag "{tenant}-{queue}" in path template "/data/{tenant}-{queue}"`,
+		},
+		{
+			name:     "path template with duplicate tag",
+			plugin:   &FileCount{PathTags: []string{"/data/{queue}/{queue}"}},
+			expected: `duplicate tag "queue" in path template "/data/{queue}/{queue}"`,
+		},
+		{
+			name:     "path template with reserved tag",
+			plugin:   &FileCount{PathTags: []string{"/data/{directory}"}},
+			expected: `tag name "directory" in path template "/data/{directory}" is reserved`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestPathTags(t *testing.T) {
+	fc := getNoFilterFileCount()
+	fc.Directories = []string{getTestdataDir() + "/**"}
+	fc.PathTags = []string{
+		getTestdataDir() + "/{dir}/{nested}",
+		getTestdataDir() + "/{dir}",
+	}
+	require.NoError(t, fc.Init())
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, acc.GatherError(fc.Gather))
+
+	// Directories are tagged by the first template matching their leading
+	// components
+	expected := map[string]map[string]string{
+		getTestdataDir() + "/subdir": {
+			"directory": getTestdataDir() + "/subdir",
+			"dir":       "subdir",
+		},
+		getTestdataDir() + "/subdir/nested2": {
+			"directory": getTestdataDir() + "/subdir/nested2",
+			"dir":       "subdir",
+			"nested":    "nested2",
+		},
+	}
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, len(expected))
+	for _, m := range metrics {
+		directory, _ := m.GetTag("directory")
+		require.Equal(t, expected[directory], m.Tags(), directory)
+	}
+}
+
+func TestPathTemplateMatch(t *testing.T) {
+	tmpl, err := newPathTemplate("/data/*/{tenant}/{queue}")
+	require.NoError(t, err)
+
+	tags, ok := tmpl.match("/data/prod/acme/orders")
+	require.True(t, ok)
+	require.Equal(t, map[string]string{"tenant": "acme", "queue": "orders"}, tags)
+
+	tags, ok = tmpl.match("/data/prod/acme/orders/archive/")
+	require.True(t, ok)
+	require.Equal(t, map[string]string{"tenant": "acme", "queue": "orders"}, tags)
+
+	_, ok = tmpl.match("/data/prod/acme")
+	require.False(t, ok)
+	_, ok = tmpl.match("/spool/prod/acme/orders")
+	require.False(t, ok)
+}
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		warningCount  int64
+		criticalCount int64
+		warningSize   config.Size
+		criticalSize  config.Size
+		expected      string
+	}{
+		{
+			name:          "ok",
+			warningCount:  5,
+			criticalCount: 10,
+			expected:      "ok",
+		},
+		{
+			name:          "warning count",
+			warningCount:  4,
+			criticalCount: 10,
+			expected:      "warning",
+		},
+		{
+			name:          "critical count",
+			warningCount:  2,
+			criticalCount: 4,
+			expected:      "critical",
+		},
+		{
+			name:        "warning size only",
+			warningSize: config.Size(1),
+			expected:    "warning",
+		},
+		{
+			name:         "critical size with count ok",
+			warningCount: 100,
+			criticalSize: config.Size(1),
+			expected:     "critical",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := getNoFilterFileCount()
+			fc.Directories = []string{getTestdataDir() + "/subdir"}
+			fc.WarningCount = tt.warningCount
+			fc.CriticalCount = tt.criticalCount
+			fc.WarningSize = tt.warningSize
+			fc.CriticalSize = tt.criticalSize
+			require.NoError(t, fc.Init())
+
+			acc := testutil.Accumulator{}
+			require.NoError(t, acc.GatherError(fc.Gather))
+
+			tags := map[string]string{"directory": getTestdataDir() + "/subdir"}
+			require.True(t, acc.HasPoint("filecount", tags, "count", int64(4)))
+			require.True(t, acc.HasPoint("filecount", tags, "status", tt.expected))
+		})
+	}
+}
+
+func TestStatusDisabled(t *testing.T) {
+	fc := getNoFilterFileCount()
+	fc.Directories = []string{getTestdataDir() + "/subdir"}
+	fc.ReportMissing = true
+	require.NoError(t, fc.Init())
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, acc.GatherError(fc.Gather))
+	require.True(t, acc.HasMeasurement("filecount"))
+	require.False(t, acc.HasField("filecount", "status"))
+}
+
+func TestStatusMissing(t *testing.T) {
+	fc := getNoFilterFileCount()
+	missing := filepath.Join(getTestdataDir(), "missing")
+	fc.Directories = []string{missing}
+	fc.ReportMissing = true
+	fc.CriticalCount = 1
+	require.NoError(t, fc.Init())
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, acc.GatherError(fc.Gather))
+	require.True(t, acc.HasPoint("filecount", map[string]string{"directory": missing}, "status", "unknown"))
+}
+
 func getNoFilterFileCount() FileCount {
 	return FileCount{
 		Log:         testutil.Logger{},
@@ -0,0 +1,77 @@
+package filecount
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// pathTemplate extracts tags from the components of a directory path, e.g.
+// the template "/data/{tenant}/{queue}" extracts the "tenant" and "queue"
+// tags from the directory "/data/acme/orders"
+type pathTemplate struct {
+	components []string
+	tags       map[int]string
+}
+
+func newPathTemplate(template string) (*pathTemplate, error) {
+	t := &pathTemplate{
+		components: splitPath(template),
+		tags:       make(map[int]string),
+	}
+	seen := make(map[string]bool)
+	for i, component := range t.components {
+		if strings.HasPrefix(component, "{") && strings.HasSuffix(component, "}") {
+			name := component[1 : len(component)-1]
+			if name == "" || strings.ContainsAny(name, "{}") {
+				return nil, fmt.Errorf("invalid tag %q in path template %q", component, template)
+			}
+			if name == "directory" {
+				return nil, fmt.Errorf("tag name %q in path template %q is reserved", name, template)
+			}
+			if seen[name] {
+				return nil, fmt.Errorf("duplicate tag %q in path template %q", name, template)
+			}
+			seen[name] = true
+			t.tags[i] = name
+			continue
+		}
+		if strings.ContainsAny(component, "{}") {
+			return nil, fmt.Errorf("invalid component %q in path template %q", component, template)
+		}
+		if _, err := path.Match(component, ""); err != nil {
+			return nil, fmt.Errorf("invalid component %q in path template %q: %w", component, template, err)
+		}
+	}
+	if len(t.tags) == 0 {
+		return nil, fmt.Errorf("no tags in path template %q", template)
+	}
+	return t, nil
+}
+
+// match returns the tags of the template if the leading components of the
+// directory match the template. Components of the template other than tags
+// can contain wildcards.
+func (t *pathTemplate) match(directory string) (map[string]string, bool) {
+	components := splitPath(directory)
+	if len(components) < len(t.components) {
+		return nil, false
+	}
+
+	tags := make(map[string]string, len(t.tags))
+	for i, pattern := range t.components {
+		if name, found := t.tags[i]; found {
+			tags[name] = components[i]
+			continue
+		}
+		if matched, _ := path.Match(pattern, components[i]); !matched {
+			return nil, false
+		}
+	}
+	return tags, true
+}
+
+func splitPath(p string) []string {
+	return strings.Split(strings.Trim(filepath.ToSlash(filepath.Clean(p)), "/"), "/")
+}
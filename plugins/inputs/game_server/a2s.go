@@ -0,0 +1,211 @@
+package game_server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Response types of the A2S protocol
+const (
+	a2sChallenge byte = 'A'
+	a2sInfo      byte = 'I'
+	a2sPlayer    byte = 'D'
+)
+
+// App ID of "The Ship" reporting additional fields in the info response
+const a2sAppIDTheShip = 2400
+
+var (
+	a2sSinglePacket = []byte{0xff, 0xff, 0xff, 0xff}
+	a2sSplitPacket  = []byte{0xfe, 0xff, 0xff, 0xff}
+	a2sInfoRequest  = []byte("\xff\xff\xff\xffTSource Engine Query\x00")
+	a2sPlayerQuery  = []byte("\xff\xff\xff\xffU")
+)
+
+type a2sClient struct{}
+
+func (*a2sClient) query(address string, timeout time.Duration) (*serverStatus, error) {
+	conn, err := net.DialTimeout("udp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	// The info request gets the challenge appended while the player request
+	// replaces the initial challenge of -1 with the received one
+	resp, err := a2sExchange(conn, a2sInfo, func(challenge []byte) []byte {
+		return append(bytes.Clone(a2sInfoRequest), challenge...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("requesting info failed: %w", err)
+	}
+	status, err := parseA2SInfo(resp)
+	if err != nil {
+		return nil, fmt.Errorf("parsing info failed: %w", err)
+	}
+
+	resp, err = a2sExchange(conn, a2sPlayer, func(challenge []byte) []byte {
+		if challenge == nil {
+			challenge = a2sSinglePacket
+		}
+		return append(bytes.Clone(a2sPlayerQuery), challenge...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("requesting players failed: %w", err)
+	}
+	if status.players, err = parseA2SPlayers(resp); err != nil {
+		return nil, fmt.Errorf("parsing players failed: %w", err)
+	}
+
+	return status, nil
+}
+
+// a2sExchange sends the request created by the given function and answers
+// challenges of the server by resending the request including the challenge.
+// The response payload starting after the type byte is returned.
+func a2sExchange(conn net.Conn, expected byte, request func(challenge []byte) []byte) ([]byte, error) {
+	var challenge []byte
+	buf := make([]byte, 65535)
+	for range 3 {
+		if _, err := conn.Write(request(challenge)); err != nil {
+			return nil, err
+		}
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		resp := buf[:n]
+		if bytes.HasPrefix(resp, a2sSplitPacket) {
+			return nil, errors.New("split responses are not supported")
+		}
+		if len(resp) < 5 || !bytes.HasPrefix(resp, a2sSinglePacket) {
+			return nil, errors.New("invalid response header")
+		}
+
+		switch resp[4] {
+		case expected:
+			return bytes.Clone(resp[5:]), nil
+		case a2sChallenge:
+			if len(resp) < 9 {
+				return nil, errors.New("invalid challenge")
+			}
+			challenge = bytes.Clone(resp[5:9])
+		default:
+			return nil, fmt.Errorf("unexpected response type 0x%02x", resp[4])
+		}
+	}
+
+	return nil, errors.New("too many challenges")
+}
+
+// parseA2SInfo parses the payload of an A2S_INFO response
+func parseA2SInfo(data []byte) (*serverStatus, error) {
+	r := bufio.NewReader(bytes.NewReader(data))
+
+	var header struct {
+		Players     uint8
+		MaxPlayers  uint8
+		Bots        uint8
+		ServerType  uint8
+		Environment uint8
+		Visibility  uint8
+		VAC         uint8
+	}
+	var appID uint16
+	status := &serverStatus{}
+
+	// Skip the protocol version
+	if _, err := r.ReadByte(); err != nil {
+		return nil, err
+	}
+	var folder string
+	for _, s := range []*string{&status.name, &status.mapName, &folder, &status.game} {
+		v, err := readCString(r)
+		if err != nil {
+			return nil, err
+		}
+		*s = v
+	}
+	if err := binary.Read(r, binary.LittleEndian, &appID); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if appID == a2sAppIDTheShip {
+		if _, err := r.Discard(3); err != nil {
+			return nil, err
+		}
+	}
+	version, err := readCString(r)
+	if err != nil {
+		return nil, err
+	}
+	status.version = version
+
+	// The number of players includes the bots
+	status.bots = int(header.Bots)
+	status.online = max(int(header.Players)-status.bots, 0)
+	status.max = int(header.MaxPlayers)
+
+	return status, nil
+}
+
+// parseA2SPlayers parses the payload of an A2S_PLAYER response
+func parseA2SPlayers(data []byte) ([]player, error) {
+	r := bufio.NewReader(bytes.NewReader(data))
+
+	count, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	players := make([]player, 0, count)
+	for range count {
+		// Skip the index
+		if _, err := r.ReadByte(); err != nil {
+			return nil, err
+		}
+		name, err := readCString(r)
+		if err != nil {
+			return nil, err
+		}
+		var stats struct {
+			Score    int32
+			Duration float32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &stats); err != nil {
+			return nil, err
+		}
+
+		// Players still connecting do not have a name yet
+		if name == "" {
+			continue
+		}
+		players = append(players, player{
+			name: name,
+			fields: map[string]interface{}{
+				"score":    int64(stats.Score),
+				"duration": float64(stats.Duration),
+			},
+		})
+	}
+
+	return players, nil
+}
+
+func readCString(r *bufio.Reader) (string, error) {
+	s, err := r.ReadString(0)
+	if err != nil {
+		return "", err
+	}
+	return s[:len(s)-1], nil
+}
@@ -0,0 +1,183 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package game_server
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type GameServer struct {
+	Timeout config.Duration `toml:"timeout"`
+	Servers []*server       `toml:"server"`
+	Log     telegraf.Logger `toml:"-"`
+}
+
+type server struct {
+	Address  string            `toml:"address"`
+	Protocol string            `toml:"protocol"`
+	Password config.Secret     `toml:"password"`
+	Game     string            `toml:"game"`
+	Tags     map[string]string `toml:"tags"`
+
+	client querier
+}
+
+// querier retrieves the status of a game server using a specific protocol
+type querier interface {
+	query(address string, timeout time.Duration) (*serverStatus, error)
+}
+
+// serverStatus contains the information reported by a game server
+type serverStatus struct {
+	name    string
+	game    string
+	mapName string
+	version string
+	online  int
+	max     int
+	bots    int
+	players []player
+}
+
+// player contains the information reported for a connected player
+type player struct {
+	name   string
+	fields map[string]interface{}
+}
+
+func (*GameServer) SampleConfig() string {
+	return sampleConfig
+}
+
+func (g *GameServer) Init() error {
+	if len(g.Servers) == 0 {
+		return errors.New("no servers configured")
+	}
+
+	for i, s := range g.Servers {
+		if s.Address == "" {
+			return fmt.Errorf("server %d: address required", i+1)
+		}
+
+		var defaultPort string
+		switch s.Protocol {
+		case "rcon":
+			switch s.Game {
+			case "", "source":
+				s.Game = "source"
+				defaultPort = "27015"
+			case "minecraft":
+				defaultPort = "25575"
+			default:
+				return fmt.Errorf("server %q: invalid game %q", s.Address, s.Game)
+			}
+			s.client = &rconClient{password: s.Password, game: s.Game}
+		case "query":
+			defaultPort = "25565"
+			s.client = &queryClient{}
+		case "a2s":
+			defaultPort = "27015"
+			s.client = &a2sClient{}
+		case "":
+			return fmt.Errorf("server %q: protocol required", s.Address)
+		default:
+			return fmt.Errorf("server %q: invalid protocol %q", s.Address, s.Protocol)
+		}
+
+		// Use the default port of the protocol if none is given
+		if _, _, err := net.SplitHostPort(s.Address); err != nil {
+			if !strings.Contains(err.Error(), "missing port") {
+				return fmt.Errorf("server %q: invalid address: %w", s.Address, err)
+			}
+			s.Address = net.JoinHostPort(strings.Trim(s.Address, "[]"), defaultPort)
+		}
+	}
+
+	return nil
+}
+
+func (g *GameServer) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, s := range g.Servers {
+		wg.Add(1)
+		go func(s *server) {
+			defer wg.Done()
+			if err := g.gatherServer(acc, s); err != nil {
+				acc.AddError(fmt.Errorf("querying %q failed: %w", s.Address, err))
+			}
+		}(s)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (g *GameServer) gatherServer(acc telegraf.Accumulator, s *server) error {
+	start := time.Now()
+	status, err := s.client.query(s.Address, time.Duration(g.Timeout))
+	if err != nil {
+		return err
+	}
+	responseTime := time.Since(start)
+
+	tags := make(map[string]string, len(s.Tags)+2)
+	for k, v := range s.Tags {
+		tags[k] = v
+	}
+	tags["server"] = s.Address
+	tags["protocol"] = s.Protocol
+
+	fields := map[string]interface{}{
+		"players_online": status.online,
+		"players_max":    status.max,
+		"response_time":  responseTime.Seconds(),
+	}
+	if s.Protocol != "query" {
+		fields["bots"] = status.bots
+	}
+	for k, v := range map[string]string{
+		"name":    status.name,
+		"game":    status.game,
+		"map":     status.mapName,
+		"version": status.version,
+	} {
+		if v != "" {
+			fields[k] = v
+		}
+	}
+	acc.AddFields("game_server", fields, tags, start)
+
+	for _, p := range status.players {
+		if len(p.fields) == 0 {
+			continue
+		}
+		ptags := make(map[string]string, len(tags)+1)
+		for k, v := range tags {
+			ptags[k] = v
+		}
+		ptags["player"] = p.name
+		acc.AddFields("game_server_player", p.fields, ptags, start)
+	}
+
+	return nil
+}
+
+func init() {
+	inputs.Add("game_server", func() telegraf.Input {
+		return &GameServer{
+			Timeout: config.Duration(5 * time.Second),
+		}
+	})
+}
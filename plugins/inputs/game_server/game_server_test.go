@@ -0,0 +1,432 @@
+package game_server
+
+import (
+	"encoding/binary"
+	"math"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorcon/rcon/rcontest"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		servers  []*server
+		expected string
+	}{
+		{
+			name:     "no servers",
+			expected: "no servers configured",
+		},
+		{
+			name:     "missing address",
+			servers:  []*server{{Protocol: "a2s"}},
+			expected: "address required",
+		},
+		{
+			name:     "missing protocol",
+			servers:  []*server{{Address: "localhost"}},
+			expected: "protocol required",
+		},
+		{
+			name:     "invalid protocol",
+			servers:  []*server{{Address: "localhost", Protocol: "foo"}},
+			expected: `invalid protocol "foo"`,
+		},
+		{
+			name:     "invalid game",
+			servers:  []*server{{Address: "localhost", Protocol: "rcon", Game: "foo"}},
+			expected: `invalid game "foo"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &GameServer{
+				Servers: tt.servers,
+				Log:     testutil.Logger{},
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInitDefaultPorts(t *testing.T) {
+	plugin := &GameServer{
+		Servers: []*server{
+			{Address: "localhost", Protocol: "rcon"},
+			{Address: "localhost", Protocol: "rcon", Game: "minecraft"},
+			{Address: "localhost", Protocol: "query"},
+			{Address: "[::1]", Protocol: "a2s"},
+			{Address: "localhost:1234", Protocol: "a2s"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	addresses := make([]string, 0, len(plugin.Servers))
+	for _, s := range plugin.Servers {
+		addresses = append(addresses, s.Address)
+	}
+	require.Equal(t, []string{"localhost:27015", "localhost:25575", "localhost:25565", "[::1]:27015", "localhost:1234"}, addresses)
+}
+
+func TestParseSourceStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		expected *serverStatus
+	}{
+		{
+			name: "current",
+			file: "source_status.txt",
+			expected: &serverStatus{
+				name:    "Fleet Server 01",
+				mapName: "de_dust2",
+				version: "1.38.8.1/13881",
+				online:  2,
+				max:     16,
+				bots:    1,
+				players: []player{
+					{
+						name:   "alice",
+						fields: map[string]interface{}{"duration": 723.0, "ping": int64(45), "loss": int64(0)},
+					},
+					{
+						name:   "bob",
+						fields: map[string]interface{}{"duration": 3735.0, "ping": int64(80), "loss": int64(2)},
+					},
+				},
+			},
+		},
+		{
+			name: "legacy",
+			file: "source_status_legacy.txt",
+			expected: &serverStatus{
+				name:    "Legacy Server",
+				mapName: "cp_badlands",
+				version: "2.0.0.0/24",
+				online:  1,
+				max:     24,
+				players: []player{
+					{
+						name:   "carol",
+						fields: map[string]interface{}{"duration": 330.0, "ping": int64(60), "loss": int64(0)},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := os.ReadFile("testdata/" + tt.file)
+			require.NoError(t, err)
+			status, err := parseSourceStatus(string(buf))
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, status)
+		})
+	}
+}
+
+func TestParseSourceStatusInvalid(t *testing.T) {
+	_, err := parseSourceStatus("Unknown command \"status\"")
+	require.ErrorContains(t, err, "no player information")
+}
+
+func TestParseMinecraftList(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *serverStatus
+	}{
+		{
+			name:  "current",
+			input: "There are 2 of a max of 20 players online: notch, dinnerbone",
+			expected: &serverStatus{
+				game:    "minecraft",
+				online:  2,
+				max:     20,
+				players: []player{{name: "notch"}, {name: "dinnerbone"}},
+			},
+		},
+		{
+			name:  "legacy",
+			input: "There are 1/20 players online:\njeb",
+			expected: &serverStatus{
+				game:    "minecraft",
+				online:  1,
+				max:     20,
+				players: []player{{name: "jeb"}},
+			},
+		},
+		{
+			name:     "empty",
+			input:    "There are 0 of a max of 20 players online: ",
+			expected: &serverStatus{game: "minecraft", max: 20},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := parseMinecraftList(tt.input)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, status)
+		})
+	}
+}
+
+func TestGatherRCON(t *testing.T) {
+	buf, err := os.ReadFile("testdata/source_status.txt")
+	require.NoError(t, err)
+
+	srv := rcontest.NewServer(
+		rcontest.SetSettings(rcontest.Settings{Password: "secret"}),
+		rcontest.SetCommandHandler(func(c *rcontest.Context) {
+			resp := "Unknown command"
+			if c.Request().Body() == "status" {
+				resp = string(buf)
+			}
+			_, _ = rcon.NewPacket(rcon.SERVERDATA_RESPONSE_VALUE, c.Request().ID, resp).WriteTo(c.Conn())
+		}),
+	)
+	defer srv.Close()
+
+	plugin := &GameServer{
+		Timeout: config.Duration(5 * time.Second),
+		Servers: []*server{
+			{
+				Address:  srv.Addr(),
+				Protocol: "rcon",
+				Password: config.NewSecret([]byte("secret")),
+				Tags:     map[string]string{"region": "eu"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	tags := map[string]string{"server": srv.Addr(), "protocol": "rcon", "region": "eu"}
+	expected := []telegraf.Metric{
+		metric.New(
+			"game_server",
+			tags,
+			map[string]interface{}{
+				"name":           "Fleet Server 01",
+				"map":            "de_dust2",
+				"version":        "1.38.8.1/13881",
+				"players_online": 2,
+				"players_max":    16,
+				"bots":           1,
+				"response_time":  0.0,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"game_server_player",
+			map[string]string{"server": srv.Addr(), "protocol": "rcon", "region": "eu", "player": "alice"},
+			map[string]interface{}{"duration": 723.0, "ping": int64(45), "loss": int64(0)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"game_server_player",
+			map[string]string{"server": srv.Addr(), "protocol": "rcon", "region": "eu", "player": "bob"},
+			map[string]interface{}{"duration": 3735.0, "ping": int64(80), "loss": int64(2)},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(),
+		testutil.IgnoreTime(), testutil.SortMetrics(), testutil.IgnoreFields("response_time"))
+}
+
+func TestGatherQuery(t *testing.T) {
+	addr := startUDPServer(t, func(req []byte) []byte {
+		if len(req) < 7 || req[0] != 0xfe || req[1] != 0xfd {
+			return nil
+		}
+		session := req[3:7]
+		switch req[2] {
+		case queryHandshake:
+			resp := append([]byte{queryHandshake}, session...)
+			return append(resp, []byte("9513307\x00")...)
+		case queryStat:
+			if len(req) != 15 || binary.BigEndian.Uint32(req[7:11]) != 9513307 {
+				return nil
+			}
+			resp := append([]byte{queryStat}, session...)
+			resp = append(resp, queryStatPadding...)
+			resp = append(resp, []byte("hostname\x00A Minecraft Server\x00gametype\x00SMP\x00game_id\x00MINECRAFT\x00"+
+				"version\x001.21.1\x00plugins\x00\x00map\x00world\x00numplayers\x002\x00maxplayers\x0020\x00"+
+				"hostport\x0025565\x00hostip\x00127.0.0.1\x00\x00")...)
+			resp = append(resp, []byte("\x01player_\x00\x00notch\x00jeb\x00\x00")...)
+			return resp
+		}
+		return nil
+	})
+
+	plugin := &GameServer{
+		Timeout: config.Duration(5 * time.Second),
+		Servers: []*server{{Address: addr, Protocol: "query"}},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"game_server",
+			map[string]string{"server": addr, "protocol": "query"},
+			map[string]interface{}{
+				"name":           "A Minecraft Server",
+				"game":           "MINECRAFT",
+				"map":            "world",
+				"version":        "1.21.1",
+				"players_online": 2,
+				"players_max":    20,
+				"response_time":  0.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(),
+		testutil.IgnoreTime(), testutil.IgnoreFields("response_time"))
+}
+
+func TestGatherA2S(t *testing.T) {
+	challenge := []byte{0x12, 0x34, 0x56, 0x78}
+	addr := startUDPServer(t, func(req []byte) []byte {
+		header := []byte{0xff, 0xff, 0xff, 0xff}
+		switch {
+		case string(req) == string(a2sInfoRequest):
+			return append(append(header, a2sChallenge), challenge...)
+		case string(req) == string(a2sInfoRequest)+string(challenge):
+			resp := append(header, a2sInfo, 17)
+			resp = append(resp, []byte("Fleet Server 02\x00de_inferno\x00csgo\x00Counter-Strike: Global Offensive\x00")...)
+			resp = binary.LittleEndian.AppendUint16(resp, 730)
+			resp = append(resp, 3, 24, 1, 'd', 'l', 0, 1)
+			return append(resp, []byte("1.38.8.1\x00")...)
+		case string(req) == string(a2sPlayerQuery)+"\xff\xff\xff\xff":
+			return append(append(header, a2sChallenge), challenge...)
+		case string(req) == string(a2sPlayerQuery)+string(challenge):
+			resp := append(header, a2sPlayer, 3)
+			for _, p := range []struct {
+				name     string
+				score    int32
+				duration float32
+			}{{"alice", 12, 723.5}, {"", 0, 1.5}, {"bob", -1, 60}} {
+				resp = append(resp, 0)
+				resp = append(resp, []byte(p.name+"\x00")...)
+				resp = binary.LittleEndian.AppendUint32(resp, uint32(p.score))
+				resp = binary.LittleEndian.AppendUint32(resp, math.Float32bits(p.duration))
+			}
+			return resp
+		}
+		return nil
+	})
+
+	plugin := &GameServer{
+		Timeout: config.Duration(5 * time.Second),
+		Servers: []*server{{Address: addr, Protocol: "a2s", Tags: map[string]string{"customer": "acme"}}},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"game_server",
+			map[string]string{"server": addr, "protocol": "a2s", "customer": "acme"},
+			map[string]interface{}{
+				"name":           "Fleet Server 02",
+				"game":           "Counter-Strike: Global Offensive",
+				"map":            "de_inferno",
+				"version":        "1.38.8.1",
+				"players_online": 2,
+				"players_max":    24,
+				"bots":           1,
+				"response_time":  0.0,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"game_server_player",
+			map[string]string{"server": addr, "protocol": "a2s", "customer": "acme", "player": "alice"},
+			map[string]interface{}{"score": int64(12), "duration": 723.5},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"game_server_player",
+			map[string]string{"server": addr, "protocol": "a2s", "customer": "acme", "player": "bob"},
+			map[string]interface{}{"score": int64(-1), "duration": 60.0},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(),
+		testutil.IgnoreTime(), testutil.SortMetrics(), testutil.IgnoreFields("response_time"))
+}
+
+func TestGatherUnreachable(t *testing.T) {
+	// Reserve an address without a server to get a timeout
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := conn.LocalAddr().String()
+	require.NoError(t, conn.Close())
+
+	plugin := &GameServer{
+		Timeout: config.Duration(100 * time.Millisecond),
+		Servers: []*server{{Address: addr, Protocol: "a2s"}},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "querying \""+addr+"\" failed")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+// startUDPServer starts a server answering each request with the response
+// of the given handler, ignoring requests with a nil response
+func startUDPServer(t *testing.T, handler func(req []byte) []byte) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if resp := handler(buf[:n]); resp != nil {
+				_, _ = conn.WriteTo(resp, addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
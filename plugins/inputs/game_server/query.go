@@ -0,0 +1,122 @@
+package game_server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Packet types of the GameSpy4 query protocol
+const (
+	queryHandshake byte = 0x09
+	queryStat      byte = 0x00
+)
+
+var (
+	queryMagic        = []byte{0xfe, 0xfd}
+	queryStatPadding  = []byte("splitnum\x00\x80\x00")
+	queryPlayerMarker = []byte("\x00\x00\x01player_\x00\x00")
+)
+
+type queryClient struct{}
+
+func (*queryClient) query(address string, timeout time.Duration) (*serverStatus, error) {
+	conn, err := net.DialTimeout("udp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	//nolint:gosec // G404: The session ID is not security-relevant
+	session := rand.Uint32() & 0x0f0f0f0f
+
+	// Request a challenge token for the session
+	resp, err := queryExchange(conn, queryHandshake, session, nil)
+	if err != nil {
+		return nil, fmt.Errorf("handshake failed: %w", err)
+	}
+	token, err := strconv.ParseInt(string(bytes.TrimRight(resp, "\x00")), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid challenge token: %w", err)
+	}
+
+	// Request the full statistics using the token
+	payload := make([]byte, 8)
+	binary.BigEndian.PutUint32(payload, uint32(token))
+	resp, err = queryExchange(conn, queryStat, session, payload)
+	if err != nil {
+		return nil, fmt.Errorf("requesting statistics failed: %w", err)
+	}
+
+	return parseQueryStat(resp)
+}
+
+// queryExchange sends a request of the given type and returns the payload of
+// the response after checking the type and session
+func queryExchange(conn net.Conn, kind byte, session uint32, payload []byte) ([]byte, error) {
+	req := make([]byte, 0, 7+len(payload))
+	req = append(req, queryMagic...)
+	req = append(req, kind)
+	req = binary.BigEndian.AppendUint32(req, session)
+	req = append(req, payload...)
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 65535)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	if n < 5 || buf[0] != kind || binary.BigEndian.Uint32(buf[1:5]) != session {
+		return nil, errors.New("unexpected response")
+	}
+
+	return buf[5:n], nil
+}
+
+// parseQueryStat parses the payload of a full statistics response consisting
+// of null-terminated key-value pairs followed by the list of player names
+func parseQueryStat(data []byte) (*serverStatus, error) {
+	data = bytes.TrimPrefix(data, queryStatPadding)
+	info, names, found := bytes.Cut(data, queryPlayerMarker)
+	if !found {
+		return nil, errors.New("missing player section")
+	}
+
+	values := make(map[string]string)
+	parts := bytes.Split(info, []byte{0})
+	for i := 0; i+1 < len(parts); i += 2 {
+		values[string(parts[i])] = string(parts[i+1])
+	}
+
+	status := &serverStatus{
+		name:    values["hostname"],
+		game:    values["game_id"],
+		mapName: values["map"],
+		version: values["version"],
+	}
+	var err error
+	if status.online, err = strconv.Atoi(values["numplayers"]); err != nil {
+		return nil, fmt.Errorf("invalid number of players: %w", err)
+	}
+	if status.max, err = strconv.Atoi(values["maxplayers"]); err != nil {
+		return nil, fmt.Errorf("invalid maximum number of players: %w", err)
+	}
+
+	for _, name := range bytes.Split(names, []byte{0}) {
+		if len(name) > 0 {
+			status.players = append(status.players, player{name: string(name)})
+		}
+	}
+
+	return status, nil
+}
@@ -0,0 +1,172 @@
+package game_server
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorcon/rcon"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+var (
+	// Player counts of the Source "status" command for e.g. CS:GO and older
+	// engine versions
+	sourcePlayersRe       = regexp.MustCompile(`^(\d+) humans?, (\d+) bots? \((\d+)/\d+ max\)`)
+	sourcePlayersLegacyRe = regexp.MustCompile(`^(\d+) \((\d+) max\)`)
+
+	// Player lines of the Source "status" command with the columns
+	//   # userid [slot] name uniqueid connected ping loss state ...
+	// Bots do not have a connection time and are not matched.
+	sourcePlayerRe = regexp.MustCompile(`^#\s*\d+\s+(?:\d+\s+)?"(.*)"\s+\S+\s+(\d+(?::\d+){1,2})\s+(\d+)\s+(\d+)\s+\w+`)
+
+	// Response of the Minecraft "list" command for versions >= 1.13 and older
+	minecraftListRe = regexp.MustCompile(`(?s)There are (\d+)(?: of a max(?: of)? |/)(\d+) players online:(.*)`)
+)
+
+type rconClient struct {
+	password config.Secret
+	game     string
+}
+
+func (c *rconClient) query(address string, timeout time.Duration) (*serverStatus, error) {
+	password, err := c.password.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting password failed: %w", err)
+	}
+	conn, err := rcon.Dial(address, password.String(), rcon.SetDialTimeout(timeout), rcon.SetDeadline(timeout))
+	password.Destroy()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if c.game == "minecraft" {
+		resp, err := conn.Execute("list")
+		if err != nil {
+			return nil, err
+		}
+		return parseMinecraftList(resp)
+	}
+
+	resp, err := conn.Execute("status")
+	if err != nil {
+		return nil, err
+	}
+	return parseSourceStatus(resp)
+}
+
+// parseSourceStatus parses the response of the "status" command of Source
+// engine servers
+func parseSourceStatus(resp string) (*serverStatus, error) {
+	status := &serverStatus{}
+	var foundPlayers bool
+
+	scanner := bufio.NewScanner(strings.NewReader(resp))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			m := sourcePlayerRe.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			duration, err := parseConnected(m[2])
+			if err != nil {
+				continue
+			}
+			ping, err := strconv.ParseInt(m[3], 10, 64)
+			if err != nil {
+				continue
+			}
+			loss, err := strconv.ParseInt(m[4], 10, 64)
+			if err != nil {
+				continue
+			}
+			status.players = append(status.players, player{
+				name: m[1],
+				fields: map[string]interface{}{
+					"duration": duration.Seconds(),
+					"ping":     ping,
+					"loss":     loss,
+				},
+			})
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "hostname":
+			status.name = value
+		case "version":
+			if parts := strings.Fields(value); len(parts) > 0 {
+				status.version = parts[0]
+			}
+		case "map":
+			if parts := strings.Fields(value); len(parts) > 0 {
+				status.mapName = parts[0]
+			}
+		case "players":
+			if m := sourcePlayersRe.FindStringSubmatch(value); m != nil {
+				status.online, _ = strconv.Atoi(m[1])
+				status.bots, _ = strconv.Atoi(m[2])
+				status.max, _ = strconv.Atoi(m[3])
+				foundPlayers = true
+			} else if m := sourcePlayersLegacyRe.FindStringSubmatch(value); m != nil {
+				status.online, _ = strconv.Atoi(m[1])
+				status.max, _ = strconv.Atoi(m[2])
+				foundPlayers = true
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if !foundPlayers {
+		return nil, fmt.Errorf("no player information in response %q", resp)
+	}
+
+	return status, nil
+}
+
+// parseConnected parses the connection time of a player in the form of
+// "mm:ss" or "hh:mm:ss"
+func parseConnected(value string) (time.Duration, error) {
+	var seconds int64
+	for _, part := range strings.Split(value, ":") {
+		v, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		seconds = seconds*60 + v
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// parseMinecraftList parses the response of the "list" command of Minecraft
+// servers
+func parseMinecraftList(resp string) (*serverStatus, error) {
+	m := minecraftListRe.FindStringSubmatch(resp)
+	if m == nil {
+		return nil, fmt.Errorf("unexpected response %q", resp)
+	}
+
+	status := &serverStatus{game: "minecraft"}
+	status.online, _ = strconv.Atoi(m[1])
+	status.max, _ = strconv.Atoi(m[2])
+	for _, name := range strings.Split(m[3], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			status.players = append(status.players, player{name: name})
+		}
+	}
+
+	return status, nil
+}
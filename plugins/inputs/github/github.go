@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -29,8 +30,11 @@ type GitHub struct {
 	AdditionalFields  []string        `toml:"additional_fields"`
 	EnterpriseBaseURL string          `toml:"enterprise_base_url"`
 	HTTPTimeout       config.Duration `toml:"http_timeout"`
+	GraphQLBatchSize  int             `toml:"graphql_batch_size"`
+	PullRequestSample int             `toml:"pull_request_sample"`
 
 	githubClient    *github.Client
+	httpClient      *http.Client
 	obfuscatedToken string
 
 	rateLimit       selfstat.Stat
@@ -99,6 +103,19 @@ func (g *GitHub) Gather(acc telegraf.Accumulator) error {
 					for k, v := range addFields {
 						fields[k] = v
 					}
+				case "runners":
+					// Self-hosted runner and workflow queue properties
+					addFields, err := g.getRunnerFields(ctx, owner, repository)
+					if err != nil {
+						acc.AddError(err)
+						continue
+					}
+
+					for k, v := range addFields {
+						fields[k] = v
+					}
+				case "pull-request-stats":
+					// Queried in batches for all repositories below
 				default:
 					acc.AddError(fmt.Errorf("unknown additional field %q", field))
 					continue
@@ -110,6 +127,11 @@ func (g *GitHub) Gather(acc telegraf.Accumulator) error {
 	}
 
 	wg.Wait()
+
+	if slices.Contains(g.AdditionalFields, "pull-request-stats") {
+		g.gatherPullRequestStats(ctx, acc)
+	}
+
 	return nil
 }
 
@@ -132,9 +154,11 @@ func (g *GitHub) createGitHubClient(ctx context.Context) (*github.Client, error)
 
 		g.obfuscatedToken = g.AccessToken[0:4] + "..." + g.AccessToken[len(g.AccessToken)-3:]
 
+		g.httpClient = oauthClient
 		return g.newGithubClient(oauthClient)
 	}
 
+	g.httpClient = httpClient
 	return g.newGithubClient(httpClient)
 }
 
@@ -223,7 +247,9 @@ func (g *GitHub) getPullRequestFields(ctx context.Context, owner, repo string) (
 func init() {
 	inputs.Add("github", func() telegraf.Input {
 		return &GitHub{
-			HTTPTimeout: config.Duration(time.Second * 5),
+			HTTPTimeout:       config.Duration(time.Second * 5),
+			GraphQLBatchSize:  25,
+			PullRequestSample: 50,
 		}
 	})
 }
@@ -1,11 +1,19 @@
 package github
 
 import (
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	gh "github.com/google/go-github/v32/github"
 	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
 )
 
 func TestNewGithubClient(t *testing.T) {
@@ -138,3 +146,113 @@ func TestGetFields(t *testing.T) {
 
 	require.Equal(t, getFieldsReturn, correctFieldReturn)
 }
+
+func TestGatherRunnersAndPullRequestStats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/repos/influxdata/telegraf":
+			_, _ = w.Write([]byte(`{"name":"telegraf","owner":{"login":"influxdata"},"language":"Go","stargazers_count":10}`))
+		case "/api/v3/repos/influxdata/telegraf/actions/runners":
+			_, _ = w.Write([]byte(`{"total_count":3,"runners":[
+				{"name":"r1","status":"online","busy":true},
+				{"name":"r2","status":"online","busy":false},
+				{"name":"r3","status":"offline","busy":false}
+			]}`))
+		case "/api/v3/repos/influxdata/telegraf/actions/runs":
+			if r.URL.Query().Get("status") == "queued" {
+				_, _ = w.Write([]byte(`{"total_count":4,"workflow_runs":[]}`))
+			} else {
+				_, _ = w.Write([]byte(`{"total_count":1,"workflow_runs":[]}`))
+			}
+		case "/api/graphql":
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if !strings.Contains(string(body), `r0: repository(owner: \"influxdata\", name: \"telegraf\")`) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{
+				"rateLimit":{"limit":5000,"cost":1,"remaining":4999,"resetAt":"2024-01-01T01:00:00Z"},
+				"r0":{"owner":{"login":"influxdata"},"name":"telegraf","openPullRequests":{"totalCount":7},
+					"mergedPullRequests":{"nodes":[
+						{"createdAt":"2024-01-01T00:00:00Z","mergedAt":"2024-01-01T02:00:00Z","reviews":{"nodes":[{"submittedAt":"2024-01-01T00:30:00Z"}]}},
+						{"createdAt":"2024-01-01T00:00:00Z","mergedAt":"2024-01-01T04:00:00Z","reviews":{"nodes":[]}}
+					]}}
+			},
+			"errors":[
+				{"message":"Something went wrong","path":["r0","mergedPullRequests","nodes",1,"reviews"]}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	plugin := &GitHub{
+		Repositories:      []string{"influxdata/telegraf"},
+		AdditionalFields:  []string{"runners", "pull-request-stats"},
+		EnterpriseBaseURL: ts.URL + "/",
+		GraphQLBatchSize:  25,
+		PullRequestSample: 50,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.EqualError(t, acc.Errors[0], "querying r0.mergedPullRequests.nodes.1.reviews failed: Something went wrong")
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"github_repository",
+			map[string]string{"owner": "influxdata", "name": "telegraf", "language": "Go", "license": "None"},
+			map[string]interface{}{
+				"stars":                     10,
+				"subscribers":               0,
+				"watchers":                  0,
+				"networks":                  0,
+				"forks":                     0,
+				"open_issues":               0,
+				"size":                      0,
+				"runners_online":            2,
+				"runners_offline":           1,
+				"runners_busy":              1,
+				"runners_idle":              1,
+				"workflow_runs_queued":      4,
+				"workflow_runs_in_progress": 1,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"github_pull_requests",
+			map[string]string{"owner": "influxdata", "name": "telegraf"},
+			map[string]interface{}{
+				"open":                          7,
+				"merged":                        2,
+				"reviewed":                      1,
+				"cycle_time_mean_seconds":       float64(3 * 3600),
+				"cycle_time_median_seconds":     float64(3 * 3600),
+				"cycle_time_max_seconds":        float64(4 * 3600),
+				"review_latency_mean_seconds":   float64(1800),
+				"review_latency_median_seconds": float64(1800),
+				"review_latency_max_seconds":    float64(1800),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"github_rate_limit",
+			map[string]string{"api": "graphql"},
+			map[string]interface{}{
+				"limit":     5000,
+				"cost":      1,
+				"remaining": 4999,
+				"reset":     int64(1704070800),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
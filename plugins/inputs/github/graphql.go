@@ -0,0 +1,247 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+type graphQLRequest struct {
+	Query string `json:"query"`
+}
+
+type graphQLResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string        `json:"message"`
+		Path    []interface{} `json:"path"`
+	} `json:"errors"`
+}
+
+type graphQLRateLimit struct {
+	Limit     int       `json:"limit"`
+	Cost      int       `json:"cost"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+type graphQLRepository struct {
+	Owner struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	Name             string `json:"name"`
+	OpenPullRequests struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"openPullRequests"`
+	MergedPullRequests struct {
+		Nodes []struct {
+			CreatedAt time.Time `json:"createdAt"`
+			MergedAt  time.Time `json:"mergedAt"`
+			Reviews   struct {
+				Nodes []struct {
+					SubmittedAt time.Time `json:"submittedAt"`
+				} `json:"nodes"`
+			} `json:"reviews"`
+		} `json:"nodes"`
+	} `json:"mergedPullRequests"`
+}
+
+// graphQLURL returns the GraphQL endpoint corresponding to the REST API
+// endpoint of the client, e.g. "https://github.example.com/api/graphql" for
+// the enterprise endpoint "https://github.example.com/api/v3/".
+func (g *GitHub) graphQLURL() string {
+	if g.EnterpriseBaseURL == "" {
+		return "https://api.github.com/graphql"
+	}
+	base := g.githubClient.BaseURL.String()
+	return strings.TrimSuffix(base, "v3/") + "graphql"
+}
+
+// gatherPullRequestStats queries the pull-request statistics of the
+// repositories in batches, one GraphQL query per batch, and reports the
+// consumed rate-limit budget.
+func (g *GitHub) gatherPullRequestStats(ctx context.Context, acc telegraf.Accumulator) {
+	batchSize := g.GraphQLBatchSize
+	if batchSize <= 0 {
+		batchSize = len(g.Repositories)
+	}
+
+	for start := 0; start < len(g.Repositories); start += batchSize {
+		end := min(start+batchSize, len(g.Repositories))
+		if err := g.gatherPullRequestBatch(ctx, acc, g.Repositories[start:end]); err != nil {
+			acc.AddError(err)
+		}
+	}
+}
+
+func (g *GitHub) gatherPullRequestBatch(ctx context.Context, acc telegraf.Accumulator, repositories []string) error {
+	// The API limits the number of returned nodes to 100
+	sample := max(min(g.PullRequestSample, 100), 1)
+
+	var query strings.Builder
+	query.WriteString("query {\n  rateLimit { limit cost remaining resetAt }\n")
+	for i, repositoryName := range repositories {
+		owner, repository, err := splitRepositoryName(repositoryName)
+		if err != nil {
+			acc.AddError(err)
+			continue
+		}
+		o, err := json.Marshal(owner)
+		if err != nil {
+			return err
+		}
+		n, err := json.Marshal(repository)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&query, "  r%d: repository(owner: %s, name: %s) {\n", i, o, n)
+		query.WriteString("    owner { login }\n    name\n")
+		query.WriteString("    openPullRequests: pullRequests(states: OPEN) { totalCount }\n")
+		fmt.Fprintf(&query, "    mergedPullRequests: pullRequests(states: MERGED, first: %d, orderBy: {field: UPDATED_AT, direction: DESC}) {\n",
+			sample)
+		query.WriteString("      nodes { createdAt mergedAt reviews(first: 1) { nodes { submittedAt } } }\n")
+		query.WriteString("    }\n  }\n")
+	}
+	query.WriteString("}\n")
+
+	response, err := g.queryGraphQL(ctx, query.String())
+	if err != nil {
+		return err
+	}
+	for _, e := range response.Errors {
+		acc.AddError(fmt.Errorf("querying %s failed: %s", formatPath(e.Path), e.Message))
+	}
+
+	now := time.Now()
+	for alias, raw := range response.Data {
+		if alias == "rateLimit" {
+			var rl graphQLRateLimit
+			if err := json.Unmarshal(raw, &rl); err != nil {
+				return fmt.Errorf("decoding rate-limit failed: %w", err)
+			}
+			g.rateLimit.Set(int64(rl.Limit))
+			g.rateRemaining.Set(int64(rl.Remaining))
+			fields := map[string]interface{}{
+				"limit":     rl.Limit,
+				"cost":      rl.Cost,
+				"remaining": rl.Remaining,
+				"reset":     rl.ResetAt.Unix(),
+			}
+			acc.AddFields("github_rate_limit", fields, map[string]string{"api": "graphql"}, now)
+			continue
+		}
+
+		// Repositories not found or inaccessible are returned as null
+		if bytes.Equal(raw, []byte("null")) {
+			continue
+		}
+		var repo graphQLRepository
+		if err := json.Unmarshal(raw, &repo); err != nil {
+			return fmt.Errorf("decoding repository failed: %w", err)
+		}
+
+		cycleTimes := make([]float64, 0, len(repo.MergedPullRequests.Nodes))
+		reviewLatencies := make([]float64, 0, len(repo.MergedPullRequests.Nodes))
+		for _, pr := range repo.MergedPullRequests.Nodes {
+			cycleTimes = append(cycleTimes, pr.MergedAt.Sub(pr.CreatedAt).Seconds())
+			if len(pr.Reviews.Nodes) > 0 {
+				reviewLatencies = append(reviewLatencies, pr.Reviews.Nodes[0].SubmittedAt.Sub(pr.CreatedAt).Seconds())
+			}
+		}
+
+		fields := map[string]interface{}{
+			"open":     repo.OpenPullRequests.TotalCount,
+			"merged":   len(cycleTimes),
+			"reviewed": len(reviewLatencies),
+		}
+		addDurationStats(fields, "cycle_time", cycleTimes)
+		addDurationStats(fields, "review_latency", reviewLatencies)
+
+		tags := map[string]string{
+			"owner": repo.Owner.Login,
+			"name":  repo.Name,
+		}
+		acc.AddFields("github_pull_requests", fields, tags, now)
+	}
+
+	return nil
+}
+
+func (g *GitHub) queryGraphQL(ctx context.Context, query string) (*graphQLResponse, error) {
+	body, err := json.Marshal(graphQLRequest{Query: query})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.graphQLURL(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("querying GraphQL API failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+			g.rateLimitErrors.Incr(1)
+		}
+		return nil, fmt.Errorf("querying GraphQL API failed: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var response graphQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decoding GraphQL response failed: %w", err)
+	}
+	if response.Data == nil && len(response.Errors) > 0 {
+		return nil, errors.New(response.Errors[0].Message)
+	}
+
+	return &response, nil
+}
+
+// addDurationStats adds the mean, median and maximum of the given durations
+// in seconds to the fields
+func addDurationStats(fields map[string]interface{}, prefix string, durations []float64) {
+	if len(durations) == 0 {
+		return
+	}
+	sort.Float64s(durations)
+
+	var sum float64
+	for _, d := range durations {
+		sum += d
+	}
+
+	median := durations[len(durations)/2]
+	if len(durations)%2 == 0 {
+		median = (durations[len(durations)/2-1] + durations[len(durations)/2]) / 2
+	}
+
+	fields[prefix+"_mean_seconds"] = sum / float64(len(durations))
+	fields[prefix+"_median_seconds"] = median
+	fields[prefix+"_max_seconds"] = durations[len(durations)-1]
+}
+
+// formatPath joins the elements of a GraphQL error path, consisting of field
+// names and list indices, e.g. "r0.mergedPullRequests.nodes.3.reviews".
+func formatPath(path []interface{}) string {
+	parts := make([]string, 0, len(path))
+	for _, p := range path {
+		parts = append(parts, fmt.Sprintf("%v", p))
+	}
+	return strings.Join(parts, ".")
+}
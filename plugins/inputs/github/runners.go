@@ -0,0 +1,73 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// runner is a self-hosted runner including the "busy" flag not available in
+// the client library
+type runner struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Busy   bool   `json:"busy"`
+}
+
+type runnerList struct {
+	TotalCount int      `json:"total_count"`
+	Runners    []runner `json:"runners"`
+}
+
+// getRunnerFields collects the utilization of the self-hosted runners and the
+// number of queued and running workflow runs of the repository
+func (g *GitHub) getRunnerFields(ctx context.Context, owner, repo string) (map[string]interface{}, error) {
+	var online, offline, busy int
+	for page := 1; page > 0; {
+		req, err := g.githubClient.NewRequest("GET", fmt.Sprintf("repos/%s/%s/actions/runners?per_page=100&page=%d", owner, repo, page), nil)
+		if err != nil {
+			return nil, err
+		}
+		var runners runnerList
+		response, err := g.githubClient.Do(ctx, req, &runners)
+		g.handleRateLimit(response, err)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range runners.Runners {
+			if r.Status == "online" {
+				online++
+			} else {
+				offline++
+			}
+			if r.Busy {
+				busy++
+			}
+		}
+		page = response.NextPage
+	}
+
+	fields := map[string]interface{}{
+		"runners_online":  online,
+		"runners_offline": offline,
+		"runners_busy":    busy,
+		"runners_idle":    online - busy,
+	}
+
+	for _, status := range []string{"queued", "in_progress"} {
+		options := &github.ListWorkflowRunsOptions{
+			Status:      status,
+			ListOptions: github.ListOptions{PerPage: 1},
+		}
+		runs, response, err := g.githubClient.Actions.ListRepositoryWorkflowRuns(ctx, owner, repo, options)
+		g.handleRateLimit(response, err)
+		if err != nil {
+			return nil, err
+		}
+		fields["workflow_runs_"+status] = runs.GetTotalCount()
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,322 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type GitLab struct {
+	URL                string          `toml:"url"`
+	Token              config.Secret   `toml:"token"`
+	Projects           []string        `toml:"projects"`
+	Collect            []string        `toml:"collect"`
+	GraphQLBatchSize   int             `toml:"graphql_batch_size"`
+	MergeRequestSample int             `toml:"merge_request_sample"`
+	Log                telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client *http.Client
+}
+
+type graphQLResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type count struct {
+	Count int `json:"count"`
+}
+
+type project struct {
+	FullPath            string `json:"fullPath"`
+	OpenMergeRequests   *count `json:"openMergeRequests"`
+	MergedMergeRequests *struct {
+		Nodes []struct {
+			CreatedAt time.Time `json:"createdAt"`
+			MergedAt  time.Time `json:"mergedAt"`
+		} `json:"nodes"`
+	} `json:"mergedMergeRequests"`
+	PendingJobs *count `json:"pendingJobs"`
+	RunningJobs *count `json:"runningJobs"`
+	Runners     *struct {
+		Nodes []struct {
+			Status string `json:"status"`
+			Paused bool   `json:"paused"`
+		} `json:"nodes"`
+	} `json:"runners"`
+}
+
+func (*GitLab) SampleConfig() string {
+	return sampleConfig
+}
+
+func (g *GitLab) Init() error {
+	if g.URL == "" {
+		g.URL = "https://gitlab.com"
+	}
+	g.URL = strings.TrimSuffix(g.URL, "/")
+
+	if len(g.Projects) == 0 {
+		return errors.New("no projects specified")
+	}
+
+	if len(g.Collect) == 0 {
+		g.Collect = []string{"merge_requests", "jobs", "runners"}
+	}
+	for _, c := range g.Collect {
+		switch c {
+		case "merge_requests", "jobs", "runners":
+		default:
+			return fmt.Errorf("invalid 'collect' option %q", c)
+		}
+	}
+
+	if g.GraphQLBatchSize <= 0 {
+		return errors.New("'graphql_batch_size' has to be larger than zero")
+	}
+	if g.MergeRequestSample < 1 || g.MergeRequestSample > 100 {
+		return errors.New("'merge_request_sample' has to be between 1 and 100")
+	}
+
+	return nil
+}
+
+func (g *GitLab) Start(telegraf.Accumulator) error {
+	client, err := g.HTTPClientConfig.CreateClient(context.Background(), g.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	g.client = client
+	return nil
+}
+
+func (g *GitLab) Gather(acc telegraf.Accumulator) error {
+	for start := 0; start < len(g.Projects); start += g.GraphQLBatchSize {
+		end := min(start+g.GraphQLBatchSize, len(g.Projects))
+		if err := g.gatherBatch(acc, g.Projects[start:end]); err != nil {
+			acc.AddError(err)
+		}
+	}
+	return nil
+}
+
+func (g *GitLab) Stop() {
+	if g.client != nil {
+		g.client.CloseIdleConnections()
+	}
+}
+
+// buildQuery creates a single GraphQL query for all given projects using an
+// alias per project
+func (g *GitLab) buildQuery(projects []string) (string, error) {
+	var query strings.Builder
+	query.WriteString("query {\n")
+	for i, p := range projects {
+		fullPath, err := json.Marshal(p)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&query, "  p%d: project(fullPath: %s) {\n    fullPath\n", i, fullPath)
+		if slices.Contains(g.Collect, "merge_requests") {
+			query.WriteString("    openMergeRequests: mergeRequests(state: opened) { count }\n")
+			fmt.Fprintf(&query, "    mergedMergeRequests: mergeRequests(state: merged, first: %d, sort: MERGED_AT_DESC) {\n", g.MergeRequestSample)
+			query.WriteString("      nodes { createdAt mergedAt }\n    }\n")
+		}
+		if slices.Contains(g.Collect, "jobs") {
+			query.WriteString("    pendingJobs: jobs(statuses: [PENDING]) { count }\n")
+			query.WriteString("    runningJobs: jobs(statuses: [RUNNING]) { count }\n")
+		}
+		if slices.Contains(g.Collect, "runners") {
+			query.WriteString("    runners { nodes { status paused } }\n")
+		}
+		query.WriteString("  }\n")
+	}
+	query.WriteString("}\n")
+
+	return query.String(), nil
+}
+
+func (g *GitLab) gatherBatch(acc telegraf.Accumulator, projects []string) error {
+	query, err := g.buildQuery(projects)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{"query": query})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, g.URL+"/api/graphql", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if !g.Token.Empty() {
+		token, err := g.Token.Get()
+		if err != nil {
+			return fmt.Errorf("getting token failed: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.String())
+		token.Destroy()
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("querying GraphQL API failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	now := time.Now()
+	g.addRateLimit(acc, resp.Header, now)
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("querying GraphQL API failed: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var response graphQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("decoding GraphQL response failed: %w", err)
+	}
+	for _, e := range response.Errors {
+		acc.AddError(fmt.Errorf("querying GraphQL API failed: %s", e.Message))
+	}
+
+	for i, name := range projects {
+		raw, found := response.Data["p"+strconv.Itoa(i)]
+		if !found || bytes.Equal(raw, []byte("null")) {
+			acc.AddError(fmt.Errorf("project %q not found", name))
+			continue
+		}
+		var p project
+		if err := json.Unmarshal(raw, &p); err != nil {
+			acc.AddError(fmt.Errorf("decoding project %q failed: %w", name, err))
+			continue
+		}
+		addProject(acc, &p, now)
+	}
+
+	return nil
+}
+
+func addProject(acc telegraf.Accumulator, p *project, now time.Time) {
+	tags := map[string]string{"project": p.FullPath}
+
+	if p.OpenMergeRequests != nil && p.MergedMergeRequests != nil {
+		cycleTimes := make([]float64, 0, len(p.MergedMergeRequests.Nodes))
+		for _, mr := range p.MergedMergeRequests.Nodes {
+			cycleTimes = append(cycleTimes, mr.MergedAt.Sub(mr.CreatedAt).Seconds())
+		}
+		fields := map[string]interface{}{
+			"open":   p.OpenMergeRequests.Count,
+			"merged": len(cycleTimes),
+		}
+		addDurationStats(fields, "cycle_time", cycleTimes)
+		acc.AddFields("gitlab_merge_requests", fields, tags, now)
+	}
+
+	if p.PendingJobs != nil && p.RunningJobs != nil {
+		fields := map[string]interface{}{
+			"pending": p.PendingJobs.Count,
+			"running": p.RunningJobs.Count,
+		}
+		acc.AddFields("gitlab_jobs", fields, tags, now)
+	}
+
+	if p.Runners != nil {
+		fields := map[string]interface{}{
+			"online":          0,
+			"offline":         0,
+			"stale":           0,
+			"never_contacted": 0,
+			"paused":          0,
+		}
+		for _, r := range p.Runners.Nodes {
+			status := strings.ToLower(r.Status)
+			if _, found := fields[status]; found {
+				fields[status] = fields[status].(int) + 1
+			}
+			if r.Paused {
+				fields["paused"] = fields["paused"].(int) + 1
+			}
+		}
+		acc.AddFields("gitlab_runners", fields, tags, now)
+	}
+}
+
+// addRateLimit reports the rate-limit budget if the server sends the
+// corresponding headers
+func (g *GitLab) addRateLimit(acc telegraf.Accumulator, header http.Header, now time.Time) {
+	fields := make(map[string]interface{}, 3)
+	for name, field := range map[string]string{
+		"RateLimit-Limit":     "limit",
+		"RateLimit-Remaining": "remaining",
+		"RateLimit-Reset":     "reset",
+	} {
+		if v, err := strconv.ParseInt(header.Get(name), 10, 64); err == nil {
+			fields[field] = v
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+	acc.AddFields("gitlab_rate_limit", fields, map[string]string{"url": g.URL}, now)
+}
+
+// addDurationStats adds the mean, median and maximum of the given durations
+// in seconds to the fields
+func addDurationStats(fields map[string]interface{}, prefix string, durations []float64) {
+	if len(durations) == 0 {
+		return
+	}
+	sort.Float64s(durations)
+
+	var sum float64
+	for _, d := range durations {
+		sum += d
+	}
+
+	median := durations[len(durations)/2]
+	if len(durations)%2 == 0 {
+		median = (durations[len(durations)/2-1] + durations[len(durations)/2]) / 2
+	}
+
+	fields[prefix+"_mean_seconds"] = sum / float64(len(durations))
+	fields[prefix+"_median_seconds"] = median
+	fields[prefix+"_max_seconds"] = durations[len(durations)-1]
+}
+
+func init() {
+	inputs.Add("gitlab", func() telegraf.Input {
+		return &GitLab{
+			URL:                "https://gitlab.com",
+			GraphQLBatchSize:   25,
+			MergeRequestSample: 50,
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
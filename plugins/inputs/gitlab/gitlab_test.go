@@ -0,0 +1,160 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const response = `{"data":{
+  "p0":{"fullPath":"group/app",
+    "openMergeRequests":{"count":3},
+    "mergedMergeRequests":{"nodes":[
+      {"createdAt":"2024-01-01T00:00:00Z","mergedAt":"2024-01-01T01:00:00Z"},
+      {"createdAt":"2024-01-01T00:00:00Z","mergedAt":"2024-01-01T02:00:00Z"},
+      {"createdAt":"2024-01-01T00:00:00Z","mergedAt":"2024-01-01T06:00:00Z"}
+    ]},
+    "pendingJobs":{"count":5},
+    "runningJobs":{"count":2},
+    "runners":{"nodes":[
+      {"status":"ONLINE","paused":false},
+      {"status":"ONLINE","paused":true},
+      {"status":"OFFLINE","paused":false}
+    ]}
+  },
+  "p1":null
+}}`
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *GitLab
+		expected string
+	}{
+		{
+			name:     "no projects",
+			plugin:   &GitLab{GraphQLBatchSize: 1, MergeRequestSample: 1},
+			expected: "no projects specified",
+		},
+		{
+			name:     "invalid collect",
+			plugin:   &GitLab{Projects: []string{"a/b"}, Collect: []string{"issues"}, GraphQLBatchSize: 1, MergeRequestSample: 1},
+			expected: `invalid 'collect' option "issues"`,
+		},
+		{
+			name:     "zero batch size",
+			plugin:   &GitLab{Projects: []string{"a/b"}, MergeRequestSample: 1},
+			expected: "'graphql_batch_size' has to be larger than zero",
+		},
+		{
+			name:     "sample too large",
+			plugin:   &GitLab{Projects: []string{"a/b"}, GraphQLBatchSize: 1, MergeRequestSample: 500},
+			expected: "'merge_request_sample' has to be between 1 and 100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGather(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/graphql" || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var request map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// Both projects must be queried in a single request
+		if !strings.Contains(request["query"], `p0: project(fullPath: "group/app")`) ||
+			!strings.Contains(request["query"], `p1: project(fullPath: "group/missing")`) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("RateLimit-Limit", "2000")
+		w.Header().Set("RateLimit-Remaining", "1999")
+		w.Header().Set("RateLimit-Reset", "1704070800")
+		_, _ = w.Write([]byte(response))
+	}))
+	defer ts.Close()
+
+	plugin := &GitLab{
+		URL:                ts.URL,
+		Token:              config.NewSecret([]byte("secret")),
+		Projects:           []string{"group/app", "group/missing"},
+		GraphQLBatchSize:   25,
+		MergeRequestSample: 50,
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `project "group/missing" not found`)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"gitlab_merge_requests",
+			map[string]string{"project": "group/app"},
+			map[string]interface{}{
+				"open":                      3,
+				"merged":                    3,
+				"cycle_time_mean_seconds":   float64(3 * 3600),
+				"cycle_time_median_seconds": float64(2 * 3600),
+				"cycle_time_max_seconds":    float64(6 * 3600),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"gitlab_jobs",
+			map[string]string{"project": "group/app"},
+			map[string]interface{}{
+				"pending": 5,
+				"running": 2,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"gitlab_runners",
+			map[string]string{"project": "group/app"},
+			map[string]interface{}{
+				"online":          2,
+				"offline":         1,
+				"stale":           0,
+				"never_contacted": 0,
+				"paused":          1,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"gitlab_rate_limit",
+			map[string]string{"url": ts.URL},
+			map[string]interface{}{
+				"limit":     int64(2000),
+				"remaining": int64(1999),
+				"reset":     int64(1704070800),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
@@ -54,6 +54,8 @@ type GNMI struct {
 	Username                      config.Secret     `toml:"username"`
 	Password                      config.Secret     `toml:"password"`
 	Redial                        config.Duration   `toml:"redial"`
+	MaxRedial                     config.Duration   `toml:"max_redial"`
+	HeartbeatTimeout              config.Duration   `toml:"heartbeat_timeout"`
 	MaxMsgSize                    config.Size       `toml:"max_msg_size"`
 	Depth                         int32             `toml:"depth"`
 	Trace                         bool              `toml:"dump_responses"`
@@ -70,20 +72,22 @@ type GNMI struct {
 
 	// Internal state
 	internalAliases map[*pathInfo]string
+	dedupNames      map[string]bool
 	decoder         *yangmodel.Decoder
 	cancel          context.CancelFunc
 	wg              sync.WaitGroup
 }
 
 type subscription struct {
-	Name              string          `toml:"name"`
-	Origin            string          `toml:"origin"`
-	Path              string          `toml:"path"`
-	SubscriptionMode  string          `toml:"subscription_mode"`
-	SampleInterval    config.Duration `toml:"sample_interval"`
-	SuppressRedundant bool            `toml:"suppress_redundant"`
-	HeartbeatInterval config.Duration `toml:"heartbeat_interval"`
-	TagOnly           bool            `toml:"tag_only" deprecated:"1.25.0;1.35.0;please use 'tag_subscription's instead"`
+	Name               string          `toml:"name"`
+	Origin             string          `toml:"origin"`
+	Path               string          `toml:"path"`
+	SubscriptionMode   string          `toml:"subscription_mode"`
+	SampleInterval     config.Duration `toml:"sample_interval"`
+	SuppressRedundant  bool            `toml:"suppress_redundant"`
+	HeartbeatInterval  config.Duration `toml:"heartbeat_interval"`
+	SuppressDuplicates bool            `toml:"suppress_duplicates"`
+	TagOnly            bool            `toml:"tag_only" deprecated:"1.25.0;1.35.0;please use 'tag_subscription's instead"`
 
 	fullPath *gnmi.Path
 }
@@ -112,6 +116,12 @@ func (c *GNMI) Init() error {
 	if time.Duration(c.Redial) <= 0 {
 		return errors.New("redial duration must be positive")
 	}
+	if c.MaxRedial != 0 && c.MaxRedial < c.Redial {
+		return errors.New("max_redial must not be smaller than redial")
+	}
+	if c.HeartbeatTimeout < 0 {
+		return errors.New("heartbeat_timeout must not be negative")
+	}
 
 	// Check vendor_specific options configured by user
 	if err := choice.CheckSlice(c.VendorSpecific, supportedExtensions); err != nil {
@@ -132,6 +142,7 @@ func (c *GNMI) Init() error {
 
 	// Split the subscriptions into "normal" and "tag" subscription
 	// and prepare them.
+	c.dedupNames = make(map[string]bool)
 	for i := len(c.Subscriptions) - 1; i >= 0; i-- {
 		subscription := c.Subscriptions[i]
 
@@ -154,6 +165,12 @@ func (c *GNMI) Init() error {
 			c.Subscriptions = append(c.Subscriptions[:i], c.Subscriptions[i+1:]...)
 			continue
 		}
+		if subscription.SuppressDuplicates {
+			if !strings.EqualFold(subscription.SubscriptionMode, "on_change") {
+				return fmt.Errorf("'suppress_duplicates' requires 'on_change' mode for subscription %d", i+1)
+			}
+			c.dedupNames[subscription.Name] = true
+		}
 		if err := subscription.buildFullPath(c); err != nil {
 			return err
 		}
@@ -299,6 +316,9 @@ func (c *GNMI) Start(acc telegraf.Accumulator) error {
 				guessPathStrategy:             c.GuessPathStrategy,
 				decoder:                       c.decoder,
 				enforceFirstNamespaceAsOrigin: c.EnforceFirstNamespaceAsOrigin,
+				heartbeatTimeout:              time.Duration(c.HeartbeatTimeout),
+				dedupNames:                    c.dedupNames,
+				lastValues:                    make(map[uint64]*seriesValues),
 				log:                           c.Log,
 				ClientParameters: keepalive.ClientParameters{
 					Time:                time.Duration(c.KeepaliveTime),
@@ -306,14 +326,24 @@ func (c *GNMI) Start(acc telegraf.Accumulator) error {
 					PermitWithoutStream: false,
 				},
 			}
+			delay := time.Duration(c.Redial)
 			for ctx.Err() == nil {
 				if err := h.subscribeGNMI(ctx, acc, tlscfg, request); err != nil && ctx.Err() == nil {
 					acc.AddError(err)
 				}
 
+				// Start over with the initial delay if the device sent data,
+				// otherwise back off exponentially up to the maximum if set
+				if h.received {
+					delay = time.Duration(c.Redial)
+				}
+				c.Log.Debugf("Redialing %s in %s", addr, delay)
 				select {
 				case <-ctx.Done():
-				case <-time.After(time.Duration(c.Redial)):
+				case <-time.After(delay):
+				}
+				if c.MaxRedial > 0 {
+					delay = min(2*delay, time.Duration(c.MaxRedial))
 				}
 			}
 		}(addr)
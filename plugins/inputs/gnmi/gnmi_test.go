@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -1101,6 +1102,279 @@ func TestRedial(t *testing.T) {
 	wg.Wait()
 }
 
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *GNMI
+		expected string
+	}{
+		{
+			name: "max redial smaller than redial",
+			plugin: &GNMI{
+				Redial:    config.Duration(10 * time.Second),
+				MaxRedial: config.Duration(time.Second),
+			},
+			expected: "max_redial must not be smaller than redial",
+		},
+		{
+			name: "negative heartbeat timeout",
+			plugin: &GNMI{
+				Redial:           config.Duration(10 * time.Second),
+				HeartbeatTimeout: config.Duration(-time.Second),
+			},
+			expected: "heartbeat_timeout must not be negative",
+		},
+		{
+			name: "suppress duplicates for sample subscription",
+			plugin: &GNMI{
+				Redial: config.Duration(10 * time.Second),
+				Subscriptions: []subscription{
+					{
+						Name:               "alias",
+						Path:               "/model",
+						SubscriptionMode:   "sample",
+						SuppressDuplicates: true,
+					},
+				},
+			},
+			expected: "'suppress_duplicates' requires 'on_change' mode for subscription 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestSuppressDuplicates(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	plugin := &GNMI{
+		Log:       testutil.Logger{},
+		Addresses: []string{listener.Addr().String()},
+		Encoding:  "proto",
+		Redial:    config.Duration(10 * time.Millisecond),
+		Subscriptions: []subscription{
+			{
+				Name:               "alias",
+				Origin:             "type",
+				Path:               "/model",
+				SubscriptionMode:   "on_change",
+				SuppressDuplicates: true,
+			},
+		},
+	}
+
+	grpcServer := grpc.NewServer()
+	gnmiServer := &mockServer{
+		subscribeF: func(server gnmi.GNMI_SubscribeServer) error {
+			// Send the initial state twice, e.g. due to a heartbeat, and
+			// change one of the values afterwards
+			for range 2 {
+				notification := mockGNMINotification()
+				if err := server.Send(&gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: notification}}); err != nil {
+					return err
+				}
+			}
+			notification := mockGNMINotification()
+			notification.Update[1].Val = &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: "changed"}}
+			if err := server.Send(&gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: notification}}); err != nil {
+				return err
+			}
+			<-server.Context().Done()
+			return nil
+		},
+		grpcServer: grpcServer,
+	}
+	gnmi.RegisterGNMIServer(grpcServer, gnmiServer)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := grpcServer.Serve(listener); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start(&acc))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"alias",
+			map[string]string{
+				"path":   "type:/model",
+				"source": "127.0.0.1",
+				"foo":    "bar",
+				"name":   "str",
+				"uint64": "1234",
+			},
+			map[string]interface{}{
+				"some/path": int64(5678),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"alias",
+			map[string]string{
+				"path":   "type:/model",
+				"source": "127.0.0.1",
+				"foo":    "bar",
+			},
+			map[string]interface{}{
+				"other/path": "foobar",
+				"other/this": "that",
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"alias",
+			map[string]string{
+				"path":   "type:/model",
+				"source": "127.0.0.1",
+				"foo":    "bar",
+			},
+			map[string]interface{}{
+				"other/path": "changed",
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	acc.Wait(len(expected))
+	plugin.Stop()
+	grpcServer.Stop()
+	wg.Wait()
+
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestSuppressDuplicatesPruneOnResubscribe(t *testing.T) {
+	h := &handler{lastValues: make(map[uint64]*seriesValues)}
+	newMetric := func(name string) telegraf.Metric {
+		return testutil.MustMetric(name, map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	}
+
+	h.pruneLastValues()
+	require.True(t, h.removeDuplicates(newMetric("foo")))
+	require.True(t, h.removeDuplicates(newMetric("bar")))
+	require.Len(t, h.lastValues, 2)
+
+	// Series resent after resubscribing are kept and still deduplicated
+	h.pruneLastValues()
+	require.False(t, h.removeDuplicates(newMetric("foo")))
+	require.Len(t, h.lastValues, 2)
+
+	// Series not received in the previous session are dropped
+	h.pruneLastValues()
+	require.Len(t, h.lastValues, 1)
+	require.True(t, h.removeDuplicates(newMetric("bar")))
+}
+
+func TestHeartbeatTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	plugin := &GNMI{
+		Log:              testutil.Logger{},
+		Addresses:        []string{listener.Addr().String()},
+		Encoding:         "proto",
+		Redial:           config.Duration(10 * time.Millisecond),
+		HeartbeatTimeout: config.Duration(100 * time.Millisecond),
+		Aliases:          map[string]string{"dummy": "type:/model"},
+	}
+
+	// Send an update and stay silent afterwards without closing the stream
+	grpcServer := grpc.NewServer()
+	gnmiServer := &mockServer{
+		subscribeF: func(server gnmi.GNMI_SubscribeServer) error {
+			notification := mockGNMINotification()
+			if err := server.Send(&gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: notification}}); err != nil {
+				return err
+			}
+			<-server.Context().Done()
+			return nil
+		},
+		grpcServer: grpcServer,
+	}
+	gnmi.RegisterGNMIServer(grpcServer, gnmiServer)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := grpcServer.Serve(listener); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start(&acc))
+
+	// The plugin must resubscribe after the timeout
+	acc.WaitError(1)
+	acc.Wait(4)
+	plugin.Stop()
+	grpcServer.Stop()
+	wg.Wait()
+
+	require.ErrorContains(t, acc.FirstError(), "within 100ms")
+}
+
+func TestRedialBackoff(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	plugin := &GNMI{
+		Log:       testutil.Logger{},
+		Addresses: []string{listener.Addr().String()},
+		Encoding:  "proto",
+		Redial:    config.Duration(50 * time.Millisecond),
+		MaxRedial: config.Duration(time.Second),
+	}
+
+	var attempts atomic.Int32
+	grpcServer := grpc.NewServer()
+	gnmiServer := &mockServer{
+		subscribeF: func(gnmi.GNMI_SubscribeServer) error {
+			attempts.Add(1)
+			return errors.New("testerror")
+		},
+		grpcServer: grpcServer,
+	}
+	gnmi.RegisterGNMIServer(grpcServer, gnmiServer)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := grpcServer.Serve(listener); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start(&acc))
+
+	// With a fixed delay, the device would be dialed 16 times within 800ms
+	// while backing off results in dialing after 0, 50, 150, 350 and 750ms
+	time.Sleep(800 * time.Millisecond)
+	plugin.Stop()
+	grpcServer.Stop()
+	wg.Wait()
+
+	require.LessOrEqual(t, attempts.Load(), int32(6))
+	require.GreaterOrEqual(t, attempts.Load(), int32(2))
+}
+
 func TestCases(t *testing.T) {
 	// Get all testcase directories
 	folders, err := os.ReadDir("testcases")
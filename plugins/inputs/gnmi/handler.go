@@ -11,6 +11,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
@@ -32,6 +33,13 @@ import (
 
 const eidJuniperTelemetryHeader = 1
 
+// seriesValues holds the last received field values of a series and the
+// subscription session the series was last received in
+type seriesValues struct {
+	fields  map[string]interface{}
+	session uint64
+}
+
 type handler struct {
 	host                          string
 	port                          string
@@ -48,6 +56,11 @@ type handler struct {
 	guessPathStrategy             string
 	decoder                       *yangmodel.Decoder
 	enforceFirstNamespaceAsOrigin bool
+	heartbeatTimeout              time.Duration
+	dedupNames                    map[string]bool
+	lastValues                    map[uint64]*seriesValues
+	session                       uint64
+	received                      bool
 	log                           telegraf.Logger
 	keepalive.ClientParameters
 }
@@ -87,7 +100,24 @@ func (h *handler) subscribeGNMI(ctx context.Context, acc telegraf.Accumulator, t
 	}
 	defer client.Close()
 
-	subscribeClient, err := gnmi.NewGNMIClient(client).Subscribe(ctx)
+	h.pruneLastValues()
+
+	// Abort the subscription if the device stays silent for too long, e.g.
+	// because it rebooted without closing the connection
+	h.received = false
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var timer *time.Timer
+	var timedOut atomic.Bool
+	if h.heartbeatTimeout > 0 {
+		timer = time.AfterFunc(h.heartbeatTimeout, func() {
+			timedOut.Store(true)
+			cancel()
+		})
+		defer timer.Stop()
+	}
+
+	subscribeClient, err := gnmi.NewGNMIClient(client).Subscribe(streamCtx)
 	if err != nil {
 		return fmt.Errorf("failed to setup subscription: %w", err)
 	}
@@ -104,11 +134,18 @@ func (h *handler) subscribeGNMI(ctx context.Context, acc telegraf.Accumulator, t
 	for ctx.Err() == nil {
 		var reply *gnmi.SubscribeResponse
 		if reply, err = subscribeClient.Recv(); err != nil {
+			if timedOut.Load() {
+				return fmt.Errorf("no response from gNMI device %s within %s", address, h.heartbeatTimeout)
+			}
 			if !errors.Is(err, io.EOF) && ctx.Err() == nil {
 				return fmt.Errorf("aborted gNMI subscription: %w", err)
 			}
 			break
 		}
+		h.received = true
+		if timer != nil {
+			timer.Reset(h.heartbeatTimeout)
+		}
 
 		if h.trace {
 			buf, err := protojson.Marshal(reply)
@@ -308,10 +345,52 @@ func (h *handler) handleSubscribeResponseUpdate(acc telegraf.Accumulator, respon
 
 	// Add grouped measurements
 	for _, metricToAdd := range grouper.Metrics() {
+		if h.dedupNames[metricToAdd.Name()] && !h.removeDuplicates(metricToAdd) {
+			continue
+		}
 		acc.AddMetric(metricToAdd)
 	}
 }
 
+// Remove the fields where the value did not change since the last update of
+// the series, e.g. in heartbeats or when resyncing after reconnecting. Returns
+// false if no field is left.
+func (h *handler) removeDuplicates(m telegraf.Metric) bool {
+	id := m.HashID()
+	last, found := h.lastValues[id]
+	if !found {
+		last = &seriesValues{fields: make(map[string]interface{}, len(m.FieldList()))}
+		h.lastValues[id] = last
+	}
+	last.session = h.session
+
+	var unchanged []string
+	for _, field := range m.FieldList() {
+		if v, found := last.fields[field.Key]; found && v == field.Value {
+			unchanged = append(unchanged, field.Key)
+			continue
+		}
+		last.fields[field.Key] = field.Value
+	}
+	for _, key := range unchanged {
+		m.RemoveField(key)
+	}
+	return len(m.FieldList()) > 0
+}
+
+// Forget the values of series not received during the last subscription
+// before starting a new one. The device resends the whole state when
+// subscribing, so series missing in the previous session are gone and would
+// otherwise be kept forever.
+func (h *handler) pruneLastValues() {
+	for id, last := range h.lastValues {
+		if last.session < h.session {
+			delete(h.lastValues, id)
+		}
+	}
+	h.session++
+}
+
 // Try to find the alias for the given path
 type aliasCandidate struct {
 	path, alias string
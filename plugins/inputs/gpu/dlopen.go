@@ -0,0 +1,41 @@
+//go:build linux && (amd64 || arm64)
+
+package gpu
+
+import (
+	"fmt"
+
+	"github.com/ebitengine/purego"
+)
+
+// loadLibrary opens the shared library at the given path and binds the
+// functions mapping symbol names to pointers of the Go function variables.
+// Loading does not require cgo.
+func loadLibrary(path string, functions map[string]interface{}) (uintptr, error) {
+	handle, err := purego.Dlopen(path, purego.RTLD_NOW|purego.RTLD_GLOBAL)
+	if err != nil {
+		return 0, err
+	}
+
+	for name, fptr := range functions {
+		sym, err := purego.Dlsym(handle, name)
+		if err != nil {
+			//nolint:errcheck // Ignore the error as we are already failing
+			purego.Dlclose(handle)
+			return 0, fmt.Errorf("looking up symbol %q failed: %w", name, err)
+		}
+		purego.RegisterFunc(fptr, sym)
+	}
+
+	return handle, nil
+}
+
+// cString converts the NULL-terminated buffer to a Go string
+func cString(buf []byte) string {
+	for i, c := range buf {
+		if c == 0 {
+			return string(buf[:i])
+		}
+	}
+	return string(buf)
+}
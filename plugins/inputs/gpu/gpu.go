@@ -0,0 +1,178 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux && (amd64 || arm64)
+
+package gpu
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var supportedVendors = []string{"nvidia", "amd"}
+
+type GPU struct {
+	Vendors        []string        `toml:"vendors"`
+	NVMLLibrary    string          `toml:"nvml_library"`
+	ROCmSMILibrary string          `toml:"rocm_smi_library"`
+	ProcessMetrics bool            `toml:"process_metrics"`
+	Log            telegraf.Logger `toml:"-"`
+
+	openLibrary func(vendor string) (library, error)
+	libraries   []library
+}
+
+// library provides the metrics of the devices managed by a vendor library
+type library interface {
+	vendor() string
+	devices(withProcesses bool) ([]device, error)
+	shutdown() error
+}
+
+type device struct {
+	index     int
+	name      string
+	uuid      string
+	fields    map[string]interface{}
+	processes []process
+}
+
+type process struct {
+	pid        uint32
+	memoryUsed uint64
+}
+
+func (*GPU) SampleConfig() string {
+	return sampleConfig
+}
+
+func (g *GPU) Init() error {
+	for _, vendor := range g.Vendors {
+		if !slices.Contains(supportedVendors, vendor) {
+			return fmt.Errorf("invalid vendor %q", vendor)
+		}
+	}
+
+	if g.openLibrary == nil {
+		g.openLibrary = g.open
+	}
+
+	return nil
+}
+
+func (g *GPU) Start(telegraf.Accumulator) error {
+	vendors := g.Vendors
+	if len(vendors) == 0 {
+		vendors = supportedVendors
+	}
+
+	for _, vendor := range vendors {
+		lib, err := g.openLibrary(vendor)
+		if err != nil {
+			// Only fail for explicitly requested vendors as we otherwise
+			// probe for all libraries
+			if len(g.Vendors) > 0 {
+				g.Stop()
+				return fmt.Errorf("loading library for vendor %q failed: %w", vendor, err)
+			}
+			g.Log.Debugf("Library for vendor %q not available: %v", vendor, err)
+			continue
+		}
+		g.libraries = append(g.libraries, lib)
+	}
+
+	if len(g.libraries) == 0 {
+		g.Log.Warn("No GPU library available, no metrics will be collected")
+	}
+
+	return nil
+}
+
+func (g *GPU) Gather(acc telegraf.Accumulator) error {
+	for _, lib := range g.libraries {
+		devices, err := lib.devices(g.ProcessMetrics)
+		if err != nil {
+			acc.AddError(fmt.Errorf("gathering %s devices failed: %w", lib.vendor(), err))
+			continue
+		}
+
+		for _, dev := range devices {
+			tags := map[string]string{
+				"vendor": lib.vendor(),
+				"index":  strconv.Itoa(dev.index),
+				"name":   dev.name,
+			}
+			if dev.uuid != "" {
+				tags["uuid"] = dev.uuid
+			}
+			acc.AddFields("gpu", dev.fields, tags)
+
+			for _, proc := range dev.processes {
+				ptags := map[string]string{
+					"vendor": lib.vendor(),
+					"index":  strconv.Itoa(dev.index),
+					"pid":    strconv.FormatUint(uint64(proc.pid), 10),
+				}
+				if dev.uuid != "" {
+					ptags["uuid"] = dev.uuid
+				}
+				if command := processCommand(proc.pid); command != "" {
+					ptags["command"] = command
+				}
+				acc.AddFields("gpu_process", map[string]interface{}{"memory_used": proc.memoryUsed}, ptags)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g *GPU) Stop() {
+	for _, lib := range g.libraries {
+		if err := lib.shutdown(); err != nil {
+			g.Log.Errorf("Shutting down %s library failed: %v", lib.vendor(), err)
+		}
+	}
+	g.libraries = nil
+}
+
+func (g *GPU) open(vendor string) (library, error) {
+	switch vendor {
+	case "nvidia":
+		return openNVML(g.NVMLLibrary)
+	case "amd":
+		return openROCmSMI(g.ROCmSMILibrary)
+	}
+	return nil, fmt.Errorf("invalid vendor %q", vendor)
+}
+
+// processCommand returns the command name of the process or an empty string
+// if the process is not accessible, e.g. running in another PID namespace
+func processCommand(pid uint32) string {
+	buf, err := os.ReadFile(filepath.Join(internal.GetProcPath(), strconv.FormatUint(uint64(pid), 10), "comm"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(buf))
+}
+
+func init() {
+	inputs.Add("gpu", func() telegraf.Input {
+		return &GPU{
+			NVMLLibrary:    "libnvidia-ml.so.1",
+			ROCmSMILibrary: "librocm_smi64.so",
+			ProcessMetrics: true,
+		}
+	})
+}
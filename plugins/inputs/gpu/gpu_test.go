@@ -0,0 +1,212 @@
+//go:build linux && (amd64 || arm64)
+
+package gpu
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type fakeLibrary struct {
+	name   string
+	devs   []device
+	err    error
+	closed bool
+}
+
+func (l *fakeLibrary) vendor() string {
+	return l.name
+}
+
+func (l *fakeLibrary) devices(withProcesses bool) ([]device, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	devs := make([]device, 0, len(l.devs))
+	for _, dev := range l.devs {
+		if !withProcesses {
+			dev.processes = nil
+		}
+		devs = append(devs, dev)
+	}
+	return devs, nil
+}
+
+func (l *fakeLibrary) shutdown() error {
+	l.closed = true
+	return nil
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &GPU{
+		Vendors: []string{"nvidia", "intel"},
+		Log:     &testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), `invalid vendor "intel"`)
+}
+
+func TestStartMissingLibrary(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "libmissing.so")
+
+	// Probing all vendors must not fail if no library is available
+	plugin := &GPU{
+		NVMLLibrary:    missing,
+		ROCmSMILibrary: missing,
+		Log:            &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start(nil))
+	require.Empty(t, plugin.libraries)
+	plugin.Stop()
+
+	// Explicitly requested vendors must fail
+	plugin = &GPU{
+		Vendors:     []string{"nvidia"},
+		NVMLLibrary: missing,
+		Log:         &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.ErrorContains(t, plugin.Start(nil), `loading library for vendor "nvidia" failed`)
+}
+
+func TestGather(t *testing.T) {
+	// Use a process that is guaranteed to exist to resolve the command
+	pid := uint32(os.Getpid())
+	buf, err := os.ReadFile("/proc/self/comm")
+	require.NoError(t, err)
+	command := strings.TrimSpace(string(buf))
+
+	nvidia := &fakeLibrary{
+		name: "nvidia",
+		devs: []device{
+			{
+				index: 0,
+				name:  "NVIDIA A100-SXM4-40GB",
+				uuid:  "GPU-9a6d4f3b-4c17-4d1f-a2c4-0b8a41f8b3f1",
+				fields: map[string]interface{}{
+					"utilization_gpu":    uint32(87),
+					"utilization_memory": uint32(42),
+					"memory_total":       uint64(42949672960),
+					"memory_used":        uint64(10737418240),
+					"memory_free":        uint64(32212254720),
+					"temperature":        float64(61),
+					"power_draw":         float64(247.5),
+					"clocks_graphics":    uint32(1410),
+					"clocks_sm":          uint32(1410),
+					"clocks_memory":      uint32(1215),
+				},
+				processes: []process{
+					{pid: pid, memoryUsed: 8589934592},
+				},
+			},
+		},
+	}
+	amd := &fakeLibrary{
+		name: "amd",
+		devs: []device{
+			{
+				index: 0,
+				name:  "Instinct MI210",
+				fields: map[string]interface{}{
+					"utilization_gpu":      uint32(12),
+					"temperature":          float64(38),
+					"temperature_junction": float64(41.5),
+				},
+			},
+		},
+	}
+
+	plugin := &GPU{
+		ProcessMetrics: true,
+		Log:            &testutil.Logger{},
+		openLibrary: func(vendor string) (library, error) {
+			switch vendor {
+			case "nvidia":
+				return nvidia, nil
+			case "amd":
+				return amd, nil
+			}
+			return nil, errors.New("unexpected vendor")
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Gather(&acc))
+	plugin.Stop()
+	require.True(t, nvidia.closed)
+	require.True(t, amd.closed)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"gpu",
+			map[string]string{
+				"vendor": "nvidia",
+				"index":  "0",
+				"name":   "NVIDIA A100-SXM4-40GB",
+				"uuid":   "GPU-9a6d4f3b-4c17-4d1f-a2c4-0b8a41f8b3f1",
+			},
+			nvidia.devs[0].fields,
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"gpu_process",
+			map[string]string{
+				"vendor":  "nvidia",
+				"index":   "0",
+				"uuid":    "GPU-9a6d4f3b-4c17-4d1f-a2c4-0b8a41f8b3f1",
+				"pid":     strconv.FormatUint(uint64(pid), 10),
+				"command": command,
+			},
+			map[string]interface{}{
+				"memory_used": uint64(8589934592),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"gpu",
+			map[string]string{
+				"vendor": "amd",
+				"index":  "0",
+				"name":   "Instinct MI210",
+			},
+			amd.devs[0].fields,
+			time.Unix(0, 0),
+		),
+	}
+
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherError(t *testing.T) {
+	plugin := &GPU{
+		Log: &testutil.Logger{},
+		openLibrary: func(vendor string) (library, error) {
+			if vendor == "nvidia" {
+				return &fakeLibrary{name: vendor, err: errors.New("GPU is lost")}, nil
+			}
+			return nil, errors.New("not available")
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "gathering nvidia devices failed: GPU is lost")
+}
@@ -0,0 +1,33 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux || !(amd64 || arm64)
+
+package gpu
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type GPU struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*GPU) SampleConfig() string { return sampleConfig }
+
+func (g *GPU) Init() error {
+	g.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*GPU) Gather(_ telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("gpu", func() telegraf.Input {
+		return &GPU{}
+	})
+}
@@ -0,0 +1,211 @@
+//go:build linux && (amd64 || arm64)
+
+package gpu
+
+import (
+	"fmt"
+
+	"github.com/ebitengine/purego"
+)
+
+// NVML return codes and constants, see nvml.h
+const (
+	nvmlSuccess          = 0
+	nvmlInsufficientSize = 7
+
+	nvmlDeviceNameBufferSize = 96
+	nvmlDeviceUUIDBufferSize = 80
+
+	nvmlTemperatureGPU = 0
+
+	nvmlClockGraphics = 0
+	nvmlClockSM       = 1
+	nvmlClockMem      = 2
+)
+
+type nvmlUtilization struct {
+	gpu    uint32
+	memory uint32
+}
+
+type nvmlMemory struct {
+	total uint64
+	free  uint64
+	used  uint64
+}
+
+// nvmlProcessInfo corresponds to nvmlProcessInfo_v2_t
+type nvmlProcessInfo struct {
+	pid           uint32
+	usedGPUMemory uint64
+	_             [2]uint32 // GPU and compute instance IDs
+}
+
+// nvml binds the functions of the NVIDIA Management Library
+type nvml struct {
+	handle uintptr
+
+	initV2                    func() int32
+	shutdownFn                func() int32
+	errorString               func(int32) string
+	deviceGetCount            func(*uint32) int32
+	deviceGetHandleByIndex    func(uint32, *uintptr) int32
+	deviceGetName             func(uintptr, *byte, uint32) int32
+	deviceGetUUID             func(uintptr, *byte, uint32) int32
+	deviceGetUtilizationRates func(uintptr, *nvmlUtilization) int32
+	deviceGetMemoryInfo       func(uintptr, *nvmlMemory) int32
+	deviceGetTemperature      func(uintptr, uint32, *uint32) int32
+	deviceGetPowerUsage       func(uintptr, *uint32) int32
+	deviceGetFanSpeed         func(uintptr, *uint32) int32
+	deviceGetClockInfo        func(uintptr, uint32, *uint32) int32
+	deviceGetComputeProcesses func(uintptr, *uint32, *nvmlProcessInfo) int32
+}
+
+func openNVML(path string) (library, error) {
+	l := &nvml{}
+	handle, err := loadLibrary(path, map[string]interface{}{
+		"nvmlInit_v2":                             &l.initV2,
+		"nvmlShutdown":                            &l.shutdownFn,
+		"nvmlErrorString":                         &l.errorString,
+		"nvmlDeviceGetCount_v2":                   &l.deviceGetCount,
+		"nvmlDeviceGetHandleByIndex_v2":           &l.deviceGetHandleByIndex,
+		"nvmlDeviceGetName":                       &l.deviceGetName,
+		"nvmlDeviceGetUUID":                       &l.deviceGetUUID,
+		"nvmlDeviceGetUtilizationRates":           &l.deviceGetUtilizationRates,
+		"nvmlDeviceGetMemoryInfo":                 &l.deviceGetMemoryInfo,
+		"nvmlDeviceGetTemperature":                &l.deviceGetTemperature,
+		"nvmlDeviceGetPowerUsage":                 &l.deviceGetPowerUsage,
+		"nvmlDeviceGetFanSpeed":                   &l.deviceGetFanSpeed,
+		"nvmlDeviceGetClockInfo":                  &l.deviceGetClockInfo,
+		"nvmlDeviceGetComputeRunningProcesses_v2": &l.deviceGetComputeProcesses,
+	})
+	if err != nil {
+		return nil, err
+	}
+	l.handle = handle
+
+	if ret := l.initV2(); ret != nvmlSuccess {
+		//nolint:errcheck // Ignore the error as we are already failing
+		purego.Dlclose(handle)
+		return nil, fmt.Errorf("initializing NVML failed: %w", l.error(ret))
+	}
+
+	return l, nil
+}
+
+func (*nvml) vendor() string {
+	return "nvidia"
+}
+
+func (l *nvml) error(ret int32) error {
+	return fmt.Errorf("%s (%d)", l.errorString(ret), ret)
+}
+
+func (l *nvml) devices(withProcesses bool) ([]device, error) {
+	var count uint32
+	if ret := l.deviceGetCount(&count); ret != nvmlSuccess {
+		return nil, fmt.Errorf("getting device count failed: %w", l.error(ret))
+	}
+
+	devices := make([]device, 0, count)
+	for i := uint32(0); i < count; i++ {
+		var h uintptr
+		if ret := l.deviceGetHandleByIndex(i, &h); ret != nvmlSuccess {
+			return nil, fmt.Errorf("getting handle of device %d failed: %w", i, l.error(ret))
+		}
+
+		dev := device{
+			index:  int(i),
+			fields: make(map[string]interface{}),
+		}
+
+		name := make([]byte, nvmlDeviceNameBufferSize)
+		if ret := l.deviceGetName(h, &name[0], uint32(len(name))); ret == nvmlSuccess {
+			dev.name = cString(name)
+		}
+		uuid := make([]byte, nvmlDeviceUUIDBufferSize)
+		if ret := l.deviceGetUUID(h, &uuid[0], uint32(len(uuid))); ret == nvmlSuccess {
+			dev.uuid = cString(uuid)
+		}
+
+		// Not all values are supported by all devices, e.g. data-center
+		// GPUs do not have fans, so skip the ones not available
+		var utilization nvmlUtilization
+		if ret := l.deviceGetUtilizationRates(h, &utilization); ret == nvmlSuccess {
+			dev.fields["utilization_gpu"] = utilization.gpu
+			dev.fields["utilization_memory"] = utilization.memory
+		}
+		var memory nvmlMemory
+		if ret := l.deviceGetMemoryInfo(h, &memory); ret == nvmlSuccess {
+			dev.fields["memory_total"] = memory.total
+			dev.fields["memory_used"] = memory.used
+			dev.fields["memory_free"] = memory.free
+		}
+		var value uint32
+		if ret := l.deviceGetTemperature(h, nvmlTemperatureGPU, &value); ret == nvmlSuccess {
+			dev.fields["temperature"] = float64(value)
+		}
+		if ret := l.deviceGetPowerUsage(h, &value); ret == nvmlSuccess {
+			dev.fields["power_draw"] = float64(value) / 1000.0
+		}
+		if ret := l.deviceGetFanSpeed(h, &value); ret == nvmlSuccess {
+			dev.fields["fan_speed"] = value
+		}
+		if ret := l.deviceGetClockInfo(h, nvmlClockGraphics, &value); ret == nvmlSuccess {
+			dev.fields["clocks_graphics"] = value
+		}
+		if ret := l.deviceGetClockInfo(h, nvmlClockSM, &value); ret == nvmlSuccess {
+			dev.fields["clocks_sm"] = value
+		}
+		if ret := l.deviceGetClockInfo(h, nvmlClockMem, &value); ret == nvmlSuccess {
+			dev.fields["clocks_memory"] = value
+		}
+
+		if withProcesses {
+			processes, err := l.processes(h)
+			if err != nil {
+				return nil, fmt.Errorf("getting processes of device %d failed: %w", i, err)
+			}
+			dev.processes = processes
+		}
+
+		devices = append(devices, dev)
+	}
+
+	return devices, nil
+}
+
+func (l *nvml) processes(h uintptr) ([]process, error) {
+	// Query the number of processes first and retry in case new processes
+	// started in between the calls
+	var count uint32
+	ret := l.deviceGetComputeProcesses(h, &count, nil)
+	for ret == nvmlInsufficientSize {
+		// Reserve some extra space for processes started after the query
+		count += 4
+		infos := make([]nvmlProcessInfo, count)
+		ret = l.deviceGetComputeProcesses(h, &count, &infos[0])
+		if ret == nvmlSuccess {
+			processes := make([]process, 0, count)
+			for _, info := range infos[:count] {
+				processes = append(processes, process{pid: info.pid, memoryUsed: info.usedGPUMemory})
+			}
+			return processes, nil
+		}
+	}
+	if ret != nvmlSuccess {
+		return nil, l.error(ret)
+	}
+	return nil, nil
+}
+
+func (l *nvml) shutdown() error {
+	ret := l.shutdownFn()
+	if err := purego.Dlclose(l.handle); err != nil {
+		return err
+	}
+	if ret != nvmlSuccess {
+		return fmt.Errorf("shutting down NVML failed: %d", ret)
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+//go:build linux && (amd64 || arm64)
+
+package gpu
+
+import (
+	"fmt"
+
+	"github.com/ebitengine/purego"
+)
+
+// ROCm SMI return codes and constants, see rocm_smi.h
+const (
+	rsmiSuccess          = 0
+	rsmiInsufficientSize = 15
+
+	rsmiNameBufferSize = 256
+
+	rsmiMemTypeVRAM = 0
+
+	rsmiTempTypeEdge     = 0
+	rsmiTempTypeJunction = 1
+	rsmiTempTypeMemory   = 2
+	rsmiTempCurrent      = 0
+)
+
+// rsmiProcessInfo corresponds to rsmi_process_info_t
+type rsmiProcessInfo struct {
+	processID uint32
+	_         uint32 // PASID
+	vramUsage uint64
+	_         uint64 // SDMA usage
+	_         uint32 // compute unit occupancy
+}
+
+// rocmSMI binds the functions of the AMD ROCm System Management Interface
+// library
+type rocmSMI struct {
+	handle uintptr
+
+	initFn                  func(uint64) int32
+	shutDown                func() int32
+	numMonitorDevices       func(*uint32) int32
+	devNameGet              func(uint32, *byte, uintptr) int32
+	devUniqueIDGet          func(uint32, *uint64) int32
+	devBusyPercentGet       func(uint32, *uint32) int32
+	devMemoryBusyPercentGet func(uint32, *uint32) int32
+	devMemoryTotalGet       func(uint32, uint32, *uint64) int32
+	devMemoryUsageGet       func(uint32, uint32, *uint64) int32
+	devTempMetricGet        func(uint32, uint32, uint32, *int64) int32
+	devPowerAveGet          func(uint32, uint32, *uint64) int32
+	devFanSpeedGet          func(uint32, uint32, *int64) int32
+	devFanSpeedMaxGet       func(uint32, uint32, *uint64) int32
+	computeProcessInfoGet   func(*rsmiProcessInfo, *uint32) int32
+	computeProcessGPUsGet   func(uint32, *uint32, *uint32) int32
+}
+
+func openROCmSMI(path string) (library, error) {
+	l := &rocmSMI{}
+	handle, err := loadLibrary(path, map[string]interface{}{
+		"rsmi_init":                        &l.initFn,
+		"rsmi_shut_down":                   &l.shutDown,
+		"rsmi_num_monitor_devices":         &l.numMonitorDevices,
+		"rsmi_dev_name_get":                &l.devNameGet,
+		"rsmi_dev_unique_id_get":           &l.devUniqueIDGet,
+		"rsmi_dev_busy_percent_get":        &l.devBusyPercentGet,
+		"rsmi_dev_memory_busy_percent_get": &l.devMemoryBusyPercentGet,
+		"rsmi_dev_memory_total_get":        &l.devMemoryTotalGet,
+		"rsmi_dev_memory_usage_get":        &l.devMemoryUsageGet,
+		"rsmi_dev_temp_metric_get":         &l.devTempMetricGet,
+		"rsmi_dev_power_ave_get":           &l.devPowerAveGet,
+		"rsmi_dev_fan_speed_get":           &l.devFanSpeedGet,
+		"rsmi_dev_fan_speed_max_get":       &l.devFanSpeedMaxGet,
+		"rsmi_compute_process_info_get":    &l.computeProcessInfoGet,
+		"rsmi_compute_process_gpus_get":    &l.computeProcessGPUsGet,
+	})
+	if err != nil {
+		return nil, err
+	}
+	l.handle = handle
+
+	if ret := l.initFn(0); ret != rsmiSuccess {
+		//nolint:errcheck // Ignore the error as we are already failing
+		purego.Dlclose(handle)
+		return nil, fmt.Errorf("initializing ROCm SMI failed: status %d", ret)
+	}
+
+	return l, nil
+}
+
+func (*rocmSMI) vendor() string {
+	return "amd"
+}
+
+func (l *rocmSMI) devices(withProcesses bool) ([]device, error) {
+	var count uint32
+	if ret := l.numMonitorDevices(&count); ret != rsmiSuccess {
+		return nil, fmt.Errorf("getting device count failed: status %d", ret)
+	}
+
+	devices := make([]device, 0, count)
+	for i := uint32(0); i < count; i++ {
+		dev := device{
+			index:  int(i),
+			fields: make(map[string]interface{}),
+		}
+
+		name := make([]byte, rsmiNameBufferSize)
+		if ret := l.devNameGet(i, &name[0], uintptr(len(name))); ret == rsmiSuccess {
+			dev.name = cString(name)
+		}
+		var id uint64
+		if ret := l.devUniqueIDGet(i, &id); ret == rsmiSuccess {
+			dev.uuid = fmt.Sprintf("0x%x", id)
+		}
+
+		// Not all values are supported by all devices so skip the ones not
+		// available
+		var percent uint32
+		if ret := l.devBusyPercentGet(i, &percent); ret == rsmiSuccess {
+			dev.fields["utilization_gpu"] = percent
+		}
+		if ret := l.devMemoryBusyPercentGet(i, &percent); ret == rsmiSuccess {
+			dev.fields["utilization_memory"] = percent
+		}
+		var total, used uint64
+		if ret := l.devMemoryTotalGet(i, rsmiMemTypeVRAM, &total); ret == rsmiSuccess {
+			dev.fields["memory_total"] = total
+		}
+		if ret := l.devMemoryUsageGet(i, rsmiMemTypeVRAM, &used); ret == rsmiSuccess {
+			dev.fields["memory_used"] = used
+			if total >= used {
+				dev.fields["memory_free"] = total - used
+			}
+		}
+
+		// Temperatures are reported in millidegrees Celsius
+		var temperature int64
+		if ret := l.devTempMetricGet(i, rsmiTempTypeEdge, rsmiTempCurrent, &temperature); ret == rsmiSuccess {
+			dev.fields["temperature"] = float64(temperature) / 1000.0
+		}
+		if ret := l.devTempMetricGet(i, rsmiTempTypeJunction, rsmiTempCurrent, &temperature); ret == rsmiSuccess {
+			dev.fields["temperature_junction"] = float64(temperature) / 1000.0
+		}
+		if ret := l.devTempMetricGet(i, rsmiTempTypeMemory, rsmiTempCurrent, &temperature); ret == rsmiSuccess {
+			dev.fields["temperature_memory"] = float64(temperature) / 1000.0
+		}
+
+		// Power is reported in microwatts
+		var power uint64
+		if ret := l.devPowerAveGet(i, 0, &power); ret == rsmiSuccess {
+			dev.fields["power_draw"] = float64(power) / 1000000.0
+		}
+
+		var speed int64
+		var maxSpeed uint64
+		if l.devFanSpeedGet(i, 0, &speed) == rsmiSuccess && l.devFanSpeedMaxGet(i, 0, &maxSpeed) == rsmiSuccess && maxSpeed > 0 {
+			dev.fields["fan_speed"] = uint32(float64(speed) / float64(maxSpeed) * 100.0)
+		}
+
+		devices = append(devices, dev)
+	}
+
+	if withProcesses && count > 0 {
+		if err := l.assignProcesses(devices); err != nil {
+			return nil, fmt.Errorf("getting processes failed: %w", err)
+		}
+	}
+
+	return devices, nil
+}
+
+// assignProcesses adds the running processes to the devices used by the
+// process. ROCm SMI only reports the total VRAM usage of a process, so the
+// usage is attributed to all devices of the process.
+func (l *rocmSMI) assignProcesses(devices []device) error {
+	var count uint32
+	if ret := l.computeProcessInfoGet(nil, &count); ret != rsmiSuccess {
+		return fmt.Errorf("status %d", ret)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	// Reserve some extra space for processes started after the query
+	count += 4
+	infos := make([]rsmiProcessInfo, count)
+	if ret := l.computeProcessInfoGet(&infos[0], &count); ret != rsmiSuccess && ret != rsmiInsufficientSize {
+		return fmt.Errorf("status %d", ret)
+	}
+	if int(count) > len(infos) {
+		count = uint32(len(infos))
+	}
+
+	indices := make([]uint32, len(devices))
+	for _, info := range infos[:count] {
+		n := uint32(len(indices))
+		if ret := l.computeProcessGPUsGet(info.processID, &indices[0], &n); ret != rsmiSuccess {
+			// The process might have terminated in the meantime
+			continue
+		}
+		for _, idx := range indices[:min(int(n), len(indices))] {
+			if int(idx) >= len(devices) {
+				continue
+			}
+			devices[idx].processes = append(devices[idx].processes, process{pid: info.processID, memoryUsed: info.vramUsage})
+		}
+	}
+
+	return nil
+}
+
+func (l *rocmSMI) shutdown() error {
+	ret := l.shutDown()
+	if err := purego.Dlclose(l.handle); err != nil {
+		return err
+	}
+	if ret != rsmiSuccess {
+		return fmt.Errorf("shutting down ROCm SMI failed: status %d", ret)
+	}
+	return nil
+}
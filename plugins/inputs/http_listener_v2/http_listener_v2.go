@@ -27,8 +27,11 @@ import (
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/internal/handoff"
+	"github.com/influxdata/telegraf/models"
 	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/parsers"
 )
 
 //go:embed sample.conf
@@ -41,9 +44,12 @@ const (
 	// if the request body is over this size, we will return an HTTP 413 error.
 	// 500 MB
 	defaultMaxBodySize = 500 * 1024 * 1024
-	body               = "body"
-	query              = "query"
-	pathTag            = "http_listener_v2_path"
+	// defaultSpoolMaxSize is the default size limit of the spool, in bytes.
+	// 100 MB
+	defaultSpoolMaxSize = 100 * 1024 * 1024
+	body                = "body"
+	query               = "query"
+	pathTag             = "http_listener_v2_path"
 )
 
 type HTTPListenerV2 struct {
@@ -61,6 +67,9 @@ type HTTPListenerV2 struct {
 	BasicUsername  string            `toml:"basic_username"`
 	BasicPassword  string            `toml:"basic_password"`
 	HTTPHeaderTags map[string]string `toml:"http_header_tags"`
+	Routes         []*route          `toml:"route"`
+	SpoolDirectory string            `toml:"spool_directory"`
+	SpoolMaxSize   config.Size       `toml:"spool_max_size"`
 
 	common_tls.ServerConfig
 	tlsConf *tls.Config
@@ -75,7 +84,22 @@ type HTTPListenerV2 struct {
 	url      *url.URL
 
 	telegraf.Parser
-	acc telegraf.Accumulator
+	acc    telegraf.Accumulator
+	routes map[string]*route
+
+	spool     *spool
+	spoolDone chan struct{}
+}
+
+// route overrides the data format, methods and credentials for a single path
+type route struct {
+	Path          string   `toml:"path"`
+	Methods       []string `toml:"methods"`
+	DataFormat    string   `toml:"data_format"`
+	BasicUsername string   `toml:"basic_username"`
+	BasicPassword string   `toml:"basic_password"`
+
+	parser telegraf.Parser
 }
 
 // timeFunc provides a timestamp for the metrics
@@ -108,6 +132,50 @@ func (h *HTTPListenerV2) Init() error {
 		h.SuccessCode = http.StatusNoContent
 	}
 
+	h.routes = make(map[string]*route, len(h.Routes))
+	for i, r := range h.Routes {
+		if r.Path == "" {
+			return fmt.Errorf("route %d: path required", i+1)
+		}
+		if _, found := h.routes[r.Path]; found {
+			return fmt.Errorf("duplicate route for path %q", r.Path)
+		}
+		if (r.BasicUsername == "") != (r.BasicPassword == "") {
+			return fmt.Errorf("route %q: both basic_username and basic_password required", r.Path)
+		}
+
+		// Fall back to the plugin-level settings for everything not
+		// overridden by the route
+		if len(r.Methods) == 0 {
+			r.Methods = h.Methods
+		}
+		if r.BasicUsername == "" {
+			r.BasicUsername = h.BasicUsername
+			r.BasicPassword = h.BasicPassword
+		}
+		if r.DataFormat == "" {
+			r.parser = h.Parser
+		} else {
+			creator, found := parsers.Parsers[r.DataFormat]
+			if !found {
+				return fmt.Errorf("route %q: unknown data format %q", r.Path, r.DataFormat)
+			}
+			parser := creator("http_listener_v2")
+			models.SetLoggerOnPlugin(parser, h.Log)
+			if p, ok := parser.(telegraf.Initializer); ok {
+				if err := p.Init(); err != nil {
+					return fmt.Errorf("route %q: initializing parser failed: %w", r.Path, err)
+				}
+			}
+			r.parser = parser
+		}
+		h.routes[r.Path] = r
+	}
+
+	if h.SpoolMaxSize == 0 {
+		h.SpoolMaxSize = config.Size(defaultSpoolMaxSize)
+	}
+
 	return nil
 }
 
@@ -133,16 +201,13 @@ func (h *HTTPListenerV2) Start(acc telegraf.Accumulator) error {
 		return fmt.Errorf("unknown protocol %q", u.Scheme)
 	}
 
-	var listener net.Listener
-	var err error
-	if h.tlsConf != nil {
-		listener, err = tls.Listen(u.Scheme, address, h.tlsConf)
-	} else {
-		listener, err = net.Listen(u.Scheme, address)
-	}
+	listener, err := handoff.Listen(u.Scheme, address)
 	if err != nil {
 		return err
 	}
+	if h.tlsConf != nil {
+		listener = tls.NewListener(listener, h.tlsConf)
+	}
 	h.listener = listener
 
 	if u.Scheme == "unix" && h.SocketMode != "" {
@@ -172,6 +237,25 @@ func (h *HTTPListenerV2) Start(acc telegraf.Accumulator) error {
 
 	h.acc = acc
 
+	if h.SpoolDirectory != "" {
+		s, err := openSpool(h.SpoolDirectory, int64(h.SpoolMaxSize))
+		if err != nil {
+			h.listener.Close()
+			return err
+		}
+		if n := s.len(); n > 0 {
+			h.Log.Infof("Replaying %d spooled requests", n)
+		}
+		h.spool = s
+		h.spoolDone = make(chan struct{})
+
+		h.wg.Add(1)
+		go func() {
+			defer h.wg.Done()
+			h.processSpool()
+		}()
+	}
+
 	server := h.createHTTPServer()
 
 	h.wg.Add(1)
@@ -198,22 +282,42 @@ func (h *HTTPListenerV2) Stop() {
 	if h.listener != nil {
 		h.listener.Close()
 	}
+	if h.spoolDone != nil {
+		close(h.spoolDone)
+	}
 	h.wg.Wait()
+
+	// Remaining entries are kept on disk and processed on the next start
+	if h.spool != nil {
+		if err := h.spool.close(); err != nil {
+			h.Log.Errorf("Closing spool failed: %v", err)
+		}
+	}
 }
 
 // ServeHTTP implements [http.Handler]
 func (h *HTTPListenerV2) ServeHTTP(res http.ResponseWriter, req *http.Request) {
-	handler := h.serveWrite
+	for key, value := range h.HTTPHeaders {
+		res.Header().Set(key, value)
+	}
 
-	if !choice.Contains(req.URL.Path, h.Paths) {
-		handler = http.NotFound
+	// Routes take precedence over the generic paths
+	if r, found := h.routes[req.URL.Path]; found {
+		handler := func(res http.ResponseWriter, req *http.Request) {
+			h.serveWrite(res, req, r.Methods, r.parser)
+		}
+		authenticateIfSet(handler, res, req, r.BasicUsername, r.BasicPassword)
+		return
 	}
 
-	for key, value := range h.HTTPHeaders {
-		res.Header().Set(key, value)
+	handler := func(res http.ResponseWriter, req *http.Request) {
+		h.serveWrite(res, req, h.Methods, h.Parser)
+	}
+	if !choice.Contains(req.URL.Path, h.Paths) {
+		handler = http.NotFound
 	}
 
-	h.authenticateIfSet(handler, res, req)
+	authenticateIfSet(handler, res, req, h.BasicUsername, h.BasicPassword)
 }
 
 func (h *HTTPListenerV2) createHTTPServer() *http.Server {
@@ -226,7 +330,7 @@ func (h *HTTPListenerV2) createHTTPServer() *http.Server {
 	}
 }
 
-func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request) {
+func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request, methods []string, parser telegraf.Parser) {
 	select {
 	case <-h.close:
 		res.WriteHeader(http.StatusGone)
@@ -244,7 +348,7 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 
 	// Check if the requested HTTP method was specified in config.
 	isAcceptedMethod := false
-	for _, method := range h.Methods {
+	for _, method := range methods {
 		if req.Method == method {
 			isAcceptedMethod = true
 			break
@@ -271,7 +375,33 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	metrics, err := h.Parse(bytes)
+	tags := make(map[string]string, len(h.HTTPHeaderTags))
+	for headerName, measurementName := range h.HTTPHeaderTags {
+		headerValues := req.Header.Get(headerName)
+		if len(headerValues) > 0 {
+			tags[measurementName] = headerValues
+		}
+	}
+
+	// Enqueue the request and defer parsing to the spool consumer
+	if h.spool != nil {
+		entry := &spoolEntry{Path: req.URL.Path, Tags: tags, Body: bytes}
+		if err := h.spool.push(entry); err != nil {
+			if errors.Is(err, errSpoolFull) {
+				h.Log.Debug("Spool full, rejecting request")
+			} else {
+				h.Log.Errorf("Spooling request failed: %v", err)
+			}
+			if err := serviceUnavailable(res); err != nil {
+				h.Log.Debugf("error in service-unavailable: %v", err)
+			}
+			return
+		}
+		res.WriteHeader(h.SuccessCode)
+		return
+	}
+
+	metrics, err := parser.Parse(bytes)
 	if err != nil {
 		h.Log.Debugf("Parse error: %s", err.Error())
 		if err := badRequest(res); err != nil {
@@ -279,7 +409,12 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 		}
 		return
 	}
+	h.addMetrics(metrics, req.URL.Path, tags)
+
+	res.WriteHeader(h.SuccessCode)
+}
 
+func (h *HTTPListenerV2) addMetrics(metrics []telegraf.Metric, path string, tags map[string]string) {
 	if len(metrics) == 0 {
 		once.Do(func() {
 			h.Log.Debug(internal.NoMetricsCreatedMsg)
@@ -287,21 +422,63 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 	}
 
 	for _, m := range metrics {
-		for headerName, measurementName := range h.HTTPHeaderTags {
-			headerValues := req.Header.Get(headerName)
-			if len(headerValues) > 0 {
-				m.AddTag(measurementName, headerValues)
-			}
+		for key, value := range tags {
+			m.AddTag(key, value)
 		}
 
 		if h.PathTag {
-			m.AddTag(pathTag, req.URL.Path)
+			m.AddTag(pathTag, path)
 		}
 
 		h.acc.AddMetric(m)
 	}
+}
 
-	res.WriteHeader(h.SuccessCode)
+// processSpool parses the spooled requests in order of arrival until the
+// plugin is stopped
+func (h *HTTPListenerV2) processSpool() {
+	for {
+		select {
+		case <-h.spoolDone:
+			return
+		case <-h.spool.notify:
+		}
+
+		for {
+			select {
+			case <-h.spoolDone:
+				return
+			default:
+			}
+
+			idx, entry, err := h.spool.peek()
+			if idx == 0 {
+				if err != nil {
+					h.Log.Errorf("Reading spool failed: %v", err)
+				}
+				break
+			}
+			if err != nil {
+				h.Log.Errorf("Dropping spooled request: %v", err)
+			} else {
+				parser := h.Parser
+				if r, found := h.routes[entry.Path]; found {
+					parser = r.parser
+				}
+				metrics, err := parser.Parse(entry.Body)
+				if err != nil {
+					h.Log.Errorf("Parsing spooled request for path %q failed: %v", entry.Path, err)
+				} else {
+					h.addMetrics(metrics, entry.Path, entry.Tags)
+				}
+			}
+
+			if err := h.spool.remove(idx); err != nil {
+				h.Log.Errorf("Removing spooled request failed: %v", err)
+				break
+			}
+		}
+	}
 }
 
 func (h *HTTPListenerV2) collectBody(res http.ResponseWriter, req *http.Request) ([]byte, bool) {
@@ -397,12 +574,19 @@ func badRequest(res http.ResponseWriter) error {
 	return err
 }
 
-func (h *HTTPListenerV2) authenticateIfSet(handler http.HandlerFunc, res http.ResponseWriter, req *http.Request) {
-	if h.BasicUsername != "" && h.BasicPassword != "" {
+func serviceUnavailable(res http.ResponseWriter) error {
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusServiceUnavailable)
+	_, err := res.Write([]byte(`{"error":"http: service unavailable"}`))
+	return err
+}
+
+func authenticateIfSet(handler http.HandlerFunc, res http.ResponseWriter, req *http.Request, username, password string) {
+	if username != "" && password != "" {
 		reqUsername, reqPassword, ok := req.BasicAuth()
 		if !ok ||
-			subtle.ConstantTimeCompare([]byte(reqUsername), []byte(h.BasicUsername)) != 1 ||
-			subtle.ConstantTimeCompare([]byte(reqPassword), []byte(h.BasicPassword)) != 1 {
+			subtle.ConstantTimeCompare([]byte(reqUsername), []byte(username)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(reqPassword), []byte(password)) != 1 {
 			http.Error(res, "Unauthorized.", http.StatusUnauthorized)
 			return
 		}
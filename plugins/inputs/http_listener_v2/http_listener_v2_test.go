@@ -23,6 +23,8 @@ import (
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/parsers/form_urlencoded"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	_ "github.com/influxdata/telegraf/plugins/parsers/json"
+	_ "github.com/influxdata/telegraf/plugins/parsers/opentsdb"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -776,3 +778,242 @@ func mustReadHugeMetric() []byte {
 
 // The term 'master_repl' used here is archaic language from redis
 var hugeMetric = mustReadHugeMetric()
+
+func TestRoutesInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		routes   []*route
+		expected string
+	}{
+		{
+			name:     "no path",
+			routes:   []*route{{DataFormat: "json"}},
+			expected: "route 1: path required",
+		},
+		{
+			name:     "duplicate path",
+			routes:   []*route{{Path: "/app"}, {Path: "/app"}},
+			expected: `duplicate route for path "/app"`,
+		},
+		{
+			name:     "incomplete credentials",
+			routes:   []*route{{Path: "/app", BasicUsername: "app"}},
+			expected: "both basic_username and basic_password required",
+		},
+		{
+			name:     "unknown data format",
+			routes:   []*route{{Path: "/app", DataFormat: "foo"}},
+			expected: `unknown data format "foo"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, err := newTestHTTPListenerV2()
+			require.NoError(t, err)
+			listener.Routes = tt.routes
+			require.ErrorContains(t, listener.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWriteHTTPRoutes(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.Routes = []*route{
+		{
+			Path:          "/app/json",
+			Methods:       []string{"PUT"},
+			DataFormat:    "json",
+			BasicUsername: basicUsername,
+			BasicPassword: basicPassword,
+		},
+		{
+			Path: "/app/influx",
+		},
+	}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	client := &http.Client{}
+
+	// JSON route requires credentials and the configured method
+	req, err := http.NewRequest("PUT", createURL(listener, "http", "/app/json", ""), bytes.NewBufferString(`{"value": 42}`))
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, err = http.NewRequest("POST", createURL(listener, "http", "/app/json", ""), bytes.NewBufferString(`{"value": 42}`))
+	require.NoError(t, err)
+	req.SetBasicAuth(basicUsername, basicPassword)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	req, err = http.NewRequest("PUT", createURL(listener, "http", "/app/json", ""), bytes.NewBufferString(`{"value": 42}`))
+	require.NoError(t, err)
+	req.SetBasicAuth(basicUsername, basicPassword)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, http.StatusNoContent, resp.StatusCode)
+
+	// Route without overrides uses the plugin-level parser and methods
+	resp, err = http.Post(createURL(listener, "http", "/app/influx", ""), "", bytes.NewBufferString(testMsg))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, http.StatusNoContent, resp.StatusCode)
+
+	// Generic paths are still served
+	resp, err = http.Post(createURL(listener, "http", "/write", ""), "", bytes.NewBufferString(testMsg))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, http.StatusNoContent, resp.StatusCode)
+
+	acc.Wait(3)
+	acc.AssertContainsFields(t, "http_listener_v2", map[string]interface{}{"value": float64(42)})
+	acc.AssertContainsTaggedFields(t, "cpu_load_short",
+		map[string]interface{}{"value": float64(12)},
+		map[string]string{"host": "server01"},
+	)
+	require.Equal(t, 2, countMetrics(acc, "cpu_load_short"))
+}
+
+func TestWriteHTTPRouteParserLogger(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.Routes = []*route{{Path: "/app/opentsdb", DataFormat: "opentsdb"}}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	// The parser logs invalid lines which requires a logger to be set
+	body := "put sys.cpu.user 1356998400 42.5 host=webserver01\ninvalid line\n"
+	resp, err := http.Post(createURL(listener, "http", "/app/opentsdb", ""), "", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, http.StatusNoContent, resp.StatusCode)
+
+	acc.Wait(1)
+	acc.AssertContainsTaggedFields(t, "sys.cpu.user",
+		map[string]interface{}{"value": 42.5},
+		map[string]string{"host": "webserver01"},
+	)
+}
+
+func countMetrics(acc *testutil.Accumulator, name string) int {
+	var count int
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == name {
+			count++
+		}
+	}
+	return count
+}
+
+func TestWriteHTTPSpool(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.SpoolDirectory = t.TempDir()
+	listener.PathTag = true
+	listener.HTTPHeaderTags = map[string]string{"Present_http_header_1": "presentMeasurementName1"}
+	listener.Routes = []*route{{Path: "/app/json", DataFormat: "json"}}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	req, err := http.NewRequest("POST", createURL(listener, "http", "/write", ""), bytes.NewBufferString(testMsgs))
+	require.NoError(t, err)
+	req.Header.Set("Present_http_header_1", "present1")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, http.StatusNoContent, resp.StatusCode)
+
+	// Parse errors are not reported to the client with spooling
+	resp, err = http.Post(createURL(listener, "http", "/write", ""), "", bytes.NewBufferString(badMsg))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, err = http.Post(createURL(listener, "http", "/app/json", ""), "", bytes.NewBufferString(`{"value": 42}`))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, http.StatusNoContent, resp.StatusCode)
+
+	acc.Wait(6)
+	for _, host := range []string{"server02", "server03", "server04", "server05", "server06"} {
+		acc.AssertContainsTaggedFields(t, "cpu_load_short",
+			map[string]interface{}{"value": float64(12)},
+			map[string]string{"host": host, "presentMeasurementName1": "present1", "http_listener_v2_path": "/write"},
+		)
+	}
+	acc.AssertContainsTaggedFields(t, "http_listener_v2",
+		map[string]interface{}{"value": float64(42)},
+		map[string]string{"http_listener_v2_path": "/app/json"},
+	)
+	require.Eventually(t, func() bool {
+		return listener.spool.len() == 0
+	}, 3*time.Second, 10*time.Millisecond)
+}
+
+func TestWriteHTTPSpoolFull(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.SpoolDirectory = t.TempDir()
+	listener.SpoolMaxSize = config.Size(10)
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	resp, err := http.Post(createURL(listener, "http", "/write", ""), "", bytes.NewBufferString(testMsg))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, http.StatusServiceUnavailable, resp.StatusCode)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestWriteHTTPSpoolReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	// Simulate requests accepted but not processed before a restart
+	s, err := openSpool(dir, defaultSpoolMaxSize)
+	require.NoError(t, err)
+	require.NoError(t, s.push(&spoolEntry{Path: "/write", Body: []byte(testMsg)}))
+	require.NoError(t, s.push(&spoolEntry{Path: "/write", Body: []byte(testMsgNoNewline)}))
+	require.NoError(t, s.close())
+
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.SpoolDirectory = dir
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	acc.Wait(2)
+	require.Equal(t, 2, countMetrics(acc, "cpu_load_short"))
+	require.Eventually(t, func() bool {
+		return listener.spool.len() == 0
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// New requests are appended to the drained spool
+	resp, err := http.Post(createURL(listener, "http", "/write", ""), "", bytes.NewBufferString(testMsg))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, http.StatusNoContent, resp.StatusCode)
+	acc.Wait(3)
+}
@@ -0,0 +1,163 @@
+package http_listener_v2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/tidwall/wal"
+)
+
+var errSpoolFull = errors.New("spool is full")
+
+// spoolEntry is an accepted but not yet parsed request
+type spoolEntry struct {
+	Path string            `json:"path"`
+	Tags map[string]string `json:"tags,omitempty"`
+	Body []byte            `json:"body"`
+}
+
+// spool is a bounded on-disk queue of accepted requests
+type spool struct {
+	sync.Mutex
+
+	file    *wal.Log
+	size    int64
+	maxSize int64
+
+	notify chan struct{}
+}
+
+func openSpool(path string, maxSize int64) (*spool, error) {
+	opts := *wal.DefaultOptions
+	opts.AllowEmpty = true
+	file, err := wal.Open(path, &opts)
+	if err != nil {
+		return nil, fmt.Errorf("opening spool failed: %w", err)
+	}
+
+	s := &spool{
+		file:    file,
+		maxSize: maxSize,
+		notify:  make(chan struct{}, 1),
+	}
+
+	// Account for the entries left over from a previous run
+	first, last, err := s.indices()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	for idx := first; idx <= last; idx++ {
+		data, err := file.Read(idx)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("reading spool entry %d failed: %w", idx, err)
+		}
+		s.size += int64(len(data))
+	}
+	if s.size > 0 {
+		s.signal()
+	}
+
+	return s, nil
+}
+
+// push appends the entry to the queue unless the size limit is exceeded
+func (s *spool) push(entry *spoolEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding spool entry failed: %w", err)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.size+int64(len(data)) > s.maxSize {
+		return errSpoolFull
+	}
+	_, last, err := s.indices()
+	if err != nil {
+		return err
+	}
+	if err := s.file.Write(last+1, data); err != nil {
+		return fmt.Errorf("writing spool entry failed: %w", err)
+	}
+	s.size += int64(len(data))
+	s.signal()
+
+	return nil
+}
+
+// peek returns the index and the entry at the front of the queue or a zero
+// index if the queue is empty
+func (s *spool) peek() (uint64, *spoolEntry, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	first, last, err := s.indices()
+	if err != nil || first > last {
+		return 0, nil, err
+	}
+	data, err := s.file.Read(first)
+	if err != nil {
+		return first, nil, fmt.Errorf("reading spool entry %d failed: %w", first, err)
+	}
+
+	var entry spoolEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return first, nil, fmt.Errorf("decoding spool entry %d failed: %w", first, err)
+	}
+	return first, &entry, nil
+}
+
+// remove drops the entry with the given index from the front of the queue
+func (s *spool) remove(idx uint64) error {
+	s.Lock()
+	defer s.Unlock()
+
+	data, err := s.file.Read(idx)
+	if err != nil {
+		return fmt.Errorf("reading spool entry %d failed: %w", idx, err)
+	}
+	if err := s.file.TruncateFront(idx + 1); err != nil {
+		return fmt.Errorf("removing spool entry %d failed: %w", idx, err)
+	}
+	s.size -= int64(len(data))
+
+	return nil
+}
+
+func (s *spool) len() int {
+	s.Lock()
+	defer s.Unlock()
+
+	first, last, err := s.indices()
+	if err != nil {
+		return 0
+	}
+	return int(last + 1 - first)
+}
+
+func (s *spool) close() error {
+	return s.file.Close()
+}
+
+func (s *spool) indices() (first, last uint64, err error) {
+	if first, err = s.file.FirstIndex(); err != nil {
+		return 0, 0, fmt.Errorf("getting first spool index failed: %w", err)
+	}
+	if last, err = s.file.LastIndex(); err != nil {
+		return 0, 0, fmt.Errorf("getting last spool index failed: %w", err)
+	}
+	return first, last, nil
+}
+
+// signal wakes up the consumer without blocking if a wakeup is pending already
+func (s *spool) signal() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
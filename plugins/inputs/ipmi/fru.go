@@ -0,0 +1,135 @@
+package ipmi
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// Names of the fields in the chassis, board and product info areas of the
+// FRU inventory in the order defined by the specification
+var (
+	fruChassisFields = []string{"chassis_part_number", "chassis_serial"}
+	fruBoardFields   = []string{"board_manufacturer", "board_product", "board_serial", "board_part_number"}
+	fruProductFields = []string{
+		"product_manufacturer", "product_name", "product_part_number",
+		"product_version", "product_serial", "product_asset_tag",
+	}
+)
+
+// readFRU reads the inventory data of the given FRU device
+func (s *session) readFRU(device uint8) ([]byte, error) {
+	const chunkSize = 16
+
+	info, err := s.command(netFnStorage, 0, cmdGetFRUInventoryAreaInfo, []byte{device})
+	if err != nil {
+		return nil, err
+	}
+	if len(info) < 3 {
+		return nil, errors.New("invalid FRU inventory area info")
+	}
+	size := int(binary.LittleEndian.Uint16(info))
+	words := info[2]&0x01 != 0
+
+	data := make([]byte, 0, size)
+	for len(data) < size {
+		offset, count := len(data), min(chunkSize, size-len(data))
+		if words {
+			offset, count = offset/2, max(count/2, 1)
+		}
+		chunk, err := s.command(netFnStorage, 0, cmdReadFRUData, []byte{device, byte(offset), byte(offset >> 8), byte(count)})
+		if err != nil {
+			return nil, err
+		}
+		if len(chunk) < 2 {
+			return nil, errors.New("empty FRU data")
+		}
+		data = append(data, chunk[1:]...)
+	}
+	return data[:size], nil
+}
+
+// parseFRU decodes the chassis, board and product info areas of the FRU
+// inventory data
+func parseFRU(data []byte) (map[string]interface{}, error) {
+	if len(data) < 8 || data[0] != 0x01 {
+		return nil, errors.New("unsupported FRU format")
+	}
+	if checksum(data[:8]) != 0 {
+		return nil, errors.New("invalid FRU header checksum")
+	}
+
+	fields := make(map[string]interface{})
+	areas := []struct {
+		offset int
+		skip   int // bytes between the area header and the first field
+		names  []string
+	}{
+		{offset: int(data[2]) * 8, skip: 1, names: fruChassisFields}, // chassis type
+		{offset: int(data[3]) * 8, skip: 4, names: fruBoardFields},   // language and manufacturing date
+		{offset: int(data[4]) * 8, skip: 1, names: fruProductFields}, // language
+	}
+	for _, area := range areas {
+		if area.offset == 0 {
+			continue
+		}
+		if area.offset+2 > len(data) {
+			return nil, errors.New("FRU area exceeds inventory data")
+		}
+		end := area.offset + int(data[area.offset+1])*8
+		if end > len(data) {
+			return nil, errors.New("FRU area exceeds inventory data")
+		}
+
+		offset := area.offset + 2 + area.skip
+		for _, name := range area.names {
+			if offset >= end || data[offset] == 0xc1 {
+				break
+			}
+			value, n := decodeFRUField(data[offset:end])
+			offset += n
+			if value != "" {
+				fields[name] = value
+			}
+		}
+	}
+
+	return fields, nil
+}
+
+// decodeFRUField decodes a type/length encoded field and returns the value
+// and the number of bytes consumed
+func decodeFRUField(data []byte) (string, int) {
+	length := int(data[0] & 0x3f)
+	if 1+length > len(data) {
+		return "", len(data)
+	}
+	raw := data[1 : 1+length]
+
+	var value string
+	switch data[0] >> 6 {
+	case 0x00: // binary
+		value = hex.EncodeToString(raw)
+	case 0x01: // BCD plus
+		const digits = "0123456789 -.???"
+		var sb strings.Builder
+		for _, b := range raw {
+			sb.WriteByte(digits[b>>4])
+			sb.WriteByte(digits[b&0x0f])
+		}
+		value = sb.String()
+	case 0x02: // 6-bit packed ASCII
+		var sb strings.Builder
+		for i := 0; i+2 < len(raw); i += 3 {
+			v := uint32(raw[i]) | uint32(raw[i+1])<<8 | uint32(raw[i+2])<<16
+			for j := 0; j < 4; j++ {
+				sb.WriteByte(byte(v>>(6*j))&0x3f + 0x20)
+			}
+		}
+		value = sb.String()
+	default: // 8-bit ASCII and Latin 1
+		value = string(raw)
+	}
+	return strings.TrimSpace(strings.TrimRight(value, "\x00")), 1 + length
+}
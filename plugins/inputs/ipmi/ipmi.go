@@ -0,0 +1,394 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package ipmi
+
+import (
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var privileges = map[string]uint8{
+	"callback":      privilegeCallback,
+	"user":          privilegeUser,
+	"operator":      privilegeOperator,
+	"administrator": privilegeAdministrator,
+}
+
+type IPMI struct {
+	Servers        []string        `toml:"servers"`
+	Username       config.Secret   `toml:"username"`
+	Password       config.Secret   `toml:"password"`
+	HexKey         config.Secret   `toml:"hex_key"`
+	Privilege      string          `toml:"privilege"`
+	CipherSuite    int             `toml:"cipher_suite"`
+	Collect        []string        `toml:"collect"`
+	Timeout        config.Duration `toml:"timeout"`
+	Retries        int             `toml:"retries"`
+	IdleTimeout    config.Duration `toml:"idle_timeout"`
+	MaxConcurrency int             `toml:"max_concurrency"`
+	Log            telegraf.Logger `toml:"-"`
+
+	suite     *cipherSuite
+	privilege uint8
+	bmcs      []*bmc
+}
+
+// bmc keeps the session and the cached data of a server across gathers
+type bmc struct {
+	address  string
+	hostname string
+
+	session      *session
+	sdrTimestamp uint64
+	sensors      []*sensorRecord
+	selInfo      *selInfo
+	selSeen      map[uint16]bool
+	fru          map[string]interface{}
+}
+
+func (*IPMI) SampleConfig() string {
+	return sampleConfig
+}
+
+func (m *IPMI) Init() error {
+	if len(m.Servers) == 0 {
+		return errors.New("no servers configured")
+	}
+
+	if m.Privilege == "" {
+		m.Privilege = "user"
+	}
+	var found bool
+	if m.privilege, found = privileges[strings.ToLower(m.Privilege)]; !found {
+		return fmt.Errorf("invalid privilege %q", m.Privilege)
+	}
+
+	if m.CipherSuite == 0 {
+		m.CipherSuite = 3
+	}
+	if m.suite, found = cipherSuites[m.CipherSuite]; !found {
+		return fmt.Errorf("unsupported cipher suite %d", m.CipherSuite)
+	}
+
+	if len(m.Collect) == 0 {
+		m.Collect = []string{"sensors"}
+	}
+	if err := choice.CheckSlice(m.Collect, []string{"sensors", "sel", "fru"}); err != nil {
+		return fmt.Errorf("invalid 'collect' setting: %w", err)
+	}
+
+	if m.Retries < 0 {
+		return errors.New("'retries' must not be negative")
+	}
+	if m.MaxConcurrency < 1 {
+		m.MaxConcurrency = 1
+	}
+
+	m.bmcs = make([]*bmc, 0, len(m.Servers))
+	for _, server := range m.Servers {
+		address := server
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			address = net.JoinHostPort(server, "623")
+		}
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return fmt.Errorf("invalid server %q: %w", server, err)
+		}
+		m.bmcs = append(m.bmcs, &bmc{address: address, hostname: host})
+	}
+
+	return nil
+}
+
+func (*IPMI) Start(telegraf.Accumulator) error {
+	return nil
+}
+
+func (m *IPMI) Gather(acc telegraf.Accumulator) error {
+	// Limit the number of servers queried at the same time
+	sem := make(chan struct{}, m.MaxConcurrency)
+	var wg sync.WaitGroup
+	for _, b := range m.bmcs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(b *bmc) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			m.gatherServer(acc, b)
+		}(b)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (m *IPMI) Stop() {
+	for _, b := range m.bmcs {
+		m.disconnect(b)
+	}
+}
+
+func (m *IPMI) gatherServer(acc telegraf.Accumulator, b *bmc) {
+	for _, c := range m.Collect {
+		var gather func(telegraf.Accumulator, *bmc) error
+		switch c {
+		case "sensors":
+			gather = m.gatherSensors
+		case "sel":
+			gather = m.gatherSEL
+		case "fru":
+			gather = m.gatherFRU
+		}
+
+		if err := m.connect(b); err != nil {
+			acc.AddError(fmt.Errorf("connecting to %q failed: %w", b.address, err))
+			return
+		}
+		err := gather(acc, b)
+		if err == nil {
+			continue
+		}
+
+		// The BMC might have dropped the session, so retry with a new one
+		m.Log.Debugf("Gathering %s from %q failed, reconnecting: %v", c, b.address, err)
+		m.disconnect(b)
+		if err := m.connect(b); err != nil {
+			acc.AddError(fmt.Errorf("connecting to %q failed: %w", b.address, err))
+			return
+		}
+		if err := gather(acc, b); err != nil {
+			acc.AddError(fmt.Errorf("gathering %s from %q failed: %w", c, b.address, err))
+		}
+	}
+}
+
+// connect establishes a new session if the server has no session or the
+// session was idle for too long and might have been closed by the BMC
+func (m *IPMI) connect(b *bmc) error {
+	if b.session != nil {
+		if m.IdleTimeout <= 0 || time.Since(b.session.lastUsed) < time.Duration(m.IdleTimeout) {
+			return nil
+		}
+		m.disconnect(b)
+	}
+
+	creds, err := m.credentials()
+	if err != nil {
+		return err
+	}
+	s, err := openSession(b.address, creds, m.suite, m.privilege, time.Duration(m.Timeout), m.Retries)
+	clear(creds.password)
+	clear(creds.kg)
+	if err != nil {
+		return err
+	}
+	b.session = s
+	return nil
+}
+
+func (*IPMI) disconnect(b *bmc) {
+	if b.session != nil {
+		b.session.close()
+		b.session = nil
+	}
+	// Re-read the FRU data with the next session
+	b.fru = nil
+}
+
+func (m *IPMI) credentials() (*credentials, error) {
+	var creds credentials
+
+	username, err := m.Username.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting username failed: %w", err)
+	}
+	creds.username = []byte(username.String())
+	username.Destroy()
+
+	password, err := m.Password.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting password failed: %w", err)
+	}
+	creds.password = []byte(password.String())
+	password.Destroy()
+
+	if !m.HexKey.Empty() {
+		key, err := m.HexKey.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting hex key failed: %w", err)
+		}
+		creds.kg, err = hex.DecodeString(strings.TrimPrefix(key.String(), "0x"))
+		key.Destroy()
+		if err != nil {
+			return nil, fmt.Errorf("decoding hex key failed: %w", err)
+		}
+	}
+
+	return &creds, nil
+}
+
+func (m *IPMI) gatherSensors(acc telegraf.Accumulator, b *bmc) error {
+	// Only re-read the sensor data records if the repository changed
+	timestamp, err := b.session.sdrRepositoryTimestamp()
+	if err != nil {
+		return fmt.Errorf("querying SDR repository failed: %w", err)
+	}
+	if b.sensors == nil || timestamp != b.sdrTimestamp {
+		sensors, err := b.session.readSDRs()
+		if err != nil {
+			return err
+		}
+		b.sensors = sensors
+		b.sdrTimestamp = timestamp
+	}
+
+	// Collect the metrics first to avoid duplicates if the gather is retried
+	now := time.Now()
+	metrics := make([]telegraf.Metric, 0, len(b.sensors))
+	for _, r := range b.sensors {
+		// Sensors owned by satellite controllers require bridging
+		if r.owner != bmcAddress {
+			continue
+		}
+
+		reading, err := b.session.readSensor(r)
+		if err != nil {
+			var cerr *completionError
+			if errors.As(err, &cerr) {
+				m.Log.Debugf("Reading sensor %q of %q failed: %v", r.name, b.address, err)
+				continue
+			}
+			return fmt.Errorf("reading sensor %q failed: %w", r.name, err)
+		}
+		if reading == nil {
+			continue
+		}
+
+		tags := map[string]string{
+			"server":    b.hostname,
+			"name":      transform(r.name),
+			"entity_id": r.entity(),
+		}
+		fields := make(map[string]interface{}, 1)
+		if r.analog {
+			tags["unit"] = r.unit()
+			tags["status_code"] = reading.statusCode()
+			fields["value"] = r.convert(reading.raw)
+		} else {
+			tags["status_code"] = "ok"
+			fields["state"] = uint64(reading.status & 0x7fff)
+		}
+		metrics = append(metrics, metric.New("ipmi_sensor", tags, fields, now))
+	}
+
+	for _, m := range metrics {
+		acc.AddMetric(m)
+	}
+	return nil
+}
+
+func (m *IPMI) gatherSEL(acc telegraf.Accumulator, b *bmc) error {
+	info, err := b.session.readSELInfo()
+	if err != nil {
+		return fmt.Errorf("querying SEL info failed: %w", err)
+	}
+
+	// Only read the entries if the log changed since the last gather
+	var entries []*selEntry
+	changed := b.selInfo == nil || *b.selInfo != *info
+	if changed {
+		if entries, err = b.session.readSEL(); err != nil {
+			return err
+		}
+	}
+
+	acc.AddFields("ipmi_sel",
+		map[string]interface{}{
+			"entries":    uint64(info.entries),
+			"free_bytes": uint64(info.free),
+		},
+		map[string]string{"server": b.hostname},
+	)
+	if !changed {
+		return nil
+	}
+
+	seen := make(map[uint16]bool, len(entries))
+	for _, e := range entries {
+		seen[e.id] = true
+		if b.selSeen[e.id] {
+			continue
+		}
+
+		tags, fields := e.fields()
+		tags["server"] = b.hostname
+		if t, ok := e.time(); ok {
+			acc.AddFields("ipmi_sel_entry", fields, tags, t)
+		} else {
+			acc.AddFields("ipmi_sel_entry", fields, tags)
+		}
+	}
+	b.selInfo = info
+	b.selSeen = seen
+	m.Log.Tracef("Read %d SEL entries from %q", len(entries), b.address)
+
+	return nil
+}
+
+func (*IPMI) gatherFRU(acc telegraf.Accumulator, b *bmc) error {
+	if b.fru == nil {
+		data, err := b.session.readFRU(0)
+		if err != nil {
+			return fmt.Errorf("reading FRU failed: %w", err)
+		}
+		if b.fru, err = parseFRU(data); err != nil {
+			return fmt.Errorf("parsing FRU failed: %w", err)
+		}
+	}
+	if len(b.fru) == 0 {
+		return nil
+	}
+
+	tags := map[string]string{
+		"server": b.hostname,
+		"fru_id": "0",
+	}
+	acc.AddFields("ipmi_fru", b.fru, tags)
+	return nil
+}
+
+// transform converts the sensor name to the format used by the ipmi_sensor
+// input
+func transform(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.ToLower(s)
+	return strings.ReplaceAll(s, " ", "_")
+}
+
+func init() {
+	inputs.Add("ipmi", func() telegraf.Input {
+		return &IPMI{
+			Privilege:      "user",
+			CipherSuite:    3,
+			Collect:        []string{"sensors"},
+			Timeout:        config.Duration(2 * time.Second),
+			Retries:        2,
+			IdleTimeout:    config.Duration(50 * time.Second),
+			MaxConcurrency: 32,
+		}
+	})
+}
@@ -0,0 +1,908 @@
+package ipmi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *IPMI
+		expected string
+	}{
+		{
+			name:     "no servers",
+			plugin:   &IPMI{},
+			expected: "no servers configured",
+		},
+		{
+			name:     "invalid privilege",
+			plugin:   &IPMI{Servers: []string{"localhost"}, Privilege: "root"},
+			expected: `invalid privilege "root"`,
+		},
+		{
+			name:     "invalid cipher suite",
+			plugin:   &IPMI{Servers: []string{"localhost"}, CipherSuite: 1},
+			expected: "unsupported cipher suite 1",
+		},
+		{
+			name:     "invalid collect",
+			plugin:   &IPMI{Servers: []string{"localhost"}, Collect: []string{"sensors", "sdr"}},
+			expected: "invalid 'collect' setting",
+		},
+		{
+			name:     "invalid server",
+			plugin:   &IPMI{Servers: []string{"[::1"}},
+			expected: `invalid server "[::1"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = &testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInitDefaultPort(t *testing.T) {
+	plugin := &IPMI{
+		Servers: []string{"10.0.0.1", "10.0.0.2:6230", "::1", "bmc.example.com"},
+		Log:     &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	addresses := make([]string, 0, len(plugin.bmcs))
+	for _, b := range plugin.bmcs {
+		addresses = append(addresses, b.address)
+	}
+	require.Equal(t, []string{"10.0.0.1:623", "10.0.0.2:6230", "[::1]:623", "bmc.example.com:623"}, addresses)
+}
+
+func TestGatherSensors(t *testing.T) {
+	for _, suite := range []int{3, 17} {
+		t.Run(cipherSuiteName(suite), func(t *testing.T) {
+			bmc := newMockBMC(t, suite)
+			plugin := bmc.plugin("sensors")
+			plugin.CipherSuite = suite
+			require.NoError(t, plugin.Init())
+			defer plugin.Stop()
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Gather(&acc))
+			require.Empty(t, acc.Errors)
+
+			expected := []telegraf.Metric{
+				metric.New(
+					"ipmi_sensor",
+					map[string]string{
+						"server":      "127.0.0.1",
+						"name":        "cpu_temp",
+						"entity_id":   "3.1",
+						"unit":        "degrees_c",
+						"status_code": "ok",
+					},
+					map[string]interface{}{"value": 45.0},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"ipmi_sensor",
+					map[string]string{
+						"server":      "127.0.0.1",
+						"name":        "12v",
+						"entity_id":   "7.1",
+						"unit":        "volts",
+						"status_code": "cr",
+					},
+					map[string]interface{}{"value": float64(63) * 190 * math.Pow10(-3)},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"ipmi_sensor",
+					map[string]string{
+						"server":      "127.0.0.1",
+						"name":        "fan_1",
+						"entity_id":   "29.1",
+						"unit":        "percent",
+						"status_code": "ok",
+					},
+					map[string]interface{}{"value": 40.0},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"ipmi_sensor",
+					map[string]string{
+						"server":      "127.0.0.1",
+						"name":        "inlet_temp",
+						"entity_id":   "64.1",
+						"unit":        "degrees_c",
+						"status_code": "ok",
+					},
+					map[string]interface{}{"value": -10.0},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"ipmi_sensor",
+					map[string]string{
+						"server":      "127.0.0.1",
+						"name":        "psu_status",
+						"entity_id":   "10.1",
+						"status_code": "ok",
+					},
+					map[string]interface{}{"state": uint64(1)},
+					time.Unix(0, 0),
+				),
+			}
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+			// The session and the sensor records are reused
+			reads := bmc.sdrReads()
+			acc.ClearMetrics()
+			require.NoError(t, plugin.Gather(&acc))
+			require.Empty(t, acc.Errors)
+			require.Len(t, acc.GetTelegrafMetrics(), len(expected))
+			require.Equal(t, 1, bmc.sessionCount())
+			require.Equal(t, reads, bmc.sdrReads())
+		})
+	}
+}
+
+func TestGatherSEL(t *testing.T) {
+	bmc := newMockBMC(t, 3)
+	plugin := bmc.plugin("sel")
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ipmi_sel",
+			map[string]string{"server": "127.0.0.1"},
+			map[string]interface{}{
+				"entries":    uint64(2),
+				"free_bytes": uint64(16352),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ipmi_sel_entry",
+			map[string]string{
+				"server":          "127.0.0.1",
+				"sensor_type":     "temperature",
+				"event_direction": "assertion",
+			},
+			map[string]interface{}{
+				"record_id":     uint64(1),
+				"record_type":   uint64(2),
+				"generator_id":  uint64(0x20),
+				"sensor_number": uint64(1),
+				"event_type":    uint64(1),
+				"event_offset":  uint64(7),
+				"event_data":    "57505a",
+			},
+			time.Unix(1700000000, 0),
+		),
+		metric.New(
+			"ipmi_sel_entry",
+			map[string]string{"server": "127.0.0.1"},
+			map[string]interface{}{
+				"record_id":   uint64(2),
+				"record_type": uint64(0xe0),
+				"data":        "0200e0010203040506070809101112ff",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	actual := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+	require.Equal(t, time.Unix(1700000000, 0), actual[1].Time())
+
+	// Unchanged logs only report the log info
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected[:1], acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// Only new entries are reported
+	bmc.addSELEntry(selRecord(3, 1700000100, 0x01, 0x02, 0x81, 0x07))
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected = []telegraf.Metric{
+		metric.New(
+			"ipmi_sel",
+			map[string]string{"server": "127.0.0.1"},
+			map[string]interface{}{
+				"entries":    uint64(3),
+				"free_bytes": uint64(16336),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ipmi_sel_entry",
+			map[string]string{
+				"server":          "127.0.0.1",
+				"sensor_type":     "temperature",
+				"event_direction": "deassertion",
+			},
+			map[string]interface{}{
+				"record_id":     uint64(3),
+				"record_type":   uint64(2),
+				"generator_id":  uint64(0x20),
+				"sensor_number": uint64(2),
+				"event_type":    uint64(1),
+				"event_offset":  uint64(7),
+				"event_data":    "07505a",
+			},
+			time.Unix(1700000100, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherFRU(t *testing.T) {
+	bmc := newMockBMC(t, 3)
+	plugin := bmc.plugin("fru")
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ipmi_fru",
+			map[string]string{
+				"server": "127.0.0.1",
+				"fru_id": "0",
+			},
+			map[string]interface{}{
+				"chassis_part_number":  "CPN1",
+				"chassis_serial":       "CSN1",
+				"board_manufacturer":   "ACME",
+				"board_product":        "X11",
+				"board_serial":         "SN123",
+				"board_part_number":    "PN-1",
+				"product_manufacturer": "ACME",
+				"product_name":         "Server",
+				"product_part_number":  "ABC1",
+				"product_version":      "1.2",
+				"product_serial":       "0a0b",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherReconnect(t *testing.T) {
+	bmc := newMockBMC(t, 3)
+	plugin := bmc.plugin("sensors")
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 5)
+
+	// Sessions dropped by the BMC are reestablished
+	bmc.dropSession()
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 5)
+	require.Equal(t, 2, bmc.sessionCount())
+
+	// Idle sessions are reestablished
+	plugin.IdleTimeout = config.Duration(time.Nanosecond)
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 5)
+	require.Equal(t, 3, bmc.sessionCount())
+}
+
+func TestStopClosesSessions(t *testing.T) {
+	bmc := newMockBMC(t, 3)
+	plugin := bmc.plugin("sensors")
+
+	// The agent only stops service inputs, so run the plugin like the agent
+	model := models.NewRunningInput(plugin, &models.InputConfig{Name: "ipmi"})
+	require.NoError(t, model.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, model.Start(&acc))
+	require.NoError(t, model.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.True(t, bmc.sessionActive())
+
+	model.Stop()
+	require.False(t, bmc.sessionActive())
+}
+
+func TestGatherAuthenticationFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		password string
+		expected string
+	}{
+		{
+			name:     "invalid password",
+			username: "admin",
+			password: "invalid",
+			expected: "invalid RAKP 2 authentication code",
+		},
+		{
+			name:     "unknown user",
+			username: "root",
+			password: "secret",
+			expected: "RAKP 2 failed: unauthorized name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bmc := newMockBMC(t, 3)
+			plugin := bmc.plugin("sensors")
+			plugin.Username = config.NewSecret([]byte(tt.username))
+			plugin.Password = config.NewSecret([]byte(tt.password))
+			require.NoError(t, plugin.Init())
+			defer plugin.Stop()
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Gather(&acc))
+			require.Len(t, acc.Errors, 1)
+			require.ErrorContains(t, acc.Errors[0], tt.expected)
+			require.Empty(t, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestGatherTimeout(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	plugin := &IPMI{
+		Servers:  []string{conn.LocalAddr().String()},
+		Username: config.NewSecret([]byte("admin")),
+		Password: config.NewSecret([]byte("secret")),
+		Timeout:  config.Duration(50 * time.Millisecond),
+		Retries:  1,
+		Log:      &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "timeout waiting for response")
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		record   *sensorRecord
+		raw      uint8
+		expected float64
+	}{
+		{
+			name:     "unsigned",
+			record:   &sensorRecord{m: 2, b: 10},
+			raw:      100,
+			expected: 210,
+		},
+		{
+			name:     "ones complement",
+			record:   &sensorRecord{format: 0x01, m: 1},
+			raw:      0xfe,
+			expected: -1,
+		},
+		{
+			name:     "twos complement",
+			record:   &sensorRecord{format: 0x02, m: 1},
+			raw:      0xfe,
+			expected: -2,
+		},
+		{
+			name:     "exponents",
+			record:   &sensorRecord{m: 5, b: 3, bexp: 2, rexp: 1},
+			raw:      4,
+			expected: 3200,
+		},
+		{
+			name:     "negative coefficients",
+			record:   &sensorRecord{m: -1, b: 100},
+			raw:      20,
+			expected: 80,
+		},
+		{
+			name:     "square",
+			record:   &sensorRecord{m: 1, linearization: 0x08},
+			raw:      12,
+			expected: 144,
+		},
+		{
+			name:     "inverse",
+			record:   &sensorRecord{m: 1, linearization: 0x07},
+			raw:      4,
+			expected: 0.25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.InDelta(t, tt.expected, tt.record.convert(tt.raw), 1e-9)
+		})
+	}
+}
+
+func TestParseSDRCoefficients(t *testing.T) {
+	record := fullSensor(1, 1, 7, 1, 0x02, 0x00, 4, -300, -2, -4, 3, "V")
+	r, err := parseSDR(record)
+	require.NoError(t, err)
+	require.Equal(t, int16(-300), r.m)
+	require.Equal(t, int16(-2), r.b)
+	require.Equal(t, int8(-4), r.rexp)
+	require.Equal(t, int8(3), r.bexp)
+	require.True(t, r.analog)
+
+	_, err = parseSDR(record[:20])
+	require.ErrorContains(t, err, "truncated record of type 0x01")
+
+	r, err = parseSDR([]byte{0x01, 0x00, 0x51, 0x12, 0x01, 0x20})
+	require.NoError(t, err)
+	require.Nil(t, r)
+}
+
+func TestUnitTables(t *testing.T) {
+	require.Len(t, sensorUnits, 93)
+	require.Equal(t, "grams", unitName(92))
+	require.Equal(t, "unknown", unitName(93))
+	require.Len(t, sensorTypes, 0x2d)
+	require.Equal(t, "fru_state", sensorTypeName(0x2c))
+	require.Equal(t, "oem", sensorTypeName(0xc0))
+
+	r := &sensorRecord{baseUnit: 18, modifierUnit: 23, modifierOp: 0x01}
+	require.Equal(t, "rpm_per_minute", r.unit())
+}
+
+func TestPacketIntegrity(t *testing.T) {
+	keys := newSessionKeys(cipherSuites[3], []byte("session integrity key"))
+	p := &packet{
+		payloadType: payloadIPMI | payloadAuthenticated | payloadEncrypted,
+		sessionID:   0x01020304,
+		sequence:    7,
+		payload:     []byte("some payload"),
+	}
+	buf, err := encodePacket(p, keys)
+	require.NoError(t, err)
+
+	decoded, err := decodePacket(buf, keys)
+	require.NoError(t, err)
+	require.Equal(t, uint8(payloadIPMI), decoded.payloadType)
+	require.Equal(t, p.sessionID, decoded.sessionID)
+	require.Equal(t, p.sequence, decoded.sequence)
+	require.Equal(t, p.payload, decoded.payload)
+
+	// Modified packets are rejected
+	buf[20] ^= 0x01
+	_, err = decodePacket(buf, keys)
+	require.ErrorContains(t, err, "integrity check failed")
+}
+
+func cipherSuiteName(id int) string {
+	if id == 17 {
+		return "cipher suite 17"
+	}
+	return "cipher suite 3"
+}
+
+// mockBMC implements the server side of IPMI v2.0 sessions and the commands
+// used by the plugin
+type mockBMC struct {
+	t        *testing.T
+	conn     *net.UDPConn
+	suite    *cipherSuite
+	username string
+	password string
+	guid     []byte
+
+	sdrs     [][]byte
+	readings map[uint8][]byte
+	fru      []byte
+
+	sync.Mutex
+	sel         [][]byte
+	selAddition uint32
+	sessions    int
+	sdrReadsCnt int
+	consoleID   []byte
+	bmcID       []byte
+	rm          []byte
+	rc          []byte
+	userInfo    []byte
+	keys        *sessionKeys
+	active      bool
+}
+
+func newMockBMC(t *testing.T, suite int) *mockBMC {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+
+	m := &mockBMC{
+		t:        t,
+		conn:     conn,
+		suite:    cipherSuites[suite],
+		username: "admin",
+		password: "secret",
+		guid:     bytes.Repeat([]byte{0xa5}, 16),
+		bmcID:    []byte{0x44, 0x33, 0x22, 0x11},
+		sdrs: [][]byte{
+			fullSensor(1, 0x01, 3, 1, 0x01, 0x00, 1, 1, 0, 0, 0, "CPU Temp"),
+			fullSensor(2, 0x02, 7, 1, 0x02, 0x00, 4, 63, 0, -3, 0, "12V"),
+			{0x03, 0x00, 0x51, 0x12, 0x06, 0x20, 0x00, 0x00, 0x00, 0x00, 0x00}, // MC device locator
+			fullSensor(4, 0x03, 29, 1, 0x04, 0x01, 0, 1, 0, 0, 0, "Fan 1"),
+			fullSensor(5, 0x04, 64, 1, 0x01, 0x80, 1, 1, 0, 0, 0, "Inlet Temp"),
+			compactSensor(6, 0x05, 10, 1, 0x08, 0x6f, "PSU Status"),
+			compactSensor(7, 0x06, 10, 2, 0x08, 0x6f, "PSU2 Status"),
+		},
+		readings: map[uint8][]byte{
+			0x01: {45, 0xc0, 0x00},
+			0x02: {190, 0xc0, 0x10},
+			0x03: {40, 0xc0, 0x00},
+			0x04: {0xf6, 0xc0, 0x00},
+			0x05: {0x00, 0xc0, 0x01, 0x00},
+			0x06: {0x00, 0xe0, 0x00, 0x00}, // reading unavailable
+		},
+		sel: [][]byte{
+			selRecord(1, 1700000000, 0x01, 0x01, 0x01, 0x57),
+			{0x02, 0x00, 0xe0, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x10, 0x11, 0x12, 0xff},
+		},
+		selAddition: 1700000000,
+		fru:         testFRU(),
+	}
+	go m.serve()
+	t.Cleanup(func() { conn.Close() })
+
+	return m
+}
+
+func (m *mockBMC) plugin(collect ...string) *IPMI {
+	return &IPMI{
+		Servers:     []string{m.conn.LocalAddr().String()},
+		Username:    config.NewSecret([]byte(m.username)),
+		Password:    config.NewSecret([]byte(m.password)),
+		Privilege:   "administrator",
+		Collect:     collect,
+		Timeout:     config.Duration(100 * time.Millisecond),
+		IdleTimeout: config.Duration(time.Minute),
+		Log:         &testutil.Logger{},
+	}
+}
+
+func (m *mockBMC) sessionCount() int {
+	m.Lock()
+	defer m.Unlock()
+	return m.sessions
+}
+
+func (m *mockBMC) sessionActive() bool {
+	m.Lock()
+	defer m.Unlock()
+	return m.active
+}
+
+func (m *mockBMC) sdrReads() int {
+	m.Lock()
+	defer m.Unlock()
+	return m.sdrReadsCnt
+}
+
+func (m *mockBMC) dropSession() {
+	m.Lock()
+	defer m.Unlock()
+	m.active = false
+	m.keys = nil
+}
+
+func (m *mockBMC) addSELEntry(record []byte) {
+	m.Lock()
+	defer m.Unlock()
+	m.sel = append(m.sel, record)
+	m.selAddition = binary.LittleEndian.Uint32(record[3:])
+}
+
+func (m *mockBMC) serve() {
+	buf := make([]byte, 1024)
+	for {
+		n, addr, err := m.conn.ReadFromUDP(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			m.t.Error(err)
+			return
+		}
+
+		m.Lock()
+		reply := m.handle(buf[:n])
+		m.Unlock()
+		if reply == nil {
+			continue
+		}
+		if _, err := m.conn.WriteToUDP(reply, addr); err != nil {
+			m.t.Error(err)
+			return
+		}
+	}
+}
+
+func (m *mockBMC) handle(buf []byte) []byte {
+	// Packets of dropped sessions are ignored
+	if buf[4] == authTypeRMCPPlus && buf[5]&payloadAuthenticated != 0 && !m.active {
+		return nil
+	}
+
+	p, err := decodePacket(buf, m.keys)
+	if err != nil {
+		m.t.Errorf("decoding packet failed: %v", err)
+		return nil
+	}
+
+	if buf[4] == authTypeNone {
+		// Channel authentication capabilities with IPMI v2.0 support
+		return encodePacketV15(mockResponse(p.payload, 0, []byte{0x01, 0x80, 0x04, 0x02, 0, 0, 0, 0}))
+	}
+
+	var ptype uint8
+	var payload []byte
+	switch p.payloadType {
+	case payloadOpenSessionRequest:
+		m.consoleID = bytes.Clone(p.payload[4:8])
+		ptype = payloadOpenSessionResponse
+		payload = append([]byte{p.payload[0], 0, privilegeAdministrator, 0}, m.consoleID...)
+		payload = append(payload, m.bmcID...)
+		payload = append(payload, p.payload[8:32]...)
+	case payloadRAKP1:
+		ptype = payloadRAKP2
+		ulen := int(p.payload[27])
+		if string(p.payload[28:28+ulen]) != m.username {
+			payload = append([]byte{p.payload[0], 0x0d, 0, 0}, m.consoleID...)
+			break
+		}
+		m.rm = bytes.Clone(p.payload[8:24])
+		m.rc = bytes.Repeat([]byte{0x5a}, 16)
+		m.userInfo = append([]byte{p.payload[24], p.payload[27]}, p.payload[28:28+ulen]...)
+		payload = append([]byte{p.payload[0], 0, 0, 0}, m.consoleID...)
+		payload = append(payload, m.rc...)
+		payload = append(payload, m.guid...)
+		payload = append(payload, m.suite.hmac([]byte(m.password), m.consoleID, m.bmcID, m.rm, m.rc, m.guid, m.userInfo)...)
+	case payloadRAKP3:
+		ptype = payloadRAKP4
+		expected := m.suite.hmac([]byte(m.password), m.rc, m.consoleID, m.userInfo)
+		if !bytes.Equal(p.payload[8:], expected) {
+			payload = append([]byte{p.payload[0], 0x0f, 0, 0}, m.consoleID...)
+			break
+		}
+		sik := m.suite.hmac([]byte(m.password), m.rm, m.rc, m.userInfo)
+		payload = append([]byte{p.payload[0], 0, 0, 0}, m.consoleID...)
+		payload = append(payload, m.suite.hmac(sik, m.rm, m.bmcID, m.guid)[:m.suite.integrityLen]...)
+		m.keys = newSessionKeys(m.suite, sik)
+		m.active = true
+		m.sessions++
+	case payloadIPMI:
+		if p.sessionID != binary.LittleEndian.Uint32(m.bmcID) {
+			m.t.Errorf("unexpected session ID 0x%08x", p.sessionID)
+			return nil
+		}
+		ptype = payloadIPMI | payloadAuthenticated | payloadEncrypted
+		payload = m.command(p.payload)
+	default:
+		m.t.Errorf("unexpected payload type 0x%02x", p.payloadType)
+		return nil
+	}
+
+	reply, err := encodePacket(&packet{
+		payloadType: ptype,
+		sessionID:   binary.LittleEndian.Uint32(m.consoleID),
+		payload:     payload,
+	}, m.keys)
+	if err != nil {
+		m.t.Errorf("encoding packet failed: %v", err)
+		return nil
+	}
+	if p.payloadType == payloadIPMI && payload[5] == cmdCloseSession {
+		m.active = false
+		m.keys = nil
+	}
+	return reply
+}
+
+func (m *mockBMC) command(msg []byte) []byte {
+	netFn, cmd, data := msg[1]>>2, msg[5], msg[6:len(msg)-1]
+
+	switch {
+	case netFn == netFnApp && cmd == cmdSetSessionPrivilegeLevel:
+		return mockResponse(msg, 0, data)
+	case netFn == netFnApp && cmd == cmdCloseSession:
+		return mockResponse(msg, 0, nil)
+	case netFn == netFnStorage && cmd == cmdGetSDRRepositoryInfo:
+		resp := []byte{0x51, byte(len(m.sdrs)), 0, 0, 0x10, 0x00, 0x00, 0x00, 0x65, 0, 0, 0, 0, 0}
+		return mockResponse(msg, 0, resp)
+	case netFn == netFnStorage && cmd == cmdReserveSDRRepository:
+		return mockResponse(msg, 0, []byte{0x01, 0x00})
+	case netFn == netFnStorage && cmd == cmdGetSDR:
+		index := int(binary.LittleEndian.Uint16(data[2:]))
+		if index > 0 {
+			index--
+		}
+		if index >= len(m.sdrs) {
+			return mockResponse(msg, 0xcb, nil)
+		}
+		if index == 0 && data[4] == 0 {
+			m.sdrReadsCnt++
+		}
+		next := uint16(index + 2)
+		if index == len(m.sdrs)-1 {
+			next = 0xffff
+		}
+		record := m.sdrs[index]
+		offset, count := int(data[4]), int(data[5])
+		resp := binary.LittleEndian.AppendUint16(nil, next)
+		resp = append(resp, record[offset:min(offset+count, len(record))]...)
+		return mockResponse(msg, 0, resp)
+	case netFn == netFnSensor && cmd == cmdGetSensorReading:
+		reading, found := m.readings[data[0]]
+		if !found {
+			return mockResponse(msg, 0xcb, nil)
+		}
+		return mockResponse(msg, 0, reading)
+	case netFn == netFnStorage && cmd == cmdGetSELInfo:
+		resp := []byte{0x51}
+		resp = binary.LittleEndian.AppendUint16(resp, uint16(len(m.sel)))
+		resp = binary.LittleEndian.AppendUint16(resp, uint16(16384-16*len(m.sel)))
+		resp = binary.LittleEndian.AppendUint32(resp, m.selAddition)
+		resp = binary.LittleEndian.AppendUint32(resp, 0)
+		resp = append(resp, 0x02)
+		return mockResponse(msg, 0, resp)
+	case netFn == netFnStorage && cmd == cmdGetSELEntry:
+		id := binary.LittleEndian.Uint16(data[2:])
+		for i, record := range m.sel {
+			if id != 0 && binary.LittleEndian.Uint16(record) != id {
+				continue
+			}
+			next := uint16(0xffff)
+			if i < len(m.sel)-1 {
+				next = binary.LittleEndian.Uint16(m.sel[i+1])
+			}
+			return mockResponse(msg, 0, append(binary.LittleEndian.AppendUint16(nil, next), record...))
+		}
+		return mockResponse(msg, 0xcb, nil)
+	case netFn == netFnStorage && cmd == cmdGetFRUInventoryAreaInfo:
+		return mockResponse(msg, 0, []byte{byte(len(m.fru)), byte(len(m.fru) >> 8), 0x00})
+	case netFn == netFnStorage && cmd == cmdReadFRUData:
+		offset, count := int(binary.LittleEndian.Uint16(data[1:])), int(data[3])
+		chunk := m.fru[offset:min(offset+count, len(m.fru))]
+		return mockResponse(msg, 0, append([]byte{byte(len(chunk))}, chunk...))
+	}
+
+	m.t.Errorf("unexpected command 0x%02x/0x%02x", netFn, cmd)
+	return mockResponse(msg, 0xc1, nil)
+}
+
+// mockResponse creates the response message to the given request message
+func mockResponse(req []byte, code uint8, data []byte) []byte {
+	buf := []byte{consoleAddress, (req[1]>>2+1)<<2 | req[1]&0x03, 0, bmcAddress, req[4], req[5], code}
+	buf[2] = checksum(buf[:2])
+	buf = append(buf, data...)
+	return append(buf, checksum(buf[3:]))
+}
+
+func fullSensor(id uint16, number, entity, instance, sensorType, units1, baseUnit uint8, m, b int, rexp, bexp int8, name string) []byte {
+	record := make([]byte, 48+len(name))
+	binary.LittleEndian.PutUint16(record, id)
+	record[2] = 0x51
+	record[3] = sdrFullSensor
+	record[4] = byte(len(record) - 5)
+	record[5] = bmcAddress
+	record[7] = number
+	record[8] = entity
+	record[9] = instance
+	record[12] = sensorType
+	record[13] = readingTypeThreshold
+	record[20] = units1
+	record[21] = baseUnit
+	record[24] = byte(m)
+	record[25] = byte(m>>2) & 0xc0
+	record[26] = byte(b)
+	record[27] = byte(b>>2) & 0xc0
+	record[29] = byte(rexp)<<4 | byte(bexp)&0x0f
+	record[47] = 0xc0 | byte(len(name))
+	copy(record[48:], name)
+	return record
+}
+
+func compactSensor(id uint16, number, entity, instance, sensorType, readingType uint8, name string) []byte {
+	record := make([]byte, 32+len(name))
+	binary.LittleEndian.PutUint16(record, id)
+	record[2] = 0x51
+	record[3] = sdrCompactSensor
+	record[4] = byte(len(record) - 5)
+	record[5] = bmcAddress
+	record[7] = number
+	record[8] = entity
+	record[9] = instance
+	record[12] = sensorType
+	record[13] = readingType
+	record[31] = 0xc0 | byte(len(name))
+	copy(record[32:], name)
+	return record
+}
+
+func selRecord(id uint16, timestamp uint32, sensorType, number, eventType, data1 uint8) []byte {
+	record := binary.LittleEndian.AppendUint16(nil, id)
+	record = append(record, selSystemEvent)
+	record = binary.LittleEndian.AppendUint32(record, timestamp)
+	record = append(record, 0x20, 0x00, 0x04, sensorType, number, eventType, data1, 0x50, 0x5a)
+	return record
+}
+
+// testFRU creates FRU inventory data using 8-bit ASCII, 6-bit packed ASCII,
+// BCD plus and binary encoded fields
+func testFRU() []byte {
+	area := func(prefix []byte, fields ...[]byte) []byte {
+		data := append([]byte{0x01, 0x00}, prefix...)
+		for _, f := range fields {
+			data = append(data, f...)
+		}
+		data = append(data, 0xc1)
+		for (len(data)+1)%8 != 0 {
+			data = append(data, 0x00)
+		}
+		data[1] = byte((len(data) + 1) / 8)
+		return append(data, checksum(data))
+	}
+	ascii := func(s string) []byte {
+		return append([]byte{0xc0 | byte(len(s))}, s...)
+	}
+
+	chassis := area([]byte{0x17}, ascii("CPN1"), ascii("CSN1"))
+	board := area([]byte{0x00, 0x00, 0x00, 0x00}, ascii("ACME"), ascii("X11"), ascii("SN123"), ascii("PN-1"))
+	product := area([]byte{0x00},
+		ascii("ACME"),
+		ascii("Server"),
+		[]byte{0x83, 0xa1, 0x38, 0x46}, // 6-bit packed "ABC1"
+		[]byte{0x42, 0x1c, 0x2a},       // BCD plus "1.2 "
+		[]byte{0x02, 0x0a, 0x0b},       // binary
+	)
+
+	header := []byte{0x01, 0x00, 1, byte(1 + len(chassis)/8), byte(1 + (len(chassis)+len(board))/8), 0x00, 0x00}
+	header = append(header, checksum(header))
+
+	data := append(header, chassis...)
+	data = append(data, board...)
+	return append(data, product...)
+}
@@ -0,0 +1,307 @@
+package ipmi
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // required by cipher suite 3 of the IPMI v2.0 specification
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// RMCP header and IPMI session header constants
+const (
+	rmcpVersion   = 0x06
+	rmcpNoAck     = 0xff
+	rmcpClassIPMI = 0x07
+
+	authTypeNone     = 0x00
+	authTypeRMCPPlus = 0x06
+
+	payloadIPMI                = 0x00
+	payloadOpenSessionRequest  = 0x10
+	payloadOpenSessionResponse = 0x11
+	payloadRAKP1               = 0x12
+	payloadRAKP2               = 0x13
+	payloadRAKP3               = 0x14
+	payloadRAKP4               = 0x15
+
+	payloadEncrypted     = 0x80
+	payloadAuthenticated = 0x40
+	payloadTypeMask      = 0x3f
+
+	nextHeaderIPMI = 0x07
+)
+
+// Addresses of the BMC and the remote console in IPMI messages
+const (
+	bmcAddress     = 0x20
+	consoleAddress = 0x81
+)
+
+// cipherSuite contains the algorithms used for authentication, integrity and
+// confidentiality of a session
+type cipherSuite struct {
+	authAlg      uint8
+	integrityAlg uint8
+	confAlg      uint8
+	hash         func() hash.Hash
+	integrityLen int
+}
+
+// Supported cipher suites, both use AES-CBC-128 for encrypting the payload
+var cipherSuites = map[int]*cipherSuite{
+	// RAKP-HMAC-SHA1, HMAC-SHA1-96
+	3: {authAlg: 0x01, integrityAlg: 0x01, confAlg: 0x01, hash: sha1.New, integrityLen: 12},
+	// RAKP-HMAC-SHA256, HMAC-SHA256-128
+	17: {authAlg: 0x03, integrityAlg: 0x04, confAlg: 0x01, hash: sha256.New, integrityLen: 16},
+}
+
+func (c *cipherSuite) hmac(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(c.hash, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+// sessionKeys contain the keys derived from the session integrity key to
+// authenticate and encrypt the packets of an active session
+type sessionKeys struct {
+	suite *cipherSuite
+	k1    []byte
+	k2    []byte
+}
+
+func newSessionKeys(suite *cipherSuite, sik []byte) *sessionKeys {
+	const1 := make([]byte, 20)
+	const2 := make([]byte, 20)
+	for i := range const1 {
+		const1[i] = 0x01
+		const2[i] = 0x02
+	}
+	return &sessionKeys{
+		suite: suite,
+		k1:    suite.hmac(sik, const1),
+		k2:    suite.hmac(sik, const2),
+	}
+}
+
+// packet is a decoded IPMI v2.0 (RMCP+) or IPMI v1.5 packet
+type packet struct {
+	payloadType uint8
+	sessionID   uint32
+	sequence    uint32
+	payload     []byte
+}
+
+// encodePacket serializes the packet and, for authenticated or encrypted
+// payload types, protects it with the given session keys
+func encodePacket(p *packet, keys *sessionKeys) ([]byte, error) {
+	payload := p.payload
+	if p.payloadType&payloadEncrypted != 0 {
+		var err error
+		if payload, err = keys.encrypt(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	buf := make([]byte, 16, 16+len(payload)+32)
+	buf[0] = rmcpVersion
+	buf[2] = rmcpNoAck
+	buf[3] = rmcpClassIPMI
+	buf[4] = authTypeRMCPPlus
+	buf[5] = p.payloadType
+	binary.LittleEndian.PutUint32(buf[6:], p.sessionID)
+	binary.LittleEndian.PutUint32(buf[10:], p.sequence)
+	binary.LittleEndian.PutUint16(buf[14:], uint16(len(payload)))
+	buf = append(buf, payload...)
+
+	if p.payloadType&payloadAuthenticated != 0 {
+		// Pad the data covered by the integrity check, starting at the
+		// authentication type and ending at the next header, to a multiple
+		// of four bytes
+		pad := (4 - (len(buf)-4+2)%4) % 4
+		for range pad {
+			buf = append(buf, 0xff)
+		}
+		buf = append(buf, byte(pad), nextHeaderIPMI)
+		buf = append(buf, keys.suite.hmac(keys.k1, buf[4:])[:keys.suite.integrityLen]...)
+	}
+	return buf, nil
+}
+
+// encodePacketV15 serializes an IPMI v1.5 packet outside of a session as
+// used for querying the channel authentication capabilities
+func encodePacketV15(msg []byte) []byte {
+	buf := make([]byte, 14, 14+len(msg))
+	buf[0] = rmcpVersion
+	buf[2] = rmcpNoAck
+	buf[3] = rmcpClassIPMI
+	buf[4] = authTypeNone
+	buf[13] = byte(len(msg))
+	return append(buf, msg...)
+}
+
+// decodePacket parses an IPMI packet and, for authenticated or encrypted
+// payloads, checks and decrypts it using the given session keys
+func decodePacket(buf []byte, keys *sessionKeys) (*packet, error) {
+	if len(buf) < 5 || buf[0] != rmcpVersion || buf[3] != rmcpClassIPMI {
+		return nil, errors.New("not an IPMI packet")
+	}
+
+	switch buf[4] {
+	case authTypeNone:
+		if len(buf) < 14 || len(buf) < 14+int(buf[13]) {
+			return nil, errors.New("truncated IPMI v1.5 packet")
+		}
+		return &packet{
+			payloadType: payloadIPMI,
+			sequence:    binary.LittleEndian.Uint32(buf[5:]),
+			sessionID:   binary.LittleEndian.Uint32(buf[9:]),
+			payload:     buf[14 : 14+int(buf[13])],
+		}, nil
+	case authTypeRMCPPlus:
+	default:
+		return nil, fmt.Errorf("unsupported authentication type %d", buf[4])
+	}
+
+	if len(buf) < 16 {
+		return nil, errors.New("truncated IPMI v2.0 packet")
+	}
+	p := &packet{
+		payloadType: buf[5],
+		sessionID:   binary.LittleEndian.Uint32(buf[6:]),
+		sequence:    binary.LittleEndian.Uint32(buf[10:]),
+	}
+	length := int(binary.LittleEndian.Uint16(buf[14:]))
+	if len(buf) < 16+length {
+		return nil, errors.New("truncated IPMI v2.0 payload")
+	}
+	p.payload = buf[16 : 16+length]
+
+	if p.payloadType&(payloadAuthenticated|payloadEncrypted) != 0 && keys == nil {
+		return nil, errors.New("protected payload outside of a session")
+	}
+
+	if p.payloadType&payloadAuthenticated != 0 {
+		n := keys.suite.integrityLen
+		if len(buf) < 16+length+2+n {
+			return nil, errors.New("truncated IPMI v2.0 session trailer")
+		}
+		end := len(buf) - n
+		expected := keys.suite.hmac(keys.k1, buf[4:end])[:n]
+		if !hmac.Equal(buf[end:], expected) {
+			return nil, errors.New("integrity check failed")
+		}
+	}
+
+	if p.payloadType&payloadEncrypted != 0 {
+		var err error
+		if p.payload, err = keys.decrypt(p.payload); err != nil {
+			return nil, err
+		}
+	}
+	p.payloadType &= payloadTypeMask
+
+	return p, nil
+}
+
+// encrypt encrypts the data using AES-CBC-128 with a random IV prepended to
+// the cipher text
+func (k *sessionKeys) encrypt(data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(k.k2[:16])
+	if err != nil {
+		return nil, err
+	}
+
+	pad := (aes.BlockSize - (len(data)+1)%aes.BlockSize) % aes.BlockSize
+	plain := make([]byte, 0, len(data)+pad+1)
+	plain = append(plain, data...)
+	for i := range pad {
+		plain = append(plain, byte(i+1))
+	}
+	plain = append(plain, byte(pad))
+
+	out := make([]byte, aes.BlockSize+len(plain))
+	if _, err := rand.Read(out[:aes.BlockSize]); err != nil {
+		return nil, err
+	}
+	cipher.NewCBCEncrypter(block, out[:aes.BlockSize]).CryptBlocks(out[aes.BlockSize:], plain)
+	return out, nil
+}
+
+// decrypt decrypts the data encrypted using AES-CBC-128 and removes the
+// confidentiality trailer
+func (k *sessionKeys) decrypt(data []byte) ([]byte, error) {
+	if len(data) < 2*aes.BlockSize || len(data)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid length of encrypted payload")
+	}
+	block, err := aes.NewCipher(k.k2[:16])
+	if err != nil {
+		return nil, err
+	}
+
+	plain := make([]byte, len(data)-aes.BlockSize)
+	cipher.NewCBCDecrypter(block, data[:aes.BlockSize]).CryptBlocks(plain, data[aes.BlockSize:])
+	pad := int(plain[len(plain)-1])
+	if pad >= len(plain) {
+		return nil, errors.New("invalid padding of encrypted payload")
+	}
+	return plain[:len(plain)-pad-1], nil
+}
+
+// request is an IPMI request message sent to the BMC
+type request struct {
+	netFn    uint8
+	lun      uint8
+	sequence uint8
+	command  uint8
+	data     []byte
+}
+
+func (r *request) encode() []byte {
+	buf := []byte{bmcAddress, r.netFn<<2 | r.lun&0x03, 0, consoleAddress, r.sequence << 2, r.command}
+	buf[2] = checksum(buf[:2])
+	buf = append(buf, r.data...)
+	return append(buf, checksum(buf[3:]))
+}
+
+// response is an IPMI response message received from the BMC
+type response struct {
+	netFn          uint8
+	sequence       uint8
+	command        uint8
+	completionCode uint8
+	data           []byte
+}
+
+func decodeResponse(buf []byte) (*response, error) {
+	if len(buf) < 8 {
+		return nil, errors.New("truncated IPMI message")
+	}
+	if checksum(buf[:3]) != 0 || checksum(buf[3:]) != 0 {
+		return nil, errors.New("invalid IPMI message checksum")
+	}
+	return &response{
+		netFn:          buf[1] >> 2,
+		sequence:       buf[4] >> 2,
+		command:        buf[5],
+		completionCode: buf[6],
+		data:           buf[7 : len(buf)-1],
+	}, nil
+}
+
+// checksum computes the two's complement checksum of the data, so the sum of
+// the data and the checksum is zero
+func checksum(data []byte) uint8 {
+	var sum uint8
+	for _, b := range data {
+		sum += b
+	}
+	return -sum
+}
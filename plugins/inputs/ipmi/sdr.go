@@ -0,0 +1,350 @@
+package ipmi
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Types of the sensor data records
+const (
+	sdrFullSensor    = 0x01
+	sdrCompactSensor = 0x02
+)
+
+// Event/reading type code of threshold based sensors
+const readingTypeThreshold = 0x01
+
+// Units of analog sensors indexed by the unit type code
+var sensorUnits = []string{
+	"unspecified", "degrees_c", "degrees_f", "degrees_k", "volts", "amps",
+	"watts", "joules", "coulombs", "va", "nits", "lumen", "lux", "candela",
+	"kpa", "psi", "newton", "cfm", "rpm", "hz", "microsecond", "millisecond",
+	"second", "minute", "hour", "day", "week", "mil", "inches", "feet", "cu_in",
+	"cu_feet", "mm", "cm", "m", "cu_cm", "cu_m", "liters", "fluid_ounce",
+	"radians", "steradians", "revolutions", "cycles", "gravities", "ounce",
+	"pound", "ft-lb", "oz-in", "gauss", "gilberts", "henry", "millihenry",
+	"farad", "microfarad", "ohms", "siemens", "mole", "becquerel", "ppm",
+	"reserved", "decibels", "dba", "dbc", "gray", "sievert", "color_temp_deg_k",
+	"bit", "kilobit", "megabit", "gigabit", "byte", "kilobyte", "megabyte",
+	"gigabyte", "word", "dword", "qword", "line", "hit", "miss", "retry",
+	"reset", "overflow", "underrun", "collision", "packets", "messages",
+	"characters", "error", "correctable_error", "uncorrectable_error",
+	"fatal_error", "grams",
+}
+
+// sensorRecord contains the information of a full or compact sensor data
+// record required to read and convert the sensor values
+type sensorRecord struct {
+	owner          uint8
+	lun            uint8
+	number         uint8
+	entityID       uint8
+	entityInstance uint8
+	sensorType     uint8
+	readingType    uint8
+	name           string
+
+	// Conversion of analog readings, only set for full sensor records
+	analog        bool
+	format        uint8
+	percentage    bool
+	modifierOp    uint8
+	baseUnit      uint8
+	modifierUnit  uint8
+	linearization uint8
+	m             int16
+	b             int16
+	rexp          int8
+	bexp          int8
+}
+
+// parseSDR decodes a sensor data record, records of other types than full
+// and compact sensor records are skipped by returning nil
+func parseSDR(data []byte) (*sensorRecord, error) {
+	if len(data) < 5 {
+		return nil, errors.New("truncated record header")
+	}
+
+	var nameOffset int
+	switch data[3] {
+	case sdrFullSensor:
+		nameOffset = 47
+	case sdrCompactSensor:
+		nameOffset = 31
+	default:
+		return nil, nil
+	}
+	if len(data) <= nameOffset {
+		return nil, fmt.Errorf("truncated record of type 0x%02x", data[3])
+	}
+
+	r := &sensorRecord{
+		owner:          data[5],
+		lun:            data[6] & 0x03,
+		number:         data[7],
+		entityID:       data[8],
+		entityInstance: data[9] & 0x7f,
+		sensorType:     data[12],
+		readingType:    data[13],
+	}
+	length := min(int(data[nameOffset]&0x1f), len(data)-nameOffset-1)
+	r.name = strings.TrimRight(string(data[nameOffset+1:nameOffset+1+length]), "\x00 ")
+
+	if data[3] == sdrFullSensor {
+		r.format = data[20] >> 6
+		r.analog = r.format != 0x03 && r.readingType == readingTypeThreshold
+		r.percentage = data[20]&0x01 != 0
+		r.modifierOp = (data[20] >> 1) & 0x03
+		r.baseUnit = data[21]
+		r.modifierUnit = data[22]
+		r.linearization = data[23] & 0x7f
+		r.m = signExtend(uint16(data[24])|uint16(data[25]&0xc0)<<2, 10)
+		r.b = signExtend(uint16(data[26])|uint16(data[27]&0xc0)<<2, 10)
+		r.rexp = int8(signExtend(uint16(data[29]>>4), 4))
+		r.bexp = int8(signExtend(uint16(data[29]&0x0f), 4))
+	}
+
+	return r, nil
+}
+
+// signExtend interprets the lower bits of the value as signed integer
+func signExtend(v uint16, bits int) int16 {
+	shift := 16 - bits
+	return int16(v<<shift) >> shift
+}
+
+// entity returns the entity ID in the format used by ipmitool
+func (r *sensorRecord) entity() string {
+	return fmt.Sprintf("%d.%d", r.entityID, r.entityInstance)
+}
+
+// unit returns the unit of analog sensors
+func (r *sensorRecord) unit() string {
+	if r.percentage {
+		return "percent"
+	}
+	unit := unitName(r.baseUnit)
+	switch r.modifierOp {
+	case 0x01:
+		return unit + "_per_" + unitName(r.modifierUnit)
+	case 0x02:
+		return unit + "_" + unitName(r.modifierUnit)
+	}
+	return unit
+}
+
+func unitName(code uint8) string {
+	if int(code) < len(sensorUnits) {
+		return sensorUnits[code]
+	}
+	return "unknown"
+}
+
+// convert computes the value of an analog reading using the formula
+// y = L[(M*x + B*10^Bexp) * 10^Rexp] of the sensor data record
+func (r *sensorRecord) convert(raw uint8) float64 {
+	var x float64
+	switch r.format {
+	case 0x01: // one's complement
+		if raw&0x80 != 0 {
+			x = -float64(^raw & 0x7f)
+		} else {
+			x = float64(raw)
+		}
+	case 0x02: // two's complement
+		x = float64(int8(raw))
+	default:
+		x = float64(raw)
+	}
+
+	y := (float64(r.m)*x + float64(r.b)*math.Pow10(int(r.bexp))) * math.Pow10(int(r.rexp))
+
+	switch r.linearization {
+	case 0x01:
+		y = math.Log(y)
+	case 0x02:
+		y = math.Log10(y)
+	case 0x03:
+		y = math.Log2(y)
+	case 0x04:
+		y = math.Exp(y)
+	case 0x05:
+		y = math.Pow(10, y)
+	case 0x06:
+		y = math.Exp2(y)
+	case 0x07:
+		y = 1 / y
+	case 0x08:
+		y *= y
+	case 0x09:
+		y = y * y * y
+	case 0x0a:
+		y = math.Sqrt(y)
+	case 0x0b:
+		y = math.Cbrt(y)
+	}
+	return y
+}
+
+// sensorReading is the state of a sensor returned by the BMC
+type sensorReading struct {
+	raw    uint8
+	status uint16
+}
+
+// statusCode returns the threshold status of the reading using the two
+// letter codes of ipmitool
+func (s *sensorReading) statusCode() string {
+	switch {
+	case s.status&0x24 != 0:
+		return "nr"
+	case s.status&0x12 != 0:
+		return "cr"
+	case s.status&0x09 != 0:
+		return "nc"
+	}
+	return "ok"
+}
+
+// sdrRepositoryTimestamp returns the timestamps of the most recent addition
+// and erase of the SDR repository to detect changes
+func (s *session) sdrRepositoryTimestamp() (uint64, error) {
+	data, err := s.command(netFnStorage, 0, cmdGetSDRRepositoryInfo, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) < 13 {
+		return 0, errors.New("invalid SDR repository info")
+	}
+	return binary.LittleEndian.Uint64(data[5:13]), nil
+}
+
+// readSDRs reads all sensor records from the SDR repository
+func (s *session) readSDRs() ([]*sensorRecord, error) {
+	reservation, err := s.reserve(cmdReserveSDRRepository)
+	if err != nil {
+		return nil, fmt.Errorf("reserving SDR repository failed: %w", err)
+	}
+
+	var records []*sensorRecord
+	id := uint16(0)
+	// Limit the number of records to protect against broken record chains
+	for i := 0; id != 0xffff && i < 0xffff; i++ {
+		next, data, err := s.readSDR(reservation, id)
+		var cerr *completionError
+		if errors.As(err, &cerr) && cerr.code == completionReservationCancelled {
+			// The repository changed, retry with a new reservation
+			if reservation, err = s.reserve(cmdReserveSDRRepository); err != nil {
+				return nil, fmt.Errorf("reserving SDR repository failed: %w", err)
+			}
+			next, data, err = s.readSDR(reservation, id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading SDR 0x%04x failed: %w", id, err)
+		}
+
+		r, err := parseSDR(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing SDR 0x%04x failed: %w", id, err)
+		}
+		if r != nil {
+			records = append(records, r)
+		}
+
+		if next == id {
+			break
+		}
+		id = next
+	}
+
+	return records, nil
+}
+
+// readSDR reads the record with the given ID in chunks as many BMCs are not
+// able to return the complete record at once
+func (s *session) readSDR(reservation, id uint16) (uint16, []byte, error) {
+	const chunkSize = 16
+
+	next, header, err := s.readSDRChunk(reservation, id, 0, 5)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(header) < 5 {
+		return 0, nil, errors.New("truncated record header")
+	}
+
+	total := 5 + int(header[4])
+	record := make([]byte, 0, total)
+	record = append(record, header[:5]...)
+	for len(record) < total {
+		offset := len(record)
+		_, chunk, err := s.readSDRChunk(reservation, id, offset, min(chunkSize, total-offset))
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(chunk) == 0 {
+			return 0, nil, errors.New("empty record chunk")
+		}
+		record = append(record, chunk[:min(len(chunk), total-offset)]...)
+	}
+
+	return next, record, nil
+}
+
+func (s *session) readSDRChunk(reservation, id uint16, offset, count int) (uint16, []byte, error) {
+	req := make([]byte, 6)
+	binary.LittleEndian.PutUint16(req[0:], reservation)
+	binary.LittleEndian.PutUint16(req[2:], id)
+	req[4] = byte(offset)
+	req[5] = byte(count)
+	data, err := s.command(netFnStorage, 0, cmdGetSDR, req)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(data) < 2 {
+		return 0, nil, errors.New("truncated response")
+	}
+	return binary.LittleEndian.Uint16(data), data[2:], nil
+}
+
+// reserve reserves the repository using the given command to guarantee
+// consistent reads
+func (s *session) reserve(cmd uint8) (uint16, error) {
+	data, err := s.command(netFnStorage, 0, cmd, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) < 2 {
+		return 0, errors.New("truncated reservation")
+	}
+	return binary.LittleEndian.Uint16(data), nil
+}
+
+// readSensor returns the current reading of the sensor, nil is returned if
+// the reading is unavailable
+func (s *session) readSensor(r *sensorRecord) (*sensorReading, error) {
+	data, err := s.command(netFnSensor, r.lun, cmdGetSensorReading, []byte{r.number})
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 2 {
+		return nil, errors.New("truncated sensor reading")
+	}
+
+	// Skip sensors with disabled scanning or unavailable readings
+	if data[1]&0x40 == 0 || data[1]&0x20 != 0 {
+		return nil, nil
+	}
+
+	reading := &sensorReading{raw: data[0]}
+	if len(data) > 2 {
+		reading.status = uint16(data[2])
+	}
+	if len(data) > 3 {
+		reading.status |= uint16(data[3]) << 8
+	}
+	return reading, nil
+}
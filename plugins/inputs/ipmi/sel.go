@@ -0,0 +1,145 @@
+package ipmi
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Record type of system event records in the SEL
+const selSystemEvent = 0x02
+
+// Timestamps below this value are relative to the BMC initialization
+const selTimestampMin = 0x20000000
+
+// Sensor types indexed by the sensor type code as used in SEL entries
+var sensorTypes = []string{
+	"reserved", "temperature", "voltage", "current", "fan",
+	"physical_security", "platform_security", "processor", "power_supply",
+	"power_unit", "cooling_device", "other_units_based_sensor", "memory",
+	"drive_slot", "post_memory_resize", "system_firmware_progress",
+	"event_logging_disabled", "watchdog_1", "system_event",
+	"critical_interrupt", "button_switch", "module_board",
+	"microcontroller_coprocessor", "add_in_card", "chassis", "chip_set",
+	"other_fru", "cable_interconnect", "terminator", "system_boot_initiated",
+	"boot_error", "os_boot", "os_critical_stop", "slot_connector",
+	"system_acpi_power_state", "watchdog_2", "platform_alert",
+	"entity_presence", "monitor_asic_ic", "lan", "management_subsystem_health",
+	"battery", "session_audit", "version_change", "fru_state",
+}
+
+func sensorTypeName(code uint8) string {
+	if int(code) < len(sensorTypes) {
+		return sensorTypes[code]
+	}
+	if code >= 0xc0 {
+		return "oem"
+	}
+	return "unknown"
+}
+
+// selInfo contains the state of the system event log
+type selInfo struct {
+	entries      uint16
+	free         uint16
+	lastAddition uint32
+	lastErase    uint32
+}
+
+// selEntry is a record of the system event log
+type selEntry struct {
+	id         uint16
+	recordType uint8
+	timestamp  uint32
+	data       []byte
+}
+
+// time returns the timestamp of the entry if it contains an absolute time
+func (e *selEntry) time() (time.Time, bool) {
+	// OEM records without timestamp
+	if e.recordType >= 0xe0 || e.timestamp < selTimestampMin || e.timestamp == 0xffffffff {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(e.timestamp), 0), true
+}
+
+// fields returns the tags and fields of the entry
+func (e *selEntry) fields() (map[string]string, map[string]interface{}) {
+	tags := make(map[string]string)
+	fields := map[string]interface{}{
+		"record_id":   uint64(e.id),
+		"record_type": uint64(e.recordType),
+	}
+
+	if e.recordType != selSystemEvent || len(e.data) < 16 {
+		fields["data"] = hex.EncodeToString(e.data)
+		return tags, fields
+	}
+
+	tags["sensor_type"] = sensorTypeName(e.data[10])
+	if e.data[12]&0x80 != 0 {
+		tags["event_direction"] = "deassertion"
+	} else {
+		tags["event_direction"] = "assertion"
+	}
+	fields["generator_id"] = uint64(binary.LittleEndian.Uint16(e.data[7:]))
+	fields["sensor_number"] = uint64(e.data[11])
+	fields["event_type"] = uint64(e.data[12] & 0x7f)
+	fields["event_offset"] = uint64(e.data[13] & 0x0f)
+	fields["event_data"] = hex.EncodeToString(e.data[13:16])
+	return tags, fields
+}
+
+func (s *session) readSELInfo() (*selInfo, error) {
+	data, err := s.command(netFnStorage, 0, cmdGetSELInfo, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 13 {
+		return nil, errors.New("invalid SEL info")
+	}
+	return &selInfo{
+		entries:      binary.LittleEndian.Uint16(data[1:]),
+		free:         binary.LittleEndian.Uint16(data[3:]),
+		lastAddition: binary.LittleEndian.Uint32(data[5:]),
+		lastErase:    binary.LittleEndian.Uint32(data[9:]),
+	}, nil
+}
+
+// readSEL reads all entries of the system event log
+func (s *session) readSEL() ([]*selEntry, error) {
+	var entries []*selEntry
+	id := uint16(0)
+	// Limit the number of records to protect against broken record chains
+	for i := 0; id != 0xffff && i < 0xffff; i++ {
+		req := []byte{0, 0, byte(id), byte(id >> 8), 0, 0xff}
+		data, err := s.command(netFnStorage, 0, cmdGetSELEntry, req)
+		if err != nil {
+			var cerr *completionError
+			if errors.As(err, &cerr) && cerr.code == 0xcb && id == 0 {
+				// The log is empty
+				return nil, nil
+			}
+			return nil, fmt.Errorf("reading SEL entry 0x%04x failed: %w", id, err)
+		}
+		if len(data) < 18 {
+			return nil, fmt.Errorf("truncated SEL entry 0x%04x", id)
+		}
+
+		entries = append(entries, &selEntry{
+			id:         binary.LittleEndian.Uint16(data[2:]),
+			recordType: data[4],
+			timestamp:  binary.LittleEndian.Uint32(data[5:]),
+			data:       data[2:18],
+		})
+
+		next := binary.LittleEndian.Uint16(data)
+		if next == id {
+			break
+		}
+		id = next
+	}
+	return entries, nil
+}
@@ -0,0 +1,362 @@
+package ipmi
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// Network functions and commands used by the plugin
+const (
+	netFnSensor  = 0x04
+	netFnApp     = 0x06
+	netFnStorage = 0x0a
+
+	cmdGetSensorReading = 0x2d
+
+	cmdGetChannelAuthCapabilities = 0x38
+	cmdSetSessionPrivilegeLevel   = 0x3b
+	cmdCloseSession               = 0x3c
+
+	cmdGetFRUInventoryAreaInfo = 0x10
+	cmdReadFRUData             = 0x11
+	cmdGetSDRRepositoryInfo    = 0x20
+	cmdReserveSDRRepository    = 0x22
+	cmdGetSDR                  = 0x23
+	cmdGetSELInfo              = 0x40
+	cmdGetSELEntry             = 0x43
+)
+
+// Privilege levels of a session
+const (
+	privilegeCallback      = 0x01
+	privilegeUser          = 0x02
+	privilegeOperator      = 0x03
+	privilegeAdministrator = 0x04
+)
+
+// Completion code returned if the reservation of a repository was cancelled
+const completionReservationCancelled = 0xc5
+
+// completionError is returned for responses with a non-zero completion code
+type completionError struct {
+	code uint8
+}
+
+func (e *completionError) Error() string {
+	return fmt.Sprintf("completion code 0x%02x", e.code)
+}
+
+// rmcpStatusMessages contains the status codes of the RMCP+ and RAKP messages
+var rmcpStatusMessages = map[uint8]string{
+	0x01: "insufficient resources to create a session",
+	0x02: "invalid session ID",
+	0x03: "invalid payload type",
+	0x04: "invalid authentication algorithm",
+	0x05: "invalid integrity algorithm",
+	0x06: "no matching authentication payload",
+	0x07: "no matching integrity payload",
+	0x08: "inactive session ID",
+	0x09: "invalid role",
+	0x0a: "unauthorized role or privilege level requested",
+	0x0b: "insufficient resources to create a session at the requested role",
+	0x0c: "invalid name length",
+	0x0d: "unauthorized name",
+	0x0e: "unauthorized GUID",
+	0x0f: "invalid integrity check value",
+	0x10: "invalid confidentiality algorithm",
+	0x11: "no cipher suite match with proposed security algorithms",
+	0x12: "illegal or unrecognized parameter",
+}
+
+func rmcpStatusError(step string, status uint8) error {
+	if msg, found := rmcpStatusMessages[status]; found {
+		return fmt.Errorf("%s failed: %s", step, msg)
+	}
+	return fmt.Errorf("%s failed with status 0x%02x", step, status)
+}
+
+// session is an authenticated and encrypted IPMI v2.0 (RMCP+) session with
+// a BMC
+type session struct {
+	suite     *cipherSuite
+	privilege uint8
+	timeout   time.Duration
+	retries   int
+
+	conn      net.Conn
+	keys      *sessionKeys
+	id        uint32 // session ID assigned by the BMC
+	consoleID uint32 // session ID assigned by the console
+	sequence  uint32
+	rqSeq     uint8
+	lastUsed  time.Time
+}
+
+// credentials used to establish a session, the password is used as the
+// key-generating key if no explicit key is given
+type credentials struct {
+	username []byte
+	password []byte
+	kg       []byte
+}
+
+func openSession(address string, creds *credentials, suite *cipherSuite, privilege uint8, timeout time.Duration, retries int) (*session, error) {
+	if len(creds.username) > 16 {
+		return nil, errors.New("username exceeds 16 characters")
+	}
+	if len(creds.password) > 20 {
+		return nil, errors.New("password exceeds 20 characters")
+	}
+
+	conn, err := net.DialTimeout("udp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &session{
+		suite:     suite,
+		privilege: privilege,
+		timeout:   timeout,
+		retries:   retries,
+		conn:      conn,
+	}
+	if err := s.handshake(creds); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	s.lastUsed = time.Now()
+
+	// Sessions start at user level and need to be elevated explicitly
+	if privilege > privilegeUser {
+		if _, err := s.command(netFnApp, 0, cmdSetSessionPrivilegeLevel, []byte{privilege}); err != nil {
+			s.close()
+			return nil, fmt.Errorf("setting privilege level failed: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *session) handshake(creds *credentials) error {
+	// Query the authentication capabilities to check for IPMI v2.0 support
+	msg := (&request{netFn: netFnApp, command: cmdGetChannelAuthCapabilities, data: []byte{0x8e, s.privilege}}).encode()
+	reply, err := s.exchange(encodePacketV15(msg), func(p *packet) bool {
+		return p.payloadType == payloadIPMI
+	})
+	if err != nil {
+		return fmt.Errorf("querying authentication capabilities failed: %w", err)
+	}
+	resp, err := decodeResponse(reply.payload)
+	if err != nil {
+		return fmt.Errorf("querying authentication capabilities failed: %w", err)
+	}
+	if resp.completionCode != 0 {
+		return fmt.Errorf("querying authentication capabilities failed: %w", &completionError{resp.completionCode})
+	}
+	if len(resp.data) < 4 || resp.data[1]&0x80 == 0 || resp.data[3]&0x02 == 0 {
+		return errors.New("BMC does not support IPMI v2.0")
+	}
+
+	// Open the session proposing the algorithms of the cipher suite
+	consoleID := make([]byte, 4)
+	if _, err := rand.Read(consoleID); err != nil {
+		return err
+	}
+	consoleID[3] |= 0x01 // Make sure the ID is non-zero
+	s.consoleID = binary.LittleEndian.Uint32(consoleID)
+
+	tag := uint8(1)
+	openReq := make([]byte, 32)
+	openReq[0] = tag
+	copy(openReq[4:], consoleID)
+	copy(openReq[8:], []byte{0x00, 0, 0, 0x08, s.suite.authAlg})
+	copy(openReq[16:], []byte{0x01, 0, 0, 0x08, s.suite.integrityAlg})
+	copy(openReq[24:], []byte{0x02, 0, 0, 0x08, s.suite.confAlg})
+	payload, err := s.exchangePayload(payloadOpenSessionRequest, openReq, payloadOpenSessionResponse, tag)
+	if err != nil {
+		return fmt.Errorf("opening session failed: %w", err)
+	}
+	if payload[1] != 0 {
+		return rmcpStatusError("opening session", payload[1])
+	}
+	if len(payload) < 36 || binary.LittleEndian.Uint32(payload[4:]) != s.consoleID {
+		return errors.New("invalid open session response")
+	}
+	s.id = binary.LittleEndian.Uint32(payload[8:])
+	bmcID := payload[8:12]
+
+	// RAKP message 1 and 2 authenticate the BMC
+	tag++
+	rm := make([]byte, 16)
+	if _, err := rand.Read(rm); err != nil {
+		return err
+	}
+	role := s.privilege | 0x10 // name-only lookup
+	userInfo := append([]byte{role, byte(len(creds.username))}, creds.username...)
+	rakp1 := make([]byte, 0, 28+len(creds.username))
+	rakp1 = append(rakp1, tag, 0, 0, 0)
+	rakp1 = append(rakp1, bmcID...)
+	rakp1 = append(rakp1, rm...)
+	rakp1 = append(rakp1, role, 0, 0, byte(len(creds.username)))
+	rakp1 = append(rakp1, creds.username...)
+	payload, err = s.exchangePayload(payloadRAKP1, rakp1, payloadRAKP2, tag)
+	if err != nil {
+		return fmt.Errorf("RAKP 1 failed: %w", err)
+	}
+	if payload[1] != 0 {
+		return rmcpStatusError("RAKP 2", payload[1])
+	}
+	size := s.suite.hash().Size()
+	if len(payload) < 40+size || binary.LittleEndian.Uint32(payload[4:]) != s.consoleID {
+		return errors.New("invalid RAKP 2 message")
+	}
+	rc := payload[8:24]
+	guid := payload[24:40]
+	expected := s.suite.hmac(creds.password, consoleID, bmcID, rm, rc, guid, userInfo)
+	if !hmac.Equal(payload[40:40+size], expected) {
+		return errors.New("invalid RAKP 2 authentication code, check the username and password")
+	}
+
+	kg := creds.kg
+	if len(kg) == 0 {
+		kg = creds.password
+	}
+	sik := s.suite.hmac(kg, rm, rc, userInfo)
+
+	// RAKP message 3 and 4 authenticate the console
+	tag++
+	rakp3 := make([]byte, 0, 8+size)
+	rakp3 = append(rakp3, tag, 0, 0, 0)
+	rakp3 = append(rakp3, bmcID...)
+	rakp3 = append(rakp3, s.suite.hmac(creds.password, rc, consoleID, userInfo)...)
+	payload, err = s.exchangePayload(payloadRAKP3, rakp3, payloadRAKP4, tag)
+	if err != nil {
+		return fmt.Errorf("RAKP 3 failed: %w", err)
+	}
+	if payload[1] != 0 {
+		return rmcpStatusError("RAKP 4", payload[1])
+	}
+	n := s.suite.integrityLen
+	if len(payload) < 8+n {
+		return errors.New("invalid RAKP 4 message")
+	}
+	if !hmac.Equal(payload[8:8+n], s.suite.hmac(sik, rm, bmcID, guid)[:n]) {
+		return errors.New("invalid RAKP 4 integrity check value")
+	}
+
+	s.keys = newSessionKeys(s.suite, sik)
+	return nil
+}
+
+// exchangePayload sends a session setup payload outside of a session and
+// returns the response payload of the given type with the matching tag
+func (s *session) exchangePayload(ptype uint8, payload []byte, rtype, tag uint8) ([]byte, error) {
+	buf, err := encodePacket(&packet{payloadType: ptype, payload: payload}, nil)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := s.exchange(buf, func(p *packet) bool {
+		return p.payloadType == rtype && len(p.payload) >= 2 && p.payload[0] == tag
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply.payload, nil
+}
+
+// command sends the request within the session and returns the response data
+func (s *session) command(netFn, lun, cmd uint8, data []byte) ([]byte, error) {
+	s.rqSeq = (s.rqSeq + 1) & 0x3f
+	msg := (&request{netFn: netFn, lun: lun, sequence: s.rqSeq, command: cmd, data: data}).encode()
+
+	var resp *response
+	_, err := s.exchangeFunc(func() ([]byte, error) {
+		s.sequence++
+		return encodePacket(&packet{
+			payloadType: payloadIPMI | payloadAuthenticated | payloadEncrypted,
+			sessionID:   s.id,
+			sequence:    s.sequence,
+			payload:     msg,
+		}, s.keys)
+	}, func(p *packet) bool {
+		if p.payloadType != payloadIPMI || p.sessionID != s.consoleID {
+			return false
+		}
+		r, err := decodeResponse(p.payload)
+		if err != nil || r.netFn != netFn+1 || r.command != cmd || r.sequence != s.rqSeq {
+			return false
+		}
+		resp = r
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.lastUsed = time.Now()
+
+	if resp.completionCode != 0 {
+		return nil, &completionError{resp.completionCode}
+	}
+	return resp.data, nil
+}
+
+// exchange sends the packet and waits for a reply accepted by the match
+// function, resending the packet on timeouts
+func (s *session) exchange(buf []byte, match func(*packet) bool) (*packet, error) {
+	return s.exchangeFunc(func() ([]byte, error) { return buf, nil }, match)
+}
+
+func (s *session) exchangeFunc(encode func() ([]byte, error), match func(*packet) bool) (*packet, error) {
+	reply := make([]byte, 1024)
+	for attempt := 0; attempt <= s.retries; attempt++ {
+		buf, err := encode()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.conn.Write(buf); err != nil {
+			return nil, err
+		}
+
+		if err := s.conn.SetReadDeadline(time.Now().Add(s.timeout)); err != nil {
+			return nil, err
+		}
+		for {
+			n, err := s.conn.Read(reply)
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			// Ignore unrelated or corrupted packets, e.g. late replies to
+			// previous attempts
+			p, err := decodePacket(reply[:n], s.keys)
+			if err != nil || !match(p) {
+				continue
+			}
+			return p, nil
+		}
+	}
+	return nil, errors.New("timeout waiting for response")
+}
+
+// close closes the session on the BMC and releases the connection
+func (s *session) close() {
+	if s.keys != nil {
+		id := make([]byte, 4)
+		binary.LittleEndian.PutUint32(id, s.id)
+		retries := s.retries
+		s.retries = 0
+		//nolint:errcheck // the session times out on the BMC anyway
+		s.command(netFnApp, 0, cmdCloseSession, id)
+		s.retries = retries
+	}
+	s.conn.Close()
+}
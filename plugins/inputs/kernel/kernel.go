@@ -31,12 +31,14 @@ var (
 
 type Kernel struct {
 	ConfigCollect []string `toml:"collect"`
+	PSICgroups    []string `toml:"psi_cgroups"`
 
 	optCollect      map[string]bool
 	statFile        string
 	entropyStatFile string
 	ksmStatsDir     string
 	psiDir          string
+	cgroupDir       string
 	procfs          procfs.FS
 }
 
@@ -64,6 +66,11 @@ func (k *Kernel) Init() error {
 			return fmt.Errorf("failed to initialize procfs on %s: %w", procdir, err)
 		}
 	}
+	for _, pattern := range k.PSICgroups {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid cgroup pattern %q: %w", pattern, err)
+		}
+	}
 	return nil
 }
 
@@ -161,6 +168,9 @@ func (k *Kernel) Gather(acc telegraf.Accumulator) error {
 		if err := k.gatherPressure(acc); err != nil {
 			return err
 		}
+		if err := k.gatherCgroupPressure(acc); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -202,6 +212,7 @@ func init() {
 			entropyStatFile: "/proc/sys/kernel/random/entropy_avail",
 			ksmStatsDir:     "/sys/kernel/mm/ksm",
 			psiDir:          "/proc/pressure",
+			cgroupDir:       "/sys/fs/cgroup",
 		}
 	})
 }
@@ -3,7 +3,13 @@
 package kernel
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/prometheus/procfs"
@@ -11,41 +17,145 @@ import (
 	"github.com/influxdata/telegraf"
 )
 
+var psiResources = []string{"cpu", "memory", "io"}
+
 // Gather PSI metrics
 func (k *Kernel) gatherPressure(acc telegraf.Accumulator) error {
-	for _, resource := range []string{"cpu", "memory", "io"} {
+	for _, resource := range psiResources {
 		now := time.Now()
 		psiStats, err := k.procfs.PSIStatsForResource(resource)
 		if err != nil {
 			return fmt.Errorf("failed to read %s pressure: %w", resource, err)
 		}
 
-		stats := map[string]*procfs.PSILine{
-			"some": psiStats.Some,
-			"full": psiStats.Full,
+		// resource=cpu,type=full is omitted because it is always zero
+		addPressure(acc, resource, psiStats, resource != "cpu", nil, now)
+	}
+	return nil
+}
+
+// Gather PSI metrics of the cgroups matching the configured patterns
+func (k *Kernel) gatherCgroupPressure(acc telegraf.Accumulator) error {
+	for _, pattern := range k.PSICgroups {
+		dirs, err := filepath.Glob(filepath.Join(k.cgroupDir, pattern))
+		if err != nil {
+			return fmt.Errorf("invalid cgroup pattern %q: %w", pattern, err)
 		}
 
-		for _, typ := range []string{"some", "full"} {
-			if resource == "cpu" && typ == "full" {
-				// resource=cpu,type=full is omitted because it is always zero
-				continue
+		for _, dir := range dirs {
+			cgroup, err := filepath.Rel(k.cgroupDir, dir)
+			if err != nil {
+				return err
 			}
+			tags := map[string]string{"cgroup": cgroup}
 
-			tags := map[string]string{
-				"resource": resource,
-				"type":     typ,
+			for _, resource := range psiResources {
+				now := time.Now()
+				psiStats, err := readPSIFile(filepath.Join(dir, resource+".pressure"))
+				if err != nil {
+					// The cgroup might have vanished or PSI is disabled for it
+					if errors.Is(err, os.ErrNotExist) {
+						continue
+					}
+					return fmt.Errorf("failed to read %s pressure of cgroup %q: %w", resource, cgroup, err)
+				}
+				addPressure(acc, resource, psiStats, true, tags, now)
 			}
-			stat := stats[typ]
-
-			acc.AddCounter("pressure", map[string]interface{}{
-				"total": stat.Total,
-			}, tags, now)
-			acc.AddGauge("pressure", map[string]interface{}{
-				"avg10":  stat.Avg10,
-				"avg60":  stat.Avg60,
-				"avg300": stat.Avg300,
-			}, tags, now)
 		}
 	}
 	return nil
 }
+
+func addPressure(acc telegraf.Accumulator, resource string, psiStats procfs.PSIStats, withFull bool, extraTags map[string]string, now time.Time) {
+	stats := map[string]*procfs.PSILine{
+		"some": psiStats.Some,
+		"full": psiStats.Full,
+	}
+
+	for _, typ := range []string{"some", "full"} {
+		if typ == "full" && !withFull {
+			continue
+		}
+		stat := stats[typ]
+		if stat == nil {
+			continue
+		}
+
+		tags := map[string]string{
+			"resource": resource,
+			"type":     typ,
+		}
+		for k, v := range extraTags {
+			tags[k] = v
+		}
+
+		acc.AddCounter("pressure", map[string]interface{}{
+			"total": stat.Total,
+		}, tags, now)
+		acc.AddGauge("pressure", map[string]interface{}{
+			"avg10":  stat.Avg10,
+			"avg60":  stat.Avg60,
+			"avg300": stat.Avg300,
+		}, tags, now)
+	}
+}
+
+// readPSIFile parses a pressure file of a cgroup which uses the same format as
+// the files in /proc/pressure, e.g.
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func readPSIFile(path string) (procfs.PSIStats, error) {
+	var psiStats procfs.PSIStats
+
+	file, err := os.Open(path)
+	if err != nil {
+		return psiStats, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) == 0 {
+			continue
+		}
+
+		line := &procfs.PSILine{}
+		for _, part := range parts[1:] {
+			name, value, found := strings.Cut(part, "=")
+			if !found {
+				return psiStats, fmt.Errorf("malformed entry %q", part)
+			}
+			switch name {
+			case "avg10", "avg60", "avg300":
+				v, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return psiStats, fmt.Errorf("parsing %q failed: %w", name, err)
+				}
+				switch name {
+				case "avg10":
+					line.Avg10 = v
+				case "avg60":
+					line.Avg60 = v
+				case "avg300":
+					line.Avg300 = v
+				}
+			case "total":
+				v, err := strconv.ParseUint(value, 10, 64)
+				if err != nil {
+					return psiStats, fmt.Errorf("parsing %q failed: %w", name, err)
+				}
+				line.Total = v
+			}
+		}
+
+		switch parts[0] {
+		case "some":
+			psiStats.Some = line
+		case "full":
+			psiStats.Full = line
+		}
+	}
+	return psiStats, scanner.Err()
+}
@@ -168,3 +168,144 @@ func TestPSIStats(t *testing.T) {
 	actual := acc.GetTelegrafMetrics()
 	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
 }
+
+func TestPSICgroupStats(t *testing.T) {
+	k := Kernel{
+		psiDir:        "testdata/pressure",
+		cgroupDir:     "testdata/cgroup",
+		ConfigCollect: []string{"psi"},
+		PSICgroups:    []string{"system.slice/*.service", "user.slice/*"},
+	}
+	require.NoError(t, k.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, k.gatherCgroupPressure(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"pressure",
+			map[string]string{
+				"cgroup":   "system.slice/a.service",
+				"resource": "cpu",
+				"type":     "some",
+			},
+			map[string]interface{}{
+				"avg10":  float64(1),
+				"avg60":  float64(2),
+				"avg300": float64(3),
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"pressure",
+			map[string]string{
+				"cgroup":   "system.slice/a.service",
+				"resource": "cpu",
+				"type":     "some",
+			},
+			map[string]interface{}{
+				"total": uint64(100),
+			},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+		metric.New(
+			"pressure",
+			map[string]string{
+				"cgroup":   "system.slice/a.service",
+				"resource": "cpu",
+				"type":     "full",
+			},
+			map[string]interface{}{
+				"avg10":  float64(0.5),
+				"avg60":  float64(1.5),
+				"avg300": float64(2.5),
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"pressure",
+			map[string]string{
+				"cgroup":   "system.slice/a.service",
+				"resource": "cpu",
+				"type":     "full",
+			},
+			map[string]interface{}{
+				"total": uint64(50),
+			},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+		metric.New(
+			"pressure",
+			map[string]string{
+				"cgroup":   "system.slice/b.service",
+				"resource": "cpu",
+				"type":     "some",
+			},
+			map[string]interface{}{
+				"avg10":  float64(4),
+				"avg60":  float64(5),
+				"avg300": float64(6),
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"pressure",
+			map[string]string{
+				"cgroup":   "system.slice/b.service",
+				"resource": "cpu",
+				"type":     "some",
+			},
+			map[string]interface{}{
+				"total": uint64(400),
+			},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+		metric.New(
+			"pressure",
+			map[string]string{
+				"cgroup":   "system.slice/b.service",
+				"resource": "cpu",
+				"type":     "full",
+			},
+			map[string]interface{}{
+				"avg10":  float64(0),
+				"avg60":  float64(0),
+				"avg300": float64(0),
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"pressure",
+			map[string]string{
+				"cgroup":   "system.slice/b.service",
+				"resource": "cpu",
+				"type":     "full",
+			},
+			map[string]interface{}{
+				"total": uint64(0),
+			},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+	}
+
+	actual := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestPSICgroupInvalidPattern(t *testing.T) {
+	k := Kernel{
+		psiDir:        "testdata/pressure",
+		cgroupDir:     "testdata/cgroup",
+		ConfigCollect: []string{"psi"},
+		PSICgroups:    []string{"system.slice/["},
+	}
+	require.ErrorContains(t, k.Init(), "invalid cgroup pattern")
+}
@@ -0,0 +1,110 @@
+package netstat
+
+import (
+	"bufio"
+	"fmt"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+// asnTable maps network prefixes to autonomous system numbers
+type asnTable struct {
+	ipv4 prefixTable
+	ipv6 prefixTable
+}
+
+// prefixTable groups prefixes by their length to allow a longest-prefix match
+// with one lookup per prefix length
+type prefixTable struct {
+	prefixes map[int]map[netip.Prefix]string
+	lengths  []int
+}
+
+// loadASNTable reads a prefix-to-AS mapping file. Each line either contains
+// a prefix in CIDR notation followed by the AS number e.g. "192.0.2.0/24 64496"
+// or prefix, length and AS number separated by whitespace as used by the
+// CAIDA pfx2as datasets e.g. "192.0.2.0 24 64496". Empty lines and lines
+// starting with '#' are ignored.
+func loadASNTable(filename string) (*asnTable, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var table asnTable
+	scanner := bufio.NewScanner(f)
+	var lineno int
+	for scanner.Scan() {
+		lineno++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var raw, asn string
+		parts := strings.Fields(line)
+		switch len(parts) {
+		case 2:
+			raw, asn = parts[0], parts[1]
+		case 3:
+			raw, asn = parts[0]+"/"+parts[1], parts[2]
+		default:
+			return nil, fmt.Errorf("invalid entry in line %d", lineno)
+		}
+		prefix, err := netip.ParsePrefix(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prefix in line %d: %w", lineno, err)
+		}
+
+		if prefix.Addr().Is4() {
+			table.ipv4.add(prefix, asn)
+		} else {
+			table.ipv6.add(prefix, asn)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &table, nil
+}
+
+// lookup returns the AS number of the most specific prefix containing the
+// given address or "unknown" if no prefix matches.
+func (t *asnTable) lookup(addr netip.Addr) string {
+	addr = addr.Unmap()
+	table := &t.ipv6
+	if addr.Is4() {
+		table = &t.ipv4
+	}
+
+	for _, bits := range table.lengths {
+		prefix, err := addr.Prefix(bits)
+		if err != nil {
+			continue
+		}
+		if asn, found := table.prefixes[bits][prefix]; found {
+			return asn
+		}
+	}
+	return "unknown"
+}
+
+func (t *prefixTable) add(prefix netip.Prefix, asn string) {
+	if t.prefixes == nil {
+		t.prefixes = make(map[int]map[netip.Prefix]string)
+	}
+
+	bits := prefix.Bits()
+	if _, found := t.prefixes[bits]; !found {
+		t.prefixes[bits] = make(map[netip.Prefix]string)
+
+		// Keep the most specific prefix lengths first
+		t.lengths = append(t.lengths, bits)
+		sort.Sort(sort.Reverse(sort.IntSlice(t.lengths)))
+	}
+	t.prefixes[bits][prefix.Masked()] = asn
+}
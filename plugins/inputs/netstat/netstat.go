@@ -4,6 +4,8 @@ package netstat
 import (
 	_ "embed"
 	"fmt"
+	"net/netip"
+	"strconv"
 	"syscall"
 
 	"github.com/influxdata/telegraf"
@@ -15,13 +17,29 @@ import (
 var sampleConfig string
 
 type NetStat struct {
-	ps psutil.PS
+	PerPort  bool   `toml:"per_port"`
+	ASNFile  string `toml:"asn_file"`
+	Sockstat bool   `toml:"sockstat"`
+
+	ps  psutil.PS
+	asn *asnTable
 }
 
 func (*NetStat) SampleConfig() string {
 	return sampleConfig
 }
 
+func (ns *NetStat) Init() error {
+	if ns.ASNFile != "" {
+		table, err := loadASNTable(ns.ASNFile)
+		if err != nil {
+			return fmt.Errorf("loading AS mapping failed: %w", err)
+		}
+		ns.asn = table
+	}
+	return nil
+}
+
 func (ns *NetStat) Gather(acc telegraf.Accumulator) error {
 	netconns, err := ns.ps.NetConnections()
 	if err != nil {
@@ -30,6 +48,18 @@ func (ns *NetStat) Gather(acc telegraf.Accumulator) error {
 	counts := make(map[string]int)
 	counts["UDP"] = 0
 
+	// Local ports with a listening socket
+	listening := make(map[uint32]bool)
+	if ns.PerPort {
+		for _, netcon := range netconns {
+			if netcon.Type != syscall.SOCK_DGRAM && netcon.Status == "LISTEN" {
+				listening[netcon.Laddr.Port] = true
+			}
+		}
+	}
+	perPort := make(map[uint32]map[string]int, len(listening))
+	perAS := make(map[string]map[string]int)
+
 	// TODO: add family to tags or else
 	tags := make(map[string]string)
 	for _, netcon := range netconns {
@@ -42,9 +72,52 @@ func (ns *NetStat) Gather(acc telegraf.Accumulator) error {
 			counts[netcon.Status] = 0
 		}
 		counts[netcon.Status] = c + 1
+
+		if listening[netcon.Laddr.Port] {
+			if perPort[netcon.Laddr.Port] == nil {
+				perPort[netcon.Laddr.Port] = make(map[string]int)
+			}
+			perPort[netcon.Laddr.Port][netcon.Status]++
+		}
+
+		if ns.asn != nil && netcon.Status != "LISTEN" {
+			addr, err := netip.ParseAddr(netcon.Raddr.IP)
+			if err != nil || addr.IsLoopback() || addr.IsUnspecified() {
+				continue
+			}
+			asn := ns.asn.lookup(addr)
+			if perAS[asn] == nil {
+				perAS[asn] = make(map[string]int)
+			}
+			perAS[asn][netcon.Status]++
+		}
+	}
+
+	fields := tcpFields(counts)
+	fields["udp_socket"] = counts["UDP"]
+	acc.AddFields("netstat", fields, tags)
+
+	for port, c := range perPort {
+		acc.AddFields("netstat_port", tcpFields(c), map[string]string{"port": strconv.FormatUint(uint64(port), 10)})
+	}
+
+	for asn, c := range perAS {
+		acc.AddFields("netstat_remote_as", tcpFields(c), map[string]string{"asn": asn})
+	}
+
+	if ns.Sockstat {
+		fields, err := gatherSockstat()
+		if err != nil {
+			return fmt.Errorf("error getting socket statistics: %w", err)
+		}
+		acc.AddFields("netstat_sockstat", fields, nil)
 	}
 
-	fields := map[string]interface{}{
+	return nil
+}
+
+func tcpFields(counts map[string]int) map[string]interface{} {
+	return map[string]interface{}{
 		"tcp_established": counts["ESTABLISHED"],
 		"tcp_syn_sent":    counts["SYN_SENT"],
 		"tcp_syn_recv":    counts["SYN_RECV"],
@@ -57,11 +130,7 @@ func (ns *NetStat) Gather(acc telegraf.Accumulator) error {
 		"tcp_listen":      counts["LISTEN"],
 		"tcp_closing":     counts["CLOSING"],
 		"tcp_none":        counts["NONE"],
-		"udp_socket":      counts["UDP"],
 	}
-	acc.AddFields("netstat", fields, tags)
-
-	return nil
 }
 
 func init() {
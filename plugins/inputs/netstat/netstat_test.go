@@ -1,6 +1,8 @@
 package netstat
 
 import (
+	"net/netip"
+	"path/filepath"
 	"syscall"
 	"testing"
 	"time"
@@ -64,3 +66,145 @@ func TestNetStats(t *testing.T) {
 		testutil.IgnoreTime(),
 	)
 }
+
+func TestNetStatsPerPortAndAS(t *testing.T) {
+	var mps psutil.MockPS
+	defer mps.AssertExpectations(t)
+	mps.On("NetConnections").Return([]net.ConnectionStat{
+		{
+			Laddr:  net.Addr{IP: "0.0.0.0", Port: 443},
+			Status: "LISTEN",
+		},
+		{
+			Laddr:  net.Addr{IP: "10.0.0.1", Port: 443},
+			Raddr:  net.Addr{IP: "192.0.2.1", Port: 51000},
+			Status: "ESTABLISHED",
+		},
+		{
+			Laddr:  net.Addr{IP: "10.0.0.1", Port: 443},
+			Raddr:  net.Addr{IP: "192.0.2.200", Port: 51001},
+			Status: "SYN_RECV",
+		},
+		{
+			Laddr:  net.Addr{IP: "10.0.0.1", Port: 40000},
+			Raddr:  net.Addr{IP: "198.51.100.1", Port: 80},
+			Status: "ESTABLISHED",
+		},
+		{
+			Laddr:  net.Addr{IP: "127.0.0.1", Port: 40001},
+			Raddr:  net.Addr{IP: "127.0.0.1", Port: 8080},
+			Status: "ESTABLISHED",
+		},
+	}, nil)
+
+	plugin := &NetStat{
+		PerPort: true,
+		ASNFile: filepath.Join("testdata", "pfx2as.txt"),
+		ps:      &mps,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"netstat",
+			map[string]string{},
+			tcpStateFields(map[string]interface{}{"tcp_established": 3, "tcp_syn_recv": 1, "tcp_listen": 1, "udp_socket": 0}),
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"netstat_port",
+			map[string]string{"port": "443"},
+			tcpStateFields(map[string]interface{}{"tcp_established": 1, "tcp_syn_recv": 1, "tcp_listen": 1}),
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"netstat_remote_as",
+			map[string]string{"asn": "64496"},
+			tcpStateFields(map[string]interface{}{"tcp_established": 1}),
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"netstat_remote_as",
+			map[string]string{"asn": "64497"},
+			tcpStateFields(map[string]interface{}{"tcp_syn_recv": 1}),
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"netstat_remote_as",
+			map[string]string{"asn": "unknown"},
+			tcpStateFields(map[string]interface{}{"tcp_established": 1}),
+			time.Unix(0, 0),
+		),
+	}
+
+	testutil.RequireMetricsEqual(t,
+		expected,
+		acc.GetTelegrafMetrics(),
+		testutil.IgnoreTime(),
+		testutil.SortMetrics(),
+	)
+}
+
+func TestASNTableLookup(t *testing.T) {
+	table, err := loadASNTable(filepath.Join("testdata", "pfx2as.txt"))
+	require.NoError(t, err)
+
+	require.Equal(t, "64496", table.lookup(netip.MustParseAddr("192.0.2.1")))
+	require.Equal(t, "64497", table.lookup(netip.MustParseAddr("192.0.2.129")))
+	require.Equal(t, "64497", table.lookup(netip.MustParseAddr("::ffff:192.0.2.129")))
+	require.Equal(t, "64498", table.lookup(netip.MustParseAddr("2001:db8::1")))
+	require.Equal(t, "unknown", table.lookup(netip.MustParseAddr("198.51.100.1")))
+}
+
+func TestSockstat(t *testing.T) {
+	t.Setenv("HOST_PROC", filepath.Join("testdata", "proc"))
+
+	var mps psutil.MockPS
+	defer mps.AssertExpectations(t)
+	mps.On("NetConnections").Return([]net.ConnectionStat{}, nil)
+
+	plugin := &NetStat{Sockstat: true, ps: &mps}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := metric.New(
+		"netstat_sockstat",
+		map[string]string{},
+		map[string]interface{}{
+			"sockets_used":     int64(290),
+			"tcp_inuse":        int64(7),
+			"tcp_orphan":       int64(0),
+			"tcp_tw":           int64(0),
+			"tcp_alloc":        int64(9),
+			"tcp_mem":          int64(1),
+			"tcp_mem_low":      int64(93312),
+			"tcp_mem_pressure": int64(124416),
+			"tcp_mem_high":     int64(186624),
+			"udp_inuse":        int64(3),
+			"udp_mem":          int64(2),
+			"udplite_inuse":    int64(0),
+			"raw_inuse":        int64(0),
+			"frag_inuse":       int64(0),
+			"frag_memory":      int64(0),
+		},
+		time.Unix(0, 0),
+	)
+
+	actual := acc.GetTelegrafMetrics()
+	require.Len(t, actual, 2)
+	testutil.RequireMetricEqual(t, expected, actual[1], testutil.IgnoreTime())
+}
+
+// tcpStateFields returns the TCP state fields with all states not given set to zero
+func tcpStateFields(fields map[string]interface{}) map[string]interface{} {
+	result := tcpFields(nil)
+	for k, v := range fields {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,68 @@
+package netstat
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// gatherSockstat reads the socket statistics of "/proc/net/sockstat" e.g.
+//
+//	sockets: used 290
+//	TCP: inuse 7 orphan 0 tw 0 alloc 9 mem 1
+//	UDP: inuse 3 mem 2
+//
+// and the TCP memory limits from "/proc/sys/net/ipv4/tcp_mem" if available.
+func gatherSockstat() (map[string]interface{}, error) {
+	procPath := internal.GetProcPath()
+
+	buf, err := os.ReadFile(filepath.Join(procPath, "net", "sockstat"))
+	if err != nil {
+		return nil, err
+	}
+	fields := parseSockstat(buf)
+
+	// The limits are given in pages as "<low> <pressure> <high>"
+	if buf, err := os.ReadFile(filepath.Join(procPath, "sys", "net", "ipv4", "tcp_mem")); err == nil {
+		limits := strings.Fields(string(buf))
+		if len(limits) == 3 {
+			for i, name := range []string{"tcp_mem_low", "tcp_mem_pressure", "tcp_mem_high"} {
+				if v, err := strconv.ParseInt(limits[i], 10, 64); err == nil {
+					fields[name] = v
+				}
+			}
+		}
+	}
+
+	return fields, nil
+}
+
+func parseSockstat(buf []byte) map[string]interface{} {
+	fields := make(map[string]interface{})
+
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		protocol, values, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		protocol = strings.ToLower(strings.TrimSpace(protocol))
+
+		// Values are pairs of names and numbers e.g. "inuse 7 orphan 0"
+		parts := strings.Fields(values)
+		for i := 0; i+1 < len(parts); i += 2 {
+			v, err := strconv.ParseInt(parts[i+1], 10, 64)
+			if err != nil {
+				continue
+			}
+			fields[protocol+"_"+parts[i]] = v
+		}
+	}
+
+	return fields
+}
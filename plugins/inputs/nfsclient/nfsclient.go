@@ -397,6 +397,15 @@ func (n *NFSClient) parseStat(mountpoint, export, version string, line []string,
 				for i, t := range nline {
 					fields[nfsopFields[i]] = t
 				}
+
+				// Average latencies per operation to attribute slow mounts
+				// to specific operations
+				if len(nline) >= 8 && nline[0] > 0 {
+					ops := float64(nline[0])
+					fields["queue_time_per_op"] = float64(nline[5]) / ops
+					fields["response_time_per_op"] = float64(nline[6]) / ops
+					fields["total_time_per_op"] = float64(nline[7]) / ops
+				}
 				acc.AddFields("nfs_ops", fields, tags)
 			}
 		}
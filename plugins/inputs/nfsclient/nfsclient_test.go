@@ -39,6 +39,10 @@ func TestNFSClientParsev3(t *testing.T) {
 		"queue_time":    uint64(505),
 		"response_time": uint64(506),
 		"total_time":    uint64(507),
+
+		"queue_time_per_op":    float64(505) / 500,
+		"response_time_per_op": float64(506) / 500,
+		"total_time_per_op":    float64(507) / 500,
 	}
 	acc.AssertContainsFields(t, "nfs_ops", fieldsOps)
 }
@@ -62,6 +66,10 @@ func TestNFSClientParsev4(t *testing.T) {
 		"queue_time":    uint64(505),
 		"response_time": uint64(506),
 		"total_time":    uint64(507),
+
+		"queue_time_per_op":    float64(505) / 500,
+		"response_time_per_op": float64(506) / 500,
+		"total_time_per_op":    float64(507) / 500,
 	}
 	acc.AssertContainsFields(t, "nfs_ops", fieldsOps)
 }
@@ -73,6 +81,7 @@ func TestNFSClientParseLargeValue(t *testing.T) {
 	nfsclient.nfs3Ops = map[string]bool{"SETCLIENTID": true, "GETATTR": false}
 	nfsclient.nfs4Ops = map[string]bool{"SETCLIENTID": true, "GETATTR": false}
 	data := strings.Fields("    SETCLIENTID: 218 216 0 53568 12960 18446744073709531008 134 197")
+	var queueTime uint64 = 18446744073709531008
 	err := nfsclient.parseStat("2.2.2.2:/nfsdata/", "/B", "4", data, &acc)
 	require.NoError(t, err)
 
@@ -82,9 +91,13 @@ func TestNFSClientParseLargeValue(t *testing.T) {
 		"timeouts":      uint64(0),
 		"bytes_sent":    uint64(53568),
 		"bytes_recv":    uint64(12960),
-		"queue_time":    uint64(18446744073709531008),
+		"queue_time":    queueTime,
 		"response_time": uint64(134),
 		"total_time":    uint64(197),
+
+		"queue_time_per_op":    float64(queueTime) / 218,
+		"response_time_per_op": float64(134) / 218,
+		"total_time_per_op":    float64(197) / 218,
 	}
 	acc.AssertContainsFields(t, "nfs_ops", fieldsOps)
 }
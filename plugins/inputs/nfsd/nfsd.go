@@ -0,0 +1,321 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build linux
+
+package nfsd
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Operations in the order of the counters in the 'proc2', 'proc3' and
+// 'proc4ops' lines. For NFSv4 the index corresponds to the operation number,
+// numbers 0 to 2 are unused.
+var (
+	nfs2Ops = []string{
+		"NULL", "GETATTR", "SETATTR", "ROOT", "LOOKUP", "READLINK", "READ", "WRCACHE", "WRITE", "CREATE",
+		"REMOVE", "RENAME", "LINK", "SYMLINK", "MKDIR", "RMDIR", "READDIR", "FSSTAT",
+	}
+	nfs3Ops = []string{
+		"NULL", "GETATTR", "SETATTR", "LOOKUP", "ACCESS", "READLINK", "READ", "WRITE", "CREATE", "MKDIR",
+		"SYMLINK", "MKNOD", "REMOVE", "RMDIR", "RENAME", "LINK", "READDIR", "READDIRPLUS", "FSSTAT", "FSINFO",
+		"PATHCONF", "COMMIT",
+	}
+	nfs4Ops = []string{
+		"", "", "", "ACCESS", "CLOSE", "COMMIT", "CREATE", "DELEGPURGE", "DELEGRETURN", "GETATTR",
+		"GETFH", "LINK", "LOCK", "LOCKT", "LOCKU", "LOOKUP", "LOOKUPP", "NVERIFY", "OPEN", "OPENATTR",
+		"OPEN_CONFIRM", "OPEN_DOWNGRADE", "PUTFH", "PUTPUBFH", "PUTROOTFH", "READ", "READDIR", "READLINK", "REMOVE", "RENAME",
+		"RENEW", "RESTOREFH", "SAVEFH", "SECINFO", "SETATTR", "SETCLIENTID", "SETCLIENTID_CONFIRM", "VERIFY", "WRITE", "RELEASE_LOCKOWNER",
+		"BACKCHANNEL_CTL", "BIND_CONN_TO_SESSION", "EXCHANGE_ID", "CREATE_SESSION", "DESTROY_SESSION", "FREE_STATEID",
+		"GET_DIR_DELEGATION", "GETDEVICEINFO", "GETDEVICELIST", "LAYOUTCOMMIT",
+		"LAYOUTGET", "LAYOUTRETURN", "SECINFO_NO_NAME", "SEQUENCE", "SET_SSV", "TEST_STATEID", "WANT_DELEGATION",
+		"DESTROY_CLIENTID", "RECLAIM_COMPLETE", "ALLOCATE",
+		"COPY", "COPY_NOTIFY", "DEALLOCATE", "IO_ADVISE", "LAYOUTERROR", "LAYOUTSTATS", "OFFLOAD_CANCEL",
+		"OFFLOAD_STATUS", "READ_PLUS", "SEEK",
+		"WRITE_SAME", "CLONE", "GETXATTR", "SETXATTR", "LISTXATTRS", "REMOVEXATTR",
+	}
+
+	// Fields of the general server statistics per line prefix
+	statFields = map[string][]string{
+		"rc":             {"reply_cache_hits", "reply_cache_misses", "reply_cache_nocache"},
+		"io":             {"read_bytes", "write_bytes"},
+		"th":             {"threads"},
+		"net":            {"net_packets", "net_udp", "net_tcp", "net_tcp_connections"},
+		"rpc":            {"rpc_calls", "rpc_bad_calls", "rpc_bad_format", "rpc_bad_auth", "rpc_bad_client"},
+		"wdeleg_getattr": {"wdeleg_getattr"},
+	}
+
+	stateTypeRe   = regexp.MustCompile(`\btype: (\w+)`)
+	stateAccessRe = regexp.MustCompile(`\baccess: ([\w-]+)`)
+)
+
+type NFSD struct {
+	IncludeOperations []string        `toml:"include_operations"`
+	ExcludeOperations []string        `toml:"exclude_operations"`
+	ClientStates      bool            `toml:"client_states"`
+	Log               telegraf.Logger `toml:"-"`
+
+	statsPath   string
+	clientsPath string
+	operations  filter.Filter
+}
+
+func (*NFSD) SampleConfig() string {
+	return sampleConfig
+}
+
+func (n *NFSD) Init() error {
+	f, err := filter.NewIncludeExcludeFilter(n.IncludeOperations, n.ExcludeOperations)
+	if err != nil {
+		return fmt.Errorf("creating operation filter failed: %w", err)
+	}
+	n.operations = f
+
+	procPath := internal.GetProcPath()
+	n.statsPath = filepath.Join(procPath, "net", "rpc", "nfsd")
+	n.clientsPath = filepath.Join(procPath, "fs", "nfsd", "clients")
+
+	return nil
+}
+
+func (n *NFSD) Gather(acc telegraf.Accumulator) error {
+	if err := n.gatherStats(acc); err != nil {
+		return err
+	}
+
+	if n.ClientStates {
+		if err := n.gatherClients(acc); err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	return nil
+}
+
+func (n *NFSD) gatherStats(acc telegraf.Accumulator) error {
+	file, err := os.Open(n.statsPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fields := make(map[string]interface{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.Fields(scanner.Text())
+		if len(line) < 2 {
+			continue
+		}
+
+		switch line[0] {
+		case "proc2":
+			n.addOperations(acc, "2", nfs2Ops, line[1:])
+		case "proc3":
+			n.addOperations(acc, "3", nfs3Ops, line[1:])
+		case "proc4":
+			// The second counter contains the number of COMPOUND calls
+			if len(line) > 3 {
+				if v, err := strconv.ParseUint(line[3], 10, 64); err == nil {
+					fields["compounds"] = v
+				}
+			}
+		case "proc4ops":
+			n.addOperations(acc, "4", nfs4Ops, line[1:])
+		default:
+			names, found := statFields[line[0]]
+			if !found {
+				continue
+			}
+			for i, name := range names {
+				if i+1 >= len(line) {
+					break
+				}
+				v, err := strconv.ParseUint(line[i+1], 10, 64)
+				if err != nil {
+					return fmt.Errorf("parsing %q value %q failed: %w", name, line[i+1], err)
+				}
+				fields[name] = v
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if len(fields) > 0 {
+		acc.AddFields("nfsd", fields, nil)
+	}
+
+	return nil
+}
+
+// addOperations adds the per-operation counts of an operation line where the
+// first value contains the number of counters.
+func (n *NFSD) addOperations(acc telegraf.Accumulator, version string, operations, values []string) {
+	count, err := strconv.Atoi(values[0])
+	if err != nil {
+		n.Log.Debugf("Invalid number of NFSv%s operations %q", version, values[0])
+		return
+	}
+	counters := values[1:]
+	if count < len(counters) {
+		counters = counters[:count]
+	}
+
+	for i, raw := range counters {
+		if i >= len(operations) {
+			break
+		}
+		op := operations[i]
+		if op == "" || !n.operations.Match(op) {
+			continue
+		}
+		v, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			n.Log.Debugf("Invalid count %q for NFSv%s operation %q", raw, version, op)
+			continue
+		}
+		tags := map[string]string{
+			"version":   version,
+			"operation": op,
+		}
+		acc.AddFields("nfsd_ops", map[string]interface{}{"ops": v}, tags)
+	}
+}
+
+func (n *NFSD) gatherClients(acc telegraf.Accumulator) error {
+	entries, err := os.ReadDir(n.clientsPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			n.Log.Debugf("Client states not available at %q", n.clientsPath)
+			return nil
+		}
+		return fmt.Errorf("reading clients failed: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(n.clientsPath, entry.Name())
+		if err := gatherClient(acc, dir); err != nil {
+			// Clients might disconnect while reading the information
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			acc.AddError(fmt.Errorf("reading client %q failed: %w", entry.Name(), err))
+		}
+	}
+
+	return nil
+}
+
+func gatherClient(acc telegraf.Accumulator, dir string) error {
+	info, err := readClientInfo(filepath.Join(dir, "info"))
+	if err != nil {
+		return err
+	}
+
+	tags := map[string]string{
+		"address":       info["address"],
+		"name":          info["name"],
+		"minor_version": info["minor version"],
+	}
+	if host, _, err := net.SplitHostPort(tags["address"]); err == nil {
+		tags["address"] = host
+	}
+
+	fields := map[string]interface{}{
+		"opens":             uint64(0),
+		"locks":             uint64(0),
+		"delegations_read":  uint64(0),
+		"delegations_write": uint64(0),
+		"layouts":           uint64(0),
+	}
+	if v, ok := info["status"]; ok {
+		fields["status"] = v
+	}
+	if v, ok := info["callback state"]; ok {
+		fields["callback_state"] = v
+	}
+	if v, err := strconv.ParseUint(info["seconds from last renew"], 10, 64); err == nil {
+		fields["seconds_since_renew"] = v
+	}
+
+	file, err := os.Open(filepath.Join(dir, "states"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		m := stateTypeRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		switch m[1] {
+		case "open":
+			fields["opens"] = fields["opens"].(uint64) + 1
+		case "lock":
+			fields["locks"] = fields["locks"].(uint64) + 1
+		case "layout":
+			fields["layouts"] = fields["layouts"].(uint64) + 1
+		case "deleg":
+			key := "delegations_read"
+			if a := stateAccessRe.FindStringSubmatch(line); a != nil && strings.Contains(a[1], "w") {
+				key = "delegations_write"
+			}
+			fields[key] = fields[key].(uint64) + 1
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	acc.AddFields("nfsd_client", fields, tags)
+
+	return nil
+}
+
+// readClientInfo reads the "key: value" pairs of a client's info file
+func readClientInfo(path string) (map[string]string, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info := make(map[string]string)
+	for _, line := range strings.Split(string(buf), "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		info[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+
+	return info, nil
+}
+
+func init() {
+	inputs.Add("nfsd", func() telegraf.Input {
+		return &NFSD{ClientStates: true}
+	})
+}
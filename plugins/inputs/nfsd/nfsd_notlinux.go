@@ -0,0 +1,33 @@
+//go:generate ../../../tools/readme_config_includer/generator
+//go:build !linux
+
+package nfsd
+
+import (
+	_ "embed"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type NFSD struct {
+	Log telegraf.Logger `toml:"-"`
+}
+
+func (*NFSD) SampleConfig() string { return sampleConfig }
+
+func (n *NFSD) Init() error {
+	n.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*NFSD) Gather(telegraf.Accumulator) error { return nil }
+
+func init() {
+	inputs.Add("nfsd", func() telegraf.Input {
+		return &NFSD{}
+	})
+}
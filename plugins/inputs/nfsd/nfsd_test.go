@@ -0,0 +1,189 @@
+//go:build linux
+
+package nfsd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &NFSD{
+		IncludeOperations: []string{"READ["},
+		Log:               testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "creating operation filter failed")
+}
+
+func TestGather(t *testing.T) {
+	plugin := &NFSD{
+		IncludeOperations: []string{"READ*", "WRITE", "DELEG*", "OPEN"},
+		ExcludeOperations: []string{"READDIR*"},
+		ClientStates:      true,
+		Log:               testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.statsPath = "testdata/nfsd"
+	plugin.clientsPath = "testdata/clients"
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nfsd",
+			map[string]string{},
+			map[string]interface{}{
+				"reply_cache_hits":    uint64(0),
+				"reply_cache_misses":  uint64(35026),
+				"reply_cache_nocache": uint64(1205042),
+				"read_bytes":          uint64(1420442414),
+				"write_bytes":         uint64(3520739747),
+				"threads":             uint64(8),
+				"net_packets":         uint64(1240068),
+				"net_udp":             uint64(0),
+				"net_tcp":             uint64(1240112),
+				"net_tcp_connections": uint64(2615),
+				"rpc_calls":           uint64(1240067),
+				"rpc_bad_calls":       uint64(0),
+				"rpc_bad_format":      uint64(0),
+				"rpc_bad_auth":        uint64(0),
+				"rpc_bad_client":      uint64(0),
+				"compounds":           uint64(1240021),
+				"wdeleg_getattr":      uint64(7),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfsd_ops",
+			map[string]string{"version": "3", "operation": "READLINK"},
+			map[string]interface{}{"ops": uint64(0)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfsd_ops",
+			map[string]string{"version": "3", "operation": "READ"},
+			map[string]interface{}{"ops": uint64(29757)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfsd_ops",
+			map[string]string{"version": "3", "operation": "WRITE"},
+			map[string]interface{}{"ops": uint64(1147)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfsd_ops",
+			map[string]string{"version": "4", "operation": "DELEGPURGE"},
+			map[string]interface{}{"ops": uint64(0)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfsd_ops",
+			map[string]string{"version": "4", "operation": "DELEGRETURN"},
+			map[string]interface{}{"ops": uint64(17)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfsd_ops",
+			map[string]string{"version": "4", "operation": "OPEN"},
+			map[string]interface{}{"ops": uint64(321)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfsd_ops",
+			map[string]string{"version": "4", "operation": "READ"},
+			map[string]interface{}{"ops": uint64(9120)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfsd_ops",
+			map[string]string{"version": "4", "operation": "READLINK"},
+			map[string]interface{}{"ops": uint64(0)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfsd_ops",
+			map[string]string{"version": "4", "operation": "WRITE"},
+			map[string]interface{}{"ops": uint64(3318)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfsd_ops",
+			map[string]string{"version": "4", "operation": "READ_PLUS"},
+			map[string]interface{}{"ops": uint64(0)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfsd_client",
+			map[string]string{
+				"address":       "192.168.122.36",
+				"name":          "Linux NFSv4.2 client1.example.org",
+				"minor_version": "2",
+			},
+			map[string]interface{}{
+				"status":              "confirmed",
+				"callback_state":      "UP",
+				"seconds_since_renew": uint64(32),
+				"opens":               uint64(2),
+				"locks":               uint64(1),
+				"delegations_read":    uint64(1),
+				"delegations_write":   uint64(1),
+				"layouts":             uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"nfsd_client",
+			map[string]string{
+				"address":       "192.168.122.40",
+				"name":          "Linux NFSv4.1 client2.example.org",
+				"minor_version": "1",
+			},
+			map[string]interface{}{
+				"status":              "courtesy",
+				"callback_state":      "DOWN",
+				"seconds_since_renew": uint64(120),
+				"opens":               uint64(0),
+				"locks":               uint64(0),
+				"delegations_read":    uint64(0),
+				"delegations_write":   uint64(0),
+				"layouts":             uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherMissingClients(t *testing.T) {
+	plugin := &NFSD{
+		ClientStates: true,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.statsPath = "testdata/nfsd"
+	plugin.clientsPath = "testdata/nonexistent"
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.NotEmpty(t, acc.GetTelegrafMetrics())
+	require.False(t, acc.HasMeasurement("nfsd_client"))
+}
+
+func TestGatherMissingStats(t *testing.T) {
+	plugin := &NFSD{Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	plugin.statsPath = "testdata/nonexistent"
+
+	var acc testutil.Accumulator
+	require.Error(t, plugin.Gather(&acc))
+}
@@ -22,6 +22,8 @@ const timeLayout = "2006-01-02 15:04:05 -0700 MST"
 
 type Nomad struct {
 	URL             string          `toml:"url"`
+	Token           config.Secret   `toml:"token"`
+	Include         []string        `toml:"include"`
 	ResponseTimeout config.Duration `toml:"response_timeout"`
 	tls.ClientConfig
 
@@ -37,6 +39,14 @@ func (n *Nomad) Init() error {
 		n.URL = "http://127.0.0.1:4646"
 	}
 
+	for _, include := range n.Include {
+		switch include {
+		case "allocations", "deployments", "evaluations":
+		default:
+			return fmt.Errorf("invalid 'include' value %q", include)
+		}
+	}
+
 	tlsCfg, err := n.ClientConfig.TLSConfig()
 	if err != nil {
 		return fmt.Errorf("setting up TLS configuration failed: %w", err)
@@ -63,6 +73,23 @@ func (n *Nomad) Gather(acc telegraf.Accumulator) error {
 		return err
 	}
 
+	for _, include := range n.Include {
+		switch include {
+		case "allocations":
+			if err := n.gatherAllocations(acc); err != nil {
+				acc.AddError(fmt.Errorf("gathering allocations failed: %w", err))
+			}
+		case "deployments":
+			if err := n.gatherDeployments(acc); err != nil {
+				acc.AddError(fmt.Errorf("gathering deployments failed: %w", err))
+			}
+		case "evaluations":
+			if err := n.gatherBlockedEvaluations(acc); err != nil {
+				acc.AddError(fmt.Errorf("gathering evaluations failed: %w", err))
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -72,6 +99,15 @@ func (n *Nomad) loadJSON(url string, v interface{}) error {
 		return err
 	}
 
+	if !n.Token.Empty() {
+		token, err := n.Token.Get()
+		if err != nil {
+			return fmt.Errorf("getting token failed: %w", err)
+		}
+		req.Header.Set("X-Nomad-Token", token.String())
+		token.Destroy()
+	}
+
 	resp, err := n.roundTripper.RoundTrip(req)
 	if err != nil {
 		return fmt.Errorf("error making HTTP request to %q: %w", url, err)
@@ -0,0 +1,214 @@
+package nomad
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Deployment states considered as active, i.e. still in progress
+var activeDeploymentStates = map[string]bool{
+	"initializing": true,
+	"running":      true,
+	"pending":      true,
+	"blocked":      true,
+	"paused":       true,
+	"unblocking":   true,
+}
+
+type agentSelf struct {
+	Stats struct {
+		Client struct {
+			NodeID string `json:"node_id"`
+		} `json:"client"`
+	} `json:"stats"`
+}
+
+type allocation struct {
+	ID           string `json:"ID"`
+	Name         string `json:"Name"`
+	Namespace    string `json:"Namespace"`
+	NodeID       string `json:"NodeID"`
+	JobID        string `json:"JobID"`
+	TaskGroup    string `json:"TaskGroup"`
+	ClientStatus string `json:"ClientStatus"`
+}
+
+type allocationStats struct {
+	ResourceUsage struct {
+		MemoryStats struct {
+			RSS            uint64 `json:"RSS"`
+			Cache          uint64 `json:"Cache"`
+			Swap           uint64 `json:"Swap"`
+			Usage          uint64 `json:"Usage"`
+			MaxUsage       uint64 `json:"MaxUsage"`
+			KernelUsage    uint64 `json:"KernelUsage"`
+			KernelMaxUsage uint64 `json:"KernelMaxUsage"`
+		} `json:"MemoryStats"`
+		CPUStats struct {
+			SystemMode       float64 `json:"SystemMode"`
+			UserMode         float64 `json:"UserMode"`
+			TotalTicks       float64 `json:"TotalTicks"`
+			ThrottledPeriods uint64  `json:"ThrottledPeriods"`
+			ThrottledTime    uint64  `json:"ThrottledTime"`
+			Percent          float64 `json:"Percent"`
+		} `json:"CpuStats"`
+	} `json:"ResourceUsage"`
+	Timestamp int64 `json:"Timestamp"`
+}
+
+type deployment struct {
+	ID                string                         `json:"ID"`
+	Namespace         string                         `json:"Namespace"`
+	JobID             string                         `json:"JobID"`
+	JobVersion        uint64                         `json:"JobVersion"`
+	Status            string                         `json:"Status"`
+	StatusDescription string                         `json:"StatusDescription"`
+	TaskGroups        map[string]deploymentTaskGroup `json:"TaskGroups"`
+}
+
+type deploymentTaskGroup struct {
+	Promoted        bool `json:"Promoted"`
+	DesiredCanaries int  `json:"DesiredCanaries"`
+	DesiredTotal    int  `json:"DesiredTotal"`
+	PlacedAllocs    int  `json:"PlacedAllocs"`
+	HealthyAllocs   int  `json:"HealthyAllocs"`
+	UnhealthyAllocs int  `json:"UnhealthyAllocs"`
+}
+
+type evaluation struct {
+	ID        string `json:"ID"`
+	Namespace string `json:"Namespace"`
+	JobID     string `json:"JobID"`
+	Status    string `json:"Status"`
+}
+
+// gatherAllocations collects the resource usage of the allocations running
+// on the client node of the agent
+func (n *Nomad) gatherAllocations(acc telegraf.Accumulator) error {
+	var self agentSelf
+	if err := n.loadJSON(n.URL+"/v1/agent/self", &self); err != nil {
+		return err
+	}
+	nodeID := self.Stats.Client.NodeID
+	if nodeID == "" {
+		return fmt.Errorf("agent at %q is not a client node", n.URL)
+	}
+
+	var allocations []allocation
+	if err := n.loadJSON(n.URL+"/v1/node/"+url.PathEscape(nodeID)+"/allocations", &allocations); err != nil {
+		return err
+	}
+
+	for _, alloc := range allocations {
+		if alloc.ClientStatus != "running" {
+			continue
+		}
+
+		var stats allocationStats
+		if err := n.loadJSON(n.URL+"/v1/client/allocation/"+url.PathEscape(alloc.ID)+"/stats", &stats); err != nil {
+			acc.AddError(err)
+			continue
+		}
+
+		tags := map[string]string{
+			"alloc_id":   alloc.ID,
+			"alloc_name": alloc.Name,
+			"namespace":  alloc.Namespace,
+			"job":        alloc.JobID,
+			"task_group": alloc.TaskGroup,
+			"node_id":    nodeID,
+		}
+		memory := stats.ResourceUsage.MemoryStats
+		cpu := stats.ResourceUsage.CPUStats
+		fields := map[string]interface{}{
+			"cpu_percent":           cpu.Percent,
+			"cpu_system_mode":       cpu.SystemMode,
+			"cpu_user_mode":         cpu.UserMode,
+			"cpu_total_ticks":       cpu.TotalTicks,
+			"cpu_throttled_periods": cpu.ThrottledPeriods,
+			"cpu_throttled_time":    cpu.ThrottledTime,
+			"memory_rss":            memory.RSS,
+			"memory_cache":          memory.Cache,
+			"memory_swap":           memory.Swap,
+			"memory_usage":          memory.Usage,
+			"memory_max_usage":      memory.MaxUsage,
+			"memory_kernel_usage":   memory.KernelUsage,
+		}
+		acc.AddGauge("nomad_allocation", fields, tags, time.Unix(0, stats.Timestamp))
+	}
+
+	return nil
+}
+
+// gatherDeployments collects the health and progress of the task groups of
+// all active deployments
+func (n *Nomad) gatherDeployments(acc telegraf.Accumulator) error {
+	var deployments []deployment
+	if err := n.loadJSON(n.URL+"/v1/deployments?namespace=*", &deployments); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, d := range deployments {
+		if !activeDeploymentStates[d.Status] {
+			continue
+		}
+
+		for name, group := range d.TaskGroups {
+			tags := map[string]string{
+				"deployment_id": d.ID,
+				"namespace":     d.Namespace,
+				"job":           d.JobID,
+				"task_group":    name,
+				"status":        d.Status,
+			}
+			fields := map[string]interface{}{
+				"job_version":      d.JobVersion,
+				"desired_total":    group.DesiredTotal,
+				"desired_canaries": group.DesiredCanaries,
+				"placed_allocs":    group.PlacedAllocs,
+				"healthy_allocs":   group.HealthyAllocs,
+				"unhealthy_allocs": group.UnhealthyAllocs,
+				"promoted":         group.Promoted,
+			}
+			if group.DesiredTotal > 0 {
+				fields["progress_percent"] = 100 * float64(group.HealthyAllocs) / float64(group.DesiredTotal)
+			}
+			acc.AddGauge("nomad_deployment", fields, tags, now)
+		}
+	}
+
+	return nil
+}
+
+// gatherBlockedEvaluations collects the number of blocked evaluations per
+// namespace and job
+func (n *Nomad) gatherBlockedEvaluations(acc telegraf.Accumulator) error {
+	filter := url.QueryEscape(`Status == "blocked"`)
+	var evaluations []evaluation
+	if err := n.loadJSON(n.URL+"/v1/evaluations?namespace=*&filter="+filter, &evaluations); err != nil {
+		return err
+	}
+
+	type key struct{ namespace, job string }
+	counts := make(map[key]int)
+	for _, e := range evaluations {
+		if e.Status == "blocked" {
+			counts[key{e.Namespace, e.JobID}]++
+		}
+	}
+
+	now := time.Now()
+	for k, count := range counts {
+		tags := map[string]string{
+			"namespace": k.namespace,
+			"job":       k.job,
+		}
+		acc.AddGauge("nomad_evaluations", map[string]interface{}{"blocked": count}, tags, now)
+	}
+
+	return nil
+}
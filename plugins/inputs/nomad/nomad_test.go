@@ -5,12 +5,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -112,3 +115,145 @@ func TestNomadStats(t *testing.T) {
 		})
 	}
 }
+
+func TestInitFail(t *testing.T) {
+	plugin := &Nomad{Include: []string{"allocations", "jobs"}}
+	require.ErrorContains(t, plugin.Init(), `invalid 'include' value "jobs"`)
+}
+
+func TestNomadAPI(t *testing.T) {
+	responses := map[string]string{
+		"/v1/metrics":    "testdata/response_key_metrics.json",
+		"/v1/agent/self": "testdata/agent_self.json",
+		"/v1/node/2bbff078-8473-a9de-6c5e-42b4e053e12f/allocations":        "testdata/node_allocations.json",
+		"/v1/client/allocation/f9a9a9d2-3bfa-4d9f-9b3c-4e0e7b0e5c1a/stats": "testdata/allocation_stats.json",
+		"/v1/deployments": "testdata/deployments.json",
+		"/v1/evaluations": "testdata/evaluations.json",
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Nomad-Token") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/deployments":
+			if r.URL.Query().Get("namespace") != "*" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		case "/v1/evaluations":
+			if r.URL.Query().Get("filter") != `Status == "blocked"` {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+
+		fn, found := responses[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		buf, err := os.ReadFile(fn)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := w.Write(buf); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer ts.Close()
+
+	plugin := &Nomad{
+		URL:     ts.URL,
+		Token:   config.NewSecret([]byte("secret")),
+		Include: []string{"allocations", "deployments", "evaluations"},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"nomad_allocation",
+			map[string]string{
+				"alloc_id":   "f9a9a9d2-3bfa-4d9f-9b3c-4e0e7b0e5c1a",
+				"alloc_name": "web.frontend[0]",
+				"namespace":  "default",
+				"job":        "web",
+				"task_group": "frontend",
+				"node_id":    "2bbff078-8473-a9de-6c5e-42b4e053e12f",
+			},
+			map[string]interface{}{
+				"cpu_percent":           4.662507025836762,
+				"cpu_system_mode":       1.6663803484716715,
+				"cpu_user_mode":         2.9961242095834887,
+				"cpu_total_ticks":       106.80323554075688,
+				"cpu_throttled_periods": uint64(0),
+				"cpu_throttled_time":    uint64(0),
+				"memory_rss":            uint64(6791168),
+				"memory_cache":          uint64(1875968),
+				"memory_swap":           uint64(0),
+				"memory_usage":          uint64(9175040),
+				"memory_max_usage":      uint64(9265152),
+				"memory_kernel_usage":   uint64(0),
+			},
+			time.Unix(1636843140, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"nomad_deployment",
+			map[string]string{
+				"deployment_id": "70638f62-5c19-193e-30d6-f9d6e689ab8e",
+				"namespace":     "default",
+				"job":           "web",
+				"task_group":    "frontend",
+				"status":        "running",
+			},
+			map[string]interface{}{
+				"job_version":      uint64(3),
+				"desired_total":    4,
+				"desired_canaries": 1,
+				"placed_allocs":    3,
+				"healthy_allocs":   2,
+				"unhealthy_allocs": 1,
+				"promoted":         false,
+				"progress_percent": float64(50),
+			},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"nomad_evaluations",
+			map[string]string{
+				"namespace": "default",
+				"job":       "web",
+			},
+			map[string]interface{}{"blocked": 2},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"nomad_evaluations",
+			map[string]string{
+				"namespace": "batch",
+				"job":       "reports",
+			},
+			map[string]interface{}{"blocked": 1},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if strings.HasPrefix(m.Name(), "nomad_") {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+}
@@ -0,0 +1,267 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package openvswitch
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type OpenVSwitch struct {
+	Address   string          `toml:"address"`
+	Timeout   config.Duration `toml:"timeout"`
+	Collect   []string        `toml:"collect"`
+	OVSAppctl string          `toml:"ovs_appctl"`
+	OVSOfctl  string          `toml:"ovs_ofctl"`
+	UseSudo   bool            `toml:"use_sudo"`
+	Log       telegraf.Logger `toml:"-"`
+
+	network string
+	address string
+	run     runner
+}
+
+type runner func(timeout time.Duration, useSudo bool, binary string, args ...string) ([]byte, error)
+
+type bridge struct {
+	name  string
+	ports []string
+}
+
+type port struct {
+	name       string
+	interfaces []string
+}
+
+func (*OpenVSwitch) SampleConfig() string {
+	return sampleConfig
+}
+
+func (o *OpenVSwitch) Init() error {
+	if o.Address == "" {
+		o.Address = "unix:///var/run/openvswitch/db.sock"
+	}
+	u, err := url.Parse(o.Address)
+	if err != nil {
+		return fmt.Errorf("parsing address failed: %w", err)
+	}
+	switch u.Scheme {
+	case "unix":
+		o.network, o.address = "unix", u.Path
+	case "tcp":
+		o.network, o.address = "tcp", u.Host
+	default:
+		return fmt.Errorf("invalid scheme %q in address", u.Scheme)
+	}
+
+	if len(o.Collect) == 0 {
+		o.Collect = []string{"interface", "datapath", "upcall", "openflow"}
+	}
+	for _, c := range o.Collect {
+		switch c {
+		case "interface", "datapath", "upcall", "openflow":
+		default:
+			return fmt.Errorf("invalid 'collect' option %q", c)
+		}
+	}
+
+	if o.run == nil {
+		o.run = execRunner
+	}
+
+	return nil
+}
+
+func (o *OpenVSwitch) Gather(acc telegraf.Accumulator) error {
+	timeout := time.Duration(o.Timeout)
+
+	if slices.Contains(o.Collect, "interface") || slices.Contains(o.Collect, "openflow") {
+		if err := o.gatherOVSDB(acc); err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	if slices.Contains(o.Collect, "datapath") {
+		out, err := o.run(timeout, o.UseSudo, o.OVSAppctl, "dpctl/show")
+		if err != nil {
+			acc.AddError(fmt.Errorf("getting datapath statistics failed: %w", err))
+		} else {
+			for _, dp := range parseDatapathStats(out) {
+				acc.AddFields("openvswitch_datapath", dp.fields, map[string]string{"datapath": dp.name})
+			}
+		}
+	}
+
+	if slices.Contains(o.Collect, "upcall") {
+		out, err := o.run(timeout, o.UseSudo, o.OVSAppctl, "upcall/show")
+		if err != nil {
+			acc.AddError(fmt.Errorf("getting upcall statistics failed: %w", err))
+		} else {
+			for _, dp := range parseUpcallStats(out) {
+				acc.AddFields("openvswitch_upcall", dp.fields, map[string]string{"datapath": dp.name})
+			}
+		}
+	}
+
+	return nil
+}
+
+func (o *OpenVSwitch) gatherOVSDB(acc telegraf.Accumulator) error {
+	timeout := time.Duration(o.Timeout)
+	queries := []tableQuery{
+		{table: "Bridge", columns: []string{"_uuid", "name", "ports"}},
+		{table: "Port", columns: []string{"_uuid", "name", "interfaces"}},
+		{table: "Interface", columns: []string{"_uuid", "name", "type", "statistics", "link_state", "admin_state"}},
+	}
+	results, err := selectTables(o.network, o.address, timeout, queries)
+	if err != nil {
+		return err
+	}
+
+	bridges := make([]bridge, 0, len(results[0]))
+	for _, row := range results[0] {
+		ports, err := ovsdbUUIDSet(row["ports"])
+		if err != nil {
+			return fmt.Errorf("decoding ports of bridge %v failed: %w", row["name"], err)
+		}
+		bridges = append(bridges, bridge{name: ovsdbString(row["name"]), ports: ports})
+	}
+	sort.Slice(bridges, func(i, j int) bool { return bridges[i].name < bridges[j].name })
+
+	if slices.Contains(o.Collect, "interface") {
+		if err := gatherInterfaces(acc, bridges, results[1], results[2]); err != nil {
+			return err
+		}
+	}
+
+	if slices.Contains(o.Collect, "openflow") {
+		for _, b := range bridges {
+			out, err := o.run(timeout, o.UseSudo, o.OVSOfctl, "dump-aggregate", b.name)
+			if err != nil {
+				acc.AddError(fmt.Errorf("getting OpenFlow statistics for bridge %q failed: %w", b.name, err))
+				continue
+			}
+			fields, err := parseAggregateStats(out)
+			if err != nil {
+				acc.AddError(fmt.Errorf("parsing OpenFlow statistics for bridge %q failed: %w", b.name, err))
+				continue
+			}
+			fields["ports"] = len(b.ports)
+			acc.AddFields("openvswitch_bridge", fields, map[string]string{"bridge": b.name})
+		}
+	}
+
+	return nil
+}
+
+func gatherInterfaces(acc telegraf.Accumulator, bridges []bridge, portRows, interfaceRows []map[string]interface{}) error {
+	ports := make(map[string]port, len(portRows))
+	for _, row := range portRows {
+		id, err := ovsdbUUID(row["_uuid"])
+		if err != nil {
+			return err
+		}
+		interfaces, err := ovsdbUUIDSet(row["interfaces"])
+		if err != nil {
+			return fmt.Errorf("decoding interfaces of port %v failed: %w", row["name"], err)
+		}
+		ports[id] = port{name: ovsdbString(row["name"]), interfaces: interfaces}
+	}
+
+	interfaces := make(map[string]map[string]interface{}, len(interfaceRows))
+	for _, row := range interfaceRows {
+		id, err := ovsdbUUID(row["_uuid"])
+		if err != nil {
+			return err
+		}
+		interfaces[id] = row
+	}
+
+	for _, b := range bridges {
+		for _, portID := range b.ports {
+			p, found := ports[portID]
+			if !found {
+				continue
+			}
+			for _, ifaceID := range p.interfaces {
+				row, found := interfaces[ifaceID]
+				if !found {
+					continue
+				}
+				stats, err := ovsdbMap(row["statistics"])
+				if err != nil {
+					return fmt.Errorf("decoding statistics of interface %v failed: %w", row["name"], err)
+				}
+
+				fields := make(map[string]interface{}, len(stats)+2)
+				for k, v := range stats {
+					if n, ok := v.(float64); ok {
+						fields[k] = int64(n)
+					}
+				}
+				if state := ovsdbString(row["link_state"]); state != "" {
+					fields["link_up"] = state == "up"
+				}
+				if state := ovsdbString(row["admin_state"]); state != "" {
+					fields["admin_up"] = state == "up"
+				}
+				if len(fields) == 0 {
+					continue
+				}
+
+				ifaceType := ovsdbString(row["type"])
+				if ifaceType == "" {
+					ifaceType = "system"
+				}
+				tags := map[string]string{
+					"bridge":    b.name,
+					"port":      p.name,
+					"interface": ovsdbString(row["name"]),
+					"type":      ifaceType,
+				}
+				acc.AddFields("openvswitch_interface", fields, tags)
+			}
+		}
+	}
+
+	return nil
+}
+
+func execRunner(timeout time.Duration, useSudo bool, binary string, args ...string) ([]byte, error) {
+	if binary == "" {
+		return nil, errors.New("no binary specified")
+	}
+	cmd := exec.Command(binary, args...)
+	if useSudo {
+		cmd = exec.Command("sudo", append([]string{"-n", binary}, args...)...)
+	}
+	out, err := internal.CombinedOutputTimeout(cmd, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("running %q %q failed: %w - %s", binary, args, err, string(out))
+	}
+	return out, nil
+}
+
+func init() {
+	inputs.Add("openvswitch", func() telegraf.Input {
+		return &OpenVSwitch{
+			Address:   "unix:///var/run/openvswitch/db.sock",
+			Timeout:   config.Duration(5 * time.Second),
+			OVSAppctl: "/usr/bin/ovs-appctl",
+			OVSOfctl:  "/usr/bin/ovs-ofctl",
+		}
+	})
+}
@@ -0,0 +1,185 @@
+package openvswitch
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const transactResponse = `{"id":0,"error":null,"result":[
+{"rows":[{"_uuid":["uuid","b1"],"name":"br0","ports":["set",[["uuid","p1"],["uuid","p2"]]]}]},
+{"rows":[
+  {"_uuid":["uuid","p1"],"name":"br0","interfaces":["uuid","i1"]},
+  {"_uuid":["uuid","p2"],"name":"eth1","interfaces":["uuid","i2"]}
+]},
+{"rows":[
+  {"_uuid":["uuid","i1"],"name":"br0","type":"internal","link_state":"up","admin_state":"up",
+   "statistics":["map",[["rx_bytes",0],["rx_packets",0],["tx_bytes",0],["tx_packets",0]]]},
+  {"_uuid":["uuid","i2"],"name":"eth1","type":"","link_state":"down","admin_state":"up",
+   "statistics":["map",[["rx_bytes",1024],["rx_dropped",2],["rx_packets",10],["tx_bytes",2048],["tx_packets",20]]]}
+]}
+]}`
+
+func startServer(t *testing.T) string {
+	t.Helper()
+
+	sock := filepath.Join(t.TempDir(), "db.sock")
+	listener, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			var request ovsdbRequest
+			if err := json.NewDecoder(conn).Decode(&request); err != nil || request.Method != "transact" {
+				conn.Close()
+				continue
+			}
+			_, _ = conn.Write([]byte(transactResponse))
+			conn.Close()
+		}
+	}()
+
+	return "unix://" + sock
+}
+
+func fakeRunner(t *testing.T) runner {
+	return func(_ time.Duration, _ bool, _ string, args ...string) ([]byte, error) {
+		switch args[0] {
+		case "dpctl/show":
+			return os.ReadFile(filepath.Join("testdata", "dpctl_show.txt"))
+		case "upcall/show":
+			return os.ReadFile(filepath.Join("testdata", "upcall_show.txt"))
+		case "dump-aggregate":
+			require.Equal(t, []string{"dump-aggregate", "br0"}, args)
+			return os.ReadFile(filepath.Join("testdata", "dump_aggregate.txt"))
+		}
+		return nil, errors.New("unexpected command")
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &OpenVSwitch{Address: "http://localhost:6640"}
+	require.ErrorContains(t, plugin.Init(), `invalid scheme "http"`)
+
+	plugin = &OpenVSwitch{Collect: []string{"foo"}}
+	require.ErrorContains(t, plugin.Init(), `invalid 'collect' option "foo"`)
+}
+
+func TestGather(t *testing.T) {
+	plugin := &OpenVSwitch{
+		Address: startServer(t),
+		Timeout: config.Duration(5 * time.Second),
+		Log:     testutil.Logger{},
+		run:     fakeRunner(t),
+	}
+	require.NoError(t, plugin.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"openvswitch_interface",
+			map[string]string{"bridge": "br0", "port": "br0", "interface": "br0", "type": "internal"},
+			map[string]interface{}{
+				"rx_bytes":   int64(0),
+				"rx_packets": int64(0),
+				"tx_bytes":   int64(0),
+				"tx_packets": int64(0),
+				"link_up":    true,
+				"admin_up":   true,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"openvswitch_interface",
+			map[string]string{"bridge": "br0", "port": "eth1", "interface": "eth1", "type": "system"},
+			map[string]interface{}{
+				"rx_bytes":   int64(1024),
+				"rx_dropped": int64(2),
+				"rx_packets": int64(10),
+				"tx_bytes":   int64(2048),
+				"tx_packets": int64(20),
+				"link_up":    false,
+				"admin_up":   true,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"openvswitch_bridge",
+			map[string]string{"bridge": "br0"},
+			map[string]interface{}{
+				"packet_count": int64(1234),
+				"byte_count":   int64(567890),
+				"flow_count":   int64(4),
+				"ports":        2,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"openvswitch_datapath",
+			map[string]string{"datapath": "system@ovs-system"},
+			map[string]interface{}{
+				"lookups_hit":             int64(1346),
+				"lookups_missed":          int64(54),
+				"lookups_lost":            int64(0),
+				"lookups_hit_ratio":       float64(1346) / 1400,
+				"flows":                   int64(3),
+				"masks_hit":               int64(2138),
+				"masks_total":             int64(2),
+				"masks_hit_per_packet":    1.52,
+				"megaflow_cache_hit":      int64(1174),
+				"megaflow_cache_hit_rate": 83.86,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"openvswitch_upcall",
+			map[string]string{"datapath": "system@ovs-system"},
+			map[string]interface{}{
+				"flows_current":    int64(2),
+				"flows_avg":        int64(1),
+				"flows_max":        int64(5),
+				"flows_limit":      int64(10000),
+				"offloaded_flows":  int64(0),
+				"dump_duration_ms": int64(1),
+				"handler_keys":     int64(2),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherCommandFailure(t *testing.T) {
+	plugin := &OpenVSwitch{
+		Address: startServer(t),
+		Timeout: config.Duration(5 * time.Second),
+		Collect: []string{"datapath"},
+		Log:     testutil.Logger{},
+		run: func(time.Duration, bool, string, ...string) ([]byte, error) {
+			return nil, errors.New("not running")
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, acc.GatherError(plugin.Gather), "getting datapath statistics failed")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
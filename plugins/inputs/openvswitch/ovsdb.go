@@ -0,0 +1,176 @@
+package openvswitch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ovsdbRequest is a JSON-RPC 1.0 request as specified in RFC 7047
+type ovsdbRequest struct {
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+	ID     int           `json:"id"`
+}
+
+type ovsdbResponse struct {
+	Result []ovsdbResult `json:"result"`
+	Error  interface{}   `json:"error"`
+	ID     int           `json:"id"`
+}
+
+type ovsdbResult struct {
+	Rows    []map[string]interface{} `json:"rows"`
+	Error   string                   `json:"error"`
+	Details string                   `json:"details"`
+}
+
+type ovsdbSelect struct {
+	Op      string        `json:"op"`
+	Table   string        `json:"table"`
+	Where   []interface{} `json:"where"`
+	Columns []string      `json:"columns"`
+}
+
+type tableQuery struct {
+	table   string
+	columns []string
+}
+
+// selectTables queries the given columns of the given tables of the
+// "Open_vSwitch" database in a single transaction and returns the rows per
+// table in the order of the queries
+func selectTables(network, address string, timeout time.Duration, queries []tableQuery) ([][]map[string]interface{}, error) {
+	conn, err := net.DialTimeout(network, address, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to OVSDB failed: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, fmt.Errorf("setting deadline failed: %w", err)
+	}
+
+	params := []interface{}{"Open_vSwitch"}
+	for _, q := range queries {
+		params = append(params, ovsdbSelect{
+			Op:      "select",
+			Table:   q.table,
+			Where:   make([]interface{}, 0),
+			Columns: q.columns,
+		})
+	}
+
+	if err := json.NewEncoder(conn).Encode(ovsdbRequest{Method: "transact", Params: params}); err != nil {
+		return nil, fmt.Errorf("sending request failed: %w", err)
+	}
+
+	var response ovsdbResponse
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return nil, fmt.Errorf("reading response failed: %w", err)
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("transaction failed: %v", response.Error)
+	}
+	if len(response.Result) != len(queries) {
+		return nil, fmt.Errorf("expected %d results but got %d", len(queries), len(response.Result))
+	}
+
+	results := make([][]map[string]interface{}, 0, len(queries))
+	for i, q := range queries {
+		r := response.Result[i]
+		if r.Error != "" {
+			return nil, fmt.Errorf("selecting table %q failed: %s %s", q.table, r.Error, r.Details)
+		}
+		results = append(results, r.Rows)
+	}
+	return results, nil
+}
+
+// ovsdbUUID returns the UUID string of an OVSDB atom of the form ["uuid", "<id>"]
+func ovsdbUUID(value interface{}) (string, error) {
+	v, ok := value.([]interface{})
+	if !ok || len(v) != 2 {
+		return "", fmt.Errorf("invalid uuid %v", value)
+	}
+	if kind, ok := v[0].(string); !ok || kind != "uuid" {
+		return "", fmt.Errorf("invalid uuid %v", value)
+	}
+	id, ok := v[1].(string)
+	if !ok {
+		return "", fmt.Errorf("invalid uuid %v", value)
+	}
+	return id, nil
+}
+
+// ovsdbUUIDSet returns the UUIDs of an OVSDB set. Sets with exactly one
+// element are encoded as the element itself.
+func ovsdbUUIDSet(value interface{}) ([]string, error) {
+	v, ok := value.([]interface{})
+	if !ok || len(v) != 2 {
+		return nil, fmt.Errorf("invalid set %v", value)
+	}
+	if kind, ok := v[0].(string); ok && kind == "uuid" {
+		id, err := ovsdbUUID(value)
+		if err != nil {
+			return nil, err
+		}
+		return []string{id}, nil
+	}
+	if kind, ok := v[0].(string); !ok || kind != "set" {
+		return nil, fmt.Errorf("invalid set %v", value)
+	}
+	elements, ok := v[1].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid set %v", value)
+	}
+	ids := make([]string, 0, len(elements))
+	for _, e := range elements {
+		id, err := ovsdbUUID(e)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ovsdbMap returns the key-value pairs of an OVSDB map of the form
+// ["map", [[key, value], ...]]
+func ovsdbMap(value interface{}) (map[string]interface{}, error) {
+	v, ok := value.([]interface{})
+	if !ok || len(v) != 2 {
+		return nil, fmt.Errorf("invalid map %v", value)
+	}
+	if kind, ok := v[0].(string); !ok || kind != "map" {
+		return nil, fmt.Errorf("invalid map %v", value)
+	}
+	pairs, ok := v[1].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid map %v", value)
+	}
+	m := make(map[string]interface{}, len(pairs))
+	for _, p := range pairs {
+		pair, ok := p.([]interface{})
+		if !ok || len(pair) != 2 {
+			return nil, errors.New("invalid map entry")
+		}
+		key, ok := pair[0].(string)
+		if !ok {
+			return nil, errors.New("invalid map key")
+		}
+		m[key] = pair[1]
+	}
+	return m, nil
+}
+
+// ovsdbString returns the string value of an atom or an empty string for an
+// empty set, i.e. an unset optional column
+func ovsdbString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,212 @@
+package openvswitch
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	upcallFlowsRe    = regexp.MustCompile(`\((current|avg|max|limit) (\d+)\)`)
+	aggregateFieldRe = regexp.MustCompile(`(packet_count|byte_count|flow_count)=(\d+)`)
+)
+
+type datapath struct {
+	name   string
+	fields map[string]interface{}
+}
+
+// parseDatapathStats parses the output of "ovs-appctl dpctl/show" e.g.
+//
+//	system@ovs-system:
+//	  lookups: hit:1346 missed:57 lost:0
+//	  flows: 3
+//	  masks: hit:2138 total:2 hit/pkt:1.52
+//	  cache: hit:1174 hit-rate:83.67%
+//	  port 0: ovs-system (internal)
+func parseDatapathStats(out []byte) []datapath {
+	var result []datapath
+	var current *datapath
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		// Datapath names start at the beginning of the line
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			result = append(result, datapath{
+				name:   strings.TrimSuffix(strings.TrimSpace(line), ":"),
+				fields: make(map[string]interface{}),
+			})
+			current = &result[len(result)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		key, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "lookups":
+			for k, v := range parseKeyValues(value) {
+				if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+					current.fields["lookups_"+k] = n
+				}
+			}
+			hit, hitOK := current.fields["lookups_hit"].(int64)
+			missed, missedOK := current.fields["lookups_missed"].(int64)
+			if hitOK && missedOK && hit+missed > 0 {
+				current.fields["lookups_hit_ratio"] = float64(hit) / float64(hit+missed)
+			}
+		case "flows":
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.fields["flows"] = n
+			}
+		case "masks":
+			for k, v := range parseKeyValues(value) {
+				if k == "hit/pkt" {
+					if f, err := strconv.ParseFloat(v, 64); err == nil {
+						current.fields["masks_hit_per_packet"] = f
+					}
+					continue
+				}
+				if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+					current.fields["masks_"+k] = n
+				}
+			}
+		case "cache":
+			for k, v := range parseKeyValues(value) {
+				if k == "hit-rate" {
+					if f, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64); err == nil {
+						current.fields["megaflow_cache_hit_rate"] = f
+					}
+					continue
+				}
+				if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+					current.fields["megaflow_cache_"+k] = n
+				}
+			}
+		}
+	}
+
+	// Remove datapaths without any statistics
+	filtered := result[:0]
+	for _, dp := range result {
+		if len(dp.fields) > 0 {
+			filtered = append(filtered, dp)
+		}
+	}
+	return filtered
+}
+
+// parseUpcallStats parses the output of "ovs-appctl upcall/show" e.g.
+//
+//	system@ovs-system:
+//	  flows         : (current 0) (avg 0) (max 0) (limit 10000)
+//	  offloaded flows : 0
+//	  dump duration : 1ms
+//	  ufid enabled : true
+//
+//	  5: (keys 0)
+func parseUpcallStats(out []byte) []datapath {
+	var result []datapath
+	var current *datapath
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			result = append(result, datapath{
+				name:   strings.TrimSuffix(strings.TrimSpace(line), ":"),
+				fields: make(map[string]interface{}),
+			})
+			current = &result[len(result)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		key, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		switch key {
+		case "flows":
+			for _, match := range upcallFlowsRe.FindAllStringSubmatch(value, -1) {
+				if n, err := strconv.ParseInt(match[2], 10, 64); err == nil {
+					current.fields["flows_"+match[1]] = n
+				}
+			}
+		case "offloaded flows":
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.fields["offloaded_flows"] = n
+			}
+		case "dump duration":
+			if n, err := strconv.ParseInt(strings.TrimSuffix(value, "ms"), 10, 64); err == nil {
+				current.fields["dump_duration_ms"] = n
+			}
+		default:
+			// Handler threads e.g. "5: (keys 0)"
+			if _, err := strconv.Atoi(key); err == nil {
+				keys := strings.TrimSuffix(strings.TrimPrefix(value, "(keys "), ")")
+				if n, err := strconv.ParseInt(keys, 10, 64); err == nil {
+					prev, _ := current.fields["handler_keys"].(int64)
+					current.fields["handler_keys"] = prev + n
+				}
+			}
+		}
+	}
+
+	filtered := result[:0]
+	for _, dp := range result {
+		if len(dp.fields) > 0 {
+			filtered = append(filtered, dp)
+		}
+	}
+	return filtered
+}
+
+// parseAggregateStats parses the output of "ovs-ofctl dump-aggregate <bridge>"
+// e.g. "NXST_AGGREGATE reply (xid=0x4): packet_count=0 byte_count=0 flow_count=4"
+func parseAggregateStats(out []byte) (map[string]interface{}, error) {
+	fields := make(map[string]interface{}, 3)
+	for _, match := range aggregateFieldRe.FindAllSubmatch(out, -1) {
+		n, err := strconv.ParseInt(string(match[2]), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		fields[string(match[1])] = n
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("no statistics found")
+	}
+	return fields, nil
+}
+
+// parseKeyValues splits strings like "hit:1346 missed:57 lost:0"
+func parseKeyValues(s string) map[string]string {
+	kv := make(map[string]string)
+	for _, item := range strings.Fields(s) {
+		if k, v, found := strings.Cut(item, ":"); found {
+			kv[k] = v
+		}
+	}
+	return kv
+}
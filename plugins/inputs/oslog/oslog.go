@@ -0,0 +1,42 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package oslog
+
+import (
+	_ "embed"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/process"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type OSLog struct {
+	Predicate    string          `toml:"predicate"`
+	Level        string          `toml:"level"`
+	LogCommand   string          `toml:"log_command"`
+	RestartDelay config.Duration `toml:"restart_delay"`
+	BufferSize   config.Size     `toml:"buffer_size"`
+	Log          telegraf.Logger `toml:"-"`
+
+	acc     telegraf.Accumulator
+	process *process.Process
+}
+
+func (*OSLog) SampleConfig() string {
+	return sampleConfig
+}
+
+func init() {
+	inputs.Add("oslog", func() telegraf.Input {
+		return &OSLog{
+			Level:        "default",
+			LogCommand:   "/usr/bin/log",
+			RestartDelay: config.Duration(10 * time.Second),
+			BufferSize:   config.Size(64 * 1024),
+		}
+	})
+}
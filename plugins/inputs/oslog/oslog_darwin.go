@@ -0,0 +1,185 @@
+//go:build darwin
+
+package oslog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/process"
+)
+
+// Timestamp format used by the 'log' tool, e.g. "2024-05-01 10:11:12.345678+0200"
+const timestampFormat = "2006-01-02 15:04:05.999999-0700"
+
+// entry is a single event of the 'log stream --style ndjson' output
+type entry struct {
+	Timestamp          string `json:"timestamp"`
+	EventType          string `json:"eventType"`
+	MessageType        string `json:"messageType"`
+	EventMessage       string `json:"eventMessage"`
+	Subsystem          string `json:"subsystem"`
+	Category           string `json:"category"`
+	ProcessImagePath   string `json:"processImagePath"`
+	SenderImagePath    string `json:"senderImagePath"`
+	ProcessID          int64  `json:"processID"`
+	ThreadID           uint64 `json:"threadID"`
+	ActivityIdentifier uint64 `json:"activityIdentifier"`
+	TraceID            uint64 `json:"traceID"`
+}
+
+func (o *OSLog) Init() error {
+	switch o.Level {
+	case "":
+		o.Level = "default"
+	case "default", "info", "debug":
+	default:
+		return fmt.Errorf("invalid level %q", o.Level)
+	}
+
+	if o.LogCommand == "" {
+		o.LogCommand = "/usr/bin/log"
+	}
+
+	return nil
+}
+
+func (o *OSLog) Start(acc telegraf.Accumulator) error {
+	o.acc = acc
+
+	proc, err := process.New(o.command(), nil)
+	if err != nil {
+		return fmt.Errorf("creating process failed: %w", err)
+	}
+	proc.ReadStdoutFn = o.readOutput
+	proc.ReadStderrFn = o.readError
+	proc.RestartDelay = time.Duration(o.RestartDelay)
+	proc.Log = o.Log
+	if err := proc.Start(); err != nil {
+		return fmt.Errorf("starting log stream failed: %w", err)
+	}
+	o.process = proc
+
+	return nil
+}
+
+func (*OSLog) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (o *OSLog) Stop() {
+	if o.process != nil {
+		o.process.Stop()
+	}
+}
+
+func (o *OSLog) command() []string {
+	cmd := []string{o.LogCommand, "stream", "--style", "ndjson", "--level", o.Level}
+	if o.Predicate != "" {
+		cmd = append(cmd, "--predicate", o.Predicate)
+	}
+	return cmd
+}
+
+func (o *OSLog) readOutput(out io.Reader) {
+	rdr := bufio.NewReaderSize(out, int(o.BufferSize))
+
+	for {
+		line, err := rdr.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			o.Log.Errorf("Dropping event exceeding the buffer size of %d bytes", o.BufferSize)
+			// Skip the remainder of the event
+			for errors.Is(err, bufio.ErrBufferFull) {
+				_, err = rdr.ReadSlice('\n')
+			}
+			if err == nil {
+				continue
+			}
+			line = nil
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			o.processLine(line)
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, os.ErrClosed) {
+				o.acc.AddError(fmt.Errorf("reading log stream failed: %w", err))
+			}
+			return
+		}
+	}
+}
+
+func (o *OSLog) processLine(line []byte) {
+	// The tool prints informational lines like the applied filter before the
+	// actual events, so skip everything not being a JSON object.
+	line = bytes.TrimSpace(line)
+	if !bytes.HasPrefix(line, []byte("{")) {
+		o.Log.Debugf("Skipping non-event line %q", line)
+		return
+	}
+
+	var e entry
+	if err := json.Unmarshal(line, &e); err != nil {
+		o.acc.AddError(fmt.Errorf("decoding event failed: %w", err))
+		return
+	}
+
+	timestamp, err := time.Parse(timestampFormat, e.Timestamp)
+	if err != nil {
+		o.Log.Debugf("Parsing timestamp %q failed, using current time: %v", e.Timestamp, err)
+		timestamp = time.Now()
+	}
+
+	tags := make(map[string]string, 5)
+	if e.EventType != "" {
+		tags["event_type"] = e.EventType
+	}
+	if e.MessageType != "" {
+		tags["message_type"] = strings.ToLower(e.MessageType)
+	}
+	if e.Subsystem != "" {
+		tags["subsystem"] = e.Subsystem
+	}
+	if e.Category != "" {
+		tags["category"] = e.Category
+	}
+	if e.ProcessImagePath != "" {
+		tags["process"] = filepath.Base(e.ProcessImagePath)
+	}
+
+	fields := map[string]interface{}{
+		"message":   e.EventMessage,
+		"pid":       e.ProcessID,
+		"thread_id": e.ThreadID,
+	}
+	if e.SenderImagePath != "" {
+		fields["sender"] = filepath.Base(e.SenderImagePath)
+	}
+	if e.ActivityIdentifier != 0 {
+		fields["activity_id"] = e.ActivityIdentifier
+	}
+	if e.TraceID != 0 {
+		fields["trace_id"] = e.TraceID
+	}
+
+	o.acc.AddFields("oslog", fields, tags, timestamp)
+}
+
+func (o *OSLog) readError(out io.Reader) {
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		o.Log.Errorf("stderr: %q", scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		o.acc.AddError(fmt.Errorf("reading stderr failed: %w", err))
+	}
+}
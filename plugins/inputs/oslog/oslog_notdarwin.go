@@ -0,0 +1,22 @@
+//go:build !darwin
+
+package oslog
+
+import (
+	"github.com/influxdata/telegraf"
+)
+
+func (o *OSLog) Init() error {
+	o.Log.Warn("Current platform is not supported")
+	return nil
+}
+
+func (*OSLog) Start(telegraf.Accumulator) error {
+	return nil
+}
+
+func (*OSLog) Gather(telegraf.Accumulator) error {
+	return nil
+}
+
+func (*OSLog) Stop() {}
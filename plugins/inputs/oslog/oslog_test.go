@@ -0,0 +1,122 @@
+//go:build darwin
+
+package oslog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const stream = `Filtering the log data using "subsystem == \"com.example.agent\""
+{"traceID":1234,"eventMessage":"Connection established","eventType":"logEvent","source":null,"formatString":"Connection %{public}s","activityIdentifier":42,"subsystem":"com.example.agent","category":"network","threadID":1337,"senderImageUUID":"9E3A","bootUUID":"","processImagePath":"/usr/local/bin/agent","timestamp":"2024-05-01 10:11:12.345678+0200","senderImagePath":"/usr/lib/libnetwork.dylib","machTimestamp":123456,"messageType":"Default","processImageUUID":"A1B2","processID":77,"senderProgramCounter":100,"parentActivityIdentifier":0,"timezoneName":""}
+{"traceID":0,"eventMessage":"Disk almost full","eventType":"logEvent","activityIdentifier":0,"subsystem":"","category":"","threadID":1,"processImagePath":"/usr/sbin/diskd","timestamp":"2024-05-01 10:11:13.000001+0200","messageType":"Fault","processID":12}
+`
+
+func TestInitInvalid(t *testing.T) {
+	plugin := &OSLog{Level: "trace", Log: testutil.Logger{}}
+	require.ErrorContains(t, plugin.Init(), `invalid level "trace"`)
+}
+
+func TestCommand(t *testing.T) {
+	plugin := &OSLog{
+		Predicate: `subsystem == "com.example.agent"`,
+		Level:     "info",
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, []string{
+		"/usr/bin/log", "stream", "--style", "ndjson", "--level", "info",
+		"--predicate", `subsystem == "com.example.agent"`,
+	}, plugin.command())
+}
+
+func TestStream(t *testing.T) {
+	// Emulate the log tool by a script printing the events and waiting
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "stream.ndjson"), []byte(stream), 0600))
+	script := filepath.Join(dir, "log")
+	content := "#!/bin/sh\ncat " + filepath.Join(dir, "stream.ndjson") + "\nexec sleep 60\n"
+	require.NoError(t, os.WriteFile(script, []byte(content), 0700)) //nolint:gosec // the script must be executable
+
+	plugin := &OSLog{
+		Level:      "default",
+		LogCommand: script,
+		BufferSize: 64 * 1024,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"oslog",
+			map[string]string{
+				"event_type":   "logEvent",
+				"message_type": "default",
+				"subsystem":    "com.example.agent",
+				"category":     "network",
+				"process":      "agent",
+			},
+			map[string]interface{}{
+				"message":     "Connection established",
+				"pid":         int64(77),
+				"thread_id":   uint64(1337),
+				"sender":      "libnetwork.dylib",
+				"activity_id": uint64(42),
+				"trace_id":    uint64(1234),
+			},
+			time.Date(2024, 5, 1, 8, 11, 12, 345678000, time.UTC),
+		),
+		metric.New(
+			"oslog",
+			map[string]string{
+				"event_type":   "logEvent",
+				"message_type": "fault",
+				"process":      "diskd",
+			},
+			map[string]interface{}{
+				"message":   "Disk almost full",
+				"pid":       int64(12),
+				"thread_id": uint64(1),
+			},
+			time.Date(2024, 5, 1, 8, 11, 13, 1000, time.UTC),
+		),
+	}
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= uint64(len(expected))
+	}, 5*time.Second, 50*time.Millisecond)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestBufferSizeLimit(t *testing.T) {
+	plugin := &OSLog{
+		BufferSize: 1024,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	plugin.acc = &acc
+
+	// Events exceeding the buffer size must be dropped without affecting the
+	// following events
+	long := `{"eventMessage":"` + strings.Repeat("x", 4096) + `","eventType":"logEvent","processID":1}` + "\n"
+	lines := strings.SplitAfter(stream, "\n")
+	plugin.readOutput(strings.NewReader(lines[0] + lines[1] + long + lines[2]))
+
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+	require.Empty(t, acc.Errors)
+}
@@ -0,0 +1,258 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package pdu
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/internal/snmp"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// OID of the sysObjectID used to detect the profile of an agent
+const sysObjectID = ".1.3.6.1.2.1.1.2.0"
+
+type PDU struct {
+	Agents  []string        `toml:"agents"`
+	Profile string          `toml:"profile"`
+	Collect []string        `toml:"collect"`
+	Log     telegraf.Logger `toml:"-"`
+	snmp.ClientConfig
+
+	tables map[string]map[string]*snmp.Table
+
+	// Connections and detected profiles, one per agent
+	connections []snmp.Connection
+	profiles    []*profile
+}
+
+func (*PDU) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *PDU) Init() error {
+	if len(p.Agents) == 0 {
+		return errors.New("no agents configured")
+	}
+
+	if p.Profile == "" {
+		p.Profile = "auto"
+	}
+	names := []string{"auto"}
+	for _, prof := range profiles {
+		names = append(names, prof.name)
+	}
+	if err := choice.Check(p.Profile, names); err != nil {
+		return fmt.Errorf("invalid 'profile' setting: %w", err)
+	}
+
+	if len(p.Collect) == 0 {
+		p.Collect = []string{"device", "inlets", "outlets", "sensors"}
+	}
+	if err := choice.CheckSlice(p.Collect, []string{"device", "inlets", "outlets", "sensors"}); err != nil {
+		return fmt.Errorf("invalid 'collect' setting: %w", err)
+	}
+
+	// Setup the tables of the profiles to query
+	p.tables = make(map[string]map[string]*snmp.Table, len(profiles))
+	for _, prof := range profiles {
+		if p.Profile != "auto" && p.Profile != prof.name {
+			continue
+		}
+		p.tables[prof.name] = make(map[string]*snmp.Table, len(p.Collect))
+		for _, c := range p.Collect {
+			t, found := prof.tables[c]
+			if !found {
+				continue
+			}
+			st := &snmp.Table{
+				Name:       t.measurement,
+				IndexAsTag: true,
+				Fields:     append([]snmp.Field(nil), t.fields...),
+			}
+			if err := st.Init(nil); err != nil {
+				return fmt.Errorf("initializing %s table of profile %q failed: %w", c, prof.name, err)
+			}
+			p.tables[prof.name][c] = st
+		}
+	}
+
+	p.connections = make([]snmp.Connection, len(p.Agents))
+	p.profiles = make([]*profile, len(p.Agents))
+	if p.Profile != "auto" {
+		for i := range p.profiles {
+			p.profiles[i] = profileByName(p.Profile)
+		}
+	}
+
+	return nil
+}
+
+func (p *PDU) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for i, agent := range p.Agents {
+		wg.Add(1)
+		go func(idx int, agent string) {
+			defer wg.Done()
+			if err := p.gatherAgent(acc, idx); err != nil {
+				acc.AddError(fmt.Errorf("agent %s: %w", agent, err))
+			}
+		}(i, agent)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (p *PDU) gatherAgent(acc telegraf.Accumulator, idx int) error {
+	gs, err := p.getConnection(idx)
+	if err != nil {
+		return fmt.Errorf("connecting failed: %w", err)
+	}
+
+	prof := p.profiles[idx]
+	if prof == nil {
+		if prof, err = detectProfile(gs); err != nil {
+			return err
+		}
+		p.Log.Debugf("Using profile %q for agent %s", prof.name, p.Agents[idx])
+		p.profiles[idx] = prof
+	}
+
+	for _, c := range p.Collect {
+		st, found := p.tables[prof.name][c]
+		if !found {
+			continue
+		}
+		if err := p.gatherTable(acc, gs, prof, st, prof.tables[c]); err != nil {
+			acc.AddError(fmt.Errorf("agent %s: gathering %s failed: %w", p.Agents[idx], c, err))
+		}
+	}
+
+	return nil
+}
+
+func (*PDU) gatherTable(acc telegraf.Accumulator, gs snmp.Connection, prof *profile, st *snmp.Table, t table) error {
+	rt, err := st.Build(gs, true)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rt.Rows {
+		for name, states := range t.states {
+			v, ok := toInt(row.Fields[name])
+			if !ok {
+				continue
+			}
+			if state, found := states[v]; found {
+				row.Fields[name] = state
+			} else {
+				row.Fields[name] = "unknown"
+			}
+		}
+		if t.transform != nil {
+			t.transform(row.Fields)
+		}
+		if len(row.Fields) == 0 {
+			continue
+		}
+
+		if idx, found := row.Tags["index"]; found {
+			delete(row.Tags, "index")
+			row.Tags[t.index] = idx
+		}
+		row.Tags["source"] = gs.Host()
+		row.Tags["profile"] = prof.name
+		acc.AddFields(rt.Name, row.Fields, row.Tags, rt.Time)
+	}
+
+	return nil
+}
+
+func (p *PDU) getConnection(idx int) (snmp.Connection, error) {
+	if gs := p.connections[idx]; gs != nil {
+		return gs, nil
+	}
+
+	gs, err := snmp.NewWrapper(p.ClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := gs.SetAgent(p.Agents[idx]); err != nil {
+		return nil, err
+	}
+	if err := gs.Connect(); err != nil {
+		return nil, fmt.Errorf("setting up connection: %w", err)
+	}
+	p.connections[idx] = gs
+
+	return gs, nil
+}
+
+// detectProfile selects the profile by the enterprise of the sysObjectID of
+// the agent
+func detectProfile(gs snmp.Connection) (*profile, error) {
+	pkt, err := gs.Get([]string{sysObjectID})
+	if err != nil {
+		return nil, fmt.Errorf("querying sysObjectID failed: %w", err)
+	}
+	if len(pkt.Variables) != 1 || pkt.Variables[0].Type == gosnmp.NoSuchObject || pkt.Variables[0].Type == gosnmp.NoSuchInstance {
+		return nil, errors.New("agent did not return the sysObjectID")
+	}
+
+	var oid string
+	switch v := pkt.Variables[0].Value.(type) {
+	case string:
+		oid = v
+	case []byte:
+		oid = string(v)
+	}
+	if !strings.HasPrefix(oid, ".") {
+		oid = "." + oid
+	}
+	for _, prof := range profiles {
+		if strings.HasPrefix(oid, prof.enterprise) {
+			return prof, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no profile for device with sysObjectID %q", oid)
+}
+
+func profileByName(name string) *profile {
+	for _, prof := range profiles {
+		if prof.name == name {
+			return prof
+		}
+	}
+	return nil
+}
+
+func init() {
+	inputs.Add("pdu", func() telegraf.Input {
+		return &PDU{
+			Profile: "auto",
+			Collect: []string{"device", "inlets", "outlets", "sensors"},
+			ClientConfig: snmp.ClientConfig{
+				Retries:         3,
+				MaxRepetitions:  10,
+				BulkWalkColumns: 10,
+				Timeout:         config.Duration(5 * time.Second),
+				Version:         2,
+				Community:       "public",
+			},
+		}
+	})
+}
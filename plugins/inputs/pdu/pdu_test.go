@@ -0,0 +1,322 @@
+package pdu
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/snmp"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type testConnection struct {
+	host   string
+	values map[string]interface{}
+}
+
+func (tc *testConnection) Host() string {
+	return tc.host
+}
+
+func (tc *testConnection) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	sp := &gosnmp.SnmpPacket{}
+	for _, oid := range oids {
+		v, ok := tc.values[oid]
+		if !ok {
+			sp.Variables = append(sp.Variables, gosnmp.SnmpPDU{Name: oid, Type: gosnmp.NoSuchObject})
+			continue
+		}
+		sp.Variables = append(sp.Variables, gosnmp.SnmpPDU{Name: oid, Type: gosnmp.ObjectIdentifier, Value: v})
+	}
+	return sp, nil
+}
+
+func (tc *testConnection) Walk(oid string, wf gosnmp.WalkFunc) error {
+	for name, v := range tc.values {
+		if !strings.HasPrefix(name, oid+".") {
+			continue
+		}
+		if err := wf(gosnmp.SnmpPDU{Name: name, Value: v}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*testConnection) Reconnect() error {
+	return nil
+}
+
+var apcConnection = &testConnection{
+	host: "apc",
+	values: map[string]interface{}{
+		sysObjectID: ".1.3.6.1.4.1.318.1.3.4.5",
+		// Device
+		".1.3.6.1.4.1.318.1.1.26.4.3.1.4.1": 152,
+		".1.3.6.1.4.1.318.1.1.26.4.3.1.9.1": 12345,
+		// Phases
+		".1.3.6.1.4.1.318.1.1.26.6.3.1.4.1": 2,
+		".1.3.6.1.4.1.318.1.1.26.6.3.1.5.1": 66,
+		".1.3.6.1.4.1.318.1.1.26.6.3.1.6.1": 230,
+		".1.3.6.1.4.1.318.1.1.26.6.3.1.7.1": 152,
+		// Outlets
+		".1.3.6.1.4.1.318.1.1.26.9.4.3.1.3.1":  []byte("server01"),
+		".1.3.6.1.4.1.318.1.1.26.9.4.3.1.3.2":  []byte("server02"),
+		".1.3.6.1.4.1.318.1.1.26.9.2.3.1.5.1":  2,
+		".1.3.6.1.4.1.318.1.1.26.9.2.3.1.5.2":  1,
+		".1.3.6.1.4.1.318.1.1.26.9.4.3.1.6.1":  12,
+		".1.3.6.1.4.1.318.1.1.26.9.4.3.1.6.2":  0,
+		".1.3.6.1.4.1.318.1.1.26.9.4.3.1.7.1":  276,
+		".1.3.6.1.4.1.318.1.1.26.9.4.3.1.7.2":  0,
+		".1.3.6.1.4.1.318.1.1.26.9.4.3.1.11.1": 2104,
+		".1.3.6.1.4.1.318.1.1.26.9.4.3.1.11.2": 15,
+		// Sensors
+		".1.3.6.1.4.1.318.1.1.26.10.2.2.1.3.1":  []byte("rack01_top"),
+		".1.3.6.1.4.1.318.1.1.26.10.2.2.1.8.1":  245,
+		".1.3.6.1.4.1.318.1.1.26.10.2.2.1.9.1":  4,
+		".1.3.6.1.4.1.318.1.1.26.10.2.2.1.10.1": 41,
+		".1.3.6.1.4.1.318.1.1.26.10.2.2.1.11.1": 9,
+		".1.3.6.1.4.1.318.1.1.26.10.2.1.1.9.1":  40,
+		".1.3.6.1.4.1.318.1.1.26.10.2.1.1.10.1": 35,
+		".1.3.6.1.4.1.318.1.1.26.10.2.1.1.13.1": 20,
+		".1.3.6.1.4.1.318.1.1.26.10.2.1.1.14.1": 10,
+	},
+}
+
+var raritanConnection = &testConnection{
+	host: "raritan",
+	values: map[string]interface{}{
+		sysObjectID: ".1.3.6.1.4.1.13742.6",
+		// Inlet 1 of PDU 1
+		".1.3.6.1.4.1.13742.6.5.2.3.1.4.1.1.1": uint(6600),
+		".1.3.6.1.4.1.13742.6.5.2.3.1.4.1.1.4": uint(231),
+		".1.3.6.1.4.1.13742.6.5.2.3.1.4.1.1.5": uint(1520),
+		".1.3.6.1.4.1.13742.6.5.2.3.1.4.1.1.8": uint(1234500),
+		// Outlet 1 of PDU 1
+		".1.3.6.1.4.1.13742.6.3.5.3.1.3.1.1":    []byte("server01"),
+		".1.3.6.1.4.1.13742.6.5.4.3.1.3.1.1.14": 7,
+		".1.3.6.1.4.1.13742.6.5.4.3.1.4.1.1.1":  uint(1200),
+		".1.3.6.1.4.1.13742.6.5.4.3.1.4.1.1.5":  uint(276),
+		".1.3.6.1.4.1.13742.6.5.4.3.1.4.1.1.8":  uint(210400),
+		// Temperature sensor 1, humidity sensor 2 and contact closure 3
+		".1.3.6.1.4.1.13742.6.3.6.3.1.4.1.1":  []byte("inlet_temp"),
+		".1.3.6.1.4.1.13742.6.3.6.3.1.4.1.2":  []byte("inlet_humidity"),
+		".1.3.6.1.4.1.13742.6.3.6.3.1.4.1.3":  []byte("door"),
+		".1.3.6.1.4.1.13742.6.3.6.3.1.2.1.1":  10,
+		".1.3.6.1.4.1.13742.6.3.6.3.1.2.1.2":  11,
+		".1.3.6.1.4.1.13742.6.3.6.3.1.2.1.3":  14,
+		".1.3.6.1.4.1.13742.6.3.6.3.1.17.1.1": uint(1),
+		".1.3.6.1.4.1.13742.6.3.6.3.1.17.1.2": uint(0),
+		".1.3.6.1.4.1.13742.6.3.6.3.1.17.1.3": uint(0),
+		".1.3.6.1.4.1.13742.6.3.6.3.1.31.1.1": uint(50),
+		".1.3.6.1.4.1.13742.6.3.6.3.1.31.1.2": uint(10),
+		".1.3.6.1.4.1.13742.6.3.6.3.1.32.1.1": uint(100),
+		".1.3.6.1.4.1.13742.6.3.6.3.1.32.1.2": uint(20),
+		".1.3.6.1.4.1.13742.6.3.6.3.1.33.1.1": uint(400),
+		".1.3.6.1.4.1.13742.6.3.6.3.1.33.1.2": uint(80),
+		".1.3.6.1.4.1.13742.6.3.6.3.1.34.1.1": uint(350),
+		".1.3.6.1.4.1.13742.6.3.6.3.1.34.1.2": uint(70),
+		".1.3.6.1.4.1.13742.6.5.5.3.1.4.1.1":  uint(238),
+		".1.3.6.1.4.1.13742.6.5.5.3.1.4.1.2":  uint(45),
+		".1.3.6.1.4.1.13742.6.5.5.3.1.4.1.3":  uint(1),
+	},
+}
+
+func TestGatherAPC(t *testing.T) {
+	plugin := &PDU{
+		Agents:  []string{"udp://10.0.0.10:161"},
+		Profile: "apc",
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.connections[0] = apcConnection
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"pdu",
+			map[string]string{"source": "apc", "profile": "apc", "module": "1"},
+			map[string]interface{}{"power_watts": float64(1520), "energy_kwh": 1234.5},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"pdu_inlet",
+			map[string]string{"source": "apc", "profile": "apc", "inlet": "1"},
+			map[string]interface{}{
+				"load_state":    "normal",
+				"current_amps":  6.6,
+				"voltage_volts": float64(230),
+				"power_watts":   float64(1520),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"pdu_outlet",
+			map[string]string{"source": "apc", "profile": "apc", "outlet": "1", "name": "server01"},
+			map[string]interface{}{
+				"state":        "on",
+				"current_amps": 1.2,
+				"power_watts":  float64(276),
+				"energy_kwh":   210.4,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"pdu_outlet",
+			map[string]string{"source": "apc", "profile": "apc", "outlet": "2", "name": "server02"},
+			map[string]interface{}{
+				"state":        "off",
+				"current_amps": float64(0),
+				"power_watts":  float64(0),
+				"energy_kwh":   1.5,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"pdu_sensor",
+			map[string]string{"source": "apc", "profile": "apc", "sensor": "1", "name": "rack01_top"},
+			map[string]interface{}{
+				"temperature_celsius":          24.5,
+				"temperature_status":           "normal",
+				"temperature_warning_celsius":  float64(35),
+				"temperature_critical_celsius": float64(40),
+				"humidity_percent":             float64(41),
+				"humidity_status":              "unknown",
+				"humidity_warning_percent":     float64(20),
+				"humidity_critical_percent":    float64(10),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherRaritan(t *testing.T) {
+	plugin := &PDU{
+		Agents:  []string{"udp://10.0.0.11:161"},
+		Profile: "raritan",
+		Collect: []string{"inlets", "outlets", "sensors"},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.connections[0] = raritanConnection
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"pdu_inlet",
+			map[string]string{"source": "raritan", "profile": "raritan", "inlet": "1.1"},
+			map[string]interface{}{
+				"current_amps":  6.6,
+				"voltage_volts": float64(231),
+				"power_watts":   float64(1520),
+				"energy_kwh":    1234.5,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"pdu_outlet",
+			map[string]string{"source": "raritan", "profile": "raritan", "outlet": "1.1", "name": "server01"},
+			map[string]interface{}{
+				"state":        "on",
+				"current_amps": 1.2,
+				"power_watts":  float64(276),
+				"energy_kwh":   210.4,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"pdu_sensor",
+			map[string]string{"source": "raritan", "profile": "raritan", "sensor": "1.1", "name": "inlet_temp"},
+			map[string]interface{}{
+				"temperature_celsius":          23.8,
+				"temperature_warning_celsius":  float64(35),
+				"temperature_critical_celsius": float64(40),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"pdu_sensor",
+			map[string]string{"source": "raritan", "profile": "raritan", "sensor": "1.2", "name": "inlet_humidity"},
+			map[string]interface{}{
+				"humidity_percent":          float64(45),
+				"humidity_warning_percent":  float64(20),
+				"humidity_critical_percent": float64(10),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherAutoDetect(t *testing.T) {
+	unknown := &testConnection{
+		host:   "unknown",
+		values: map[string]interface{}{sysObjectID: ".1.3.6.1.4.1.9.1.1"},
+	}
+
+	plugin := &PDU{
+		Agents:  []string{"udp://10.0.0.10:161", "udp://10.0.0.11:161", "udp://10.0.0.12:161"},
+		Collect: []string{"outlets"},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.connections = []snmp.Connection{apcConnection, raritanConnection, unknown}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], `no profile for device with sysObjectID ".1.3.6.1.4.1.9.1.1"`)
+
+	profiles := make(map[string]int)
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.Equal(t, "pdu_outlet", m.Name())
+		source, _ := m.GetTag("source")
+		profile, _ := m.GetTag("profile")
+		require.Equal(t, source, profile)
+		profiles[profile]++
+	}
+	require.Equal(t, map[string]int{"apc": 2, "raritan": 1}, profiles)
+	require.Equal(t, "apc", plugin.profiles[0].name)
+	require.Equal(t, "raritan", plugin.profiles[1].name)
+	require.Nil(t, plugin.profiles[2])
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *PDU
+		expected string
+	}{
+		{
+			name:     "no agents",
+			plugin:   &PDU{},
+			expected: "no agents configured",
+		},
+		{
+			name:     "invalid profile",
+			plugin:   &PDU{Agents: []string{"127.0.0.1"}, Profile: "foo"},
+			expected: "invalid 'profile' setting",
+		},
+		{
+			name:     "invalid collect",
+			plugin:   &PDU{Agents: []string{"127.0.0.1"}, Collect: []string{"foo"}},
+			expected: "invalid 'collect' setting",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
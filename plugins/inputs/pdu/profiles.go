@@ -0,0 +1,309 @@
+package pdu
+
+import (
+	"math"
+
+	"github.com/influxdata/telegraf/internal/snmp"
+)
+
+// profile describes how to query the metrics of the devices of a vendor
+type profile struct {
+	name string
+	// Prefix of the sysObjectID of the devices covered by the profile
+	enterprise string
+	// Tables of the profile keyed by the collected data
+	tables map[string]table
+}
+
+// table describes a SNMP table mapped to a measurement
+type table struct {
+	measurement string
+	// Tag name of the row index
+	index  string
+	fields []snmp.Field
+	// Names of the values of enumerated fields
+	states map[string]map[int64]string
+	// Vendor specific conversion of the row fields
+	transform func(fields map[string]interface{})
+}
+
+var profiles = []*profile{apc, raritan, vertiv}
+
+// Status of the APC sensors compared to the thresholds
+var apcSensorStates = map[int64]string{
+	1: "not_present",
+	2: "below_min",
+	3: "below_low",
+	4: "normal",
+	5: "above_high",
+	6: "above_max",
+}
+
+// APC switched rack PDUs (PowerNet-MIB, rPDU2 objects)
+var apc = &profile{
+	name:       "apc",
+	enterprise: ".1.3.6.1.4.1.318.",
+	tables: map[string]table{
+		"device": {
+			measurement: "pdu",
+			index:       "module",
+			fields: []snmp.Field{
+				// rPDU2DeviceStatusPower in hundredths of kW
+				{Name: "power_watts", Oid: ".1.3.6.1.4.1.318.1.1.26.4.3.1.4", Conversion: "float(-1)"},
+				// rPDU2DeviceStatusEnergy in tenths of kWh
+				{Name: "energy_kwh", Oid: ".1.3.6.1.4.1.318.1.1.26.4.3.1.9", Conversion: "float(1)"},
+			},
+		},
+		"inlets": {
+			measurement: "pdu_inlet",
+			index:       "inlet",
+			fields: []snmp.Field{
+				// rPDU2PhaseStatusLoadState
+				{Name: "load_state", Oid: ".1.3.6.1.4.1.318.1.1.26.6.3.1.4"},
+				// rPDU2PhaseStatusCurrent in tenths of A
+				{Name: "current_amps", Oid: ".1.3.6.1.4.1.318.1.1.26.6.3.1.5", Conversion: "float(1)"},
+				// rPDU2PhaseStatusVoltage in V
+				{Name: "voltage_volts", Oid: ".1.3.6.1.4.1.318.1.1.26.6.3.1.6", Conversion: "float"},
+				// rPDU2PhaseStatusPower in hundredths of kW
+				{Name: "power_watts", Oid: ".1.3.6.1.4.1.318.1.1.26.6.3.1.7", Conversion: "float(-1)"},
+			},
+			states: map[string]map[int64]string{
+				"load_state": {1: "low_load", 2: "normal", 3: "near_overload", 4: "overload"},
+			},
+		},
+		"outlets": {
+			measurement: "pdu_outlet",
+			index:       "outlet",
+			fields: []snmp.Field{
+				// rPDU2OutletMeteredStatusName
+				{Name: "name", Oid: ".1.3.6.1.4.1.318.1.1.26.9.4.3.1.3", IsTag: true},
+				// rPDU2OutletSwitchedStatusState
+				{Name: "state", Oid: ".1.3.6.1.4.1.318.1.1.26.9.2.3.1.5"},
+				// rPDU2OutletMeteredStatusCurrent in tenths of A
+				{Name: "current_amps", Oid: ".1.3.6.1.4.1.318.1.1.26.9.4.3.1.6", Conversion: "float(1)"},
+				// rPDU2OutletMeteredStatusPower in W
+				{Name: "power_watts", Oid: ".1.3.6.1.4.1.318.1.1.26.9.4.3.1.7", Conversion: "float"},
+				// rPDU2OutletMeteredStatusEnergy in tenths of kWh
+				{Name: "energy_kwh", Oid: ".1.3.6.1.4.1.318.1.1.26.9.4.3.1.11", Conversion: "float(1)"},
+			},
+			states: map[string]map[int64]string{
+				"state": {1: "off", 2: "on"},
+			},
+		},
+		"sensors": {
+			measurement: "pdu_sensor",
+			index:       "sensor",
+			fields: []snmp.Field{
+				// rPDU2SensorTempHumidityStatusName
+				{Name: "name", Oid: ".1.3.6.1.4.1.318.1.1.26.10.2.2.1.3", IsTag: true},
+				// rPDU2SensorTempHumidityStatusTempC in tenths of °C
+				{Name: "temperature_celsius", Oid: ".1.3.6.1.4.1.318.1.1.26.10.2.2.1.8", Conversion: "float(1)"},
+				// rPDU2SensorTempHumidityStatusTempStatus
+				{Name: "temperature_status", Oid: ".1.3.6.1.4.1.318.1.1.26.10.2.2.1.9"},
+				// rPDU2SensorTempHumidityStatusRelativeHumidity in %
+				{Name: "humidity_percent", Oid: ".1.3.6.1.4.1.318.1.1.26.10.2.2.1.10", Conversion: "float"},
+				// rPDU2SensorTempHumidityStatusHumidityStatus
+				{Name: "humidity_status", Oid: ".1.3.6.1.4.1.318.1.1.26.10.2.2.1.11"},
+				// rPDU2SensorTempHumidityConfigTempMaxThreshC in °C
+				{Name: "temperature_critical_celsius", Oid: ".1.3.6.1.4.1.318.1.1.26.10.2.1.1.9", Conversion: "float"},
+				// rPDU2SensorTempHumidityConfigTempHighThreshC in °C
+				{Name: "temperature_warning_celsius", Oid: ".1.3.6.1.4.1.318.1.1.26.10.2.1.1.10", Conversion: "float"},
+				// rPDU2SensorTempHumidityConfigHumidityLowThresh in %
+				{Name: "humidity_warning_percent", Oid: ".1.3.6.1.4.1.318.1.1.26.10.2.1.1.13", Conversion: "float"},
+				// rPDU2SensorTempHumidityConfigHumidityMinThresh in %
+				{Name: "humidity_critical_percent", Oid: ".1.3.6.1.4.1.318.1.1.26.10.2.1.1.14", Conversion: "float"},
+			},
+			states: map[string]map[int64]string{
+				"temperature_status": apcSensorStates,
+				"humidity_status":    apcSensorStates,
+			},
+		},
+	},
+}
+
+// Raritan PX PDUs (PDU2-MIB). The measurement tables are indexed by the PDU,
+// the inlet or outlet and the sensor type, so the sensor type is stripped from
+// the index to join the readings of an inlet or outlet.
+var raritan = &profile{
+	name:       "raritan",
+	enterprise: ".1.3.6.1.4.1.13742.",
+	tables: map[string]table{
+		"inlets": {
+			measurement: "pdu_inlet",
+			index:       "inlet",
+			fields: []snmp.Field{
+				// measurementsInletSensorValue of the rmsCurrent sensor in mA
+				{Name: "current_amps", Oid: ".1.3.6.1.4.1.13742.6.5.2.3.1.4", OidIndexSuffix: ".1", Conversion: "float(3)"},
+				// measurementsInletSensorValue of the rmsVoltage sensor in V
+				{Name: "voltage_volts", Oid: ".1.3.6.1.4.1.13742.6.5.2.3.1.4", OidIndexSuffix: ".4", Conversion: "float"},
+				// measurementsInletSensorValue of the activePower sensor in W
+				{Name: "power_watts", Oid: ".1.3.6.1.4.1.13742.6.5.2.3.1.4", OidIndexSuffix: ".5", Conversion: "float"},
+				// measurementsInletSensorValue of the activeEnergy sensor in Wh
+				{Name: "energy_kwh", Oid: ".1.3.6.1.4.1.13742.6.5.2.3.1.4", OidIndexSuffix: ".8", Conversion: "float(3)"},
+			},
+		},
+		"outlets": {
+			measurement: "pdu_outlet",
+			index:       "outlet",
+			fields: []snmp.Field{
+				// outletName
+				{Name: "name", Oid: ".1.3.6.1.4.1.13742.6.3.5.3.1.3", IsTag: true},
+				// measurementsOutletSensorState of the onOff sensor
+				{Name: "state", Oid: ".1.3.6.1.4.1.13742.6.5.4.3.1.3", OidIndexSuffix: ".14"},
+				// measurementsOutletSensorValue of the rmsCurrent sensor in mA
+				{Name: "current_amps", Oid: ".1.3.6.1.4.1.13742.6.5.4.3.1.4", OidIndexSuffix: ".1", Conversion: "float(3)"},
+				// measurementsOutletSensorValue of the activePower sensor in W
+				{Name: "power_watts", Oid: ".1.3.6.1.4.1.13742.6.5.4.3.1.4", OidIndexSuffix: ".5", Conversion: "float"},
+				// measurementsOutletSensorValue of the activeEnergy sensor in Wh
+				{Name: "energy_kwh", Oid: ".1.3.6.1.4.1.13742.6.5.4.3.1.4", OidIndexSuffix: ".8", Conversion: "float(3)"},
+			},
+			states: map[string]map[int64]string{
+				"state": {7: "on", 8: "off"},
+			},
+		},
+		"sensors": {
+			measurement: "pdu_sensor",
+			index:       "sensor",
+			fields: []snmp.Field{
+				// externalSensorName
+				{Name: "name", Oid: ".1.3.6.1.4.1.13742.6.3.6.3.1.4", IsTag: true},
+				// externalSensorType
+				{Name: "type", Oid: ".1.3.6.1.4.1.13742.6.3.6.3.1.2"},
+				// externalSensorDecimalDigits
+				{Name: "decimals", Oid: ".1.3.6.1.4.1.13742.6.3.6.3.1.17"},
+				// externalSensorLowerCriticalThreshold
+				{Name: "lower_critical", Oid: ".1.3.6.1.4.1.13742.6.3.6.3.1.31"},
+				// externalSensorLowerWarningThreshold
+				{Name: "lower_warning", Oid: ".1.3.6.1.4.1.13742.6.3.6.3.1.32"},
+				// externalSensorUpperCriticalThreshold
+				{Name: "upper_critical", Oid: ".1.3.6.1.4.1.13742.6.3.6.3.1.33"},
+				// externalSensorUpperWarningThreshold
+				{Name: "upper_warning", Oid: ".1.3.6.1.4.1.13742.6.3.6.3.1.34"},
+				// measurementsExternalSensorValue
+				{Name: "value", Oid: ".1.3.6.1.4.1.13742.6.5.5.3.1.4"},
+			},
+			transform: raritanSensor,
+		},
+	},
+}
+
+// raritanSensor scales the raw readings and thresholds of a Raritan external
+// sensor by its decimal digits and names them by the sensor type
+func raritanSensor(fields map[string]interface{}) {
+	sensorType, _ := toInt(fields["type"])
+	decimals, _ := toInt(fields["decimals"])
+	raw := map[string]interface{}{
+		"value":          fields["value"],
+		"lower_critical": fields["lower_critical"],
+		"lower_warning":  fields["lower_warning"],
+		"upper_critical": fields["upper_critical"],
+		"upper_warning":  fields["upper_warning"],
+	}
+	clear(fields)
+
+	// Only temperature and humidity sensors are reported, thresholds are
+	// named like the ones of the other profiles
+	var names map[string]string
+	switch sensorType {
+	case 10:
+		names = map[string]string{
+			"value":          "temperature_celsius",
+			"upper_critical": "temperature_critical_celsius",
+			"upper_warning":  "temperature_warning_celsius",
+		}
+	case 11:
+		names = map[string]string{
+			"value":          "humidity_percent",
+			"lower_critical": "humidity_critical_percent",
+			"lower_warning":  "humidity_warning_percent",
+		}
+	default:
+		return
+	}
+	for k, name := range names {
+		if v, ok := toInt(raw[k]); ok {
+			fields[name] = float64(v) / math.Pow10(int(decimals))
+		}
+	}
+}
+
+// Vertiv Geist rack PDUs (GEIST-V5-MIB)
+var vertiv = &profile{
+	name:       "vertiv",
+	enterprise: ".1.3.6.1.4.1.21239.",
+	tables: map[string]table{
+		"device": {
+			measurement: "pdu",
+			index:       "module",
+			fields: []snmp.Field{
+				// totalRealPower in W
+				{Name: "power_watts", Oid: ".1.3.6.1.4.1.21239.5.1.3.1.8", Conversion: "float"},
+				// totalEnergy in Wh
+				{Name: "energy_kwh", Oid: ".1.3.6.1.4.1.21239.5.1.3.1.13", Conversion: "float(3)"},
+			},
+		},
+		"inlets": {
+			measurement: "pdu_inlet",
+			index:       "inlet",
+			fields: []snmp.Field{
+				// phaseLabel
+				{Name: "name", Oid: ".1.3.6.1.4.1.21239.5.1.4.1.2", IsTag: true},
+				// phaseVoltage in tenths of V
+				{Name: "voltage_volts", Oid: ".1.3.6.1.4.1.21239.5.1.4.1.4", Conversion: "float(1)"},
+				// phaseCurrent in hundredths of A
+				{Name: "current_amps", Oid: ".1.3.6.1.4.1.21239.5.1.4.1.8", Conversion: "float(2)"},
+				// phaseRealPower in W
+				{Name: "power_watts", Oid: ".1.3.6.1.4.1.21239.5.1.4.1.10", Conversion: "float"},
+			},
+		},
+		"outlets": {
+			measurement: "pdu_outlet",
+			index:       "outlet",
+			fields: []snmp.Field{
+				// outletLabel
+				{Name: "name", Oid: ".1.3.6.1.4.1.21239.5.1.5.1.2", IsTag: true},
+				// outletCurrent in hundredths of A
+				{Name: "current_amps", Oid: ".1.3.6.1.4.1.21239.5.1.5.1.4", Conversion: "float(2)"},
+				// outletRealPower in W
+				{Name: "power_watts", Oid: ".1.3.6.1.4.1.21239.5.1.5.1.5", Conversion: "float"},
+				// outletEnergy in Wh
+				{Name: "energy_kwh", Oid: ".1.3.6.1.4.1.21239.5.1.5.1.9", Conversion: "float(3)"},
+			},
+		},
+		"sensors": {
+			measurement: "pdu_sensor",
+			index:       "sensor",
+			fields: []snmp.Field{
+				// internalName
+				{Name: "name", Oid: ".1.3.6.1.4.1.21239.5.1.2.1.3", IsTag: true},
+				// internalTemp in tenths of °C
+				{Name: "temperature_celsius", Oid: ".1.3.6.1.4.1.21239.5.1.2.1.5", Conversion: "float(1)"},
+				// internalHumidity in %
+				{Name: "humidity_percent", Oid: ".1.3.6.1.4.1.21239.5.1.2.1.6", Conversion: "float"},
+				// internalTempHighThreshold in tenths of °C
+				{Name: "temperature_warning_celsius", Oid: ".1.3.6.1.4.1.21239.5.1.2.1.8", Conversion: "float(1)"},
+				// internalTempCriticalThreshold in tenths of °C
+				{Name: "temperature_critical_celsius", Oid: ".1.3.6.1.4.1.21239.5.1.2.1.9", Conversion: "float(1)"},
+			},
+		},
+	},
+}
+
+// toInt converts the integer values returned by the agent
+func toInt(v interface{}) (int64, bool) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	}
+	return 0, false
+}
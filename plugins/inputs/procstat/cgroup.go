@@ -0,0 +1,304 @@
+package procstat
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	gopsprocess "github.com/shirou/gopsutil/v4/process"
+
+	"github.com/influxdata/telegraf"
+)
+
+// cgroupSummary accumulates the resource usage of the monitored processes
+// within a single cgroup
+type cgroupSummary struct {
+	processes     int64
+	cpuUsage      float64
+	cpuTimeUser   float64
+	cpuTimeSystem float64
+	memoryRSS     uint64
+	memoryVMS     uint64
+	numFDs        int64
+	numThreads    int64
+	hasCPU        bool
+	hasMemory     bool
+}
+
+// cgroupAggregator summarizes the metrics of the processes collected in one
+// gather cycle by their cgroup
+type cgroupAggregator struct {
+	prefix    string
+	lookup    func(pid) (string, error)
+	summaries map[string]*cgroupSummary
+	cgroups   map[pid]string
+}
+
+func newCgroupAggregator(prefix string, lookup func(pid) (string, error)) *cgroupAggregator {
+	if prefix != "" {
+		prefix += "_"
+	}
+	return &cgroupAggregator{
+		prefix:    prefix,
+		lookup:    lookup,
+		summaries: make(map[string]*cgroupSummary),
+		cgroups:   make(map[pid]string),
+	}
+}
+
+// add accounts the metrics of the process with the given ID to its cgroup.
+// Processes matching multiple filters are only accounted once.
+func (a *cgroupAggregator) add(id pid, metrics []telegraf.Metric) {
+	if _, found := a.cgroups[id]; found {
+		return
+	}
+	cgroup, err := a.lookup(id)
+	if err != nil {
+		// The process probably ended in the meantime
+		return
+	}
+	a.cgroups[id] = cgroup
+
+	summary, found := a.summaries[cgroup]
+	if !found {
+		summary = &cgroupSummary{}
+		a.summaries[cgroup] = summary
+	}
+	summary.processes++
+
+	for _, m := range metrics {
+		if m.Name() != "procstat" {
+			continue
+		}
+		fields := m.Fields()
+		if v, ok := fields[a.prefix+"cpu_usage"].(float64); ok {
+			summary.cpuUsage += v
+			summary.hasCPU = true
+		}
+		if v, ok := fields[a.prefix+"cpu_time_user"].(float64); ok {
+			summary.cpuTimeUser += v
+		}
+		if v, ok := fields[a.prefix+"cpu_time_system"].(float64); ok {
+			summary.cpuTimeSystem += v
+		}
+		if v, ok := fields[a.prefix+"memory_rss"].(uint64); ok {
+			summary.memoryRSS += v
+			summary.hasMemory = true
+		}
+		if v, ok := fields[a.prefix+"memory_vms"].(uint64); ok {
+			summary.memoryVMS += v
+		}
+		if v, ok := fields[a.prefix+"num_fds"].(int64); ok {
+			summary.numFDs += v
+		}
+		if v, ok := fields[a.prefix+"num_threads"].(int64); ok {
+			summary.numThreads += v
+		}
+	}
+}
+
+// emit adds one metric per cgroup including the process events if any
+func (a *cgroupAggregator) emit(acc telegraf.Accumulator, events map[string]*cgroupEvents, t time.Time) {
+	for cgroup, summary := range a.summaries {
+		fields := map[string]interface{}{
+			a.prefix + "process_count": summary.processes,
+			a.prefix + "num_fds":       summary.numFDs,
+			a.prefix + "num_threads":   summary.numThreads,
+		}
+		if summary.hasCPU {
+			fields[a.prefix+"cpu_usage"] = summary.cpuUsage
+			fields[a.prefix+"cpu_time_user"] = summary.cpuTimeUser
+			fields[a.prefix+"cpu_time_system"] = summary.cpuTimeSystem
+		}
+		if summary.hasMemory {
+			fields[a.prefix+"memory_rss"] = summary.memoryRSS
+			fields[a.prefix+"memory_vms"] = summary.memoryVMS
+		}
+		if ev, found := events[cgroup]; found {
+			a.addEventFields(fields, ev)
+		} else if events != nil {
+			a.addEventFields(fields, &cgroupEvents{})
+		}
+		acc.AddFields("procstat_cgroup", fields, cgroupTags(cgroup), t)
+	}
+
+	// Report cgroups where all processes ended since the last collection
+	for cgroup, ev := range events {
+		if _, found := a.summaries[cgroup]; found {
+			continue
+		}
+		fields := map[string]interface{}{
+			a.prefix + "process_count": int64(0),
+		}
+		a.addEventFields(fields, ev)
+		acc.AddFields("procstat_cgroup", fields, cgroupTags(cgroup), t)
+	}
+}
+
+func (a *cgroupAggregator) addEventFields(fields map[string]interface{}, ev *cgroupEvents) {
+	fields[a.prefix+"processes_started"] = ev.started
+	fields[a.prefix+"processes_exited"] = ev.exited
+	fields[a.prefix+"exited_cpu_time"] = ev.exitedCPUTime
+}
+
+// cgroupTags returns the cgroup path and, for systemd managed cgroups, the
+// innermost slice and the unit as tags
+func cgroupTags(cgroup string) map[string]string {
+	tags := map[string]string{"cgroup": cgroup}
+	for _, element := range strings.Split(strings.Trim(cgroup, "/"), "/") {
+		switch path.Ext(element) {
+		case ".slice":
+			tags["systemd_slice"] = element
+		case ".service", ".scope":
+			tags["systemd_unit"] = element
+		}
+	}
+	return tags
+}
+
+// parseCgroup returns the unified (v2) cgroup of the process, falling back to
+// the systemd hierarchy for legacy (v1) setups
+func parseCgroup(content string) (string, error) {
+	var fallback string
+	for _, line := range strings.Split(strings.TrimSpace(content), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		switch {
+		case parts[0] == "0" && parts[1] == "":
+			return parts[2], nil
+		case parts[1] == "name=systemd":
+			fallback = parts[2]
+		case fallback == "":
+			fallback = parts[2]
+		}
+	}
+	if fallback == "" {
+		return "", errors.New("no cgroup found")
+	}
+	return fallback, nil
+}
+
+// cgroupEvents contains the process events of a cgroup since the last
+// collection
+type cgroupEvents struct {
+	started       int64
+	exited        int64
+	exitedCPUTime float64
+}
+
+// exitTracker follows the process trees of the monitored processes using
+// process events. This allows to capture processes started and ended between
+// two collections.
+type exitTracker struct {
+	lookup  func(pid) (string, error)
+	cpuTime func(pid) (float64, error)
+
+	sync.Mutex
+	tracked map[pid]string
+	events  map[string]*cgroupEvents
+}
+
+func newExitTracker(lookup func(pid) (string, error), cpuTime func(pid) (float64, error)) *exitTracker {
+	return &exitTracker{
+		lookup:  lookup,
+		cpuTime: cpuTime,
+		tracked: make(map[pid]string),
+		events:  make(map[string]*cgroupEvents),
+	}
+}
+
+// update replaces the set of monitored processes with the ones found during
+// the last collection keeping children started since then
+func (t *exitTracker) update(cgroups map[pid]string) {
+	t.Lock()
+	defer t.Unlock()
+
+	for id := range t.tracked {
+		if _, found := cgroups[id]; found {
+			continue
+		}
+		// Remove processes we missed the exit event for
+		if _, err := t.lookup(id); err != nil {
+			delete(t.tracked, id)
+		}
+	}
+	for id, cgroup := range cgroups {
+		t.tracked[id] = cgroup
+	}
+}
+
+// collect returns the events since the last call
+func (t *exitTracker) collect() map[string]*cgroupEvents {
+	t.Lock()
+	defer t.Unlock()
+
+	events := t.events
+	t.events = make(map[string]*cgroupEvents, len(events))
+	return events
+}
+
+func (t *exitTracker) handleFork(parent, child pid) {
+	t.Lock()
+	defer t.Unlock()
+
+	parentCgroup, found := t.tracked[parent]
+	if !found {
+		return
+	}
+	cgroup, err := t.lookup(child)
+	if err != nil {
+		// The child is already gone, assume it did not move
+		cgroup = parentCgroup
+	}
+	t.tracked[child] = cgroup
+	t.cgroupEvents(cgroup).started++
+}
+
+func (t *exitTracker) handleExit(id pid) {
+	t.Lock()
+	cgroup, found := t.tracked[id]
+	delete(t.tracked, id)
+	t.Unlock()
+	if !found {
+		return
+	}
+
+	// The process can only be inspected until its parent collected the exit
+	// status so the CPU time might not be available anymore
+	cpuTime, err := t.cpuTime(id)
+
+	t.Lock()
+	defer t.Unlock()
+
+	ev := t.cgroupEvents(cgroup)
+	ev.exited++
+	if err == nil {
+		ev.exitedCPUTime += cpuTime
+	}
+}
+
+func (t *exitTracker) cgroupEvents(cgroup string) *cgroupEvents {
+	ev, found := t.events[cgroup]
+	if !found {
+		ev = &cgroupEvents{}
+		t.events[cgroup] = ev
+	}
+	return ev
+}
+
+// processCPUTime returns the user and system CPU time of the process in seconds
+func processCPUTime(id pid) (float64, error) {
+	proc, err := gopsprocess.NewProcess(int32(id))
+	if err != nil {
+		return 0, err
+	}
+	times, err := proc.Times()
+	if err != nil {
+		return 0, err
+	}
+	return times.User + times.System, nil
+}
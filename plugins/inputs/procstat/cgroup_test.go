@@ -0,0 +1,260 @@
+package procstat
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var testCgroups = map[pid]string{
+	1: "/system.slice/nginx.service",
+	2: "/system.slice/nginx.service",
+	3: "/user.slice/user-1000.slice/session-2.scope",
+}
+
+func lookupTestCgroup(id pid) (string, error) {
+	if cgroup, found := testCgroups[id]; found {
+		return cgroup, nil
+	}
+	return "", errors.New("no such process")
+}
+
+func TestCgroupSummaryInvalid(t *testing.T) {
+	p := Procstat{
+		Exe:        exe,
+		PidFinder:  "test",
+		ExitEvents: true,
+		Log:        testutil.Logger{},
+	}
+	require.ErrorContains(t, p.Init(), "'exit_events' requires 'cgroup_summary'")
+}
+
+func TestCgroupSummary(t *testing.T) {
+	p := Procstat{
+		Exe:           exe,
+		PidFinder:     "test",
+		Properties:    []string{"cpu", "memory"},
+		CgroupSummary: true,
+		Log:           testutil.Logger{},
+		finder:        newTestFinder([]pid{1, 2, 3}),
+		createProcess: newTestProc,
+		cgroupOf:      lookupTestCgroup,
+	}
+	require.NoError(t, p.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"procstat_cgroup",
+			map[string]string{
+				"cgroup":        "/system.slice/nginx.service",
+				"systemd_slice": "system.slice",
+				"systemd_unit":  "nginx.service",
+			},
+			map[string]interface{}{
+				"process_count":   int64(2),
+				"num_fds":         int64(0),
+				"num_threads":     int64(0),
+				"cpu_usage":       float64(0),
+				"cpu_time_user":   float64(0),
+				"cpu_time_system": float64(0),
+				"memory_rss":      uint64(0),
+				"memory_vms":      uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"procstat_cgroup",
+			map[string]string{
+				"cgroup":        "/user.slice/user-1000.slice/session-2.scope",
+				"systemd_slice": "user-1000.slice",
+				"systemd_unit":  "session-2.scope",
+			},
+			map[string]interface{}{
+				"process_count":   int64(1),
+				"num_fds":         int64(0),
+				"num_threads":     int64(0),
+				"cpu_usage":       float64(0),
+				"cpu_time_user":   float64(0),
+				"cpu_time_system": float64(0),
+				"memory_rss":      uint64(0),
+				"memory_vms":      uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "procstat_cgroup" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.SortMetrics(), testutil.IgnoreTime())
+}
+
+func TestCgroupAggregator(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	newProcessMetric := func(cpu float64, rss uint64, fds int32) telegraf.Metric {
+		return metric.New(
+			"procstat",
+			map[string]string{},
+			map[string]interface{}{
+				"test_cpu_usage":       cpu,
+				"test_cpu_time_user":   cpu * 10,
+				"test_cpu_time_system": cpu * 2,
+				"test_memory_rss":      rss,
+				"test_memory_vms":      2 * rss,
+				"test_num_fds":         fds,
+				"test_num_threads":     int32(1),
+			},
+			now,
+		)
+	}
+
+	aggregator := newCgroupAggregator("test", lookupTestCgroup)
+	aggregator.add(1, []telegraf.Metric{newProcessMetric(1.5, 1024, 10)})
+	aggregator.add(2, []telegraf.Metric{newProcessMetric(2.5, 2048, 5)})
+	// Processes matched by multiple filters must only be counted once
+	aggregator.add(2, []telegraf.Metric{newProcessMetric(2.5, 2048, 5)})
+	// Processes ended in the meantime are skipped
+	aggregator.add(4, []telegraf.Metric{newProcessMetric(1, 1, 1)})
+
+	events := map[string]*cgroupEvents{
+		"/system.slice/nginx.service": {started: 3, exited: 2, exitedCPUTime: 0.25},
+		"/system.slice/cron.service":  {started: 1, exited: 1, exitedCPUTime: 0.5},
+	}
+
+	var acc testutil.Accumulator
+	aggregator.emit(&acc, events, now)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"procstat_cgroup",
+			map[string]string{
+				"cgroup":        "/system.slice/nginx.service",
+				"systemd_slice": "system.slice",
+				"systemd_unit":  "nginx.service",
+			},
+			map[string]interface{}{
+				"test_process_count":     int64(2),
+				"test_num_fds":           int64(15),
+				"test_num_threads":       int64(2),
+				"test_cpu_usage":         float64(4),
+				"test_cpu_time_user":     float64(40),
+				"test_cpu_time_system":   float64(8),
+				"test_memory_rss":        uint64(3072),
+				"test_memory_vms":        uint64(6144),
+				"test_processes_started": int64(3),
+				"test_processes_exited":  int64(2),
+				"test_exited_cpu_time":   float64(0.25),
+			},
+			now,
+		),
+		metric.New(
+			"procstat_cgroup",
+			map[string]string{
+				"cgroup":        "/system.slice/cron.service",
+				"systemd_slice": "system.slice",
+				"systemd_unit":  "cron.service",
+			},
+			map[string]interface{}{
+				"test_process_count":     int64(0),
+				"test_processes_started": int64(1),
+				"test_processes_exited":  int64(1),
+				"test_exited_cpu_time":   float64(0.5),
+			},
+			now,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
+
+func TestExitTracker(t *testing.T) {
+	cgroups := map[pid]string{
+		1:  "/system.slice/nginx.service",
+		10: "/system.slice/nginx.service",
+	}
+	lookup := func(id pid) (string, error) {
+		if cgroup, found := cgroups[id]; found {
+			return cgroup, nil
+		}
+		return "", errors.New("no such process")
+	}
+	cpuTime := func(id pid) (float64, error) {
+		if id == 10 {
+			return 0.75, nil
+		}
+		return 0, errors.New("no such process")
+	}
+
+	tracker := newExitTracker(lookup, cpuTime)
+	tracker.update(map[pid]string{1: "/system.slice/nginx.service"})
+
+	// A short-lived child of a tracked process and its grandchild
+	tracker.handleFork(1, 10)
+	tracker.handleFork(10, 11)
+	tracker.handleExit(11)
+	tracker.handleExit(10)
+
+	// Processes outside of the tracked trees are ignored
+	tracker.handleFork(99, 100)
+	tracker.handleExit(100)
+
+	events := tracker.collect()
+	require.Equal(t, map[string]*cgroupEvents{
+		"/system.slice/nginx.service": {started: 2, exited: 2, exitedCPUTime: 0.75},
+	}, events)
+	require.Empty(t, tracker.collect())
+
+	// Processes with missed exit events are removed on update
+	tracker.handleFork(1, 12)
+	tracker.update(map[pid]string{1: "/system.slice/nginx.service"})
+	require.Len(t, tracker.tracked, 1)
+}
+
+func TestParseCgroup(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "unified",
+			content:  "0::/system.slice/nginx.service\n",
+			expected: "/system.slice/nginx.service",
+		},
+		{
+			name: "hybrid",
+			content: "12:cpuset:/\n" +
+				"1:name=systemd:/system.slice/nginx.service\n" +
+				"0::/system.slice/nginx.service\n",
+			expected: "/system.slice/nginx.service",
+		},
+		{
+			name: "legacy",
+			content: "12:cpuset:/docker\n" +
+				"1:name=systemd:/system.slice/docker.service\n",
+			expected: "/system.slice/docker.service",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := parseCgroup(tt.content)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+
+	_, err := parseCgroup("")
+	require.ErrorContains(t, err, "no cgroup found")
+}
@@ -0,0 +1,175 @@
+//go:build linux
+
+package procstat
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Constants of the kernel's process events connector, see
+// include/uapi/linux/cn_proc.h and include/uapi/linux/connector.h
+const (
+	cnIdxProc          = 0x1
+	cnValProc          = 0x1
+	procCnMcastListen  = 0x1
+	procCnMcastIgnore  = 0x2
+	procEventFork      = 0x00000001
+	procEventExit      = 0x80000000
+	cnMsgHeaderLen     = 20
+	procEventHeaderLen = 16
+)
+
+const exitEventsSupported = true
+
+// processEvents listens for fork and exit events of processes using the
+// netlink process connector. This requires the CAP_NET_ADMIN capability.
+type processEvents struct {
+	fd     int
+	onFork func(parent, child pid)
+	onExit func(pid)
+	log    telegraf.Logger
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func startProcessEvents(onFork func(parent, child pid), onExit func(pid), log telegraf.Logger) (*processEvents, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.NETLINK_CONNECTOR)
+	if err != nil {
+		return nil, fmt.Errorf("creating netlink socket failed: %w", err)
+	}
+	addr := &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: cnIdxProc, Pid: uint32(os.Getpid())}
+	if err := unix.Bind(fd, addr); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("binding netlink socket failed: %w", err)
+	}
+
+	// Use a receive timeout to be able to stop the listener
+	tv := unix.Timeval{Sec: 1}
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("setting netlink socket timeout failed: %w", err)
+	}
+
+	e := &processEvents{
+		fd:     fd,
+		onFork: onFork,
+		onExit: onExit,
+		log:    log,
+		done:   make(chan struct{}),
+	}
+	if err := e.subscribe(procCnMcastListen); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("subscribing to process events failed: %w", err)
+	}
+
+	e.wg.Add(1)
+	go e.listen()
+
+	return e, nil
+}
+
+func (e *processEvents) stop() {
+	close(e.done)
+	e.wg.Wait()
+
+	if err := e.subscribe(procCnMcastIgnore); err != nil {
+		e.log.Debugf("Unsubscribing from process events failed: %v", err)
+	}
+	unix.Close(e.fd)
+}
+
+func (e *processEvents) subscribe(op uint32) error {
+	// Netlink header followed by the connector message containing the
+	// operation
+	msg := make([]byte, unix.NLMSG_HDRLEN+cnMsgHeaderLen+4)
+	binary.NativeEndian.PutUint32(msg[0:4], uint32(len(msg)))
+	binary.NativeEndian.PutUint16(msg[4:6], unix.NLMSG_DONE)
+	binary.NativeEndian.PutUint32(msg[12:16], uint32(os.Getpid()))
+
+	cn := msg[unix.NLMSG_HDRLEN:]
+	binary.NativeEndian.PutUint32(cn[0:4], cnIdxProc)
+	binary.NativeEndian.PutUint32(cn[4:8], cnValProc)
+	binary.NativeEndian.PutUint16(cn[16:18], 4)
+	binary.NativeEndian.PutUint32(cn[cnMsgHeaderLen:], op)
+
+	return unix.Sendto(e.fd, msg, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK})
+}
+
+func (e *processEvents) listen() {
+	defer e.wg.Done()
+
+	buf := make([]byte, os.Getpagesize())
+	for {
+		select {
+		case <-e.done:
+			return
+		default:
+		}
+
+		n, _, err := unix.Recvfrom(e.fd, buf, 0)
+		if err != nil {
+			if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+				continue
+			}
+			if errors.Is(err, unix.ENOBUFS) {
+				e.log.Warn("Process events lost due to buffer overrun")
+				continue
+			}
+			e.log.Errorf("Receiving process events failed: %v", err)
+			return
+		}
+
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			e.log.Debugf("Parsing netlink message failed: %v", err)
+			continue
+		}
+		for _, msg := range msgs {
+			e.handle(msg.Data)
+		}
+	}
+}
+
+func (e *processEvents) handle(data []byte) {
+	if len(data) < cnMsgHeaderLen+procEventHeaderLen {
+		return
+	}
+	event := data[cnMsgHeaderLen:]
+	payload := event[procEventHeaderLen:]
+
+	switch binary.NativeEndian.Uint32(event[0:4]) {
+	case procEventFork:
+		if len(payload) < 16 {
+			return
+		}
+		parentTgid := binary.NativeEndian.Uint32(payload[4:8])
+		childPid := binary.NativeEndian.Uint32(payload[8:12])
+		childTgid := binary.NativeEndian.Uint32(payload[12:16])
+		// Ignore new threads
+		if childPid != childTgid {
+			return
+		}
+		e.onFork(pid(parentTgid), pid(childTgid))
+	case procEventExit:
+		if len(payload) < 8 {
+			return
+		}
+		processPid := binary.NativeEndian.Uint32(payload[0:4])
+		processTgid := binary.NativeEndian.Uint32(payload[4:8])
+		// Ignore ending threads
+		if processPid != processTgid {
+			return
+		}
+		e.onExit(pid(processTgid))
+	}
+}
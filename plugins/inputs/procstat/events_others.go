@@ -0,0 +1,19 @@
+//go:build !linux
+
+package procstat
+
+import (
+	"errors"
+
+	"github.com/influxdata/telegraf"
+)
+
+const exitEventsSupported = false
+
+type processEvents struct{}
+
+func startProcessEvents(func(parent, child pid), func(pid), telegraf.Logger) (*processEvents, error) {
+	return nil, errors.New("process events not supported on this OS")
+}
+
+func (*processEvents) stop() {}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"os/user"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
@@ -407,3 +408,12 @@ func statsUnix(conns []gopsnet.ConnectionStat) ([]map[string]interface{}, error)
 
 	return fieldslist, nil
 }
+
+func processCgroup(id pid) (string, error) {
+	fn := filepath.Join(internal.GetProcPath(), strconv.Itoa(int(id)), "cgroup")
+	buf, err := os.ReadFile(fn)
+	if err != nil {
+		return "", err
+	}
+	return parseCgroup(string(buf))
+}
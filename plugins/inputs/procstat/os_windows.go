@@ -165,3 +165,7 @@ func statsUDP(conns []gopsnet.ConnectionStat, _ uint8) ([]map[string]interface{}
 func statsUnix([]gopsnet.ConnectionStat) ([]map[string]interface{}, error) {
 	return nil, nil
 }
+
+func processCgroup(pid) (string, error) {
+	return "", errors.New("cgroups not supported on this OS")
+}
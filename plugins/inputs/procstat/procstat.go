@@ -51,14 +51,19 @@ type Procstat struct {
 	SocketProtocols        []string        `toml:"socket_protocols"`
 	TagWith                []string        `toml:"tag_with"`
 	Filter                 []filter        `toml:"filter"`
+	CgroupSummary          bool            `toml:"cgroup_summary"`
+	ExitEvents             bool            `toml:"exit_events"`
 	Log                    telegraf.Logger `toml:"-"`
 
 	finder    pidFinder
 	processes map[pid]process
 	cfg       collectionConfig
 	oldMode   bool
+	exits     *exitTracker
+	events    *processEvents
 
 	createProcess func(pid) (process, error)
+	cgroupOf      func(pid) (string, error)
 }
 
 type collectionConfig struct {
@@ -203,12 +208,47 @@ func (p *Procstat) Init() error {
 		}
 	}
 
+	// Check the cgroup summary settings
+	if p.ExitEvents {
+		if !p.CgroupSummary {
+			return errors.New("'exit_events' requires 'cgroup_summary'")
+		}
+		if !exitEventsSupported {
+			return errors.New("'exit_events' not supported on this OS")
+		}
+	}
+	if p.cgroupOf == nil {
+		p.cgroupOf = processCgroup
+	}
+
 	// Initialize the running process cache
 	p.processes = make(map[pid]process)
 
 	return nil
 }
 
+func (p *Procstat) Start(telegraf.Accumulator) error {
+	if !p.ExitEvents {
+		return nil
+	}
+
+	p.exits = newExitTracker(p.cgroupOf, processCPUTime)
+	events, err := startProcessEvents(p.exits.handleFork, p.exits.handleExit, p.Log)
+	if err != nil {
+		return fmt.Errorf("starting process events listener failed: %w", err)
+	}
+	p.events = events
+
+	return nil
+}
+
+func (p *Procstat) Stop() {
+	if p.events != nil {
+		p.events.stop()
+		p.events = nil
+	}
+}
+
 func (p *Procstat) Gather(acc telegraf.Accumulator) error {
 	if p.oldMode {
 		return p.gatherOld(acc)
@@ -240,6 +280,11 @@ func (p *Procstat) gatherOld(acc telegraf.Accumulator) error {
 		return err
 	}
 
+	var cgroups *cgroupAggregator
+	if p.CgroupSummary {
+		cgroups = newCgroupAggregator(p.Prefix, p.cgroupOf)
+	}
+
 	var count int
 	running := make(map[pid]bool)
 	for _, r := range results {
@@ -287,11 +332,17 @@ func (p *Procstat) gatherOld(acc telegraf.Accumulator) error {
 				// metrics available
 				acc.AddError(err)
 			}
+			// Aggregate the cgroup summary before handing over the metrics
+			// as they must not be accessed afterwards
+			if cgroups != nil {
+				cgroups.add(pid, metrics)
+			}
 			for _, m := range metrics {
 				acc.AddMetric(m)
 			}
 		}
 	}
+	p.addCgroupSummaries(acc, cgroups, now)
 
 	// Cleanup processes that are not running anymore
 	for pid := range p.processes {
@@ -325,6 +376,12 @@ func (p *Procstat) gatherOld(acc telegraf.Accumulator) error {
 
 func (p *Procstat) gatherNew(acc telegraf.Accumulator) error {
 	now := time.Now()
+
+	var cgroups *cgroupAggregator
+	if p.CgroupSummary {
+		cgroups = newCgroupAggregator(p.Prefix, p.cgroupOf)
+	}
+
 	running := make(map[pid]bool)
 	for _, f := range p.Filter {
 		groups, err := f.applyFilter()
@@ -395,6 +452,11 @@ func (p *Procstat) gatherNew(acc telegraf.Accumulator) error {
 					// metrics available
 					acc.AddError(err)
 				}
+				// Aggregate the cgroup summary before handing over the metrics
+				// as they must not be accessed afterwards
+				if cgroups != nil {
+					cgroups.add(pid, metrics)
+				}
 				for _, m := range metrics {
 					acc.AddMetric(m)
 				}
@@ -434,6 +496,8 @@ func (p *Procstat) gatherNew(acc telegraf.Accumulator) error {
 		)
 	}
 
+	p.addCgroupSummaries(acc, cgroups, now)
+
 	// Cleanup processes that are not running anymore across all filters/groups
 	for pid := range p.processes {
 		if !running[pid] {
@@ -443,6 +507,21 @@ func (p *Procstat) gatherNew(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// addCgroupSummaries adds the per-cgroup metrics of the collected processes
+// and updates the processes tracked for exit events
+func (p *Procstat) addCgroupSummaries(acc telegraf.Accumulator, cgroups *cgroupAggregator, t time.Time) {
+	if cgroups == nil {
+		return
+	}
+
+	var events map[string]*cgroupEvents
+	if p.exits != nil {
+		p.exits.update(cgroups.cgroups)
+		events = p.exits.collect()
+	}
+	cgroups.emit(acc, events, t)
+}
+
 // Get matching PIDs and their initial tags
 func (p *Procstat) findPids() ([]pidsTags, error) {
 	switch {
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"net"
 	"net/http"
 	"net/url"
@@ -12,12 +13,12 @@ import (
 	"os/user"
 	"path/filepath"
 	"strconv"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -77,11 +78,24 @@ func (p *Prometheus) startK8s(ctx context.Context) error {
 		}
 	}
 
-	if !p.isNodeScrapeScope {
-		err = p.watchPod(ctx, client)
-		if err != nil {
-			p.Log.Warnf("Error while attempting to watch pod: %s", err.Error())
+	if (p.MonitorPods && !p.isNodeScrapeScope) || p.MonitorServices {
+		f := p.informerFactory(client)
+		if p.MonitorPods && !p.isNodeScrapeScope {
+			if err := p.watchPod(f); err != nil {
+				p.Log.Warnf("Error while attempting to watch pod: %s", err.Error())
+			}
+		}
+		if p.MonitorServices {
+			if err := p.watchService(f); err != nil {
+				p.Log.Warnf("Error while attempting to watch service: %s", err.Error())
+			}
 		}
+		f.Start(ctx.Done())
+		f.WaitForCacheSync(ctx.Done())
+	}
+
+	if !p.MonitorPods || !p.isNodeScrapeScope {
+		return nil
 	}
 
 	p.wg.Add(1)
@@ -92,22 +106,18 @@ func (p *Prometheus) startK8s(ctx context.Context) error {
 			case <-ctx.Done():
 				return
 			case <-time.After(time.Second):
-				if p.isNodeScrapeScope {
-					bearerToken := config.BearerToken
-					if config.BearerTokenFile != "" {
-						bearerTokenBytes, err := os.ReadFile(config.BearerTokenFile)
-						if err != nil {
-							p.Log.Errorf("Error reading bearer token file hence falling back to BearerToken: %s", err.Error())
-						} else {
-							bearerToken = string(bearerTokenBytes)
-						}
-					}
-					err = p.cAdvisor(ctx, bearerToken)
+				bearerToken := config.BearerToken
+				if config.BearerTokenFile != "" {
+					bearerTokenBytes, err := os.ReadFile(config.BearerTokenFile)
 					if err != nil {
-						p.Log.Errorf("Unable to monitor pods with node scrape scope: %s", err.Error())
+						p.Log.Errorf("Error reading bearer token file hence falling back to BearerToken: %s", err.Error())
+					} else {
+						bearerToken = string(bearerTokenBytes)
 					}
-				} else {
-					<-ctx.Done()
+				}
+				err = p.cAdvisor(ctx, bearerToken)
+				if err != nil {
+					p.Log.Errorf("Unable to monitor pods with node scrape scope: %s", err.Error())
 				}
 			}
 		}
@@ -122,27 +132,40 @@ func shouldScrapePod(pod *corev1.Pod, p *Prometheus) bool {
 		podHasMatchingLabelSelector(pod, p.podLabelSelector) &&
 		podHasMatchingFieldSelector(pod, p.podFieldSelector)
 
-	var shouldScrape bool
+	return isCandidate && scrapeEnabled(pod.Annotations, p)
+}
+
+func shouldScrapeService(svc *corev1.Service, p *Prometheus) bool {
+	isCandidate := (p.PodNamespace == "" || svc.Namespace == p.PodNamespace) &&
+		(p.serviceLabelSelector == nil || p.serviceLabelSelector.Matches(labels.Set(svc.Labels)))
+
+	return isCandidate && scrapeEnabled(svc.Annotations, p)
+}
+
+// scrapeEnabled checks the 'prometheus.io/scrape' annotation of the pod or
+// service according to the configured method
+func scrapeEnabled(annotations map[string]string, p *Prometheus) bool {
 	switch p.MonitorKubernetesPodsMethod {
 	case monitorMethodAnnotations: // must have 'true' annotation to be scraped
-		shouldScrape = pod.Annotations != nil && pod.Annotations["prometheus.io/scrape"] == "true"
+		return annotations != nil && annotations["prometheus.io/scrape"] == "true"
 	case monitorMethodSettings: // will be scraped regardless of annotation
-		shouldScrape = true
+		return true
 	case monitorMethodSettingsAndAnnotations: // will be scraped unless opts out with 'false' annotation
-		shouldScrape = pod.Annotations == nil || pod.Annotations["prometheus.io/scrape"] != "false"
+		return annotations == nil || annotations["prometheus.io/scrape"] != "false"
 	}
-
-	return isCandidate && shouldScrape
+	return false
 }
 
 // Share informer per namespace across all instances of this plugin
-var informerfactory map[string]informers.SharedInformerFactory
+var (
+	informerfactory     map[string]informers.SharedInformerFactory
+	informerfactoryLock sync.Mutex
+)
 
-// An edge case exists if a pod goes offline at the same time a new pod is created
-// (without the scrape annotations). K8s may re-assign the old pod ip to the non-scrape
-// pod, causing errors in the logs. This is only true if the pod going offline is not
-// directed to do so by K8s.
-func (p *Prometheus) watchPod(ctx context.Context, clientset *kubernetes.Clientset) error {
+// informerFactory returns the shared informer factory for the configured
+// namespace. The informers keep a local cache of the watched objects so
+// gathering does not require any calls to the API server.
+func (p *Prometheus) informerFactory(clientset kubernetes.Interface) informers.SharedInformerFactory {
 	var resyncinterval time.Duration
 
 	if p.CacheRefreshInterval != 0 {
@@ -151,13 +174,15 @@ func (p *Prometheus) watchPod(ctx context.Context, clientset *kubernetes.Clients
 		resyncinterval = 60 * time.Minute
 	}
 
+	informerfactoryLock.Lock()
+	defer informerfactoryLock.Unlock()
+
 	if informerfactory == nil {
 		informerfactory = make(map[string]informers.SharedInformerFactory)
 	}
 
-	var f informers.SharedInformerFactory
-	var ok bool
-	if f, ok = informerfactory[p.PodNamespace]; !ok {
+	f, ok := informerfactory[p.PodNamespace]
+	if !ok {
 		var informerOptions []informers.SharedInformerOption
 		if p.PodNamespace != "" {
 			informerOptions = append(informerOptions, informers.WithNamespace(p.PodNamespace))
@@ -166,10 +191,18 @@ func (p *Prometheus) watchPod(ctx context.Context, clientset *kubernetes.Clients
 		informerfactory[p.PodNamespace] = f
 	}
 
-	if p.nsAnnotationPass != nil || p.nsAnnotationDrop != nil {
+	if p.nsAnnotationPass != nil || p.nsAnnotationDrop != nil || p.nsLabelSelector != nil {
 		p.nsStore = f.Core().V1().Namespaces().Informer().GetStore()
 	}
 
+	return f
+}
+
+// An edge case exists if a pod goes offline at the same time a new pod is created
+// (without the scrape annotations). K8s may re-assign the old pod ip to the non-scrape
+// pod, causing errors in the logs. This is only true if the pod going offline is not
+// directed to do so by K8s.
+func (p *Prometheus) watchPod(f informers.SharedInformerFactory) error {
 	podinformer := f.Core().V1().Pods()
 	_, err := podinformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(newObj interface{}) {
@@ -214,8 +247,43 @@ func (p *Prometheus) watchPod(ctx context.Context, clientset *kubernetes.Clients
 		},
 	})
 
-	f.Start(ctx.Done())
-	f.WaitForCacheSync(wait.NeverStop)
+	return err
+}
+
+func (p *Prometheus) watchService(f informers.SharedInformerFactory) error {
+	serviceinformer := f.Core().V1().Services()
+	_, err := serviceinformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(newObj interface{}) {
+			svc, ok := newObj.(*corev1.Service)
+			if !ok {
+				p.Log.Errorf("[BUG] received unexpected object: %v", newObj)
+				return
+			}
+			if shouldScrapeService(svc, p) {
+				registerService(svc, p)
+			}
+		},
+		// Annotations of services might change so always update the target
+		UpdateFunc: func(_, newObj interface{}) {
+			svc, ok := newObj.(*corev1.Service)
+			if !ok {
+				p.Log.Errorf("[BUG] received unexpected object: %v", newObj)
+				return
+			}
+			if shouldScrapeService(svc, p) {
+				registerService(svc, p)
+			} else {
+				unregisterService(svc.GetNamespace()+"/"+svc.GetName(), p)
+			}
+		},
+		DeleteFunc: func(oldObj interface{}) {
+			key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(oldObj)
+			if err == nil {
+				unregisterService(key, p)
+			}
+		},
+	})
+
 	return err
 }
 
@@ -355,7 +423,7 @@ func getNamespaceObject(name string, p *Prometheus) *corev1.Namespace {
 func namespaceAnnotationMatch(nsName string, p *Prometheus) bool {
 	// In case of no filtering or any issues with acquiring namespace information
 	// just let it pass trough...
-	if (p.nsAnnotationPass == nil && p.nsAnnotationDrop == nil) || p.nsStore == nil {
+	if (p.nsAnnotationPass == nil && p.nsAnnotationDrop == nil && p.nsLabelSelector == nil) || p.nsStore == nil {
 		return true
 	}
 	ns := getNamespaceObject(nsName, p)
@@ -363,6 +431,10 @@ func namespaceAnnotationMatch(nsName string, p *Prometheus) bool {
 		return true
 	}
 
+	if p.nsLabelSelector != nil && !p.nsLabelSelector.Matches(labels.Set(ns.Labels)) {
+		return false
+	}
+
 	tags := make([]*telegraf.Tag, 0, len(ns.Annotations))
 	for k, v := range ns.Annotations {
 		tags = append(tags, &telegraf.Tag{Key: k, Value: v})
@@ -444,6 +516,44 @@ func getScrapeURL(pod *corev1.Pod, p *Prometheus) (*url.URL, error) {
 		return nil, nil
 	}
 
+	// Named ports refer to the ports of the pod's containers
+	resolvePort := func(name string) (int32, bool) {
+		for _, c := range pod.Spec.Containers {
+			for _, port := range c.Ports {
+				if port.Name == name {
+					return port.ContainerPort, true
+				}
+			}
+		}
+		return 0, false
+	}
+
+	return scrapeURL(ip, pod.Annotations, resolvePort, p)
+}
+
+func getServiceScrapeURL(svc *corev1.Service, p *Prometheus) (*url.URL, error) {
+	// Headless services do not have a virtual IP to scrape, use pod
+	// monitoring or 'kubernetes_services' instead
+	ip := svc.Spec.ClusterIP
+	if ip == "" || ip == corev1.ClusterIPNone {
+		return nil, nil
+	}
+
+	resolvePort := func(name string) (int32, bool) {
+		for _, port := range svc.Spec.Ports {
+			if port.Name == name {
+				return port.Port, true
+			}
+		}
+		return 0, false
+	}
+
+	return scrapeURL(ip, svc.Annotations, resolvePort, p)
+}
+
+// scrapeURL constructs the target URL from the settings and annotations. The
+// port annotation can either be a number or a named port.
+func scrapeURL(ip string, annotations map[string]string, resolvePort func(string) (int32, bool), p *Prometheus) (*url.URL, error) {
 	var scheme, pathAndQuery, port string
 
 	if p.MonitorKubernetesPodsMethod == monitorMethodSettings ||
@@ -455,14 +565,21 @@ func getScrapeURL(pod *corev1.Pod, p *Prometheus) (*url.URL, error) {
 
 	if p.MonitorKubernetesPodsMethod == monitorMethodAnnotations ||
 		p.MonitorKubernetesPodsMethod == monitorMethodSettingsAndAnnotations {
-		if ann := pod.Annotations["prometheus.io/scheme"]; ann != "" {
+		if ann := annotations["prometheus.io/scheme"]; ann != "" {
 			scheme = ann
 		}
-		if ann := pod.Annotations["prometheus.io/path"]; ann != "" {
+		if ann := annotations["prometheus.io/path"]; ann != "" {
 			pathAndQuery = ann
 		}
-		if ann := pod.Annotations["prometheus.io/port"]; ann != "" {
+		if ann := annotations["prometheus.io/port"]; ann != "" {
 			port = ann
+			if _, err := strconv.ParseUint(ann, 10, 16); err != nil {
+				number, found := resolvePort(ann)
+				if !found {
+					return nil, fmt.Errorf("unknown port %q", ann)
+				}
+				port = strconv.Itoa(int(number))
+			}
 		}
 	}
 
@@ -498,3 +615,93 @@ func unregisterPod(podID podID, p *Prometheus) {
 		p.Log.Debugf("will stop scraping for %q", v.url.String())
 	}
 }
+
+func registerService(svc *corev1.Service, p *Prometheus) {
+	targetURL, err := getServiceScrapeURL(svc, p)
+	if err != nil {
+		p.Log.Errorf("could not parse URL of service %s/%s: %s", svc.GetNamespace(), svc.GetName(), err)
+		return
+	} else if targetURL == nil {
+		return
+	}
+
+	tags := make(map[string]string, len(svc.Annotations)+len(svc.Labels)+2)
+
+	// add annotation and labels as metrics tags, subject to the same
+	// include/exclude filters as for pods
+	for k, v := range svc.Annotations {
+		if models.ShouldPassFilters(p.podAnnotationIncludeFilter, p.podAnnotationExcludeFilter, k) {
+			tags[k] = v
+		}
+	}
+	for k, v := range svc.Labels {
+		if models.ShouldPassFilters(p.podLabelIncludeFilter, p.podLabelExcludeFilter, k) {
+			tags[k] = v
+		}
+	}
+
+	tags["service_name"] = svc.Name
+	namespaceLabel := "namespace"
+	if p.PodNamespaceLabelName != "" {
+		namespaceLabel = p.PodNamespaceLabelName
+	}
+	tags[namespaceLabel] = svc.Namespace
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	key := svc.GetNamespace() + "/" + svc.GetName()
+	if _, found := p.kubernetesSvcs[key]; !found {
+		p.Log.Debugf("will scrape metrics from %q", targetURL.String())
+	}
+	p.kubernetesSvcs[key] = urlAndAddress{
+		url:         targetURL,
+		address:     targetURL.Hostname(),
+		originalURL: targetURL,
+		tags:        tags,
+		namespace:   svc.GetNamespace(),
+	}
+}
+
+func unregisterService(key string, p *Prometheus) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if v, ok := p.kubernetesSvcs[key]; ok {
+		delete(p.kubernetesSvcs, key)
+		p.Log.Debugf("will stop scraping for %q", v.url.String())
+	}
+}
+
+// ownsTarget decides if the target identified by the given key is scraped by
+// this instance when sharding the targets across multiple instances. The
+// rendezvous hashing used assigns each target to the shard with the highest
+// score, so changing the number of shards only moves the targets of the added
+// or removed shards.
+func (p *Prometheus) ownsTarget(key string) bool {
+	if p.KubernetesShardCount <= 1 {
+		return true
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	hash := h.Sum64()
+
+	var owner int
+	var highest uint64
+	for shard := range p.KubernetesShardCount {
+		score := mix(hash ^ (uint64(shard+1) * 0x9e3779b97f4a7c15))
+		if shard == 0 || score > highest {
+			owner, highest = shard, score
+		}
+	}
+	return owner == p.KubernetesShardIndex
+}
+
+// mix is the finalizer of the 64-bit MurmurHash3 to spread the shard scores
+func mix(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -1,6 +1,7 @@
 package prometheus
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -359,6 +360,190 @@ func TestLabelFilters(t *testing.T) {
 	}
 }
 
+func TestScrapeURLAnnotationsNamedPort(t *testing.T) {
+	prom := initPrometheus()
+	p := pod()
+	p.Annotations = map[string]string{"prometheus.io/port": "metrics"}
+	p.Spec.Containers = []corev1.Container{
+		{Name: "app", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}},
+		{Name: "exporter", Ports: []corev1.ContainerPort{{Name: "metrics", ContainerPort: 9113}}},
+	}
+	url, err := getScrapeURL(p, prom)
+	require.NoError(t, err)
+	require.Equal(t, "http://127.0.0.1:9113/metrics", url.String())
+
+	p.Annotations["prometheus.io/port"] = "unknown"
+	_, err = getScrapeURL(p, prom)
+	require.ErrorContains(t, err, `unknown port "unknown"`)
+}
+
+func TestAddService(t *testing.T) {
+	prom := initPrometheus()
+	prom.kubernetesSvcs = map[string]urlAndAddress{}
+
+	s := service()
+	s.Annotations = map[string]string{"prometheus.io/scrape": "true", "prometheus.io/port": "metrics"}
+	s.Labels = map[string]string{"app": "nginx"}
+	require.True(t, shouldScrapeService(s, prom))
+	registerService(s, prom)
+	require.Len(t, prom.kubernetesSvcs, 1)
+
+	target := prom.kubernetesSvcs["default/myService"]
+	require.Equal(t, "http://10.0.0.10:9113/metrics", target.url.String())
+	require.Equal(t, "myService", target.tags["service_name"])
+	require.Equal(t, "default", target.tags["namespace"])
+	require.Equal(t, "nginx", target.tags["app"])
+
+	urls, err := prom.getAllURLs()
+	require.NoError(t, err)
+	require.Contains(t, urls, "http://10.0.0.10:9113/metrics")
+
+	unregisterService("default/myService", prom)
+	require.Empty(t, prom.kubernetesSvcs)
+}
+
+func TestAddHeadlessService(t *testing.T) {
+	prom := initPrometheus()
+	prom.kubernetesSvcs = map[string]urlAndAddress{}
+
+	s := service()
+	s.Spec.ClusterIP = corev1.ClusterIPNone
+	s.Annotations = map[string]string{"prometheus.io/scrape": "true"}
+	registerService(s, prom)
+	require.Empty(t, prom.kubernetesSvcs)
+}
+
+func TestServiceLabelSelector(t *testing.T) {
+	prom := initPrometheus()
+	selector, err := labels.Parse("app=nginx")
+	require.NoError(t, err)
+	prom.serviceLabelSelector = selector
+
+	s := service()
+	s.Annotations = map[string]string{"prometheus.io/scrape": "true"}
+	s.Labels = map[string]string{"app": "nginx"}
+	require.True(t, shouldScrapeService(s, prom))
+
+	s.Labels["app"] = "redis"
+	require.False(t, shouldScrapeService(s, prom))
+
+	// Services must opt-in using the annotation
+	s.Labels["app"] = "nginx"
+	s.Annotations = nil
+	require.False(t, shouldScrapeService(s, prom))
+}
+
+func TestNamespaceLabelSelector(t *testing.T) {
+	prom := initPrometheus()
+	selector, err := labels.Parse("monitoring=enabled")
+	require.NoError(t, err)
+	prom.nsLabelSelector = selector
+
+	prom.nsStore = cache.NewStore(cache.MetaNamespaceKeyFunc)
+	require.NoError(t, prom.nsStore.Add(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{"monitoring": "enabled"}},
+	}))
+	require.NoError(t, prom.nsStore.Add(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-system"},
+	}))
+
+	p := pod()
+	p.Annotations = map[string]string{"prometheus.io/scrape": "true"}
+	registerPod(p, prom)
+	p.Name = "Pod2"
+	p.Namespace = "kube-system"
+	p.Status.PodIP = "127.0.0.2"
+	registerPod(p, prom)
+	require.Len(t, prom.kubernetesPods, 2)
+
+	urls, err := prom.getAllURLs()
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	require.Contains(t, urls, "http://127.0.0.1:9102/metrics")
+}
+
+func TestShardingInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		count    int
+		index    int
+		expected string
+	}{
+		{
+			name:     "negative count",
+			count:    -1,
+			expected: "'kubernetes_shard_count' must not be negative",
+		},
+		{
+			name:     "index out of range",
+			count:    3,
+			index:    3,
+			expected: "'kubernetes_shard_index' must be between 0 and 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prom := &Prometheus{
+				Log:                  testutil.Logger{},
+				KubernetesShardCount: tt.count,
+				KubernetesShardIndex: tt.index,
+			}
+			require.ErrorContains(t, prom.Init(), tt.expected)
+		})
+	}
+}
+
+func TestSharding(t *testing.T) {
+	keys := make([]string, 0, 1000)
+	for i := range 1000 {
+		keys = append(keys, fmt.Sprintf("namespace-%d/pod-%d", i%7, i))
+	}
+
+	owners := func(count int) map[string]int {
+		assignment := make(map[string]int, len(keys))
+		for index := range count {
+			prom := &Prometheus{KubernetesShardCount: count, KubernetesShardIndex: index}
+			for _, key := range keys {
+				if prom.ownsTarget(key) {
+					_, found := assignment[key]
+					require.False(t, found, "key %q owned by multiple shards", key)
+					assignment[key] = index
+				}
+			}
+		}
+		require.Len(t, assignment, len(keys))
+		return assignment
+	}
+
+	// Targets are distributed across the shards
+	three := owners(3)
+	counts := make([]int, 3)
+	for _, owner := range three {
+		counts[owner]++
+	}
+	for _, c := range counts {
+		require.InDelta(t, 333, c, 60)
+	}
+
+	// Adding a shard only moves targets to the new shard
+	four := owners(4)
+	var moved int
+	for key, owner := range four {
+		if owner != three[key] {
+			require.Equal(t, 3, owner)
+			moved++
+		}
+	}
+	require.InDelta(t, 250, moved, 60)
+
+	// Without sharding all targets are scraped
+	prom := &Prometheus{}
+	for _, key := range keys {
+		require.True(t, prom.ownsTarget(key))
+	}
+}
+
 func pod() *corev1.Pod {
 	p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{}, Status: corev1.PodStatus{}, Spec: corev1.PodSpec{}}
 	p.Status.PodIP = "127.0.0.1"
@@ -366,3 +551,12 @@ func pod() *corev1.Pod {
 	p.Namespace = "default"
 	return p
 }
+
+func service() *corev1.Service {
+	s := &corev1.Service{ObjectMeta: metav1.ObjectMeta{}, Spec: corev1.ServiceSpec{}}
+	s.Name = "myService"
+	s.Namespace = "default"
+	s.Spec.ClusterIP = "10.0.0.10"
+	s.Spec.Ports = []corev1.ServicePort{{Name: "metrics", Port: 9113}}
+	return s
+}
@@ -59,6 +59,7 @@ type Prometheus struct {
 
 	// Kubernetes service discovery
 	MonitorPods                 bool                `toml:"monitor_kubernetes_pods"`
+	MonitorServices             bool                `toml:"monitor_kubernetes_services"`
 	PodScrapeScope              string              `toml:"pod_scrape_scope"`
 	NodeIP                      string              `toml:"node_ip"`
 	PodScrapeInterval           int                 `toml:"pod_scrape_interval"`
@@ -68,6 +69,10 @@ type Prometheus struct {
 	KubeConfig                  string              `toml:"kube_config"`
 	KubernetesLabelSelector     string              `toml:"kubernetes_label_selector"`
 	KubernetesFieldSelector     string              `toml:"kubernetes_field_selector"`
+	ServiceLabelSelector        string              `toml:"kubernetes_service_label_selector"`
+	NamespaceLabelSelector      string              `toml:"kubernetes_namespace_label_selector"`
+	KubernetesShardCount        int                 `toml:"kubernetes_shard_count"`
+	KubernetesShardIndex        int                 `toml:"kubernetes_shard_index"`
 	MonitorKubernetesPodsMethod monitorMethod       `toml:"monitor_kubernetes_pods_method"`
 	MonitorKubernetesPodsScheme string              `toml:"monitor_kubernetes_pods_scheme"`
 	MonitorKubernetesPodsPath   string              `toml:"monitor_kubernetes_pods_path"`
@@ -96,16 +101,19 @@ type Prometheus struct {
 	nsStore          cache.Store
 	nsAnnotationPass []models.TagFilter
 	nsAnnotationDrop []models.TagFilter
+	nsLabelSelector  labels.Selector
 
 	// Should we scrape Kubernetes services for prometheus annotations
 	lock           sync.Mutex
 	kubernetesPods map[podID]urlAndAddress
+	kubernetesSvcs map[string]urlAndAddress
 	cancel         context.CancelFunc
 	wg             sync.WaitGroup
 
 	// Only for monitor_kubernetes_pods=true and pod_scrape_scope="node"
 	podLabelSelector           labels.Selector
 	podFieldSelector           fields.Selector
+	serviceLabelSelector       labels.Selector
 	isNodeScrapeScope          bool
 	podAnnotationIncludeFilter filter.Filter
 	podAnnotationExcludeFilter filter.Filter
@@ -204,6 +212,26 @@ func (p *Prometheus) Init() error {
 		p.Log.Debugf("Using the field selector: %v", p.podFieldSelector)
 	}
 
+	if p.ServiceLabelSelector != "" {
+		p.serviceLabelSelector, err = labels.Parse(p.ServiceLabelSelector)
+		if err != nil {
+			return fmt.Errorf("error parsing the specified service label selector(s): %w", err)
+		}
+	}
+	if p.NamespaceLabelSelector != "" {
+		p.nsLabelSelector, err = labels.Parse(p.NamespaceLabelSelector)
+		if err != nil {
+			return fmt.Errorf("error parsing the specified namespace label selector(s): %w", err)
+		}
+	}
+
+	if p.KubernetesShardCount < 0 {
+		return errors.New("'kubernetes_shard_count' must not be negative")
+	}
+	if p.KubernetesShardCount > 0 && (p.KubernetesShardIndex < 0 || p.KubernetesShardIndex >= p.KubernetesShardCount) {
+		return fmt.Errorf("'kubernetes_shard_index' must be between 0 and %d", p.KubernetesShardCount-1)
+	}
+
 	for k, vs := range p.NamespaceAnnotationPass {
 		tagFilter := models.TagFilter{}
 		tagFilter.Name = k
@@ -254,6 +282,7 @@ func (p *Prometheus) Init() error {
 	}
 
 	p.kubernetesPods = make(map[podID]urlAndAddress)
+	p.kubernetesSvcs = make(map[string]urlAndAddress)
 
 	return nil
 }
@@ -269,7 +298,7 @@ func (p *Prometheus) Start(_ telegraf.Accumulator) error {
 			return err
 		}
 	}
-	if p.MonitorPods {
+	if p.MonitorPods || p.MonitorServices {
 		if err := p.startK8s(ctx); err != nil {
 			return err
 		}
@@ -369,7 +398,7 @@ func addressToURL(u *url.URL, address string) *url.URL {
 }
 
 func (p *Prometheus) getAllURLs() (map[string]urlAndAddress, error) {
-	allURLs := make(map[string]urlAndAddress, len(p.URLs)+len(p.consulServices)+len(p.kubernetesPods)+len(p.httpServices))
+	allURLs := make(map[string]urlAndAddress, len(p.URLs)+len(p.consulServices)+len(p.kubernetesPods)+len(p.kubernetesSvcs)+len(p.httpServices))
 	for _, u := range p.URLs {
 		address, err := url.Parse(u)
 		if err != nil {
@@ -390,8 +419,14 @@ func (p *Prometheus) getAllURLs() (map[string]urlAndAddress, error) {
 		allURLs[k] = v
 	}
 	// loop through all pods scraped via the prometheus annotation on the pods
-	for _, v := range p.kubernetesPods {
-		if namespaceAnnotationMatch(v.namespace, p) {
+	for id, v := range p.kubernetesPods {
+		if namespaceAnnotationMatch(v.namespace, p) && p.ownsTarget(string(id)) {
+			allURLs[v.url.String()] = v
+		}
+	}
+	// add all services discovered via the kubernetes API
+	for key, v := range p.kubernetesSvcs {
+		if namespaceAnnotationMatch(v.namespace, p) && p.ownsTarget("service/"+key) {
 			allURLs[v.url.String()] = v
 		}
 	}
@@ -2,6 +2,7 @@
 package redfish
 
 import (
+	"context"
 	_ "embed"
 	"encoding/json"
 	"errors"
@@ -13,6 +14,7 @@ import (
 	"path"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -40,10 +42,20 @@ type Redfish struct {
 	Workarounds      []string        `toml:"workarounds"`
 	Timeout          config.Duration `toml:"timeout"`
 
+	Mode                    string          `toml:"mode"`
+	MetricReportDefinitions []string        `toml:"metric_report_definitions"`
+	ReconnectInterval       config.Duration `toml:"reconnect_interval"`
+	Log                     telegraf.Logger `toml:"-"`
+
 	tagSet map[string]bool
 	client http.Client
 	tls.ClientConfig
 	baseURL *url.URL
+
+	// client without timeout for the long-lived event stream
+	streamClient http.Client
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
 }
 
 type system struct {
@@ -181,6 +193,17 @@ func (r *Redfish) Init() error {
 		}
 	}
 
+	switch r.Mode {
+	case "":
+		r.Mode = "poll"
+	case "poll", "subscription":
+	default:
+		return fmt.Errorf("unknown mode %q", r.Mode)
+	}
+	if len(r.MetricReportDefinitions) > 0 && r.Mode != "subscription" {
+		return errors.New("'metric_report_definitions' requires subscription mode")
+	}
+
 	for _, workaround := range r.Workarounds {
 		switch workaround {
 		case "ilo4-thermal":
@@ -204,18 +227,49 @@ func (r *Redfish) Init() error {
 		return err
 	}
 
+	transport := &http.Transport{
+		TLSClientConfig:       tlsCfg,
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: time.Duration(r.Timeout),
+	}
 	r.client = http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: tlsCfg,
-			Proxy:           http.ProxyFromEnvironment,
-		},
-		Timeout: time.Duration(r.Timeout),
+		Transport: transport,
+		Timeout:   time.Duration(r.Timeout),
+	}
+	r.streamClient = http.Client{Transport: transport}
+
+	return nil
+}
+
+func (r *Redfish) Start(acc telegraf.Accumulator) error {
+	if r.Mode != "subscription" {
+		return nil
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.subscribe(ctx, acc)
+	}()
+
 	return nil
 }
 
+func (r *Redfish) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+}
+
 func (r *Redfish) Gather(acc telegraf.Accumulator) error {
+	// Metric reports are pushed by the service in subscription mode
+	if r.Mode == "subscription" {
+		return nil
+	}
+
 	address, _, err := net.SplitHostPort(r.baseURL.Host)
 	if err != nil {
 		address = r.baseURL.Host
@@ -250,12 +304,7 @@ func (r *Redfish) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
-func (r *Redfish) getData(address string, payload interface{}) error {
-	req, err := http.NewRequest("GET", address, nil)
-	if err != nil {
-		return err
-	}
-
+func (r *Redfish) setAuth(req *http.Request) error {
 	username, err := r.Username.Get()
 	if err != nil {
 		return fmt.Errorf("getting username failed: %w", err)
@@ -271,6 +320,18 @@ func (r *Redfish) getData(address string, payload interface{}) error {
 	password.Destroy()
 
 	req.SetBasicAuth(user, pass)
+	return nil
+}
+
+func (r *Redfish) getData(address string, payload interface{}) error {
+	req, err := http.NewRequest("GET", address, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := r.setAuth(req); err != nil {
+		return err
+	}
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("OData-Version", "4.0")
@@ -531,6 +592,9 @@ func init() {
 			// default tag set of chassis.location required for backwards compatibility
 			IncludeTagSets: []string{tagSetChassisLocation},
 			IncludeMetrics: []string{"power", "thermal"},
+			Mode:           "poll",
+
+			ReconnectInterval: config.Duration(5 * time.Second),
 		}
 	})
 }
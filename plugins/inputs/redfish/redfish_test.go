@@ -1,10 +1,12 @@
 package redfish
 
 import (
+	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -1212,3 +1215,183 @@ func TestIncludeTagSetsConfiguration(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expectedMetricsHp, hpAcc.GetTelegrafMetrics(),
 		testutil.IgnoreTime())
 }
+
+func TestSubscription(t *testing.T) {
+	reports := []string{
+		`{"@odata.type":"#MetricReport.v1_4_2.MetricReport","Id":"PowerMetrics",` +
+			`"MetricReportDefinition":{"@odata.id":"/redfish/v1/TelemetryService/MetricReportDefinitions/PowerMetrics"},` +
+			`"Timestamp":"2023-08-05T21:16:05Z","MetricValues":[` +
+			`{"MetricId":"SystemInputPower","MetricValue":"312",` +
+			`"MetricProperty":"/redfish/v1/Chassis/1U/Power#/PowerControl/0/PowerConsumedWatts"},` +
+			`{"MetricId":"PowerState","MetricValue":"On","Timestamp":"2023-08-05T21:16:04Z"}]}`,
+		`{"@odata.type":"#MetricReport.v1_4_2.MetricReport","Id":"ThermalMetrics",` +
+			`"MetricReportDefinition":{"@odata.id":"/redfish/v1/TelemetryService/MetricReportDefinitions/ThermalMetrics"},` +
+			`"Timestamp":"2023-08-05T21:16:05Z","MetricValues":[{"MetricId":"CPU1Temp","MetricValue":"41"}]}`,
+	}
+
+	// The first connection is closed after one event to test the reconnect
+	var connections atomic.Int32
+	lastEventID := make(chan string, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !checkAuth(r, "test", "test") {
+			http.Error(w, "Unauthorized.", http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/redfish/v1/Systems/System.Embedded.1":
+			http.ServeFile(w, r, "testdata/dell_systems.json")
+		case "/redfish/v1/TelemetryService":
+			fmt.Fprint(w, `{"Id":"TelemetryService","ServiceEnabled":true,"Status":{"State":"Enabled"}}`)
+		case "/redfish/v1/EventService":
+			fmt.Fprint(w, `{"Id":"EventService","ServiceEnabled":true,"ServerSentEventUri":"/redfish/v1/SSE"}`)
+		case "/redfish/v1/SSE":
+			if r.URL.Query().Get("$filter") != "EventFormatType eq 'MetricReport'" {
+				http.Error(w, "Bad filter.", http.StatusBadRequest)
+				return
+			}
+			lastEventID <- r.Header.Get("Last-Event-ID")
+			w.Header().Set("Content-Type", "text/event-stream")
+			if connections.Add(1) == 1 {
+				fmt.Fprintf(w, ": keep-alive\n\nid: 1\ndata: %s\n\n", reports[0])
+				return
+			}
+			fmt.Fprintf(w, "id: 2\ndata: %s\n\n", reports[1])
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	address, _, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+
+	plugin := &Redfish{
+		Address:           ts.URL,
+		Username:          config.NewSecret([]byte("test")),
+		Password:          config.NewSecret([]byte("test")),
+		ComputerSystemID:  "System.Embedded.1",
+		IncludeMetrics:    []string{"thermal", "power"},
+		Mode:              "subscription",
+		ReconnectInterval: config.Duration(10 * time.Millisecond),
+		Log:               testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Polling is disabled in subscription mode
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"redfish_telemetry",
+			map[string]string{
+				"address":         address,
+				"source":          "tpa-hostname",
+				"report":          "PowerMetrics",
+				"metric_id":       "SystemInputPower",
+				"metric_property": "/redfish/v1/Chassis/1U/Power#/PowerControl/0/PowerConsumedWatts",
+			},
+			map[string]interface{}{"value": float64(312)},
+			time.Date(2023, 8, 5, 21, 16, 5, 0, time.UTC),
+		),
+		metric.New(
+			"redfish_telemetry",
+			map[string]string{
+				"address":   address,
+				"source":    "tpa-hostname",
+				"report":    "PowerMetrics",
+				"metric_id": "PowerState",
+			},
+			map[string]interface{}{"value": "On"},
+			time.Date(2023, 8, 5, 21, 16, 4, 0, time.UTC),
+		),
+		metric.New(
+			"redfish_telemetry",
+			map[string]string{
+				"address":   address,
+				"source":    "tpa-hostname",
+				"report":    "ThermalMetrics",
+				"metric_id": "CPU1Temp",
+			},
+			map[string]interface{}{"value": float64(41)},
+			time.Date(2023, 8, 5, 21, 16, 5, 0, time.UTC),
+		),
+	}
+
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() >= uint64(len(expected))
+	}, 3*time.Second, 10*time.Millisecond)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+
+	// The reconnect resumes the stream after the last received event
+	require.Empty(t, <-lastEventID)
+	require.Equal(t, "1", <-lastEventID)
+	require.NotEmpty(t, acc.Errors)
+}
+
+func TestSubscriptionReportFilter(t *testing.T) {
+	plugin := &Redfish{
+		Address:                 "http://127.0.0.1",
+		Username:                config.NewSecret([]byte("test")),
+		Password:                config.NewSecret([]byte("test")),
+		ComputerSystemID:        "System.Embedded.1",
+		IncludeMetrics:          []string{"thermal", "power"},
+		Mode:                    "subscription",
+		MetricReportDefinitions: []string{"ThermalMetrics"},
+		Log:                     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	sys := &system{Hostname: "tpa-hostname"}
+	plugin.processEvent(&acc, "127.0.0.1", sys, []byte(
+		`{"Id":"PowerMetrics","MetricReportDefinition":{"@odata.id":"/redfish/v1/TelemetryService/MetricReportDefinitions/PowerMetrics"},`+
+			`"MetricValues":[{"MetricId":"SystemInputPower","MetricValue":"312"}]}`,
+	))
+	plugin.processEvent(&acc, "127.0.0.1", sys, []byte(
+		`{"Id":"ThermalMetrics-1","MetricReportDefinition":{"@odata.id":"/redfish/v1/TelemetryService/MetricReportDefinitions/ThermalMetrics"},`+
+			`"MetricValues":[{"MetricId":"CPU1Temp","MetricValue":"41"}]}`,
+	))
+	// Events not containing metric values are ignored
+	plugin.processEvent(&acc, "127.0.0.1", sys, []byte(`{"Id":"1","Events":[{"MessageId":"Alert.1.0.Test"}]}`))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"redfish_telemetry",
+			map[string]string{
+				"address":   "127.0.0.1",
+				"source":    "tpa-hostname",
+				"report":    "ThermalMetrics-1",
+				"metric_id": "CPU1Temp",
+			},
+			map[string]interface{}{"value": float64(41)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+	require.Empty(t, acc.Errors)
+}
+
+func TestInitInvalidMode(t *testing.T) {
+	plugin := &Redfish{
+		Address:          "http://127.0.0.1",
+		Username:         config.NewSecret([]byte("test")),
+		Password:         config.NewSecret([]byte("test")),
+		ComputerSystemID: "System.Embedded.1",
+		IncludeMetrics:   []string{"thermal", "power"},
+		Mode:             "push",
+	}
+	require.ErrorContains(t, plugin.Init(), `unknown mode "push"`)
+
+	plugin.Mode = "poll"
+	plugin.MetricReportDefinitions = []string{"PowerMetrics"}
+	require.ErrorContains(t, plugin.Init(), "requires subscription mode")
+}
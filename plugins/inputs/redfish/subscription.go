@@ -0,0 +1,227 @@
+package redfish
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Maximum size of a single server-sent event, metric reports can get large
+const maxEventSize = 16 * 1024 * 1024
+
+type eventService struct {
+	ServiceEnabled     *bool
+	ServerSentEventURI string `json:"ServerSentEventUri"`
+}
+
+type telemetryService struct {
+	ServiceEnabled *bool
+	Status         status
+}
+
+type metricReport struct {
+	ID                     string `json:"Id"`
+	Timestamp              string
+	MetricReportDefinition struct {
+		Ref string `json:"@odata.id"`
+	}
+	MetricValues []struct {
+		MetricID       string `json:"MetricId"`
+		MetricValue    string
+		MetricProperty string
+		Timestamp      string
+	}
+}
+
+// subscribe receives metric reports from the event stream of the service and
+// reconnects on errors until the context is cancelled
+func (r *Redfish) subscribe(ctx context.Context, acc telegraf.Accumulator) {
+	var lastID string
+	for {
+		err := r.stream(ctx, acc, &lastID)
+		if ctx.Err() != nil {
+			return
+		}
+		acc.AddError(fmt.Errorf("event stream of %q failed: %w", r.Address, err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(r.ReconnectInterval)):
+		}
+	}
+}
+
+// stream connects to the server-sent event stream of the event service and
+// processes the received events until the stream is closed
+func (r *Redfish) stream(ctx context.Context, acc telegraf.Accumulator, lastID *string) error {
+	address, _, err := net.SplitHostPort(r.baseURL.Host)
+	if err != nil {
+		address = r.baseURL.Host
+	}
+
+	// Resolve the hostname of the system to tag the metrics consistently with
+	// the poll mode
+	system, err := r.getComputerSystem(r.ComputerSystemID)
+	if err != nil {
+		return err
+	}
+
+	var telemetry telemetryService
+	loc := r.baseURL.ResolveReference(&url.URL{Path: "/redfish/v1/TelemetryService"})
+	if err := r.getData(loc.String(), &telemetry); err != nil {
+		return fmt.Errorf("querying telemetry service failed: %w", err)
+	}
+	if (telemetry.ServiceEnabled != nil && !*telemetry.ServiceEnabled) || telemetry.Status.State == "Disabled" {
+		return errors.New("telemetry service is disabled")
+	}
+
+	var events eventService
+	loc = r.baseURL.ResolveReference(&url.URL{Path: "/redfish/v1/EventService"})
+	if err := r.getData(loc.String(), &events); err != nil {
+		return fmt.Errorf("querying event service failed: %w", err)
+	}
+	if events.ServiceEnabled != nil && !*events.ServiceEnabled {
+		return errors.New("event service is disabled")
+	}
+	if events.ServerSentEventURI == "" {
+		return errors.New("event service does not support server-sent events")
+	}
+
+	ref, err := url.Parse(events.ServerSentEventURI)
+	if err != nil {
+		return fmt.Errorf("invalid server-sent event URI: %w", err)
+	}
+	loc = r.baseURL.ResolveReference(ref)
+	query := loc.Query()
+	query.Set("$filter", "EventFormatType eq 'MetricReport'")
+	loc.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", loc.String(), nil)
+	if err != nil {
+		return err
+	}
+	if err := r.setAuth(req); err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("OData-Version", "4.0")
+	if *lastID != "" {
+		req.Header.Set("Last-Event-ID", *lastID)
+	}
+
+	resp, err := r.streamClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("received status code %d (%s) for address %s, expected 200",
+			resp.StatusCode,
+			http.StatusText(resp.StatusCode),
+			loc.String())
+	}
+	r.Log.Debugf("Subscribed to metric reports of %q", r.Address)
+
+	// Parse the stream according to the server-sent events specification
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+	var data bytes.Buffer
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if data.Len() > 0 {
+				r.processEvent(acc, address, system, data.Bytes())
+				data.Reset()
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		case "id":
+			*lastID = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return errors.New("stream closed by service")
+}
+
+func (r *Redfish) processEvent(acc telegraf.Accumulator, address string, system *system, data []byte) {
+	var report metricReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		acc.AddError(fmt.Errorf("error parsing event: %w", err))
+		return
+	}
+	if len(report.MetricValues) == 0 {
+		r.Log.Tracef("Ignoring event without metric values from %q", r.Address)
+		return
+	}
+
+	definition := path.Base(report.MetricReportDefinition.Ref)
+	if report.MetricReportDefinition.Ref == "" {
+		definition = report.ID
+	}
+	if len(r.MetricReportDefinitions) > 0 && !slices.Contains(r.MetricReportDefinitions, definition) {
+		return
+	}
+
+	reportTime := parseTimestamp(report.Timestamp, time.Now())
+	for _, v := range report.MetricValues {
+		tags := map[string]string{
+			"address":   address,
+			"source":    system.Hostname,
+			"report":    report.ID,
+			"metric_id": v.MetricID,
+		}
+		if v.MetricProperty != "" {
+			tags["metric_property"] = v.MetricProperty
+		}
+
+		fields := make(map[string]interface{}, 1)
+		if value, err := strconv.ParseFloat(v.MetricValue, 64); err == nil {
+			fields["value"] = value
+		} else {
+			fields["value"] = v.MetricValue
+		}
+		acc.AddFields("redfish_telemetry", fields, tags, parseTimestamp(v.Timestamp, reportTime))
+	}
+}
+
+// parseTimestamp returns the time of the given RFC3339 timestamp or the
+// fallback if the timestamp is missing or invalid
+func parseTimestamp(timestamp string, fallback time.Time) time.Time {
+	if timestamp == "" {
+		return fallback
+	}
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return fallback
+	}
+	return t
+}
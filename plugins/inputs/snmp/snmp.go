@@ -5,9 +5,13 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/gosnmp/gosnmp"
+
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/snmp"
@@ -25,8 +29,17 @@ type Snmp struct {
 	// The tag used to name the agent host
 	AgentHostTag string `toml:"agent_host_tag"`
 
+	// Number of tables walked concurrently for each agent
+	MaxConcurrentTables int `toml:"max_concurrent_tables"`
+
 	snmp.ClientConfig
 
+	// Additional SNMPv3 credential sets tried in order for each agent
+	Credentials []snmp.Credential `toml:"credential"`
+
+	// Client settings overridden for individual agents
+	AgentOverrides []agentOverride `toml:"agent_override"`
+
 	Tables []snmp.Table `toml:"table"`
 
 	// Name & Fields are the elements of a Table.
@@ -37,11 +50,29 @@ type Snmp struct {
 
 	Log telegraf.Logger `toml:"-"`
 
-	connectionCache []snmp.Connection
+	connectionCache [][]snmp.Connection
+
+	// Client config of each agent with the overrides applied
+	agentConfigs []snmp.ClientConfig
+	// Credential sets to try and the index of the last working set per agent
+	credentials     []snmp.Credential
+	credentialIndex []int
+	// Agents whose connections must be reestablished with probing the
+	// credentials again, e.g. after the credentials were rotated
+	credentialReset []atomic.Bool
 
 	translator snmp.Translator
 }
 
+type agentOverride struct {
+	Agents  []string        `toml:"agents"`
+	Timeout config.Duration `toml:"timeout"`
+	Retries *int            `toml:"retries"`
+}
+
+// OID of sysObjectID.0 used to test the credentials of an agent
+const probeOID = ".1.3.6.1.2.1.1.2.0"
+
 func (*Snmp) SampleConfig() string {
 	return sampleConfig
 }
@@ -64,7 +95,13 @@ func (s *Snmp) Init() error {
 		return errors.New("invalid translator value")
 	}
 
-	s.connectionCache = make([]snmp.Connection, len(s.Agents))
+	if s.MaxConcurrentTables < 1 {
+		s.MaxConcurrentTables = 1
+	}
+	s.connectionCache = make([][]snmp.Connection, len(s.Agents))
+	for i := range s.connectionCache {
+		s.connectionCache[i] = make([]snmp.Connection, s.MaxConcurrentTables)
+	}
 
 	for i := range s.Tables {
 		if err := s.Tables[i].Init(s.translator); err != nil {
@@ -92,6 +129,41 @@ func (s *Snmp) Init() error {
 		s.GosnmpDebugLogger = s.Log
 	}
 
+	s.agentConfigs = make([]snmp.ClientConfig, len(s.Agents))
+	for i := range s.agentConfigs {
+		s.agentConfigs[i] = s.ClientConfig
+	}
+	for _, o := range s.AgentOverrides {
+		if len(o.Agents) == 0 {
+			return errors.New("agent override without agents")
+		}
+		for _, agent := range o.Agents {
+			idx := slices.Index(s.Agents, agent)
+			if idx < 0 {
+				return fmt.Errorf("override for unknown agent %q", agent)
+			}
+			if o.Timeout > 0 {
+				s.agentConfigs[idx].Timeout = o.Timeout
+			}
+			if o.Retries != nil {
+				s.agentConfigs[idx].Retries = *o.Retries
+			}
+		}
+	}
+
+	if len(s.Credentials) > 0 {
+		if s.Version != 3 {
+			return errors.New("credential sets require SNMP version 3")
+		}
+		// The top-level security parameters are tried first if configured
+		if s.SecName != "" {
+			s.credentials = append(s.credentials, s.ClientConfig.Credential())
+		}
+		s.credentials = append(s.credentials, s.Credentials...)
+		s.credentialIndex = make([]int, len(s.Agents))
+		s.credentialReset = make([]atomic.Bool, len(s.Agents))
+	}
+
 	return nil
 }
 
@@ -101,7 +173,7 @@ func (s *Snmp) Gather(acc telegraf.Accumulator) error {
 		wg.Add(1)
 		go func(i int, agent string) {
 			defer wg.Done()
-			gs, err := s.getConnection(i)
+			gs, err := s.getConnection(i, 0)
 			if err != nil {
 				acc.AddError(fmt.Errorf("agent %s: %w", agent, err))
 				return
@@ -114,15 +186,12 @@ func (s *Snmp) Gather(acc telegraf.Accumulator) error {
 			}
 			topTags := make(map[string]string)
 			if err := s.gatherTable(acc, gs, t, topTags, false); err != nil {
+				s.checkCredentialError(i, err)
 				acc.AddError(fmt.Errorf("agent %s: %w", agent, err))
 			}
 
 			// Now is the real tables.
-			for _, t := range s.Tables {
-				if err := s.gatherTable(acc, gs, t, topTags, true); err != nil {
-					acc.AddError(fmt.Errorf("agent %s: gathering table %s: %w", agent, t.Name, err))
-				}
-			}
+			s.gatherTables(acc, i, gs, topTags)
 		}(i, agent)
 	}
 	wg.Wait()
@@ -130,6 +199,54 @@ func (s *Snmp) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// gatherTables walks the tables of the agent with the given index using up to
+// MaxConcurrentTables sessions. The first session is the given connection.
+func (s *Snmp) gatherTables(acc telegraf.Accumulator, idx int, gs snmp.Connection, topTags map[string]string) {
+	agent := s.Agents[idx]
+
+	workers := min(s.MaxConcurrentTables, len(s.Tables))
+	if workers <= 1 {
+		for _, t := range s.Tables {
+			if err := s.gatherTable(acc, gs, t, topTags, true); err != nil {
+				s.checkCredentialError(idx, err)
+				acc.AddError(fmt.Errorf("agent %s: gathering table %s: %w", agent, t.Name, err))
+			}
+		}
+		return
+	}
+
+	tables := make(chan snmp.Table, len(s.Tables))
+	for _, t := range s.Tables {
+		tables <- t
+	}
+	close(tables)
+
+	var wg sync.WaitGroup
+	for session := 0; session < workers; session++ {
+		conn := gs
+		if session > 0 {
+			var err error
+			if conn, err = s.getConnection(idx, session); err != nil {
+				acc.AddError(fmt.Errorf("agent %s: session %d: %w", agent, session, err))
+				continue
+			}
+		}
+
+		wg.Add(1)
+		go func(conn snmp.Connection) {
+			defer wg.Done()
+			for t := range tables {
+				// The top-level tags are only read at this point
+				if err := s.gatherTable(acc, conn, t, topTags, true); err != nil {
+					s.checkCredentialError(idx, err)
+					acc.AddError(fmt.Errorf("agent %s: gathering table %s: %w", agent, t.Name, err))
+				}
+			}
+		}(conn)
+	}
+	wg.Wait()
+}
+
 func (s *Snmp) gatherTable(acc telegraf.Accumulator, gs snmp.Connection, t snmp.Table, topTags map[string]string, walk bool) error {
 	rt, err := t.Build(gs, walk)
 	if err != nil {
@@ -160,11 +277,15 @@ func (s *Snmp) gatherTable(acc telegraf.Accumulator, gs snmp.Connection, t snmp.
 }
 
 // getConnection creates a snmpConnection (*gosnmp.GoSNMP) object and caches the
-// result using `agentIndex` as the cache key.  This is done to allow multiple
-// connections to a single address.  It is an error to use a connection in
-// more than one goroutine.
-func (s *Snmp) getConnection(idx int) (snmp.Connection, error) {
-	if gs := s.connectionCache[idx]; gs != nil {
+// result using `agentIndex` and `session` as the cache key.  This is done to
+// allow multiple connections to a single address.  It is an error to use a
+// connection in more than one goroutine.
+func (s *Snmp) getConnection(idx, session int) (snmp.Connection, error) {
+	if s.credentials != nil && session == 0 && s.credentialReset[idx].CompareAndSwap(true, false) {
+		s.closeConnections(idx)
+	}
+
+	if gs := s.connectionCache[idx][session]; gs != nil {
 		if err := gs.Reconnect(); err != nil {
 			return gs, fmt.Errorf("reconnecting: %w", err)
 		}
@@ -172,9 +293,33 @@ func (s *Snmp) getConnection(idx int) (snmp.Connection, error) {
 		return gs, nil
 	}
 
+	var gs snmp.Connection
+	var err error
+	switch {
+	case s.credentials == nil:
+		gs, err = s.connect(idx, s.agentConfigs[idx])
+	case session == 0:
+		gs, err = s.tryCredentials(idx, func(cfg snmp.ClientConfig) (snmp.Connection, error) {
+			return s.connectAndProbe(idx, cfg)
+		})
+	default:
+		// Additional sessions use the credentials found for the first session
+		cred := s.credentials[s.credentialIndex[idx]]
+		gs, err = s.connect(idx, s.agentConfigs[idx].WithCredential(cred))
+	}
+	if gs != nil {
+		s.connectionCache[idx][session] = gs
+	}
+	if err != nil {
+		return nil, err
+	}
+	return gs, nil
+}
+
+func (s *Snmp) connect(idx int, cfg snmp.ClientConfig) (snmp.Connection, error) {
 	agent := s.Agents[idx]
 
-	gs, err := snmp.NewWrapper(s.ClientConfig)
+	gs, err := snmp.NewWrapper(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -184,27 +329,94 @@ func (s *Snmp) getConnection(idx int) (snmp.Connection, error) {
 		return nil, err
 	}
 
-	s.connectionCache[idx] = gs
-
 	if err := gs.Connect(); err != nil {
-		return nil, fmt.Errorf("setting up connection: %w", err)
+		return gs, fmt.Errorf("setting up connection: %w", err)
 	}
 
 	return gs, nil
 }
 
+// connectAndProbe connects to the agent and tests the credentials of the
+// config by querying the sysObjectID of the agent
+func (s *Snmp) connectAndProbe(idx int, cfg snmp.ClientConfig) (snmp.Connection, error) {
+	gs, err := s.connect(idx, cfg)
+	if err != nil {
+		if gs != nil {
+			closeConnection(gs)
+		}
+		return nil, err
+	}
+	if _, err := gs.Get([]string{probeOID}); err != nil {
+		closeConnection(gs)
+		return nil, err
+	}
+	return gs, nil
+}
+
+// tryCredentials connects to the agent with the credential sets in order,
+// starting with the set that worked last for the agent
+func (s *Snmp) tryCredentials(idx int, connect func(snmp.ClientConfig) (snmp.Connection, error)) (snmp.Connection, error) {
+	start := s.credentialIndex[idx]
+	errs := make([]error, 0, len(s.credentials))
+	for n := range s.credentials {
+		i := (start + n) % len(s.credentials)
+		cred := s.credentials[i]
+		gs, err := connect(s.agentConfigs[idx].WithCredential(cred))
+		if err == nil {
+			if i != start {
+				s.Log.Debugf("Agent %s: using credentials of security name %q", s.Agents[idx], cred.SecName)
+			}
+			s.credentialIndex[idx] = i
+			return gs, nil
+		}
+		errs = append(errs, fmt.Errorf("security name %q: %w", cred.SecName, err))
+	}
+	return nil, fmt.Errorf("no working credentials: %w", errors.Join(errs...))
+}
+
+// checkCredentialError marks the connections of the agent for being
+// reestablished if the error indicates invalid credentials
+func (s *Snmp) checkCredentialError(idx int, err error) {
+	if s.credentials == nil {
+		return
+	}
+	for _, e := range []error{gosnmp.ErrUnknownUsername, gosnmp.ErrWrongDigest, gosnmp.ErrUnknownSecurityLevel, gosnmp.ErrDecryption} {
+		if errors.Is(err, e) {
+			s.credentialReset[idx].Store(true)
+			return
+		}
+	}
+}
+
+func (s *Snmp) closeConnections(idx int) {
+	for session, gs := range s.connectionCache[idx] {
+		if gs != nil {
+			closeConnection(gs)
+		}
+		s.connectionCache[idx][session] = nil
+	}
+}
+
+func closeConnection(gs snmp.Connection) {
+	if w, ok := gs.(snmp.GosnmpWrapper); ok && w.Conn != nil {
+		w.Conn.Close()
+	}
+}
+
 func init() {
 	inputs.Add("snmp", func() telegraf.Input {
 		return &Snmp{
 			Name: "snmp",
 			ClientConfig: snmp.ClientConfig{
-				Retries:        3,
-				MaxRepetitions: 10,
-				Timeout:        config.Duration(5 * time.Second),
-				Version:        2,
-				Path:           []string{"/usr/share/snmp/mibs"},
-				Community:      "public",
+				Retries:         3,
+				MaxRepetitions:  10,
+				BulkWalkColumns: 1,
+				Timeout:         config.Duration(5 * time.Second),
+				Version:         2,
+				Path:            []string{"/usr/share/snmp/mibs"},
+				Community:       "public",
 			},
+			MaxConcurrentTables: 1,
 		}
 	})
 }
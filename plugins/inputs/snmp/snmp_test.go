@@ -1,6 +1,7 @@
 package snmp
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"path/filepath"
@@ -11,8 +12,10 @@ import (
 	"github.com/gosnmp/gosnmp"
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/snmp"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -190,7 +193,7 @@ func TestGetSNMPConnection_v2(t *testing.T) {
 	}
 	require.NoError(t, s.Init())
 
-	gsc, err := s.getConnection(0)
+	gsc, err := s.getConnection(0, 0)
 	require.NoError(t, err)
 	gs := gsc.(snmp.GosnmpWrapper)
 	require.Equal(t, "1.2.3.4", gs.Target)
@@ -199,14 +202,14 @@ func TestGetSNMPConnection_v2(t *testing.T) {
 	require.Equal(t, "foo", gs.Community)
 	require.Equal(t, "udp", gs.Transport)
 
-	gsc, err = s.getConnection(1)
+	gsc, err = s.getConnection(1, 0)
 	require.NoError(t, err)
 	gs = gsc.(snmp.GosnmpWrapper)
 	require.Equal(t, "1.2.3.4", gs.Target)
 	require.EqualValues(t, 161, gs.Port)
 	require.Equal(t, "udp", gs.Transport)
 
-	gsc, err = s.getConnection(2)
+	gsc, err = s.getConnection(2, 0)
 	require.NoError(t, err)
 	gs = gsc.(snmp.GosnmpWrapper)
 	require.Equal(t, "127.0.0.1", gs.Target)
@@ -230,7 +233,7 @@ func TestGetSNMPConnectionTCP(t *testing.T) {
 	}
 	require.NoError(t, s.Init())
 
-	gsc, err := s.getConnection(0)
+	gsc, err := s.getConnection(0, 0)
 	require.NoError(t, err)
 	gs := gsc.(snmp.GosnmpWrapper)
 	require.Equal(t, "127.0.0.1", gs.Target)
@@ -259,7 +262,7 @@ func TestGetSNMPConnection_v3(t *testing.T) {
 	err := s.Init()
 	require.NoError(t, err)
 
-	gsc, err := s.getConnection(0)
+	gsc, err := s.getConnection(0, 0)
 	require.NoError(t, err)
 	gs := gsc.(snmp.GosnmpWrapper)
 	require.Equal(t, gosnmp.Version3, gs.Version)
@@ -380,7 +383,7 @@ func TestGetSNMPConnection_v3_blumenthal(t *testing.T) {
 			err := s.Init()
 			require.NoError(t, err)
 
-			gsc, err := s.getConnection(0)
+			gsc, err := s.getConnection(0, 0)
 			require.NoError(t, err)
 			gs := gsc.(snmp.GosnmpWrapper)
 			require.Equal(t, gosnmp.Version3, gs.Version)
@@ -410,19 +413,39 @@ func TestGetSNMPConnection_caching(t *testing.T) {
 	}
 	err := s.Init()
 	require.NoError(t, err)
-	gs1, err := s.getConnection(0)
+	gs1, err := s.getConnection(0, 0)
 	require.NoError(t, err)
-	gs2, err := s.getConnection(0)
+	gs2, err := s.getConnection(0, 0)
 	require.NoError(t, err)
-	gs3, err := s.getConnection(1)
+	gs3, err := s.getConnection(1, 0)
 	require.NoError(t, err)
-	gs4, err := s.getConnection(2)
+	gs4, err := s.getConnection(2, 0)
 	require.NoError(t, err)
 	require.Equal(t, gs1, gs2)
 	require.NotEqual(t, gs2, gs3)
 	require.NotEqual(t, gs3, gs4)
 }
 
+func TestGetSNMPConnection_sessions(t *testing.T) {
+	s := &Snmp{
+		Agents:              []string{"1.2.3.4"},
+		MaxConcurrentTables: 2,
+		ClientConfig: snmp.ClientConfig{
+			Translator: "netsnmp",
+		},
+	}
+	require.NoError(t, s.Init())
+
+	gs1, err := s.getConnection(0, 0)
+	require.NoError(t, err)
+	gs2, err := s.getConnection(0, 1)
+	require.NoError(t, err)
+	gs3, err := s.getConnection(0, 1)
+	require.NoError(t, err)
+	require.NotSame(t, gs1.(snmp.GosnmpWrapper).GoSNMP, gs2.(snmp.GosnmpWrapper).GoSNMP)
+	require.Same(t, gs2.(snmp.GosnmpWrapper).GoSNMP, gs3.(snmp.GosnmpWrapper).GoSNMP)
+}
+
 func TestGosnmpWrapper_walk_retry(t *testing.T) {
 	t.Skip("Skipping test due to random failures.")
 
@@ -561,8 +584,8 @@ func TestGather(t *testing.T) {
 			},
 		},
 
-		connectionCache: []snmp.Connection{
-			tsc,
+		connectionCache: [][]snmp.Connection{
+			{tsc},
 		},
 	}
 	acc := &testutil.Accumulator{}
@@ -590,6 +613,53 @@ func TestGather(t *testing.T) {
 	require.Equal(t, 123456, m2.Fields["myOtherField"])
 }
 
+func TestGatherConcurrentTables(t *testing.T) {
+	s := &Snmp{
+		Agents:              []string{"TestGather"},
+		AgentHostTag:        "source",
+		MaxConcurrentTables: 3,
+		Name:                "mytable",
+		Fields: []snmp.Field{
+			{
+				Name:  "myfield1",
+				Oid:   ".1.0.0.1.1",
+				IsTag: true,
+			},
+		},
+		connectionCache: [][]snmp.Connection{
+			{tsc, tsc, tsc},
+		},
+	}
+	for i := 0; i < 5; i++ {
+		s.Tables = append(s.Tables, snmp.Table{
+			Name:        fmt.Sprintf("table%d", i),
+			InheritTags: []string{"myfield1"},
+			Fields: []snmp.Field{
+				{
+					Name: "myOtherField",
+					Oid:  ".1.0.0.0.1.5",
+				},
+			},
+		})
+	}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Gather(acc))
+	require.Empty(t, acc.Errors)
+
+	// The top-level fields only contain a tag so no metric is created for them
+	expected := make([]telegraf.Metric, 0, len(s.Tables))
+	for _, tbl := range s.Tables {
+		expected = append(expected, metric.New(
+			tbl.Name,
+			map[string]string{"myfield1": "baz", "source": "tsc"},
+			map[string]interface{}{"myOtherField": 123456},
+			time.Unix(0, 0),
+		))
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
 func TestGather_host(t *testing.T) {
 	s := &Snmp{
 		Agents: []string{"TestGather"},
@@ -606,8 +676,8 @@ func TestGather_host(t *testing.T) {
 			},
 		},
 
-		connectionCache: []snmp.Connection{
-			tsc,
+		connectionCache: [][]snmp.Connection{
+			{tsc},
 		},
 	}
 
@@ -741,7 +811,7 @@ func TestGatherGosmi(t *testing.T) {
 			},
 		},
 
-		connectionCache: []snmp.Connection{tsc},
+		connectionCache: [][]snmp.Connection{{tsc}},
 
 		ClientConfig: snmp.ClientConfig{
 			Translator: "gosmi",
@@ -788,7 +858,7 @@ func TestGather_hostGosmi(t *testing.T) {
 			},
 		},
 
-		connectionCache: []snmp.Connection{tsc},
+		connectionCache: [][]snmp.Connection{{tsc}},
 	}
 
 	acc := &testutil.Accumulator{}
@@ -799,3 +869,131 @@ func TestGather_hostGosmi(t *testing.T) {
 	m := acc.Metrics[0]
 	require.Equal(t, "baz", m.Tags["host"])
 }
+
+func TestGetSNMPConnection_agentOverride(t *testing.T) {
+	retries := 0
+	s := &Snmp{
+		Agents: []string{"1.2.3.4", "1.2.3.5"},
+		ClientConfig: snmp.ClientConfig{
+			Timeout:    config.Duration(3 * time.Second),
+			Retries:    4,
+			Version:    2,
+			Translator: "netsnmp",
+		},
+		AgentOverrides: []agentOverride{
+			{
+				Agents:  []string{"1.2.3.5"},
+				Timeout: config.Duration(10 * time.Second),
+				Retries: &retries,
+			},
+		},
+	}
+	require.NoError(t, s.Init())
+
+	gsc, err := s.getConnection(0, 0)
+	require.NoError(t, err)
+	gs := gsc.(snmp.GosnmpWrapper)
+	require.Equal(t, 3*time.Second, gs.Timeout)
+	require.Equal(t, 4, gs.Retries)
+
+	gsc, err = s.getConnection(1, 0)
+	require.NoError(t, err)
+	gs = gsc.(snmp.GosnmpWrapper)
+	require.Equal(t, 10*time.Second, gs.Timeout)
+	require.Equal(t, 0, gs.Retries)
+}
+
+func TestSnmpInit_invalidOverrides(t *testing.T) {
+	s := &Snmp{
+		Agents:         []string{"1.2.3.4"},
+		ClientConfig:   snmp.ClientConfig{Translator: "netsnmp"},
+		AgentOverrides: []agentOverride{{Agents: []string{"1.2.3.5"}}},
+	}
+	require.ErrorContains(t, s.Init(), `override for unknown agent "1.2.3.5"`)
+
+	s = &Snmp{
+		Agents:       []string{"1.2.3.4"},
+		ClientConfig: snmp.ClientConfig{Translator: "netsnmp", Version: 2},
+		Credentials:  []snmp.Credential{{SecName: "user"}},
+	}
+	require.ErrorContains(t, s.Init(), "credential sets require SNMP version 3")
+}
+
+func TestCredentialFallback(t *testing.T) {
+	s := &Snmp{
+		Agents: []string{"1.2.3.4", "1.2.3.5"},
+		ClientConfig: snmp.ClientConfig{
+			Version:    3,
+			SecLevel:   "authNoPriv",
+			SecName:    "current",
+			Translator: "netsnmp",
+		},
+		Credentials: []snmp.Credential{
+			{SecLevel: "authNoPriv", SecName: "legacy"},
+			{SecLevel: "authNoPriv", SecName: "fallback"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+
+	// The agent only accepts one of the credential sets
+	valid := "legacy"
+	var tried []string
+	connect := func(cfg snmp.ClientConfig) (snmp.Connection, error) {
+		tried = append(tried, cfg.SecName)
+		if cfg.SecName != valid {
+			return nil, gosnmp.ErrUnknownUsername
+		}
+		return &testSNMPConnection{host: "1.2.3.4"}, nil
+	}
+
+	_, err := s.tryCredentials(0, connect)
+	require.NoError(t, err)
+	require.Equal(t, []string{"current", "legacy"}, tried)
+
+	// The working credentials are tried first for the next connection
+	tried = nil
+	_, err = s.tryCredentials(0, connect)
+	require.NoError(t, err)
+	require.Equal(t, []string{"legacy"}, tried)
+
+	// Other agents are not affected
+	tried = nil
+	valid = "fallback"
+	_, err = s.tryCredentials(1, connect)
+	require.NoError(t, err)
+	require.Equal(t, []string{"current", "legacy", "fallback"}, tried)
+
+	// The sets are tried in order after the last working set
+	tried = nil
+	valid = "current"
+	_, err = s.tryCredentials(0, connect)
+	require.NoError(t, err)
+	require.Equal(t, []string{"legacy", "fallback", "current"}, tried)
+
+	valid = ""
+	_, err = s.tryCredentials(0, connect)
+	require.ErrorContains(t, err, "no working credentials")
+	require.ErrorIs(t, err, gosnmp.ErrUnknownUsername)
+}
+
+func TestCredentialReset(t *testing.T) {
+	s := &Snmp{
+		Agents: []string{"1.2.3.4"},
+		ClientConfig: snmp.ClientConfig{
+			Version:    3,
+			Translator: "netsnmp",
+		},
+		Credentials: []snmp.Credential{{SecLevel: "authNoPriv", SecName: "user"}},
+	}
+	require.NoError(t, s.Init())
+	s.connectionCache[0][0] = &testSNMPConnection{host: "1.2.3.4"}
+
+	s.checkCredentialError(0, errors.New("timeout"))
+	require.False(t, s.credentialReset[0].Load())
+	s.checkCredentialError(0, fmt.Errorf("performing get on field x: %w", gosnmp.ErrWrongDigest))
+	require.True(t, s.credentialReset[0].Load())
+
+	s.closeConnections(0)
+	require.Nil(t, s.connectionCache[0][0])
+}
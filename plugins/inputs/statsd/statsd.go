@@ -17,7 +17,10 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/handoff"
+	"github.com/influxdata/telegraf/internal/templating"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/graphite"
 	"github.com/influxdata/telegraf/selfstat"
@@ -93,7 +96,20 @@ type Statsd struct {
 
 	// Max duration for each metric to stay cached without being updated.
 	MaxTTL config.Duration `toml:"max_ttl"`
-	Log    telegraf.Logger `toml:"-"`
+
+	// Expiry overrides the maximum TTL of gauges and sets for bucket names
+	// matching the given pattern.
+	Expiry []*expiry `toml:"expiry"`
+
+	// CounterMode defines if counters are emitted as deltas since the last
+	// gather or as cumulative values. If empty, 'delete_counters' applies.
+	CounterMode string `toml:"counter_mode"`
+
+	// TemplatePriority defines which template is applied if multiple template
+	// filters match a bucket, either the most specific one or the first one.
+	TemplatePriority string `toml:"template_priority"`
+
+	Log telegraf.Logger `toml:"-"`
 
 	sync.Mutex
 	// Lock for preventing a data race during resource cleanup
@@ -127,8 +143,11 @@ type Statsd struct {
 	// track current connections so we can close them in Stop()
 	conns          map[string]*net.TCPConn
 	graphiteParser *graphite.Parser
-	acc            telegraf.Accumulator
-	bufPool        sync.Pool // pool of byte slices to handle parsing
+	// templates in configured order for the "order" template priority
+	orderedTemplates []*templating.Engine
+	defaultTemplate  *templating.Template
+	acc              telegraf.Accumulator
+	bufPool          sync.Pool // pool of byte slices to handle parsing
 
 	lastGatherTime time.Time
 
@@ -164,6 +183,13 @@ func (n *number) UnmarshalTOML(b []byte) error {
 	return nil
 }
 
+type expiry struct {
+	Pattern string          `toml:"pattern"`
+	TTL     config.Duration `toml:"ttl"`
+
+	filter filter.Filter
+}
+
 type input struct {
 	*bytes.Buffer
 	time.Time
@@ -223,6 +249,53 @@ func (*Statsd) SampleConfig() string {
 	return sampleConfig
 }
 
+func (s *Statsd) Init() error {
+	switch s.CounterMode {
+	case "", "delta", "cumulative":
+	default:
+		return fmt.Errorf("invalid 'counter_mode' %q", s.CounterMode)
+	}
+
+	for i, e := range s.Expiry {
+		if e.Pattern == "" {
+			return fmt.Errorf("empty pattern in expiry setting %d", i+1)
+		}
+		if e.TTL <= 0 {
+			return fmt.Errorf("'ttl' of expiry pattern %q has to be positive", e.Pattern)
+		}
+		f, err := filter.Compile([]string{e.Pattern})
+		if err != nil {
+			return fmt.Errorf("compiling expiry pattern %q failed: %w", e.Pattern, err)
+		}
+		e.filter = f
+	}
+
+	switch s.TemplatePriority {
+	case "", "specificity":
+	case "order":
+		separator := s.MetricSeparator
+		if separator == "" {
+			separator = defaultSeparator
+		}
+		for _, tmpl := range s.Templates {
+			engine, err := templating.NewEngine(separator, nil, []string{tmpl})
+			if err != nil {
+				return fmt.Errorf("creating template %q failed: %w", tmpl, err)
+			}
+			s.orderedTemplates = append(s.orderedTemplates, engine)
+		}
+		defaultTemplate, err := templating.NewDefaultTemplateWithPattern("measurement*")
+		if err != nil {
+			return fmt.Errorf("creating default template failed: %w", err)
+		}
+		s.defaultTemplate = defaultTemplate
+	default:
+		return fmt.Errorf("invalid 'template_priority' %q", s.TemplatePriority)
+	}
+
+	return nil
+}
+
 func (s *Statsd) Start(ac telegraf.Accumulator) error {
 	s.acc = ac
 
@@ -273,14 +346,14 @@ func (s *Statsd) Start(ac telegraf.Accumulator) error {
 	}
 
 	if s.isUDP() {
-		address, err := net.ResolveUDPAddr(s.Protocol, s.ServiceAddress)
+		pc, err := handoff.ListenPacket(s.Protocol, s.ServiceAddress)
 		if err != nil {
 			return err
 		}
-
-		conn, err := net.ListenUDP(s.Protocol, address)
-		if err != nil {
-			return err
+		conn, ok := pc.(*net.UDPConn)
+		if !ok {
+			pc.Close()
+			return fmt.Errorf("unexpected connection type %T", pc)
 		}
 
 		s.Log.Infof("UDP listening on %q", conn.LocalAddr().String())
@@ -294,13 +367,14 @@ func (s *Statsd) Start(ac telegraf.Accumulator) error {
 			}
 		}()
 	} else {
-		address, err := net.ResolveTCPAddr("tcp", s.ServiceAddress)
+		l, err := handoff.Listen("tcp", s.ServiceAddress)
 		if err != nil {
 			return err
 		}
-		listener, err := net.ListenTCP("tcp", address)
-		if err != nil {
-			return err
+		listener, ok := l.(*net.TCPListener)
+		if !ok {
+			l.Close()
+			return fmt.Errorf("unexpected listener type %T", l)
 		}
 
 		s.Log.Infof("TCP listening on %q", listener.Addr().String())
@@ -393,19 +467,35 @@ func (s *Statsd) Gather(acc telegraf.Accumulator) error {
 	}
 
 	for _, m := range s.counters {
-		if s.EnableAggregationTemporality && m.fields != nil {
-			m.fields["start_time"] = s.lastGatherTime.Format(time.RFC3339)
+		// Copy the fields as the cached values must remain integers for
+		// counters being kept across gathers
+		fields := make(map[string]interface{}, len(m.fields)+1)
+		for key, value := range m.fields {
+			if s.FloatCounters {
+				fields[key] = float64(value.(int64))
+			} else {
+				fields[key] = value
+			}
+		}
+		if s.EnableAggregationTemporality {
+			fields["start_time"] = s.lastGatherTime.Format(time.RFC3339)
 		}
 
-		if s.FloatCounters {
+		acc.AddCounter(m.name, fields, m.tags, now)
+	}
+	switch s.CounterMode {
+	case "delta":
+		// Keep the series but restart counting from zero
+		for _, m := range s.counters {
 			for key := range m.fields {
-				m.fields[key] = float64(m.fields[key].(int64))
+				m.fields[key] = int64(0)
 			}
 		}
-		acc.AddCounter(m.name, m.fields, m.tags, now)
-	}
-	if s.DeleteCounters {
-		s.counters = make(map[string]cachedcounter)
+	case "cumulative":
+	default:
+		if s.DeleteCounters {
+			s.counters = make(map[string]cachedcounter)
+		}
 	}
 
 	for _, m := range s.sets {
@@ -724,7 +814,7 @@ func (s *Statsd) parseStatsdLine(line string) error {
 			m.tags["metric_type"] = "counter"
 
 			if s.EnableAggregationTemporality {
-				if s.DeleteCounters {
+				if s.CounterMode == "delta" || (s.CounterMode == "" && s.DeleteCounters) {
 					m.tags["temporality"] = "delta"
 				} else {
 					m.tags["temporality"] = "cumulative"
@@ -795,19 +885,23 @@ func (s *Statsd) parseName(bucket string) (name, field string, tags map[string]s
 		s.Log.Errorf("Unknown sanitizae name method: %s", s.SanitizeNamesMethod)
 	}
 
-	p := s.graphiteParser
-	var err error
+	if s.TemplatePriority == "order" {
+		name, tags, field = s.applyOrderedTemplates(name, tags)
+	} else {
+		p := s.graphiteParser
+		var err error
 
-	if p == nil || s.graphiteParser.Separator != s.MetricSeparator {
-		p = &graphite.Parser{Separator: s.MetricSeparator, Templates: s.Templates}
-		err = p.Init()
-		s.graphiteParser = p
-	}
+		if p == nil || s.graphiteParser.Separator != s.MetricSeparator {
+			p = &graphite.Parser{Separator: s.MetricSeparator, Templates: s.Templates}
+			err = p.Init()
+			s.graphiteParser = p
+		}
 
-	if err == nil {
-		p.DefaultTags = tags
-		//nolint:errcheck // unable to propagate
-		name, tags, field, _ = p.ApplyTemplate(name)
+		if err == nil {
+			p.DefaultTags = tags
+			//nolint:errcheck // unable to propagate
+			name, tags, field, _ = p.ApplyTemplate(name)
+		}
 	}
 
 	if s.ConvertNames {
@@ -821,6 +915,30 @@ func (s *Statsd) parseName(bucket string) (name, field string, tags map[string]s
 	return name, field, tags
 }
 
+// applyOrderedTemplates applies the first template in the configured order
+// with a filter matching the name. Tags given in the bucket are only added if
+// not extracted by the template, as for the "specificity" priority.
+func (s *Statsd) applyOrderedTemplates(name string, bucketTags map[string]string) (string, map[string]string, string) {
+	tmpl := s.defaultTemplate
+	for _, engine := range s.orderedTemplates {
+		if t := engine.Match(name); t != nil {
+			tmpl = t
+			break
+		}
+	}
+
+	measurement, tags, field, err := tmpl.Apply(name, s.MetricSeparator)
+	if err != nil {
+		return name, bucketTags, ""
+	}
+	for k, v := range bucketTags {
+		if _, found := tags[k]; !found {
+			tags[k] = v
+		}
+	}
+	return measurement, tags, field
+}
+
 // Parse the key,value out of a string that looks like "key=value"
 func parseKeyValue(keyValue string) (key, val string) {
 	split := strings.Split(keyValue, "=")
@@ -884,7 +1002,7 @@ func (s *Statsd) aggregate(m metric) {
 			field.addValue(m.floatvalue)
 		}
 		cached.fields[m.field] = field
-		cached.expiresAt = time.Now().Add(time.Duration(s.MaxTTL))
+		cached.expiresAt = s.expiresAt(m.bucket, false)
 		s.timings[m.hash] = cached
 	case "c":
 		// check if the measurement exists
@@ -902,7 +1020,7 @@ func (s *Statsd) aggregate(m metric) {
 			cached.fields[m.field] = int64(0)
 		}
 		cached.fields[m.field] = cached.fields[m.field].(int64) + m.intvalue
-		cached.expiresAt = time.Now().Add(time.Duration(s.MaxTTL))
+		cached.expiresAt = s.expiresAt(m.bucket, false)
 		s.counters[m.hash] = cached
 	case "g":
 		// check if the measurement exists
@@ -925,7 +1043,7 @@ func (s *Statsd) aggregate(m metric) {
 			cached.fields[m.field] = m.floatvalue
 		}
 
-		cached.expiresAt = time.Now().Add(time.Duration(s.MaxTTL))
+		cached.expiresAt = s.expiresAt(m.bucket, true)
 		s.gauges[m.hash] = cached
 	case "s":
 		// check if the measurement exists
@@ -943,7 +1061,7 @@ func (s *Statsd) aggregate(m metric) {
 			cached.fields[m.field] = make(map[string]bool)
 		}
 		cached.fields[m.field][m.strvalue] = true
-		cached.expiresAt = time.Now().Add(time.Duration(s.MaxTTL))
+		cached.expiresAt = s.expiresAt(m.bucket, true)
 		s.sets[m.hash] = cached
 	}
 }
@@ -1031,34 +1149,58 @@ func (s *Statsd) isUDP() bool {
 	return strings.HasPrefix(s.Protocol, "udp")
 }
 
+// expiresAt returns the expiry time of a cached metric updated now. Expiry
+// patterns are only applied if the metric supports them. A zero time means
+// the metric never expires.
+func (s *Statsd) expiresAt(bucket string, usePatterns bool) time.Time {
+	ttl := time.Duration(s.MaxTTL)
+	if usePatterns && len(s.Expiry) > 0 {
+		name, _, _ := strings.Cut(bucket, ",")
+		for _, e := range s.Expiry {
+			if e.filter != nil && e.filter.Match(name) {
+				ttl = time.Duration(e.TTL)
+				break
+			}
+		}
+	}
+
+	if ttl == 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
 func (s *Statsd) expireCachedMetrics() {
-	// If Max TTL wasn't configured, skip expiration.
-	if s.MaxTTL == 0 {
+	// If no TTL was configured, skip expiration.
+	if s.MaxTTL == 0 && len(s.Expiry) == 0 {
 		return
 	}
 
 	now := time.Now()
+	expired := func(t time.Time) bool {
+		return !t.IsZero() && now.After(t)
+	}
 
 	for key, cached := range s.gauges {
-		if now.After(cached.expiresAt) {
+		if expired(cached.expiresAt) {
 			delete(s.gauges, key)
 		}
 	}
 
 	for key, cached := range s.sets {
-		if now.After(cached.expiresAt) {
+		if expired(cached.expiresAt) {
 			delete(s.sets, key)
 		}
 	}
 
 	for key, cached := range s.timings {
-		if now.After(cached.expiresAt) {
+		if expired(cached.expiresAt) {
 			delete(s.timings, key)
 		}
 	}
 
 	for key, cached := range s.counters {
-		if now.After(cached.expiresAt) {
+		if expired(cached.expiresAt) {
 			delete(s.counters, key)
 		}
 	}
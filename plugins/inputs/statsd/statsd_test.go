@@ -2397,3 +2397,125 @@ func TestParse_DeltaCounter(t *testing.T) {
 
 	require.NoError(t, conn.Close())
 }
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Statsd
+		expected string
+	}{
+		{
+			name:     "invalid counter mode",
+			plugin:   &Statsd{CounterMode: "foo"},
+			expected: `invalid 'counter_mode' "foo"`,
+		},
+		{
+			name:     "invalid template priority",
+			plugin:   &Statsd{TemplatePriority: "foo"},
+			expected: `invalid 'template_priority' "foo"`,
+		},
+		{
+			name:     "empty expiry pattern",
+			plugin:   &Statsd{Expiry: []*expiry{{TTL: config.Duration(time.Minute)}}},
+			expected: "empty pattern in expiry setting 1",
+		},
+		{
+			name:     "invalid expiry ttl",
+			plugin:   &Statsd{Expiry: []*expiry{{Pattern: "app.*"}}},
+			expected: `'ttl' of expiry pattern "app.*" has to be positive`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestExpiryPatterns(t *testing.T) {
+	s := newTestStatsd()
+	s.MaxTTL = config.Duration(time.Hour)
+	s.Expiry = []*expiry{
+		{Pattern: "short.*", TTL: config.Duration(10 * time.Millisecond)},
+	}
+	require.NoError(t, s.Init())
+
+	require.NoError(t, s.parseStatsdLine("short.gauge:1|g"))
+	require.NoError(t, s.parseStatsdLine("short.set:foo|s"))
+	require.NoError(t, s.parseStatsdLine("long.gauge:2|g"))
+	require.NoError(t, s.parseStatsdLine("short.counter:3|c"))
+
+	time.Sleep(100 * time.Millisecond)
+	s.expireCachedMetrics()
+
+	// Only gauges and sets matching the pattern expire
+	require.Len(t, s.gauges, 1)
+	require.NoError(t, testValidateGauge("long_gauge", 2, s.gauges))
+	require.Empty(t, s.sets)
+	require.NoError(t, testValidateCounter("short_counter", 3, s.counters))
+}
+
+func TestCounterMode(t *testing.T) {
+	newMetric := func(value int64) telegraf.Metric {
+		return testutil.MustMetric(
+			"requests",
+			map[string]string{"metric_type": "counter"},
+			map[string]interface{}{"value": value},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		)
+	}
+
+	tests := []struct {
+		mode     string
+		expected []telegraf.Metric
+	}{
+		{
+			mode:     "delta",
+			expected: []telegraf.Metric{newMetric(5), newMetric(0), newMetric(2)},
+		},
+		{
+			mode:     "cumulative",
+			expected: []telegraf.Metric{newMetric(5), newMetric(5), newMetric(7)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			s := newTestStatsd()
+			s.CounterMode = tt.mode
+			s.DeleteCounters = true
+			require.NoError(t, s.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, s.parseStatsdLine("requests:5|c"))
+			require.NoError(t, s.Gather(&acc))
+			require.NoError(t, s.Gather(&acc))
+			require.NoError(t, s.parseStatsdLine("requests:2|c"))
+			require.NoError(t, s.Gather(&acc))
+
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestParse_TemplatePriorityOrder(t *testing.T) {
+	s := newTestStatsd()
+	s.TemplatePriority = "order"
+	s.Templates = []string{
+		"cpu.* measurement.host.measurement",
+		"cpu.idle.* measurement.measurement.host",
+	}
+	require.NoError(t, s.Init())
+
+	// The first template matches despite the second one being more specific
+	require.NoError(t, s.parseStatsdLine("cpu.idle.localhost:1|c"))
+	require.NoError(t, s.parseStatsdLine("mem.used:2|g"))
+
+	require.NoError(t, testValidateCounter("cpu_localhost", 1, s.counters))
+	for _, m := range s.counters {
+		require.Equal(t, "idle", m.tags["host"])
+	}
+	require.NoError(t, testValidateGauge("mem_used", 2, s.gauges))
+}
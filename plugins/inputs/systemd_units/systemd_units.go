@@ -18,6 +18,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -144,6 +145,14 @@ type archParams struct {
 	scope         string
 	user          string
 	warnUnitProps map[string]bool
+	accepted      map[string]acceptSample
+}
+
+// acceptSample holds the number of accepted connections of a socket unit at
+// the given time for computing the accept rate
+type acceptSample struct {
+	count     uint64
+	timestamp time.Time
 }
 
 type client interface {
@@ -214,6 +223,7 @@ func (s *SystemdUnits) Init() error {
 	}
 
 	s.warnUnitProps = make(map[string]bool)
+	s.accepted = make(map[string]acceptSample)
 
 	return nil
 }
@@ -424,6 +434,11 @@ func (s *SystemdUnits) Gather(acc telegraf.Accumulator) error {
 			if v, found := unitProperties["ActiveEnterTimestamp"]; found {
 				fields["active_enter_timestamp_us"] = v
 			}
+			if v, found := unitProperties["NeedDaemonReload"]; found {
+				// The unit file changed on disk but systemd still uses the
+				// previously loaded configuration
+				fields["need_daemon_reload"] = v
+			}
 
 			fields["status_errno"] = properties["StatusErrno"]
 			fields["restarts"] = properties["NRestarts"]
@@ -436,6 +451,24 @@ func (s *SystemdUnits) Gather(acc telegraf.Accumulator) error {
 			fields["swap_current"] = properties["MemorySwapCurrent"]
 			fields["swap_peak"] = properties["MemorySwapPeak"]
 
+			// Add unit-type specific fields
+			switch s.UnitType {
+			case "service":
+				if v, ok := properties["Result"].(string); ok {
+					fields["start_limit_hit"] = v == "start-limit-hit"
+				}
+			case "timer":
+				fields["last_trigger_timestamp_us"] = properties["LastTriggerUSec"]
+				fields["next_elapse_timestamp_us"] = properties["NextElapseUSecRealtime"]
+			case "socket":
+				fields["accepted"] = properties["NAccepted"]
+				fields["connections"] = properties["NConnections"]
+				fields["refused"] = properties["NRefused"]
+				if rate, ok := s.acceptRate(state.Name, properties["NAccepted"]); ok {
+					fields["accept_rate"] = rate
+				}
+			}
+
 			// Sanitize unset memory fields
 			for k, value := range fields {
 				switch {
@@ -453,6 +486,28 @@ func (s *SystemdUnits) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// acceptRate computes the number of accepted connections per second since the
+// last gathering cycle. No rate is reported for the first sample or if the
+// counter was reset, e.g. due to restarting the socket.
+func (s *SystemdUnits) acceptRate(name string, value interface{}) (float64, bool) {
+	count, err := internal.ToUint64(value)
+	if err != nil {
+		return 0, false
+	}
+	now := time.Now()
+
+	prev, found := s.accepted[name]
+	s.accepted[name] = acceptSample{count: count, timestamp: now}
+	if !found || count < prev.count {
+		return 0, false
+	}
+	elapsed := now.Sub(prev.timestamp).Seconds()
+	if elapsed <= 0 {
+		return 0, false
+	}
+	return float64(count-prev.count) / elapsed, true
+}
+
 func (s *SystemdUnits) Stop() {
 	if s.client != nil && s.client.Connected() {
 		s.client.Close()
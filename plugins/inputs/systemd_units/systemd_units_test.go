@@ -29,6 +29,7 @@ type properties struct {
 	ufPreset      string
 	ufState       string
 	ufActiveEnter uint64
+	ufProperties  map[string]interface{}
 	properties    map[string]interface{}
 }
 
@@ -568,6 +569,61 @@ func TestShow(t *testing.T) {
 				),
 			},
 		},
+		{
+			name: "example rate limited with pending reload",
+			properties: map[string]properties{
+				"example.service": {
+					utype: "Service",
+					state: &sdbus.UnitStatus{
+						Name:        "example.service",
+						LoadState:   "loaded",
+						ActiveState: "failed",
+						SubState:    "failed",
+					},
+					ufPreset:      "disabled",
+					ufState:       "enabled",
+					ufActiveEnter: 0,
+					ufProperties: map[string]interface{}{
+						"NeedDaemonReload": true,
+					},
+					properties: map[string]interface{}{
+						"Id":          "example.service",
+						"StatusErrno": 0,
+						"NRestarts":   5,
+						"Result":      "start-limit-hit",
+					},
+				},
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"systemd_units",
+					map[string]string{
+						"name":   "example.service",
+						"load":   "loaded",
+						"active": "failed",
+						"sub":    "failed",
+						"state":  "enabled",
+						"preset": "disabled",
+					},
+					map[string]interface{}{
+						"load_code":                 0,
+						"active_code":               3,
+						"sub_code":                  12,
+						"status_errno":              0,
+						"restarts":                  5,
+						"mem_current":               uint64(0),
+						"mem_peak":                  uint64(0),
+						"swap_current":              uint64(0),
+						"swap_peak":                 uint64(0),
+						"mem_avail":                 uint64(0),
+						"active_enter_timestamp_us": uint64(0),
+						"need_daemon_reload":        true,
+						"start_limit_hit":           true,
+					},
+					time.Unix(0, 0),
+				),
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -607,6 +663,139 @@ func TestShow(t *testing.T) {
 	}
 }
 
+func TestTimerDetails(t *testing.T) {
+	plugin := &SystemdUnits{
+		Pattern:  "examp*",
+		UnitType: "timer",
+		Details:  true,
+		Timeout:  config.Duration(time.Second),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.client = &fakeClient{
+		units: map[string]properties{
+			"example.timer": {
+				utype: "Timer",
+				state: &sdbus.UnitStatus{
+					Name:        "example.timer",
+					LoadState:   "loaded",
+					ActiveState: "active",
+					SubState:    "waiting",
+				},
+				ufPreset: "enabled",
+				ufState:  "enabled",
+				properties: map[string]interface{}{
+					"Id":                     "example.timer",
+					"LastTriggerUSec":        uint64(1700000000000000),
+					"NextElapseUSecRealtime": uint64(1700003600000000),
+				},
+			},
+		},
+		connected: true,
+	}
+	defer plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"systemd_units",
+			map[string]string{
+				"name":   "example.timer",
+				"load":   "loaded",
+				"active": "active",
+				"sub":    "waiting",
+				"state":  "enabled",
+				"preset": "enabled",
+			},
+			map[string]interface{}{
+				"load_code":                 0,
+				"active_code":               0,
+				"sub_code":                  0x0010,
+				"mem_current":               uint64(0),
+				"mem_peak":                  uint64(0),
+				"swap_current":              uint64(0),
+				"swap_peak":                 uint64(0),
+				"mem_avail":                 uint64(0),
+				"active_enter_timestamp_us": uint64(0),
+				"last_trigger_timestamp_us": uint64(1700000000000000),
+				"next_elapse_timestamp_us":  uint64(1700003600000000),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestSocketDetails(t *testing.T) {
+	plugin := &SystemdUnits{
+		Pattern:  "examp*",
+		UnitType: "socket",
+		Details:  true,
+		Timeout:  config.Duration(time.Second),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	client := &fakeClient{
+		units: map[string]properties{
+			"example.socket": {
+				utype: "Socket",
+				state: &sdbus.UnitStatus{
+					Name:        "example.socket",
+					LoadState:   "loaded",
+					ActiveState: "active",
+					SubState:    "listening",
+				},
+				ufPreset: "enabled",
+				ufState:  "enabled",
+				properties: map[string]interface{}{
+					"Id":           "example.socket",
+					"NAccepted":    uint32(100),
+					"NConnections": uint32(2),
+					"NRefused":     uint32(0),
+				},
+			},
+		},
+		connected: true,
+	}
+	plugin.client = client
+	defer plugin.Stop()
+
+	// The first gathering cycle has no previous sample to compute a rate
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	require.Equal(t, uint64(100), metrics[0].Fields()["accepted"])
+	require.Equal(t, uint64(2), metrics[0].Fields()["connections"])
+	require.Equal(t, uint64(0), metrics[0].Fields()["refused"])
+	require.NotContains(t, metrics[0].Fields(), "accept_rate")
+
+	// Fake the previous sample to be ten seconds old
+	sample := plugin.accepted["example.socket"]
+	sample.timestamp = sample.timestamp.Add(-10 * time.Second)
+	plugin.accepted["example.socket"] = sample
+	client.units["example.socket"].properties["NAccepted"] = uint32(150)
+
+	acc.ClearMetrics()
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	metrics = acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	require.InDelta(t, 5.0, metrics[0].Fields()["accept_rate"], 0.1)
+
+	// A reset counter must not result in a rate
+	client.units["example.socket"].properties["NAccepted"] = uint32(10)
+
+	acc.ClearMetrics()
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	metrics = acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	require.NotContains(t, metrics[0].Fields(), "accept_rate")
+}
+
 func TestMultiInstance(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -990,11 +1179,15 @@ func (c *fakeClient) GetUnitPropertiesContext(_ context.Context, unit string) (m
 		return nil, nil
 	}
 
-	return map[string]interface{}{
+	props := map[string]interface{}{
 		"UnitFileState":        u.ufState,
 		"UnitFilePreset":       u.ufPreset,
 		"ActiveEnterTimestamp": u.ufActiveEnter,
-	}, nil
+	}
+	for k, v := range u.ufProperties {
+		props[k] = v
+	}
+	return props, nil
 }
 
 func (c *fakeClient) ListUnitsContext(_ context.Context) ([]sdbus.UnitStatus, error) {
@@ -14,6 +14,8 @@ import (
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/performance"
 	"github.com/vmware/govmomi/session"
+	"github.com/vmware/govmomi/vapi/rest"
+	"github.com/vmware/govmomi/vapi/tags"
 	"github.com/vmware/govmomi/view"
 	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/methods"
@@ -43,6 +45,8 @@ type client struct {
 	views     *view.Manager
 	root      *view.ContainerView
 	perf      *performance.Manager
+	rest      *rest.Client
+	auth      *url.Userinfo
 	valid     bool
 	timeout   time.Duration
 	closeGate sync.Once
@@ -221,6 +225,7 @@ func newClient(ctx context.Context, vSphereURL *url.URL, vs *VSphere) (*client,
 		views:   m,
 		root:    v,
 		perf:    p,
+		auth:    vSphereURL.User,
 		valid:   true,
 		timeout: time.Duration(vs.Timeout),
 	}
@@ -254,6 +259,11 @@ func (c *client) close() {
 	c.closeGate.Do(func() {
 		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
 		defer cancel()
+		if c.rest != nil {
+			if err := c.rest.Logout(ctx); err != nil {
+				c.log.Errorf("REST logout: %s", err.Error())
+			}
+		}
 		if c.client != nil {
 			if err := c.client.Logout(ctx); err != nil {
 				c.log.Errorf("Logout: %s", err.Error())
@@ -359,3 +369,21 @@ func (c *client) getCustomFields(ctx context.Context) (map[int32]string, error)
 	}
 	return r, nil
 }
+
+// tagManager returns a manager for vSphere tags and categories. The REST session
+// required for those is created on first use and renewed once expired.
+func (c *client) tagManager(ctx context.Context) (*tags.Manager, error) {
+	ctx1, cancel1 := context.WithTimeout(ctx, c.timeout)
+	defer cancel1()
+	if c.rest == nil {
+		c.rest = rest.NewClient(c.client.Client)
+	} else if s, err := c.rest.Session(ctx1); err == nil && s != nil {
+		return tags.NewManager(c.rest), nil
+	}
+
+	// Without user information the session of the SOAP client is used as token
+	if err := c.rest.Login(ctx1, c.auth); err != nil {
+		return nil, fmt.Errorf("creating REST session failed: %w", err)
+	}
+	return tags.NewManager(c.rest), nil
+}
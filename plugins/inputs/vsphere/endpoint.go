@@ -56,6 +56,9 @@ type endpoint struct {
 	customFields      map[int32]string
 	customAttrFilter  filter.Filter
 	customAttrEnabled bool
+	tagCategoryFilter filter.Filter
+	tagsEnabled       bool
+	tagCache          *tagCache
 	metricNameLookup  map[int32]string
 	metricNameMux     sync.RWMutex
 	log               telegraf.Logger
@@ -104,6 +107,7 @@ type objectRef struct {
 	dcname            string
 	rpname            string
 	customValues      map[string]string
+	tags              map[string]string
 	lookup            map[string]string
 }
 
@@ -128,6 +132,9 @@ func newEndpoint(ctx context.Context, parent *VSphere, address *url.URL, log tel
 		clientFactory:     newClientFactory(address, parent),
 		customAttrFilter:  newFilterOrPanic(parent.CustomAttributeInclude, parent.CustomAttributeExclude),
 		customAttrEnabled: anythingEnabled(parent.CustomAttributeExclude),
+		tagCategoryFilter: newFilterOrPanic(parent.TagCategoryInclude, parent.TagCategoryExclude),
+		tagsEnabled:       anythingEnabled(parent.TagCategoryExclude),
+		tagCache:          newTagCache(time.Duration(parent.TagCacheTTL)),
 		log:               log,
 	}
 
@@ -510,6 +517,11 @@ func (e *endpoint) discover(ctx context.Context) error {
 					e.complexMetadataSelect(ctx, res, objects)
 				}
 			}
+
+			// Attach the vSphere tags from the tagging service
+			if e.tagsEnabled {
+				e.loadTags(ctx, client, objects)
+			}
 			newObjects[k] = objects
 
 			sendInternalCounterWithTags("discovered_objects", e.url.Host, map[string]string{"type": res.name}, int64(len(objects)))
@@ -1421,6 +1433,11 @@ func (e *endpoint) populateTags(objectRef *objectRef, resourceType string, resou
 			t[k] = v
 		}
 	}
+
+	// Fill in vSphere tags if they exist
+	for k, v := range objectRef.tags {
+		t[k] = v
+	}
 }
 
 func (e *endpoint) populateGlobalFields(objectRef *objectRef, resourceType, prefix string) map[string]interface{} {
@@ -0,0 +1,164 @@
+package vsphere
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/vapi/tags"
+	"github.com/vmware/govmomi/vim25/mo"
+)
+
+// Maximum number of objects to query the attached tags for in one request
+const maxTagQueryObjects = 500
+
+// tagCache keeps the vSphere tags attached to inventory objects between
+// discoveries to avoid querying the tagging service for every object at each
+// discovery. Entries are refreshed once they are older than the TTL.
+type tagCache struct {
+	ttl time.Duration
+
+	// Names of tags and categories by ID
+	names        map[string]tags.Tag
+	categories   map[string]string
+	namesExpires time.Time
+
+	// Tags of the objects by managed object ID
+	objects map[string]*taggedObject
+}
+
+type taggedObject struct {
+	tags    map[string]string
+	expires time.Time
+}
+
+func newTagCache(ttl time.Duration) *tagCache {
+	return &tagCache{
+		ttl:     ttl,
+		objects: make(map[string]*taggedObject),
+	}
+}
+
+// loadTags sets the vSphere tags of the given objects with the category name
+// as key and the tag name as value. Multiple tags of the same category are
+// joined in alphabetical order separated by comma.
+func (e *endpoint) loadTags(ctx context.Context, client *client, objects objectMap) {
+	now := time.Now()
+
+	// Find the objects without up-to-date tags
+	refs := make([]mo.Reference, 0, len(objects))
+	for _, obj := range objects {
+		if cached, found := e.tagCache.objects[obj.ref.Value]; !found || now.After(cached.expires) {
+			refs = append(refs, obj.ref)
+		}
+	}
+
+	if len(refs) > 0 {
+		if err := e.refreshTags(ctx, client, refs, now); err != nil {
+			e.log.Warnf("Could not load tags: %s", err)
+		}
+	}
+
+	for _, obj := range objects {
+		if cached, found := e.tagCache.objects[obj.ref.Value]; found {
+			obj.tags = cached.tags
+		}
+	}
+}
+
+func (e *endpoint) refreshTags(ctx context.Context, client *client, refs []mo.Reference, now time.Time) error {
+	m, err := client.tagManager(ctx)
+	if err != nil {
+		return err
+	}
+
+	attached := make([]tags.AttachedTags, 0, len(refs))
+	for chunk := range slices.Chunk(refs, maxTagQueryObjects) {
+		ctx1, cancel1 := context.WithTimeout(ctx, time.Duration(e.parent.Timeout))
+		res, err := m.ListAttachedTagsOnObjects(ctx1, chunk)
+		cancel1()
+		if err != nil {
+			return err
+		}
+		attached = append(attached, res...)
+	}
+
+	// Tags created since the last refresh of the names require a new refresh
+	missing := now.After(e.tagCache.namesExpires)
+	for _, a := range attached {
+		for _, id := range a.TagIDs {
+			if _, found := e.tagCache.names[id]; !found {
+				missing = true
+			}
+		}
+	}
+	if missing {
+		if err := e.refreshTagNames(ctx, m, now); err != nil {
+			return err
+		}
+	}
+
+	// Objects without tags are not returned but must be cached nevertheless
+	expires := now.Add(e.tagCache.ttl)
+	for _, ref := range refs {
+		e.tagCache.objects[ref.Reference().Value] = &taggedObject{expires: expires}
+	}
+	for _, a := range attached {
+		values := make(map[string][]string)
+		for _, id := range a.TagIDs {
+			tag, found := e.tagCache.names[id]
+			if !found {
+				continue
+			}
+			category, found := e.tagCache.categories[tag.CategoryID]
+			if !found || !e.tagCategoryFilter.Match(category) {
+				continue
+			}
+			values[category] = append(values[category], tag.Name)
+		}
+
+		t := make(map[string]string, len(values))
+		for category, names := range values {
+			slices.Sort(names)
+			t[category] = strings.Join(names, ",")
+		}
+		e.tagCache.objects[a.ObjectID.Reference().Value] = &taggedObject{tags: t, expires: expires}
+	}
+
+	// Forget about objects not discovered anymore
+	for id, cached := range e.tagCache.objects {
+		if now.After(cached.expires.Add(e.tagCache.ttl)) {
+			delete(e.tagCache.objects, id)
+		}
+	}
+
+	return nil
+}
+
+func (e *endpoint) refreshTagNames(ctx context.Context, m *tags.Manager, now time.Time) error {
+	ctx1, cancel1 := context.WithTimeout(ctx, time.Duration(e.parent.Timeout))
+	defer cancel1()
+	categories, err := m.GetCategories(ctx1)
+	if err != nil {
+		return err
+	}
+
+	ctx2, cancel2 := context.WithTimeout(ctx, time.Duration(e.parent.Timeout))
+	defer cancel2()
+	tagList, err := m.GetTags(ctx2)
+	if err != nil {
+		return err
+	}
+
+	e.tagCache.categories = make(map[string]string, len(categories))
+	for _, c := range categories {
+		e.tagCache.categories[c.ID] = c.Name
+	}
+	e.tagCache.names = make(map[string]tags.Tag, len(tagList))
+	for _, t := range tagList {
+		e.tagCache.names[t.ID] = t
+	}
+	e.tagCache.namesExpires = now.Add(e.tagCache.ttl)
+	return nil
+}
@@ -11,7 +11,11 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/performance"
 	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vapi/rest"
+	_ "github.com/vmware/govmomi/vapi/simulator"
+	"github.com/vmware/govmomi/vapi/tags"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 
@@ -139,6 +143,7 @@ func defaultVSphere() *VSphere {
 		CollectConcurrency:      1,
 		Separator:               ".",
 		HistoricalInterval:      config.Duration(time.Second * 300),
+		TagCategoryExclude:      []string{"*"},
 	}
 }
 
@@ -473,6 +478,75 @@ func TestDisconnectedServerBehavior(t *testing.T) {
 	require.Equal(t, `"something else" is not a valid value for disconnected_servers_behavior`, err.Error())
 }
 
+func TestDiscoveryTags(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping long test in short mode")
+	}
+
+	model := simulator.VPX()
+	require.NoError(t, model.Create())
+	defer model.Remove()
+	model.Service.TLS = new(tls.Config)
+	model.Service.RegisterEndpoints = true
+	s := model.Service.NewServer()
+	defer s.Close()
+
+	// Tag a VM using the tagging service of the simulator
+	v := defaultVSphere()
+	c, err := newClient(t.Context(), s.URL, v)
+	require.NoError(t, err)
+	defer c.close()
+
+	rc := rest.NewClient(c.client.Client)
+	require.NoError(t, rc.Login(t.Context(), s.URL.User))
+	tm := tags.NewManager(rc)
+	categories := map[string][]string{
+		"environment": {"production"},
+		"owner":       {"team-b", "team-a"},
+	}
+	tagIDs := make([]string, 0, 3)
+	for category, names := range categories {
+		categoryID, err := tm.CreateCategory(t.Context(), &tags.Category{Name: category, Cardinality: "MULTIPLE"})
+		require.NoError(t, err)
+		for _, name := range names {
+			id, err := tm.CreateTag(t.Context(), &tags.Tag{Name: name, CategoryID: categoryID})
+			require.NoError(t, err)
+			tagIDs = append(tagIDs, id)
+		}
+	}
+
+	var vms []mo.VirtualMachine
+	f := finder{c}
+	require.NoError(t, f.find(t.Context(), "VirtualMachine", "/DC0/vm/DC0_H0_VM0", &vms))
+	require.Len(t, vms, 1)
+	require.NoError(t, tm.AttachMultipleTagsToObject(t.Context(), tagIDs, vms[0].Reference()))
+
+	// Discover with tags of the owner category only
+	v.TagCategoryInclude = []string{"owner"}
+	v.TagCategoryExclude = nil
+	v.TagCacheTTL = config.Duration(time.Hour)
+	e, err := newEndpoint(t.Context(), v, s.URL, v.Log)
+	require.NoError(t, err)
+	defer e.close()
+	require.NoError(t, e.discover(t.Context()))
+
+	res := e.resourceKinds["vm"]
+	var found bool
+	for _, obj := range res.objects {
+		if obj.name != "DC0_H0_VM0" {
+			require.Empty(t, obj.tags)
+			continue
+		}
+		found = true
+		metricTags := make(map[string]string)
+		e.populateTags(obj, "vm", res, metricTags, performance.MetricSeries{Name: "cpu.usage.average"})
+		require.Equal(t, "team-a,team-b", metricTags["owner"])
+		require.NotContains(t, metricTags, "environment")
+	}
+	require.True(t, found, "Tagged VM not discovered")
+	require.Contains(t, e.tagCache.objects, vms[0].Self.Value)
+}
+
 func testCollection(t *testing.T, excludeClusters bool) {
 	mustHaveMetrics := map[string]struct{}{
 		"vsphere.vm.cpu":         {},
@@ -0,0 +1,123 @@
+package webserver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var errUnknownFormat = errors.New("unknown status format")
+
+// parseApache parses the machine readable status page of Apache's mod_status
+// module as returned with the "auto" query parameter
+func parseApache(buf []byte) (*status, error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if found {
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if _, found := values["BusyWorkers"]; !found {
+		return nil, errUnknownFormat
+	}
+
+	fields := make(map[string]interface{})
+	for key, field := range map[string]string{
+		"BusyWorkers":    "active",
+		"IdleWorkers":    "idle",
+		"Total Accesses": "requests",
+		"Uptime":         "uptime",
+	} {
+		v, found := values[key]
+		if !found {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %q failed: %w", key, err)
+		}
+		fields[field] = n
+	}
+	if v, found := values["Total kBytes"]; found {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %q failed: %w", "Total kBytes", err)
+		}
+		fields["bytes"] = n * 1024
+	}
+
+	return &status{tags: make(map[string]string), fields: fields}, nil
+}
+
+// parseNginx parses the status page of Nginx's stub_status module, e.g.
+//
+//	Active connections: 291
+//	server accepts handled requests
+//	 16630948 16630948 31070465
+//	Reading: 6 Writing: 179 Waiting: 106
+func parseNginx(buf []byte) (*status, error) {
+	parts := strings.Fields(string(buf))
+	if len(parts) != 16 ||
+		parts[0] != "Active" || parts[1] != "connections:" ||
+		parts[3] != "server" || parts[4] != "accepts" || parts[5] != "handled" || parts[6] != "requests" ||
+		parts[10] != "Reading:" || parts[12] != "Writing:" || parts[14] != "Waiting:" {
+		return nil, errUnknownFormat
+	}
+
+	fields := make(map[string]interface{})
+	for i, field := range map[int]string{
+		2:  "active",
+		7:  "accepted",
+		8:  "handled",
+		9:  "requests",
+		11: "reading",
+		13: "writing",
+		15: "idle",
+	} {
+		n, err := strconv.ParseInt(parts[i], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %q failed: %w", field, err)
+		}
+		fields[field] = n
+	}
+
+	return &status{tags: make(map[string]string), fields: fields}, nil
+}
+
+// parsePhpfpm parses the status page of a PHP-FPM pool in JSON format
+func parsePhpfpm(buf []byte) (*status, error) {
+	var data struct {
+		Pool               *string `json:"pool"`
+		StartSince         int64   `json:"start since"`
+		AcceptedConn       int64   `json:"accepted conn"`
+		ListenQueue        int64   `json:"listen queue"`
+		IdleProcesses      int64   `json:"idle processes"`
+		ActiveProcesses    int64   `json:"active processes"`
+		MaxChildrenReached int64   `json:"max children reached"`
+	}
+	if err := json.Unmarshal(buf, &data); err != nil || data.Pool == nil {
+		return nil, errUnknownFormat
+	}
+
+	st := &status{
+		tags: map[string]string{"pool": *data.Pool},
+		fields: map[string]interface{}{
+			"active":               data.ActiveProcesses,
+			"idle":                 data.IdleProcesses,
+			"requests":             data.AcceptedConn,
+			"uptime":               data.StartSince,
+			"queued":               data.ListenQueue,
+			"max_children_reached": data.MaxChildrenReached,
+		},
+	}
+	return st, nil
+}
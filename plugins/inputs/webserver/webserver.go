@@ -0,0 +1,222 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package webserver
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Webserver struct {
+	URLs          []string        `toml:"urls"`
+	Servers       []string        `toml:"servers"`
+	ProbeInterval config.Duration `toml:"probe_interval"`
+	Log           telegraf.Logger `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client    *http.Client
+	endpoints []*endpoint
+	lastProbe time.Time
+	reprobe   bool
+}
+
+// endpoint is a detected status page
+type endpoint struct {
+	server string
+	url    string
+	parse  parseFunc
+}
+
+// status is the normalized status of a server
+type status struct {
+	tags   map[string]string
+	fields map[string]interface{}
+}
+
+// parseFunc parses a status page and returns an error if the page is not of
+// the expected kind
+type parseFunc func([]byte) (*status, error)
+
+// detector contains the status page locations and the parser of one kind of
+// server
+type detector struct {
+	paths []string
+	parse parseFunc
+}
+
+var detectors = map[string]detector{
+	"apache": {
+		paths: []string{"/server-status?auto"},
+		parse: parseApache,
+	},
+	"nginx": {
+		paths: []string{"/nginx_status", "/stub_status", "/basic_status"},
+		parse: parseNginx,
+	},
+	"phpfpm": {
+		paths: []string{"/status?json", "/fpm-status?json"},
+		parse: parsePhpfpm,
+	},
+}
+
+func (*Webserver) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *Webserver) Init() error {
+	if len(w.URLs) == 0 {
+		w.URLs = []string{"http://localhost"}
+	}
+	for i, u := range w.URLs {
+		if _, err := url.Parse(u); err != nil {
+			return fmt.Errorf("parsing URL %q failed: %w", u, err)
+		}
+		w.URLs[i] = strings.TrimRight(u, "/")
+	}
+
+	if len(w.Servers) == 0 {
+		w.Servers = []string{"apache", "nginx", "phpfpm"}
+	}
+	for _, s := range w.Servers {
+		if _, found := detectors[s]; !found {
+			return fmt.Errorf("unknown server %q", s)
+		}
+	}
+
+	if w.ProbeInterval == 0 {
+		w.ProbeInterval = config.Duration(10 * time.Minute)
+	}
+
+	return nil
+}
+
+func (w *Webserver) Start(telegraf.Accumulator) error {
+	client, err := w.HTTPClientConfig.CreateClient(context.Background(), w.Log)
+	if err != nil {
+		return fmt.Errorf("creating client failed: %w", err)
+	}
+	w.client = client
+
+	return nil
+}
+
+func (w *Webserver) Gather(acc telegraf.Accumulator) error {
+	if w.reprobe || time.Since(w.lastProbe) >= time.Duration(w.ProbeInterval) {
+		w.probe()
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, ep := range w.endpoints {
+		wg.Add(1)
+		go func(ep *endpoint) {
+			defer wg.Done()
+
+			st, err := w.query(ep.url, ep.parse)
+			if err != nil {
+				acc.AddError(fmt.Errorf("querying %s status at %q failed: %w", ep.server, ep.url, err))
+				// The server might have been reconfigured so detect it again
+				mu.Lock()
+				w.reprobe = true
+				mu.Unlock()
+				return
+			}
+
+			st.tags["server"] = ep.server
+			st.tags["url"] = ep.url
+			acc.AddFields("webserver", st.fields, st.tags)
+		}(ep)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (w *Webserver) Stop() {
+	if w.client != nil {
+		w.client.CloseIdleConnections()
+	}
+}
+
+// probe detects the status pages of the configured servers. For each URL and
+// server, the known locations are tried in order and the first one returning
+// a valid status page is used.
+func (w *Webserver) probe() {
+	var endpoints []*endpoint
+	for _, base := range w.URLs {
+		for _, server := range w.Servers {
+			d := detectors[server]
+			for _, path := range d.paths {
+				u := base + path
+				if _, err := w.query(u, d.parse); err != nil {
+					w.Log.Tracef("No %s status at %q: %v", server, u, err)
+					continue
+				}
+				endpoints = append(endpoints, &endpoint{server: server, url: u, parse: d.parse})
+				break
+			}
+		}
+	}
+
+	// Only log if the detected endpoints changed
+	changed := len(endpoints) != len(w.endpoints)
+	for i := 0; !changed && i < len(endpoints); i++ {
+		changed = endpoints[i].url != w.endpoints[i].url
+	}
+	if changed {
+		detected := make([]string, 0, len(endpoints))
+		for _, ep := range endpoints {
+			detected = append(detected, ep.server+"@"+ep.url)
+		}
+		if len(detected) == 0 {
+			w.Log.Debug("No status pages detected")
+		} else {
+			w.Log.Debugf("Detected status pages: %s", strings.Join(detected, ", "))
+		}
+	}
+
+	w.endpoints = endpoints
+	w.lastProbe = time.Now()
+	w.reprobe = false
+}
+
+func (w *Webserver) query(u string, parse parseFunc) (*status, error) {
+	resp, err := w.client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received status %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading body failed: %w", err)
+	}
+
+	return parse(body)
+}
+
+func init() {
+	inputs.Add("webserver", func() telegraf.Input {
+		return &Webserver{
+			ProbeInterval: config.Duration(10 * time.Minute),
+		}
+	})
+}
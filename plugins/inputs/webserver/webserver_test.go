@@ -0,0 +1,196 @@
+package webserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const apacheStatus = `localhost
+ServerVersion: Apache/2.4.57 (Debian)
+Total Accesses: 129811861
+Total kBytes: 5213701865
+Uptime: 3357619
+ReqPerSec: 38.6616
+BusyWorkers: 3
+IdleWorkers: 197
+Scoreboard: _WW___________________
+`
+
+const nginxStatus = `Active connections: 585
+server accepts handled requests
+ 85340 85340 35085
+Reading: 4 Writing: 135 Waiting: 446
+`
+
+const phpfpmStatus = `{
+  "pool": "www",
+  "process manager": "dynamic",
+  "start time": 1700000000,
+  "start since": 1200,
+  "accepted conn": 3627,
+  "listen queue": 0,
+  "max listen queue": 0,
+  "listen queue len": 0,
+  "idle processes": 4,
+  "active processes": 1,
+  "total processes": 5,
+  "max active processes": 3,
+  "max children reached": 0,
+  "slow requests": 0
+}`
+
+func TestInitFail(t *testing.T) {
+	plugin := &Webserver{Servers: []string{"iis"}}
+	require.ErrorContains(t, plugin.Init(), `unknown server "iis"`)
+}
+
+func TestDetection(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/server-status", func(w http.ResponseWriter, r *http.Request) {
+		if _, found := r.URL.Query()["auto"]; !found {
+			_, _ = w.Write([]byte("<html>Apache Status</html>"))
+			return
+		}
+		_, _ = w.Write([]byte(apacheStatus))
+	})
+	mux.HandleFunc("/basic_status", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(nginxStatus))
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(phpfpmStatus))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	plugin := &Webserver{
+		URLs: []string{server.URL + "/"},
+		Log:  testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"webserver",
+			map[string]string{
+				"server": "apache",
+				"url":    server.URL + "/server-status?auto",
+			},
+			map[string]interface{}{
+				"active":   int64(3),
+				"idle":     int64(197),
+				"requests": int64(129811861),
+				"uptime":   int64(3357619),
+				"bytes":    int64(5213701865 * 1024),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"webserver",
+			map[string]string{
+				"server": "nginx",
+				"url":    server.URL + "/basic_status",
+			},
+			map[string]interface{}{
+				"active":   int64(585),
+				"accepted": int64(85340),
+				"handled":  int64(85340),
+				"requests": int64(35085),
+				"reading":  int64(4),
+				"writing":  int64(135),
+				"idle":     int64(446),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"webserver",
+			map[string]string{
+				"server": "phpfpm",
+				"url":    server.URL + "/status?json",
+				"pool":   "www",
+			},
+			map[string]interface{}{
+				"active":               int64(1),
+				"idle":                 int64(4),
+				"requests":             int64(3627),
+				"uptime":               int64(1200),
+				"queued":               int64(0),
+				"max_children_reached": int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestRedetection(t *testing.T) {
+	path := "/nginx_status"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(nginxStatus))
+	}))
+	defer server.Close()
+
+	plugin := &Webserver{
+		URLs:    []string{server.URL},
+		Servers: []string{"nginx"},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	require.Equal(t, server.URL+"/nginx_status", acc.GetTelegrafMetrics()[0].Tags()["url"])
+
+	// Move the status page, the query must fail and trigger a detection
+	path = "/stub_status"
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	acc.ClearMetrics()
+	acc.Errors = nil
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	require.Equal(t, server.URL+"/stub_status", acc.GetTelegrafMetrics()[0].Tags()["url"])
+}
+
+func TestNoServers(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	plugin := &Webserver{
+		URLs: []string{server.URL},
+		Log:  testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
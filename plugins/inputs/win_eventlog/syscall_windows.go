@@ -10,6 +10,19 @@ const (
 	errInsufficientBuffer syscall.Errno = 122
 	errNoMoreItems        syscall.Errno = 259
 	errInvalidOperation   syscall.Errno = 4317
+	// ERROR_EVT_QUERY_RESULT_STALE
+	errEvtQueryResultStale syscall.Errno = 15011
+)
+
+// evtSubscribeNotifyAction defines the possible actions passed to the
+// subscription callback.
+type evtSubscribeNotifyAction uint32
+
+// EVT_SUBSCRIBE_NOTIFY_ACTION enumeration
+// https://learn.microsoft.com/en-us/windows/win32/api/winevt/ne-winevt-evt_subscribe_notify_action
+const (
+	evtSubscribeActionError   evtSubscribeNotifyAction = 0
+	evtSubscribeActionDeliver evtSubscribeNotifyAction = 1
 )
 
 // evtSubscribeFlag defines the possible values that specify when to start subscribing to events.
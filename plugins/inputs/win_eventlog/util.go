@@ -146,3 +146,40 @@ func uniqueFieldNames(fields []eventField, fieldsUsage map[string]int, separator
 	}
 	return fieldsUnique
 }
+
+// buildQuery creates a structured XML query from the given filters
+func buildQuery(filters []eventFilter) (string, error) {
+	var buf strings.Builder
+	buf.WriteString("<QueryList>")
+	for i, f := range filters {
+		if f.Channel == "" {
+			return "", fmt.Errorf("filter %d: missing channel", i)
+		}
+		selects := f.Select
+		if len(selects) == 0 {
+			selects = []string{"*"}
+		}
+
+		var channel strings.Builder
+		if err := xml.EscapeText(&channel, []byte(f.Channel)); err != nil {
+			return "", fmt.Errorf("filter %d: escaping channel failed: %w", i, err)
+		}
+		fmt.Fprintf(&buf, `<Query Id="%d" Path="%s">`, i, channel.String())
+		for _, element := range []struct {
+			name  string
+			paths []string
+		}{{"Select", selects}, {"Suppress", f.Suppress}} {
+			for _, path := range element.paths {
+				fmt.Fprintf(&buf, `<%s Path="%s">`, element.name, channel.String())
+				if err := xml.EscapeText(&buf, []byte(path)); err != nil {
+					return "", fmt.Errorf("filter %d: escaping %q failed: %w", i, path, err)
+				}
+				fmt.Fprintf(&buf, "</%s>", element.name)
+			}
+		}
+		buf.WriteString("</Query>")
+	}
+	buf.WriteString("</QueryList>")
+
+	return buf.String(), nil
+}
@@ -10,6 +10,8 @@ import (
 	"reflect"
 	"testing"
 	"unicode/utf16"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestDecodeUTF16(t *testing.T) {
@@ -196,3 +198,33 @@ func TestUniqueFieldNames(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildQuery(t *testing.T) {
+	filters := []eventFilter{
+		{
+			Channel:  "Security",
+			Suppress: []string{"*[System[(EventID >= 5152 and EventID <= 5158)]]"},
+		},
+		{
+			Channel: "Windows PowerShell",
+			Select:  []string{"*[System[(Level < 4)]]", "*[System[(EventID=400)]]"},
+		},
+	}
+	expected := `<QueryList>` +
+		`<Query Id="0" Path="Security">` +
+		`<Select Path="Security">*</Select>` +
+		`<Suppress Path="Security">*[System[(EventID &gt;= 5152 and EventID &lt;= 5158)]]</Suppress>` +
+		`</Query>` +
+		`<Query Id="1" Path="Windows PowerShell">` +
+		`<Select Path="Windows PowerShell">*[System[(Level &lt; 4)]]</Select>` +
+		`<Select Path="Windows PowerShell">*[System[(EventID=400)]]</Select>` +
+		`</Query>` +
+		`</QueryList>`
+
+	actual, err := buildQuery(filters)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+
+	_, err = buildQuery([]eventFilter{{Select: []string{"*"}}})
+	require.ErrorContains(t, err, "missing channel")
+}
@@ -11,8 +11,11 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -33,6 +36,9 @@ type WinEventLog struct {
 	Locale                 uint32          `toml:"locale"`
 	EventlogName           string          `toml:"eventlog_name"`
 	Query                  string          `toml:"xpath_query"`
+	Filters                []eventFilter   `toml:"filter"`
+	SubscriptionMode       string          `toml:"subscription_mode"`
+	BookmarkFile           string          `toml:"bookmark_file"`
 	FromBeginning          bool            `toml:"from_beginning"`
 	BatchSize              uint32          `toml:"event_batch_size"`
 	ProcessUserData        bool            `toml:"process_userdata"`
@@ -49,10 +55,26 @@ type WinEventLog struct {
 
 	subscription     evtHandle
 	subscriptionFlag evtSubscribeFlag
-	bookmark         evtHandle
 	tagFilter        filter.Filter
 	fieldFilter      filter.Filter
 	fieldEmptyFilter filter.Filter
+
+	// The bookmark is updated from the subscription callback in push mode
+	// while being persisted during gather so we need to protect it
+	bookmark      evtHandle
+	bookmarkLock  sync.Mutex
+	savedBookmark string
+
+	acc        telegraf.Accumulator
+	callback   uintptr
+	publishers map[string]evtHandle
+}
+
+// eventFilter is a server-side XPath filter for a single channel
+type eventFilter struct {
+	Channel  string   `toml:"channel"`
+	Select   []string `toml:"select"`
+	Suppress []string `toml:"suppress"`
 }
 
 func (*WinEventLog) SampleConfig() string {
@@ -70,6 +92,28 @@ func (w *WinEventLog) Init() error {
 		w.subscriptionFlag = evtSubscribeStartAtOldestRecord
 	}
 
+	switch w.SubscriptionMode {
+	case "":
+		w.SubscriptionMode = "pull"
+	case "pull", "push":
+	default:
+		return fmt.Errorf("invalid subscription_mode %q", w.SubscriptionMode)
+	}
+
+	if len(w.Filters) > 0 {
+		if w.Query != "" {
+			return errors.New("'xpath_query' and 'filter' are mutually exclusive")
+		}
+		if w.EventlogName != "" {
+			return errors.New("'eventlog_name' cannot be used together with 'filter'")
+		}
+		query, err := buildQuery(w.Filters)
+		if err != nil {
+			return err
+		}
+		w.Query = query
+	}
+
 	if w.Query == "" {
 		w.Query = "*"
 	}
@@ -99,10 +143,21 @@ func (w *WinEventLog) Init() error {
 		return fmt.Errorf("creating empty fields filter failed: %w", err)
 	}
 
+	w.publishers = make(map[string]evtHandle)
+
 	return nil
 }
 
-func (w *WinEventLog) Start(telegraf.Accumulator) error {
+func (w *WinEventLog) Start(acc telegraf.Accumulator) error {
+	w.acc = acc
+
+	// A bookmark file takes precedence over the state persisted by Telegraf
+	if w.BookmarkFile != "" {
+		if err := w.loadBookmark(); err != nil {
+			return err
+		}
+	}
+
 	subscription, err := w.evtSubscribe()
 	if err != nil {
 		return fmt.Errorf("subscription of Windows Event Log failed: %w", err)
@@ -114,6 +169,9 @@ func (w *WinEventLog) Start(telegraf.Accumulator) error {
 }
 
 func (w *WinEventLog) GetState() interface{} {
+	w.bookmarkLock.Lock()
+	defer w.bookmarkLock.Unlock()
+
 	bookmarkXML, err := w.renderBookmark()
 	if err != nil {
 		w.Log.Errorf("State-persistence failed, cannot render bookmark: %v", err)
@@ -137,6 +195,10 @@ func (w *WinEventLog) SetState(state interface{}) error {
 	if err != nil {
 		return fmt.Errorf("creating bookmark failed: %w", err)
 	}
+	if w.bookmark != 0 {
+		//nolint:errcheck // replacing the bookmark, error can be ignored
+		_ = evtClose(w.bookmark)
+	}
 	w.bookmark = bookmark
 	w.subscriptionFlag = evtSubscribeStartAfterBookmark
 
@@ -144,147 +206,229 @@ func (w *WinEventLog) SetState(state interface{}) error {
 }
 
 func (w *WinEventLog) Gather(acc telegraf.Accumulator) error {
-	for {
-		events, err := w.fetchEvents(w.subscription)
-		if err != nil {
-			if errors.Is(err, errNoMoreItems) {
-				break
+	// Events are delivered via the subscription callback in push mode
+	if w.SubscriptionMode == "pull" {
+		for {
+			events, err := w.fetchEvents(w.subscription)
+			if err != nil {
+				if errors.Is(err, errNoMoreItems) {
+					break
+				}
+				w.Log.Errorf("Error getting events: %v", err)
+				return err
+			}
+
+			for i := range events {
+				w.addEvent(acc, events[i])
 			}
-			w.Log.Errorf("Error getting events: %v", err)
-			return err
 		}
+	}
 
-		for i := range events {
-			// Prepare fields names usage counter
-			fieldsUsage := make(map[string]int)
-
-			tags := make(map[string]string)
-			fields := make(map[string]interface{})
-			event := events[i]
-			evt := reflect.ValueOf(&event).Elem()
-			timeStamp := time.Now()
-			// Walk through all fields of event struct to process System tags or fields
-			for i := 0; i < evt.NumField(); i++ {
-				fieldName := evt.Type().Field(i).Name
-				fieldType := evt.Field(i).Type().String()
-				fieldValue := evt.Field(i).Interface()
-				computedValues := make(map[string]interface{})
-				switch fieldName {
-				case "Source":
-					fieldValue = event.Source.Name
-					fieldType = reflect.TypeOf(fieldValue).String()
-				case "Execution":
-					fieldValue := event.Execution.ProcessID
-					fieldType = reflect.TypeOf(fieldValue).String()
-					fieldName = "ProcessID"
-					// Look up Process Name from pid
-					if should, _ := w.shouldProcessField("ProcessName"); should {
-						processName, err := getFromSnapProcess(fieldValue)
-						if err == nil {
-							computedValues["ProcessName"] = processName
-						}
-					}
-				case "TimeCreated":
-					fieldValue = event.TimeCreated.SystemTime
-					fieldType = reflect.TypeOf(fieldValue).String()
-					if w.TimeStampFromEvent {
-						timeStamp, err = time.Parse(time.RFC3339Nano, fmt.Sprintf("%v", fieldValue))
-						if err != nil {
-							w.Log.Warnf("Error parsing timestamp %q: %v", fieldValue, err)
-						}
-					}
-				case "Correlation":
-					if should, _ := w.shouldProcessField("ActivityID"); should {
-						activityID := event.Correlation.ActivityID
-						if len(activityID) > 0 {
-							computedValues["ActivityID"] = activityID
-						}
-					}
-					if should, _ := w.shouldProcessField("RelatedActivityID"); should {
-						relatedActivityID := event.Correlation.RelatedActivityID
-						if len(relatedActivityID) > 0 {
-							computedValues["RelatedActivityID"] = relatedActivityID
-						}
-					}
-				case "Security":
-					computedValues["UserID"] = event.Security.UserID
-					// Look up UserName and Domain from SID
-					if should, _ := w.shouldProcessField("UserName"); should {
-						sid := event.Security.UserID
-						usid, err := syscall.StringToSid(sid)
-						if err == nil {
-							username, domain, _, err := usid.LookupAccount("")
-							if err == nil {
-								computedValues["UserName"] = fmt.Sprint(domain, "\\", username)
-							}
-						}
-					}
+	if w.BookmarkFile != "" {
+		if err := w.saveBookmark(); err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	return nil
+}
+
+func (w *WinEventLog) addEvent(acc telegraf.Accumulator, event event) {
+	// Prepare fields names usage counter
+	fieldsUsage := make(map[string]int)
+
+	tags := make(map[string]string)
+	fields := make(map[string]interface{})
+	evt := reflect.ValueOf(&event).Elem()
+	timeStamp := time.Now()
+	// Walk through all fields of event struct to process System tags or fields
+	for i := 0; i < evt.NumField(); i++ {
+		fieldName := evt.Type().Field(i).Name
+		fieldType := evt.Field(i).Type().String()
+		fieldValue := evt.Field(i).Interface()
+		computedValues := make(map[string]interface{})
+		switch fieldName {
+		case "Source":
+			fieldValue = event.Source.Name
+			fieldType = reflect.TypeOf(fieldValue).String()
+		case "Execution":
+			fieldValue := event.Execution.ProcessID
+			fieldType = reflect.TypeOf(fieldValue).String()
+			fieldName = "ProcessID"
+			// Look up Process Name from pid
+			if should, _ := w.shouldProcessField("ProcessName"); should {
+				processName, err := getFromSnapProcess(fieldValue)
+				if err == nil {
+					computedValues["ProcessName"] = processName
 				}
-				if should, where := w.shouldProcessField(fieldName); should {
-					if where == "tags" {
-						strValue := fmt.Sprintf("%v", fieldValue)
-						if !w.shouldExcludeEmptyField(fieldName, "string", strValue) {
-							tags[fieldName] = strValue
-							fieldsUsage[fieldName]++
-						}
-					} else if where == "fields" {
-						if !w.shouldExcludeEmptyField(fieldName, fieldType, fieldValue) {
-							fields[fieldName] = fieldValue
-							fieldsUsage[fieldName]++
-						}
-					}
+			}
+		case "TimeCreated":
+			fieldValue = event.TimeCreated.SystemTime
+			fieldType = reflect.TypeOf(fieldValue).String()
+			if w.TimeStampFromEvent {
+				var err error
+				timeStamp, err = time.Parse(time.RFC3339Nano, fmt.Sprintf("%v", fieldValue))
+				if err != nil {
+					w.Log.Warnf("Error parsing timestamp %q: %v", fieldValue, err)
 				}
-
-				// Insert computed fields
-				for computedKey, computedValue := range computedValues {
-					if should, where := w.shouldProcessField(computedKey); should {
-						if where == "tags" {
-							tags[computedKey] = fmt.Sprintf("%v", computedValue)
-							fieldsUsage[computedKey]++
-						} else if where == "fields" {
-							fields[computedKey] = computedValue
-							fieldsUsage[computedKey]++
-						}
-					}
+			}
+		case "Correlation":
+			if should, _ := w.shouldProcessField("ActivityID"); should {
+				activityID := event.Correlation.ActivityID
+				if len(activityID) > 0 {
+					computedValues["ActivityID"] = activityID
 				}
 			}
-
-			// Unroll additional XML
-			var xmlFields []eventField
-			if w.ProcessUserData {
-				fieldsUserData, xmlFieldsUsage := unrollXMLFields(event.UserData.InnerXML, fieldsUsage, w.Separator)
-				xmlFields = append(xmlFields, fieldsUserData...)
-				fieldsUsage = xmlFieldsUsage
+			if should, _ := w.shouldProcessField("RelatedActivityID"); should {
+				relatedActivityID := event.Correlation.RelatedActivityID
+				if len(relatedActivityID) > 0 {
+					computedValues["RelatedActivityID"] = relatedActivityID
+				}
 			}
-			if w.ProcessEventData {
-				fieldsEventData, xmlFieldsUsage := unrollXMLFields(event.EventData.InnerXML, fieldsUsage, w.Separator)
-				xmlFields = append(xmlFields, fieldsEventData...)
-				fieldsUsage = xmlFieldsUsage
+		case "Security":
+			computedValues["UserID"] = event.Security.UserID
+			// Look up UserName and Domain from SID
+			if should, _ := w.shouldProcessField("UserName"); should {
+				sid := event.Security.UserID
+				usid, err := syscall.StringToSid(sid)
+				if err == nil {
+					username, domain, _, err := usid.LookupAccount("")
+					if err == nil {
+						computedValues["UserName"] = fmt.Sprint(domain, "\\", username)
+					}
+				}
 			}
-			uniqueXMLFields := uniqueFieldNames(xmlFields, fieldsUsage, w.Separator)
-			for _, xmlField := range uniqueXMLFields {
-				should, where := w.shouldProcessField(xmlField.Name)
-				if !should {
-					continue
+		}
+		if should, where := w.shouldProcessField(fieldName); should {
+			if where == "tags" {
+				strValue := fmt.Sprintf("%v", fieldValue)
+				if !w.shouldExcludeEmptyField(fieldName, "string", strValue) {
+					tags[fieldName] = strValue
+					fieldsUsage[fieldName]++
+				}
+			} else if where == "fields" {
+				if !w.shouldExcludeEmptyField(fieldName, fieldType, fieldValue) {
+					fields[fieldName] = fieldValue
+					fieldsUsage[fieldName]++
 				}
+			}
+		}
+
+		// Insert computed fields
+		for computedKey, computedValue := range computedValues {
+			if should, where := w.shouldProcessField(computedKey); should {
 				if where == "tags" {
-					tags[xmlField.Name] = xmlField.Value
-				} else {
-					fields[xmlField.Name] = xmlField.Value
+					tags[computedKey] = fmt.Sprintf("%v", computedValue)
+					fieldsUsage[computedKey]++
+				} else if where == "fields" {
+					fields[computedKey] = computedValue
+					fieldsUsage[computedKey]++
 				}
 			}
+		}
+	}
 
-			// Pass collected metrics
-			acc.AddFields("win_eventlog", fields, tags, timeStamp)
+	// Unroll additional XML
+	var xmlFields []eventField
+	if w.ProcessUserData {
+		fieldsUserData, xmlFieldsUsage := unrollXMLFields(event.UserData.InnerXML, fieldsUsage, w.Separator)
+		xmlFields = append(xmlFields, fieldsUserData...)
+		fieldsUsage = xmlFieldsUsage
+	}
+	if w.ProcessEventData {
+		fieldsEventData, xmlFieldsUsage := unrollXMLFields(event.EventData.InnerXML, fieldsUsage, w.Separator)
+		xmlFields = append(xmlFields, fieldsEventData...)
+		fieldsUsage = xmlFieldsUsage
+	}
+	uniqueXMLFields := uniqueFieldNames(xmlFields, fieldsUsage, w.Separator)
+	for _, xmlField := range uniqueXMLFields {
+		should, where := w.shouldProcessField(xmlField.Name)
+		if !should {
+			continue
+		}
+		if where == "tags" {
+			tags[xmlField.Name] = xmlField.Value
+		} else {
+			fields[xmlField.Name] = xmlField.Value
 		}
 	}
 
-	return nil
+	// Pass collected metrics
+	acc.AddFields("win_eventlog", fields, tags, timeStamp)
 }
 
 func (w *WinEventLog) Stop() {
 	//nolint:errcheck // ending the subscription, error can be ignored
 	_ = evtClose(w.subscription)
+
+	if w.BookmarkFile != "" {
+		if err := w.saveBookmark(); err != nil {
+			w.Log.Errorf("Persisting bookmark failed: %v", err)
+		}
+	}
+
+	for name, handle := range w.publishers {
+		//nolint:errcheck // releasing the metadata, error can be ignored
+		_ = evtClose(handle)
+		delete(w.publishers, name)
+	}
+}
+
+// loadBookmark creates the bookmark from the bookmark file if it exists
+func (w *WinEventLog) loadBookmark() error {
+	buf, err := os.ReadFile(w.BookmarkFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("reading bookmark file failed: %w", err)
+	}
+	bookmarkXML := strings.TrimSpace(string(buf))
+	if bookmarkXML == "" {
+		return nil
+	}
+
+	if err := w.SetState(bookmarkXML); err != nil {
+		return fmt.Errorf("loading bookmark file %q failed: %w", w.BookmarkFile, err)
+	}
+	w.savedBookmark = bookmarkXML
+	w.Log.Debugf("Continuing after bookmark from %q", w.BookmarkFile)
+
+	return nil
+}
+
+// saveBookmark atomically writes the current bookmark to the bookmark file if
+// it changed since the last call
+func (w *WinEventLog) saveBookmark() error {
+	w.bookmarkLock.Lock()
+	bookmarkXML, err := w.renderBookmark()
+	w.bookmarkLock.Unlock()
+	if err != nil {
+		return fmt.Errorf("rendering bookmark failed: %w", err)
+	}
+	if bookmarkXML == w.savedBookmark {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(w.BookmarkFile), filepath.Base(w.BookmarkFile)+".*")
+	if err != nil {
+		return fmt.Errorf("creating bookmark file failed: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(bookmarkXML); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing bookmark file failed: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing bookmark file failed: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), w.BookmarkFile); err != nil {
+		return fmt.Errorf("replacing bookmark file failed: %w", err)
+	}
+	w.savedBookmark = bookmarkXML
+
+	return nil
 }
 
 func (w *WinEventLog) shouldProcessField(field string) (should bool, list string) {
@@ -317,11 +461,22 @@ func (w *WinEventLog) shouldExcludeEmptyField(field, fieldType string, fieldValu
 }
 
 func (w *WinEventLog) evtSubscribe() (evtHandle, error) {
-	sigEvent, err := windows.CreateEvent(nil, 0, 0, nil)
-	if err != nil {
-		return 0, err
+	var sigEvent windows.Handle
+	var callback uintptr
+	if w.SubscriptionMode == "push" {
+		// The callback cannot be released, so only create it once per instance
+		if w.callback == 0 {
+			w.callback = windows.NewCallback(w.onEvent)
+		}
+		callback = w.callback
+	} else {
+		var err error
+		sigEvent, err = windows.CreateEvent(nil, 0, 0, nil)
+		if err != nil {
+			return 0, err
+		}
+		defer windows.CloseHandle(sigEvent)
 	}
-	defer windows.CloseHandle(sigEvent)
 
 	logNamePtr, err := syscall.UTF16PtrFromString(w.EventlogName)
 	if err != nil {
@@ -337,7 +492,7 @@ func (w *WinEventLog) evtSubscribe() (evtHandle, error) {
 	if w.subscriptionFlag == evtSubscribeStartAfterBookmark {
 		bookmark = w.bookmark
 	}
-	subsHandle, err := evtSubscribe(0, uintptr(sigEvent), logNamePtr, xqueryPtr, bookmark, 0, 0, w.subscriptionFlag)
+	subsHandle, err := evtSubscribe(0, uintptr(sigEvent), logNamePtr, xqueryPtr, bookmark, 0, syscall.Handle(callback), w.subscriptionFlag)
 	if err != nil {
 		return 0, err
 	}
@@ -345,6 +500,36 @@ func (w *WinEventLog) evtSubscribe() (evtHandle, error) {
 	return subsHandle, nil
 }
 
+// onEvent is called by the system for each event delivered to the push
+// subscription. The event handle is owned by the system and must not be closed.
+func (w *WinEventLog) onEvent(action, _, eventHandle uintptr) uintptr {
+	switch evtSubscribeNotifyAction(action) {
+	case evtSubscribeActionError:
+		// The handle carries the error code in this case
+		errno := syscall.Errno(eventHandle)
+		if errno == errEvtQueryResultStale {
+			w.Log.Error("Events were lost as the event log was overwritten before they were delivered")
+		} else {
+			w.Log.Errorf("Subscription callback received error: %v", errno)
+		}
+	case evtSubscribeActionDeliver:
+		event, err := w.renderEvent(evtHandle(eventHandle))
+		if err != nil {
+			w.Log.Errorf("Rendering event failed: %v", err)
+		} else {
+			w.addEvent(w.acc, event)
+		}
+
+		w.bookmarkLock.Lock()
+		err = evtUpdateBookmark(w.bookmark, evtHandle(eventHandle))
+		w.bookmarkLock.Unlock()
+		if err != nil {
+			w.Log.Errorf("Updating bookmark failed: %v", err)
+		}
+	}
+	return 0
+}
+
 func (w *WinEventLog) fetchEventHandles(subsHandle evtHandle) ([]evtHandle, error) {
 	var evtReturned uint32
 
@@ -378,8 +563,11 @@ func (w *WinEventLog) fetchEvents(subsHandle evtHandle) ([]event, error) {
 			events = append(events, event)
 		}
 
-		if err := evtUpdateBookmark(w.bookmark, eventHandle); err != nil {
-			w.Log.Errorf("Updateing bookmark failed: %v", err)
+		w.bookmarkLock.Lock()
+		err := evtUpdateBookmark(w.bookmark, eventHandle)
+		w.bookmarkLock.Unlock()
+		if err != nil {
+			w.Log.Errorf("Updating bookmark failed: %v", err)
 			if evterr == nil {
 				evterr = err
 			}
@@ -470,11 +658,10 @@ func (w *WinEventLog) renderEvent(eventHandle evtHandle) (event, error) {
 }
 
 func (w *WinEventLog) renderLocalMessage(event event, eventHandle evtHandle) (event, error) {
-	publisherHandle, err := openPublisherMetadata(0, event.Source.Name, w.Locale)
+	publisherHandle, err := w.publisherMetadata(event.Source.Name)
 	if err != nil {
 		return event, nil
 	}
-	defer evtClose(publisherHandle) //nolint:errcheck // Ignore error returned during Close
 
 	// Populating text values
 	keywords, err := formatEventString(evtFormatMessageKeyword, eventHandle, publisherHandle)
@@ -573,6 +760,27 @@ func formatEventString(messageFlag evtFormatMessageFlag, eventHandle, publisherH
 	return out, nil
 }
 
+// publisherMetadata returns the cached metadata handle of the given publisher
+// using the configured locale. If the publisher does not provide messages in
+// that locale, the default Windows locale is used instead.
+func (w *WinEventLog) publisherMetadata(name string) (evtHandle, error) {
+	if handle, found := w.publishers[name]; found {
+		return handle, nil
+	}
+
+	handle, err := openPublisherMetadata(0, name, w.Locale)
+	if err != nil && w.Locale != 0 {
+		w.Log.Debugf("Opening metadata of publisher %q for locale %d failed, using default locale: %v", name, w.Locale, err)
+		handle, err = openPublisherMetadata(0, name, 0)
+	}
+	if err != nil {
+		return 0, err
+	}
+	w.publishers[name] = handle
+
+	return handle, nil
+}
+
 // openPublisherMetadata opens a handle to the publisher's metadata. Close must
 // be called on returned evtHandle when finished with the handle.
 func openPublisherMetadata(session evtHandle, publisherName string, lang uint32) (evtHandle, error) {
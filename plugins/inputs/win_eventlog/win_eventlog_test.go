@@ -131,3 +131,43 @@ func TestWinEventLog_shouldProcessField(t *testing.T) {
 		})
 	}
 }
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *WinEventLog
+		expected string
+	}{
+		{
+			name:     "invalid subscription mode",
+			plugin:   &WinEventLog{SubscriptionMode: "poll"},
+			expected: `invalid subscription_mode "poll"`,
+		},
+		{
+			name: "query and filter",
+			plugin: &WinEventLog{
+				Query:   "Event/System[EventID=999]",
+				Filters: []eventFilter{{Channel: "System"}},
+			},
+			expected: "'xpath_query' and 'filter' are mutually exclusive",
+		},
+		{
+			name: "eventlog name and filter",
+			plugin: &WinEventLog{
+				EventlogName: "System",
+				Filters:      []eventFilter{{Channel: "System"}},
+			},
+			expected: "'eventlog_name' cannot be used together with 'filter'",
+		},
+		{
+			name:     "filter without channel",
+			plugin:   &WinEventLog{Filters: []eventFilter{{Select: []string{"*"}}}},
+			expected: "missing channel",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
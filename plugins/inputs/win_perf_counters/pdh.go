@@ -270,6 +270,7 @@ var (
 	pdhGetCounterInfoWProc           *syscall.Proc
 	pdhGetRawCounterValueProc        *syscall.Proc
 	pdhGetRawCounterArrayWProc       *syscall.Proc
+	pdhRemoveCounterProc             *syscall.Proc
 )
 
 func init() {
@@ -289,6 +290,7 @@ func init() {
 	pdhGetCounterInfoWProc = libPdhDll.MustFindProc("PdhGetCounterInfoW")
 	pdhGetRawCounterValueProc = libPdhDll.MustFindProc("PdhGetRawCounterValue")
 	pdhGetRawCounterArrayWProc = libPdhDll.MustFindProc("PdhGetRawCounterArrayW")
+	pdhRemoveCounterProc = libPdhDll.MustFindProc("PdhRemoveCounter")
 }
 
 // pdhAddCounter adds the specified counter to the query. This is the internationalized version. Preferably, use the
@@ -371,6 +373,13 @@ func pdhCloseQuery(hQuery pdhQueryHandle) uint32 {
 	return uint32(ret)
 }
 
+// pdhRemoveCounter removes a counter from a query. The counter handle is invalid afterwards.
+func pdhRemoveCounter(hCounter pdhCounterHandle) uint32 {
+	ret, _, _ := pdhRemoveCounterProc.Call(uintptr(hCounter))
+
+	return uint32(ret)
+}
+
 // pdhCollectQueryData collects the current raw data value for all counters in the specified query and updates the status
 // code of each counter. With some counters, this function needs to be repeatedly called before the value
 // of the counter can be extracted with PdhGetFormattedCounterValue(). For example, the following code
@@ -29,6 +29,7 @@ type performanceQuery interface {
 	close() error
 	addCounterToQuery(counterPath string) (pdhCounterHandle, error)
 	addEnglishCounterToQuery(counterPath string) (pdhCounterHandle, error)
+	removeCounterFromQuery(counterHandle pdhCounterHandle) error
 	getCounterPath(counterHandle pdhCounterHandle) (string, error)
 	expandWildCardPath(counterPath string) ([]string, error)
 	getFormattedCounterValueDouble(hCounter pdhCounterHandle) (float64, error)
@@ -127,6 +128,16 @@ func (m *performanceQueryImpl) addEnglishCounterToQuery(counterPath string) (pdh
 	return counterHandle, nil
 }
 
+func (m *performanceQueryImpl) removeCounterFromQuery(counterHandle pdhCounterHandle) error {
+	if m.query == 0 {
+		return errors.New("uninitialized query")
+	}
+	if ret := pdhRemoveCounter(counterHandle); ret != errorSuccess {
+		return newPdhError(ret)
+	}
+	return nil
+}
+
 // getCounterPath returns counter information for given handle
 func (m *performanceQueryImpl) getCounterPath(counterHandle pdhCounterHandle) (string, error) {
 	for buflen := initialBufferSize; buflen <= m.maxBufferSize; buflen *= 2 {
@@ -34,6 +34,7 @@ type WinPerfCounters struct {
 	UsePerfCounterTime         bool            `toml:"UsePerfCounterTime"`
 	Object                     []perfObject    `toml:"object"`
 	CountersRefreshInterval    config.Duration `toml:"CountersRefreshInterval"`
+	InstancesRefreshInterval   config.Duration `toml:"InstancesRefreshInterval"`
 	UseWildcardsExpansion      bool            `toml:"UseWildcardsExpansion"`
 	LocalizeWildcardsExpansion bool            `toml:"LocalizeWildcardsExpansion"`
 	IgnoredErrors              []string        `toml:"IgnoredErrors"`
@@ -42,9 +43,10 @@ type WinPerfCounters struct {
 
 	Log telegraf.Logger `toml:"-"`
 
-	lastRefreshed time.Time
-	queryCreator  performanceQueryCreator
-	hostCounters  map[string]*hostCountersInfo
+	lastRefreshed          time.Time
+	lastInstancesRefreshed time.Time
+	queryCreator           performanceQueryCreator
+	hostCounters           map[string]*hostCountersInfo
 	// cached os.Hostname()
 	cachedHostname string
 }
@@ -67,10 +69,27 @@ type hostCountersInfo struct {
 	// computer name used in tag
 	tag       string
 	counters  []*counter
+	wildcards []*wildcardItem
 	query     performanceQuery
 	timestamp time.Time
 }
 
+// wildcardItem is a configured counter path containing wildcards which is
+// re-expanded at runtime to pick up new instances
+type wildcardItem struct {
+	// counter path used for expansion as returned by the query
+	counterPath string
+	// object and counter names as configured
+	objectName   string
+	counterName  string
+	instance     string
+	measurement  string
+	includeTotal bool
+	useRawValue  bool
+	// paths of the counters resulting from the last expansion
+	paths map[string]bool
+}
+
 type counter struct {
 	counterPath   string
 	computer      string
@@ -130,6 +149,10 @@ func (m *WinPerfCounters) Init() error {
 			return errors.New("wildcards can't be used with LocalizeWildcardsExpansion=false")
 		}
 	}
+
+	if m.InstancesRefreshInterval > 0 && !m.UseWildcardsExpansion {
+		m.Log.Warn("'InstancesRefreshInterval' has no effect without 'UseWildcardsExpansion'")
+	}
 	return nil
 }
 
@@ -152,8 +175,13 @@ func (m *WinPerfCounters) Gather(acc telegraf.Accumulator) error {
 			}
 		}
 		m.lastRefreshed = time.Now()
+		m.lastInstancesRefreshed = m.lastRefreshed
 		// minimum time between collecting two samples
 		time.Sleep(time.Second)
+	} else if m.UseWildcardsExpansion && m.InstancesRefreshInterval > 0 &&
+		m.lastInstancesRefreshed.Add(time.Duration(m.InstancesRefreshInterval)).Before(time.Now()) {
+		m.refreshInstances()
+		m.lastInstancesRefreshed = time.Now()
 	}
 
 	for _, hostCounterSet := range m.hostCounters {
@@ -327,90 +355,103 @@ func (m *WinPerfCounters) addItem(counterPath, computer, objectName, instance, c
 	}
 
 	if m.UseWildcardsExpansion {
-		origInstance := instance
 		counterPath, err = hostCounter.query.getCounterPath(counterHandle)
 		if err != nil {
 			return err
 		}
-		counters, err := hostCounter.query.expandWildCardPath(counterPath)
+
+		_, origObjectName, _, origCounterName, err := extractCounterInfoFromCounterPath(origCounterPath)
 		if err != nil {
 			return err
 		}
 
-		_, origObjectName, _, origCounterName, err := extractCounterInfoFromCounterPath(origCounterPath)
+		item := &wildcardItem{
+			counterPath:  counterPath,
+			objectName:   origObjectName,
+			counterName:  origCounterName,
+			instance:     instance,
+			measurement:  measurement,
+			includeTotal: includeTotal,
+			useRawValue:  useRawValue,
+		}
+		item.paths, err = m.expandItem(hostCounter, item, nil)
 		if err != nil {
 			return err
 		}
+		if strings.ContainsAny(origCounterPath, "*?") {
+			hostCounter.wildcards = append(hostCounter.wildcards, item)
+		}
+		return nil
+	}
 
-		for _, counterPath := range counters {
-			_, err := hostCounter.query.addCounterToQuery(counterPath)
-			if err != nil {
-				return err
-			}
+	newItem := newCounter(
+		counterHandle,
+		counterPath,
+		computer,
+		objectName,
+		instance,
+		counterName,
+		measurement,
+		includeTotal,
+		useRawValue,
+	)
+	hostCounter.counters = append(hostCounter.counters, newItem)
+	if m.PrintValid {
+		m.Log.Infof("Valid: %s", counterPath)
+	}
 
-			computer, objectName, instance, counterName, err = extractCounterInfoFromCounterPath(counterPath)
-			if err != nil {
-				return err
-			}
+	return nil
+}
 
-			var newItem *counter
-			if !m.LocalizeWildcardsExpansion {
-				// On localized installations of Windows, Telegraf
-				// should return English metrics, but
-				// expandWildCardPath returns localized counters. Undo
-				// that by using the original object and counter
-				// names, along with the expanded instance.
-
-				var newInstance string
-				if instance == "" {
-					newInstance = emptyInstance
-				} else {
-					newInstance = instance
-				}
-				counterPath = formatPath(computer, origObjectName, newInstance, origCounterName)
-				counterHandle, err = hostCounter.query.addEnglishCounterToQuery(counterPath)
-				if err != nil {
-					return err
-				}
-				newItem = newCounter(
-					counterHandle,
-					counterPath,
-					computer,
-					origObjectName, instance,
-					origCounterName,
-					measurement,
-					includeTotal,
-					useRawValue,
-				)
-			} else {
-				counterHandle, err = hostCounter.query.addCounterToQuery(counterPath)
-				if err != nil {
-					return err
-				}
-				newItem = newCounter(
-					counterHandle,
-					counterPath,
-					computer,
-					objectName,
-					instance,
-					counterName,
-					measurement,
-					includeTotal,
-					useRawValue,
-				)
-			}
+// expandItem expands the counter path of the given item and adds all resulting
+// counters not contained in known to the host's query. The paths of all
+// expanded counters are returned.
+func (m *WinPerfCounters) expandItem(hostCounter *hostCountersInfo, item *wildcardItem, known map[string]bool) (map[string]bool, error) {
+	counters, err := hostCounter.query.expandWildCardPath(item.counterPath)
+	if err != nil {
+		return nil, err
+	}
 
-			if instance == "_Total" && origInstance == "*" && !includeTotal {
-				continue
-			}
+	expanded := make(map[string]bool, len(counters))
+	for _, counterPath := range counters {
+		computer, objectName, instance, counterName, err := extractCounterInfoFromCounterPath(counterPath)
+		if err != nil {
+			return nil, err
+		}
 
-			hostCounter.counters = append(hostCounter.counters, newItem)
+		if instance == "_Total" && item.instance == "*" && !item.includeTotal {
+			continue
+		}
 
-			if m.PrintValid {
-				m.Log.Infof("Valid: %s", counterPath)
+		if !m.LocalizeWildcardsExpansion {
+			// On localized installations of Windows, Telegraf
+			// should return English metrics, but
+			// expandWildCardPath returns localized counters. Undo
+			// that by using the original object and counter
+			// names, along with the expanded instance.
+			newInstance := instance
+			if instance == "" {
+				newInstance = emptyInstance
 			}
+			objectName = item.objectName
+			counterName = item.counterName
+			counterPath = formatPath(computer, objectName, newInstance, counterName)
+		}
+
+		expanded[counterPath] = true
+		if known[counterPath] {
+			continue
+		}
+
+		var counterHandle pdhCounterHandle
+		if !m.LocalizeWildcardsExpansion {
+			counterHandle, err = hostCounter.query.addEnglishCounterToQuery(counterPath)
+		} else {
+			counterHandle, err = hostCounter.query.addCounterToQuery(counterPath)
+		}
+		if err != nil {
+			return nil, err
 		}
-	} else {
 		newItem := newCounter(
 			counterHandle,
 			counterPath,
@@ -418,17 +459,73 @@ func (m *WinPerfCounters) addItem(counterPath, computer, objectName, instance, c
 			objectName,
 			instance,
 			counterName,
-			measurement,
-			includeTotal,
-			useRawValue,
+			item.measurement,
+			item.includeTotal,
+			item.useRawValue,
 		)
 		hostCounter.counters = append(hostCounter.counters, newItem)
+		if known != nil {
+			known[counterPath] = true
+		}
+
 		if m.PrintValid {
 			m.Log.Infof("Valid: %s", counterPath)
 		}
 	}
 
-	return nil
+	return expanded, nil
+}
+
+// refreshInstances re-expands the wildcard counters of all hosts, adding the
+// counters of new instances to the queries and removing the counters of
+// vanished instances.
+func (m *WinPerfCounters) refreshInstances() {
+	for _, hostCounter := range m.hostCounters {
+		if len(hostCounter.wildcards) == 0 {
+			continue
+		}
+
+		known := make(map[string]bool, len(hostCounter.counters))
+		for _, c := range hostCounter.counters {
+			known[c.counterPath] = true
+		}
+
+		current := make(map[string]bool, len(known))
+		vanished := make(map[string]bool)
+		for _, item := range hostCounter.wildcards {
+			paths, err := m.expandItem(hostCounter, item, known)
+			if err != nil {
+				m.Log.Warnf("Refreshing instances of %q on %q failed: %v", item.counterPath, hostCounter.computer, err)
+				paths = item.paths
+			}
+			for path := range item.paths {
+				if !paths[path] {
+					vanished[path] = true
+				}
+			}
+			for path := range paths {
+				current[path] = true
+			}
+			item.paths = paths
+		}
+
+		// Counters might be expanded from multiple configured paths so only
+		// remove those not contained in any of the expansions
+		counters := make([]*counter, 0, len(hostCounter.counters))
+		for _, c := range hostCounter.counters {
+			if !vanished[c.counterPath] || current[c.counterPath] {
+				counters = append(counters, c)
+				continue
+			}
+			if err := hostCounter.query.removeCounterFromQuery(c.counterHandle); err != nil {
+				m.Log.Warnf("Removing counter %q failed: %v", c.counterPath, err)
+			}
+			if m.PrintValid {
+				m.Log.Infof("Removed: %s", c.counterPath)
+			}
+		}
+		hostCounter.counters = counters
+	}
 }
 
 func formatPath(computer, objectName, instance, counter string) string {
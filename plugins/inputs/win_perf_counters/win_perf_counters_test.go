@@ -27,6 +27,7 @@ type fakePerformanceQuery struct {
 	vistaAndNewer bool
 	expandPaths   map[string][]string
 	openCalled    bool
+	removed       []string
 }
 
 var metricTime = time.Date(2018, 5, 28, 12, 0, 0, 0, time.UTC)
@@ -86,6 +87,19 @@ func (m *fakePerformanceQuery) addEnglishCounterToQuery(counterPath string) (pdh
 	return 0, fmt.Errorf("in addEnglishCounterToQuery: invalid counter path: %q", counterPath)
 }
 
+func (m *fakePerformanceQuery) removeCounterFromQuery(counterHandle pdhCounterHandle) error {
+	if !m.openCalled {
+		return errors.New("in removeCounterFromQuery: uninitialized query")
+	}
+	for _, counter := range m.counters {
+		if counter.handle == counterHandle {
+			m.removed = append(m.removed, counter.path)
+			return nil
+		}
+	}
+	return fmt.Errorf("in removeCounterFromQuery: invalid handle: %q", counterHandle)
+}
+
 func (m *fakePerformanceQuery) getCounterPath(counterHandle pdhCounterHandle) (string, error) {
 	for _, counter := range m.counters {
 		if counter.handle == counterHandle {
@@ -1540,6 +1554,58 @@ func TestGatherRefreshingWithExpansion(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestGatherRefreshingInstances(t *testing.T) {
+	measurement := "test"
+	perfObjects := createPerfObject("", measurement, "O", []string{"*"}, []string{"C1"}, true, false, false)
+	cps1 := []string{"\\O(I1)\\C1", "\\O(I2)\\C1"}
+	fpm := &fakePerformanceQuery{
+		counters: createCounterMap(append(cps1, "\\O(*)\\C1"), []float64{1.1, 1.2, 0}, []uint32{0, 0, 0}),
+		expandPaths: map[string][]string{
+			"\\O(*)\\C1": cps1,
+		},
+		vistaAndNewer: true,
+	}
+	m := WinPerfCounters{
+		Log:                   testutil.Logger{},
+		Object:                perfObjects,
+		UseWildcardsExpansion: true,
+		queryCreator: &fakePerformanceQueryCreator{
+			fakeQueries: map[string]*fakePerformanceQuery{"localhost": fpm},
+		},
+		InstancesRefreshInterval:   config.Duration(time.Nanosecond),
+		LocalizeWildcardsExpansion: true,
+	}
+	var acc1 testutil.Accumulator
+	require.NoError(t, m.Gather(&acc1))
+	require.Len(t, acc1.Metrics, 2)
+
+	// Instance I3 appears and I2 vanishes without restarting the query
+	cps2 := []string{"\\O(I1)\\C1", "\\O(I3)\\C1"}
+	fpm.counters["\\O(I3)\\C1"] = testCounter{pdhCounterHandle(3), "\\O(I3)\\C1", 1.3, 0}
+	fpm.expandPaths["\\O(*)\\C1"] = cps2
+
+	time.Sleep(time.Millisecond)
+	var acc2 testutil.Accumulator
+	require.NoError(t, m.Gather(&acc2))
+	require.True(t, fpm.openCalled)
+	require.Equal(t, []string{"\\O(I2)\\C1"}, fpm.removed)
+
+	counters, ok := m.hostCounters["localhost"]
+	require.True(t, ok)
+	require.Len(t, counters.counters, 2)
+	require.Len(t, acc2.Metrics, 2)
+
+	tags := map[string]string{
+		"instance":   "I1",
+		"objectname": "O",
+		"source":     hostname(),
+	}
+	acc2.AssertContainsTaggedFields(t, measurement, map[string]interface{}{"C1": 1.1}, tags)
+	tags["instance"] = "I3"
+	acc2.AssertContainsTaggedFields(t, measurement, map[string]interface{}{"C1": 1.3}, tags)
+	require.NoError(t, m.cleanQueries())
+}
+
 func TestGatherRefreshingWithoutExpansion(t *testing.T) {
 	var err error
 	if testing.Short() {
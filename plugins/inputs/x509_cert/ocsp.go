@@ -0,0 +1,104 @@
+package x509_cert
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// Maximum size of an OCSP response accepted from a responder
+const maxOCSPResponseSize = 1024 * 1024
+
+// addOCSP adds the OCSP status of the leaf certificate from the stapled OCSP
+// response. If no response is stapled, the OCSP responder of the certificate
+// is queried if enabled.
+func (c *X509Cert) addOCSP(cert *x509.Certificate, chain []*x509.Certificate, stapled *[]byte, tags map[string]string, fields map[string]interface{}) {
+	var ocspissuer *x509.Certificate
+	for _, chaincert := range chain {
+		if cert.Issuer.CommonName == chaincert.Subject.CommonName &&
+			cert.Issuer.SerialNumber == chaincert.Subject.SerialNumber {
+			ocspissuer = chaincert
+			break
+		}
+	}
+
+	tags["ocsp_stapled"] = "no"
+	var raw []byte
+	if stapled != nil && len(*stapled) > 0 {
+		raw = *stapled
+	} else {
+		if !c.QueryOCSPResponder || len(cert.OCSPServer) == 0 {
+			return
+		}
+		var err error
+		if raw, err = c.queryOCSPResponder(cert, ocspissuer); err != nil {
+			fields["ocsp_error"] = err.Error()
+			return
+		}
+	}
+
+	resp, err := ocsp.ParseResponse(raw, ocspissuer)
+	if err != nil && ocspissuer != nil {
+		ocspissuer = nil // retry parsing w/out issuer cert
+		resp, err = ocsp.ParseResponse(raw, ocspissuer)
+	}
+	if err != nil {
+		fields["ocsp_error"] = err.Error()
+		return
+	}
+
+	if stapled != nil && len(*stapled) > 0 {
+		tags["ocsp_stapled"] = "yes"
+	}
+	if ocspissuer != nil {
+		tags["ocsp_verified"] = "yes"
+	} else {
+		tags["ocsp_verified"] = "no"
+	}
+	// resp.Status: 0=Good 1=Revoked 2=Unknown
+	fields["ocsp_status_code"] = resp.Status
+	switch resp.Status {
+	case 0:
+		tags["ocsp_status"] = "good"
+	case 1:
+		tags["ocsp_status"] = "revoked"
+		// Status=Good: revoked_at always = -62135596800
+		fields["ocsp_revoked_at"] = resp.RevokedAt.Unix()
+	default:
+		tags["ocsp_status"] = "unknown"
+	}
+	fields["ocsp_produced_at"] = resp.ProducedAt.Unix()
+	fields["ocsp_this_update"] = resp.ThisUpdate.Unix()
+	fields["ocsp_next_update"] = resp.NextUpdate.Unix()
+}
+
+// queryOCSPResponder requests the revocation status of the certificate from
+// the first OCSP responder listed in the certificate
+func (c *X509Cert) queryOCSPResponder(cert, issuer *x509.Certificate) ([]byte, error) {
+	if issuer == nil {
+		return nil, errors.New("issuer certificate required for OCSP request not found")
+	}
+
+	request, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating OCSP request failed: %w", err)
+	}
+
+	client := &http.Client{Timeout: time.Duration(c.Timeout)}
+	resp, err := client.Post(cert.OCSPServer[0], "application/ocsp-request", bytes.NewReader(request))
+	if err != nil {
+		return nil, fmt.Errorf("querying OCSP responder failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("querying OCSP responder failed: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxOCSPResponseSize))
+}
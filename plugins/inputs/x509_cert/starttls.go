@@ -0,0 +1,139 @@
+package x509_cert
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// Default ports of the protocols supporting STARTTLS
+var startTLSPorts = map[string]string{
+	"imap":     "143",
+	"ldap":     "389",
+	"postgres": "5432",
+}
+
+// Magic code of the PostgreSQL SSLRequest message
+const postgresSSLRequestCode = 80877103
+
+// getCertStartTLS connects to the server in plain-text using the given
+// protocol and upgrades the connection to TLS to retrieve the certificates.
+func (c *X509Cert) getCertStartTLS(u *url.URL, timeout time.Duration) ([]*x509.Certificate, *[]byte, error) {
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), startTLSPorts[u.Scheme])
+	}
+
+	dialer, err := c.Proxy()
+	if err != nil {
+		return nil, nil, err
+	}
+	ipConn, err := dialer.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer ipConn.Close()
+	if err := ipConn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, nil, err
+	}
+
+	downloadTLSCfg := c.tlsCfg.Clone()
+	downloadTLSCfg.ServerName = c.serverName(u)
+	downloadTLSCfg.InsecureSkipVerify = true
+
+	var state tls.ConnectionState
+	switch u.Scheme {
+	case "imap":
+		if err := startTLSIMAP(ipConn); err != nil {
+			return nil, nil, err
+		}
+	case "ldap":
+		conn := ldap.NewConn(ipConn, false)
+		conn.SetTimeout(timeout)
+		conn.Start()
+		defer conn.Close()
+		if err := conn.StartTLS(downloadTLSCfg); err != nil {
+			return nil, nil, err
+		}
+		var ok bool
+		if state, ok = conn.TLSConnectionState(); !ok {
+			return nil, nil, errors.New("no TLS connection state")
+		}
+		return state.PeerCertificates, &state.OCSPResponse, nil
+	case "postgres":
+		if err := startTLSPostgres(ipConn); err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported STARTTLS protocol %q", u.Scheme)
+	}
+
+	tlsConn := tls.Client(ipConn, downloadTLSCfg)
+	defer tlsConn.Close()
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, nil, err
+	}
+	state = tlsConn.ConnectionState()
+
+	return state.PeerCertificates, &state.OCSPResponse, nil
+}
+
+// startTLSIMAP negotiates TLS according to RFC 3501 section 6.2.1
+func startTLSIMAP(conn net.Conn) error {
+	reader := bufio.NewReader(conn)
+	greeting, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("reading greeting failed: %w", err)
+	}
+	if !strings.HasPrefix(greeting, "* OK") {
+		return fmt.Errorf("unexpected greeting %q", strings.TrimSpace(greeting))
+	}
+
+	if _, err := io.WriteString(conn, "a1 STARTTLS\r\n"); err != nil {
+		return err
+	}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("reading STARTTLS response failed: %w", err)
+		}
+		// Skip untagged responses
+		if strings.HasPrefix(line, "* ") {
+			continue
+		}
+		if !strings.HasPrefix(line, "a1 OK") {
+			return fmt.Errorf("STARTTLS rejected: %q", strings.TrimSpace(line))
+		}
+		return nil
+	}
+}
+
+// startTLSPostgres negotiates TLS by sending an SSLRequest message as
+// described in the PostgreSQL frontend/backend protocol
+func startTLSPostgres(conn net.Conn) error {
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], postgresSSLRequestCode)
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	response := make([]byte, 1)
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return fmt.Errorf("reading SSLRequest response failed: %w", err)
+	}
+	if response[0] != 'S' {
+		return errors.New("server does not support SSL")
+	}
+	return nil
+}
@@ -12,17 +12,18 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"math"
 	"net"
 	"net/smtp"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/pion/dtls/v2"
-	"golang.org/x/crypto/ocsp"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
@@ -39,13 +40,14 @@ var sampleConfig string
 var reDriveLetter = regexp.MustCompile(`^/([a-zA-Z]:/)`)
 
 type X509Cert struct {
-	Sources          []string        `toml:"sources"`
-	Timeout          config.Duration `toml:"timeout"`
-	ServerName       string          `toml:"server_name"`
-	Password         config.Secret   `toml:"password"`
-	ExcludeRootCerts bool            `toml:"exclude_root_certs"`
-	PadSerial        bool            `toml:"pad_serial_with_zeroes"`
-	Log              telegraf.Logger `toml:"-"`
+	Sources            []string        `toml:"sources"`
+	Timeout            config.Duration `toml:"timeout"`
+	ServerName         string          `toml:"server_name"`
+	Password           config.Secret   `toml:"password"`
+	ExcludeRootCerts   bool            `toml:"exclude_root_certs"`
+	PadSerial          bool            `toml:"pad_serial_with_zeroes"`
+	QueryOCSPResponder bool            `toml:"query_ocsp_responder"`
+	Log                telegraf.Logger `toml:"-"`
 	common_tls.ClientConfig
 	proxy.TCPProxy
 
@@ -145,54 +147,13 @@ func (c *X509Cert) Gather(acc telegraf.Accumulator) error {
 				fields["verification_error"] = strings.Trim(strings.TrimSpace(err.Error()), ":")
 			}
 			// OCSPResponse only for leaf cert
-			if i == 0 && ocspresp != nil && len(*ocspresp) > 0 {
-				var ocspissuer *x509.Certificate
-				for _, chaincert := range certs[1:] {
-					if cert.Issuer.CommonName == chaincert.Subject.CommonName &&
-						cert.Issuer.SerialNumber == chaincert.Subject.SerialNumber {
-						ocspissuer = chaincert
-						break
-					}
-				}
-				resp, err := ocsp.ParseResponse(*ocspresp, ocspissuer)
-				if err != nil {
-					if ocspissuer == nil {
-						tags["ocsp_stapled"] = "no"
-						fields["ocsp_error"] = err.Error()
-					} else {
-						ocspissuer = nil // retry parsing w/out issuer cert
-						resp, err = ocsp.ParseResponse(*ocspresp, ocspissuer)
-					}
-				}
-				if err != nil {
-					tags["ocsp_stapled"] = "no"
-					fields["ocsp_error"] = err.Error()
-				} else {
-					tags["ocsp_stapled"] = "yes"
-					if ocspissuer != nil {
-						tags["ocsp_verified"] = "yes"
-					} else {
-						tags["ocsp_verified"] = "no"
-					}
-					// resp.Status: 0=Good 1=Revoked 2=Unknown
-					fields["ocsp_status_code"] = resp.Status
-					switch resp.Status {
-					case 0:
-						tags["ocsp_status"] = "good"
-					case 1:
-						tags["ocsp_status"] = "revoked"
-						// Status=Good: revoked_at always = -62135596800
-						fields["ocsp_revoked_at"] = resp.RevokedAt.Unix()
-					default:
-						tags["ocsp_status"] = "unknown"
-					}
-					fields["ocsp_produced_at"] = resp.ProducedAt.Unix()
-					fields["ocsp_this_update"] = resp.ThisUpdate.Unix()
-					fields["ocsp_next_update"] = resp.NextUpdate.Unix()
-				}
+			if i == 0 {
+				c.addOCSP(cert, certs[1:], ocspresp, tags, fields)
+				fields["chain_expiry"] = chainExpiry(certs, now)
 			} else {
 				tags["ocsp_stapled"] = "no"
 			}
+			fields["chain_depth"] = i
 
 			// Determine the classification
 			sig := hex.EncodeToString(cert.Signature)
@@ -297,6 +258,8 @@ func (c *X509Cert) sourcesToURLs() error {
 		default:
 			if strings.Index(source, ":\\") == 1 {
 				source = "file://" + filepath.ToSlash(source)
+			} else if isHostPort(source) {
+				source = "tcp://" + source
 			}
 			u, err := url.Parse(source)
 			if err != nil {
@@ -310,6 +273,9 @@ func (c *X509Cert) sourcesToURLs() error {
 }
 
 func (c *X509Cert) serverName(u *url.URL) string {
+	if name := u.Query().Get("server_name"); name != "" {
+		return name
+	}
 	if c.tlsCfg.ServerName != "" {
 		return c.tlsCfg.ServerName
 	}
@@ -455,6 +421,8 @@ func (c *X509Cert) getCert(u *url.URL, timeout time.Duration) ([]*x509.Certifica
 		ocspresp := tlsConn.ConnectionState().OCSPResponse
 
 		return certs, &ocspresp, nil
+	case "imap", "ldap", "postgres":
+		return c.getCertStartTLS(u, timeout)
 	case "jks":
 		certs, err := c.processJKS(u.Path)
 		return certs, nil, err
@@ -525,6 +493,30 @@ func (c *X509Cert) getTags(cert *x509.Certificate, location string) map[string]s
 	return tags
 }
 
+// isHostPort checks if the source is a plain address with a numeric port
+func isHostPort(source string) bool {
+	if strings.Contains(source, "://") {
+		return false
+	}
+	address, _, _ := strings.Cut(source, "?")
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	_, err = strconv.ParseUint(port, 10, 16)
+	return err == nil
+}
+
+// chainExpiry returns the time in seconds until the first certificate of the
+// chain expires
+func chainExpiry(certs []*x509.Certificate, now time.Time) int {
+	expiry := math.MaxInt
+	for _, cert := range certs {
+		expiry = min(expiry, int(cert.NotAfter.Sub(now).Seconds()))
+	}
+	return expiry
+}
+
 func (c *X509Cert) collectCertURLs() []*url.URL {
 	var urls []*url.URL
 
@@ -1,6 +1,7 @@
 package x509_cert
 
 import (
+	"bufio"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/tls"
@@ -9,6 +10,7 @@ import (
 	"encoding/base64"
 	"encoding/pem"
 	"fmt"
+	"io"
 	"math/big"
 	"net"
 	"net/http"
@@ -23,6 +25,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/pion/dtls/v2"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
@@ -390,12 +393,211 @@ func TestGatherCertMustNotTimeoutIntegration(t *testing.T) {
 	require.True(t, acc.HasTag("x509_cert", "ocsp_stapled"))
 }
 
+func TestGatherStartTLS(t *testing.T) {
+	tests := []struct {
+		protocol  string
+		handshake func(net.Conn) error
+	}{
+		{
+			protocol: "imap",
+			handshake: func(conn net.Conn) error {
+				if _, err := io.WriteString(conn, "* OK IMAP4rev1 ready\r\n"); err != nil {
+					return err
+				}
+				line, err := bufio.NewReader(conn).ReadString('\n')
+				if err != nil {
+					return err
+				}
+				if line != "a1 STARTTLS\r\n" {
+					return fmt.Errorf("unexpected command %q", line)
+				}
+				_, err = io.WriteString(conn, "a1 OK Begin TLS negotiation now\r\n")
+				return err
+			},
+		},
+		{
+			protocol: "ldap",
+			handshake: func(conn net.Conn) error {
+				// Read the extended request and answer with a successful
+				// extended response using the same message ID
+				header := make([]byte, 2)
+				if _, err := io.ReadFull(conn, header); err != nil {
+					return err
+				}
+				request := make([]byte, header[1])
+				if _, err := io.ReadFull(conn, request); err != nil {
+					return err
+				}
+				id := request[:2+request[1]]
+				response := []byte{0x30, byte(len(id) + 9)}
+				response = append(response, id...)
+				response = append(response, 0x78, 0x07, 0x0a, 0x01, 0x00, 0x04, 0x00, 0x04, 0x00)
+				_, err := conn.Write(response)
+				return err
+			},
+		},
+		{
+			protocol: "postgres",
+			handshake: func(conn net.Conn) error {
+				request := make([]byte, 8)
+				if _, err := io.ReadFull(conn, request); err != nil {
+					return err
+				}
+				_, err := conn.Write([]byte{'S'})
+				return err
+			},
+		},
+	}
+
+	pair, err := tls.X509KeyPair([]byte(pki.ReadServerCert()), []byte(pki.ReadServerKey()))
+	require.NoError(t, err)
+	cfg := &tls.Config{Certificates: []tls.Certificate{pair}}
+
+	for _, tt := range tests {
+		t.Run(tt.protocol, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer listener.Close()
+
+			go func() {
+				conn, err := listener.Accept()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer conn.Close()
+				if err := tt.handshake(conn); err != nil {
+					t.Error(err)
+					return
+				}
+				if err := tls.Server(conn, cfg).Handshake(); err != nil {
+					t.Error(err)
+				}
+			}()
+
+			plugin := &X509Cert{
+				Sources: []string{tt.protocol + "://" + listener.Addr().String() + "?server_name=localhost"},
+				Timeout: config.Duration(5 * time.Second),
+				Log:     testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Gather(&acc))
+			require.Empty(t, acc.Errors)
+
+			metrics := acc.GetTelegrafMetrics()
+			require.NotEmpty(t, metrics)
+			leaf := metrics[0]
+			require.Equal(t, pair.Leaf.Subject.CommonName, leaf.Tags()["common_name"])
+			depth, found := leaf.GetField("chain_depth")
+			require.True(t, found)
+			require.EqualValues(t, 0, depth)
+			require.True(t, leaf.HasField("chain_expiry"))
+		})
+	}
+}
+
+func TestOCSPResponder(t *testing.T) {
+	// Create a CA and a leaf certificate pointing to the OCSP responder
+	caPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	caBytes, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caPriv.PublicKey, caPriv)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(caBytes)
+	require.NoError(t, err)
+
+	revokedAt := time.Now().Add(-30 * time.Minute).Truncate(time.Second)
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		req, err := ocsp.ParseRequest(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		resp, err := ocsp.CreateResponse(ca, ca, ocsp.Response{
+			Status:       ocsp.Revoked,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+			RevokedAt:    revokedAt,
+		}, caPriv)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if _, err := w.Write(resp); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer responder.Close()
+
+	leafPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "My server"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		OCSPServer:   []string{responder.URL},
+	}
+	leafBytes, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &leafPriv.PublicKey, caPriv)
+	require.NoError(t, err)
+
+	chain := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafBytes})
+	chain = append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caBytes})...)
+	fn := filepath.Join(t.TempDir(), "cert.pem")
+	require.NoError(t, os.WriteFile(fn, chain, 0600))
+
+	plugin := &X509Cert{
+		Sources:            []string{fn},
+		Timeout:            config.Duration(5 * time.Second),
+		QueryOCSPResponder: true,
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	var found bool
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Tags()["common_name"] != "My server" {
+			continue
+		}
+		found = true
+		require.Equal(t, "no", m.Tags()["ocsp_stapled"])
+		require.Equal(t, "revoked", m.Tags()["ocsp_status"])
+		require.Equal(t, "yes", m.Tags()["ocsp_verified"])
+		revoked, ok := m.GetField("ocsp_revoked_at")
+		require.True(t, ok)
+		require.Equal(t, revokedAt.Unix(), revoked)
+	}
+	require.True(t, found)
+}
+
 func TestSourcesToURLs(t *testing.T) {
 	m := &X509Cert{
 		Sources: []string{
 			"https://www.influxdata.com:443",
 			"tcp://influxdata.com:443",
 			"smtp://influxdata.com:25",
+			"postgres://db.influxdata.com?server_name=influxdata.com",
+			"influxdata.com:636",
 			"file:///dummy_test_path_file.pem",
 			"file:///windows/temp/test.pem",
 			`file://C:\windows\temp\test.pem`,
@@ -410,6 +612,8 @@ func TestSourcesToURLs(t *testing.T) {
 		"https://www.influxdata.com:443",
 		"tcp://influxdata.com:443",
 		"smtp://influxdata.com:25",
+		"postgres://db.influxdata.com?server_name=influxdata.com",
+		"tcp://influxdata.com:636",
 	}
 
 	expectedPaths := []string{
@@ -431,7 +635,7 @@ func TestSourcesToURLs(t *testing.T) {
 		actual = append(actual, p.String())
 	}
 	require.Len(t, m.globpaths, 5)
-	require.Len(t, m.locations, 3)
+	require.Len(t, m.locations, 5)
 	require.ElementsMatch(t, expected, actual)
 }
 
@@ -447,6 +651,7 @@ func TestServerName(t *testing.T) {
 		{name: "in cfg", fromCfg: "example.com", url: "https://other.example.com", expected: "example.com"},
 		{name: "in tls", fromTLS: "example.com", url: "https://other.example.com", expected: "example.com"},
 		{name: "from URL", url: "https://other.example.com", expected: "other.example.com"},
+		{name: "per source", fromCfg: "example.com", url: "tcp://10.0.0.1:443?server_name=other.example.com", expected: "other.example.com"},
 		{name: "errors", fromCfg: "otherex.com", fromTLS: "example.com", url: "https://other.example.com", err: true},
 	}
 
@@ -611,6 +816,8 @@ func TestClassification(t *testing.T) {
 			map[string]interface{}{
 				"age":               int64(0),
 				"expiry":            int64(86399),
+				"chain_depth":       0,
+				"chain_expiry":      int64(86399),
 				"startdate":         start.Unix(),
 				"enddate":           end.Unix(),
 				"verification_code": int64(0),
@@ -637,6 +844,7 @@ func TestClassification(t *testing.T) {
 			map[string]interface{}{
 				"age":               int64(0),
 				"expiry":            int64(86399),
+				"chain_depth":       1,
 				"startdate":         start.Unix(),
 				"enddate":           end.Unix(),
 				"verification_code": int64(0),
@@ -663,6 +871,7 @@ func TestClassification(t *testing.T) {
 			map[string]interface{}{
 				"age":               int64(0),
 				"expiry":            int64(86399),
+				"chain_depth":       2,
 				"startdate":         start.Unix(),
 				"enddate":           end.Unix(),
 				"verification_code": int64(0),
@@ -675,7 +884,7 @@ func TestClassification(t *testing.T) {
 		testutil.SortMetrics(),
 		testutil.IgnoreTime(),
 		// We need to ignore those fields as they are timing sensitive.
-		testutil.IgnoreFields("age", "expiry"),
+		testutil.IgnoreFields("age", "expiry", "chain_expiry"),
 	}
 	actual := acc.GetTelegrafMetrics()
 	testutil.RequireMetricsEqual(t, expected, actual, opts...)
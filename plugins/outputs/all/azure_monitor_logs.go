@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.azure_monitor_logs
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/azure_monitor_logs" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.netdata
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/netdata" // register plugin
@@ -0,0 +1,331 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package azure_monitor_logs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const (
+	apiVersion = "2023-01-01"
+	tokenScope = "https://monitor.azure.com/.default"
+
+	// The Logs Ingestion API accepts at most 1MB per call
+	maxRequestBodySize = 1000000
+)
+
+type AzureMonitorLogs struct {
+	Endpoint        string          `toml:"endpoint"`
+	DCRImmutableID  string          `toml:"dcr_immutable_id"`
+	StreamName      string          `toml:"stream_name"`
+	TimeColumn      string          `toml:"time_column"`
+	Layout          string          `toml:"layout"`
+	ContentEncoding string          `toml:"content_encoding"`
+	TenantID        string          `toml:"tenant_id"`
+	ClientID        string          `toml:"client_id"`
+	ClientSecret    config.Secret   `toml:"client_secret"`
+	Timeout         config.Duration `toml:"timeout"`
+	Log             telegraf.Logger `toml:"-"`
+
+	url        string
+	credential azcore.TokenCredential
+	client     *http.Client
+}
+
+func (*AzureMonitorLogs) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *AzureMonitorLogs) Init() error {
+	if a.Endpoint == "" {
+		return errors.New("endpoint required")
+	}
+	if a.DCRImmutableID == "" {
+		return errors.New("dcr_immutable_id required")
+	}
+	if a.StreamName == "" {
+		return errors.New("stream_name required")
+	}
+	if a.TimeColumn == "" {
+		a.TimeColumn = "TimeGenerated"
+	}
+
+	switch a.Layout {
+	case "":
+		a.Layout = "nested"
+	case "nested", "flat":
+	default:
+		return fmt.Errorf("invalid layout %q", a.Layout)
+	}
+
+	switch a.ContentEncoding {
+	case "":
+		a.ContentEncoding = "gzip"
+	case "gzip", "identity":
+	default:
+		return fmt.Errorf("invalid content_encoding %q", a.ContentEncoding)
+	}
+
+	u, err := url.Parse(strings.TrimRight(a.Endpoint, "/"))
+	if err != nil {
+		return fmt.Errorf("parsing endpoint failed: %w", err)
+	}
+	u = u.JoinPath("dataCollectionRules", a.DCRImmutableID, "streams", a.StreamName)
+	u.RawQuery = url.Values{"api-version": []string{apiVersion}}.Encode()
+	a.url = u.String()
+
+	return nil
+}
+
+func (a *AzureMonitorLogs) Connect() error {
+	if a.credential == nil {
+		if a.ClientID != "" && !a.ClientSecret.Empty() {
+			secret, err := a.ClientSecret.Get()
+			if err != nil {
+				return fmt.Errorf("getting client secret failed: %w", err)
+			}
+			credential, err := azidentity.NewClientSecretCredential(a.TenantID, a.ClientID, secret.String(), nil)
+			secret.Destroy()
+			if err != nil {
+				return fmt.Errorf("creating client secret credentials failed: %w", err)
+			}
+			a.credential = credential
+		} else {
+			credential, err := azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{TenantID: a.TenantID})
+			if err != nil {
+				return fmt.Errorf("creating default credentials failed: %w", err)
+			}
+			a.credential = credential
+		}
+	}
+
+	a.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+		},
+		Timeout: time.Duration(a.Timeout),
+	}
+
+	return nil
+}
+
+func (a *AzureMonitorLogs) Close() error {
+	if a.client != nil {
+		a.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+func (a *AzureMonitorLogs) Write(metrics []telegraf.Metric) error {
+	writeErr := &internal.PartialWriteError{
+		MetricsAccept: make([]int, 0, len(metrics)),
+	}
+
+	// Split the records into requests not exceeding the size limit of the
+	// API. The size is checked against the uncompressed data as the limit
+	// applies to both.
+	var body bytes.Buffer
+	indices := make([]int, 0, len(metrics))
+	for i, m := range metrics {
+		record, err := json.Marshal(a.record(m))
+		if err != nil {
+			a.Log.Errorf("Serializing metric %v failed: %v", m, err)
+			writeErr.MetricsReject = append(writeErr.MetricsReject, i)
+			writeErr.MetricsRejectErrors = append(writeErr.MetricsRejectErrors, err)
+			continue
+		}
+		if len(record)+2 > maxRequestBodySize {
+			a.Log.Errorf("Metric %q exceeds the request size limit, dropping it", m.Name())
+			writeErr.MetricsReject = append(writeErr.MetricsReject, i)
+			writeErr.MetricsRejectErrors = append(writeErr.MetricsRejectErrors, errors.New("request size limit exceeded"))
+			continue
+		}
+
+		if body.Len()+len(record)+2 > maxRequestBodySize {
+			if err := a.flush(&body, indices, writeErr); err != nil {
+				writeErr.Err = err
+				return writeErr
+			}
+			indices = indices[:0]
+		}
+		if body.Len() == 0 {
+			body.WriteByte('[')
+		} else {
+			body.WriteByte(',')
+		}
+		body.Write(record)
+		indices = append(indices, i)
+	}
+	if len(indices) > 0 {
+		if err := a.flush(&body, indices, writeErr); err != nil {
+			writeErr.Err = err
+			return writeErr
+		}
+	}
+
+	if len(writeErr.MetricsReject) > 0 {
+		writeErr.Err = errors.New("metric(s) rejected")
+		return writeErr
+	}
+	return nil
+}
+
+// flush sends the records in the buffer and resets it. Records rejected by the
+// service are marked as rejected and only errors worth a retry are returned.
+func (a *AzureMonitorLogs) flush(body *bytes.Buffer, indices []int, writeErr *internal.PartialWriteError) error {
+	body.WriteByte(']')
+	defer body.Reset()
+
+	err := a.send(body.Bytes())
+	var rejected *rejectedError
+	switch {
+	case err == nil:
+		writeErr.MetricsAccept = append(writeErr.MetricsAccept, indices...)
+	case errors.As(err, &rejected):
+		a.Log.Errorf("Dropping %d metric(s): %v", len(indices), err)
+		writeErr.MetricsReject = append(writeErr.MetricsReject, indices...)
+		for range indices {
+			writeErr.MetricsRejectErrors = append(writeErr.MetricsRejectErrors, err)
+		}
+	default:
+		return err
+	}
+
+	return nil
+}
+
+// rejectedError marks requests rejected by the service which will not succeed
+// when being retried
+type rejectedError struct {
+	status  string
+	message string
+}
+
+func (e *rejectedError) Error() string {
+	return fmt.Sprintf("request rejected with status %q: %s", e.status, e.message)
+}
+
+func (a *AzureMonitorLogs) send(body []byte) error {
+	ctx := context.Background()
+	if a.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(a.Timeout))
+		defer cancel()
+	}
+
+	token, err := a.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{tokenScope}})
+	if err != nil {
+		return fmt.Errorf("getting token failed: %w", err)
+	}
+
+	var reader io.Reader = bytes.NewReader(body)
+	if a.ContentEncoding == "gzip" {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(body); err != nil {
+			return fmt.Errorf("compressing request failed: %w", err)
+		}
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("compressing request failed: %w", err)
+		}
+		reader = &buf
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	req.Header.Set("User-Agent", internal.ProductToken())
+	if a.ContentEncoding == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	message := strings.TrimSpace(string(msg))
+
+	// Throttling and server-side errors are worth a retry, all other errors
+	// indicate invalid data or configuration
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 ||
+		resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("received status %q: %s", resp.Status, message)
+	}
+	return &rejectedError{status: resp.Status, message: message}
+}
+
+// record converts the metric into a row of the stream declared in the data
+// collection rule
+func (a *AzureMonitorLogs) record(m telegraf.Metric) map[string]interface{} {
+	fields := make(map[string]interface{}, len(m.FieldList()))
+	for _, f := range m.FieldList() {
+		// JSON cannot represent non-finite numbers
+		if v, ok := f.Value.(float64); ok && (math.IsNaN(v) || math.IsInf(v, 0)) {
+			continue
+		}
+		fields[f.Key] = f.Value
+	}
+
+	timestamp := m.Time().UTC().Format(time.RFC3339Nano)
+	if a.Layout == "flat" {
+		// Fields take precedence over tags with the same name
+		record := make(map[string]interface{}, len(m.TagList())+len(fields)+2)
+		for _, t := range m.TagList() {
+			record[t.Key] = t.Value
+		}
+		for k, v := range fields {
+			record[k] = v
+		}
+		record["Name"] = m.Name()
+		record[a.TimeColumn] = timestamp
+		return record
+	}
+
+	return map[string]interface{}{
+		a.TimeColumn: timestamp,
+		"Name":       m.Name(),
+		"Tags":       m.Tags(),
+		"Fields":     fields,
+	}
+}
+
+func init() {
+	outputs.Add("azure_monitor_logs", func() telegraf.Output {
+		return &AzureMonitorLogs{
+			Timeout: config.Duration(20 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,317 @@
+package azure_monitor_logs
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *AzureMonitorLogs
+		expected string
+	}{
+		{
+			name:     "no endpoint",
+			plugin:   &AzureMonitorLogs{},
+			expected: "endpoint required",
+		},
+		{
+			name:     "no rule",
+			plugin:   &AzureMonitorLogs{Endpoint: "https://localhost"},
+			expected: "dcr_immutable_id required",
+		},
+		{
+			name:     "no stream",
+			plugin:   &AzureMonitorLogs{Endpoint: "https://localhost", DCRImmutableID: "dcr-123"},
+			expected: "stream_name required",
+		},
+		{
+			name: "invalid layout",
+			plugin: &AzureMonitorLogs{
+				Endpoint:       "https://localhost",
+				DCRImmutableID: "dcr-123",
+				StreamName:     "Custom-Telegraf_CL",
+				Layout:         "columns",
+			},
+			expected: `invalid layout "columns"`,
+		},
+		{
+			name: "invalid encoding",
+			plugin: &AzureMonitorLogs{
+				Endpoint:        "https://localhost",
+				DCRImmutableID:  "dcr-123",
+				StreamName:      "Custom-Telegraf_CL",
+				ContentEncoding: "zstd",
+			},
+			expected: `invalid content_encoding "zstd"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		layout   string
+		encoding string
+		expected []map[string]interface{}
+	}{
+		{
+			name:     "nested",
+			layout:   "nested",
+			encoding: "gzip",
+			expected: []map[string]interface{}{
+				{
+					"TimeGenerated": "2024-01-02T03:04:05.000000006Z",
+					"Name":          "cpu",
+					"Tags":          map[string]interface{}{"cpu": "cpu0", "host": "server01"},
+					"Fields":        map[string]interface{}{"usage_idle": 92.5, "usage_user": float64(3)},
+				},
+				{
+					"TimeGenerated": "2024-01-02T03:04:06Z",
+					"Name":          "syslog",
+					"Tags":          map[string]interface{}{"host": "server01"},
+					"Fields":        map[string]interface{}{"message": "started", "severity": "info"},
+				},
+			},
+		},
+		{
+			name:     "flat",
+			layout:   "flat",
+			encoding: "identity",
+			expected: []map[string]interface{}{
+				{
+					"TimeGenerated": "2024-01-02T03:04:05.000000006Z",
+					"Name":          "cpu",
+					"cpu":           "cpu0",
+					"host":          "server01",
+					"usage_idle":    92.5,
+					"usage_user":    float64(3),
+				},
+				{
+					"TimeGenerated": "2024-01-02T03:04:06Z",
+					"Name":          "syslog",
+					"host":          "server01",
+					"message":       "started",
+					"severity":      "info",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var records []map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/dataCollectionRules/dcr-123/streams/Custom-Telegraf_CL" {
+					w.WriteHeader(http.StatusNotFound)
+					t.Errorf("unexpected path %q", r.URL.Path)
+					return
+				}
+				if v := r.URL.Query().Get("api-version"); v != apiVersion {
+					w.WriteHeader(http.StatusBadRequest)
+					t.Errorf("unexpected API version %q", v)
+					return
+				}
+				if auth := r.Header.Get("Authorization"); auth != "Bearer secret-token" {
+					w.WriteHeader(http.StatusUnauthorized)
+					t.Errorf("unexpected authorization %q", auth)
+					return
+				}
+
+				var body io.Reader = r.Body
+				if tt.encoding == "gzip" {
+					if enc := r.Header.Get("Content-Encoding"); enc != "gzip" {
+						w.WriteHeader(http.StatusBadRequest)
+						t.Errorf("unexpected content encoding %q", enc)
+						return
+					}
+					gz, err := gzip.NewReader(r.Body)
+					if err != nil {
+						w.WriteHeader(http.StatusBadRequest)
+						t.Error(err)
+						return
+					}
+					body = gz
+				}
+				if err := json.NewDecoder(body).Decode(&records); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					t.Error(err)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			plugin := &AzureMonitorLogs{
+				Endpoint:        server.URL,
+				DCRImmutableID:  "dcr-123",
+				StreamName:      "Custom-Telegraf_CL",
+				Layout:          tt.layout,
+				ContentEncoding: tt.encoding,
+				Log:             testutil.Logger{},
+				credential:      &fakeCredential{token: "secret-token"},
+			}
+			require.NoError(t, plugin.Init())
+			require.NoError(t, plugin.Connect())
+			defer plugin.Close()
+
+			require.NoError(t, plugin.Write(testMetrics()))
+			require.Equal(t, tt.expected, records)
+		})
+	}
+}
+
+func TestWriteSplitsRequests(t *testing.T) {
+	var mu sync.Mutex
+	var requests, received int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			t.Error(err)
+			return
+		}
+		if len(body) > maxRequestBodySize {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			t.Errorf("request size %d exceeds limit", len(body))
+			return
+		}
+		var records []map[string]interface{}
+		if err := json.Unmarshal(body, &records); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			t.Error(err)
+			return
+		}
+		mu.Lock()
+		requests++
+		received += len(records)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	plugin := &AzureMonitorLogs{
+		Endpoint:        server.URL,
+		DCRImmutableID:  "dcr-123",
+		StreamName:      "Custom-Telegraf_CL",
+		ContentEncoding: "identity",
+		Log:             testutil.Logger{},
+		credential:      &fakeCredential{token: "secret-token"},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// Each record is about 10kB so we need multiple requests
+	metrics := make([]telegraf.Metric, 0, 250)
+	for range 250 {
+		metrics = append(metrics, metric.New(
+			"log",
+			map[string]string{},
+			map[string]interface{}{"message": strings.Repeat("x", 10000)},
+			time.Unix(0, 0),
+		))
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.Equal(t, 3, requests)
+	require.Equal(t, 250, received)
+}
+
+func TestWriteErrors(t *testing.T) {
+	var status int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+		if _, err := w.Write([]byte(`{"error":{"code":"InvalidStream"}}`)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &AzureMonitorLogs{
+		Endpoint:       server.URL,
+		DCRImmutableID: "dcr-123",
+		StreamName:     "Custom-Telegraf_CL",
+		Log:            testutil.Logger{},
+		credential:     &fakeCredential{token: "secret-token"},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// Invalid data is dropped
+	status = http.StatusBadRequest
+	err := plugin.Write(testMetrics())
+	var writeErr *internal.PartialWriteError
+	require.ErrorAs(t, err, &writeErr)
+	require.Empty(t, writeErr.MetricsAccept)
+	require.Equal(t, []int{0, 1}, writeErr.MetricsReject)
+
+	// Server errors are retried
+	status = http.StatusServiceUnavailable
+	err = plugin.Write(testMetrics())
+	require.ErrorAs(t, err, &writeErr)
+	require.ErrorContains(t, err, "503 Service Unavailable")
+	require.Empty(t, writeErr.MetricsAccept)
+	require.Empty(t, writeErr.MetricsReject)
+
+	// Token errors are retried
+	plugin.credential = &fakeCredential{err: errors.New("no identity")}
+	require.ErrorContains(t, plugin.Write(testMetrics()), "getting token failed: no identity")
+}
+
+type fakeCredential struct {
+	token string
+	err   error
+}
+
+func (c *fakeCredential) GetToken(_ context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	if c.err != nil {
+		return azcore.AccessToken{}, c.err
+	}
+	if len(options.Scopes) != 1 || options.Scopes[0] != tokenScope {
+		return azcore.AccessToken{}, errors.New("invalid scope")
+	}
+	return azcore.AccessToken{Token: c.token, ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func testMetrics() []telegraf.Metric {
+	return []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"cpu": "cpu0", "host": "server01"},
+			map[string]interface{}{"usage_idle": 92.5, "usage_user": uint64(3)},
+			time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		),
+		metric.New(
+			"syslog",
+			map[string]string{"host": "server01"},
+			map[string]interface{}{"message": "started", "severity": "info"},
+			time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC),
+		),
+	}
+}
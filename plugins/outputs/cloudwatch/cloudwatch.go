@@ -4,6 +4,8 @@ package cloudwatch
 import (
 	"context"
 	_ "embed"
+	"errors"
+	"fmt"
 	"math"
 	"net/http"
 	"sort"
@@ -13,8 +15,10 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
 	common_aws "github.com/influxdata/telegraf/plugins/common/aws"
 	common_http "github.com/influxdata/telegraf/plugins/common/http"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -24,14 +28,21 @@ import (
 var sampleConfig string
 
 type CloudWatch struct {
-	Namespace             string `toml:"namespace"` // CloudWatch Metrics Namespace
-	HighResolutionMetrics bool   `toml:"high_resolution_metrics"`
-	svc                   *cloudwatch.Client
-	WriteStatistics       bool            `toml:"write_statistics"`
-	Log                   telegraf.Logger `toml:"-"`
+	Namespace                  string   `toml:"namespace"` // CloudWatch Metrics Namespace
+	HighResolutionMetrics      bool     `toml:"high_resolution_metrics"`
+	HighResolutionMeasurements []string `toml:"high_resolution_measurements"`
+	Method                     string   `toml:"method"`
+	EMFLogGroup                string   `toml:"emf_log_group"`
+	EMFLogStream               string   `toml:"emf_log_stream"`
+	svc                        *cloudwatch.Client
+	logs                       cloudWatchLogs
+	WriteStatistics            bool            `toml:"write_statistics"`
+	Log                        telegraf.Logger `toml:"-"`
 	common_aws.CredentialConfig
 	common_http.HTTPClientConfig
-	client *http.Client
+	client        *http.Client
+	highResFilter filter.Filter
+	now           func() time.Time
 }
 
 type statisticType int
@@ -92,9 +103,10 @@ func (f *statisticField) buildDatum() []types.MetricDatum {
 		// If we don't have all required fields, we build each field as independent datum
 		for sType, value := range f.values {
 			datum := types.MetricDatum{
-				Value:      aws.Float64(value),
-				Dimensions: BuildDimensions(f.tags),
-				Timestamp:  aws.Time(f.timestamp),
+				Value:             aws.Float64(value),
+				Dimensions:        BuildDimensions(f.tags),
+				Timestamp:         aws.Time(f.timestamp),
+				StorageResolution: aws.Int32(int32(f.storageResolution)),
 			}
 
 			switch sType {
@@ -158,6 +170,35 @@ func (*CloudWatch) SampleConfig() string {
 	return sampleConfig
 }
 
+func (c *CloudWatch) Init() error {
+	switch c.Method {
+	case "put_metric_data":
+	case "emf":
+		if c.EMFLogGroup == "" {
+			return errors.New("'emf_log_group' required for method \"emf\"")
+		}
+		if c.EMFLogStream == "" {
+			return errors.New("'emf_log_stream' required for method \"emf\"")
+		}
+	default:
+		return fmt.Errorf("invalid method %q", c.Method)
+	}
+
+	if len(c.HighResolutionMeasurements) > 0 {
+		f, err := filter.Compile(c.HighResolutionMeasurements)
+		if err != nil {
+			return fmt.Errorf("creating high resolution measurement filter failed: %w", err)
+		}
+		c.highResFilter = f
+	}
+
+	if c.now == nil {
+		c.now = time.Now
+	}
+
+	return nil
+}
+
 func (c *CloudWatch) Connect() error {
 	cfg, err := c.CredentialConfig.Credentials()
 
@@ -174,6 +215,16 @@ func (c *CloudWatch) Connect() error {
 
 	c.client = client
 
+	if c.Method == "emf" {
+		c.logs = cloudwatchlogs.NewFromConfig(cfg, func(options *cloudwatchlogs.Options) {
+			options.HTTPClient = c.client
+			if c.CredentialConfig.EndpointURL != "" {
+				options.BaseEndpoint = &c.CredentialConfig.EndpointURL
+			}
+		})
+		return c.createLogStream()
+	}
+
 	c.svc = cloudwatch.NewFromConfig(cfg, func(options *cloudwatch.Options) {
 		options.HTTPClient = c.client
 	})
@@ -190,9 +241,13 @@ func (c *CloudWatch) Close() error {
 }
 
 func (c *CloudWatch) Write(metrics []telegraf.Metric) error {
+	if c.Method == "emf" {
+		return c.writeEMF(metrics)
+	}
+
 	var datums []types.MetricDatum
 	for _, m := range metrics {
-		d := BuildMetricDatum(c.WriteStatistics, c.HighResolutionMetrics, m)
+		d := BuildMetricDatum(c.WriteStatistics, c.storageResolution(m) == 1, m)
 		datums = append(datums, d...)
 	}
 
@@ -225,6 +280,15 @@ func (c *CloudWatch) WriteToCloudWatch(datums []types.MetricDatum) error {
 	return err
 }
 
+// storageResolution returns the resolution in seconds to store the metric with
+// in CloudWatch, i.e. 1 for high resolution and 60 for standard resolution
+func (c *CloudWatch) storageResolution(m telegraf.Metric) int64 {
+	if c.HighResolutionMetrics || c.highResFilter != nil && c.highResFilter.Match(m.Name()) {
+		return 1
+	}
+	return 60
+}
+
 // PartitionDatums partitions the MetricDatums into smaller slices of a max size so that are under the limit
 // for the AWS API calls.
 func PartitionDatums(size int, datums []types.MetricDatum) [][]types.MetricDatum {
@@ -420,6 +484,8 @@ func convert(v interface{}) (value float64, ok bool) {
 
 func init() {
 	outputs.Add("cloudwatch", func() telegraf.Output {
-		return &CloudWatch{}
+		return &CloudWatch{
+			Method: "put_metric_data",
+		}
 	})
 }
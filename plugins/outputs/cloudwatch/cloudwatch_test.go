@@ -1,13 +1,17 @@
 package cloudwatch
 
 import (
+	"context"
+	"encoding/json"
 	"math"
 	"sort"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
@@ -156,3 +160,212 @@ func TestPartitionDatums(t *testing.T) {
 	require.Equal(t, [][]types.MetricDatum{twoDatum}, PartitionDatums(2, twoDatum))
 	require.Equal(t, [][]types.MetricDatum{twoDatum, oneDatum}, PartitionDatums(2, threeDatum))
 }
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *CloudWatch
+		expected string
+	}{
+		{
+			name:     "invalid method",
+			plugin:   &CloudWatch{Method: "foo"},
+			expected: `invalid method "foo"`,
+		},
+		{
+			name:     "emf without log group",
+			plugin:   &CloudWatch{Method: "emf", EMFLogStream: "telegraf"},
+			expected: "'emf_log_group' required",
+		},
+		{
+			name:     "emf without log stream",
+			plugin:   &CloudWatch{Method: "emf", EMFLogGroup: "metrics"},
+			expected: "'emf_log_stream' required",
+		},
+		{
+			name: "invalid high resolution measurements",
+			plugin: &CloudWatch{
+				Method:                     "put_metric_data",
+				HighResolutionMeasurements: []string{"cpu["},
+			},
+			expected: "creating high resolution measurement filter failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestHighResolutionMeasurements(t *testing.T) {
+	plugin := &CloudWatch{
+		Method:                     "put_metric_data",
+		HighResolutionMeasurements: []string{"cpu*"},
+	}
+	require.NoError(t, plugin.Init())
+
+	cpu := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	mem := metric.New("mem", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	require.Equal(t, int64(1), plugin.storageResolution(cpu))
+	require.Equal(t, int64(60), plugin.storageResolution(mem))
+
+	plugin.HighResolutionMetrics = true
+	require.Equal(t, int64(1), plugin.storageResolution(mem))
+}
+
+type mockLogs struct {
+	streams []string
+	events  []string
+	calls   int
+}
+
+func (m *mockLogs) CreateLogStream(
+	_ context.Context,
+	input *cloudwatchlogs.CreateLogStreamInput,
+	_ ...func(options *cloudwatchlogs.Options),
+) (*cloudwatchlogs.CreateLogStreamOutput, error) {
+	m.streams = append(m.streams, *input.LogGroupName+"/"+*input.LogStreamName)
+	return &cloudwatchlogs.CreateLogStreamOutput{}, nil
+}
+
+func (m *mockLogs) PutLogEvents(
+	_ context.Context,
+	input *cloudwatchlogs.PutLogEventsInput,
+	_ ...func(options *cloudwatchlogs.Options),
+) (*cloudwatchlogs.PutLogEventsOutput, error) {
+	m.calls++
+	for _, e := range input.LogEvents {
+		m.events = append(m.events, *e.Message)
+	}
+	return &cloudwatchlogs.PutLogEventsOutput{}, nil
+}
+
+func TestWriteEMF(t *testing.T) {
+	logs := &mockLogs{}
+	plugin := &CloudWatch{
+		Namespace:                  "Telegraf",
+		Method:                     "emf",
+		EMFLogGroup:                "metrics",
+		EMFLogStream:               "telegraf",
+		HighResolutionMeasurements: []string{"cpu"},
+		Log:                        testutil.Logger{},
+		logs:                       logs,
+		now:                        func() time.Time { return time.Unix(10, 0) },
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.createLogStream())
+	require.Equal(t, []string{"metrics/telegraf"}, logs.streams)
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"mem",
+			map[string]string{"host": "example.org", "empty": ""},
+			map[string]interface{}{"used": int64(42)},
+			time.Unix(2, 0),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "example.org", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 90.5, "usage_user": 9.5, "status": "ok"},
+			time.Unix(1, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.Equal(t, 1, logs.calls)
+
+	expected := []string{
+		`{"_aws":{"Timestamp":1000,"CloudWatchMetrics":[{"Namespace":"Telegraf","Dimensions":[["host","cpu"]],` +
+			`"Metrics":[{"Name":"cpu_usage_idle","StorageResolution":1},{"Name":"cpu_usage_user","StorageResolution":1}]}]},` +
+			`"cpu":"cpu0","cpu_usage_idle":90.5,"cpu_usage_user":9.5,"host":"example.org"}`,
+		`{"_aws":{"Timestamp":2000,"CloudWatchMetrics":[{"Namespace":"Telegraf","Dimensions":[["host"]],` +
+			`"Metrics":[{"Name":"mem_used","StorageResolution":60}]}]},"host":"example.org","mem_used":42}`,
+	}
+	require.Len(t, logs.events, len(expected))
+	for i := range expected {
+		require.JSONEq(t, expected[i], logs.events[i])
+	}
+}
+
+func TestWriteEMFSplitMetrics(t *testing.T) {
+	logs := &mockLogs{}
+	plugin := &CloudWatch{
+		Namespace:    "Telegraf",
+		Method:       "emf",
+		EMFLogGroup:  "metrics",
+		EMFLogStream: "telegraf",
+		Log:          testutil.Logger{},
+		logs:         logs,
+		now:          func() time.Time { return time.Unix(10, 0) },
+	}
+	require.NoError(t, plugin.Init())
+
+	fields := make(map[string]interface{}, 150)
+	for i := range 150 {
+		fields["field"+strconv.Itoa(i)] = i
+	}
+	m := metric.New("test", map[string]string{}, fields, time.Unix(0, 0))
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+	require.Len(t, logs.events, 2)
+
+	var total int
+	for _, event := range logs.events {
+		var record struct {
+			AWS emfMetadata `json:"_aws"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(event), &record))
+		require.Len(t, record.AWS.CloudWatchMetrics, 1)
+		require.LessOrEqual(t, len(record.AWS.CloudWatchMetrics[0].Metrics), maxMetricsPerRecord)
+		total += len(record.AWS.CloudWatchMetrics[0].Metrics)
+	}
+	require.Equal(t, 150, total)
+}
+
+func TestWriteEMFTimeRange(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	logs := &mockLogs{}
+	plugin := &CloudWatch{
+		Namespace:    "Telegraf",
+		Method:       "emf",
+		EMFLogGroup:  "metrics",
+		EMFLogStream: "telegraf",
+		Log:          testutil.Logger{},
+		logs:         logs,
+		now:          func() time.Time { return now },
+	}
+	require.NoError(t, plugin.Init())
+
+	newMetric := func(ts time.Time) telegraf.Metric {
+		return metric.New("test", map[string]string{}, map[string]interface{}{"value": 42}, ts)
+	}
+	metrics := []telegraf.Metric{
+		newMetric(now),
+		newMetric(now.Add(-15 * 24 * time.Hour)),
+		newMetric(now.Add(-3 * 24 * time.Hour)),
+		newMetric(now.Add(3 * time.Hour)),
+		newMetric(now.Add(-36 * time.Hour)),
+		newMetric(now.Add(time.Hour)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	// Metrics outside of the accepted range are dropped and batches must not
+	// span more than a day
+	require.Equal(t, 3, logs.calls)
+	require.Len(t, logs.events, 4)
+	var timestamps []int64
+	for _, event := range logs.events {
+		var record struct {
+			AWS emfMetadata `json:"_aws"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(event), &record))
+		timestamps = append(timestamps, record.AWS.Timestamp)
+	}
+	require.Equal(t, []int64{
+		now.Add(-3 * 24 * time.Hour).UnixMilli(),
+		now.Add(-36 * time.Hour).UnixMilli(),
+		now.UnixMilli(),
+		now.Add(time.Hour).UnixMilli(),
+	}, timestamps)
+}
@@ -0,0 +1,199 @@
+package cloudwatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	logtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Limits of the PutLogEvents API call and the embedded metric format, see
+// https://docs.aws.amazon.com/AmazonCloudWatchLogs/latest/APIReference/API_PutLogEvents.html
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch_Embedded_Metric_Format_Specification.html
+const (
+	maxEventsPerCall    = 10000
+	maxBytesPerCall     = 1048576
+	eventOverheadBytes  = 26
+	maxEventBytes       = 262144 - eventOverheadBytes
+	maxMetricsPerRecord = 100
+	maxBatchSpan        = 24 * time.Hour
+	maxEventAge         = 14 * 24 * time.Hour
+	maxEventFuture      = 2 * time.Hour
+)
+
+// Cloudwatch Logs service interface used for writing EMF records
+type cloudWatchLogs interface {
+	CreateLogStream(
+		context.Context,
+		*cloudwatchlogs.CreateLogStreamInput,
+		...func(options *cloudwatchlogs.Options),
+	) (*cloudwatchlogs.CreateLogStreamOutput, error)
+	PutLogEvents(context.Context, *cloudwatchlogs.PutLogEventsInput, ...func(options *cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error)
+}
+
+type emfMetadata struct {
+	Timestamp         int64          `json:"Timestamp"`
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+type emfDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+type emfMetric struct {
+	Name              string `json:"Name"`
+	StorageResolution int64  `json:"StorageResolution,omitempty"`
+}
+
+// createLogStream creates the log stream for the EMF records if it does not
+// exist yet
+func (c *CloudWatch) createLogStream() error {
+	_, err := c.logs.CreateLogStream(context.Background(), &cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(c.EMFLogGroup),
+		LogStreamName: aws.String(c.EMFLogStream),
+	})
+	var exists *logtypes.ResourceAlreadyExistsException
+	if err != nil && !errors.As(err, &exists) {
+		return fmt.Errorf("creating log stream %q in log group %q failed: %w", c.EMFLogStream, c.EMFLogGroup, err)
+	}
+	return nil
+}
+
+// writeEMF sends the metrics as embedded metric format records to CloudWatch
+// Logs where they are extracted to CloudWatch metrics
+func (c *CloudWatch) writeEMF(metrics []telegraf.Metric) error {
+	// Events outside of the accepted time range would fail the whole batch
+	now := c.now()
+	oldest, newest := now.Add(-maxEventAge), now.Add(maxEventFuture)
+
+	var outdated int
+	events := make([]logtypes.InputLogEvent, 0, len(metrics))
+	for _, m := range metrics {
+		if m.Time().Before(oldest) || m.Time().After(newest) {
+			outdated++
+			continue
+		}
+		records, err := c.buildEMFRecords(m)
+		if err != nil {
+			c.Log.Errorf("Encoding metric %q failed: %v", m.Name(), err)
+			continue
+		}
+		for _, record := range records {
+			if len(record) > maxEventBytes {
+				c.Log.Errorf("Dropping metric %q with a record of %d bytes exceeding the limit", m.Name(), len(record))
+				continue
+			}
+			events = append(events, logtypes.InputLogEvent{
+				Message:   aws.String(string(record)),
+				Timestamp: aws.Int64(m.Time().UnixMilli()),
+			})
+		}
+	}
+
+	if outdated > 0 {
+		c.Log.Warnf("Dropped %d metric(s) older than %v or more than %v in the future", outdated, maxEventAge, maxEventFuture)
+	}
+
+	// Events in a batch must be in chronological order and must not span
+	// more than the maximum duration
+	sort.SliceStable(events, func(i, j int) bool {
+		return *events[i].Timestamp < *events[j].Timestamp
+	})
+
+	for len(events) > 0 {
+		var n, size int
+		for n < len(events) && n < maxEventsPerCall {
+			if *events[n].Timestamp-*events[0].Timestamp >= maxBatchSpan.Milliseconds() {
+				break
+			}
+			eventSize := len(*events[n].Message) + eventOverheadBytes
+			if size+eventSize > maxBytesPerCall {
+				break
+			}
+			size += eventSize
+			n++
+		}
+
+		params := &cloudwatchlogs.PutLogEventsInput{
+			LogGroupName:  aws.String(c.EMFLogGroup),
+			LogStreamName: aws.String(c.EMFLogStream),
+			LogEvents:     events[:n],
+		}
+		if _, err := c.logs.PutLogEvents(context.Background(), params); err != nil {
+			c.Log.Errorf("Unable to write to CloudWatch Logs : %+v", err.Error())
+			return err
+		}
+		events = events[n:]
+	}
+
+	return nil
+}
+
+// buildEMFRecords creates the embedded metric format records for the metric.
+// All tags are added as properties to be queryable in CloudWatch Logs Insights
+// while only the tags selected by BuildDimensions are used as dimensions.
+func (c *CloudWatch) buildEMFRecords(m telegraf.Metric) ([][]byte, error) {
+	tags := m.Tags()
+	dimensions := make([]string, 0, len(tags))
+	for _, d := range BuildDimensions(tags) {
+		dimensions = append(dimensions, *d.Name)
+	}
+	storageResolution := c.storageResolution(m)
+
+	keys := make([]string, 0, len(m.FieldList()))
+	values := make(map[string]float64, len(m.FieldList()))
+	for _, field := range m.FieldList() {
+		v, ok := convert(field.Value)
+		if !ok {
+			continue
+		}
+		name := strings.Join([]string{m.Name(), field.Key}, "_")
+		keys = append(keys, name)
+		values[name] = v
+	}
+	sort.Strings(keys)
+
+	records := make([][]byte, 0, len(keys)/maxMetricsPerRecord+1)
+	for start := 0; start < len(keys); start += maxMetricsPerRecord {
+		end := min(start+maxMetricsPerRecord, len(keys))
+
+		directive := emfDirective{
+			Namespace:  c.Namespace,
+			Dimensions: [][]string{dimensions},
+			Metrics:    make([]emfMetric, 0, end-start),
+		}
+		record := make(map[string]interface{}, len(tags)+end-start+1)
+		for k, v := range tags {
+			if v != "" {
+				record[k] = v
+			}
+		}
+		for _, name := range keys[start:end] {
+			directive.Metrics = append(directive.Metrics, emfMetric{Name: name, StorageResolution: storageResolution})
+			record[name] = values[name]
+		}
+		record["_aws"] = emfMetadata{
+			Timestamp:         m.Time().UnixMilli(),
+			CloudWatchMetrics: []emfDirective{directive},
+		}
+
+		buf, err := json.Marshal(record)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, buf)
+	}
+
+	return records, nil
+}
@@ -12,6 +12,7 @@ import (
 	"math"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"text/template"
@@ -30,8 +31,10 @@ var sampleConfig string
 
 type Elasticsearch struct {
 	AuthBearerToken     config.Secret          `toml:"auth_bearer_token"`
+	DataStream          bool                   `toml:"data_stream"`
 	DefaultPipeline     string                 `toml:"default_pipeline"`
 	DefaultTagValue     string                 `toml:"default_tag_value"`
+	ECSTagMapping       map[string]string      `toml:"ecs_tag_mapping"`
 	EnableGzip          bool                   `toml:"enable_gzip"`
 	EnableSniffer       bool                   `toml:"enable_sniffer"`
 	FloatHandling       string                 `toml:"float_handling"`
@@ -39,10 +42,14 @@ type Elasticsearch struct {
 	ForceDocumentID     bool                   `toml:"force_document_id"`
 	HealthCheckInterval config.Duration        `toml:"health_check_interval"`
 	HealthCheckTimeout  config.Duration        `toml:"health_check_timeout"`
+	ILMPolicy           string                 `toml:"ilm_policy"`
 	IndexName           string                 `toml:"index_name"`
 	IndexTemplate       map[string]interface{} `toml:"template_index_settings"`
 	ManageTemplate      bool                   `toml:"manage_template"`
+	MaxRetries          int                    `toml:"max_retries"`
 	OverwriteTemplate   bool                   `toml:"overwrite_template"`
+	RetryBackoff        config.Duration        `toml:"retry_backoff"`
+	RetryMaxBackoff     config.Duration        `toml:"retry_max_backoff"`
 	UseOpTypeCreate     bool                   `toml:"use_optype_create"`
 	Username            config.Secret          `toml:"username"`
 	Password            config.Secret          `toml:"password"`
@@ -53,6 +60,7 @@ type Elasticsearch struct {
 	Headers             map[string]interface{} `toml:"headers"`
 	Log                 telegraf.Logger        `toml:"-"`
 	majorReleaseNumber  int
+	ecsPaths            map[string][]string
 	pipelineName        string
 	pipelineTagKeys     []string
 	tagKeys             []string
@@ -61,26 +69,12 @@ type Elasticsearch struct {
 	Client *elastic.Client
 }
 
-const telegrafTemplate = `
-{
-	{{ if (lt .Version 6) }}
-	"template": "{{.TemplatePattern}}",
-	{{ else }}
-	"index_patterns" : [ "{{.TemplatePattern}}" ],
-	{{ end }}
-	"settings": {
-		"index": {{.IndexTemplate}}
-	},
-	"mappings" : {
-		{{ if (lt .Version 7) }}
-		"metrics" : {
-			{{ if (lt .Version 6) }}
-			"_all": { "enabled": false },
-			{{ end }}
-		{{ end }}
+// telegrafMapping is shared by the legacy and the data stream templates
+const telegrafMapping = `{{ define "mapping" }}
 		"properties" : {
 			"@timestamp" : { "type" : "date" },
-			"measurement_name" : { "type" : "keyword" }
+			{{ range .ECSFields }}"{{ . }}" : { "type" : "keyword", "ignore_above" : 1024 },
+			{{ end }}"measurement_name" : { "type" : "keyword" }
 		},
 		"dynamic_templates": [
 			{
@@ -120,12 +114,50 @@ const telegrafTemplate = `
 				}
 			}
 		]
+{{ end }}`
+
+const telegrafTemplate = `
+{
+	{{ if (lt .Version 6) }}
+	"template": "{{.TemplatePattern}}",
+	{{ else }}
+	"index_patterns" : [ "{{.TemplatePattern}}" ],
+	{{ end }}
+	"settings": {
+		"index": {{.IndexTemplate}}
+	},
+	"mappings" : {
+		{{ if (lt .Version 7) }}
+		"metrics" : {
+			{{ if (lt .Version 6) }}
+			"_all": { "enabled": false },
+			{{ end }}
+		{{ end }}
+		{{ template "mapping" . }}
 		{{ if (lt .Version 7) }}
 		}
 		{{ end }}
 	}
 }`
 
+// telegrafDataStreamTemplate is a composable index template creating data
+// streams for the matching names. The priority has to be higher than the one
+// of the built-in "metrics-*-*" template.
+const telegrafDataStreamTemplate = `
+{
+	"index_patterns" : [ "{{.TemplatePattern}}" ],
+	"data_stream": {},
+	"priority": 200,
+	"template": {
+		"settings": {
+			"index": {{.IndexTemplate}}
+		},
+		"mappings" : {
+			{{ template "mapping" . }}
+		}
+	}
+}`
+
 const defaultTemplateIndexSettings = `
 {
 	"refresh_interval": "10s",
@@ -138,6 +170,7 @@ type templatePart struct {
 	TemplatePattern string
 	Version         int
 	IndexTemplate   string
+	ECSFields       []string
 }
 
 func (*Elasticsearch) SampleConfig() string {
@@ -158,6 +191,24 @@ func (a *Elasticsearch) Connect() error {
 		return fmt.Errorf("invalid float_handling type %q", a.FloatHandling)
 	}
 
+	if a.DataStream && strings.Contains(a.IndexName, "%") {
+		return errors.New("date specifiers are not supported in data stream names")
+	}
+	if a.ILMPolicy != "" && !a.ManageTemplate {
+		return errors.New("'ilm_policy' requires 'manage_template' to be enabled")
+	}
+
+	a.ecsPaths = make(map[string][]string, len(a.ECSTagMapping))
+	for tag, field := range a.ECSTagMapping {
+		switch {
+		case field == "" || strings.HasPrefix(field, ".") || strings.HasSuffix(field, ".") || strings.Contains(field, ".."):
+			return fmt.Errorf("invalid ECS field %q for tag %q", field, tag)
+		case field == "@timestamp", field == "measurement_name", field == "tag", strings.HasPrefix(field, "tag."):
+			return fmt.Errorf("ECS field %q for tag %q conflicts with the document structure", field, tag)
+		}
+		a.ecsPaths[tag] = strings.Split(field, ".")
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(a.Timeout))
 	defer cancel()
 
@@ -223,16 +274,30 @@ func (a *Elasticsearch) Connect() error {
 	}
 
 	// quit if ES version is not supported
-	majorReleaseNumber, err := strconv.Atoi(strings.Split(esVersion, ".")[0])
+	versionParts := strings.Split(esVersion, ".")
+	majorReleaseNumber, err := strconv.Atoi(versionParts[0])
 	if err != nil || majorReleaseNumber < 5 {
 		return fmt.Errorf("elasticsearch version not supported: %s", esVersion)
 	}
+	var minorReleaseNumber int
+	if len(versionParts) > 1 {
+		minorReleaseNumber, _ = strconv.Atoi(versionParts[1])
+	}
+	if a.DataStream && (majorReleaseNumber < 7 || majorReleaseNumber == 7 && minorReleaseNumber < 9) {
+		return fmt.Errorf("data streams require elasticsearch version 7.9 or later, found %s", esVersion)
+	}
 
 	a.Log.Infof("Elasticsearch version: %q", esVersion)
 
 	a.Client = client
 	a.majorReleaseNumber = majorReleaseNumber
 
+	if a.ILMPolicy != "" {
+		if err := a.checkILMPolicy(ctx); err != nil {
+			return err
+		}
+	}
+
 	if a.ManageTemplate {
 		err := a.manageTemplate(ctx)
 		if err != nil {
@@ -299,8 +364,7 @@ func (a *Elasticsearch) Write(metrics []telegraf.Metric) error {
 		return nil
 	}
 
-	bulkRequest := a.Client.Bulk()
-
+	requests := make([]elastic.BulkableRequest, 0, len(metrics))
 	for _, metric := range metrics {
 		var name = metric.Name()
 
@@ -334,9 +398,14 @@ func (a *Elasticsearch) Write(metrics []telegraf.Metric) error {
 		m["tag"] = metric.Tags()
 		m[name] = fields
 
+		if len(a.ecsPaths) > 0 {
+			a.mapECSFields(m, metric.Tags())
+		}
+
 		br := elastic.NewBulkIndexRequest().Index(indexName).Doc(m)
 
-		if a.UseOpTypeCreate {
+		// Data streams only accept the "create" operation
+		if a.UseOpTypeCreate || a.DataStream {
 			br.OpType("create")
 		}
 
@@ -355,33 +424,125 @@ func (a *Elasticsearch) Write(metrics []telegraf.Metric) error {
 			}
 		}
 
-		bulkRequest.Add(br)
+		requests = append(requests, br)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(a.Timeout))
-	defer cancel()
+	return a.writeBulk(requests)
+}
 
-	res, err := bulkRequest.Do(ctx)
+// writeBulk sends the requests and retries the requests rejected by
+// Elasticsearch due to back pressure with an exponential backoff
+func (a *Elasticsearch) writeBulk(requests []elastic.BulkableRequest) error {
+	backoff := time.Duration(a.RetryBackoff)
+	var failed int
+	for retry := 0; ; retry++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(a.Timeout))
+		res, err := a.Client.Bulk().Add(requests...).Do(ctx)
+		cancel()
+
+		var rejected []elastic.BulkableRequest
+		switch {
+		case elastic.IsStatusCode(err, http.StatusTooManyRequests):
+			rejected = requests
+		case err != nil:
+			return fmt.Errorf("error sending bulk request to Elasticsearch: %w", err)
+		case res.Errors:
+			// The items of the response are in the order of the requests
+			for i, item := range res.Items {
+				for _, r := range item {
+					if r.Status == http.StatusTooManyRequests && i < len(requests) {
+						rejected = append(rejected, requests[i])
+						continue
+					}
+					if r.Error == nil {
+						continue
+					}
+					if failed == 0 {
+						a.Log.Errorf(
+							"Elasticsearch indexing failure, id: %d, status: %d, error: %s, caused by: %s, %s",
+							i,
+							r.Status,
+							r.Error.Reason,
+							r.Error.CausedBy["reason"],
+							r.Error.CausedBy["type"],
+						)
+					}
+					failed++
+				}
+			}
+		}
 
-	if err != nil {
-		return fmt.Errorf("error sending bulk request to Elasticsearch: %w", err)
-	}
-
-	if res.Errors {
-		for id, err := range res.Failed() {
-			a.Log.Errorf(
-				"Elasticsearch indexing failure, id: %d, status: %d, error: %s, caused by: %s, %s",
-				id,
-				err.Status,
-				err.Error.Reason,
-				err.Error.CausedBy["reason"],
-				err.Error.CausedBy["type"],
-			)
+		if len(rejected) == 0 {
 			break
 		}
-		return fmt.Errorf("elasticsearch failed to index %d metrics", len(res.Failed()))
+		if retry >= a.MaxRetries {
+			return fmt.Errorf("elasticsearch rejected %d metrics due to back pressure, %d failed to index", len(rejected), failed)
+		}
+
+		a.Log.Debugf("Elasticsearch rejected %d metrics due to back pressure, retrying in %s", len(rejected), backoff)
+		time.Sleep(backoff)
+		backoff = min(2*backoff, time.Duration(a.RetryMaxBackoff))
+		requests = rejected
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("elasticsearch failed to index %d metrics", failed)
+	}
+	return nil
+}
+
+// mapECSFields moves the tags with an ECS mapping from the tags of the
+// document to the respective ECS field
+func (a *Elasticsearch) mapECSFields(doc map[string]interface{}, tags map[string]string) {
+	docTags := doc["tag"].(map[string]string)
+	for key, value := range tags {
+		path, found := a.ecsPaths[key]
+		if !found {
+			continue
+		}
+
+		// Walk the object path and create the missing objects on the way
+		parent := doc
+		for _, p := range path[:len(path)-1] {
+			child, found := parent[p]
+			if !found {
+				child = make(map[string]interface{})
+				parent[p] = child
+			}
+			obj, ok := child.(map[string]interface{})
+			if !ok {
+				parent = nil
+				break
+			}
+			parent = obj
+		}
+		if parent == nil {
+			a.Log.Debugf("Cannot map tag %q to ECS field %q, keeping tag", key, a.ECSTagMapping[key])
+			continue
+		}
+		if _, found := parent[path[len(path)-1]]; found {
+			a.Log.Debugf("ECS field %q of tag %q already exists, keeping tag", a.ECSTagMapping[key], key)
+			continue
+		}
+		parent[path[len(path)-1]] = value
+		delete(docTags, key)
 	}
+}
 
+// checkILMPolicy makes sure the configured lifecycle policy exists as
+// Elasticsearch silently ignores missing policies
+func (a *Elasticsearch) checkILMPolicy(ctx context.Context) error {
+	res, err := a.Client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method:       "GET",
+		Path:         "/_ilm/policy/" + url.PathEscape(a.ILMPolicy),
+		IgnoreErrors: []int{http.StatusNotFound},
+	})
+	if err != nil {
+		return fmt.Errorf("elasticsearch ILM policy check failed, policy name: %s, error: %w", a.ILMPolicy, err)
+	}
+	if res.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("elasticsearch ILM policy %q does not exist", a.ILMPolicy)
+	}
 	return nil
 }
 
@@ -390,7 +551,7 @@ func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
 		return errors.New("elasticsearch template_name configuration not defined")
 	}
 
-	templateExists, errExists := a.Client.IndexTemplateExists(a.TemplateName).Do(ctx)
+	templateExists, errExists := a.templateExists(ctx)
 
 	if errExists != nil {
 		return fmt.Errorf("elasticsearch template check failed, template name: %s, error: %w", a.TemplateName, errExists)
@@ -416,7 +577,7 @@ func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
 			return err
 		}
 
-		_, errCreateTemplate := a.Client.IndexPutTemplate(a.TemplateName).BodyString(data.String()).Do(ctx)
+		errCreateTemplate := a.putTemplate(ctx, data.String())
 		if errCreateTemplate != nil {
 			return fmt.Errorf("elasticsearch failed to create index template %s: %w", a.TemplateName, errCreateTemplate)
 		}
@@ -428,25 +589,80 @@ func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
 	return nil
 }
 
+// templateExists checks for the legacy index template or the composable
+// index template in data stream mode
+func (a *Elasticsearch) templateExists(ctx context.Context) (bool, error) {
+	if !a.DataStream {
+		return a.Client.IndexTemplateExists(a.TemplateName).Do(ctx)
+	}
+
+	res, err := a.Client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method:       "HEAD",
+		Path:         "/_index_template/" + url.PathEscape(a.TemplateName),
+		IgnoreErrors: []int{http.StatusNotFound},
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.StatusCode == http.StatusOK, nil
+}
+
+func (a *Elasticsearch) putTemplate(ctx context.Context, body string) error {
+	if !a.DataStream {
+		_, err := a.Client.IndexPutTemplate(a.TemplateName).BodyString(body).Do(ctx)
+		return err
+	}
+
+	_, err := a.Client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: "PUT",
+		Path:   "/_index_template/" + url.PathEscape(a.TemplateName),
+		Body:   body,
+	})
+	return err
+}
+
 func (a *Elasticsearch) createNewTemplate(templatePattern string) (*bytes.Buffer, error) {
 	var indexTemplate string
-	if a.IndexTemplate != nil {
-		data, err := json.Marshal(&a.IndexTemplate)
+	switch {
+	case a.IndexTemplate != nil || a.ILMPolicy != "":
+		settings := make(map[string]interface{}, len(a.IndexTemplate)+1)
+		if a.IndexTemplate != nil {
+			for k, v := range a.IndexTemplate {
+				settings[k] = v
+			}
+		} else if err := json.Unmarshal([]byte(defaultTemplateIndexSettings), &settings); err != nil {
+			return nil, err
+		}
+		if a.ILMPolicy != "" {
+			settings["lifecycle.name"] = a.ILMPolicy
+		}
+		data, err := json.Marshal(settings)
 		if err != nil {
 			return nil, fmt.Errorf("elasticsearch failed to create index settings for template %s: %w", a.TemplateName, err)
 		}
 		indexTemplate = string(data)
-	} else {
+	default:
 		indexTemplate = defaultTemplateIndexSettings
 	}
 
+	ecsFields := make([]string, 0, len(a.ECSTagMapping))
+	for _, field := range a.ECSTagMapping {
+		ecsFields = append(ecsFields, field)
+	}
+	slices.Sort(ecsFields)
+
 	tp := templatePart{
 		TemplatePattern: templatePattern + "*",
 		Version:         a.majorReleaseNumber,
 		IndexTemplate:   indexTemplate,
+		ECSFields:       slices.Compact(ecsFields),
 	}
 
-	t := template.Must(template.New("template").Parse(telegrafTemplate))
+	body := telegrafTemplate
+	if a.DataStream {
+		body = telegrafDataStreamTemplate
+	}
+	t := template.Must(template.Must(template.New("template").Parse(telegrafMapping)).Parse(body))
 	var tmpl bytes.Buffer
 
 	if err := t.Execute(&tmpl, tp); err != nil {
@@ -572,6 +788,9 @@ func init() {
 			Timeout:             config.Duration(time.Second * 5),
 			HealthCheckInterval: config.Duration(time.Second * 10),
 			HealthCheckTimeout:  config.Duration(time.Second * 1),
+			MaxRetries:          3,
+			RetryBackoff:        config.Duration(500 * time.Millisecond),
+			RetryMaxBackoff:     config.Duration(10 * time.Second),
 		}
 	})
 }
@@ -1,6 +1,7 @@
 package elasticsearch
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -980,3 +981,241 @@ type esTemplate struct {
 type esSettings struct {
 	Index map[string]interface{} `json:"index"`
 }
+
+func TestDataStream(t *testing.T) {
+	var template map[string]interface{}
+	var actions []map[string]map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_index_template/telegraf":
+			switch r.Method {
+			case http.MethodHead:
+				w.WriteHeader(http.StatusNotFound)
+			case http.MethodPut:
+				if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+					return
+				}
+				fmt.Fprint(w, `{"acknowledged": true}`)
+			}
+		case "/_ilm/policy/telegraf-metrics":
+			fmt.Fprint(w, `{"telegraf-metrics": {"policy": {}}}`)
+		case "/_bulk":
+			scanner := bufio.NewScanner(r.Body)
+			for i := 0; scanner.Scan(); i++ {
+				if i%2 != 0 {
+					continue
+				}
+				var action map[string]map[string]interface{}
+				if err := json.Unmarshal(scanner.Bytes(), &action); err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+					return
+				}
+				actions = append(actions, action)
+			}
+			fmt.Fprint(w, `{"errors": false, "items": [{"create": {"status": 201}}]}`)
+		default:
+			fmt.Fprint(w, `{"version": {"number": "8.11.1"}}`)
+		}
+	}))
+	defer ts.Close()
+
+	e := &Elasticsearch{
+		URLs:           []string{ts.URL},
+		IndexName:      "metrics-telegraf-{{host}}",
+		Timeout:        config.Duration(time.Second * 5),
+		DataStream:     true,
+		ManageTemplate: true,
+		TemplateName:   "telegraf",
+		ILMPolicy:      "telegraf-metrics",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, e.Connect())
+
+	// The composable template creates data streams using the ILM policy
+	require.Equal(t, []interface{}{"metrics-telegraf-*"}, template["index_patterns"])
+	require.Contains(t, template, "data_stream")
+	settings := template["template"].(map[string]interface{})["settings"].(map[string]interface{})
+	require.Equal(t, "telegraf-metrics", settings["index"].(map[string]interface{})["lifecycle.name"])
+
+	m := testutil.TestMetric(1.0)
+	m.AddTag("host", "server01")
+	require.NoError(t, e.Write([]telegraf.Metric{m}))
+	require.Len(t, actions, 1)
+	require.Contains(t, actions[0], "create")
+	require.Equal(t, "metrics-telegraf-server01", actions[0]["create"]["_index"])
+}
+
+func TestDataStreamInvalidConfig(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_ilm/policy/missing":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"type": "resource_not_found_exception"}, "status": 404}`)
+		default:
+			fmt.Fprint(w, `{"version": {"number": "7.8.0"}}`)
+		}
+	}))
+	defer ts.Close()
+
+	e := &Elasticsearch{
+		URLs:       []string{ts.URL},
+		IndexName:  "metrics-telegraf-%Y.%m.%d",
+		Timeout:    config.Duration(time.Second * 5),
+		DataStream: true,
+		Log:        testutil.Logger{},
+	}
+	require.ErrorContains(t, e.Connect(), "date specifiers are not supported")
+
+	e.IndexName = "metrics-telegraf"
+	require.ErrorContains(t, e.Connect(), "data streams require elasticsearch version 7.9 or later")
+
+	e.DataStream = false
+	e.ILMPolicy = "missing"
+	require.ErrorContains(t, e.Connect(), "'ilm_policy' requires 'manage_template'")
+
+	e.ManageTemplate = true
+	e.TemplateName = "telegraf"
+	require.ErrorContains(t, e.Connect(), `ILM policy "missing" does not exist`)
+}
+
+func TestBulkRetryOnBackPressure(t *testing.T) {
+	var requests int
+	var documents []int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_bulk":
+			requests++
+			var count int
+			scanner := bufio.NewScanner(r.Body)
+			for scanner.Scan() {
+				count++
+			}
+			documents = append(documents, count/2)
+
+			switch requests {
+			case 1:
+				// The whole request is rejected
+				w.WriteHeader(http.StatusTooManyRequests)
+				fmt.Fprint(w, `{"error": {"type": "es_rejected_execution_exception"}, "status": 429}`)
+			case 2:
+				// The second document is rejected
+				fmt.Fprint(w, `{"errors": true, "items": [`+
+					`{"index": {"status": 201}},`+
+					`{"index": {"status": 429, "error": {"type": "es_rejected_execution_exception"}}},`+
+					`{"index": {"status": 201}}]}`)
+			default:
+				fmt.Fprint(w, `{"errors": false, "items": [{"index": {"status": 201}}]}`)
+			}
+		default:
+			fmt.Fprint(w, `{"version": {"number": "8.11.1"}}`)
+		}
+	}))
+	defer ts.Close()
+
+	e := &Elasticsearch{
+		URLs:            []string{ts.URL},
+		IndexName:       "test",
+		Timeout:         config.Duration(time.Second * 5),
+		MaxRetries:      3,
+		RetryBackoff:    config.Duration(time.Millisecond),
+		RetryMaxBackoff: config.Duration(10 * time.Millisecond),
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, e.Connect())
+
+	metrics := []telegraf.Metric{
+		testutil.TestMetric(1),
+		testutil.TestMetric(2),
+		testutil.TestMetric(3),
+	}
+	require.NoError(t, e.Write(metrics))
+	require.Equal(t, []int{3, 3, 1}, documents)
+
+	// Give up after the configured number of retries
+	requests, documents = 0, nil
+	e.MaxRetries = 0
+	require.ErrorContains(t, e.Write(metrics), "elasticsearch rejected 3 metrics due to back pressure")
+	require.Equal(t, []int{3}, documents)
+}
+
+func TestECSTagMapping(t *testing.T) {
+	var template map[string]interface{}
+	var docs []map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_template/telegraf":
+			switch r.Method {
+			case http.MethodHead:
+				w.WriteHeader(http.StatusNotFound)
+			case http.MethodPut:
+				if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+					return
+				}
+				fmt.Fprint(w, `{"acknowledged": true}`)
+			}
+		case "/_bulk":
+			scanner := bufio.NewScanner(r.Body)
+			for i := 0; scanner.Scan(); i++ {
+				if i%2 == 0 {
+					continue
+				}
+				var doc map[string]interface{}
+				if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+					w.WriteHeader(http.StatusInternalServerError)
+					t.Error(err)
+					return
+				}
+				docs = append(docs, doc)
+			}
+			fmt.Fprint(w, `{"errors": false, "items": [{"index": {"status": 201}}]}`)
+		default:
+			fmt.Fprint(w, `{"version": {"number": "8.11.1"}}`)
+		}
+	}))
+	defer ts.Close()
+
+	e := &Elasticsearch{
+		URLs:           []string{ts.URL},
+		IndexName:      "telegraf-%Y.%m.%d",
+		Timeout:        config.Duration(time.Second * 5),
+		ManageTemplate: true,
+		TemplateName:   "telegraf",
+		ECSTagMapping: map[string]string{
+			"host":       "host.name",
+			"agent_host": "host.ip",
+			"service":    "service.name",
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, e.Connect())
+
+	// The ECS fields are mapped as keywords
+	properties := template["mappings"].(map[string]interface{})["properties"].(map[string]interface{})
+	require.Contains(t, properties, "host.name")
+	require.Contains(t, properties, "host.ip")
+	require.Contains(t, properties, "service.name")
+
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{
+			"host":       "server01",
+			"agent_host": "10.0.0.1",
+			"cpu":        "cpu0",
+		},
+		map[string]interface{}{"usage_idle": 99.5},
+		time.Unix(0, 0),
+	)
+	require.NoError(t, e.Write([]telegraf.Metric{m}))
+	require.Len(t, docs, 1)
+	require.Equal(t, map[string]interface{}{"name": "server01", "ip": "10.0.0.1"}, docs[0]["host"])
+	require.Equal(t, map[string]interface{}{"cpu": "cpu0"}, docs[0]["tag"])
+	require.Equal(t, map[string]interface{}{"usage_idle": 99.5}, docs[0]["cpu"])
+
+	e.ECSTagMapping = map[string]string{"host": "tag.host"}
+	require.ErrorContains(t, e.Connect(), "conflicts with the document structure")
+}
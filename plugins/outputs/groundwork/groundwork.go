@@ -7,16 +7,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gwos/tcg/sdk/clients"
+	tcgerr "github.com/gwos/tcg/sdk/errors"
 	"github.com/gwos/tcg/sdk/log"
 	"github.com/gwos/tcg/sdk/transit"
 	"github.com/hashicorp/go-uuid"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/slog"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -25,22 +31,88 @@ import (
 var sampleConfig string
 
 type metricMeta struct {
-	group    string
-	resource string
+	groups        []string
+	serviceGroups []string
+	groupType     string
+	resource      string
+	properties    map[string]transit.TypedValue
+}
+
+// groupKey identifies a group as GroundWork allows groups of different type
+// to share the same name
+type groupKey struct {
+	name      string
+	groupType transit.GroupType
+}
+
+type groupSettings struct {
+	Name string `toml:"name"`
+	Type string `toml:"type"`
+}
+
+// keySettings contains the names of the tags and fields used as control
+// metadata of the service instead of data
+type keySettings struct {
+	Service        string   `toml:"service"`
+	Status         string   `toml:"status"`
+	Message        string   `toml:"message"`
+	UnitType       string   `toml:"unit_type"`
+	Critical       string   `toml:"critical"`
+	Warning        string   `toml:"warning"`
+	CriticalSuffix string   `toml:"critical_suffix"`
+	WarningSuffix  string   `toml:"warning_suffix"`
+	Ignore         []string `toml:"ignore"`
+}
+
+// withDefaults returns the settings with the default names for unset keys
+func (k keySettings) withDefaults() keySettings {
+	for _, d := range []struct {
+		key      *string
+		fallback string
+	}{
+		{&k.Service, "service"},
+		{&k.Status, "status"},
+		{&k.Message, "message"},
+		{&k.UnitType, "unitType"},
+		{&k.Critical, "critical"},
+		{&k.Warning, "warning"},
+		{&k.CriticalSuffix, "_cr"},
+		{&k.WarningSuffix, "_wn"},
+	} {
+		if *d.key == "" {
+			*d.key = d.fallback
+		}
+	}
+	return k
 }
 
 type Groundwork struct {
-	Server              string          `toml:"url"`
-	AgentID             string          `toml:"agent_id"`
-	Username            config.Secret   `toml:"username"`
-	Password            config.Secret   `toml:"password"`
-	DefaultAppType      string          `toml:"default_app_type"`
-	DefaultHost         string          `toml:"default_host"`
-	DefaultServiceState string          `toml:"default_service_state"`
-	GroupTag            string          `toml:"group_tag"`
-	ResourceTag         string          `toml:"resource_tag"`
-	Log                 telegraf.Logger `toml:"-"`
-	client              clients.GWClient
+	Server                string          `toml:"url"`
+	AgentID               string          `toml:"agent_id"`
+	Username              config.Secret   `toml:"username"`
+	Password              config.Secret   `toml:"password"`
+	DefaultAppType        string          `toml:"default_app_type"`
+	DefaultHost           string          `toml:"default_host"`
+	DefaultServiceState   string          `toml:"default_service_state"`
+	GroupTag              string          `toml:"group_tag"`
+	GroupTypeTag          string          `toml:"group_type_tag"`
+	ServiceGroupTag       string          `toml:"service_group_tag"`
+	DefaultGroupType      string          `toml:"default_group_type"`
+	Groups                []groupSettings `toml:"group"`
+	ResourceTag           string          `toml:"resource_tag"`
+	OwnerTag              string          `toml:"owner_tag"`
+	SendInventoryInterval config.Duration `toml:"send_inventory_interval"`
+	Keys                  keySettings     `toml:"keys"`
+	UnknownTags           string          `toml:"unknown_tags"`
+	StringFields          string          `toml:"string_fields"`
+	Log                   telegraf.Logger `toml:"-"`
+	client                clients.GWClient
+	clientLock            sync.RWMutex
+
+	groups    map[string]groupSettings
+	inventory *inventory
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
 }
 
 func (*Groundwork) SampleConfig() string {
@@ -72,6 +144,45 @@ func (g *Groundwork) Init() error {
 	if !validStatus(g.DefaultServiceState) {
 		return errors.New(`invalid "default_service_state" provided`)
 	}
+	if g.DefaultGroupType == "" {
+		g.DefaultGroupType = string(transit.HostGroup)
+	}
+	if !validGroupType(g.DefaultGroupType) {
+		return fmt.Errorf(`invalid "default_group_type" %q provided`, g.DefaultGroupType)
+	}
+	g.groups = make(map[string]groupSettings, len(g.Groups))
+	for _, group := range g.Groups {
+		if group.Name == "" {
+			return errors.New(`no "name" provided for group`)
+		}
+		if group.Type != "" && !validGroupType(group.Type) {
+			return fmt.Errorf(`invalid "type" %q provided for group %q`, group.Type, group.Name)
+		}
+		if _, found := g.groups[group.Name]; found {
+			return fmt.Errorf("duplicate settings for group %q", group.Name)
+		}
+		g.groups[group.Name] = group
+	}
+	switch g.UnknownTags {
+	case "":
+		g.UnknownTags = "service_property"
+	case "service_property", "resource_property", "drop":
+	default:
+		return fmt.Errorf(`invalid "unknown_tags" %q provided`, g.UnknownTags)
+	}
+	switch g.StringFields {
+	case "":
+		g.StringFields = "warn"
+	case "warn", "drop", "service_property":
+	default:
+		return fmt.Errorf(`invalid "string_fields" %q provided`, g.StringFields)
+	}
+	if g.SendInventoryInterval < 0 {
+		return errors.New(`invalid "send_inventory_interval" provided`)
+	}
+	if g.SendInventoryInterval > 0 {
+		g.inventory = newInventory()
+	}
 
 	username, err := g.Username.Get()
 	if err != nil {
@@ -104,58 +215,141 @@ func (g *Groundwork) Init() error {
 func (g *Groundwork) Connect() error {
 	err := g.client.Connect()
 	if err != nil {
-		return fmt.Errorf("could not login: %w", err)
+		// Authentication failures will not resolve by retrying while all other
+		// errors, e.g. GroundWork not being up yet, are worth another try
+		return &internal.StartupError{
+			Err:   fmt.Errorf("could not login: %w", err),
+			Retry: !errors.Is(err, tcgerr.ErrUnauthorized),
+		}
+	}
+
+	if g.inventory != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		g.cancel = cancel
+
+		g.wg.Add(1)
+		go func() {
+			defer g.wg.Done()
+			g.syncInventory(ctx)
+		}()
 	}
+
 	return nil
 }
 
 func (g *Groundwork) Close() error {
+	if g.cancel != nil {
+		g.cancel()
+		g.wg.Wait()
+		g.cancel = nil
+	}
+
+	g.clientLock.Lock()
 	err := g.client.Disconnect()
+	g.clientLock.Unlock()
 	if err != nil {
 		return fmt.Errorf("could not logout: %w", err)
 	}
 	return nil
 }
 
+// SecretsRotated logs in again using the updated credentials
+func (g *Groundwork) SecretsRotated() error {
+	username, err := g.Username.Get()
+	if err != nil {
+		return fmt.Errorf("getting username failed: %w", err)
+	}
+	password, err := g.Password.Get()
+	if err != nil {
+		username.Destroy()
+		return fmt.Errorf("getting password failed: %w", err)
+	}
+
+	g.clientLock.Lock()
+	defer g.clientLock.Unlock()
+
+	g.client.GWConnection.UserName = username.String()
+	g.client.GWConnection.Password = password.String()
+	username.Destroy()
+	password.Destroy()
+
+	if err := g.client.Connect(); err != nil {
+		return fmt.Errorf("could not login: %w", err)
+	}
+	g.Log.Info("Logged in with rotated credentials")
+
+	return nil
+}
+
 func (g *Groundwork) Write(metrics []telegraf.Metric) error {
-	groupMap := make(map[string][]transit.ResourceRef)
+	groupMap := make(map[groupKey][]transit.ResourceRef)
+	groupMembers := make(map[groupKey]map[transit.ResourceRef]bool)
 	resourceToServicesMap := make(map[string][]transit.MonitoredService)
+	resourceProperties := make(map[string]map[string]transit.TypedValue)
 	for _, metric := range metrics {
 		meta, service := g.parseMetric(metric)
 		resource := meta.resource
 		resourceToServicesMap[resource] = append(resourceToServicesMap[resource], *service)
+		if len(meta.properties) > 0 {
+			if resourceProperties[resource] == nil {
+				resourceProperties[resource] = make(map[string]transit.TypedValue, len(meta.properties))
+			}
+			for k, v := range meta.properties {
+				resourceProperties[resource][k] = v
+			}
+		}
+
+		for _, group := range meta.groups {
+			key, ref := g.groupMember(group, meta, service)
+			if groupMembers[key] == nil {
+				groupMembers[key] = make(map[transit.ResourceRef]bool)
+			}
+			if groupMembers[key][ref] {
+				continue
+			}
+			groupMembers[key][ref] = true
+			groupMap[key] = append(groupMap[key], ref)
+		}
 
-		group := meta.group
-		if len(group) != 0 {
-			resRef := transit.ResourceRef{
-				Name: resource,
-				Type: transit.ResourceTypeHost,
+		// Service groups given by the service group tag group the services
+		// across hosts independent of the group type settings
+		for _, group := range meta.serviceGroups {
+			key := groupKey{name: group, groupType: transit.ServiceGroup}
+			ref := serviceRef(meta, service)
+			if groupMembers[key] == nil {
+				groupMembers[key] = make(map[transit.ResourceRef]bool)
 			}
-			if refs, ok := groupMap[group]; ok {
-				refs = append(refs, resRef)
-				groupMap[group] = refs
-			} else {
-				groupMap[group] = []transit.ResourceRef{resRef}
+			if groupMembers[key][ref] {
+				continue
 			}
+			groupMembers[key][ref] = true
+			groupMap[key] = append(groupMap[key], ref)
 		}
 	}
 
 	groups := make([]transit.ResourceGroup, 0, len(groupMap))
-	for groupName, refs := range groupMap {
+	for key, refs := range groupMap {
 		groups = append(groups, transit.ResourceGroup{
-			GroupName: groupName,
+			GroupName: key.name,
 			Resources: refs,
-			Type:      transit.HostGroup,
+			Type:      key.groupType,
 		})
 	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].GroupName != groups[j].GroupName {
+			return groups[i].GroupName < groups[j].GroupName
+		}
+		return groups[i].Type < groups[j].Type
+	})
 
 	resources := make([]transit.MonitoredResource, 0, len(resourceToServicesMap))
 	for resourceName, services := range resourceToServicesMap {
 		resources = append(resources, transit.MonitoredResource{
 			BaseResource: transit.BaseResource{
 				BaseInfo: transit.BaseInfo{
-					Name: resourceName,
-					Type: transit.ResourceTypeHost,
+					Name:       resourceName,
+					Type:       transit.ResourceTypeHost,
+					Properties: resourceProperties[resourceName],
 				},
 			},
 			MonitoredInfo: transit.MonitoredInfo{
@@ -166,18 +360,16 @@ func (g *Groundwork) Write(metrics []telegraf.Metric) error {
 		})
 	}
 
-	traceToken, err := uuid.GenerateUUID()
+	if g.inventory != nil {
+		g.inventory.update(resources, groups)
+	}
+
+	tracerContext, err := g.newTracerContext()
 	if err != nil {
 		return err
 	}
 	requestJSON, err := json.Marshal(transit.ResourcesWithServicesRequest{
-		Context: &transit.TracerContext{
-			AppType:    g.DefaultAppType,
-			AgentID:    g.AgentID,
-			TraceToken: traceToken,
-			TimeStamp:  transit.NewTimestamp(),
-			Version:    transit.ModelVersion,
-		},
+		Context:   tracerContext,
 		Resources: resources,
 		Groups:    groups,
 	})
@@ -186,14 +378,117 @@ func (g *Groundwork) Write(metrics []telegraf.Metric) error {
 		return err
 	}
 
+	g.clientLock.RLock()
 	_, err = g.client.SendResourcesWithMetrics(context.Background(), requestJSON)
+	g.clientLock.RUnlock()
+	if err != nil {
+		return fmt.Errorf("error while sending: %w", err)
+	}
+
+	return nil
+}
+
+// groupMember determines the type of the given group and the reference of the
+// metric's host or service in the group. The group settings take precedence
+// over the type given by tags.
+func (g *Groundwork) groupMember(group string, meta metricMeta, service *transit.MonitoredService) (groupKey, transit.ResourceRef) {
+	groupType := g.DefaultGroupType
+	if meta.groupType != "" {
+		if validGroupType(meta.groupType) {
+			groupType = meta.groupType
+		} else {
+			g.Log.Warnf("Invalid group type %q for group %q, using %q", meta.groupType, group, groupType)
+		}
+	}
+	if settings, found := g.groups[group]; found && settings.Type != "" {
+		groupType = settings.Type
+	}
+
+	key := groupKey{name: group, groupType: transit.GroupType(groupType)}
+
+	// Service groups contain the services owned by their host
+	if key.groupType == transit.ServiceGroup {
+		return key, serviceRef(meta, service)
+	}
+
+	return key, transit.ResourceRef{
+		Name: meta.resource,
+		Type: transit.ResourceTypeHost,
+	}
+}
+
+// serviceRef returns the reference of the metric's service as member of a
+// service group
+func serviceRef(meta metricMeta, service *transit.MonitoredService) transit.ResourceRef {
+	return transit.ResourceRef{
+		Name:  service.Name,
+		Type:  transit.ResourceTypeService,
+		Owner: meta.resource,
+	}
+}
+
+// syncInventory periodically sends the full inventory of hosts, services and
+// groups seen so far until the context is cancelled.
+func (g *Groundwork) syncInventory(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(g.SendInventoryInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := g.sendInventory(ctx); err != nil {
+				g.Log.Errorf("Synchronizing inventory failed: %v", err)
+			}
+		}
+	}
+}
+
+func (g *Groundwork) sendInventory(ctx context.Context) error {
+	resources, groups := g.inventory.snapshot()
+	if len(resources) == 0 {
+		return nil
+	}
+
+	tracerContext, err := g.newTracerContext()
+	if err != nil {
+		return err
+	}
+	requestJSON, err := json.Marshal(transit.InventoryRequest{
+		Context:   tracerContext,
+		Resources: resources,
+		Groups:    groups,
+	})
+	if err != nil {
+		return err
+	}
+
+	g.clientLock.RLock()
+	_, err = g.client.SynchronizeInventory(ctx, requestJSON)
+	g.clientLock.RUnlock()
 	if err != nil {
 		return fmt.Errorf("error while sending: %w", err)
 	}
+	g.Log.Debugf("Synchronized inventory of %d hosts and %d groups", len(resources), len(groups))
 
 	return nil
 }
 
+func (g *Groundwork) newTracerContext() (*transit.TracerContext, error) {
+	traceToken, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+	return &transit.TracerContext{
+		AppType:    g.DefaultAppType,
+		AgentID:    g.AgentID,
+		TraceToken: traceToken,
+		TimeStamp:  transit.NewTimestamp(),
+		Version:    transit.ModelVersion,
+	}, nil
+}
+
 func init() {
 	outputs.Add("groundwork", func() telegraf.Output {
 		return &Groundwork{
@@ -202,25 +497,51 @@ func init() {
 			DefaultHost:         "telegraf",
 			DefaultAppType:      "TELEGRAF",
 			DefaultServiceState: string(transit.ServiceOk),
+			DefaultGroupType:    string(transit.HostGroup),
 		}
 	})
 }
 
 func (g *Groundwork) parseMetric(metric telegraf.Metric) (metricMeta, *transit.MonitoredService) {
-	group, _ := metric.GetTag(g.GroupTag)
+	keys := g.Keys.withDefaults()
+
+	var groups []string
+	if v, ok := metric.GetTag(g.GroupTag); ok {
+		// Hosts might be member of multiple groups
+		groups = splitGroups(v)
+	}
+
+	var serviceGroups []string
+	if g.ServiceGroupTag != "" {
+		if v, ok := metric.GetTag(g.ServiceGroupTag); ok {
+			serviceGroups = splitGroups(v)
+		}
+	}
+
+	var groupType string
+	if g.GroupTypeTag != "" {
+		groupType, _ = metric.GetTag(g.GroupTypeTag)
+	}
 
 	resource := g.DefaultHost
 	if v, ok := metric.GetTag(g.ResourceTag); ok {
 		resource = v
 	}
 
+	owner := resource
+	if g.OwnerTag != "" {
+		if v, ok := metric.GetTag(g.OwnerTag); ok {
+			owner = v
+		}
+	}
+
 	service := metric.Name()
-	if v, ok := metric.GetTag("service"); ok {
+	if v, ok := metric.GetTag(keys.Service); ok {
 		service = v
 	}
 
 	unitType := string(transit.UnitCounter)
-	if v, ok := metric.GetTag("unitType"); ok {
+	if v, ok := metric.GetTag(keys.UnitType); ok {
 		unitType = v
 	}
 
@@ -230,7 +551,7 @@ func (g *Groundwork) parseMetric(metric telegraf.Metric) (metricMeta, *transit.M
 		BaseInfo: transit.BaseInfo{
 			Name:       service,
 			Type:       transit.ResourceTypeService,
-			Owner:      resource,
+			Owner:      owner,
 			Properties: make(map[string]transit.TypedValue),
 		},
 		MonitoredInfo: transit.MonitoredInfo{
@@ -242,26 +563,40 @@ func (g *Groundwork) parseMetric(metric telegraf.Metric) (metricMeta, *transit.M
 	}
 
 	knownKey := func(t string) bool {
-		if strings.HasSuffix(t, "_cr") ||
-			strings.HasSuffix(t, "_wn") ||
-			t == "critical" ||
-			t == "warning" ||
+		if strings.HasSuffix(t, keys.CriticalSuffix) ||
+			strings.HasSuffix(t, keys.WarningSuffix) ||
+			t == keys.Critical ||
+			t == keys.Warning ||
 			t == g.GroupTag ||
 			t == g.ResourceTag ||
-			t == "service" ||
-			t == "status" ||
-			t == "message" ||
-			t == "unitType" {
+			(g.OwnerTag != "" && t == g.OwnerTag) ||
+			(g.GroupTypeTag != "" && t == g.GroupTypeTag) ||
+			(g.ServiceGroupTag != "" && t == g.ServiceGroupTag) ||
+			t == keys.Service ||
+			t == keys.Status ||
+			t == keys.Message ||
+			t == keys.UnitType ||
+			slices.Contains(keys.Ignore, t) {
 			return true
 		}
 		return false
 	}
 
+	var resourceProperties map[string]transit.TypedValue
 	for _, tag := range metric.TagList() {
 		if knownKey(tag.Key) {
 			continue
 		}
-		serviceObject.Properties[tag.Key] = *transit.NewTypedValue(tag.Value)
+		switch g.UnknownTags {
+		case "drop":
+		case "resource_property":
+			if resourceProperties == nil {
+				resourceProperties = make(map[string]transit.TypedValue)
+			}
+			resourceProperties[tag.Key] = *transit.NewTypedValue(tag.Value)
+		default:
+			serviceObject.Properties[tag.Key] = *transit.NewTypedValue(tag.Value)
+		}
 	}
 
 	for _, field := range metric.FieldList() {
@@ -269,9 +604,18 @@ func (g *Groundwork) parseMetric(metric telegraf.Metric) (metricMeta, *transit.M
 			continue
 		}
 
-		switch field.Value.(type) {
+		switch v := field.Value.(type) {
 		case string, []byte:
-			g.Log.Warnf("string values are not supported, skipping field %s: %q", field.Key, field.Value)
+			switch g.StringFields {
+			case "drop":
+			case "service_property":
+				if b, ok := v.([]byte); ok {
+					v = string(b)
+				}
+				serviceObject.Properties[field.Key] = *transit.NewTypedValue(v)
+			default:
+				g.Log.Warnf("string values are not supported, skipping field %s: %q", field.Key, field.Value)
+			}
 			continue
 		}
 
@@ -286,7 +630,7 @@ func (g *Groundwork) parseMetric(metric telegraf.Metric) (metricMeta, *transit.M
 			if tv := transit.NewTypedValue(v); tv != nil {
 				thresholds = append(thresholds, transit.ThresholdValue{
 					SampleType: transit.Critical,
-					Label:      field.Key + "_cr",
+					Label:      field.Key + keys.CriticalSuffix,
 					Value:      tv,
 				})
 			}
@@ -295,31 +639,31 @@ func (g *Groundwork) parseMetric(metric telegraf.Metric) (metricMeta, *transit.M
 			if tv := transit.NewTypedValue(v); tv != nil {
 				thresholds = append(thresholds, transit.ThresholdValue{
 					SampleType: transit.Warning,
-					Label:      field.Key + "_wn",
+					Label:      field.Key + keys.WarningSuffix,
 					Value:      tv,
 				})
 			}
 		}
-		if v, ok := metric.GetTag(field.Key + "_cr"); ok {
+		if v, ok := metric.GetTag(field.Key + keys.CriticalSuffix); ok {
 			if v, err := strconv.ParseFloat(v, 64); err == nil {
 				addCriticalThreshold(v)
 			}
-		} else if v, ok := metric.GetTag("critical"); ok {
+		} else if v, ok := metric.GetTag(keys.Critical); ok {
 			if v, err := strconv.ParseFloat(v, 64); err == nil {
 				addCriticalThreshold(v)
 			}
-		} else if v, ok := metric.GetField(field.Key + "_cr"); ok {
+		} else if v, ok := metric.GetField(field.Key + keys.CriticalSuffix); ok {
 			addCriticalThreshold(v)
 		}
-		if v, ok := metric.GetTag(field.Key + "_wn"); ok {
+		if v, ok := metric.GetTag(field.Key + keys.WarningSuffix); ok {
 			if v, err := strconv.ParseFloat(v, 64); err == nil {
 				addWarningThreshold(v)
 			}
-		} else if v, ok := metric.GetTag("warning"); ok {
+		} else if v, ok := metric.GetTag(keys.Warning); ok {
 			if v, err := strconv.ParseFloat(v, 64); err == nil {
 				addWarningThreshold(v)
 			}
-		} else if v, ok := metric.GetField(field.Key + "_wn"); ok {
+		} else if v, ok := metric.GetField(field.Key + keys.WarningSuffix); ok {
 			addWarningThreshold(v)
 		}
 
@@ -333,9 +677,9 @@ func (g *Groundwork) parseMetric(metric telegraf.Metric) (metricMeta, *transit.M
 		})
 	}
 
-	if m, ok := metric.GetTag("message"); ok {
+	if m, ok := metric.GetTag(keys.Message); ok {
 		serviceObject.LastPluginOutput = strings.ToValidUTF8(m, "?")
-	} else if m, ok := metric.GetField("message"); ok {
+	} else if m, ok := metric.GetField(keys.Message); ok {
 		switch m := m.(type) {
 		case string:
 			serviceObject.LastPluginOutput = strings.ToValidUTF8(m, "?")
@@ -347,11 +691,11 @@ func (g *Groundwork) parseMetric(metric telegraf.Metric) (metricMeta, *transit.M
 	}
 
 	func() {
-		if s, ok := metric.GetTag("status"); ok && validStatus(s) {
+		if s, ok := metric.GetTag(keys.Status); ok && validStatus(s) {
 			serviceObject.Status = transit.MonitorStatus(s)
 			return
 		}
-		if s, ok := metric.GetField("status"); ok {
+		if s, ok := metric.GetField(keys.Status); ok {
 			status := g.DefaultServiceState
 			switch s := s.(type) {
 			case string:
@@ -372,7 +716,24 @@ func (g *Groundwork) parseMetric(metric telegraf.Metric) (metricMeta, *transit.M
 		serviceObject.Status = status
 	}()
 
-	return metricMeta{resource: resource, group: group}, &serviceObject
+	return metricMeta{
+		groups:        groups,
+		serviceGroups: serviceGroups,
+		groupType:     groupType,
+		resource:      resource,
+		properties:    resourceProperties,
+	}, &serviceObject
+}
+
+// splitGroups returns the non-empty group names of a comma-separated list
+func splitGroups(v string) []string {
+	var groups []string
+	for _, group := range strings.Split(v, ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups
 }
 
 func validStatus(status string) bool {
@@ -383,3 +744,11 @@ func validStatus(status string) bool {
 	}
 	return false
 }
+
+func validGroupType(groupType string) bool {
+	switch transit.GroupType(groupType) {
+	case transit.HostGroup, transit.ServiceGroup, transit.CustomGroup:
+		return true
+	}
+	return false
+}
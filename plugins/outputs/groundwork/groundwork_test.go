@@ -7,7 +7,9 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gwos/tcg/sdk/clients"
 	"github.com/gwos/tcg/sdk/transit"
@@ -15,6 +17,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/logger"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -399,3 +402,505 @@ func TestWriteWithTags(t *testing.T) {
 
 	server.Close()
 }
+
+func TestConnectStartupError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		retry  bool
+	}{
+		{
+			name:   "unavailable",
+			status: http.StatusServiceUnavailable,
+			retry:  true,
+		},
+		{
+			name:   "gateway timeout",
+			status: http.StatusGatewayTimeout,
+			retry:  true,
+		},
+		{
+			name:   "unauthorized",
+			status: http.StatusUnauthorized,
+			retry:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			plugin := Groundwork{
+				Server:              server.URL,
+				AgentID:             defaultTestAgentID,
+				Username:            config.NewSecret([]byte(`tu ser`)),
+				Password:            config.NewSecret([]byte(`pu ser`)),
+				DefaultAppType:      defaultAppType,
+				DefaultHost:         defaultHost,
+				DefaultServiceState: string(transit.ServiceOk),
+				ResourceTag:         "host",
+				Log:                 testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			err := plugin.Connect()
+			require.ErrorContains(t, err, "could not login")
+
+			var serr *internal.StartupError
+			require.ErrorAs(t, err, &serr)
+			require.Equal(t, tt.retry, serr.Retry)
+		})
+	}
+}
+
+func TestParseMetricOwner(t *testing.T) {
+	m := testutil.TestMetric(1.0, "FloatMetric")
+	m.AddTag("host", "Host01")
+	m.AddTag("team", "Team01")
+
+	plugin := Groundwork{
+		DefaultHost:         defaultHost,
+		DefaultServiceState: string(transit.ServiceOk),
+		ResourceTag:         "host",
+		Log:                 testutil.Logger{},
+	}
+
+	// Without an owner tag the resource owns the service
+	_, service := plugin.parseMetric(m)
+	require.Equal(t, "Host01", service.Owner)
+	require.Contains(t, service.Properties, "team")
+
+	// The owner tag is used as owner and not added to the properties
+	plugin.OwnerTag = "team"
+	_, service = plugin.parseMetric(m)
+	require.Equal(t, "Team01", service.Owner)
+	require.NotContains(t, service.Properties, "team")
+
+	// Metrics missing the owner tag fall back to the resource
+	m.RemoveTag("team")
+	_, service = plugin.parseMetric(m)
+	require.Equal(t, "Host01", service.Owner)
+}
+
+func TestParseMetricKeys(t *testing.T) {
+	m := testutil.MustMetric(
+		"check",
+		map[string]string{
+			"host":     "Host01",
+			"svc":      "Service01",
+			"state":    string(transit.ServiceWarning),
+			"internal": "yes",
+			"team":     "Team01",
+		},
+		map[string]interface{}{
+			"value":      1.0,
+			"value_crit": 3.0,
+			"output":     "disk almost full",
+			"status":     "not a control field",
+			"mountpoint": "/var",
+		},
+		time.Unix(0, 0),
+	)
+
+	plugin := Groundwork{
+		DefaultHost:         defaultHost,
+		DefaultServiceState: string(transit.ServiceOk),
+		ResourceTag:         "host",
+		Keys: keySettings{
+			Service:        "svc",
+			Status:         "state",
+			Message:        "output",
+			CriticalSuffix: "_crit",
+			Ignore:         []string{"internal"},
+		},
+		Log: testutil.Logger{},
+	}
+
+	meta, service := plugin.parseMetric(m)
+	require.Empty(t, meta.properties)
+	require.Equal(t, "Service01", service.Name)
+	require.Equal(t, transit.ServiceWarning, service.Status)
+	require.Equal(t, "disk almost full", service.LastPluginOutput)
+	require.Len(t, service.Metrics, 1)
+	require.Equal(t, "value", service.Metrics[0].MetricName)
+	require.Len(t, service.Metrics[0].Thresholds, 1)
+	require.Equal(t, "value_crit", service.Metrics[0].Thresholds[0].Label)
+	require.Equal(t, map[string]transit.TypedValue{"team": *transit.NewTypedValue("Team01")}, service.Properties)
+
+	// Pass unknown tags to the resource and string fields to the service
+	plugin.UnknownTags = "resource_property"
+	plugin.StringFields = "service_property"
+	meta, service = plugin.parseMetric(m)
+	require.Equal(t, map[string]transit.TypedValue{"team": *transit.NewTypedValue("Team01")}, meta.properties)
+	require.Equal(t, map[string]transit.TypedValue{
+		"status":     *transit.NewTypedValue("not a control field"),
+		"mountpoint": *transit.NewTypedValue("/var"),
+	}, service.Properties)
+
+	// Drop unknown tags and string fields
+	plugin.UnknownTags = "drop"
+	plugin.StringFields = "drop"
+	meta, service = plugin.parseMetric(m)
+	require.Empty(t, meta.properties)
+	require.Empty(t, service.Properties)
+	require.Len(t, service.Metrics, 1)
+}
+
+func TestInitFailPolicies(t *testing.T) {
+	tests := []struct {
+		name         string
+		unknownTags  string
+		stringFields string
+		expected     string
+	}{
+		{
+			name:        "invalid unknown tags",
+			unknownTags: "host_property",
+			expected:    `invalid "unknown_tags" "host_property" provided`,
+		},
+		{
+			name:         "invalid string fields",
+			stringFields: "metric",
+			expected:     `invalid "string_fields" "metric" provided`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := Groundwork{
+				Server:              "http://localhost",
+				AgentID:             defaultTestAgentID,
+				Username:            config.NewSecret([]byte(`tu ser`)),
+				Password:            config.NewSecret([]byte(`pu ser`)),
+				DefaultAppType:      defaultAppType,
+				DefaultHost:         defaultHost,
+				DefaultServiceState: string(transit.ServiceOk),
+				ResourceTag:         "host",
+				UnknownTags:         tt.unknownTags,
+				StringFields:        tt.stringFields,
+				Log:                 testutil.Logger{},
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestSendInventory(t *testing.T) {
+	var inventory transit.InventoryRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, string(clients.GWEntrypointSynchronizeInventory)) {
+			if err := json.NewDecoder(r.Body).Decode(&inventory); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				t.Error(err)
+				return
+			}
+		}
+		if _, err := fmt.Fprintln(w, "OK"); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := Groundwork{
+		Log:                   testutil.Logger{},
+		Server:                server.URL,
+		AgentID:               defaultTestAgentID,
+		Username:              config.NewSecret([]byte(`tu ser`)),
+		Password:              config.NewSecret([]byte(`pu ser`)),
+		DefaultHost:           defaultHost,
+		DefaultAppType:        defaultAppType,
+		DefaultServiceState:   string(transit.ServiceOk),
+		GroupTag:              "group",
+		ResourceTag:           "host",
+		SendInventoryInterval: config.Duration(time.Hour),
+	}
+	require.NoError(t, plugin.Init())
+	plugin.client.GWConnection.HostName = server.URL
+
+	cpu := testutil.TestMetric(1.0, "cpu")
+	cpu.AddTag("host", "Host01")
+	cpu.AddTag("group", "Group01")
+	mem := testutil.TestMetric(2.0, "mem")
+	mem.AddTag("host", "Host01")
+	require.NoError(t, plugin.Write([]telegraf.Metric{cpu, mem}))
+
+	// Services not reporting anymore are kept in the inventory
+	disk := testutil.TestMetric(3.0, "disk")
+	disk.AddTag("host", "Host02")
+	require.NoError(t, plugin.Write([]telegraf.Metric{disk}))
+
+	require.NoError(t, plugin.sendInventory(t.Context()))
+	require.Equal(t, defaultTestAgentID, inventory.Context.AgentID)
+	require.Len(t, inventory.Resources, 2)
+	require.Equal(t, "Host01", inventory.Resources[0].Name)
+	require.Len(t, inventory.Resources[0].Services, 2)
+	require.Equal(t, "cpu", inventory.Resources[0].Services[0].Name)
+	require.Equal(t, "mem", inventory.Resources[0].Services[1].Name)
+	require.Equal(t, "Host02", inventory.Resources[1].Name)
+	require.Len(t, inventory.Resources[1].Services, 1)
+	require.Equal(t, "disk", inventory.Resources[1].Services[0].Name)
+	require.Len(t, inventory.Groups, 1)
+	require.Equal(t, "Group01", inventory.Groups[0].GroupName)
+	require.Len(t, inventory.Groups[0].Resources, 1)
+	require.Equal(t, "Host01", inventory.Groups[0].Resources[0].Name)
+}
+
+func TestSecretsRotated(t *testing.T) {
+	var logins []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, string(clients.GWEntrypointAuthenticatePassword)) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var credentials map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		logins = append(logins, credentials["name"])
+		if credentials["password"] != "rotated" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if _, err := fmt.Fprint(w, `{"name":"tu ser","accessToken":"token"}`); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := Groundwork{
+		Log:                 testutil.Logger{},
+		Server:              server.URL,
+		AgentID:             defaultTestAgentID,
+		Username:            config.NewSecret([]byte(`tu ser`)),
+		Password:            config.NewSecret([]byte(`pu ser`)),
+		DefaultHost:         defaultHost,
+		DefaultAppType:      defaultAppType,
+		DefaultServiceState: string(transit.ServiceOk),
+		ResourceTag:         "host",
+	}
+	require.NoError(t, plugin.Init())
+	plugin.client.GWConnection.HostName = server.URL
+
+	// The outdated credentials are rejected
+	require.ErrorContains(t, plugin.SecretsRotated(), "could not login")
+
+	// The rotated credentials are used for logging in again
+	require.NoError(t, plugin.Username.Set([]byte("rotated user")))
+	require.NoError(t, plugin.Password.Set([]byte("rotated")))
+	require.NoError(t, plugin.SecretsRotated())
+	require.Equal(t, []string{"tu ser", "rotated user"}, logins)
+}
+
+func TestInitFailGroups(t *testing.T) {
+	tests := []struct {
+		name             string
+		defaultGroupType string
+		groups           []groupSettings
+		expected         string
+	}{
+		{
+			name:             "invalid default group type",
+			defaultGroupType: "NodeGroup",
+			expected:         `invalid "default_group_type" "NodeGroup" provided`,
+		},
+		{
+			name:     "missing group name",
+			groups:   []groupSettings{{Type: "CustomGroup"}},
+			expected: `no "name" provided for group`,
+		},
+		{
+			name:     "invalid group type",
+			groups:   []groupSettings{{Name: "Group01", Type: "NodeGroup"}},
+			expected: `invalid "type" "NodeGroup" provided for group "Group01"`,
+		},
+		{
+			name:     "duplicate group",
+			groups:   []groupSettings{{Name: "Group01"}, {Name: "Group01", Type: "CustomGroup"}},
+			expected: `duplicate settings for group "Group01"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := Groundwork{
+				Server:              "http://localhost",
+				AgentID:             defaultTestAgentID,
+				Username:            config.NewSecret([]byte(`tu ser`)),
+				Password:            config.NewSecret([]byte(`pu ser`)),
+				DefaultAppType:      defaultAppType,
+				DefaultHost:         defaultHost,
+				DefaultServiceState: string(transit.ServiceOk),
+				DefaultGroupType:    tt.defaultGroupType,
+				ResourceTag:         "host",
+				Groups:              tt.groups,
+				Log:                 testutil.Logger{},
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWriteGroups(t *testing.T) {
+	var request transit.ResourcesWithServicesRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := fmt.Fprintln(w, "OK"); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := Groundwork{
+		Log:                 testutil.Logger{},
+		Server:              server.URL,
+		AgentID:             defaultTestAgentID,
+		Username:            config.NewSecret([]byte(`tu ser`)),
+		Password:            config.NewSecret([]byte(`pu ser`)),
+		DefaultHost:         defaultHost,
+		DefaultAppType:      defaultAppType,
+		DefaultServiceState: string(transit.ServiceOk),
+		GroupTag:            "group",
+		GroupTypeTag:        "group_type",
+		ResourceTag:         "host",
+		Groups: []groupSettings{
+			{Name: "Databases", Type: "CustomGroup"},
+		},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.client.GWConnection.HostName = server.URL
+
+	cpu := testutil.TestMetric(1.0, "cpu")
+	cpu.AddTag("host", "Host01")
+	cpu.AddTag("group", "Linux, Databases")
+	mem := testutil.TestMetric(2.0, "mem")
+	mem.AddTag("host", "Host01")
+	mem.AddTag("group", "Linux")
+	disk := testutil.TestMetric(3.0, "disk")
+	disk.AddTag("host", "Host02")
+	disk.AddTag("group", "Storage,Databases")
+	disk.AddTag("group_type", "ServiceGroup")
+	require.NoError(t, plugin.Write([]telegraf.Metric{cpu, mem, disk}))
+
+	expected := []transit.ResourceGroup{
+		{
+			GroupName: "Databases",
+			Type:      transit.CustomGroup,
+			Resources: []transit.ResourceRef{
+				{Name: "Host01", Type: transit.ResourceTypeHost},
+				{Name: "Host02", Type: transit.ResourceTypeHost},
+			},
+		},
+		{
+			GroupName: "Linux",
+			Type:      transit.HostGroup,
+			Resources: []transit.ResourceRef{
+				{Name: "Host01", Type: transit.ResourceTypeHost},
+			},
+		},
+		{
+			GroupName: "Storage",
+			Type:      transit.ServiceGroup,
+			Resources: []transit.ResourceRef{
+				{Name: "disk", Type: transit.ResourceTypeService, Owner: "Host02"},
+			},
+		},
+	}
+	require.Equal(t, expected, request.Groups)
+
+	// The type tag is not added as service property
+	for _, resource := range request.Resources {
+		for _, service := range resource.Services {
+			require.NotContains(t, service.Properties, "group_type")
+		}
+	}
+}
+
+func TestWriteServiceGroups(t *testing.T) {
+	var request transit.ResourcesWithServicesRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		if _, err := fmt.Fprintln(w, "OK"); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := Groundwork{
+		Log:                 testutil.Logger{},
+		Server:              server.URL,
+		AgentID:             defaultTestAgentID,
+		Username:            config.NewSecret([]byte(`tu ser`)),
+		Password:            config.NewSecret([]byte(`pu ser`)),
+		DefaultHost:         defaultHost,
+		DefaultAppType:      defaultAppType,
+		DefaultServiceState: string(transit.ServiceOk),
+		GroupTag:            "group",
+		ServiceGroupTag:     "service_group",
+		ResourceTag:         "host",
+	}
+	require.NoError(t, plugin.Init())
+	plugin.client.GWConnection.HostName = server.URL
+
+	cpu1 := testutil.TestMetric(1.0, "cpu")
+	cpu1.AddTag("host", "Host01")
+	cpu1.AddTag("group", "Linux")
+	cpu1.AddTag("service_group", "CPU, Compute")
+	cpu2 := testutil.TestMetric(2.0, "cpu")
+	cpu2.AddTag("host", "Host02")
+	cpu2.AddTag("service_group", "CPU")
+	mem := testutil.TestMetric(3.0, "mem")
+	mem.AddTag("host", "Host01")
+	mem.AddTag("service_group", "Compute")
+	require.NoError(t, plugin.Write([]telegraf.Metric{cpu1, cpu2, mem}))
+
+	expected := []transit.ResourceGroup{
+		{
+			GroupName: "CPU",
+			Type:      transit.ServiceGroup,
+			Resources: []transit.ResourceRef{
+				{Name: "cpu", Type: transit.ResourceTypeService, Owner: "Host01"},
+				{Name: "cpu", Type: transit.ResourceTypeService, Owner: "Host02"},
+			},
+		},
+		{
+			GroupName: "Compute",
+			Type:      transit.ServiceGroup,
+			Resources: []transit.ResourceRef{
+				{Name: "cpu", Type: transit.ResourceTypeService, Owner: "Host01"},
+				{Name: "mem", Type: transit.ResourceTypeService, Owner: "Host01"},
+			},
+		},
+		{
+			GroupName: "Linux",
+			Type:      transit.HostGroup,
+			Resources: []transit.ResourceRef{
+				{Name: "Host01", Type: transit.ResourceTypeHost},
+			},
+		},
+	}
+	require.Equal(t, expected, request.Groups)
+
+	// The service group tag is not added as service property
+	for _, resource := range request.Resources {
+		for _, service := range resource.Services {
+			require.NotContains(t, service.Properties, "service_group")
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package groundwork
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/gwos/tcg/sdk/transit"
+)
+
+// inventory keeps track of all hosts, services and groups seen in the written
+// metrics to be able to synchronize the full inventory with GroundWork.
+type inventory struct {
+	sync.Mutex
+	resources map[string]map[string]transit.InventoryService
+	groups    map[groupKey]map[transit.ResourceRef]bool
+}
+
+func newInventory() *inventory {
+	return &inventory{
+		resources: make(map[string]map[string]transit.InventoryService),
+		groups:    make(map[groupKey]map[transit.ResourceRef]bool),
+	}
+}
+
+func (inv *inventory) update(resources []transit.MonitoredResource, groups []transit.ResourceGroup) {
+	inv.Lock()
+	defer inv.Unlock()
+
+	for _, resource := range resources {
+		services, found := inv.resources[resource.Name]
+		if !found {
+			services = make(map[string]transit.InventoryService, len(resource.Services))
+			inv.resources[resource.Name] = services
+		}
+		for _, service := range resource.Services {
+			services[service.Name] = transit.InventoryService{BaseInfo: service.BaseInfo}
+		}
+	}
+
+	for _, group := range groups {
+		key := groupKey{name: group.GroupName, groupType: group.Type}
+		members, found := inv.groups[key]
+		if !found {
+			members = make(map[transit.ResourceRef]bool, len(group.Resources))
+			inv.groups[key] = members
+		}
+		for _, ref := range group.Resources {
+			members[ref] = true
+		}
+	}
+}
+
+// snapshot returns the current inventory sorted by name
+func (inv *inventory) snapshot() ([]transit.InventoryResource, []transit.ResourceGroup) {
+	inv.Lock()
+	defer inv.Unlock()
+
+	resources := make([]transit.InventoryResource, 0, len(inv.resources))
+	for name, services := range inv.resources {
+		resource := transit.InventoryResource{
+			BaseResource: transit.BaseResource{
+				BaseInfo: transit.BaseInfo{
+					Name: name,
+					Type: transit.ResourceTypeHost,
+				},
+			},
+			Services: make([]transit.InventoryService, 0, len(services)),
+		}
+		for _, service := range services {
+			resource.Services = append(resource.Services, service)
+		}
+		sort.Slice(resource.Services, func(i, j int) bool { return resource.Services[i].Name < resource.Services[j].Name })
+		resources = append(resources, resource)
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Name < resources[j].Name })
+
+	groups := make([]transit.ResourceGroup, 0, len(inv.groups))
+	for key, members := range inv.groups {
+		group := transit.ResourceGroup{
+			GroupName: key.name,
+			Type:      key.groupType,
+			Resources: make([]transit.ResourceRef, 0, len(members)),
+		}
+		for member := range members {
+			group.Resources = append(group.Resources, member)
+		}
+		sort.Slice(group.Resources, func(i, j int) bool {
+			if group.Resources[i].Name != group.Resources[j].Name {
+				return group.Resources[i].Name < group.Resources[j].Name
+			}
+			return group.Resources[i].Owner < group.Resources[j].Owner
+		})
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].GroupName != groups[j].GroupName {
+			return groups[i].GroupName < groups[j].GroupName
+		}
+		return groups[i].Type < groups[j].Type
+	})
+
+	return resources, groups
+}
@@ -0,0 +1,120 @@
+package influxdb
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Number of positions of each client on the hash ring
+const virtualNodes = 128
+
+// pinger is implemented by clients supporting health checks of the endpoint
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type ringEntry struct {
+	hash   uint64
+	client int
+}
+
+// balancer keeps track of the health of the clients and selects the clients
+// to send metrics to
+type balancer struct {
+	ring []ringEntry
+
+	healthy []bool
+	sync.Mutex
+}
+
+// newBalancer creates a balancer for the clients of the given URLs with the
+// client index corresponding to the index of the URL
+func newBalancer(urls []string) *balancer {
+	b := &balancer{
+		ring:    make([]ringEntry, 0, len(urls)*virtualNodes),
+		healthy: make([]bool, len(urls)),
+	}
+
+	for n, u := range urls {
+		b.healthy[n] = true
+		for v := 0; v < virtualNodes; v++ {
+			h := fnv.New64a()
+			h.Write([]byte(u + "#" + strconv.Itoa(v)))
+			b.ring = append(b.ring, ringEntry{hash: mix(h.Sum64()), client: n})
+		}
+	}
+	sort.Slice(b.ring, func(i, j int) bool { return b.ring[i].hash < b.ring[j].hash })
+
+	return b
+}
+
+// order returns the indices of all clients in random order with the healthy
+// clients first
+func (b *balancer) order() []int {
+	b.Lock()
+	defer b.Unlock()
+
+	healthy := make([]int, 0, len(b.healthy))
+	unhealthy := make([]int, 0)
+	for _, n := range rand.Perm(len(b.healthy)) {
+		if b.healthy[n] {
+			healthy = append(healthy, n)
+		} else {
+			unhealthy = append(unhealthy, n)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// lookup returns the index of the client responsible for the given hash by
+// walking the ring clockwise starting at the hash. Excluded clients are
+// skipped and unhealthy clients are only used if no healthy client is left.
+// If all clients are excluded -1 is returned.
+func (b *balancer) lookup(hash uint64, excluded map[int]bool) int {
+	b.Lock()
+	defer b.Unlock()
+
+	hash = mix(hash)
+	start := sort.Search(len(b.ring), func(i int) bool { return b.ring[i].hash >= hash })
+	fallback := -1
+	for i := range b.ring {
+		entry := b.ring[(start+i)%len(b.ring)]
+		if excluded[entry.client] {
+			continue
+		}
+		if b.healthy[entry.client] {
+			return entry.client
+		}
+		if fallback < 0 {
+			fallback = entry.client
+		}
+	}
+	return fallback
+}
+
+func (b *balancer) isHealthy(n int) bool {
+	b.Lock()
+	defer b.Unlock()
+	return b.healthy[n]
+}
+
+func (b *balancer) setHealthy(n int, healthy bool) {
+	b.Lock()
+	defer b.Unlock()
+	b.healthy[n] = healthy
+}
+
+// mix spreads similar hashes, e.g. of series keys only differing in the last
+// characters, across the whole ring using the finalizer of MurmurHash3
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
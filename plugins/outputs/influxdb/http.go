@@ -280,6 +280,38 @@ func (c *httpClient) CreateDatabase(ctx context.Context, database string) error
 	}
 }
 
+// Ping checks the health of the InfluxDB server using the /ping endpoint
+func (c *httpClient) Ping(ctx context.Context) error {
+	pingURL, err := makePingURL(c.config.URL)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", pingURL, nil)
+	if err != nil {
+		return err
+	}
+	if err := c.addHeaders(req); err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		internal.OnClientError(c.client, err)
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Title:      resp.Status,
+		}
+	}
+	return nil
+}
+
 type dbrp struct {
 	Database        string
 	RetentionPolicy string
@@ -580,14 +612,22 @@ func makeWriteURL(loc *url.URL, db, rp, consistency string) (string, error) {
 }
 
 func makeQueryURL(loc *url.URL) (string, error) {
+	return makeEndpointURL(loc, "query")
+}
+
+func makePingURL(loc *url.URL) (string, error) {
+	return makeEndpointURL(loc, "ping")
+}
+
+func makeEndpointURL(loc *url.URL, endpoint string) (string, error) {
 	u := *loc
 	switch u.Scheme {
 	case "unix":
 		u.Scheme = "http"
 		u.Host = "127.0.0.1"
-		u.Path = "/query"
+		u.Path = "/" + endpoint
 	case "http", "https":
-		u.Path = path.Join(u.Path, "query")
+		u.Path = path.Join(u.Path, endpoint)
 	default:
 		return "", fmt.Errorf("unsupported scheme: %q", loc.Scheme)
 	}
@@ -13,6 +13,7 @@ import (
 	"path"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -277,6 +278,32 @@ func TestHTTP_CreateDatabase(t *testing.T) {
 	}
 }
 
+func TestHTTP_Ping(t *testing.T) {
+	var healthy atomic.Bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ping" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse("http://" + ts.Listener.Addr().String())
+	require.NoError(t, err)
+
+	client, err := influxdb.NewHTTPClient(influxdb.HTTPConfig{URL: u, Log: testutil.Logger{}})
+	require.NoError(t, err)
+
+	require.ErrorContains(t, client.Ping(t.Context()), "503 Service Unavailable")
+	healthy.Store(true)
+	require.NoError(t, client.Ping(t.Context()))
+}
+
 func TestHTTP_Write(t *testing.T) {
 	ts := httptest.NewServer(http.NotFoundHandler())
 	defer ts.Close()
@@ -6,11 +6,11 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
-	"math/rand"
 	"net"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -56,12 +56,17 @@ type InfluxDB struct {
 	SkipDatabaseCreation      bool                `toml:"skip_database_creation"`
 	InfluxUintSupport         bool                `toml:"influx_uint_support"`
 	OmitTimestamp             bool                `toml:"influx_omit_timestamp"`
+	URLSelection              string              `toml:"url_selection"`
+	HealthCheckInterval       config.Duration     `toml:"health_check_interval"`
 	Log                       telegraf.Logger     `toml:"-"`
 	Statistics                *selfstat.Collector `toml:"-"`
 	tls.ClientConfig
 
 	clients    []Client
+	balancer   *balancer
 	serializer *influx.Serializer
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
 
 	bytesWritten selfstat.Stat
 
@@ -83,6 +88,14 @@ func (i *InfluxDB) Init() error {
 		i.URLs = append(i.URLs, "http://localhost:8086")
 	}
 
+	switch i.URLSelection {
+	case "":
+		i.URLSelection = "random"
+	case "random", "consistent_hash":
+	default:
+		return fmt.Errorf("invalid 'url_selection' %q", i.URLSelection)
+	}
+
 	// Setup serializer
 	i.serializer = &influx.Serializer{
 		UintSupport:   i.InfluxUintSupport,
@@ -173,11 +186,28 @@ func (i *InfluxDB) Connect() error {
 			return fmt.Errorf("unsupported scheme [%q]: %q", u, parts.Scheme)
 		}
 	}
+	i.balancer = newBalancer(i.URLs)
+
+	if i.HealthCheckInterval > 0 && len(i.clients) > 1 {
+		ctx, cancel := context.WithCancel(context.Background())
+		i.cancel = cancel
+		i.wg.Add(1)
+		go func() {
+			defer i.wg.Done()
+			i.checkHealth(ctx)
+		}()
+	}
 
 	return nil
 }
 
 func (i *InfluxDB) Close() error {
+	if i.cancel != nil {
+		i.cancel()
+		i.wg.Wait()
+		i.cancel = nil
+	}
+
 	for _, client := range i.clients {
 		client.Close()
 	}
@@ -189,14 +219,16 @@ func (i *InfluxDB) Close() error {
 // Write sends metrics to one of the configured servers, logging each
 // unsuccessful. If all servers fail, return an error.
 func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
+	if i.URLSelection == "consistent_hash" {
+		return i.writeConsistentHash(metrics)
+	}
+
 	ctx := context.Background()
 
 	allErrorsAreDatabaseNotFoundErrors := true
-	var err error
-	p := rand.Perm(len(i.clients))
-	for _, n := range p {
+	for _, n := range i.balancer.order() {
 		client := i.clients[n]
-		err = client.Write(ctx, metrics)
+		err := client.Write(ctx, metrics)
 		if err == nil {
 			return nil
 		}
@@ -216,6 +248,7 @@ func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
 			i.Log.Errorf("When writing to [%s]: database %q not found and failed to recreate", client.URL(), apiError.Database)
 		} else {
 			allErrorsAreDatabaseNotFoundErrors = false
+			i.markUnhealthy(n)
 		}
 	}
 
@@ -226,6 +259,109 @@ func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
 	return errors.New("could not write any address")
 }
 
+// writeConsistentHash distributes the metrics across the servers by the hash
+// of their series key so each series is always written to the same server as
+// long as the server is healthy. Metrics of a failing server are redistributed
+// to the remaining servers.
+func (i *InfluxDB) writeConsistentHash(metrics []telegraf.Metric) error {
+	ctx := context.Background()
+
+	allErrorsAreDatabaseNotFoundErrors := true
+	excluded := make(map[int]bool, len(i.clients))
+	pending := metrics
+	for len(pending) > 0 {
+		batches := make(map[int][]telegraf.Metric)
+		for _, m := range pending {
+			n := i.balancer.lookup(m.HashID(), excluded)
+			if n < 0 {
+				if allErrorsAreDatabaseNotFoundErrors {
+					// return nil because we should not be retrying this
+					return nil
+				}
+				return errors.New("could not write any address")
+			}
+			batches[n] = append(batches[n], m)
+		}
+
+		pending = nil
+		for n, batch := range batches {
+			client := i.clients[n]
+			err := client.Write(ctx, batch)
+			if err == nil {
+				continue
+			}
+
+			i.Log.Errorf("When writing to [%s]: %v", client.URL(), err)
+
+			var apiError *DatabaseNotFoundError
+			if errors.As(err, &apiError) {
+				if !i.SkipDatabaseCreation {
+					// retry control
+					// error so the write is retried
+					if err := client.CreateDatabase(ctx, apiError.Database); err == nil {
+						return errors.New("database created; retry write")
+					}
+					i.Log.Errorf("When writing to [%s]: database %q not found and failed to recreate", client.URL(), apiError.Database)
+				}
+			} else {
+				allErrorsAreDatabaseNotFoundErrors = false
+				i.markUnhealthy(n)
+			}
+
+			excluded[n] = true
+			pending = append(pending, batch...)
+		}
+	}
+
+	return nil
+}
+
+// markUnhealthy removes the client from the set of preferred clients until
+// the next successful health check
+func (i *InfluxDB) markUnhealthy(n int) {
+	if i.HealthCheckInterval <= 0 || len(i.clients) < 2 || !i.balancer.isHealthy(n) {
+		return
+	}
+	i.Log.Warnf("Marking [%s] as unhealthy", i.clients[n].URL())
+	i.balancer.setHealthy(n, false)
+}
+
+// checkHealth periodically checks the health of all servers. Clients not
+// supporting health checks are considered healthy again after one interval.
+func (i *InfluxDB) checkHealth(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(i.HealthCheckInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for n, client := range i.clients {
+			healthy := true
+			if p, ok := client.(pinger); ok {
+				if err := p.Ping(ctx); err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+					i.Log.Debugf("Health check of [%s] failed: %v", client.URL(), err)
+					healthy = false
+				}
+			}
+
+			switch wasHealthy := i.balancer.isHealthy(n); {
+			case healthy && !wasHealthy:
+				i.Log.Infof("Marking [%s] as healthy", client.URL())
+			case !healthy && wasHealthy:
+				i.Log.Warnf("Marking [%s] as unhealthy", client.URL())
+			}
+			i.balancer.setHealthy(n, healthy)
+		}
+	}
+}
+
 func (i *InfluxDB) udpClient(address *url.URL, localAddr *net.UDPAddr) (Client, error) {
 	udpConfig := &UDPConfig{
 		URL:            address,
@@ -2,9 +2,12 @@ package influxdb_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -22,6 +25,8 @@ import (
 type MockClient struct {
 	URLF            func() string
 	WriteF          func() error
+	WriteMetricsF   func([]telegraf.Metric) error
+	PingF           func() error
 	CreateDatabaseF func() error
 	DatabaseF       func() string
 	CloseF          func()
@@ -33,10 +38,20 @@ func (c *MockClient) URL() string {
 	return c.URLF()
 }
 
-func (c *MockClient) Write(context.Context, []telegraf.Metric) error {
+func (c *MockClient) Write(_ context.Context, metrics []telegraf.Metric) error {
+	if c.WriteMetricsF != nil {
+		return c.WriteMetricsF(metrics)
+	}
 	return c.WriteF()
 }
 
+func (c *MockClient) Ping(context.Context) error {
+	if c.PingF != nil {
+		return c.PingF()
+	}
+	return nil
+}
+
 func (c *MockClient) CreateDatabase(context.Context, string) error {
 	return c.CreateDatabaseF()
 }
@@ -761,3 +776,188 @@ func BenchmarkWrite100k(b *testing.B) {
 	}
 	b.ReportMetric(float64(batchsize*b.N)/b.Elapsed().Seconds(), "metrics/s")
 }
+
+func TestInitInvalidURLSelection(t *testing.T) {
+	output := influxdb.InfluxDB{
+		URLSelection: "round_robin",
+		Statistics:   selfstat.NewCollector(nil),
+	}
+	defer output.Statistics.UnregisterAll()
+	require.ErrorContains(t, output.Init(), `invalid 'url_selection' "round_robin"`)
+}
+
+// recordingClient stores the series written to each URL and fails writes
+// while the URL is marked as down
+type recordingClient struct {
+	sync.Mutex
+	series map[string]map[string]int
+	down   map[string]bool
+}
+
+func (r *recordingClient) create(cfg *influxdb.HTTPConfig) (influxdb.Client, error) {
+	u := cfg.URL.String()
+	return &MockClient{
+		URLF:      func() string { return u },
+		DatabaseF: func() string { return "telegraf" },
+		WriteMetricsF: func(metrics []telegraf.Metric) error {
+			r.Lock()
+			defer r.Unlock()
+			if r.down[u] {
+				return errors.New("connection refused")
+			}
+			if r.series[u] == nil {
+				r.series[u] = make(map[string]int)
+			}
+			for _, m := range metrics {
+				r.series[u][m.Tags()["host"]]++
+			}
+			return nil
+		},
+		PingF: func() error {
+			r.Lock()
+			defer r.Unlock()
+			if r.down[u] {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+	}, nil
+}
+
+func (r *recordingClient) owner(series string) string {
+	r.Lock()
+	defer r.Unlock()
+	for u, s := range r.series {
+		if s[series] > 0 {
+			return u
+		}
+	}
+	return ""
+}
+
+func (r *recordingClient) reset() {
+	r.Lock()
+	defer r.Unlock()
+	r.series = make(map[string]map[string]int)
+}
+
+func TestWriteConsistentHash(t *testing.T) {
+	recorder := &recordingClient{
+		series: make(map[string]map[string]int),
+		down:   make(map[string]bool),
+	}
+	output := influxdb.InfluxDB{
+		URLs:                 []string{"http://node1:8086", "http://node2:8086", "http://node3:8086"},
+		URLSelection:         "consistent_hash",
+		SkipDatabaseCreation: true,
+		CreateHTTPClientF:    recorder.create,
+		Log:                  testutil.Logger{},
+		Statistics:           selfstat.NewCollector(nil),
+	}
+	defer output.Statistics.UnregisterAll()
+	require.NoError(t, output.Init())
+	require.NoError(t, output.Connect())
+	defer output.Close()
+
+	metrics := make([]telegraf.Metric, 0, 100)
+	for n := range 100 {
+		metrics = append(metrics, metric.New(
+			"cpu",
+			map[string]string{"host": fmt.Sprintf("host%d", n)},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 0),
+		))
+	}
+
+	// All series should be distributed across the nodes with each series
+	// written exactly once
+	require.NoError(t, output.Write(metrics))
+	require.Len(t, recorder.series, 3)
+	owners := make(map[string]string, len(metrics))
+	var total int
+	for u, s := range recorder.series {
+		for series, count := range s {
+			require.Equal(t, 1, count)
+			owners[series] = u
+			total++
+		}
+	}
+	require.Equal(t, len(metrics), total)
+
+	// Writing again must use the same nodes for each series
+	recorder.reset()
+	require.NoError(t, output.Write(metrics))
+	for series, u := range owners {
+		require.Equal(t, u, recorder.owner(series), series)
+	}
+
+	// Only the series of a failing node should move to other nodes
+	recorder.reset()
+	recorder.down["http://node1:8086"] = true
+	require.NoError(t, output.Write(metrics))
+	for series, u := range owners {
+		actual := recorder.owner(series)
+		if u == "http://node1:8086" {
+			require.NotEqual(t, u, actual, series)
+		} else {
+			require.Equal(t, u, actual, series)
+		}
+	}
+
+	// Writing fails if all nodes are down
+	recorder.down["http://node2:8086"] = true
+	recorder.down["http://node3:8086"] = true
+	require.ErrorContains(t, output.Write(metrics), "could not write any address")
+}
+
+func TestWriteHealthCheck(t *testing.T) {
+	recorder := &recordingClient{
+		series: make(map[string]map[string]int),
+		down:   make(map[string]bool),
+	}
+	output := influxdb.InfluxDB{
+		URLs:                 []string{"http://node1:8086", "http://node2:8086"},
+		HealthCheckInterval:  config.Duration(10 * time.Millisecond),
+		SkipDatabaseCreation: true,
+		CreateHTTPClientF:    recorder.create,
+		Log:                  testutil.Logger{},
+		Statistics:           selfstat.NewCollector(nil),
+	}
+	defer output.Statistics.UnregisterAll()
+	require.NoError(t, output.Init())
+	require.NoError(t, output.Connect())
+	defer output.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "localhost"},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 0),
+		),
+	}
+
+	// Unhealthy nodes should not be written to anymore
+	recorder.Lock()
+	recorder.down["http://node1:8086"] = true
+	recorder.Unlock()
+	require.Eventually(t, func() bool {
+		recorder.reset()
+		for range 20 {
+			if err := output.Write(metrics); err != nil {
+				return false
+			}
+		}
+		return recorder.owner("localhost") == "http://node2:8086" && len(recorder.series) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// Recovered nodes should be written to again
+	recorder.Lock()
+	recorder.down["http://node1:8086"] = false
+	recorder.Unlock()
+	require.Eventually(t, func() bool {
+		recorder.reset()
+		require.NoError(t, output.Write(metrics))
+		return recorder.owner("localhost") == "http://node1:8086"
+	}, 3*time.Second, 10*time.Millisecond)
+}
@@ -33,6 +33,7 @@ type Kafka struct {
 	RoutingKey        string          `toml:"routing_key"`
 	ProducerTimestamp string          `toml:"producer_timestamp"`
 	MetricNameHeader  string          `toml:"metric_name_header"`
+	TransactionalID   string          `toml:"transactional_id"`
 	Log               telegraf.Logger `toml:"-"`
 	proxy.Socks5ProxyConfig
 	kafka.WriteConfig
@@ -79,6 +80,19 @@ func (k *Kafka) Init() error {
 		return err
 	}
 
+	// Transactions require an idempotent producer waiting for all replicas
+	if k.TransactionalID != "" {
+		if k.RequiredAcks != -1 {
+			return errors.New("transactional_id requires required_acks to be -1")
+		}
+		if k.MaxRetry < 1 {
+			return errors.New("transactional_id requires max_retry to be at least one")
+		}
+		config.Producer.Idempotent = true
+		config.Producer.Transaction.ID = k.TransactionalID
+		config.Net.MaxOpenRequests = 1
+	}
+
 	// Legacy support ssl config
 	if k.Certificate != "" {
 		k.TLSCert = k.Certificate
@@ -165,31 +179,80 @@ func (k *Kafka) Write(metrics []telegraf.Metric) error {
 		msgs = append(msgs, m)
 	}
 
-	if err := k.producer.SendMessages(msgs); err != nil {
-		// We could have many errors, return only the first encountered.
-		var errs sarama.ProducerErrors
-		if errors.As(err, &errs) && len(errs) > 0 {
-			// Just return the first error encountered
-			firstErr := errs[0]
-			if errors.Is(firstErr.Err, sarama.ErrMessageSizeTooLarge) {
-				k.Log.Error("Message too large, consider increasing `max_message_bytes`; dropping batch")
-				return nil
-			}
-			if errors.Is(firstErr.Err, sarama.ErrInvalidTimestamp) {
-				k.Log.Error(
-					"The timestamp of the message is out of acceptable range, consider increasing broker `message.timestamp.difference.max.ms`; " +
-						"dropping batch",
-				)
-				return nil
-			}
-			return firstErr
+	if k.TransactionalID != "" {
+		return k.sendTransaction(msgs)
+	}
+
+	return k.handleSendError(k.producer.SendMessages(msgs))
+}
+
+// sendTransaction sends the messages within a transaction so consumers with
+// read-committed isolation see either all or none of the messages of a batch
+func (k *Kafka) sendTransaction(msgs []*sarama.ProducerMessage) error {
+	// A producer in fatal state, e.g. after being fenced by another producer
+	// with the same transactional ID, cannot be used anymore and needs to be
+	// recreated
+	if k.producer.TxnStatus()&sarama.ProducerTxnFlagFatalError != 0 {
+		k.Log.Warn("Producer in fatal transaction state, recreating producer")
+		if err := k.producer.Close(); err != nil {
+			k.Log.Errorf("Closing producer failed: %v", err)
 		}
-		return err
+		producer, err := k.producerFunc(k.Brokers, k.saramaConfig)
+		if err != nil {
+			return fmt.Errorf("recreating producer failed: %w", err)
+		}
+		k.producer = producer
+	}
+
+	if err := k.producer.BeginTxn(); err != nil {
+		return fmt.Errorf("beginning transaction failed: %w", err)
+	}
+
+	if err := k.producer.SendMessages(msgs); err != nil {
+		k.abortTransaction()
+		return k.handleSendError(err)
+	}
+
+	if err := k.producer.CommitTxn(); err != nil {
+		k.abortTransaction()
+		return fmt.Errorf("committing transaction failed: %w", err)
 	}
 
 	return nil
 }
 
+func (k *Kafka) abortTransaction() {
+	if err := k.producer.AbortTxn(); err != nil {
+		k.Log.Errorf("Aborting transaction failed: %v", err)
+	}
+}
+
+func (k *Kafka) handleSendError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// We could have many errors, return only the first encountered.
+	var errs sarama.ProducerErrors
+	if errors.As(err, &errs) && len(errs) > 0 {
+		// Just return the first error encountered
+		firstErr := errs[0]
+		if errors.Is(firstErr.Err, sarama.ErrMessageSizeTooLarge) {
+			k.Log.Error("Message too large, consider increasing `max_message_bytes`; dropping batch")
+			return nil
+		}
+		if errors.Is(firstErr.Err, sarama.ErrInvalidTimestamp) {
+			k.Log.Error(
+				"The timestamp of the message is out of acceptable range, consider increasing broker `message.timestamp.difference.max.ms`; " +
+					"dropping batch",
+			)
+			return nil
+		}
+		return firstErr
+	}
+	return err
+}
+
 func (k *Kafka) getTopicName(metric telegraf.Metric) (telegraf.Metric, string) {
 	topic := k.Topic
 	if k.TopicTag != "" {
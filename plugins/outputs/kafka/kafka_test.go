@@ -6,11 +6,13 @@ import (
 	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
 	"github.com/stretchr/testify/require"
 	kafkacontainer "github.com/testcontainers/testcontainers-go/modules/kafka"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/kafka"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -295,3 +297,71 @@ func (p *mockProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
 func (*mockProducer) Close() error {
 	return nil
 }
+
+func TestTransactionalInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Kafka
+		expected string
+	}{
+		{
+			name: "missing acknowledgements",
+			plugin: &Kafka{
+				TransactionalID: "telegraf",
+				WriteConfig:     kafka.WriteConfig{MaxRetry: 3, RequiredAcks: 1},
+			},
+			expected: "transactional_id requires required_acks to be -1",
+		},
+		{
+			name: "no retries",
+			plugin: &Kafka{
+				TransactionalID: "telegraf",
+				WriteConfig:     kafka.WriteConfig{RequiredAcks: -1},
+			},
+			expected: "transactional_id requires max_retry to be at least one",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestTransactionalWrite(t *testing.T) {
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+
+	var producer *mocks.SyncProducer
+	plugin := &Kafka{
+		Brokers:         []string{"127.0.0.1"},
+		Topic:           "telegraf",
+		TransactionalID: "telegraf-test",
+		WriteConfig:     kafka.WriteConfig{MaxRetry: 3, RequiredAcks: -1},
+		Log:             testutil.Logger{},
+		producerFunc: func(_ []string, cfg *sarama.Config) (sarama.SyncProducer, error) {
+			require.True(t, cfg.Producer.Idempotent)
+			require.Equal(t, "telegraf-test", cfg.Producer.Transaction.ID)
+			producer = mocks.NewSyncProducer(t, cfg)
+			return producer, nil
+		},
+	}
+	plugin.SetSerializer(s)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+	require.True(t, producer.IsTransactional())
+
+	// The mock producer refuses messages outside of a transaction
+	producer.ExpectSendMessageAndSucceed()
+	producer.ExpectSendMessageAndSucceed()
+	require.NoError(t, plugin.Write([]telegraf.Metric{testutil.TestMetric(1), testutil.TestMetric(2)}))
+	require.Equal(t, sarama.ProducerTxnFlagReady, producer.TxnStatus())
+
+	// A failing batch must abort the transaction
+	producer.ExpectSendMessageAndFail(sarama.ErrOutOfBrokers)
+	require.ErrorIs(t, plugin.Write([]telegraf.Metric{testutil.TestMetric(3)}), sarama.ErrOutOfBrokers)
+	require.Equal(t, sarama.ProducerTxnFlagReady, producer.TxnStatus())
+}
@@ -16,6 +16,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/golang/snappy"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 
@@ -48,9 +49,13 @@ type Loki struct {
 	GZipRequest        bool              `toml:"gzip_request"`
 	MetricNameLabel    string            `toml:"metric_name_label"`
 	SanitizeLabelNames bool              `toml:"sanitize_label_names"`
+	TenantTag          string            `toml:"tenant_tag"`
+	MetadataFields     []string          `toml:"structured_metadata_fields"`
+	PushFormat         string            `toml:"push_format"`
 
-	url    string
-	client *http.Client
+	url      string
+	metadata map[string]bool
+	client   *http.Client
 	tls.ClientConfig
 }
 
@@ -97,6 +102,23 @@ func (l *Loki) Connect() (err error) {
 
 	l.url = fmt.Sprintf("%s%s", l.Domain, l.Endpoint)
 
+	switch l.PushFormat {
+	case "":
+		l.PushFormat = "json"
+	case "json":
+	case "protobuf":
+		if l.GZipRequest {
+			return errors.New("gzip_request cannot be used with protobuf push format")
+		}
+	default:
+		return fmt.Errorf("invalid push_format %q", l.PushFormat)
+	}
+
+	l.metadata = make(map[string]bool, len(l.MetadataFields))
+	for _, f := range l.MetadataFields {
+		l.metadata[f] = true
+	}
+
 	if l.Timeout == 0 {
 		l.Timeout = config.Duration(defaultClientTimeout)
 	}
@@ -117,7 +139,8 @@ func (l *Loki) Close() error {
 }
 
 func (l *Loki) Write(metrics []telegraf.Metric) error {
-	s := Streams{}
+	// Streams per tenant, the empty tenant uses the configured headers only
+	tenants := make(map[string]Streams)
 
 	sort.SliceStable(metrics, func(i, j int) bool {
 		return metrics[i].Time().Before(metrics[j].Time())
@@ -128,7 +151,15 @@ func (l *Loki) Write(metrics []telegraf.Metric) error {
 			m.AddTag(l.MetricNameLabel, m.Name())
 		}
 
-		tags := m.TagList()
+		var tenant string
+		tags := make([]*telegraf.Tag, 0, len(m.TagList()))
+		for _, t := range m.TagList() {
+			if l.TenantTag != "" && t.Key == l.TenantTag {
+				tenant = t.Value
+				continue
+			}
+			tags = append(tags, t)
+		}
 		if l.SanitizeLabelNames {
 			for _, t := range tags {
 				t.Key = sanitizeLabelName(t.Key)
@@ -136,20 +167,53 @@ func (l *Loki) Write(metrics []telegraf.Metric) error {
 		}
 
 		var line string
+		var md map[string]string
 		for _, f := range m.FieldList() {
+			if l.metadata[f.Key] {
+				if md == nil {
+					md = make(map[string]string, len(l.metadata))
+				}
+				md[f.Key] = fmt.Sprintf("%v", f.Value)
+				continue
+			}
 			line += fmt.Sprintf("%s=\"%v\" ", f.Key, f.Value)
 		}
 
-		s.insertLog(tags, Log{strconv.FormatInt(m.Time().UnixNano(), 10), line})
+		s, found := tenants[tenant]
+		if !found {
+			s = Streams{}
+			tenants[tenant] = s
+		}
+		s.insertLogWithMetadata(tags, Log{strconv.FormatInt(m.Time().UnixNano(), 10), line}, md)
+	}
+
+	for tenant, s := range tenants {
+		if err := l.writeMetrics(s, tenant); err != nil {
+			return err
+		}
 	}
 
-	return l.writeMetrics(s)
+	return nil
 }
 
-func (l *Loki) writeMetrics(s Streams) error {
-	bs, err := json.Marshal(s)
-	if err != nil {
-		return fmt.Errorf("json.Marshal: %w", err)
+func (l *Loki) writeMetrics(s Streams, tenant string) error {
+	var bs []byte
+	var err error
+	var contentType string
+	switch l.PushFormat {
+	case "protobuf":
+		buf, err := s.marshalProtobuf()
+		if err != nil {
+			return fmt.Errorf("encoding protobuf: %w", err)
+		}
+		bs = snappy.Encode(nil, buf)
+		contentType = "application/x-protobuf"
+	default:
+		bs, err = json.Marshal(s)
+		if err != nil {
+			return fmt.Errorf("json.Marshal: %w", err)
+		}
+		contentType = "application/json"
 	}
 
 	var reqBodyBuffer io.Reader = bytes.NewBuffer(bs)
@@ -187,8 +251,12 @@ func (l *Loki) writeMetrics(s Streams) error {
 		req.Header.Set(k, v)
 	}
 
+	if tenant != "" {
+		req.Header.Set("X-Scope-OrgID", tenant)
+	}
+
 	req.Header.Set("User-Agent", internal.ProductToken())
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
 	if l.GZipRequest {
 		req.Header.Set("Content-Encoding", "gzip")
 	}
@@ -9,10 +9,13 @@ import (
 	"net/url"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/golang/snappy"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
@@ -594,6 +597,197 @@ func TestMetricSorting(t *testing.T) {
 	})
 }
 
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Loki
+		expected string
+	}{
+		{
+			name:     "invalid push format",
+			plugin:   &Loki{Domain: "http://localhost", PushFormat: "xml"},
+			expected: `invalid push_format "xml"`,
+		},
+		{
+			name:     "gzip with protobuf",
+			plugin:   &Loki{Domain: "http://localhost", PushFormat: "protobuf", GZipRequest: true},
+			expected: "gzip_request cannot be used with protobuf push format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Connect(), tt.expected)
+		})
+	}
+}
+
+// recordedRequest holds the relevant parts of a request received by the
+// test server
+type recordedRequest struct {
+	tenant      string
+	contentType string
+	body        []byte
+}
+
+func newRecordingServer(t *testing.T) (*httptest.Server, func() []recordedRequest) {
+	var mu sync.Mutex
+	var requests []recordedRequest
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		mu.Lock()
+		requests = append(requests, recordedRequest{
+			tenant:      r.Header.Get("X-Scope-OrgID"),
+			contentType: r.Header.Get("Content-Type"),
+			body:        body,
+		})
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts, func() []recordedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func TestTenantRouting(t *testing.T) {
+	ts, requests := newRecordingServer(t)
+
+	plugin := &Loki{
+		Domain:          ts.URL,
+		TenantTag:       "tenant",
+		Headers:         map[string]string{"X-Scope-OrgID": "default"},
+		MetricNameLabel: "__name",
+	}
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("log", map[string]string{"tenant": "team-a", "host": "a"}, map[string]interface{}{"line": "a1"}, time.Unix(1, 0)),
+		testutil.MustMetric("log", map[string]string{"tenant": "team-b", "host": "b"}, map[string]interface{}{"line": "b1"}, time.Unix(2, 0)),
+		testutil.MustMetric("log", map[string]string{"tenant": "team-a", "host": "a"}, map[string]interface{}{"line": "a2"}, time.Unix(3, 0)),
+		testutil.MustMetric("log", map[string]string{"host": "c"}, map[string]interface{}{"line": "c1"}, time.Unix(4, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	received := make(map[string]Request)
+	for _, r := range requests() {
+		var req Request
+		require.NoError(t, json.Unmarshal(r.body, &req))
+		received[r.tenant] = req
+	}
+	require.Len(t, received, 3)
+
+	require.Len(t, received["team-a"].Streams, 1)
+	require.Equal(t, map[string]string{"__name": "log", "host": "a"}, received["team-a"].Streams[0].Labels)
+	require.Len(t, received["team-a"].Streams[0].Logs, 2)
+
+	require.Len(t, received["team-b"].Streams, 1)
+	require.Equal(t, map[string]string{"__name": "log", "host": "b"}, received["team-b"].Streams[0].Labels)
+
+	require.Len(t, received["default"].Streams, 1)
+	require.Equal(t, map[string]string{"__name": "log", "host": "c"}, received["default"].Streams[0].Labels)
+}
+
+func TestStructuredMetadata(t *testing.T) {
+	ts, requests := newRecordingServer(t)
+
+	plugin := &Loki{
+		Domain:         ts.URL,
+		MetadataFields: []string{"trace_id"},
+	}
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("log", map[string]string{}, map[string]interface{}{"line": "with", "trace_id": "abc"}, time.Unix(1, 0)),
+		testutil.MustMetric("log", map[string]string{}, map[string]interface{}{"line": "without"}, time.Unix(2, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	received := requests()
+	require.Len(t, received, 1)
+	require.Equal(t, "application/json", received[0].contentType)
+
+	var req struct {
+		Streams []struct {
+			Values [][]interface{} `json:"values"`
+		} `json:"streams"`
+	}
+	require.NoError(t, json.Unmarshal(received[0].body, &req))
+	require.Len(t, req.Streams, 1)
+	require.Equal(t, [][]interface{}{
+		{"1000000000", `line="with" `, map[string]interface{}{"trace_id": "abc"}},
+		{"2000000000", `line="without" `},
+	}, req.Streams[0].Values)
+}
+
+func TestProtobufPush(t *testing.T) {
+	ts, requests := newRecordingServer(t)
+
+	plugin := &Loki{
+		Domain:          ts.URL,
+		PushFormat:      "protobuf",
+		MetricNameLabel: "__name",
+		MetadataFields:  []string{"trace_id"},
+	}
+	require.NoError(t, plugin.Connect())
+
+	m := testutil.MustMetric(
+		"log",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"line": "my log", "trace_id": "abc"},
+		time.Unix(123, 456),
+	)
+	require.NoError(t, plugin.Write([]telegraf.Metric{m}))
+
+	received := requests()
+	require.Len(t, received, 1)
+	require.Equal(t, "application/x-protobuf", received[0].contentType)
+
+	buf, err := snappy.Decode(nil, received[0].body)
+	require.NoError(t, err)
+
+	// Build the expected message independently of the plugin's encoder
+	var timestamp []byte
+	timestamp = protowire.AppendTag(timestamp, 1, protowire.VarintType)
+	timestamp = protowire.AppendVarint(timestamp, 123)
+	timestamp = protowire.AppendTag(timestamp, 2, protowire.VarintType)
+	timestamp = protowire.AppendVarint(timestamp, 456)
+
+	var pair []byte
+	pair = protowire.AppendTag(pair, 1, protowire.BytesType)
+	pair = protowire.AppendString(pair, "trace_id")
+	pair = protowire.AppendTag(pair, 2, protowire.BytesType)
+	pair = protowire.AppendString(pair, "abc")
+
+	var entry []byte
+	entry = protowire.AppendTag(entry, 1, protowire.BytesType)
+	entry = protowire.AppendBytes(entry, timestamp)
+	entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+	entry = protowire.AppendString(entry, `line="my log" `)
+	entry = protowire.AppendTag(entry, 3, protowire.BytesType)
+	entry = protowire.AppendBytes(entry, pair)
+
+	var stream []byte
+	stream = protowire.AppendTag(stream, 1, protowire.BytesType)
+	stream = protowire.AppendString(stream, `{__name="log", host="a"}`)
+	stream = protowire.AppendTag(stream, 2, protowire.BytesType)
+	stream = protowire.AppendBytes(stream, entry)
+
+	var expected []byte
+	expected = protowire.AppendTag(expected, 1, protowire.BytesType)
+	expected = protowire.AppendBytes(expected, stream)
+
+	require.Equal(t, expected, buf)
+}
+
 func TestSanitizeLabelName(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,146 @@
+package loki
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the Loki push API protobuf messages, see
+// https://github.com/grafana/loki/blob/main/pkg/push/push.proto
+const (
+	pushRequestStreams = 1
+
+	streamLabels  = 1
+	streamEntries = 2
+
+	entryTimestamp          = 1
+	entryLine               = 2
+	entryStructuredMetadata = 3
+
+	timestampSeconds = 1
+	timestampNanos   = 2
+
+	labelPairName  = 1
+	labelPairValue = 2
+)
+
+// marshalProtobuf encodes the streams as Loki PushRequest message. The
+// message is small enough to be encoded by hand instead of pulling in the
+// generated Loki types.
+func (s Streams) marshalProtobuf() ([]byte, error) {
+	// Sort the streams to produce deterministic output
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf []byte
+	for _, k := range keys {
+		stream, err := s[k].marshalProtobuf()
+		if err != nil {
+			return nil, err
+		}
+		buf = protowire.AppendTag(buf, pushRequestStreams, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, stream)
+	}
+
+	return buf, nil
+}
+
+func (s *Stream) marshalProtobuf() ([]byte, error) {
+	var buf []byte
+	buf = protowire.AppendTag(buf, streamLabels, protowire.BytesType)
+	buf = protowire.AppendString(buf, labelString(s.Labels))
+
+	for i, l := range s.Logs {
+		if len(l) < 2 {
+			return nil, fmt.Errorf("invalid log entry with %d element(s)", len(l))
+		}
+		ts, err := strconv.ParseInt(l[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing timestamp %q failed: %w", l[0], err)
+		}
+
+		var md map[string]string
+		if i < len(s.Metadata) {
+			md = s.Metadata[i]
+		}
+
+		buf = protowire.AppendTag(buf, streamEntries, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, marshalEntry(ts, l[1], md))
+	}
+
+	return buf, nil
+}
+
+func marshalEntry(ts int64, line string, md map[string]string) []byte {
+	// Nanoseconds must be positive even for timestamps before the epoch
+	sec, nsec := ts/1e9, ts%1e9
+	if nsec < 0 {
+		sec--
+		nsec += 1e9
+	}
+
+	var timestamp []byte
+	if sec != 0 {
+		timestamp = protowire.AppendTag(timestamp, timestampSeconds, protowire.VarintType)
+		timestamp = protowire.AppendVarint(timestamp, uint64(sec))
+	}
+	if nsec != 0 {
+		timestamp = protowire.AppendTag(timestamp, timestampNanos, protowire.VarintType)
+		timestamp = protowire.AppendVarint(timestamp, uint64(nsec))
+	}
+
+	var buf []byte
+	buf = protowire.AppendTag(buf, entryTimestamp, protowire.BytesType)
+	buf = protowire.AppendBytes(buf, timestamp)
+	buf = protowire.AppendTag(buf, entryLine, protowire.BytesType)
+	buf = protowire.AppendString(buf, line)
+
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var pair []byte
+		pair = protowire.AppendTag(pair, labelPairName, protowire.BytesType)
+		pair = protowire.AppendString(pair, k)
+		pair = protowire.AppendTag(pair, labelPairValue, protowire.BytesType)
+		pair = protowire.AppendString(pair, md[k])
+
+		buf = protowire.AppendTag(buf, entryStructuredMetadata, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, pair)
+	}
+
+	return buf
+}
+
+// labelString formats the labels in the Prometheus notation expected by Loki
+// i.e. {key1="value1", key2="value2"}
+func labelString(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[k]))
+	}
+	b.WriteByte('}')
+
+	return b.String()
+}
@@ -14,8 +14,9 @@ type (
 	Streams map[string]*Stream
 
 	Stream struct {
-		Labels map[string]string `json:"stream"`
-		Logs   []Log             `json:"values"`
+		Labels   map[string]string   `json:"stream"`
+		Logs     []Log               `json:"values"`
+		Metadata []map[string]string `json:"-"`
 	}
 
 	Request struct {
@@ -24,6 +25,12 @@ type (
 )
 
 func (s Streams) insertLog(ts []*telegraf.Tag, l Log) {
+	s.insertLogWithMetadata(ts, l, nil)
+}
+
+// insertLogWithMetadata adds the log to the stream identified by the given
+// tags and attaches the structured metadata to this specific entry.
+func (s Streams) insertLogWithMetadata(ts []*telegraf.Tag, l Log, md map[string]string) {
 	key := uniqKeyFromTagList(ts)
 
 	if _, ok := s[key]; !ok {
@@ -31,6 +38,7 @@ func (s Streams) insertLog(ts []*telegraf.Tag, l Log) {
 	}
 
 	s[key].Logs = append(s[key].Logs, l)
+	s[key].Metadata = append(s[key].Metadata, md)
 }
 
 func (s Streams) MarshalJSON() ([]byte, error) {
@@ -45,6 +53,27 @@ func (s Streams) MarshalJSON() ([]byte, error) {
 	return json.Marshal(r)
 }
 
+// MarshalJSON encodes the stream's values as "[ts, line]" tuples and appends
+// the structured metadata object as third element if present.
+func (s Stream) MarshalJSON() ([]byte, error) {
+	values := make([]interface{}, 0, len(s.Logs))
+	for i, l := range s.Logs {
+		if i < len(s.Metadata) && len(s.Metadata[i]) > 0 && len(l) == 2 {
+			values = append(values, []interface{}{l[0], l[1], s.Metadata[i]})
+			continue
+		}
+		values = append(values, l)
+	}
+
+	return json.Marshal(struct {
+		Labels map[string]string `json:"stream"`
+		Values []interface{}     `json:"values"`
+	}{
+		Labels: s.Labels,
+		Values: values,
+	})
+}
+
 func uniqKeyFromTagList(ts []*telegraf.Tag) (k string) {
 	for _, t := range ts {
 		k += fmt.Sprintf("%s-%s-",
@@ -24,9 +24,9 @@ func (m *MQTT) collectHomieDeviceMessages(topic string, metric telegraf.Metric)
 			return nil, "", fmt.Errorf("generating device name failed: %w", err)
 		}
 		messages = append(messages,
-			message{topic + "/$homie", []byte("4.0")},
-			message{topic + "/$name", []byte(deviceName)},
-			message{topic + "/$state", []byte("ready")},
+			message{topic: topic + "/$homie", payload: []byte("4.0")},
+			message{topic: topic + "/$name", payload: []byte(deviceName)},
+			message{topic: topic + "/$state", payload: []byte("ready")},
 		)
 		m.homieSeen[topic] = make(map[string]bool)
 	}
@@ -46,8 +46,8 @@ func (m *MQTT) collectHomieDeviceMessages(topic string, metric telegraf.Metric)
 		}
 		sort.Strings(nodeIDs)
 		messages = append(messages,
-			message{topic + "/$nodes", []byte(strings.Join(nodeIDs, ","))},
-			message{topic + "/" + nodeID + "/$name", []byte(nodeName)},
+			message{topic: topic + "/$nodes", payload: []byte(strings.Join(nodeIDs, ","))},
+			message{topic: topic + "/" + nodeID + "/$name", payload: []byte(nodeName)},
 		)
 	}
 
@@ -61,8 +61,8 @@ func (m *MQTT) collectHomieDeviceMessages(topic string, metric telegraf.Metric)
 	sort.Strings(properties)
 
 	messages = append(messages, message{
-		topic + "/" + nodeID + "/$properties",
-		[]byte(strings.Join(properties, ",")),
+		topic:   topic + "/" + nodeID + "/$properties",
+		payload: []byte(strings.Join(properties, ",")),
 	})
 
 	return messages, nodeID, nil
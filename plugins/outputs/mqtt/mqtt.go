@@ -30,6 +30,9 @@ var hostnameRe = regexp.MustCompile(`({{.*\B)\.Hostname(\b[^}]*}})`)
 type message struct {
 	topic   string
 	payload []byte
+
+	// Metrics contained in the message used to derive per-message properties
+	metrics []telegraf.Metric
 }
 
 type MQTT struct {
@@ -116,6 +119,10 @@ func (m *MQTT) Init() error {
 		return fmt.Errorf("invalid layout %q", m.Layout)
 	}
 
+	if m.PublishPropertiesV5 != nil && m.PublishPropertiesV5.MessageExpiryFromMetric && m.PublishPropertiesV5.MessageExpiry <= 0 {
+		return errors.New("'message_expiry_from_metric' requires a positive 'message_expiry'")
+	}
+
 	return nil
 }
 
@@ -178,7 +185,12 @@ func (m *MQTT) Write(metrics []telegraf.Metric) error {
 	}
 
 	for _, msg := range topicMessages {
-		if err := m.client.Publish(msg.topic, msg.payload); err != nil {
+		properties, expired := m.messageProperties(msg)
+		if expired {
+			m.Log.Debugf("Dropping expired message for topic %q", msg.topic)
+			continue
+		}
+		if err := m.client.PublishWithProperties(msg.topic, msg.payload, properties); err != nil {
 			// We do receive a timeout error if the remote broker is down,
 			// so let's retry the metrics in this case and drop them otherwise.
 			if errors.Is(err, internal.ErrTimeout) {
@@ -207,7 +219,7 @@ func (m *MQTT) collectNonBatch(metrics []telegraf.Metric) []message {
 			m.Log.Debugf("metric was: %v", metric)
 			continue
 		}
-		collection = append(collection, message{topic, buf})
+		collection = append(collection, message{topic: topic, payload: buf, metrics: []telegraf.Metric{metric}})
 	}
 
 	return collection
@@ -232,7 +244,7 @@ func (m *MQTT) collectBatch(metrics []telegraf.Metric) []message {
 			m.Log.Warnf("Could not serialize metric batch for topic %q: %v", topic, err)
 			continue
 		}
-		collection = append(collection, message{topic, buf})
+		collection = append(collection, message{topic: topic, payload: buf, metrics: ms})
 	}
 	return collection
 }
@@ -254,7 +266,7 @@ func (m *MQTT) collectField(metrics []telegraf.Metric) []message {
 				m.Log.Debugf("metric was: %v", metric)
 				continue
 			}
-			collection = append(collection, message{topic + "/" + n, []byte(buf)})
+			collection = append(collection, message{topic: topic + "/" + n, payload: []byte(buf), metrics: []telegraf.Metric{metric}})
 		}
 	}
 
@@ -283,9 +295,9 @@ func (m *MQTT) collectHomieV4(metrics []telegraf.Metric) []message {
 		for _, tag := range metric.TagList() {
 			propID := normalizeID(tag.Key)
 			collection = append(collection,
-				message{path + "/" + propID, []byte(tag.Value)},
-				message{path + "/" + propID + "/$name", []byte(tag.Key)},
-				message{path + "/" + propID + "/$datatype", []byte("string")},
+				message{topic: path + "/" + propID, payload: []byte(tag.Value), metrics: []telegraf.Metric{metric}},
+				message{topic: path + "/" + propID + "/$name", payload: []byte(tag.Key)},
+				message{topic: path + "/" + propID + "/$datatype", payload: []byte("string")},
 			)
 		}
 
@@ -298,9 +310,9 @@ func (m *MQTT) collectHomieV4(metrics []telegraf.Metric) []message {
 			}
 			propID := normalizeID(field.Key)
 			collection = append(collection,
-				message{path + "/" + propID, []byte(v)},
-				message{path + "/" + propID + "/$name", []byte(field.Key)},
-				message{path + "/" + propID + "/$datatype", []byte(dt)},
+				message{topic: path + "/" + propID, payload: []byte(v), metrics: []telegraf.Metric{metric}},
+				message{topic: path + "/" + propID + "/$name", payload: []byte(field.Key)},
+				message{topic: path + "/" + propID + "/$datatype", payload: []byte(dt)},
 			)
 		}
 	}
@@ -308,6 +320,52 @@ func (m *MQTT) collectHomieV4(metrics []telegraf.Metric) []message {
 	return collection
 }
 
+// messageProperties derives the MQTT 5 properties of the message from the
+// contained metrics. The expiry is reduced by the age of the oldest metric
+// and tags are only used as user properties if all metrics share the value.
+// If the message already expired, the second return value is true.
+func (m *MQTT) messageProperties(msg message) (*mqtt.MessageProperties, bool) {
+	cfg := m.PublishPropertiesV5
+	if cfg == nil || len(msg.metrics) == 0 || (!cfg.MessageExpiryFromMetric && len(cfg.UserPropertyTags) == 0) {
+		return nil, false
+	}
+
+	properties := &mqtt.MessageProperties{}
+	if cfg.MessageExpiryFromMetric {
+		oldest := msg.metrics[0].Time()
+		for _, metric := range msg.metrics[1:] {
+			if metric.Time().Before(oldest) {
+				oldest = metric.Time()
+			}
+		}
+		expiry := time.Duration(cfg.MessageExpiry) - max(time.Since(oldest), 0)
+		if expiry <= 0 {
+			return nil, true
+		}
+		properties.MessageExpiry = &expiry
+	}
+
+	for _, key := range cfg.UserPropertyTags {
+		value, found := msg.metrics[0].GetTag(key)
+		for _, metric := range msg.metrics[1:] {
+			if !found {
+				break
+			}
+			v, ok := metric.GetTag(key)
+			found = ok && v == value
+		}
+		if !found {
+			continue
+		}
+		if properties.UserProperties == nil {
+			properties.UserProperties = make(map[string]string, len(cfg.UserPropertyTags))
+		}
+		properties.UserProperties[key] = value
+	}
+
+	return properties, false
+}
+
 func (m *MQTT) generateTopic(metric telegraf.Metric) (string, error) {
 	var b strings.Builder
 	err := m.template.Execute(&b, metric)
@@ -508,7 +508,7 @@ func TestIntegrationMQTTLayoutField(t *testing.T) {
 	onMessage := func(_ paho.Client, msg paho.Message) {
 		mtx.Lock()
 		defer mtx.Unlock()
-		received = append(received, message{msg.Topic(), msg.Payload()})
+		received = append(received, message{topic: msg.Topic(), payload: msg.Payload()})
 	}
 
 	// Add routing for the messages
@@ -625,7 +625,7 @@ func TestIntegrationMQTTLayoutHomieV4(t *testing.T) {
 	onMessage := func(_ paho.Client, msg paho.Message) {
 		mtx.Lock()
 		defer mtx.Unlock()
-		received = append(received, message{msg.Topic(), msg.Payload()})
+		received = append(received, message{topic: msg.Topic(), payload: msg.Payload()})
 	}
 
 	// Add routing for the messages
@@ -935,3 +935,63 @@ func TestGenerateTopicName(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageExpiryFromMetricInitFail(t *testing.T) {
+	plugin := &MQTT{
+		MqttConfig: mqtt.MqttConfig{
+			Servers:             []string{"tcp://localhost:1883"},
+			Protocol:            "5",
+			PublishPropertiesV5: &mqtt.PublishProperties{MessageExpiryFromMetric: true},
+		},
+	}
+	require.ErrorContains(t, plugin.Init(), "'message_expiry_from_metric' requires a positive 'message_expiry'")
+}
+
+func TestMessageProperties(t *testing.T) {
+	now := time.Now()
+	plugin := &MQTT{
+		MqttConfig: mqtt.MqttConfig{
+			Servers:  []string{"tcp://localhost:1883"},
+			Protocol: "5",
+			PublishPropertiesV5: &mqtt.PublishProperties{
+				MessageExpiry:           config.Duration(time.Hour),
+				MessageExpiryFromMetric: true,
+				UserPropertyTags:        []string{"host", "region", "missing"},
+			},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	// Single metric with all tags
+	msg := message{
+		topic: "telegraf/cpu",
+		metrics: []telegraf.Metric{
+			metric.New("cpu", map[string]string{"host": "a", "region": "eu"}, map[string]interface{}{"value": 1}, now.Add(-10*time.Minute)),
+		},
+	}
+	properties, expired := plugin.messageProperties(msg)
+	require.False(t, expired)
+	require.Equal(t, map[string]string{"host": "a", "region": "eu"}, properties.UserProperties)
+	require.InDelta(t, 50*time.Minute, *properties.MessageExpiry, float64(time.Minute))
+
+	// Batches use the oldest metric and only common tags
+	msg.metrics = append(msg.metrics,
+		metric.New("cpu", map[string]string{"host": "b", "region": "eu"}, map[string]interface{}{"value": 2}, now.Add(-30*time.Minute)),
+	)
+	properties, expired = plugin.messageProperties(msg)
+	require.False(t, expired)
+	require.Equal(t, map[string]string{"region": "eu"}, properties.UserProperties)
+	require.InDelta(t, 30*time.Minute, *properties.MessageExpiry, float64(time.Minute))
+
+	// Messages with outdated metrics expire
+	msg.metrics = append(msg.metrics,
+		metric.New("cpu", map[string]string{"host": "c"}, map[string]interface{}{"value": 3}, now.Add(-2*time.Hour)),
+	)
+	_, expired = plugin.messageProperties(msg)
+	require.True(t, expired)
+
+	// Messages without metrics only use the static properties
+	properties, expired = plugin.messageProperties(message{topic: "telegraf/$state"})
+	require.False(t, expired)
+	require.Nil(t, properties)
+}
@@ -0,0 +1,343 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package netdata
+
+import (
+	"bytes"
+	"crypto/tls"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Prompt sent by the parent if it accepts the stream
+const startStreamingPrompt = "Hit me baby, push them over"
+
+var idReplacer = strings.NewReplacer(" ", "_", ".", "_", "\"", "_", "'", "_", "/", "_", "\\", "_", "=", "_", ",", "_")
+
+type Netdata struct {
+	Address     string          `toml:"address"`
+	APIKey      config.Secret   `toml:"api_key"`
+	Hostname    string          `toml:"hostname"`
+	MachineGUID string          `toml:"machine_guid"`
+	UpdateEvery config.Duration `toml:"update_every"`
+	ChartPrefix string          `toml:"chart_prefix"`
+	Divisor     int64           `toml:"divisor"`
+	Timeout     config.Duration `toml:"timeout"`
+	Log         telegraf.Logger `toml:"-"`
+	common_tls.ClientConfig
+
+	tlsConfig *tls.Config
+	conn      net.Conn
+
+	// Charts defined on the current connection
+	charts map[string]*chart
+}
+
+type chart struct {
+	id         string
+	title      string
+	family     string
+	context    string
+	dimensions []string
+}
+
+// sample contains the latest values of a chart within a batch
+type sample struct {
+	chart  *chart
+	values map[string]int64
+}
+
+func (*Netdata) SampleConfig() string {
+	return sampleConfig
+}
+
+func (n *Netdata) Init() error {
+	if n.Address == "" {
+		return errors.New("address required")
+	}
+	if n.APIKey.Empty() {
+		return errors.New("api_key required")
+	}
+	if n.Divisor < 1 {
+		return errors.New("divisor must be positive")
+	}
+	if time.Duration(n.UpdateEvery) < time.Second {
+		return errors.New("update_every must be at least one second")
+	}
+	if n.ChartPrefix == "" {
+		return errors.New("chart_prefix required")
+	}
+
+	if n.Hostname == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("getting hostname failed: %w", err)
+		}
+		n.Hostname = hostname
+	}
+
+	if n.MachineGUID == "" {
+		n.MachineGUID = uuid.NewSHA1(uuid.NameSpaceDNS, []byte(n.Hostname)).String()
+	} else if _, err := uuid.Parse(n.MachineGUID); err != nil {
+		return fmt.Errorf("invalid machine_guid: %w", err)
+	}
+
+	tlsConfig, err := n.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	n.tlsConfig = tlsConfig
+
+	return nil
+}
+
+func (n *Netdata) Connect() error {
+	dialer := &net.Dialer{Timeout: time.Duration(n.Timeout)}
+
+	var conn net.Conn
+	var err error
+	if n.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", n.Address, n.tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", n.Address)
+	}
+	if err != nil {
+		return fmt.Errorf("connecting to %q failed: %w", n.Address, err)
+	}
+
+	if err := n.handshake(conn); err != nil {
+		conn.Close()
+		return err
+	}
+	n.conn = conn
+	n.charts = make(map[string]*chart)
+
+	return nil
+}
+
+func (n *Netdata) handshake(conn net.Conn) error {
+	key, err := n.APIKey.Get()
+	if err != nil {
+		return fmt.Errorf("getting API key failed: %w", err)
+	}
+	defer key.Destroy()
+
+	params := url.Values{}
+	params.Set("key", key.String())
+	params.Set("hostname", n.Hostname)
+	params.Set("registry_hostname", n.Hostname)
+	params.Set("machine_guid", n.MachineGUID)
+	params.Set("update_every", strconv.FormatInt(int64(time.Duration(n.UpdateEvery)/time.Second), 10))
+	params.Set("ver", "1")
+
+	request := "STREAM " + params.Encode() + " HTTP/1.1\r\n" +
+		"User-Agent: " + internal.ProductToken() + "\r\n" +
+		"Accept: */*\r\n\r\n"
+
+	if err := conn.SetDeadline(time.Now().Add(time.Duration(n.Timeout))); err != nil {
+		return err
+	}
+	defer conn.SetDeadline(time.Time{}) //nolint:errcheck // deadline is set again before writing
+
+	if _, err := conn.Write([]byte(request)); err != nil {
+		return fmt.Errorf("sending stream request failed: %w", err)
+	}
+
+	buf := make([]byte, 1024)
+	count, err := conn.Read(buf)
+	if err != nil {
+		return fmt.Errorf("reading stream response failed: %w", err)
+	}
+	response := strings.TrimSpace(string(buf[:count]))
+	if !strings.HasPrefix(response, startStreamingPrompt) {
+		return fmt.Errorf("stream rejected by parent: %s", response)
+	}
+
+	return nil
+}
+
+func (n *Netdata) Close() error {
+	if n.conn == nil {
+		return nil
+	}
+	err := n.conn.Close()
+	n.conn = nil
+	return err
+}
+
+func (n *Netdata) Write(metrics []telegraf.Metric) error {
+	if n.conn == nil {
+		if err := n.Connect(); err != nil {
+			return fmt.Errorf("reconnecting failed: %w", err)
+		}
+	}
+
+	// Only the latest value of each chart dimension can be sent per update
+	samples := make(map[string]*sample)
+	order := make([]string, 0)
+	for _, m := range metrics {
+		id := n.chartID(m)
+		s, found := samples[id]
+		if !found {
+			s = &sample{
+				chart:  n.newChart(id, m),
+				values: make(map[string]int64, len(m.FieldList())),
+			}
+			samples[id] = s
+			order = append(order, id)
+		}
+		for _, field := range m.FieldList() {
+			value, ok := n.convert(field.Value)
+			if !ok {
+				n.Log.Debugf("Skipping field %q of metric %q with unsupported value %v", field.Key, m.Name(), field.Value)
+				continue
+			}
+			s.values[idReplacer.Replace(field.Key)] = value
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, id := range order {
+		s := samples[id]
+		if len(s.values) == 0 {
+			continue
+		}
+		n.define(&buf, s)
+
+		fmt.Fprintf(&buf, "BEGIN %q\n", s.chart.id)
+		for _, dim := range s.chart.dimensions {
+			if value, found := s.values[dim]; found {
+				fmt.Fprintf(&buf, "SET %q = %d\n", dim, value)
+			}
+		}
+		buf.WriteString("END\n")
+	}
+
+	if err := n.conn.SetWriteDeadline(time.Now().Add(time.Duration(n.Timeout))); err != nil {
+		return err
+	}
+	if _, err := n.conn.Write(buf.Bytes()); err != nil {
+		// Force a reconnect and resending all chart definitions
+		n.Close()
+		return fmt.Errorf("writing to %q failed: %w", n.Address, err)
+	}
+
+	return nil
+}
+
+// define sends the chart definition if the chart is unknown on the current
+// connection or if new dimensions were added to it
+func (n *Netdata) define(buf *bytes.Buffer, s *sample) {
+	current, found := n.charts[s.chart.id]
+	if !found {
+		current = s.chart
+	}
+
+	dimensions := slices.Clone(current.dimensions)
+	for dim := range s.values {
+		if !slices.Contains(dimensions, dim) {
+			dimensions = append(dimensions, dim)
+		}
+	}
+	if found && len(dimensions) == len(current.dimensions) {
+		s.chart = current
+		return
+	}
+	slices.Sort(dimensions)
+	current.dimensions = dimensions
+	n.charts[current.id] = current
+	s.chart = current
+
+	fmt.Fprintf(buf, "CHART %q %q %q %q %q %q %q %d %d %q %q %q\n",
+		current.id, "", current.title, "value", current.family, current.context, "line",
+		100000, int64(time.Duration(n.UpdateEvery)/time.Second), "", "telegraf", current.family)
+	for _, dim := range current.dimensions {
+		fmt.Fprintf(buf, "DIMENSION %q %q %q %d %d %q\n", dim, dim, "absolute", 1, n.Divisor, "")
+	}
+}
+
+func (n *Netdata) chartID(m telegraf.Metric) string {
+	parts := make([]string, 0, len(m.TagList())+1)
+	parts = append(parts, m.Name())
+	for _, tag := range m.TagList() {
+		parts = append(parts, tag.Value)
+	}
+	return n.ChartPrefix + "." + idReplacer.Replace(strings.Join(parts, "_"))
+}
+
+func (n *Netdata) newChart(id string, m telegraf.Metric) *chart {
+	tags := make([]string, 0, len(m.TagList()))
+	for _, tag := range m.TagList() {
+		tags = append(tags, tag.Key+"="+tag.Value)
+	}
+	title := m.Name()
+	if len(tags) > 0 {
+		title += " " + strings.Join(tags, ",")
+	}
+
+	return &chart{
+		id:      id,
+		title:   strings.ReplaceAll(title, `"`, "'"),
+		family:  idReplacer.Replace(m.Name()),
+		context: n.ChartPrefix + "." + idReplacer.Replace(m.Name()),
+	}
+}
+
+// convert scales the given field value by the divisor to an integer as
+// Netdata does not accept floating-point values
+func (n *Netdata) convert(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case float64:
+		scaled := math.Round(v * float64(n.Divisor))
+		if math.IsNaN(scaled) || scaled >= math.MaxInt64 || scaled < math.MinInt64 {
+			return 0, false
+		}
+		return int64(scaled), true
+	case int64:
+		if v > math.MaxInt64/n.Divisor || v < math.MinInt64/n.Divisor {
+			return 0, false
+		}
+		return v * n.Divisor, true
+	case uint64:
+		if v > uint64(math.MaxInt64/n.Divisor) {
+			return 0, false
+		}
+		return int64(v) * n.Divisor, true
+	case bool:
+		if v {
+			return n.Divisor, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func init() {
+	outputs.Add("netdata", func() telegraf.Output {
+		return &Netdata{
+			UpdateEvery: config.Duration(10 * time.Second),
+			ChartPrefix: "telegraf",
+			Divisor:     1000,
+			Timeout:     config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,243 @@
+package netdata
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const prompt = "Hit me baby, push them over..."
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Netdata
+		expected string
+	}{
+		{
+			name:     "missing address",
+			plugin:   &Netdata{APIKey: config.NewSecret([]byte("key")), Divisor: 1, UpdateEvery: config.Duration(time.Second)},
+			expected: "address required",
+		},
+		{
+			name:     "missing API key",
+			plugin:   &Netdata{Address: "localhost:19999", Divisor: 1, UpdateEvery: config.Duration(time.Second)},
+			expected: "api_key required",
+		},
+		{
+			name: "invalid divisor",
+			plugin: &Netdata{
+				Address:     "localhost:19999",
+				APIKey:      config.NewSecret([]byte("key")),
+				UpdateEvery: config.Duration(time.Second),
+			},
+			expected: "divisor must be positive",
+		},
+		{
+			name: "invalid update interval",
+			plugin: &Netdata{
+				Address: "localhost:19999",
+				APIKey:  config.NewSecret([]byte("key")),
+				Divisor: 1,
+			},
+			expected: "update_every must be at least one second",
+		},
+		{
+			name: "invalid machine GUID",
+			plugin: &Netdata{
+				Address:     "localhost:19999",
+				APIKey:      config.NewSecret([]byte("key")),
+				MachineGUID: "foo",
+				ChartPrefix: "telegraf",
+				Divisor:     1,
+				UpdateEvery: config.Duration(time.Second),
+			},
+			expected: "invalid machine_guid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	p := newParent(t, prompt)
+
+	plugin := &Netdata{
+		Address:     p.address(),
+		APIKey:      config.NewSecret([]byte("11111111-2222-3333-4444-555555555555")),
+		Hostname:    "child",
+		MachineGUID: "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee",
+		UpdateEvery: config.Duration(10 * time.Second),
+		ChartPrefix: "telegraf",
+		Divisor:     1000,
+		Timeout:     config.Duration(5 * time.Second),
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	request := <-p.request
+	require.True(t, strings.HasPrefix(request, "STREAM "), request)
+	require.Contains(t, request, "key=11111111-2222-3333-4444-555555555555")
+	require.Contains(t, request, "hostname=child")
+	require.Contains(t, request, "machine_guid=aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee")
+	require.Contains(t, request, "update_every=10")
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 95.1, "usage_user": 2.3},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 96.0},
+			time.Unix(10, 0),
+		),
+		metric.New(
+			"system",
+			map[string]string{},
+			map[string]interface{}{"load1": int64(2), "uptime": uint64(42), "online": true, "name": "foo"},
+			time.Unix(0, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	// Only new dimensions trigger resending the chart definition
+	metrics = []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 97.5},
+			time.Unix(20, 0),
+		),
+		metric.New(
+			"system",
+			map[string]string{},
+			map[string]interface{}{"load5": 0.5},
+			time.Unix(20, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.NoError(t, plugin.Close())
+
+	expected := `CHART "telegraf.cpu_cpu0" "" "cpu cpu=cpu0" "value" "cpu" "telegraf.cpu" "line" 100000 10 "" "telegraf" "cpu"
+DIMENSION "usage_idle" "usage_idle" "absolute" 1 1000 ""
+DIMENSION "usage_user" "usage_user" "absolute" 1 1000 ""
+BEGIN "telegraf.cpu_cpu0"
+SET "usage_idle" = 96000
+SET "usage_user" = 2300
+END
+CHART "telegraf.system" "" "system" "value" "system" "telegraf.system" "line" 100000 10 "" "telegraf" "system"
+DIMENSION "load1" "load1" "absolute" 1 1000 ""
+DIMENSION "online" "online" "absolute" 1 1000 ""
+DIMENSION "uptime" "uptime" "absolute" 1 1000 ""
+BEGIN "telegraf.system"
+SET "load1" = 2000
+SET "online" = 1000
+SET "uptime" = 42000
+END
+BEGIN "telegraf.cpu_cpu0"
+SET "usage_idle" = 97500
+END
+CHART "telegraf.system" "" "system" "value" "system" "telegraf.system" "line" 100000 10 "" "telegraf" "system"
+DIMENSION "load1" "load1" "absolute" 1 1000 ""
+DIMENSION "load5" "load5" "absolute" 1 1000 ""
+DIMENSION "online" "online" "absolute" 1 1000 ""
+DIMENSION "uptime" "uptime" "absolute" 1 1000 ""
+BEGIN "telegraf.system"
+SET "load5" = 500
+END
+`
+	require.Equal(t, expected, <-p.data)
+}
+
+func TestConnectRejected(t *testing.T) {
+	p := newParent(t, "START_STREAMING_ERROR_NOT_PERMITTED")
+
+	plugin := &Netdata{
+		Address:     p.address(),
+		APIKey:      config.NewSecret([]byte("11111111-2222-3333-4444-555555555555")),
+		UpdateEvery: config.Duration(10 * time.Second),
+		ChartPrefix: "telegraf",
+		Divisor:     1000,
+		Timeout:     config.Duration(5 * time.Second),
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.ErrorContains(t, plugin.Connect(), "START_STREAMING_ERROR_NOT_PERMITTED")
+}
+
+// parent accepts a single stream connection answering the stream request with
+// the given response
+type parent struct {
+	listener net.Listener
+	request  chan string
+	data     chan string
+}
+
+func newParent(t *testing.T, response string) *parent {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	p := &parent{
+		listener: listener,
+		request:  make(chan string, 1),
+		data:     make(chan string, 1),
+	}
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		var request strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if line == "\r\n" {
+				break
+			}
+			request.WriteString(line)
+		}
+		p.request <- request.String()
+
+		if _, err := conn.Write([]byte(response)); err != nil {
+			return
+		}
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return
+		}
+		p.data <- string(data)
+	}()
+
+	return p
+}
+
+func (p *parent) address() string {
+	return p.listener.Addr().String()
+}
@@ -5,12 +5,15 @@ import (
 	"context"
 	ntls "crypto/tls"
 	_ "embed"
+	"fmt"
 	"sort"
 	"time"
 
 	"github.com/influxdata/influxdb-observability/common"
 	"github.com/influxdata/influxdb-observability/influx2otel"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
@@ -19,6 +22,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -39,12 +43,21 @@ type OpenTelemetry struct {
 	Attributes  map[string]string `toml:"attributes"`
 	Coralogix   *CoralogixConfig  `toml:"coralogix"`
 
+	LogsMeasurements   []string `toml:"logs_measurements"`
+	LogBodyFields      []string `toml:"log_body_fields"`
+	TracesMeasurements []string `toml:"traces_measurements"`
+
 	Log telegraf.Logger `toml:"-"`
 
 	metricsConverter     *influx2otel.LineProtocolToOtelMetrics
 	grpcClientConn       *grpc.ClientConn
 	metricsServiceClient pmetricotlp.GRPCClient
+	logsServiceClient    plogotlp.GRPCClient
+	tracesServiceClient  ptraceotlp.GRPCClient
 	callOptions          []grpc.CallOption
+
+	logsFilter   filter.Filter
+	tracesFilter filter.Filter
 }
 
 type CoralogixConfig struct {
@@ -83,6 +96,13 @@ func (o *OpenTelemetry) Connect() error {
 		return err
 	}
 
+	if o.logsFilter, err = filter.Compile(o.LogsMeasurements); err != nil {
+		return fmt.Errorf("compiling logs measurements filter failed: %w", err)
+	}
+	if o.tracesFilter, err = filter.Compile(o.TracesMeasurements); err != nil {
+		return fmt.Errorf("compiling traces measurements filter failed: %w", err)
+	}
+
 	var grpcTLSDialOption grpc.DialOption
 	if tlsConfig, err := o.ClientConfig.TLSConfig(); err != nil {
 		return err
@@ -105,6 +125,8 @@ func (o *OpenTelemetry) Connect() error {
 	o.metricsConverter = metricsConverter
 	o.grpcClientConn = grpcClientConn
 	o.metricsServiceClient = metricsServiceClient
+	o.logsServiceClient = plogotlp.NewGRPCClient(grpcClientConn)
+	o.tracesServiceClient = ptraceotlp.NewGRPCClient(grpcClientConn)
 
 	if o.Compression != "" && o.Compression != "none" {
 		o.callOptions = append(o.callOptions, grpc.UseCompressor(o.Compression))
@@ -124,6 +146,34 @@ func (o *OpenTelemetry) Close() error {
 
 // Split metrics up by timestamp and send to Google Cloud Stackdriver
 func (o *OpenTelemetry) Write(metrics []telegraf.Metric) error {
+	// Separate the metrics to be sent as logs or traces
+	var logs, spans []telegraf.Metric
+	if o.logsFilter != nil || o.tracesFilter != nil {
+		remaining := make([]telegraf.Metric, 0, len(metrics))
+		for _, metric := range metrics {
+			switch {
+			case o.logsFilter != nil && o.logsFilter.Match(metric.Name()):
+				logs = append(logs, metric)
+			case o.tracesFilter != nil && o.tracesFilter.Match(metric.Name()):
+				spans = append(spans, metric)
+			default:
+				remaining = append(remaining, metric)
+			}
+		}
+		metrics = remaining
+	}
+
+	if len(logs) > 0 {
+		if err := o.sendLogs(logs); err != nil {
+			return fmt.Errorf("sending logs failed: %w", err)
+		}
+	}
+	if len(spans) > 0 {
+		if err := o.sendTraces(spans); err != nil {
+			return fmt.Errorf("sending traces failed: %w", err)
+		}
+	}
+
 	metricBatch := make(map[int64][]telegraf.Metric)
 	timestamps := make([]int64, 0, len(metrics))
 	for _, metric := range metrics {
@@ -188,16 +238,44 @@ func (o *OpenTelemetry) sendBatch(metrics []telegraf.Metric) error {
 		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Timeout))
+	ctx, cancel := o.requestContext()
+	defer cancel()
+	_, err := o.metricsServiceClient.Export(ctx, md, o.callOptions...)
+	return err
+}
 
-	if len(o.Headers) > 0 {
-		ctx = metadata.NewOutgoingContext(ctx, metadata.New(o.Headers))
+func (o *OpenTelemetry) sendLogs(metrics []telegraf.Metric) error {
+	ld := plogotlp.NewExportRequestFromLogs(o.convertLogs(metrics))
+	if ld.Logs().LogRecordCount() == 0 {
+		return nil
 	}
+
+	ctx, cancel := o.requestContext()
 	defer cancel()
-	_, err := o.metricsServiceClient.Export(ctx, md, o.callOptions...)
+	_, err := o.logsServiceClient.Export(ctx, ld, o.callOptions...)
+	return err
+}
+
+func (o *OpenTelemetry) sendTraces(metrics []telegraf.Metric) error {
+	td := ptraceotlp.NewExportRequestFromTraces(o.convertTraces(metrics))
+	if td.Traces().SpanCount() == 0 {
+		return nil
+	}
+
+	ctx, cancel := o.requestContext()
+	defer cancel()
+	_, err := o.tracesServiceClient.Export(ctx, td, o.callOptions...)
 	return err
 }
 
+func (o *OpenTelemetry) requestContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(o.Timeout))
+	if len(o.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(o.Headers))
+	}
+	return ctx, cancel
+}
+
 const (
 	defaultServiceAddress = "localhost:4317"
 	defaultTimeout        = config.Duration(5 * time.Second)
@@ -210,6 +288,7 @@ func init() {
 			ServiceAddress: defaultServiceAddress,
 			Timeout:        defaultTimeout,
 			Compression:    defaultCompression,
+			LogBodyFields:  []string{"body", "message"},
 		}
 	})
 }
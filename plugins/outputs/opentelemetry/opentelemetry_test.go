@@ -10,8 +10,12 @@ import (
 	"github.com/influxdata/influxdb-observability/influx2otel"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
@@ -19,6 +23,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -81,6 +86,155 @@ func TestOpenTelemetry(t *testing.T) {
 	require.JSONEq(t, string(expectJSON), string(gotJSON))
 }
 
+func TestOpenTelemetryLogsAndTraces(t *testing.T) {
+	expectLogs := plog.NewLogs()
+	{
+		rl := expectLogs.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().PutStr("service.name", "shop")
+		rl.Resource().Attributes().PutStr("attr-key", "attr-val")
+		sl := rl.ScopeLogs().AppendEmpty()
+		sl.Scope().SetName("My Library Name")
+		lr := sl.LogRecords().AppendEmpty()
+		lr.SetTimestamp(pcommon.Timestamp(1622848686000000000))
+		lr.SetObservedTimestamp(pcommon.Timestamp(1622848686000000001))
+		lr.SetTraceID(pcommon.TraceID{0x5b, 0x8e, 0xff, 0xf7, 0x98, 0x03, 0x81, 0x03, 0xd2, 0x69, 0xb6, 0x33, 0x81, 0x3f, 0xc6, 0x0c})
+		lr.SetSpanID(pcommon.SpanID{0xee, 0xe1, 0x9b, 0x7e, 0xc3, 0xc1, 0xb1, 0x74})
+		lr.SetSeverityNumber(plog.SeverityNumberInfo)
+		lr.SetSeverityText("info")
+		lr.Body().SetStr("order placed")
+		lr.Attributes().PutStr("customer", "acme")
+		lr.Attributes().PutInt("items", 3)
+
+		rl = expectLogs.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().PutStr("attr-key", "attr-val")
+		sl = rl.ScopeLogs().AppendEmpty()
+		lr = sl.LogRecords().AppendEmpty()
+		lr.SetTimestamp(pcommon.Timestamp(1622848687000000000))
+		lr.SetSeverityText("err")
+		lr.Body().SetStr("disk full")
+		lr.Attributes().PutStr("appname", "kernel")
+	}
+
+	expectTraces := ptrace.NewTraces()
+	{
+		rs := expectTraces.ResourceSpans().AppendEmpty()
+		rs.Resource().Attributes().PutStr("service.name", "shop")
+		rs.Resource().Attributes().PutStr("attr-key", "attr-val")
+		ss := rs.ScopeSpans().AppendEmpty()
+		span := ss.Spans().AppendEmpty()
+		span.SetTraceID(pcommon.TraceID{0x5b, 0x8e, 0xff, 0xf7, 0x98, 0x03, 0x81, 0x03, 0xd2, 0x69, 0xb6, 0x33, 0x81, 0x3f, 0xc6, 0x0c})
+		span.SetSpanID(pcommon.SpanID{0xee, 0xe1, 0x9b, 0x7e, 0xc3, 0xc1, 0xb1, 0x74})
+		span.SetParentSpanID(pcommon.SpanID{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88})
+		span.SetName("checkout")
+		span.SetKind(ptrace.SpanKindServer)
+		span.SetStartTimestamp(pcommon.Timestamp(1622848686000000000))
+		span.SetEndTimestamp(pcommon.Timestamp(1622848686250000000))
+		span.Status().SetCode(ptrace.StatusCodeError)
+		span.Status().SetMessage("out of stock")
+		span.Attributes().PutStr("http.method", "POST")
+	}
+
+	m := newMockOtelService(t)
+	t.Cleanup(m.Cleanup)
+
+	metricsConverter, err := influx2otel.NewLineProtocolToOtelMetrics(common.NoopLogger{})
+	require.NoError(t, err)
+	plugin := &OpenTelemetry{
+		ServiceAddress:       m.Address(),
+		Timeout:              config.Duration(time.Second),
+		Headers:              map[string]string{"test": "header1"},
+		Attributes:           map[string]string{"attr-key": "attr-val"},
+		LogBodyFields:        []string{"body", "message"},
+		metricsConverter:     metricsConverter,
+		grpcClientConn:       m.GrpcClient(),
+		metricsServiceClient: pmetricotlp.NewGRPCClient(m.GrpcClient()),
+		logsServiceClient:    plogotlp.NewGRPCClient(m.GrpcClient()),
+		tracesServiceClient:  ptraceotlp.NewGRPCClient(m.GrpcClient()),
+		Log:                  testutil.Logger{},
+	}
+	plugin.logsFilter, err = filter.Compile([]string{"logs", "syslog"})
+	require.NoError(t, err)
+	plugin.tracesFilter, err = filter.Compile([]string{"spans"})
+	require.NoError(t, err)
+
+	input := []telegraf.Metric{
+		testutil.MustMetric(
+			"logs",
+			map[string]string{
+				"service.name":      "shop",
+				"otel.library.name": "My Library Name",
+				"trace_id":          "5b8efff798038103d269b633813fc60c",
+				"span_id":           "eee19b7ec3c1b174",
+			},
+			map[string]interface{}{
+				"body":                    "order placed",
+				"severity_number":         int64(9),
+				"severity_text":           "info",
+				"observed_time_unix_nano": int64(1622848686000000001),
+				"attributes":              `{"customer":"acme"}`,
+				"items":                   int64(3),
+			},
+			time.Unix(0, 1622848686000000000),
+		),
+		testutil.MustMetric(
+			"syslog",
+			map[string]string{
+				"severity": "err",
+				"appname":  "kernel",
+			},
+			map[string]interface{}{
+				"message": "disk full",
+			},
+			time.Unix(0, 1622848687000000000),
+		),
+		testutil.MustMetric(
+			"spans",
+			map[string]string{
+				"service.name": "shop",
+				"trace_id":     "5b8efff798038103d269b633813fc60c",
+				"span_id":      "eee19b7ec3c1b174",
+			},
+			map[string]interface{}{
+				"span.name":               "checkout",
+				"span.kind":               "Server",
+				"parent_span_id":          "1122334455667788",
+				"duration_nano":           int64(250000000),
+				"otel.status_code":        "Error",
+				"otel.status_description": "out of stock",
+				"attributes":              `{"http.method":"POST"}`,
+			},
+			time.Unix(0, 1622848686000000000),
+		),
+		testutil.MustMetric(
+			"spans",
+			map[string]string{
+				"trace_id": "invalid",
+				"span_id":  "eee19b7ec3c1b174",
+			},
+			map[string]interface{}{
+				"span.name": "dropped",
+			},
+			time.Unix(0, 1622848686000000000),
+		),
+	}
+	require.NoError(t, plugin.Write(input))
+
+	// No metrics must be sent
+	require.Equal(t, pmetric.Metrics{}, m.GotMetrics())
+
+	logsJSON, err := (&plog.JSONMarshaler{}).MarshalLogs(expectLogs)
+	require.NoError(t, err)
+	gotLogsJSON, err := (&plog.JSONMarshaler{}).MarshalLogs(m.logs.logs)
+	require.NoError(t, err)
+	require.JSONEq(t, string(logsJSON), string(gotLogsJSON))
+
+	tracesJSON, err := (&ptrace.JSONMarshaler{}).MarshalTraces(expectTraces)
+	require.NoError(t, err)
+	gotTracesJSON, err := (&ptrace.JSONMarshaler{}).MarshalTraces(m.traces.traces)
+	require.NoError(t, err)
+	require.JSONEq(t, string(tracesJSON), string(gotTracesJSON))
+}
+
 var _ pmetricotlp.GRPCServer = (*mockOtelService)(nil)
 
 type mockOtelService struct {
@@ -91,6 +245,8 @@ type mockOtelService struct {
 	grpcClient *grpc.ClientConn
 
 	metrics pmetric.Metrics
+	logs    *mockLogsService
+	traces  *mockTracesService
 }
 
 func newMockOtelService(t *testing.T) *mockOtelService {
@@ -102,9 +258,13 @@ func newMockOtelService(t *testing.T) *mockOtelService {
 		t:          t,
 		listener:   listener,
 		grpcServer: grpcServer,
+		logs:       &mockLogsService{},
+		traces:     &mockTracesService{},
 	}
 
 	pmetricotlp.RegisterGRPCServer(grpcServer, mockOtelService)
+	plogotlp.RegisterGRPCServer(grpcServer, mockOtelService.logs)
+	ptraceotlp.RegisterGRPCServer(grpcServer, mockOtelService.traces)
 	go func() {
 		if err := grpcServer.Serve(listener); err != nil {
 			t.Error(err)
@@ -144,3 +304,25 @@ func (m *mockOtelService) Export(ctx context.Context, request pmetricotlp.Export
 	require.True(m.t, ok)
 	return pmetricotlp.NewExportResponse(), nil
 }
+
+type mockLogsService struct {
+	plogotlp.UnimplementedGRPCServer
+	logs plog.Logs
+}
+
+func (m *mockLogsService) Export(_ context.Context, request plogotlp.ExportRequest) (plogotlp.ExportResponse, error) {
+	m.logs = plog.NewLogs()
+	request.Logs().CopyTo(m.logs)
+	return plogotlp.NewExportResponse(), nil
+}
+
+type mockTracesService struct {
+	ptraceotlp.UnimplementedGRPCServer
+	traces ptrace.Traces
+}
+
+func (m *mockTracesService) Export(_ context.Context, request ptraceotlp.ExportRequest) (ptraceotlp.ExportResponse, error) {
+	m.traces = ptrace.NewTraces()
+	request.Traces().CopyTo(m.traces)
+	return ptraceotlp.NewExportResponse(), nil
+}
@@ -0,0 +1,366 @@
+package opentelemetry
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb-observability/common"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Keys used by the opentelemetry input in addition to the ones defined in
+// the influxdb-observability common package
+const (
+	keyLibraryName       = "otel.library.name"
+	keyLibraryVersion    = "otel.library.version"
+	keyStatusCode        = "otel.status_code"
+	keyStatusDescription = "otel.status_description"
+	keySeverity          = "severity"
+)
+
+var spanKinds = map[string]ptrace.SpanKind{
+	ptrace.SpanKindUnspecified.String(): ptrace.SpanKindUnspecified,
+	ptrace.SpanKindInternal.String():    ptrace.SpanKindInternal,
+	ptrace.SpanKindServer.String():      ptrace.SpanKindServer,
+	ptrace.SpanKindClient.String():      ptrace.SpanKindClient,
+	ptrace.SpanKindProducer.String():    ptrace.SpanKindProducer,
+	ptrace.SpanKindConsumer.String():    ptrace.SpanKindConsumer,
+}
+
+var statusCodes = map[string]ptrace.StatusCode{
+	ptrace.StatusCodeUnset.String(): ptrace.StatusCodeUnset,
+	ptrace.StatusCodeOk.String():    ptrace.StatusCodeOk,
+	ptrace.StatusCodeError.String(): ptrace.StatusCodeError,
+}
+
+// signal holds the properties of a metric common to all OpenTelemetry
+// signals, i.e. the resource, the instrumentation scope and the attributes.
+type signal struct {
+	resource     map[string]string
+	scopeName    string
+	scopeVersion string
+	tags         map[string]string
+	fields       map[string]interface{}
+}
+
+func newSignal(m telegraf.Metric) *signal {
+	s := &signal{
+		resource: make(map[string]string),
+		tags:     make(map[string]string),
+		fields:   m.Fields(),
+	}
+	for _, tag := range m.TagList() {
+		switch {
+		case tag.Key == keyLibraryName:
+			s.scopeName = tag.Value
+		case tag.Key == keyLibraryVersion:
+			s.scopeVersion = tag.Value
+		case common.ResourceNamespace.MatchString(tag.Key):
+			s.resource[tag.Key] = tag.Value
+		default:
+			s.tags[tag.Key] = tag.Value
+		}
+	}
+	return s
+}
+
+// resourceKey returns a key identifying the resource of the signal
+func (s *signal) resourceKey() string {
+	keys := make([]string, 0, len(s.resource))
+	for k := range s.resource {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var key strings.Builder
+	for _, k := range keys {
+		key.WriteString(k + "=" + s.resource[k] + "\x00")
+	}
+	return key.String()
+}
+
+// popTag removes the tag with the given key and returns its value
+func (s *signal) popTag(key string) (string, bool) {
+	v, found := s.tags[key]
+	delete(s.tags, key)
+	return v, found
+}
+
+// popField removes the field with the given key and returns its value
+func (s *signal) popField(key string) (interface{}, bool) {
+	v, found := s.fields[key]
+	delete(s.fields, key)
+	return v, found
+}
+
+func (s *signal) popString(key string) (string, bool) {
+	if v, found := s.popTag(key); found {
+		return v, true
+	}
+	v, found := s.popField(key)
+	if !found {
+		return "", false
+	}
+	if sv, ok := v.(string); ok {
+		return sv, true
+	}
+	return fmt.Sprintf("%v", v), true
+}
+
+func (s *signal) popInt(key string) (int64, bool) {
+	v, found := s.popField(key)
+	if !found {
+		return 0, false
+	}
+	switch v := v.(type) {
+	case int64:
+		return v, true
+	case uint64:
+		return int64(v), true
+	case float64:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// putAttributes adds the remaining tags and fields to the given attributes.
+// The "attributes" field written by the opentelemetry input is expanded.
+func (s *signal) putAttributes(attributes pcommon.Map) error {
+	if v, found := s.popField(common.AttributeAttributes); found {
+		raw := make(map[string]interface{})
+		if sv, ok := v.(string); !ok {
+			return errors.New("attributes field is not a string")
+		} else if err := json.Unmarshal([]byte(sv), &raw); err != nil {
+			return fmt.Errorf("decoding attributes field failed: %w", err)
+		}
+		for k, v := range raw {
+			if err := attributes.PutEmpty(k).FromRaw(v); err != nil {
+				return fmt.Errorf("converting attribute %q failed: %w", k, err)
+			}
+		}
+	}
+	for k, v := range s.tags {
+		attributes.PutStr(k, v)
+	}
+	for k, v := range s.fields {
+		if err := attributes.PutEmpty(k).FromRaw(v); err != nil {
+			return fmt.Errorf("converting field %q failed: %w", k, err)
+		}
+	}
+	return nil
+}
+
+func (o *OpenTelemetry) convertLogs(metrics []telegraf.Metric) plog.Logs {
+	logs := plog.NewLogs()
+	resources := make(map[string]plog.ResourceLogs)
+	scopes := make(map[string]plog.ScopeLogs)
+
+	for _, m := range metrics {
+		s := newSignal(m)
+
+		rkey := s.resourceKey()
+		rl, found := resources[rkey]
+		if !found {
+			rl = logs.ResourceLogs().AppendEmpty()
+			for k, v := range s.resource {
+				rl.Resource().Attributes().PutStr(k, v)
+			}
+			for k, v := range o.Attributes {
+				rl.Resource().Attributes().PutStr(k, v)
+			}
+			resources[rkey] = rl
+		}
+		skey := rkey + "\x01" + s.scopeName + "\x00" + s.scopeVersion
+		sl, found := scopes[skey]
+		if !found {
+			sl = rl.ScopeLogs().AppendEmpty()
+			sl.Scope().SetName(s.scopeName)
+			sl.Scope().SetVersion(s.scopeVersion)
+			scopes[skey] = sl
+		}
+
+		record := sl.LogRecords().AppendEmpty()
+		record.SetTimestamp(pcommon.NewTimestampFromTime(m.Time()))
+		if ts, found := s.popInt(common.AttributeObservedTimeUnixNano); found {
+			record.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Unix(0, ts)))
+		}
+		for _, key := range o.LogBodyFields {
+			if v, found := s.popField(key); found {
+				if err := record.Body().FromRaw(v); err != nil {
+					o.Log.Warnf("Converting log body of %q failed: %v", m.Name(), err)
+				}
+				break
+			}
+		}
+		if v, found := s.popInt(common.AttributeSeverityNumber); found {
+			record.SetSeverityNumber(plog.SeverityNumber(int32(v)))
+		}
+		if v, found := s.popString(common.AttributeSeverityText); found {
+			record.SetSeverityText(v)
+		} else if v, found := s.popString(keySeverity); found {
+			record.SetSeverityText(v)
+		}
+		if v, found := s.popInt(common.AttributeDroppedAttributesCount); found {
+			record.SetDroppedAttributesCount(uint32(v))
+		}
+
+		traceID, hasTraceID := s.popTag(common.AttributeTraceID)
+		spanID, hasSpanID := s.popTag(common.AttributeSpanID)
+		if hasTraceID && hasSpanID {
+			tid, err := parseTraceID(traceID)
+			if err != nil {
+				o.Log.Warnf("Invalid trace ID in %q: %v", m.Name(), err)
+			}
+			sid, err := parseSpanID(spanID)
+			if err != nil {
+				o.Log.Warnf("Invalid span ID in %q: %v", m.Name(), err)
+			}
+			record.SetTraceID(tid)
+			record.SetSpanID(sid)
+		}
+
+		if err := s.putAttributes(record.Attributes()); err != nil {
+			o.Log.Warnf("Converting log attributes of %q failed: %v", m.Name(), err)
+		}
+	}
+
+	return logs
+}
+
+func (o *OpenTelemetry) convertTraces(metrics []telegraf.Metric) ptrace.Traces {
+	traces := ptrace.NewTraces()
+	resources := make(map[string]ptrace.ResourceSpans)
+	scopes := make(map[string]ptrace.ScopeSpans)
+
+	for _, m := range metrics {
+		s := newSignal(m)
+
+		// Spans require a trace and span ID
+		traceIDTag, _ := s.popTag(common.AttributeTraceID)
+		traceID, err := parseTraceID(traceIDTag)
+		if err != nil {
+			o.Log.Warnf("Invalid trace ID in %q, dropping span: %v", m.Name(), err)
+			continue
+		}
+		spanIDTag, _ := s.popTag(common.AttributeSpanID)
+		spanID, err := parseSpanID(spanIDTag)
+		if err != nil {
+			o.Log.Warnf("Invalid span ID in %q, dropping span: %v", m.Name(), err)
+			continue
+		}
+
+		rkey := s.resourceKey()
+		rs, found := resources[rkey]
+		if !found {
+			rs = traces.ResourceSpans().AppendEmpty()
+			for k, v := range s.resource {
+				rs.Resource().Attributes().PutStr(k, v)
+			}
+			for k, v := range o.Attributes {
+				rs.Resource().Attributes().PutStr(k, v)
+			}
+			resources[rkey] = rs
+		}
+		skey := rkey + "\x01" + s.scopeName + "\x00" + s.scopeVersion
+		ss, found := scopes[skey]
+		if !found {
+			ss = rs.ScopeSpans().AppendEmpty()
+			ss.Scope().SetName(s.scopeName)
+			ss.Scope().SetVersion(s.scopeVersion)
+			scopes[skey] = ss
+		}
+
+		span := ss.Spans().AppendEmpty()
+		span.SetTraceID(traceID)
+		span.SetSpanID(spanID)
+		span.SetStartTimestamp(pcommon.NewTimestampFromTime(m.Time()))
+		if v, found := s.popInt(common.AttributeEndTimeUnixNano); found {
+			span.SetEndTimestamp(pcommon.NewTimestampFromTime(time.Unix(0, v)))
+			s.popField(common.AttributeDurationNano)
+		} else if v, found := s.popInt(common.AttributeDurationNano); found {
+			span.SetEndTimestamp(pcommon.NewTimestampFromTime(m.Time().Add(time.Duration(v))))
+		}
+		if v, found := s.popString(common.AttributeSpanName); found {
+			span.SetName(v)
+		} else {
+			span.SetName(m.Name())
+		}
+		if v, found := s.popString(common.AttributeSpanKind); found {
+			if kind, ok := spanKinds[v]; ok {
+				span.SetKind(kind)
+			} else {
+				o.Log.Warnf("Unknown span kind %q in %q", v, m.Name())
+			}
+		}
+		if v, found := s.popString(common.AttributeParentSpanID); found {
+			parent, err := parseSpanID(v)
+			if err != nil {
+				o.Log.Warnf("Invalid parent span ID in %q: %v", m.Name(), err)
+			}
+			span.SetParentSpanID(parent)
+		}
+		if v, found := s.popString(common.AttributeTraceState); found {
+			span.TraceState().FromRaw(v)
+		}
+		if v, found := s.popString(keyStatusCode); found {
+			if code, ok := statusCodes[v]; ok {
+				span.Status().SetCode(code)
+			} else {
+				o.Log.Warnf("Unknown status code %q in %q", v, m.Name())
+			}
+		}
+		if v, found := s.popString(keyStatusDescription); found {
+			span.Status().SetMessage(v)
+		}
+		if v, found := s.popInt(common.AttributeDroppedAttributesCount); found {
+			span.SetDroppedAttributesCount(uint32(v))
+		}
+		if v, found := s.popInt(common.AttributeDroppedEventsCount); found {
+			span.SetDroppedEventsCount(uint32(v))
+		}
+		if v, found := s.popInt(common.AttributeDroppedLinksCount); found {
+			span.SetDroppedLinksCount(uint32(v))
+		}
+
+		if err := s.putAttributes(span.Attributes()); err != nil {
+			o.Log.Warnf("Converting span attributes of %q failed: %v", m.Name(), err)
+		}
+	}
+
+	return traces
+}
+
+func parseTraceID(s string) (pcommon.TraceID, error) {
+	var id pcommon.TraceID
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		return id, err
+	}
+	if len(buf) != len(id) {
+		return id, fmt.Errorf("invalid length %d", len(buf))
+	}
+	copy(id[:], buf)
+	return id, nil
+}
+
+func parseSpanID(s string) (pcommon.SpanID, error) {
+	var id pcommon.SpanID
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		return id, err
+	}
+	if len(buf) != len(id) {
+		return id, fmt.Errorf("invalid length %d", len(buf))
+	}
+	copy(id[:], buf)
+	return id, nil
+}
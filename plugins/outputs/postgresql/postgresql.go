@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/coocood/freecache"
@@ -53,6 +54,9 @@ type Postgresql struct {
 	TagCacheSize               int                     `toml:"tag_cache_size"`
 	ColumnNameLenLimit         int                     `toml:"column_name_length_limit"`
 	LogLevel                   string                  `toml:"log_level"`
+	Timescale                  bool                    `toml:"timescale"`
+	TimescaleChunkTimeInterval config.Duration         `toml:"timescale_chunk_time_interval"`
+	TimescaleCompressAfter     config.Duration         `toml:"timescale_compress_after"`
 	Logger                     telegraf.Logger         `toml:"-"`
 
 	dbContext       context.Context
@@ -62,6 +66,10 @@ type Postgresql struct {
 	tableManager    *TableManager
 	tagsCache       *freecache.Cache
 
+	// Credentials of rotated secrets for new connections
+	credentials     *pgconn.Config
+	credentialsLock sync.Mutex
+
 	pguint8 *pgtype.DataType
 
 	writeChan      chan *TableSource
@@ -138,6 +146,8 @@ func (p *Postgresql) Init() error {
 		}
 	}
 
+	p.dbConfig.BeforeConnect = p.applyCredentials
+
 	switch p.Uint64Type {
 	case PgNumeric:
 	case PgUint8:
@@ -146,6 +156,12 @@ func (p *Postgresql) Init() error {
 		return errors.New("invalid uint64_type")
 	}
 
+	if p.Timescale {
+		if err := p.initTimescale(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -162,6 +178,13 @@ func (p *Postgresql) Connect() error {
 			Retry: true,
 		}
 	}
+	if p.Timescale {
+		if err := p.checkTimescale(p.dbContext); err != nil {
+			p.db.Close()
+			p.dbContextCancel()
+			return err
+		}
+	}
 	p.tableManager = NewTableManager(p)
 
 	if p.TagsAsForeignKeys {
@@ -181,6 +204,50 @@ func (p *Postgresql) Connect() error {
 	return nil
 }
 
+// SecretsRotated updates the credentials used for new connections and closes
+// all idle connections to reconnect using the new credentials
+func (p *Postgresql) SecretsRotated() error {
+	connectionSecret, err := p.Connection.Get()
+	if err != nil {
+		return fmt.Errorf("getting address failed: %w", err)
+	}
+	connection := connectionSecret.String()
+	connectionSecret.Destroy()
+
+	parsedConfig, err := pgx.ParseConfig(connection)
+	if err != nil {
+		return err
+	}
+
+	p.credentialsLock.Lock()
+	p.credentials = &parsedConfig.Config
+	p.credentialsLock.Unlock()
+
+	if p.db == nil {
+		return nil
+	}
+	for _, conn := range p.db.AcquireAllIdle(p.dbContext) {
+		if err := conn.Conn().Close(p.dbContext); err != nil {
+			p.Logger.Debugf("Closing connection failed: %v", err)
+		}
+		conn.Release()
+	}
+	p.Logger.Info("Reconnecting with rotated credentials")
+
+	return nil
+}
+
+func (p *Postgresql) applyCredentials(_ context.Context, cfg *pgx.ConnConfig) error {
+	p.credentialsLock.Lock()
+	defer p.credentialsLock.Unlock()
+
+	if p.credentials != nil {
+		cfg.User = p.credentials.User
+		cfg.Password = p.credentials.Password
+	}
+	return nil
+}
+
 func (p *Postgresql) registerUint8(_ context.Context, conn *pgx.Conn) error {
 	if p.pguint8 == nil {
 		dt := pgtype.DataType{
@@ -234,7 +301,14 @@ func (p *Postgresql) Write(metrics []telegraf.Metric) error {
 		p.tagsCache.ResetStatistics()
 	}
 
-	tableSources := NewTableSources(p, metrics)
+	var tableSources []*TableSource
+	if p.Timescale {
+		tableSources = p.chunkTableSources(NewTableSources(p, metrics), time.Now())
+	} else {
+		for _, tsrc := range NewTableSources(p, metrics) {
+			tableSources = append(tableSources, tsrc)
+		}
+	}
 
 	var err error
 	if p.db.Stat().MaxConns() > 1 {
@@ -255,7 +329,7 @@ func (p *Postgresql) Write(metrics []telegraf.Metric) error {
 	return err
 }
 
-func (p *Postgresql) writeSequential(tableSources map[string]*TableSource) error {
+func (p *Postgresql) writeSequential(tableSources []*TableSource) error {
 	tx, err := p.db.Begin(p.dbContext)
 	if err != nil {
 		return fmt.Errorf("starting transaction: %w", err)
@@ -296,7 +370,7 @@ func (p *Postgresql) writeSequential(tableSources map[string]*TableSource) error
 	return nil
 }
 
-func (p *Postgresql) writeConcurrent(tableSources map[string]*TableSource) {
+func (p *Postgresql) writeConcurrent(tableSources []*TableSource) {
 	for _, tableSource := range tableSources {
 		select {
 		case p.writeChan <- tableSource:
@@ -488,6 +562,7 @@ func newPostgresql() *Postgresql {
 		TagTableCreateTemplates:    []*sqltemplate.Template{{}},
 		TagTableAddColumnTemplates: []*sqltemplate.Template{{}},
 		RetryMaxBackoff:            config.Duration(time.Second * 15),
+		TimescaleChunkTimeInterval: config.Duration(time.Hour * 24 * 7),
 		Logger:                     logger.New("outputs", "postgresql", ""),
 		LogLevel:                   "warn",
 	}
@@ -307,6 +307,28 @@ func TestPostgresqlConnectIntegration(t *testing.T) {
 	require.EqualValues(t, 2, p.db.Stat().MaxConns())
 }
 
+func TestSecretsRotated(t *testing.T) {
+	p := newPostgresql()
+	p.Connection = config.NewSecret([]byte("host=localhost user=telegraf password=secret dbname=telegraf"))
+	p.Logger = testutil.Logger{}
+	require.NoError(t, p.Init())
+
+	// New connections use the configured credentials before rotation
+	cfg := p.dbConfig.ConnConfig.Copy()
+	require.NoError(t, p.dbConfig.BeforeConnect(t.Context(), cfg))
+	require.Equal(t, "telegraf", cfg.User)
+	require.Equal(t, "secret", cfg.Password)
+
+	// New connections use the credentials of the rotated secret
+	require.NoError(t, p.Connection.Set([]byte("host=localhost user=writer password=rotated dbname=telegraf")))
+	require.NoError(t, p.SecretsRotated())
+	cfg = p.dbConfig.ConnConfig.Copy()
+	require.NoError(t, p.dbConfig.BeforeConnect(t.Context(), cfg))
+	require.Equal(t, "writer", cfg.User)
+	require.Equal(t, "rotated", cfg.Password)
+	require.Equal(t, "localhost", cfg.Host)
+}
+
 func TestConnectionIssueAtStartup(t *testing.T) {
 	// Test case for https://github.com/influxdata/telegraf/issues/14365
 	if testing.Short() {
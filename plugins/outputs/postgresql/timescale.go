@@ -0,0 +1,142 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/outputs/postgresql/sqltemplate"
+	"github.com/influxdata/telegraf/plugins/outputs/postgresql/utils"
+)
+
+// initTimescale appends the statements converting new tables to hypertables
+// to the create templates
+func (p *Postgresql) initTimescale() error {
+	if p.TimescaleChunkTimeInterval <= 0 {
+		return errors.New("'timescale_chunk_time_interval' must be positive")
+	}
+	if p.TimescaleCompressAfter < 0 {
+		return errors.New("'timescale_compress_after' must not be negative")
+	}
+
+	timeColumn := utils.QuoteLiteral(p.TimestampColumnName)
+	statements := []string{
+		fmt.Sprintf(`SELECT create_hypertable({{ .table|quoteLiteral }}, %s, chunk_time_interval => %s, if_not_exists => true)`,
+			timeColumn, intervalLiteral(time.Duration(p.TimescaleChunkTimeInterval))),
+	}
+	if p.TimescaleCompressAfter > 0 {
+		options := fmt.Sprintf("timescaledb.compress, timescaledb.compress_orderby = %s",
+			utils.QuoteLiteral(utils.QuoteIdentifier(p.TimestampColumnName)+" DESC"))
+		if p.TagsAsForeignKeys {
+			options += ", timescaledb.compress_segmentby = 'tag_id'"
+		}
+		statements = append(statements,
+			fmt.Sprintf(`ALTER TABLE {{ .table }} SET (%s)`, options),
+			fmt.Sprintf(`SELECT add_compression_policy({{ .table|quoteLiteral }}, %s, if_not_exists => true)`,
+				intervalLiteral(time.Duration(p.TimescaleCompressAfter))),
+		)
+	}
+
+	for _, s := range statements {
+		tmpl := &sqltemplate.Template{}
+		if err := tmpl.UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("parsing timescale template failed: %w", err)
+		}
+		p.CreateTemplates = append(p.CreateTemplates, tmpl)
+	}
+
+	return nil
+}
+
+// checkTimescale makes sure the timescaledb extension is available in the
+// target database
+func (p *Postgresql) checkTimescale(ctx context.Context) error {
+	var version string
+	err := p.db.QueryRow(ctx, "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'").Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errors.New("timescaledb extension is not installed in the database")
+	}
+	if err != nil {
+		return fmt.Errorf("querying timescaledb extension failed: %w", err)
+	}
+	p.Logger.Debugf("Using timescaledb extension version %s", version)
+	return nil
+}
+
+// chunkTableSources splits the table sources into batches of metrics of the
+// same hypertable chunk ordered by time. Batches of chunks which might be
+// compressed already are moved to the end, so writes to recent chunks are not
+// slowed down by decompression and a failed write to a compressed chunk only
+// drops the affected batch.
+func (p *Postgresql) chunkTableSources(tableSources map[string]*TableSource, now time.Time) []*TableSource {
+	interval := int64(p.TimescaleChunkTimeInterval)
+	compressedBefore := int64(-1 << 63)
+	if p.TimescaleCompressAfter > 0 {
+		compressedBefore = now.Add(-time.Duration(p.TimescaleCompressAfter)).UnixNano()
+	}
+
+	type batch struct {
+		chunk      int64
+		compressed bool
+		source     *TableSource
+	}
+	batches := make([]batch, 0, len(tableSources))
+	for name, tsrc := range tableSources {
+		metrics := make([]telegraf.Metric, len(tsrc.metrics))
+		copy(metrics, tsrc.metrics)
+		sort.SliceStable(metrics, func(i, j int) bool {
+			return metrics[i].Time().Before(metrics[j].Time())
+		})
+
+		var current *batch
+		for _, m := range metrics {
+			chunk := chunkStart(m.Time().UnixNano(), interval)
+			if current == nil || current.chunk != chunk {
+				batches = append(batches, batch{
+					chunk:      chunk,
+					compressed: chunk+interval <= compressedBefore,
+					source:     NewTableSource(p, name),
+				})
+				current = &batches[len(batches)-1]
+			}
+			current.source.AddMetric(m)
+		}
+	}
+
+	// Write the uncompressed chunks first, the order of the tables is kept
+	// stable to avoid deadlocks between concurrent writers
+	sort.SliceStable(batches, func(i, j int) bool {
+		if batches[i].compressed != batches[j].compressed {
+			return !batches[i].compressed
+		}
+		if ni, nj := batches[i].source.Name(), batches[j].source.Name(); ni != nj {
+			return ni < nj
+		}
+		return batches[i].chunk < batches[j].chunk
+	})
+
+	sources := make([]*TableSource, 0, len(batches))
+	for _, b := range batches {
+		sources = append(sources, b.source)
+	}
+	return sources
+}
+
+// chunkStart returns the start of the chunk containing the given timestamp as
+// chunks of fixed intervals are aligned to the epoch
+func chunkStart(ts, interval int64) int64 {
+	start := ts - ts%interval
+	if ts < 0 && ts%interval != 0 {
+		start -= interval
+	}
+	return start
+}
+
+func intervalLiteral(d time.Duration) string {
+	return fmt.Sprintf("INTERVAL '%d milliseconds'", d.Milliseconds())
+}
@@ -0,0 +1,159 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/outputs/postgresql/sqltemplate"
+	"github.com/influxdata/telegraf/plugins/outputs/postgresql/utils"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestTimescaleTemplates(t *testing.T) {
+	p := newPostgresql()
+	p.Logger = testutil.Logger{}
+	p.TagsAsForeignKeys = true
+	p.Timescale = true
+	p.TimescaleChunkTimeInterval = config.Duration(24 * time.Hour)
+	p.TimescaleCompressAfter = config.Duration(14 * 24 * time.Hour)
+	require.NoError(t, p.Init())
+
+	expected := []string{
+		`CREATE TABLE "public"."cpu" ("time" timestamp without time zone)`,
+		`SELECT create_hypertable('"public"."cpu"', 'time', chunk_time_interval => INTERVAL '86400000 milliseconds', if_not_exists => true)`,
+		`ALTER TABLE "public"."cpu" SET (timescaledb.compress, timescaledb.compress_orderby = '"time" DESC', timescaledb.compress_segmentby = 'tag_id')`,
+		`SELECT add_compression_policy('"public"."cpu"', INTERVAL '1209600000 milliseconds', if_not_exists => true)`,
+	}
+
+	table := sqltemplate.NewTable(p.Schema, "cpu", nil)
+	actual := make([]string, 0, len(p.CreateTemplates))
+	for _, tmpl := range p.CreateTemplates {
+		sql, err := tmpl.Render(table, []utils.Column{p.timeColumn}, nil, nil)
+		require.NoError(t, err)
+		actual = append(actual, string(sql))
+	}
+	require.Equal(t, expected, actual)
+}
+
+func TestTimescaleInitInvalid(t *testing.T) {
+	p := newPostgresql()
+	p.Logger = testutil.Logger{}
+	p.Timescale = true
+	p.TimescaleChunkTimeInterval = 0
+	require.ErrorContains(t, p.Init(), "'timescale_chunk_time_interval' must be positive")
+}
+
+func TestTimescaleChunkTableSources(t *testing.T) {
+	p := newPostgresql()
+	p.Logger = testutil.Logger{}
+	p.Timescale = true
+	p.TimescaleChunkTimeInterval = config.Duration(24 * time.Hour)
+	p.TimescaleCompressAfter = config.Duration(7 * 24 * time.Hour)
+	require.NoError(t, p.Init())
+
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"v": 1}, now.Add(-1*time.Hour)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"v": 2}, now.Add(-10*24*time.Hour)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"v": 3}, now.Add(-2*time.Hour)),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"v": 4}, now.Add(-13*time.Hour)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"v": 5}, now.Add(-25*time.Hour)),
+	}
+	sources := p.chunkTableSources(NewTableSources(p, metrics), now)
+
+	// Batches of recent chunks come first ordered by table and time, the batch
+	// of the compressed chunk is written last
+	actual := make([][]interface{}, 0, len(sources))
+	for _, tsrc := range sources {
+		values := []interface{}{tsrc.Name()}
+		for _, m := range tsrc.metrics {
+			v, _ := m.GetField("v")
+			values = append(values, v)
+		}
+		actual = append(actual, values)
+	}
+	expected := [][]interface{}{
+		{"cpu", int64(5)},
+		{"cpu", int64(3), int64(1)},
+		{"mem", int64(4)},
+		{"cpu", int64(2)},
+	}
+	require.Equal(t, expected, actual)
+}
+
+func TestTimescaleChunkStart(t *testing.T) {
+	interval := int64(time.Hour)
+	require.Equal(t, int64(0), chunkStart(0, interval))
+	require.Equal(t, int64(0), chunkStart(interval-1, interval))
+	require.Equal(t, interval, chunkStart(interval, interval))
+	require.Equal(t, -interval, chunkStart(-1, interval))
+	require.Equal(t, -interval, chunkStart(-interval, interval))
+}
+
+func TestTimescaleWriteIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	servicePort := "5432"
+	container := testutil.Container{
+		Image:        "timescale/timescaledb:latest-pg16",
+		ExposedPorts: []string{servicePort},
+		Env: map[string]string{
+			"POSTGRES_USER":     "postgres",
+			"POSTGRES_PASSWORD": "postgres",
+			"POSTGRES_DB":       "telegraf_test",
+		},
+		WaitingFor: wait.ForAll(
+			wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+			wait.ForListeningPort(nat.Port(servicePort)),
+		),
+	}
+	require.NoError(t, container.Start(), "failed to start container")
+	defer container.Terminate()
+
+	p := newPostgresql()
+	p.Connection = config.NewSecret([]byte(fmt.Sprintf(
+		"host=%s port=%s user=postgres password=postgres dbname=telegraf_test",
+		container.Address,
+		container.Ports[servicePort],
+	)))
+	p.Logger = testutil.Logger{}
+	p.TagsAsForeignKeys = true
+	p.Timescale = true
+	p.TimescaleChunkTimeInterval = config.Duration(24 * time.Hour)
+	p.TimescaleCompressAfter = config.Duration(7 * 24 * time.Hour)
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Connect())
+	defer p.Close()
+
+	now := time.Now()
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"v": 1}, now),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"v": 2}, now.Add(-48*time.Hour)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"v": 3}, now.Add(-30*24*time.Hour)),
+	}
+	require.NoError(t, p.Write(metrics))
+
+	var hypertables int
+	require.NoError(t, p.db.QueryRow(ctx,
+		"SELECT count(*) FROM timescaledb_information.hypertables WHERE hypertable_name = 'cpu' AND compression_enabled",
+	).Scan(&hypertables))
+	require.Equal(t, 1, hypertables)
+
+	var chunks, rows int
+	require.NoError(t, p.db.QueryRow(ctx,
+		"SELECT count(*) FROM timescaledb_information.chunks WHERE hypertable_name = 'cpu'",
+	).Scan(&chunks))
+	require.Equal(t, 3, chunks)
+	require.NoError(t, p.db.QueryRow(ctx, `SELECT count(*) FROM "cpu"`).Scan(&rows))
+	require.Equal(t, 3, rows)
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/limiter"
 	common_tls "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -35,6 +36,8 @@ type SocketWriter struct {
 
 	encoder internal.ContentEncoder
 
+	bandwidthLimiter *limiter.BandwidthLimiter
+
 	net.Conn
 }
 
@@ -46,6 +49,10 @@ func (sw *SocketWriter) SetSerializer(s telegraf.Serializer) {
 	sw.serializer = s
 }
 
+func (sw *SocketWriter) SetBandwidthLimiter(l *limiter.BandwidthLimiter) {
+	sw.bandwidthLimiter = l
+}
+
 func (sw *SocketWriter) Connect() error {
 	spl := strings.SplitN(sw.Address, "://", 2)
 	if len(spl) != 2 {
@@ -107,7 +114,7 @@ func (sw *SocketWriter) Connect() error {
 		return err
 	}
 
-	sw.Conn = c
+	sw.Conn = sw.bandwidthLimiter.Conn(c)
 	return nil
 }
 
@@ -0,0 +1,89 @@
+package stackdriver
+
+import (
+	"context"
+	"sort"
+
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"google.golang.org/genproto/googleapis/api/label"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// createDescriptors creates the metric descriptors for all series of types not
+// seen before or with additional labels. Creating a descriptor for an
+// existing type with the same settings is a no-op, additional labels are added
+// to the descriptor.
+func (s *Stackdriver) createDescriptors(ctx context.Context, series []*monitoringpb.TimeSeries) {
+	for _, ts := range series {
+		metricType := ts.Metric.Type
+		known, found := s.descriptors[metricType]
+		if found {
+			missing := false
+			for k := range ts.Metric.Labels {
+				if !known[k] {
+					missing = true
+					break
+				}
+			}
+			if !missing {
+				continue
+			}
+		}
+
+		labels := make(map[string]bool, len(known)+len(ts.Metric.Labels))
+		for k := range known {
+			labels[k] = true
+		}
+		for k := range ts.Metric.Labels {
+			labels[k] = true
+		}
+
+		keys := make([]string, 0, len(labels))
+		for k := range labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		descriptors := make([]*label.LabelDescriptor, 0, len(keys))
+		for _, k := range keys {
+			descriptors = append(descriptors, &label.LabelDescriptor{
+				Key:       k,
+				ValueType: label.LabelDescriptor_STRING,
+			})
+		}
+
+		request := &monitoringpb.CreateMetricDescriptorRequest{
+			Name: "projects/" + s.Project,
+			MetricDescriptor: &metricpb.MetricDescriptor{
+				Type:        metricType,
+				MetricKind:  ts.MetricKind,
+				ValueType:   valueType(ts.Points[0].Value),
+				Labels:      descriptors,
+				Description: "Metric created by Telegraf",
+			},
+		}
+
+		// Only remember the type if the descriptor exists to retry transient
+		// errors with the next write
+		if _, err := s.client.CreateMetricDescriptor(ctx, request); err != nil && status.Code(err) != codes.AlreadyExists {
+			s.Log.Errorf("Creating metric descriptor for %q failed: %v", metricType, err)
+			continue
+		}
+		s.descriptors[metricType] = labels
+	}
+}
+
+func valueType(value *monitoringpb.TypedValue) metricpb.MetricDescriptor_ValueType {
+	switch value.Value.(type) {
+	case *monitoringpb.TypedValue_BoolValue:
+		return metricpb.MetricDescriptor_BOOL
+	case *monitoringpb.TypedValue_Int64Value:
+		return metricpb.MetricDescriptor_INT64
+	case *monitoringpb.TypedValue_DoubleValue:
+		return metricpb.MetricDescriptor_DOUBLE
+	case *monitoringpb.TypedValue_DistributionValue:
+		return metricpb.MetricDescriptor_DISTRIBUTION
+	}
+	return metricpb.MetricDescriptor_VALUE_TYPE_UNSPECIFIED
+}
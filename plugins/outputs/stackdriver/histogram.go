@@ -0,0 +1,169 @@
+package stackdriver
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// histogramGroup collects the buckets of a histogram spread across multiple
+// metrics with one metric per bucket bound in the 'le' tag. This layout is
+// produced e.g. by the histogram aggregator with 'cumulative = true' or the
+// prometheus parser with 'metric_version = 2'.
+type histogramGroup struct {
+	metric         telegraf.Metric
+	key            string
+	resourceLabels map[string]string
+
+	bounds     []float64
+	cumulative []int64
+	overflow   *int64
+	sum        *float64
+	count      *int64
+}
+
+type histogramGroups map[uint64]*histogramGroup
+
+// add assigns the bucket, sum and count fields of the metric to the
+// corresponding histogram. Bucket fields must end in '_bucket', sum fields in
+// '_sum' and count fields in '_count'. All other fields are ignored.
+func (hg histogramGroups) add(m telegraf.Metric, resourceLabels map[string]string) {
+	le, hasBound := m.GetTag("le")
+
+	for _, field := range m.FieldList() {
+		var key, kind string
+		switch {
+		case hasBound && strings.HasSuffix(field.Key, "_bucket"):
+			key, kind = strings.TrimSuffix(field.Key, "_bucket"), "bucket"
+		case strings.HasSuffix(field.Key, "_sum"):
+			key, kind = strings.TrimSuffix(field.Key, "_sum"), "sum"
+		case strings.HasSuffix(field.Key, "_count"):
+			key, kind = strings.TrimSuffix(field.Key, "_count"), "count"
+		default:
+			continue
+		}
+
+		h := hg.group(m, key, resourceLabels)
+		switch kind {
+		case "bucket":
+			count, err := internal.ToInt64(field.Value)
+			if err != nil {
+				continue
+			}
+			bound, err := strconv.ParseFloat(le, 64)
+			if err != nil {
+				continue
+			}
+			if math.IsInf(bound, 1) {
+				h.overflow = &count
+				continue
+			}
+			h.bounds = append(h.bounds, bound)
+			h.cumulative = append(h.cumulative, count)
+		case "sum":
+			sum, err := internal.ToFloat64(field.Value)
+			if err != nil {
+				continue
+			}
+			h.sum = &sum
+		case "count":
+			count, err := internal.ToInt64(field.Value)
+			if err != nil {
+				continue
+			}
+			h.count = &count
+		}
+	}
+}
+
+func (hg histogramGroups) group(m telegraf.Metric, key string, resourceLabels map[string]string) *histogramGroup {
+	h := fnv.New64a()
+	h.Write([]byte(m.Name()))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(key))
+	h.Write([]byte{'\n'})
+	for _, tag := range m.TagList() {
+		if tag.Key == "le" {
+			continue
+		}
+		h.Write([]byte(tag.Key))
+		h.Write([]byte{'\n'})
+		h.Write([]byte(tag.Value))
+		h.Write([]byte{'\n'})
+	}
+	id := h.Sum64()
+
+	if g, found := hg[id]; found {
+		return g
+	}
+
+	// Keep a copy of the metric for the name, tags and time of the series
+	gm := m.Copy()
+	gm.RemoveTag("le")
+	for _, f := range m.FieldList() {
+		gm.RemoveField(f.Key)
+	}
+	g := &histogramGroup{
+		metric:         gm,
+		key:            key,
+		resourceLabels: resourceLabels,
+	}
+	hg[id] = g
+
+	return g
+}
+
+// sorted returns the histograms in a deterministic order
+func (hg histogramGroups) sorted() []*histogramGroup {
+	ids := make([]uint64, 0, len(hg))
+	for id := range hg {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	groups := make([]*histogramGroup, 0, len(ids))
+	for _, id := range ids {
+		groups = append(groups, hg[id])
+	}
+	return groups
+}
+
+func (h *histogramGroup) distribution() (*monitoringpb.TypedValue, error) {
+	if len(h.bounds) == 0 && h.overflow == nil {
+		return nil, errors.New("no buckets present")
+	}
+
+	// The total count is equal to the +inf bucket, so use one if the
+	// other is missing
+	var count int64
+	switch {
+	case h.count != nil:
+		count = *h.count
+	case h.overflow != nil:
+		count = *h.overflow
+	default:
+		for _, c := range h.cumulative {
+			count = max(count, c)
+		}
+	}
+	overflow := count
+	if h.overflow != nil {
+		overflow = *h.overflow
+	}
+
+	// The mean cannot be determined without the sum
+	var mean float64
+	if h.sum != nil && count > 0 {
+		mean = *h.sum / float64(count)
+	}
+
+	return newDistribution(h.bounds, h.cumulative, overflow, count, mean), nil
+}
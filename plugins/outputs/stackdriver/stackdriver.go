@@ -44,10 +44,12 @@ type Stackdriver struct {
 	MetricCounter        []string          `toml:"metric_counter"`
 	MetricGauge          []string          `toml:"metric_gauge"`
 	MetricHistogram      []string          `toml:"metric_histogram"`
+	CreateDescriptors    bool              `toml:"create_metric_descriptors"`
 	Log                  telegraf.Logger   `toml:"-"`
 
 	client          *monitoring.MetricClient
 	counterCache    *counterCache
+	descriptors     map[string]map[string]bool
 	filterCounter   filter.Filter
 	filterGauge     filter.Filter
 	filterHistogram filter.Filter
@@ -69,6 +71,10 @@ const (
 
 	// MaxInt is the max int64 value.
 	MaxInt = int(^uint(0) >> 1)
+
+	// maxTimeSeriesPerRequest is the maximum number of time series accepted
+	// by a single CreateTimeSeries call.
+	maxTimeSeriesPerRequest = 200
 )
 
 func (s *Stackdriver) Init() error {
@@ -135,6 +141,10 @@ func (s *Stackdriver) Connect() error {
 		s.counterCache = NewCounterCache(s.Log)
 	}
 
+	if s.descriptors == nil {
+		s.descriptors = make(map[string]map[string]bool)
+	}
+
 	s.ResourceLabels["project_id"] = s.Project
 
 	// Define client options, starting with the user agent
@@ -229,19 +239,9 @@ func (s *Stackdriver) sendBatch(batch []telegraf.Metric) error {
 	ctx := context.Background()
 
 	buckets := make(timeSeriesBuckets)
+	histograms := make(histogramGroups)
 	for _, m := range batch {
-		// Set metric types based on user-provided filter
-		metricType := m.Type()
-		if s.filterCounter != nil && s.filterCounter.Match(m.Name()) {
-			metricType = telegraf.Counter
-		}
-		if s.filterGauge != nil && s.filterGauge.Match(m.Name()) {
-			metricType = telegraf.Gauge
-		}
-		if s.filterHistogram != nil && s.filterHistogram.Match(m.Name()) {
-			metricType = telegraf.Histogram
-		}
-
+		metricType := s.metricType(m)
 		metricKind, err := getStackdriverMetricKind(metricType)
 		if err != nil {
 			s.Log.Errorf("Get kind for metric %q (%T) failed: %s", m.Name(), metricType, err)
@@ -261,42 +261,25 @@ func (s *Stackdriver) sendBatch(batch []telegraf.Metric) error {
 			}
 		}
 
-		if m.Type() == telegraf.Histogram {
+		if metricType == telegraf.Histogram {
+			// Histograms with one metric per bucket are merged before
+			// creating the distribution
+			if !m.HasField("sum") || m.HasTag("le") {
+				histograms.add(m, resourceLabels)
+				continue
+			}
+
 			value, err := buildHistogram(m)
 			if err != nil {
 				s.Log.Errorf("Unable to build distribution from metric %s: %s", m, err)
 				continue
 			}
 
-			startTime, endTime := getStackdriverIntervalEndpoints(metricKind, value, m, nil, s.counterCache)
-			timeInterval, err := getStackdriverTimeInterval(metricKind, startTime, endTime)
+			timeSeries, err := s.histogramTimeSeries(m, "", value, metricKind, resourceLabels)
 			if err != nil {
 				s.Log.Errorf("Get time interval failed: %s", err)
 				continue
 			}
-
-			// Prepare an individual data point.
-			dataPoint := &monitoringpb.Point{
-				Interval: timeInterval,
-				Value:    value,
-			}
-
-			// Prepare time series.
-			timeSeries := &monitoringpb.TimeSeries{
-				Metric: &metricpb.Metric{
-					Type:   s.generateHistogramName(m),
-					Labels: s.getStackdriverLabels(m.TagList()),
-				},
-				MetricKind: metricKind,
-				Resource: &monitoredrespb.MonitoredResource{
-					Type:   s.ResourceType,
-					Labels: resourceLabels,
-				},
-				Points: []*monitoringpb.Point{
-					dataPoint,
-				},
-			}
-
 			buckets.Add(m, m.FieldList(), timeSeries)
 			continue
 		}
@@ -378,6 +361,21 @@ func (s *Stackdriver) sendBatch(batch []telegraf.Metric) error {
 		}
 	}
 
+	for _, h := range histograms.sorted() {
+		value, err := h.distribution()
+		if err != nil {
+			s.Log.Errorf("Unable to build distribution %q from metric %s: %s", h.key, h.metric, err)
+			continue
+		}
+
+		timeSeries, err := s.histogramTimeSeries(h.metric, h.key, value, metricpb.MetricDescriptor_CUMULATIVE, h.resourceLabels)
+		if err != nil {
+			s.Log.Errorf("Get time interval failed: %s", err)
+			continue
+		}
+		buckets.Add(h.metric, []*telegraf.Field{{Key: h.key}}, timeSeries)
+	}
+
 	// process the buckets in order
 	keys := make([]uint64, 0, len(buckets))
 	for k := range buckets {
@@ -386,8 +384,8 @@ func (s *Stackdriver) sendBatch(batch []telegraf.Metric) error {
 	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
 
 	for len(buckets) != 0 {
-		// can send up to 200 time series to stackdriver
-		timeSeries := make([]*monitoringpb.TimeSeries, 0, 200)
+		// each series may only occur once per request
+		timeSeries := make([]*monitoringpb.TimeSeries, 0, maxTimeSeriesPerRequest)
 		for i := 0; i < len(keys) && len(timeSeries) < cap(timeSeries); i++ {
 			k := keys[i]
 			s := buckets[k]
@@ -409,13 +407,19 @@ func (s *Stackdriver) sendBatch(batch []telegraf.Metric) error {
 			TimeSeries: timeSeries,
 		}
 
+		if s.CreateDescriptors {
+			s.createDescriptors(ctx, timeSeries)
+		}
+
 		// Create the time series in Stackdriver.
 		err := s.client.CreateTimeSeries(ctx, timeSeriesRequest)
 		if err != nil {
 			if errStatus, ok := status.FromError(err); ok {
 				if errStatus.Code().String() == "InvalidArgument" {
+					// Only drop the series of this request and continue
+					// with the remaining ones
 					s.Log.Warnf("Unable to write to Stackdriver - dropping metrics: %s", err)
-					return nil
+					continue
 				}
 			}
 
@@ -427,6 +431,58 @@ func (s *Stackdriver) sendBatch(batch []telegraf.Metric) error {
 	return nil
 }
 
+// metricType returns the type of the metric overridden by the user-provided
+// filters
+func (s *Stackdriver) metricType(m telegraf.Metric) telegraf.ValueType {
+	metricType := m.Type()
+	if s.filterCounter != nil && s.filterCounter.Match(m.Name()) {
+		metricType = telegraf.Counter
+	}
+	if s.filterGauge != nil && s.filterGauge.Match(m.Name()) {
+		metricType = telegraf.Gauge
+	}
+	if s.filterHistogram != nil && s.filterHistogram.Match(m.Name()) {
+		metricType = telegraf.Histogram
+	}
+	return metricType
+}
+
+func (s *Stackdriver) histogramTimeSeries(
+	m telegraf.Metric,
+	key string,
+	value *monitoringpb.TypedValue,
+	metricKind metricpb.MetricDescriptor_MetricKind,
+	resourceLabels map[string]string,
+) (*monitoringpb.TimeSeries, error) {
+	var field *telegraf.Field
+	if key != "" {
+		field = &telegraf.Field{Key: key}
+	}
+	startTime, endTime := getStackdriverIntervalEndpoints(metricKind, value, m, field, s.counterCache)
+	timeInterval, err := getStackdriverTimeInterval(metricKind, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return &monitoringpb.TimeSeries{
+		Metric: &metricpb.Metric{
+			Type:   s.generateHistogramName(m, key),
+			Labels: s.getStackdriverLabels(m.TagList()),
+		},
+		MetricKind: metricKind,
+		Resource: &monitoredrespb.MonitoredResource{
+			Type:   s.ResourceType,
+			Labels: resourceLabels,
+		},
+		Points: []*monitoringpb.Point{
+			{
+				Interval: timeInterval,
+				Value:    value,
+			},
+		},
+	}, nil
+}
+
 func (s *Stackdriver) generateMetricName(m telegraf.Metric, metricType telegraf.ValueType, key string) string {
 	if s.MetricNameFormat == "path" {
 		return path.Join(s.MetricTypePrefix, s.Namespace, m.Name(), key)
@@ -454,14 +510,17 @@ func (s *Stackdriver) generateMetricName(m telegraf.Metric, metricType telegraf.
 	return path.Join(s.MetricTypePrefix, name, kind)
 }
 
-func (s *Stackdriver) generateHistogramName(m telegraf.Metric) string {
+func (s *Stackdriver) generateHistogramName(m telegraf.Metric, key string) string {
 	if s.MetricNameFormat == "path" {
-		return path.Join(s.MetricTypePrefix, s.Namespace, m.Name())
+		return path.Join(s.MetricTypePrefix, s.Namespace, m.Name(), key)
 	}
 
 	name := m.Name()
+	if key != "" {
+		name += "_" + key
+	}
 	if s.Namespace != "" {
-		name = s.Namespace + "_" + m.Name()
+		name = s.Namespace + "_" + name
 	}
 
 	return path.Join(s.MetricTypePrefix, name, "histogram")
@@ -596,8 +655,9 @@ func buildHistogram(m telegraf.Metric) (*monitoringpb.TypedValue, error) {
 	m.RemoveField("count")
 
 	// Build map of the buckets and their values
-	buckets := make([]float64, 0)
-	bucketCounts := make([]int64, 0)
+	bounds := make([]float64, 0)
+	cumulative := make([]int64, 0)
+	var overflow *int64
 	for _, field := range m.FieldList() {
 		// Add the +inf value to bucket counts, no need to define a bound
 		if strings.Contains(strings.ToLower(field.Key), "+inf") {
@@ -605,7 +665,7 @@ func buildHistogram(m telegraf.Metric) (*monitoringpb.TypedValue, error) {
 			if err != nil {
 				continue
 			}
-			bucketCounts = append(bucketCounts, count)
+			overflow = &count
 			continue
 		}
 
@@ -619,16 +679,45 @@ func buildHistogram(m telegraf.Metric) (*monitoringpb.TypedValue, error) {
 			continue
 		}
 
-		buckets = append(buckets, bucket)
-		bucketCounts = append(bucketCounts, count)
+		bounds = append(bounds, bucket)
+		cumulative = append(cumulative, count)
 	}
 
-	sort.Slice(buckets, func(i, j int) bool {
-		return buckets[i] < buckets[j]
-	})
-	sort.Slice(bucketCounts, func(i, j int) bool {
-		return bucketCounts[i] < bucketCounts[j]
-	})
+	// Without an explicit +inf bucket all values not covered by the bounds
+	// fall into the overflow bucket
+	if overflow == nil {
+		total := int64(count)
+		overflow = &total
+	}
+
+	var mean float64
+	if count > 0 {
+		mean = sum / count
+	}
+
+	return newDistribution(bounds, cumulative, *overflow, int64(count), mean), nil
+}
+
+// newDistribution creates a distribution value from the given bounds and the
+// corresponding cumulative bucket counts. The overflow count is the cumulative
+// count of the implicit +inf bucket.
+func newDistribution(bounds []float64, cumulative []int64, overflow, count int64, mean float64) *monitoringpb.TypedValue {
+	// Sort the buckets by their bounds
+	idx := make([]int, len(bounds))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(i, j int) bool { return bounds[idx[i]] < bounds[idx[j]] })
+
+	sortedBounds := make([]float64, 0, len(bounds))
+	bucketCounts := make([]int64, 0, len(bounds)+1)
+	for _, i := range idx {
+		sortedBounds = append(sortedBounds, bounds[i])
+		bucketCounts = append(bucketCounts, cumulative[i])
+	}
+	if len(bucketCounts) == 0 || overflow >= bucketCounts[len(bucketCounts)-1] {
+		bucketCounts = append(bucketCounts, overflow)
+	}
 
 	// Bucket counts contain the count for a specific bucket, not the running
 	// total like Prometheus histograms use. Loop backwards to determine the
@@ -637,24 +726,22 @@ func buildHistogram(m telegraf.Metric) (*monitoringpb.TypedValue, error) {
 		bucketCounts[i] = bucketCounts[i] - bucketCounts[i-1]
 	}
 
-	v := &monitoringpb.TypedValue{
+	return &monitoringpb.TypedValue{
 		Value: &monitoringpb.TypedValue_DistributionValue{
 			DistributionValue: &distribution.Distribution{
-				Count:        int64(count),
-				Mean:         sum / count,
+				Count:        count,
+				Mean:         mean,
 				BucketCounts: bucketCounts,
 				BucketOptions: &distribution.Distribution_BucketOptions{
 					Options: &distribution.Distribution_BucketOptions_ExplicitBuckets{
 						ExplicitBuckets: &distribution.Distribution_BucketOptions_Explicit{
-							Bounds: buckets,
+							Bounds: sortedBounds,
 						},
 					},
 				},
 			},
 		},
 	}
-
-	return v, nil
 }
 
 func (s *Stackdriver) getStackdriverLabels(tags []*telegraf.Tag) map[string]string {
@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/api/option"
 	"google.golang.org/genproto/googleapis/api/distribution"
+	"google.golang.org/genproto/googleapis/api/label"
 	metricpb "google.golang.org/genproto/googleapis/api/metric"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -1082,7 +1083,7 @@ func TestGenerateHistogramName(t *testing.T) {
 				MetricNameFormat: tt.format,
 			}
 
-			name := plugin.generateHistogramName(tt.metric)
+			name := plugin.generateHistogramName(tt.metric, "")
 			require.Equal(t, tt.expected, name)
 		})
 	}
@@ -1174,6 +1175,212 @@ func TestBuildHistogram(t *testing.T) {
 	require.Equal(t, expected, value.GetDistributionValue())
 }
 
+func TestBuildHistogramWithoutInf(t *testing.T) {
+	m := metric.New(
+		"http_server_duration",
+		map[string]string{},
+		map[string]interface{}{
+			"sum":   10.0,
+			"count": 4,
+			"5.0":   1,
+			"10.0":  2,
+		},
+		time.Unix(0, 0),
+	)
+
+	value, err := buildHistogram(m)
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 1, 2}, value.GetDistributionValue().BucketCounts)
+	require.InDelta(t, 2.5, value.GetDistributionValue().Mean, 1e-9)
+}
+
+func TestWriteHistogramBuckets(t *testing.T) {
+	// Start the test-server
+	server := &mockServer{
+		resps: []proto.Message{&emptypb.Empty{}},
+	}
+	srv, client := startServer(t, server)
+	defer srv.GracefulStop()
+
+	// Setup and start the plugin with the injected client
+	plugin := &Stackdriver{
+		Project:         "projects/[PROJECT]",
+		Namespace:       "test",
+		MetricHistogram: []string{"http"},
+		Log:             testutil.Logger{},
+		client:          client,
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	// Histogram in the layout of the histogram aggregator and the prometheus
+	// parser with one metric per bucket
+	input := []telegraf.Metric{
+		metric.New("http", map[string]string{"path": "/", "le": "0.1"}, map[string]interface{}{"duration_bucket": 2}, time.Unix(10, 0)),
+		metric.New("http", map[string]string{"path": "/", "le": "0.5"}, map[string]interface{}{"duration_bucket": 5}, time.Unix(10, 0)),
+		metric.New("http", map[string]string{"path": "/", "le": "1"}, map[string]interface{}{"duration_bucket": 6}, time.Unix(10, 0)),
+		metric.New("http", map[string]string{"path": "/", "le": "+Inf"}, map[string]interface{}{"duration_bucket": 7}, time.Unix(10, 0)),
+		metric.New("http", map[string]string{"path": "/"}, map[string]interface{}{"duration_sum": 3.5, "duration_count": 7}, time.Unix(10, 0)),
+	}
+	require.NoError(t, plugin.Write(input))
+
+	require.Len(t, server.reqs, 1)
+	request, ok := server.reqs[0].(*monitoringpb.CreateTimeSeriesRequest)
+	require.Truef(t, ok, "Invalid request type %T", server.reqs[0])
+	require.Len(t, request.TimeSeries, 1)
+
+	ts := request.TimeSeries[0]
+	require.Equal(t, "custom.googleapis.com/test/http/duration", ts.Metric.Type)
+	require.Equal(t, map[string]string{"path": "/"}, ts.Metric.Labels)
+	require.Equal(t, metricpb.MetricDescriptor_CUMULATIVE, ts.MetricKind)
+	require.Len(t, ts.Points, 1)
+
+	expected := &distribution.Distribution{
+		Count:        7,
+		Mean:         0.5,
+		BucketCounts: []int64{2, 3, 1, 1},
+		BucketOptions: &distribution.Distribution_BucketOptions{
+			Options: &distribution.Distribution_BucketOptions_ExplicitBuckets{
+				ExplicitBuckets: &distribution.Distribution_BucketOptions_Explicit{
+					Bounds: []float64{0.1, 0.5, 1},
+				},
+			},
+		},
+	}
+	require.Equal(t, expected, ts.Points[0].Value.GetDistributionValue())
+}
+
+func TestWriteRequestLimit(t *testing.T) {
+	// Start the test-server
+	server := &mockServer{
+		resps: []proto.Message{&emptypb.Empty{}},
+	}
+	srv, client := startServer(t, server)
+	defer srv.GracefulStop()
+
+	// Setup and start the plugin with the injected client
+	plugin := &Stackdriver{
+		Project:   "projects/[PROJECT]",
+		Namespace: "test",
+		Log:       testutil.Logger{},
+		client:    client,
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	input := make([]telegraf.Metric, 0, 250)
+	for i := range 250 {
+		input = append(input, metric.New(
+			"cpu",
+			map[string]string{"cpu": fmt.Sprintf("cpu%d", i)},
+			map[string]interface{}{"value": i},
+			time.Unix(10, 0),
+		))
+	}
+	require.NoError(t, plugin.Write(input))
+
+	require.Len(t, server.reqs, 2)
+	var total int
+	for i, r := range server.reqs {
+		request, ok := r.(*monitoringpb.CreateTimeSeriesRequest)
+		require.Truef(t, ok, "Invalid request type %T for request %d", r, i)
+		require.LessOrEqual(t, len(request.TimeSeries), maxTimeSeriesPerRequest)
+		total += len(request.TimeSeries)
+	}
+	require.Equal(t, 250, total)
+}
+
+func TestWriteMetricDescriptors(t *testing.T) {
+	// Start the test-server
+	server := &mockServer{
+		resps: []proto.Message{&emptypb.Empty{}},
+	}
+	srv, client := startServer(t, server)
+	defer srv.GracefulStop()
+
+	// Setup and start the plugin with the injected client
+	plugin := &Stackdriver{
+		Project:           "projects/[PROJECT]",
+		Namespace:         "test",
+		CreateDescriptors: true,
+		Log:               testutil.Logger{},
+		client:            client,
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"usage": 42.0}, time.Unix(10, 0)),
+		metric.New("cpu", map[string]string{"cpu": "cpu1"}, map[string]interface{}{"usage": 23.0}, time.Unix(10, 0)),
+	}
+	require.NoError(t, plugin.Write(input))
+
+	// Each type is only created once
+	require.Len(t, server.descriptors, 1)
+	expected := &metricpb.MetricDescriptor{
+		Type:        "custom.googleapis.com/test/cpu/usage",
+		MetricKind:  metricpb.MetricDescriptor_GAUGE,
+		ValueType:   metricpb.MetricDescriptor_DOUBLE,
+		Labels:      []*label.LabelDescriptor{{Key: "cpu", ValueType: label.LabelDescriptor_STRING}},
+		Description: "Metric created by Telegraf",
+	}
+	require.True(t, proto.Equal(expected, server.descriptors[0].MetricDescriptor))
+
+	// Descriptors are not recreated for known types and labels
+	require.NoError(t, plugin.Write(input))
+	require.Len(t, server.descriptors, 1)
+
+	// Additional labels are added to the descriptor
+	input = []telegraf.Metric{
+		metric.New("cpu", map[string]string{"cpu": "cpu0", "host": "a"}, map[string]interface{}{"usage": 42.0}, time.Unix(20, 0)),
+	}
+	require.NoError(t, plugin.Write(input))
+	require.Len(t, server.descriptors, 2)
+	keys := make([]string, 0, len(server.descriptors[1].MetricDescriptor.Labels))
+	for _, l := range server.descriptors[1].MetricDescriptor.Labels {
+		keys = append(keys, l.Key)
+	}
+	require.Equal(t, []string{"cpu", "host"}, keys)
+}
+
+func TestWriteMetricDescriptorsFailed(t *testing.T) {
+	// Start the test-server
+	server := &mockServer{
+		resps:         []proto.Message{&emptypb.Empty{}},
+		descriptorErr: status.Error(codes.Unavailable, "unavailable"),
+	}
+	srv, client := startServer(t, server)
+	defer srv.GracefulStop()
+
+	// Setup and start the plugin with the injected client
+	plugin := &Stackdriver{
+		Project:           "projects/[PROJECT]",
+		Namespace:         "test",
+		CreateDescriptors: true,
+		Log:               testutil.Logger{},
+		client:            client,
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"usage": 42.0}, time.Unix(10, 0)),
+	}
+
+	// Failed descriptors must be retried with the next write
+	require.NoError(t, plugin.Write(input))
+	require.Len(t, server.descriptors, 1)
+	require.NoError(t, plugin.Write(input))
+	require.Len(t, server.descriptors, 2)
+
+	// Existing descriptors must not be retried
+	server.descriptorErr = status.Error(codes.AlreadyExists, "exists")
+	require.NoError(t, plugin.Write(input))
+	require.Len(t, server.descriptors, 3)
+	require.NoError(t, plugin.Write(input))
+	require.Len(t, server.descriptors, 3)
+}
+
 func startServer(t *testing.T, mock *mockServer) (*grpc.Server, *monitoring.MetricClient) {
 	t.Helper()
 
@@ -1202,7 +1409,11 @@ type mockServer struct {
 	// in the future.
 	monitoringpb.MetricServiceServer
 
-	reqs []proto.Message
+	reqs        []proto.Message
+	descriptors []*monitoringpb.CreateMetricDescriptorRequest
+
+	// If set, creating descriptors returns this error
+	descriptorErr error
 
 	// If set, all calls return this error.
 	err error
@@ -1231,3 +1442,11 @@ func (s *mockServer) CreateTimeSeries(ctx context.Context, req *monitoringpb.Cre
 	}
 	return s.resps[0].(*emptypb.Empty), nil
 }
+
+func (s *mockServer) CreateMetricDescriptor(_ context.Context, req *monitoringpb.CreateMetricDescriptorRequest) (*metricpb.MetricDescriptor, error) {
+	s.descriptors = append(s.descriptors, req)
+	if s.descriptorErr != nil {
+		return nil, s.descriptorErr
+	}
+	return req.MetricDescriptor, nil
+}
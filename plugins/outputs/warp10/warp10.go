@@ -10,6 +10,8 @@ import (
 	"math"
 	"net/http"
 	"net/url"
+	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -17,6 +19,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -36,17 +39,31 @@ type Warp10 struct {
 	Timeout            config.Duration `toml:"timeout"`
 	PrintErrorBody     bool            `toml:"print_error_body"`
 	MaxStringErrorSize int             `toml:"max_string_error_size"`
+	AttributeTags      []string        `toml:"attribute_tags"`
+	MaxPayloadSize     config.Size     `toml:"max_payload_size"`
+	ClassRules         []classRule     `toml:"class_rule"`
 	client             *http.Client
 	tls.ClientConfig
 	Log telegraf.Logger `toml:"-"`
 }
 
+// classRule replaces parts of the matching class names
+type classRule struct {
+	Classes     []string `toml:"classes"`
+	Pattern     string   `toml:"pattern"`
+	Replacement string   `toml:"replacement"`
+
+	filter filter.Filter
+	regex  *regexp.Regexp
+}
+
 // MetricLine Warp 10 metrics
 type MetricLine struct {
-	Metric    string
-	Timestamp int64
-	Value     string
-	Tags      string
+	Metric     string
+	Timestamp  int64
+	Value      string
+	Tags       string
+	Attributes string
 }
 
 func (w *Warp10) createClient() (*http.Client, error) {
@@ -87,11 +104,19 @@ func (w *Warp10) Connect() error {
 
 // GenWarp10Payload compute Warp 10 metrics payload
 func (w *Warp10) GenWarp10Payload(metrics []telegraf.Metric) string {
+	return strings.Join(w.genLines(metrics), "")
+}
+
+func (w *Warp10) genLines(metrics []telegraf.Metric) []string {
 	collectString := make([]string, 0)
 	for _, mm := range metrics {
+		labels, attributes := w.splitTags(mm.TagList())
+		tagsSlice := buildTags(labels)
+		attributesSlice := buildAttributes(attributes)
+
 		for _, field := range mm.FieldList() {
 			metric := &MetricLine{
-				Metric:    fmt.Sprintf("%s%s", w.Prefix, mm.Name()+"."+field.Key),
+				Metric:    w.sanitizeClass(fmt.Sprintf("%s%s", w.Prefix, mm.Name()+"."+field.Key)),
 				Timestamp: mm.Time().UnixNano() / 1000,
 			}
 
@@ -101,62 +126,159 @@ func (w *Warp10) GenWarp10Payload(metrics []telegraf.Metric) string {
 				continue
 			}
 			metric.Value = metricValue
-
-			tagsSlice := buildTags(mm.TagList())
 			metric.Tags = strings.Join(tagsSlice, ",")
 
-			messageLine := fmt.Sprintf("%d// %s{%s} %s\n", metric.Timestamp, metric.Metric, metric.Tags, metric.Value)
+			var messageLine string
+			if len(attributesSlice) > 0 {
+				metric.Attributes = strings.Join(attributesSlice, ",")
+				messageLine = fmt.Sprintf("%d// %s{%s}{%s} %s\n", metric.Timestamp, metric.Metric, metric.Tags, metric.Attributes, metric.Value)
+			} else {
+				messageLine = fmt.Sprintf("%d// %s{%s} %s\n", metric.Timestamp, metric.Metric, metric.Tags, metric.Value)
+			}
 
 			collectString = append(collectString, messageLine)
 		}
 	}
-	return strings.Join(collectString, "")
+	return collectString
+}
+
+// splitTags separates the tags used as labels of the GTS from the ones used
+// as attributes
+func (w *Warp10) splitTags(tags []*telegraf.Tag) (labels, attributes []*telegraf.Tag) {
+	if len(w.AttributeTags) == 0 {
+		return tags, nil
+	}
+
+	labels = make([]*telegraf.Tag, 0, len(tags))
+	for _, tag := range tags {
+		if slices.Contains(w.AttributeTags, tag.Key) {
+			attributes = append(attributes, tag)
+		} else {
+			labels = append(labels, tag)
+		}
+	}
+	return labels, attributes
+}
+
+// sanitizeClass applies the rules to the class name in order
+func (w *Warp10) sanitizeClass(class string) string {
+	for _, rule := range w.ClassRules {
+		if rule.regex == nil || (rule.filter != nil && !rule.filter.Match(class)) {
+			continue
+		}
+		class = rule.regex.ReplaceAllString(class, rule.Replacement)
+	}
+	return class
 }
 
 // Write metrics to Warp10
 func (w *Warp10) Write(metrics []telegraf.Metric) error {
-	payload := w.GenWarp10Payload(metrics)
-	if payload == "" {
+	lines := w.genLines(metrics)
+	if len(lines) == 0 {
 		return nil
 	}
 
-	addr := w.WarpURL + "/api/v0/update"
-	req, err := http.NewRequest("POST", addr, bytes.NewBufferString(payload))
-	if err != nil {
-		return fmt.Errorf("unable to create new request %q: %w", addr, err)
+	// Split the payload into batches not exceeding the maximum size, a single
+	// line exceeding the limit is sent on its own
+	var batch strings.Builder
+	for _, line := range lines {
+		if w.MaxPayloadSize > 0 && batch.Len() > 0 && int64(batch.Len()+len(line)) > int64(w.MaxPayloadSize) {
+			if err := w.send(batch.String()); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		batch.WriteString(line)
 	}
+	return w.send(batch.String())
+}
 
-	req.Header.Set("Content-Type", "text/plain")
-	token, err := w.Token.Get()
+// send posts the payload and retries once with the current token if the token
+// was rejected, as the token might have been rotated in the secret store
+func (w *Warp10) send(payload string) error {
+	token, err := w.token()
 	if err != nil {
-		return fmt.Errorf("getting token failed: %w", err)
+		return err
 	}
-	req.Header.Set("X-Warp10-Token", token.String())
-	token.Destroy()
 
-	resp, err := w.client.Do(req)
+	status, body, err := w.post(payload, token)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
+	if status != http.StatusOK && isTokenError(status, body) {
+		current, err := w.token()
+		if err != nil {
+			return err
+		}
+		if current != token {
+			w.Log.Debug("Token rejected, retrying with rotated token")
+			if status, body, err = w.post(payload, current); err != nil {
+				return err
+			}
+		}
+	}
 
-	if resp.StatusCode != http.StatusOK {
+	if status != http.StatusOK {
 		if w.PrintErrorBody {
-			//nolint:errcheck // err can be ignored since it is just for logging
-			body, _ := io.ReadAll(resp.Body)
-			return errors.New(w.WarpURL + ": " + HandleError(string(body), w.MaxStringErrorSize))
+			return errors.New(w.WarpURL + ": " + HandleError(body, w.MaxStringErrorSize))
 		}
 
-		if len(resp.Status) < w.MaxStringErrorSize {
-			return errors.New(w.WarpURL + ": " + resp.Status)
+		statusText := fmt.Sprintf("%d %s", status, http.StatusText(status))
+		if len(statusText) < w.MaxStringErrorSize {
+			return errors.New(w.WarpURL + ": " + statusText)
 		}
 
-		return errors.New(w.WarpURL + ": " + resp.Status[0:w.MaxStringErrorSize])
+		return errors.New(w.WarpURL + ": " + statusText[0:w.MaxStringErrorSize])
 	}
 
 	return nil
 }
 
+func (w *Warp10) token() (string, error) {
+	token, err := w.Token.Get()
+	if err != nil {
+		return "", fmt.Errorf("getting token failed: %w", err)
+	}
+	defer token.Destroy()
+	return token.String(), nil
+}
+
+func (w *Warp10) post(payload, token string) (int, string, error) {
+	addr := w.WarpURL + "/api/v0/update"
+	req, err := http.NewRequest("POST", addr, bytes.NewBufferString(payload))
+	if err != nil {
+		return 0, "", fmt.Errorf("unable to create new request %q: %w", addr, err)
+	}
+
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("X-Warp10-Token", token)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, "", nil
+	}
+
+	//nolint:errcheck // err can be ignored since the body is only used for error reporting
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body), nil
+}
+
+func isTokenError(status int, body string) bool {
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		return true
+	}
+	switch HandleError(body, len(body)+1) {
+	case "Invalid token", "Write token missing", "Token Expired", "Token revoked":
+		return true
+	}
+	return false
+}
+
 func buildTags(tags []*telegraf.Tag) []string {
 	tagsString := make([]string, 0, len(tags)+1)
 	for _, tag := range tags {
@@ -169,6 +291,17 @@ func buildTags(tags []*telegraf.Tag) []string {
 	return tagsString
 }
 
+func buildAttributes(tags []*telegraf.Tag) []string {
+	attributes := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		key := url.QueryEscape(tag.Key)
+		value := url.QueryEscape(tag.Value)
+		attributes = append(attributes, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(attributes)
+	return attributes
+}
+
 func buildValue(v interface{}) (string, error) {
 	var retv string
 	switch p := v.(type) {
@@ -245,6 +378,20 @@ func (w *Warp10) Init() error {
 	if w.MaxStringErrorSize <= 0 {
 		w.MaxStringErrorSize = 511
 	}
+
+	for i := range w.ClassRules {
+		rule := &w.ClassRules[i]
+		if rule.Pattern == "" {
+			return fmt.Errorf("class rule %d: missing pattern", i+1)
+		}
+		var err error
+		if rule.regex, err = regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("class rule %d: compiling pattern failed: %w", i+1, err)
+		}
+		if rule.filter, err = filter.Compile(rule.Classes); err != nil {
+			return fmt.Errorf("class rule %d: compiling classes failed: %w", i+1, err)
+		}
+	}
 	return nil
 }
 
@@ -1,11 +1,17 @@
 package warp10
 
 import (
+	"fmt"
+	"io"
 	"math"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -148,3 +154,122 @@ func TestHandleWarp10Error(t *testing.T) {
 		require.Exactly(t, handledError.Expected, payload)
 	}
 }
+
+func TestWriteWarp10Attributes(t *testing.T) {
+	w := Warp10{
+		Prefix:        "unit.test",
+		WarpURL:       "http://localhost:8090",
+		Token:         config.NewSecret([]byte("WRITE")),
+		AttributeTags: []string{"rack", "owner"},
+	}
+
+	metrics := testutil.MockMetrics()
+	for _, metric := range metrics {
+		metric.AddTag("rack", "r1")
+		metric.AddTag("owner", "ops team")
+	}
+
+	payload := w.GenWarp10Payload(metrics)
+	require.Exactly(t, "1257894000000000// unit.testtest1.value{source=telegraf,tag1=value1}{owner=ops+team,rack=r1} 1.000000\n", payload)
+}
+
+func TestWriteWarp10ClassRules(t *testing.T) {
+	w := Warp10{
+		Prefix:  "telegraf.",
+		WarpURL: "http://localhost:8090",
+		Token:   config.NewSecret([]byte("WRITE")),
+		ClassRules: []classRule{
+			{Classes: []string{"telegraf.win_*"}, Pattern: `[^a-zA-Z0-9._-]`, Replacement: "_"},
+			{Pattern: `^telegraf\.`, Replacement: "tg."},
+		},
+	}
+	require.NoError(t, w.Init())
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("win_cpu", map[string]string{}, map[string]interface{}{"% Idle Time": 1.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"usage idle": 1.0}, time.Unix(0, 0)),
+	}
+	payload := w.GenWarp10Payload(metrics)
+	require.Exactly(t, "0// tg.win_cpu.__Idle_Time{source=telegraf} 1.000000\n0// tg.cpu.usage idle{source=telegraf} 1.000000\n", payload)
+
+	w.ClassRules = []classRule{{Pattern: "["}}
+	require.ErrorContains(t, w.Init(), "class rule 1: compiling pattern failed")
+}
+
+func TestWriteWarp10MaxPayloadSize(t *testing.T) {
+	var payloads []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+			return
+		}
+		payloads = append(payloads, string(body))
+	}))
+	defer ts.Close()
+
+	w := Warp10{
+		WarpURL:        ts.URL,
+		Token:          config.NewSecret([]byte("WRITE")),
+		MaxPayloadSize: 80,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, w.Init())
+	require.NoError(t, w.Connect())
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"a": 1.0, "b": 2.0}, time.Unix(0, 0)),
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"c": 3.0}, time.Unix(0, 0)),
+	}
+	require.NoError(t, w.Write(metrics))
+	require.Equal(t, []string{
+		"0// cpu.a{source=telegraf} 1.000000\n0// cpu.b{source=telegraf} 2.000000\n",
+		"0// cpu.c{source=telegraf} 3.000000\n",
+	}, payloads)
+}
+
+func TestWriteWarp10TokenRotation(t *testing.T) {
+	var tokens []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("X-Warp10-Token")
+		tokens = append(tokens, token)
+		if token != "NEW" {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, "io.warp10.script.WarpScriptException: Token Expired.")
+		}
+	}))
+	defer ts.Close()
+
+	// Simulate a dynamic secret rotated in the secret store while sending the
+	// second write, linking the secret resolves it once
+	var resolved int
+	token := config.NewSecret([]byte("@{store:token}"))
+	require.NoError(t, token.Link(map[string]telegraf.ResolveFunc{
+		"@{store:token}": func() ([]byte, bool, error) {
+			resolved++
+			if resolved <= 4 {
+				return []byte("OLD"), true, nil
+			}
+			return []byte("NEW"), true, nil
+		},
+	}))
+
+	w := Warp10{
+		WarpURL:        ts.URL,
+		Token:          token,
+		PrintErrorBody: true,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, w.Init())
+	require.NoError(t, w.Connect())
+
+	// The token is checked again but did not change yet
+	require.ErrorContains(t, w.Write(testutil.MockMetrics()), "Token Expired")
+	require.Equal(t, []string{"OLD"}, tokens)
+
+	// The request is retried with the rotated token
+	tokens = nil
+	require.NoError(t, w.Write(testutil.MockMetrics()))
+	require.Equal(t, []string{"OLD", "NEW"}, tokens)
+}
@@ -0,0 +1,5 @@
+//go:build !custom || parsers || parsers.auto
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/parsers/auto" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || parsers || parsers.fhir
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/parsers/fhir" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || parsers || parsers.hl7v2
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/parsers/hl7v2" // register plugin
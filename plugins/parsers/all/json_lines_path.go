@@ -0,0 +1,5 @@
+//go:build !custom || parsers || parsers.json_lines_path
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/parsers/json_lines_path" // register plugin
@@ -0,0 +1,133 @@
+package auto
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/parsers/prometheus"
+)
+
+var defaultDataFormats = []string{"influx", "json", "prometheus"}
+
+// Parser probes each payload against a list of candidate parsers and uses the
+// result of the first one succeeding
+type Parser struct {
+	DataFormats []string          `toml:"auto_data_formats"`
+	MetricName  string            `toml:"-"`
+	DefaultTags map[string]string `toml:"-"`
+	Log         telegraf.Logger   `toml:"-"`
+
+	candidates []candidate
+}
+
+type candidate struct {
+	format string
+	parser telegraf.Parser
+}
+
+func (p *Parser) Init() error {
+	if len(p.DataFormats) == 0 {
+		p.DataFormats = defaultDataFormats
+	}
+
+	p.candidates = make([]candidate, 0, len(p.DataFormats))
+	for i, format := range p.DataFormats {
+		if format == "auto" {
+			return errors.New("data format 'auto' cannot be used as candidate")
+		}
+		if slices.Contains(p.DataFormats[:i], format) {
+			return fmt.Errorf("duplicate data format %q", format)
+		}
+		creator, found := parsers.Parsers[format]
+		if !found {
+			available := make([]string, 0, len(parsers.Parsers))
+			for name := range parsers.Parsers {
+				if name != "auto" {
+					available = append(available, name)
+				}
+			}
+			sort.Strings(available)
+			return fmt.Errorf("unknown data format %q, use one of %v", format, available)
+		}
+
+		parser := creator(p.MetricName)
+		if p.Log != nil {
+			models.SetLoggerOnPlugin(parser, p.Log)
+		}
+		// Without a header the prometheus parser expects the protobuf format
+		// but payloads from queues are usually in text exposition format
+		if prom, ok := parser.(*prometheus.Parser); ok {
+			prom.Header = http.Header{"Content-Type": []string{"text/plain; version=0.0.4"}}
+		}
+		if p.DefaultTags != nil {
+			parser.SetDefaultTags(p.DefaultTags)
+		}
+		if initializer, ok := parser.(telegraf.Initializer); ok {
+			if err := initializer.Init(); err != nil {
+				return fmt.Errorf("initializing %q parser failed: %w", format, err)
+			}
+		}
+		p.candidates = append(p.candidates, candidate{format: format, parser: parser})
+	}
+
+	return nil
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	errs := make([]error, 0, len(p.candidates))
+	for _, c := range p.candidates {
+		metrics, err := c.parser.Parse(buf)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.format, err))
+			continue
+		}
+		// Empty results are valid for many formats so only accept the format
+		// if it actually produced metrics
+		if len(metrics) > 0 {
+			return metrics, nil
+		}
+	}
+
+	if len(errs) == len(p.candidates) {
+		return nil, fmt.Errorf("no data format matched: %w", errors.Join(errs...))
+	}
+	return nil, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, errors.New("no metrics in line")
+	}
+
+	if len(metrics) > 1 {
+		return nil, errors.New("more than one metric in line")
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+	for _, c := range p.candidates {
+		c.parser.SetDefaultTags(tags)
+	}
+}
+
+func init() {
+	parsers.Add("auto",
+		func(defaultMetricName string) telegraf.Parser {
+			return &Parser{MetricName: defaultMetricName}
+		},
+	)
+}
@@ -0,0 +1,159 @@
+package auto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	_ "github.com/influxdata/telegraf/plugins/parsers/influx"
+	_ "github.com/influxdata/telegraf/plugins/parsers/json"
+	_ "github.com/influxdata/telegraf/plugins/parsers/logfmt"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		formats  []string
+		expected string
+	}{
+		{
+			name:     "recursive",
+			formats:  []string{"influx", "auto"},
+			expected: "data format 'auto' cannot be used as candidate",
+		},
+		{
+			name:     "duplicate",
+			formats:  []string{"influx", "json", "influx"},
+			expected: `duplicate data format "influx"`,
+		},
+		{
+			name:     "unknown",
+			formats:  []string{"influx", "foo"},
+			expected: `unknown data format "foo"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Parser{DataFormats: tt.formats}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []telegraf.Metric
+	}{
+		{
+			name:  "influx",
+			input: "cpu,host=a usage_idle=42 1700000000000000000\n",
+			expected: []telegraf.Metric{
+				metric.New(
+					"cpu",
+					map[string]string{"host": "a", "source": "queue"},
+					map[string]interface{}{"usage_idle": float64(42)},
+					time.Unix(1700000000, 0),
+				),
+			},
+		},
+		{
+			name:  "json",
+			input: `{"usage_idle": 42}`,
+			expected: []telegraf.Metric{
+				metric.New(
+					"consumer",
+					map[string]string{"source": "queue"},
+					map[string]interface{}{"usage_idle": float64(42)},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:  "prometheus",
+			input: "# TYPE cpu_usage_idle gauge\ncpu_usage_idle{host=\"a\"} 42\n",
+			expected: []telegraf.Metric{
+				metric.New(
+					"prometheus",
+					map[string]string{"host": "a", "source": "queue"},
+					map[string]interface{}{"cpu_usage_idle": float64(42)},
+					time.Unix(0, 0),
+					telegraf.Gauge,
+				),
+			},
+		},
+	}
+
+	plugin := &Parser{
+		MetricName: "consumer",
+		Log:        testutil.Logger{},
+	}
+	plugin.SetDefaultTags(map[string]string{"source": "queue"})
+	require.NoError(t, plugin.Init())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := plugin.Parse([]byte(tt.input))
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, tt.expected, actual, testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestParseOrder(t *testing.T) {
+	// Logfmt also accepts line protocol so the first matching format wins
+	input := []byte("cpu,host=a usage_idle=42 1700000000000000000\n")
+
+	plugin := &Parser{
+		DataFormats: []string{"influx", "logfmt"},
+		MetricName:  "consumer",
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	actual, err := plugin.Parse(input)
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	require.Equal(t, "cpu", actual[0].Name())
+
+	plugin = &Parser{
+		DataFormats: []string{"logfmt", "influx"},
+		MetricName:  "consumer",
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	actual, err = plugin.Parse(input)
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	require.Equal(t, "consumer", actual[0].Name())
+}
+
+func TestParseNoMatch(t *testing.T) {
+	plugin := &Parser{
+		DataFormats: []string{"influx", "json"},
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	_, err := plugin.Parse([]byte("this is not a metric"))
+	require.ErrorContains(t, err, "no data format matched")
+	require.ErrorContains(t, err, "influx: ")
+	require.ErrorContains(t, err, "json: ")
+}
+
+func TestParseEmpty(t *testing.T) {
+	plugin := &Parser{
+		DataFormats: []string{"influx", "json"},
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual, err := plugin.Parse([]byte("# just a comment\n"))
+	require.NoError(t, err)
+	require.Empty(t, actual)
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
+	"os"
 	"time"
 
 	"github.com/jeremywohl/flatten/v2"
@@ -21,13 +23,15 @@ import (
 // (https://docs.confluent.io/platform/current/schema-registry/serdes-develop/index.html#wire-format) and we will load the schema from the registry.
 
 // If Schema is set, we assume the input will be Avro binary format, without
-// an attached schema or schema fingerprint
+// an attached schema or schema fingerprint. SchemaFile is an alternative to
+// Schema, loading the schema from a local file instead.
 
 type Parser struct {
 	MetricName       string            `toml:"metric_name"`
 	SchemaRegistry   string            `toml:"avro_schema_registry"`
 	CaCertPath       string            `toml:"avro_schema_registry_cert"`
 	Schema           string            `toml:"avro_schema"`
+	SchemaFile       string            `toml:"avro_schema_file"`
 	Format           string            `toml:"avro_format"`
 	Measurement      string            `toml:"avro_measurement"`
 	MeasurementField string            `toml:"avro_measurement_field"`
@@ -40,6 +44,7 @@ type Parser struct {
 	DefaultTags      map[string]string `toml:"tags"`
 	Log              telegraf.Logger   `toml:"-"`
 	registryObj      *schemaRegistry
+	codec            *goavro.Codec
 }
 
 func (p *Parser) Init() error {
@@ -60,6 +65,16 @@ func (p *Parser) Init() error {
 		return fmt.Errorf("unknown avro_union_mode %q", p.Format)
 	}
 
+	if p.SchemaFile != "" {
+		if p.Schema != "" {
+			return errors.New("'schema' and 'schema_file' are mutually exclusive")
+		}
+		buf, err := os.ReadFile(p.SchemaFile)
+		if err != nil {
+			return fmt.Errorf("reading schema file failed: %w", err)
+		}
+		p.Schema = string(buf)
+	}
 	if (p.Schema == "" && p.SchemaRegistry == "") || (p.Schema != "" && p.SchemaRegistry != "") {
 		return errors.New("exactly one of 'schema_registry' or 'schema' must be specified")
 	}
@@ -77,6 +92,12 @@ func (p *Parser) Init() error {
 			return fmt.Errorf("error connecting to the schema registry %q: %w", p.SchemaRegistry, err)
 		}
 		p.registryObj = registry
+	} else {
+		codec, err := goavro.NewCodec(p.Schema)
+		if err != nil {
+			return fmt.Errorf("invalid schema: %w", err)
+		}
+		p.codec = codec
 	}
 
 	return nil
@@ -113,10 +134,7 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 			// We would get the fingerprint as int(binary.LittleEndian.Uint64(buf[2:10]))
 		} // Otherwise we assume bare Avro binary
 		schema = p.Schema
-		codec, err = goavro.NewCodec(schema)
-		if err != nil {
-			return nil, err
-		}
+		codec = p.codec
 	}
 
 	var native interface{}
@@ -233,7 +251,11 @@ func (p *Parser) flattenItem(fld string, fldVal interface{}) (map[string]interfa
 	return flat, nil
 }
 
-func (p *Parser) createMetric(data map[string]interface{}, schema string) (telegraf.Metric, error) {
+func (p *Parser) createMetric(raw map[string]interface{}, schema string) (telegraf.Metric, error) {
+	// Avro logical types are decoded into Go types not supported as field
+	// values so convert them first.
+	data := convertLogicalTypes(raw).(map[string]interface{})
+
 	// Tags differ from fields, in that tags are inherently strings.
 	// fields can be of any type.
 	fields := make(map[string]interface{})
@@ -338,11 +360,23 @@ func (p *Parser) createMetric(data map[string]interface{}, schema string) (teleg
 	}
 	var timestamp time.Time
 	if p.Timestamp != "" {
-		rawTime := fmt.Sprintf("%v", data[p.Timestamp])
-		var err error
-		timestamp, err = internal.ParseTimestamp(p.TimestampFormat, rawTime, nil)
-		if err != nil {
-			return nil, fmt.Errorf("could not parse '%s' to '%s'", rawTime, p.TimestampFormat)
+		value := raw[p.Timestamp]
+		// Resolve unions such as nullable timestamps to the actual value
+		if union, ok := value.(map[string]interface{}); ok && len(union) == 1 {
+			for _, v := range union {
+				value = v
+			}
+		}
+		if ts, ok := value.(time.Time); ok {
+			// Timestamp logical types carry their own precision
+			timestamp = ts
+		} else {
+			rawTime := fmt.Sprintf("%v", value)
+			var err error
+			timestamp, err = internal.ParseTimestamp(p.TimestampFormat, rawTime, nil)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse '%s' to '%s'", rawTime, p.TimestampFormat)
+			}
 		}
 	} else {
 		timestamp = time.Now()
@@ -350,6 +384,36 @@ func (p *Parser) createMetric(data map[string]interface{}, schema string) (teleg
 	return metric.New(name, tags, fields, timestamp), nil
 }
 
+// convertLogicalTypes returns a copy of the value replacing the native
+// representation of logical types with values usable as fields. Timestamps
+// and dates become nanoseconds since the Unix epoch, times of day nanoseconds
+// since midnight and decimals floating point numbers. UUIDs are already
+// decoded as strings.
+func convertLogicalTypes(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			converted[k] = convertLogicalTypes(item)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			converted = append(converted, convertLogicalTypes(item))
+		}
+		return converted
+	case time.Time:
+		return v.UnixNano()
+	case time.Duration:
+		return v.Nanoseconds()
+	case *big.Rat:
+		f, _ := v.Float64()
+		return f
+	}
+	return value
+}
+
 func init() {
 	parsers.Add("avro",
 		func(defaultMetricName string) telegraf.Parser {
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -48,6 +49,8 @@ type Parser struct {
 	MetadataSeparators []string        `toml:"csv_metadata_separators"`
 	MetadataTrimSet    string          `toml:"csv_metadata_trim_set"`
 	ResetMode          string          `toml:"csv_reset_mode"`
+	MultilineRecords   bool            `toml:"csv_multiline_records"`
+	TypeInferenceRows  int             `toml:"csv_type_inference_rows"`
 	Log                telegraf.Logger `toml:"-"`
 
 	metadataSeparatorList metadataPattern
@@ -65,8 +68,16 @@ type Parser struct {
 	remainingSkipRows     int
 	remainingHeaderRows   int
 	remainingMetadataRows int
+
+	// Types inferred for the columns and data of a record with quoted fields
+	// spanning multiple calls
+	inferredTypes []string
+	partial       []byte
 }
 
+// maxPartialSize limits the size of an incomplete record kept across calls
+const maxPartialSize = 1024 * 1024
+
 type metadataPattern []string
 
 func (record metadataPattern) Len() int {
@@ -134,6 +145,9 @@ func (p *Parser) Reset() {
 	p.remainingSkipRows = p.SkipRows
 	p.remainingHeaderRows = p.HeaderRowCount
 	p.remainingMetadataRows = p.MetadataRows
+
+	p.inferredTypes = nil
+	p.partial = nil
 }
 
 func (p *Parser) Init() error {
@@ -161,6 +175,13 @@ func (p *Parser) Init() error {
 		return errors.New("csv_column_names field count doesn't match with csv_column_types")
 	}
 
+	if p.TypeInferenceRows < 0 {
+		return errors.New("csv_type_inference_rows must not be negative")
+	}
+	if p.TypeInferenceRows > 0 && len(p.ColumnTypes) > 0 {
+		return errors.New("csv_type_inference_rows cannot be used together with csv_column_types")
+	}
+
 	if err := p.initializeMetadataSeparators(); err != nil {
 		return fmt.Errorf("initializing separators failed: %w", err)
 	}
@@ -217,18 +238,96 @@ func validDelim(r rune) bool {
 	return r != 0 && r != '"' && r != '\r' && r != '\n' && utf8.ValidRune(r) && r != utf8.RuneError
 }
 
+// replaceDelimiter replaces commas with replacement and the invalid delimiter
+// with commas if using an invalid delimiter
+func (p *Parser) replaceDelimiter(buf []byte) []byte {
+	if !p.invalidDelimiter {
+		return buf
+	}
+	buf = bytes.ReplaceAll(buf, []byte(commaByte), []byte(replacementByte))
+	return bytes.ReplaceAll(buf, []byte(p.Delimiter), []byte(commaByte))
+}
+
+// continueRecord prepends the incomplete record of previous calls to the data
+// and keeps a trailing record with an unterminated quoted field for the next
+// call, as quoted fields might contain line breaks. The function returns the
+// complete records and false if there are none.
+func (p *Parser) continueRecord(buf []byte) ([]byte, bool, error) {
+	if len(p.partial) > 0 {
+		buf = append(p.partial, buf...)
+		p.partial = nil
+	}
+
+	// Rows before the header are not necessarily valid CSV
+	if p.remainingSkipRows > 0 || p.remainingMetadataRows > 0 {
+		return buf, true, nil
+	}
+
+	idx := p.incompleteRecord(buf)
+	if idx < 0 {
+		return buf, true, nil
+	}
+	if len(buf)-idx > maxPartialSize {
+		return nil, false, fmt.Errorf("incomplete record exceeds %d bytes: %w", maxPartialSize, csv.ErrQuote)
+	}
+
+	// Line-wise callers strip the line break, so restore it
+	p.partial = append([]byte(nil), buf[idx:]...)
+	if !bytes.HasSuffix(p.partial, []byte("\n")) {
+		p.partial = append(p.partial, '\n')
+	}
+	return buf[:idx], idx > 0, nil
+}
+
+// incompleteRecord returns the offset of a trailing record with an
+// unterminated quoted field or -1 if all records are complete. Escaped quotes
+// are doubled within quoted fields so the number of quotes is always even for
+// complete records.
+func (p *Parser) incompleteRecord(buf []byte) int {
+	var start int
+	var quoted bool
+	for i := 0; i < len(buf); i++ {
+		if i == start && p.Comment != "" && bytes.HasPrefix(buf[i:], []byte(p.Comment)) {
+			n := bytes.IndexByte(buf[i:], '\n')
+			if n < 0 {
+				return -1
+			}
+			i += n
+			start = i + 1
+			continue
+		}
+		switch buf[i] {
+		case '"':
+			quoted = !quoted
+		case '\n':
+			if !quoted {
+				start = i + 1
+			}
+		}
+	}
+	if quoted {
+		return start
+	}
+	return -1
+}
+
 func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 	// Reset the parser according to the specified mode
 	if p.ResetMode == "always" {
 		p.Reset()
 	}
-	// If using an invalid delimiter, replace commas with replacement and
-	// invalid delimiter with commas
-	if p.invalidDelimiter {
-		buf = bytes.Replace(buf, []byte(commaByte), []byte(replacementByte), -1)
-		buf = bytes.Replace(buf, []byte(p.Delimiter), []byte(commaByte), -1)
+
+	// Without reset the data is a continuous stream where records might be
+	// split across calls, e.g. when parsing line-by-line
+	if p.ResetMode == "none" && p.MultilineRecords {
+		var complete bool
+		var err error
+		if buf, complete, err = p.continueRecord(buf); err != nil || !complete {
+			return nil, err
+		}
 	}
-	r := bytes.NewReader(buf)
+
+	r := bytes.NewReader(p.replaceDelimiter(buf))
 	metrics, err := parseCSV(p, r)
 	if err != nil && errors.Is(err, io.EOF) {
 		return nil, parsers.ErrEOF
@@ -237,6 +336,15 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 }
 
 func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	// Quoted fields might span multiple lines
+	if p.MultilineRecords {
+		buf, complete, err := p.continueRecord([]byte(line))
+		if err != nil || !complete {
+			return nil, err
+		}
+		line = string(buf)
+	}
+
 	if len(line) == 0 {
 		if p.remainingSkipRows > 0 {
 			p.remainingSkipRows--
@@ -247,7 +355,7 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 			return nil, parsers.ErrEOF
 		}
 	}
-	r := bytes.NewReader([]byte(line))
+	r := bytes.NewReader(p.replaceDelimiter([]byte(line)))
 	metrics, err := parseCSV(p, r)
 	if err != nil {
 		if errors.Is(err, io.EOF) {
@@ -264,7 +372,135 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	return nil, nil
 }
 
+// StreamParser parses CSV data record by record from a reader without loading
+// the whole data into memory. It uses the state of the parser it was created
+// from and is not safe for concurrent use.
+type StreamParser struct {
+	parser  *Parser
+	reader  io.Reader
+	records *csv.Reader
+	pending [][]string
+}
+
+// NewStreamParser creates a parser for the given stream resetting the parser
+// according to the reset mode.
+func (p *Parser) NewStreamParser(r io.Reader) *StreamParser {
+	if p.ResetMode == "always" {
+		p.Reset()
+	}
+	if p.invalidDelimiter {
+		r = &delimiterReader{parser: p, reader: bufio.NewReader(r)}
+	}
+	return &StreamParser{parser: p, reader: r}
+}
+
+// Next parses the next record of the stream and returns io.EOF at the end of
+// the stream. You can repeat calls to this function after a parsing error to
+// get the next metric or error.
+func (sp *StreamParser) Next() (telegraf.Metric, error) {
+	if sp.records == nil {
+		records, err := sp.parser.prepare(sp.reader)
+		if err != nil {
+			return nil, err
+		}
+		sp.records = records
+
+		// Read ahead the records required to infer the column types
+		if sp.parser.inferenceRequired() {
+			for len(sp.pending) < sp.parser.TypeInferenceRows {
+				record, err := sp.records.Read()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					return nil, err
+				}
+				sp.pending = append(sp.pending, record)
+			}
+			sp.parser.inferColumnTypes(sp.pending)
+		}
+	}
+
+	for {
+		var record []string
+		if len(sp.pending) > 0 {
+			record, sp.pending = sp.pending[0], sp.pending[1:]
+		} else {
+			var err error
+			if record, err = sp.records.Read(); err != nil {
+				return nil, err
+			}
+		}
+
+		m, err := sp.parser.parseRecord(record)
+		if err != nil {
+			if sp.parser.SkipErrors {
+				sp.parser.Log.Debugf("Parsing error: %v", err)
+				continue
+			}
+			return nil, err
+		}
+		return m, nil
+	}
+}
+
+// delimiterReader replaces invalid delimiters line by line to not split
+// multi-byte delimiters
+type delimiterReader struct {
+	parser *Parser
+	reader *bufio.Reader
+	buf    []byte
+}
+
+func (r *delimiterReader) Read(b []byte) (int, error) {
+	for len(r.buf) == 0 {
+		line, err := r.reader.ReadBytes('\n')
+		r.buf = r.parser.replaceDelimiter(line)
+		if err != nil {
+			if len(r.buf) == 0 {
+				return 0, err
+			}
+			break
+		}
+	}
+	n := copy(b, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
 func parseCSV(p *Parser, r io.Reader) ([]telegraf.Metric, error) {
+	csvReader, err := p.prepare(r)
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.inferenceRequired() {
+		p.inferColumnTypes(table[:min(len(table), p.TypeInferenceRows)])
+	}
+
+	metrics := make([]telegraf.Metric, 0)
+	for _, record := range table {
+		m, err := p.parseRecord(record)
+		if err != nil {
+			if p.SkipErrors {
+				p.Log.Debugf("Parsing error: %v", err)
+				continue
+			}
+			return metrics, err
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
+
+// prepare consumes the skipped, metadata and header rows and returns the
+// reader for the remaining records
+func (p *Parser) prepare(r io.Reader) (*csv.Reader, error) {
 	lineReader := bufio.NewReader(r)
 	// skip first rows
 	for p.remainingSkipRows > 0 {
@@ -320,24 +556,63 @@ func parseCSV(p *Parser, r io.Reader) ([]telegraf.Metric, error) {
 		p.gotColumnNames = true
 	}
 
-	table, err := csvReader.ReadAll()
-	if err != nil {
-		return nil, err
+	return csvReader, nil
+}
+
+// inferenceRequired returns true if the column types should be inferred but
+// are not known yet
+func (p *Parser) inferenceRequired() bool {
+	return p.TypeInferenceRows > 0 && p.inferredTypes == nil
+}
+
+// inferColumnTypes determines the narrowest type fitting all non-empty values
+// of each column in the given records. Columns without values are converted
+// per value.
+func (p *Parser) inferColumnTypes(records [][]string) {
+	if len(records) == 0 {
+		return
 	}
 
-	metrics := make([]telegraf.Metric, 0)
-	for _, record := range table {
-		m, err := p.parseRecord(record)
-		if err != nil {
-			if p.SkipErrors {
-				p.Log.Debugf("Parsing error: %v", err)
+	p.inferredTypes = make([]string, len(p.ColumnNames))
+	for _, record := range records {
+		record = record[p.SkipColumns:]
+		for i := range p.ColumnNames {
+			if i >= len(record) {
+				break
+			}
+			value := record[i]
+			if p.TrimSpace {
+				value = strings.Trim(value, " ")
+			}
+			if value == "" || slices.Contains(p.SkipValues, value) {
 				continue
 			}
-			return metrics, err
+			p.inferredTypes[i] = widenType(p.inferredTypes[i], valueType(value))
 		}
-		metrics = append(metrics, m)
 	}
-	return metrics, nil
+}
+
+func valueType(value string) string {
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return "int"
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return "float"
+	}
+	if _, err := strconv.ParseBool(value); err == nil {
+		return "bool"
+	}
+	return "string"
+}
+
+func widenType(current, value string) string {
+	switch {
+	case current == "" || current == value:
+		return value
+	case (current == "int" && value == "float") || (current == "float" && value == "int"):
+		return "float"
+	}
+	return "string"
 }
 
 func (p *Parser) parseRecord(record []string) (telegraf.Metric, error) {
@@ -420,6 +695,33 @@ outer:
 				continue
 			}
 
+			// Use the types inferred from the first records
+			if i < len(p.inferredTypes) && p.inferredTypes[i] != "" {
+				typ := p.inferredTypes[i]
+				if value == "" && typ != "string" {
+					continue
+				}
+
+				var val interface{}
+				var err error
+				switch typ {
+				case "int":
+					val, err = strconv.ParseInt(value, 10, 64)
+				case "float":
+					val, err = strconv.ParseFloat(value, 64)
+				case "bool":
+					val, err = strconv.ParseBool(value)
+				default:
+					val = value
+				}
+				if err != nil {
+					return nil, fmt.Errorf("inferred column type: parse %s error %w", typ, err)
+				}
+
+				recordFields[fieldName] = val
+				continue
+			}
+
 			// attempt type conversions
 			if iValue, err := strconv.ParseInt(value, 10, 64); err == nil {
 				recordFields[fieldName] = iValue
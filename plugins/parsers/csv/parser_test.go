@@ -1,7 +1,10 @@
 package csv
 
 import (
+	"bytes"
+	"encoding/csv"
 	"errors"
+	"io"
 	"strings"
 	"testing"
 	"time"
@@ -1576,3 +1579,231 @@ func BenchmarkParsing(b *testing.B) {
 		plugin.Parse([]byte(benchmarkData))
 	}
 }
+
+func TestMultilineQuotedFields(t *testing.T) {
+	expected := []telegraf.Metric{
+		metric.New(
+			"csv",
+			map[string]string{},
+			map[string]interface{}{
+				"id":      int64(1),
+				"message": "first line\nsecond \"line\"",
+				"value":   int64(10),
+			},
+			DefaultTime(),
+		),
+		metric.New(
+			"csv",
+			map[string]string{},
+			map[string]interface{}{
+				"id":      int64(2),
+				"message": "single line",
+				"value":   int64(20),
+			},
+			DefaultTime(),
+		),
+	}
+
+	// Parsing line-by-line keeps incomplete records until the quote is closed
+	lines := []string{
+		"id,message,value",
+		`1,"first line`,
+		`second ""line""",10`,
+		`2,single line,20`,
+	}
+	p := &Parser{
+		MetricName:       "csv",
+		HeaderRowCount:   1,
+		MultilineRecords: true,
+		TimeFunc:         DefaultTime,
+	}
+	require.NoError(t, p.Init())
+	actual := make([]telegraf.Metric, 0, len(expected))
+	for _, line := range lines {
+		m, err := p.ParseLine(line)
+		if errors.Is(err, parsers.ErrEOF) {
+			continue
+		}
+		require.NoError(t, err)
+		if m != nil {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// The same applies to subsequent chunks in reset mode "none"
+	p = &Parser{
+		MetricName:       "csv",
+		HeaderRowCount:   1,
+		MultilineRecords: true,
+		TimeFunc:         DefaultTime,
+	}
+	require.NoError(t, p.Init())
+	actual = actual[:0]
+	for _, chunk := range []string{"id,message,value\n1,\"first line\n", "second \"\"line\"\"\",10\n2,single line,20\n"} {
+		metrics, err := p.Parse([]byte(chunk))
+		require.NoError(t, err)
+		actual = append(actual, metrics...)
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Complete documents are parsed as is
+	p = &Parser{
+		MetricName:     "csv",
+		HeaderRowCount: 1,
+		ResetMode:      "always",
+		TimeFunc:       DefaultTime,
+	}
+	require.NoError(t, p.Init())
+	metrics, err := p.Parse([]byte(strings.Join(lines, "\n")))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestUnterminatedQuote(t *testing.T) {
+	// Without multi-line records unterminated quotes are an error
+	p := &Parser{
+		MetricName:     "csv",
+		HeaderRowCount: 1,
+		TimeFunc:       DefaultTime,
+	}
+	require.NoError(t, p.Init())
+	_, err := p.Parse([]byte("id,message\n1,\"unterminated\n"))
+	require.ErrorIs(t, err, csv.ErrQuote)
+	require.Empty(t, p.partial)
+
+	// Incomplete records are bounded in size
+	p = &Parser{
+		MetricName:       "csv",
+		HeaderRowCount:   1,
+		MultilineRecords: true,
+		TimeFunc:         DefaultTime,
+	}
+	require.NoError(t, p.Init())
+	_, err = p.Parse([]byte("id,message\n"))
+	require.NoError(t, err)
+	metrics, err := p.Parse([]byte("1,\"unterminated\n"))
+	require.NoError(t, err)
+	require.Empty(t, metrics)
+	_, err = p.Parse(bytes.Repeat([]byte("x"), maxPartialSize))
+	require.ErrorIs(t, err, csv.ErrQuote)
+	require.Empty(t, p.partial)
+}
+
+func TestTypeInference(t *testing.T) {
+	csv := `name,count,ratio,enabled,code
+a,1,1,true,10
+b,2,1.5,false,x1
+c,,2,true,11
+d,4,3,false,12
+`
+	expected := []telegraf.Metric{
+		metric.New("csv", map[string]string{}, map[string]interface{}{
+			"name": "a", "count": int64(1), "ratio": 1.0, "enabled": true, "code": "10",
+		}, DefaultTime()),
+		metric.New("csv", map[string]string{}, map[string]interface{}{
+			"name": "b", "count": int64(2), "ratio": 1.5, "enabled": false, "code": "x1",
+		}, DefaultTime()),
+		metric.New("csv", map[string]string{}, map[string]interface{}{
+			"name": "c", "ratio": 2.0, "enabled": true, "code": "11",
+		}, DefaultTime()),
+		metric.New("csv", map[string]string{}, map[string]interface{}{
+			"name": "d", "count": int64(4), "ratio": 3.0, "enabled": false, "code": "12",
+		}, DefaultTime()),
+	}
+
+	p := &Parser{
+		MetricName:        "csv",
+		HeaderRowCount:    1,
+		TypeInferenceRows: 3,
+		TimeFunc:          DefaultTime,
+	}
+	require.NoError(t, p.Init())
+	metrics, err := p.Parse([]byte(csv))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, metrics)
+
+	// The inferred types are kept for subsequent data
+	_, err = p.Parse([]byte("e,five,1,true,13\n"))
+	require.ErrorContains(t, err, "inferred column type: parse int error")
+}
+
+func TestTypeInferenceInitFail(t *testing.T) {
+	p := &Parser{
+		ColumnNames:       []string{"a", "b"},
+		ColumnTypes:       []string{"int", "int"},
+		TypeInferenceRows: 10,
+	}
+	require.ErrorContains(t, p.Init(), "csv_type_inference_rows cannot be used together with csv_column_types")
+
+	p = &Parser{
+		ColumnNames:       []string{"a", "b"},
+		TypeInferenceRows: -1,
+	}
+	require.ErrorContains(t, p.Init(), "csv_type_inference_rows must not be negative")
+}
+
+func TestStreamParser(t *testing.T) {
+	data := "# generated by test\nname;value;note\nfirst;1;\"a;b\"\nsecond;2.5;\"multi\nline\"\nshort;1\ninvalid;x;y\nthird;3;c\n"
+
+	p := &Parser{
+		MetricName:        "csv",
+		HeaderRowCount:    1,
+		SkipRows:          1,
+		Delimiter:         ";",
+		TypeInferenceRows: 2,
+		SkipErrors:        true,
+		TimeFunc:          DefaultTime,
+		Log:               testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	stream := p.NewStreamParser(strings.NewReader(data))
+	var actual []telegraf.Metric
+	for {
+		m, err := stream.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		actual = append(actual, m)
+	}
+
+	// The value column is inferred as float from the first two rows and
+	// records not matching the type are skipped
+	expected := []telegraf.Metric{
+		metric.New("csv", map[string]string{}, map[string]interface{}{
+			"name": "first", "value": 1.0, "note": "a;b",
+		}, DefaultTime()),
+		metric.New("csv", map[string]string{}, map[string]interface{}{
+			"name": "second", "value": 2.5, "note": "multi\nline",
+		}, DefaultTime()),
+		metric.New("csv", map[string]string{}, map[string]interface{}{
+			"name": "short", "value": 1.0,
+		}, DefaultTime()),
+		metric.New("csv", map[string]string{}, map[string]interface{}{
+			"name": "third", "value": 3.0, "note": "c",
+		}, DefaultTime()),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestStreamParserInvalidDelimiter(t *testing.T) {
+	p := &Parser{
+		MetricName:     "csv",
+		HeaderRowCount: 1,
+		Delimiter:      "\u0000",
+		TimeFunc:       DefaultTime,
+	}
+	require.NoError(t, p.Init())
+
+	stream := p.NewStreamParser(strings.NewReader("a\u0000b\n1\u00002\n"))
+	m, err := stream.Next()
+	require.NoError(t, err)
+	testutil.RequireMetricEqual(t,
+		metric.New("csv", map[string]string{}, map[string]interface{}{"a": int64(1), "b": int64(2)}, DefaultTime()),
+		m,
+	)
+	_, err = stream.Next()
+	require.ErrorIs(t, err, io.EOF)
+}
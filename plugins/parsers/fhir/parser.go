@@ -0,0 +1,350 @@
+package fhir
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+// Layouts of the FHIR dateTime and instant types with decreasing precision
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+type Parser struct {
+	IncludeSubject bool              `toml:"fhir_include_subject"`
+	MetricName     string            `toml:"-"`
+	DefaultTags    map[string]string `toml:"-"`
+}
+
+type coding struct {
+	System  string `json:"system"`
+	Code    string `json:"code"`
+	Display string `json:"display"`
+}
+
+type codeableConcept struct {
+	Coding []coding `json:"coding"`
+	Text   string   `json:"text"`
+}
+
+type quantity struct {
+	Value *float64 `json:"value"`
+	Unit  string   `json:"unit"`
+	Code  string   `json:"code"`
+}
+
+type reference struct {
+	Reference string `json:"reference"`
+}
+
+type period struct {
+	Start string `json:"start"`
+}
+
+type referenceRange struct {
+	Low  *quantity `json:"low"`
+	High *quantity `json:"high"`
+}
+
+// value holds the value[x] choice elements of observations and components
+type value struct {
+	ValueQuantity        *quantity        `json:"valueQuantity"`
+	ValueString          *string          `json:"valueString"`
+	ValueBoolean         *bool            `json:"valueBoolean"`
+	ValueInteger         *int64           `json:"valueInteger"`
+	ValueCodeableConcept *codeableConcept `json:"valueCodeableConcept"`
+}
+
+type component struct {
+	value
+	Code           codeableConcept   `json:"code"`
+	Interpretation []codeableConcept `json:"interpretation"`
+	ReferenceRange []referenceRange  `json:"referenceRange"`
+}
+
+type resource struct {
+	value
+	ResourceType      string            `json:"resourceType"`
+	ID                string            `json:"id"`
+	Status            string            `json:"status"`
+	Category          []codeableConcept `json:"category"`
+	Code              codeableConcept   `json:"code"`
+	Subject           *reference        `json:"subject"`
+	Device            *reference        `json:"device"`
+	EffectiveDateTime string            `json:"effectiveDateTime"`
+	EffectiveInstant  string            `json:"effectiveInstant"`
+	EffectivePeriod   *period           `json:"effectivePeriod"`
+	Issued            string            `json:"issued"`
+	Interpretation    []codeableConcept `json:"interpretation"`
+	ReferenceRange    []referenceRange  `json:"referenceRange"`
+	Component         []component       `json:"component"`
+
+	// Bundle entries
+	Entry []struct {
+		Resource *resource `json:"resource"`
+	} `json:"entry"`
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	buf = bytes.TrimSpace(buf)
+	if len(buf) == 0 {
+		return nil, nil
+	}
+
+	// Accept single resources, arrays of resources and newline delimited
+	// resources as used for bulk data export
+	var resources []*resource
+	decoder := json.NewDecoder(bytes.NewReader(buf))
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("decoding resource failed: %w", err)
+		}
+
+		if bytes.HasPrefix(raw, []byte("[")) {
+			var list []*resource
+			if err := json.Unmarshal(raw, &list); err != nil {
+				return nil, fmt.Errorf("decoding resource list failed: %w", err)
+			}
+			resources = append(resources, list...)
+			continue
+		}
+
+		var r resource
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return nil, fmt.Errorf("decoding resource failed: %w", err)
+		}
+		resources = append(resources, &r)
+	}
+
+	var metrics []telegraf.Metric
+	for _, r := range resources {
+		metrics = append(metrics, p.convert(r)...)
+	}
+
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(metrics) {
+	case 0:
+		return nil, nil
+	case 1:
+		return metrics[0], nil
+	default:
+		return metrics[0], fmt.Errorf("cannot parse line with multiple (%d) metrics", len(metrics))
+	}
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+// convert creates the metrics of observations, recursing into bundles. All
+// other resources types are ignored.
+func (p *Parser) convert(r *resource) []telegraf.Metric {
+	if r == nil {
+		return nil
+	}
+
+	switch r.ResourceType {
+	case "Bundle":
+		var metrics []telegraf.Metric
+		for _, entry := range r.Entry {
+			metrics = append(metrics, p.convert(entry.Resource)...)
+		}
+		return metrics
+	case "Observation":
+	default:
+		return nil
+	}
+
+	tags := make(map[string]string)
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	setTag := func(tags map[string]string, key, value string) {
+		if value != "" {
+			tags[key] = value
+		}
+	}
+	setTag(tags, "status", r.Status)
+	if len(r.Category) > 0 {
+		setTag(tags, "category", r.Category[0].code())
+	}
+	if r.Device != nil {
+		setTag(tags, "device", r.Device.Reference)
+	}
+	if p.IncludeSubject && r.Subject != nil {
+		setTag(tags, "subject", r.Subject.Reference)
+	}
+
+	timestamp := time.Now()
+	for _, ts := range []string{r.EffectiveDateTime, r.EffectiveInstant, r.effectiveStart(), r.Issued} {
+		if t, err := parseTime(ts); err == nil {
+			timestamp = t
+			break
+		}
+	}
+
+	var metrics []telegraf.Metric
+
+	// Observation with a value of its own
+	if fields := r.fields(r.ReferenceRange); len(fields) > 0 {
+		mtags := make(map[string]string, len(tags)+6)
+		for k, v := range tags {
+			mtags[k] = v
+		}
+		r.Code.setTags(mtags)
+		if len(r.Interpretation) > 0 {
+			setTag(mtags, "interpretation", r.Interpretation[0].code())
+		}
+		setTag(mtags, "unit", r.unit())
+		if r.ID != "" {
+			fields["id"] = r.ID
+		}
+		metrics = append(metrics, metric.New(p.MetricName, mtags, fields, timestamp))
+	}
+
+	// Panels like blood pressure carry their values in components
+	for _, c := range r.Component {
+		fields := c.fields(c.ReferenceRange)
+		if len(fields) == 0 {
+			continue
+		}
+		ctags := make(map[string]string, len(tags)+7)
+		for k, v := range tags {
+			ctags[k] = v
+		}
+		c.Code.setTags(ctags)
+		setTag(ctags, "panel_code", r.Code.code())
+		if len(c.Interpretation) > 0 {
+			setTag(ctags, "interpretation", c.Interpretation[0].code())
+		}
+		setTag(ctags, "unit", c.unit())
+		if r.ID != "" {
+			fields["id"] = r.ID
+		}
+		metrics = append(metrics, metric.New(p.MetricName, ctags, fields, timestamp))
+	}
+
+	return metrics
+}
+
+func (r *resource) effectiveStart() string {
+	if r.EffectivePeriod == nil {
+		return ""
+	}
+	return r.EffectivePeriod.Start
+}
+
+// fields returns the value and reference range fields, or nil if no value is
+// present
+func (v *value) fields(ranges []referenceRange) map[string]interface{} {
+	var x interface{}
+	switch {
+	case v.ValueQuantity != nil && v.ValueQuantity.Value != nil:
+		x = *v.ValueQuantity.Value
+	case v.ValueString != nil:
+		x = *v.ValueString
+	case v.ValueBoolean != nil:
+		x = *v.ValueBoolean
+	case v.ValueInteger != nil:
+		x = *v.ValueInteger
+	case v.ValueCodeableConcept != nil && v.ValueCodeableConcept.code() != "":
+		x = v.ValueCodeableConcept.code()
+	default:
+		return nil
+	}
+
+	fields := map[string]interface{}{"value": x}
+	if len(ranges) > 0 {
+		if low := ranges[0].Low; low != nil && low.Value != nil {
+			fields["reference_low"] = *low.Value
+		}
+		if high := ranges[0].High; high != nil && high.Value != nil {
+			fields["reference_high"] = *high.Value
+		}
+	}
+	return fields
+}
+
+// unit returns the human-readable unit of a quantity value, falling back to
+// its coded unit
+func (v *value) unit() string {
+	if v.ValueQuantity == nil {
+		return ""
+	}
+	if v.ValueQuantity.Unit != "" {
+		return v.ValueQuantity.Unit
+	}
+	return v.ValueQuantity.Code
+}
+
+// code returns the first code of the concept, falling back to its text
+func (c *codeableConcept) code() string {
+	for _, cd := range c.Coding {
+		if cd.Code != "" {
+			return cd.Code
+		}
+	}
+	return c.Text
+}
+
+func (c *codeableConcept) setTags(tags map[string]string) {
+	if code := c.code(); code != "" {
+		tags["code"] = code
+	}
+	for _, cd := range c.Coding {
+		if cd.Code == "" {
+			continue
+		}
+		if cd.System != "" {
+			tags["code_system"] = cd.System
+		}
+		if cd.Display != "" {
+			tags["code_display"] = cd.Display
+		}
+		break
+	}
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, errors.New("empty timestamp")
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+}
+
+func init() {
+	parsers.Add("fhir",
+		func(defaultMetricName string) telegraf.Parser {
+			return &Parser{MetricName: defaultMetricName}
+		},
+	)
+}
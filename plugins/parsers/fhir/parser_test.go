@@ -0,0 +1,195 @@
+package fhir
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const vitalsBundle = `
+{
+  "resourceType": "Bundle",
+  "type": "collection",
+  "entry": [
+    {
+      "resource": {
+        "resourceType": "Observation",
+        "id": "hr-1",
+        "status": "final",
+        "category": [{"coding": [{"system": "http://terminology.hl7.org/CodeSystem/observation-category", "code": "vital-signs"}]}],
+        "code": {"coding": [{"system": "http://loinc.org", "code": "8867-4", "display": "Heart rate"}]},
+        "subject": {"reference": "Patient/123"},
+        "device": {"reference": "Device/monitor-7"},
+        "effectiveDateTime": "2024-01-15T10:29:50Z",
+        "valueQuantity": {"value": 72, "unit": "beats/minute", "system": "http://unitsofmeasure.org", "code": "/min"},
+        "referenceRange": [{"low": {"value": 60}, "high": {"value": 100}}]
+      }
+    },
+    {
+      "resource": {
+        "resourceType": "Observation",
+        "id": "bp-1",
+        "status": "final",
+        "code": {"coding": [{"system": "http://loinc.org", "code": "85354-9", "display": "Blood pressure panel"}]},
+        "subject": {"reference": "Patient/123"},
+        "effectivePeriod": {"start": "2024-01-15T10:30:00+01:00"},
+        "component": [
+          {
+            "code": {"coding": [{"system": "http://loinc.org", "code": "8480-6", "display": "Systolic blood pressure"}]},
+            "valueQuantity": {"value": 142, "code": "mm[Hg]"},
+            "interpretation": [{"coding": [{"code": "H"}]}]
+          },
+          {
+            "code": {"coding": [{"system": "http://loinc.org", "code": "8462-4", "display": "Diastolic blood pressure"}]},
+            "valueQuantity": {"value": 85, "code": "mm[Hg]"}
+          },
+          {
+            "code": {"text": "Cuff position"}
+          }
+        ]
+      }
+    },
+    {
+      "resource": {
+        "resourceType": "Patient",
+        "id": "123"
+      }
+    }
+  ]
+}`
+
+func TestParseBundle(t *testing.T) {
+	expected := []telegraf.Metric{
+		metric.New(
+			"fhir",
+			map[string]string{
+				"code":         "8867-4",
+				"code_system":  "http://loinc.org",
+				"code_display": "Heart rate",
+				"category":     "vital-signs",
+				"status":       "final",
+				"device":       "Device/monitor-7",
+				"unit":         "beats/minute",
+			},
+			map[string]interface{}{
+				"value":          72.0,
+				"reference_low":  60.0,
+				"reference_high": 100.0,
+				"id":             "hr-1",
+			},
+			time.Date(2024, 1, 15, 10, 29, 50, 0, time.UTC),
+		),
+		metric.New(
+			"fhir",
+			map[string]string{
+				"code":           "8480-6",
+				"code_system":    "http://loinc.org",
+				"code_display":   "Systolic blood pressure",
+				"panel_code":     "85354-9",
+				"status":         "final",
+				"interpretation": "H",
+				"unit":           "mm[Hg]",
+			},
+			map[string]interface{}{
+				"value": 142.0,
+				"id":    "bp-1",
+			},
+			time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC),
+		),
+		metric.New(
+			"fhir",
+			map[string]string{
+				"code":         "8462-4",
+				"code_system":  "http://loinc.org",
+				"code_display": "Diastolic blood pressure",
+				"panel_code":   "85354-9",
+				"status":       "final",
+				"unit":         "mm[Hg]",
+			},
+			map[string]interface{}{
+				"value": 85.0,
+				"id":    "bp-1",
+			},
+			time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC),
+		),
+	}
+
+	parser := &Parser{MetricName: "fhir"}
+	actual, err := parser.Parse([]byte(vitalsBundle))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestParseIncludeSubject(t *testing.T) {
+	parser := &Parser{MetricName: "fhir", IncludeSubject: true}
+	actual, err := parser.Parse([]byte(vitalsBundle))
+	require.NoError(t, err)
+	require.Len(t, actual, 3)
+	for _, m := range actual {
+		require.Equal(t, "Patient/123", m.Tags()["subject"])
+	}
+}
+
+func TestParseValueTypes(t *testing.T) {
+	input := `
+{"resourceType": "Observation", "code": {"text": "note"}, "valueString": "calm", "issued": "2024-01-15T10:31:00.123Z"}
+{"resourceType": "Observation", "code": {"text": "alarm"}, "valueBoolean": true, "effectiveInstant": "2024-01-15T10:32:00Z"}
+{"resourceType": "Observation", "code": {"text": "count"}, "valueInteger": 3, "effectiveDateTime": "2024-01-15"}
+{"resourceType": "Observation", "code": {"text": "rhythm"}, "valueCodeableConcept": {"coding": [{"code": "SR"}]}, "effectiveDateTime": "2024-01"}
+{"resourceType": "Observation", "code": {"text": "pending"}, "status": "registered"}
+`
+	expected := []telegraf.Metric{
+		metric.New("fhir", map[string]string{"code": "note"}, map[string]interface{}{"value": "calm"},
+			time.Date(2024, 1, 15, 10, 31, 0, 123000000, time.UTC)),
+		metric.New("fhir", map[string]string{"code": "alarm"}, map[string]interface{}{"value": true},
+			time.Date(2024, 1, 15, 10, 32, 0, 0, time.UTC)),
+		metric.New("fhir", map[string]string{"code": "count"}, map[string]interface{}{"value": int64(3)},
+			time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)),
+		metric.New("fhir", map[string]string{"code": "rhythm"}, map[string]interface{}{"value": "SR"},
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+	}
+
+	parser := &Parser{MetricName: "fhir"}
+	actual, err := parser.Parse([]byte(input))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestParseArray(t *testing.T) {
+	input := `[
+  {"resourceType": "Observation", "code": {"text": "a"}, "valueInteger": 1},
+  {"resourceType": "Observation", "code": {"text": "b"}, "valueInteger": 2}
+]`
+
+	parser := &Parser{MetricName: "fhir"}
+	actual, err := parser.Parse([]byte(input))
+	require.NoError(t, err)
+	require.Len(t, actual, 2)
+}
+
+func TestParseInvalid(t *testing.T) {
+	parser := &Parser{MetricName: "fhir"}
+	_, err := parser.Parse([]byte(`{"resourceType": "Observation",`))
+	require.ErrorContains(t, err, "decoding resource failed")
+}
+
+func TestParseLine(t *testing.T) {
+	parser := &Parser{MetricName: "fhir"}
+	parser.SetDefaultTags(map[string]string{"ward": "icu"})
+
+	m, err := parser.ParseLine(`{"resourceType": "Observation", "code": {"text": "a"}, "valueInteger": 1}`)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"code": "a", "ward": "icu"}, m.Tags())
+
+	m, err = parser.ParseLine(`{"resourceType": "Patient", "id": "123"}`)
+	require.NoError(t, err)
+	require.Nil(t, m)
+
+	_, err = parser.ParseLine(vitalsBundle)
+	require.ErrorContains(t, err, "cannot parse line with multiple (3) metrics")
+}
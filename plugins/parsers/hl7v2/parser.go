@@ -0,0 +1,528 @@
+package hl7v2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+// MLLP framing characters, see HL7 Minimal Lower Layer Protocol
+const (
+	mllpStart = 0x0b
+	mllpEnd   = 0x1c
+)
+
+type Parser struct {
+	Tags        map[string]string `toml:"hl7v2_tags"`
+	Fields      map[string]string `toml:"hl7v2_fields"`
+	Timezone    string            `toml:"hl7v2_timezone"`
+	MetricName  string            `toml:"-"`
+	DefaultTags map[string]string `toml:"-"`
+
+	location   *time.Location
+	tagPaths   map[string]path
+	fieldPaths map[string]path
+}
+
+func (p *Parser) Init() error {
+	p.location = time.UTC
+	if p.Timezone != "" {
+		loc, err := time.LoadLocation(p.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone: %w", err)
+		}
+		p.location = loc
+	}
+
+	p.tagPaths = make(map[string]path, len(p.Tags))
+	for name, spec := range p.Tags {
+		pth, err := parsePath(spec)
+		if err != nil {
+			return fmt.Errorf("invalid path for tag %q: %w", name, err)
+		}
+		p.tagPaths[name] = pth
+	}
+
+	p.fieldPaths = make(map[string]path, len(p.Fields))
+	for name, spec := range p.Fields {
+		pth, err := parsePath(spec)
+		if err != nil {
+			return fmt.Errorf("invalid path for field %q: %w", name, err)
+		}
+		p.fieldPaths[name] = pth
+	}
+
+	return nil
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	var metrics []telegraf.Metric
+	for _, raw := range splitMessages(buf) {
+		msg, err := parseMessage(raw)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, p.observations(msg)...)
+	}
+
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(metrics) {
+	case 0:
+		return nil, nil
+	case 1:
+		return metrics[0], nil
+	default:
+		return metrics[0], fmt.Errorf("cannot parse line with multiple (%d) metrics", len(metrics))
+	}
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+// observations creates a metric for each OBX segment of the message
+func (p *Parser) observations(msg *message) []telegraf.Metric {
+	msh := msg.first("MSH")
+	obr := msg.first("OBR")
+
+	var metrics []telegraf.Metric
+	for _, obx := range msg.segments {
+		if obx.name != "OBX" {
+			continue
+		}
+
+		tags := make(map[string]string)
+		for k, v := range p.DefaultTags {
+			tags[k] = v
+		}
+		setTag := func(key, value string) {
+			if value != "" {
+				tags[key] = value
+			}
+		}
+		if msgType := msg.value(msh, path{segment: "MSH", field: 9, component: 1}); msgType != "" {
+			if event := msg.value(msh, path{segment: "MSH", field: 9, component: 2}); event != "" {
+				msgType += "^" + event
+			}
+			tags["message_type"] = msgType
+		}
+		setTag("sending_application", msg.value(msh, path{segment: "MSH", field: 3, component: 1}))
+		setTag("sending_facility", msg.value(msh, path{segment: "MSH", field: 4, component: 1}))
+		setTag("observation_id", msg.value(obx, path{segment: "OBX", field: 3, component: 1}))
+		setTag("observation_text", msg.value(obx, path{segment: "OBX", field: 3, component: 2}))
+		setTag("coding_system", msg.value(obx, path{segment: "OBX", field: 3, component: 3}))
+		setTag("sub_id", msg.value(obx, path{segment: "OBX", field: 4}))
+		setTag("units", msg.value(obx, path{segment: "OBX", field: 6, component: 1}))
+		setTag("abnormal_flag", msg.value(obx, path{segment: "OBX", field: 8}))
+		setTag("status", msg.value(obx, path{segment: "OBX", field: 11}))
+		setTag("equipment", msg.value(obx, path{segment: "OBX", field: 18, component: 1}))
+
+		fields := make(map[string]interface{})
+		if v := observationValue(msg, obx); v != nil {
+			fields["value"] = v
+		}
+		if v := msg.value(obx, path{segment: "OBX", field: 7}); v != "" {
+			fields["reference_range"] = v
+		}
+		if v := msg.value(msh, path{segment: "MSH", field: 10}); v != "" {
+			fields["control_id"] = v
+		}
+
+		// User-defined values either refer to the current observation or to
+		// the first segment of the given type
+		lookup := func(pth path) string {
+			if pth.segment == "OBX" {
+				return msg.value(obx, pth)
+			}
+			return msg.value(msg.first(pth.segment), pth)
+		}
+		for name, pth := range p.tagPaths {
+			setTag(name, lookup(pth))
+		}
+		for name, pth := range p.fieldPaths {
+			if v := lookup(pth); v != "" {
+				fields[name] = convert(v)
+			}
+		}
+
+		if _, found := fields["value"]; !found {
+			continue
+		}
+
+		// Use the most specific timestamp available
+		var timestamp time.Time
+		for _, ts := range []string{
+			msg.value(obx, path{segment: "OBX", field: 14, component: 1}),
+			msg.value(obr, path{segment: "OBR", field: 7, component: 1}),
+			msg.value(msh, path{segment: "MSH", field: 7, component: 1}),
+		} {
+			if t, err := parseTime(ts, p.location); err == nil {
+				timestamp = t
+				break
+			}
+		}
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+
+		metrics = append(metrics, metric.New(p.MetricName, tags, fields, timestamp))
+	}
+
+	return metrics
+}
+
+// observationValue converts the value of the observation according to the
+// value type given in OBX-2
+func observationValue(msg *message, obx *segment) interface{} {
+	valueType := msg.value(obx, path{segment: "OBX", field: 2})
+	switch valueType {
+	case "NM":
+		raw := msg.value(obx, path{segment: "OBX", field: 5})
+		if raw == "" {
+			return nil
+		}
+		if v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64); err == nil {
+			return v
+		}
+		return raw
+	case "SN":
+		// Structured numeric values are only converted if they represent a
+		// plain number without comparator, e.g. "^120"
+		comparator := msg.value(obx, path{segment: "OBX", field: 5, component: 1})
+		separator := msg.value(obx, path{segment: "OBX", field: 5, component: 3})
+		num := msg.value(obx, path{segment: "OBX", field: 5, component: 2})
+		if comparator == "" && separator == "" && num != "" {
+			if v, err := strconv.ParseFloat(num, 64); err == nil {
+				return v
+			}
+		}
+	case "CE", "CWE", "CNE", "CF":
+		if v := msg.value(obx, path{segment: "OBX", field: 5, component: 1}); v != "" {
+			return v
+		}
+		return nil
+	}
+
+	if raw := msg.value(obx, path{segment: "OBX", field: 5}); raw != "" {
+		return raw
+	}
+	return nil
+}
+
+func convert(value string) interface{} {
+	if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return v
+	}
+	if v, err := strconv.ParseFloat(value, 64); err == nil {
+		return v
+	}
+	return value
+}
+
+// path addresses a value within a message in the usual HL7 notation, e.g.
+// "PID-3.1" for the first component of the third field of the PID segment
+type path struct {
+	segment      string
+	field        int
+	component    int
+	subcomponent int
+}
+
+func parsePath(spec string) (path, error) {
+	seg, position, found := strings.Cut(spec, "-")
+	if !found || len(seg) != 3 {
+		return path{}, fmt.Errorf("invalid path %q, expected format 'SEG-field[.component[.subcomponent]]'", spec)
+	}
+
+	parts := strings.Split(position, ".")
+	if len(parts) > 3 {
+		return path{}, fmt.Errorf("invalid path %q, too many levels", spec)
+	}
+	indices := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 1 {
+			return path{}, fmt.Errorf("invalid position %q in path %q", part, spec)
+		}
+		indices[i] = n
+	}
+
+	return path{segment: seg, field: indices[0], component: indices[1], subcomponent: indices[2]}, nil
+}
+
+type encoding struct {
+	field        byte
+	component    byte
+	repetition   byte
+	escape       byte
+	subcomponent byte
+}
+
+type segment struct {
+	name   string
+	fields []string
+}
+
+type message struct {
+	encoding encoding
+	segments []*segment
+}
+
+// splitMessages splits the data into messages on MLLP framing or, if the data
+// is not framed, on MSH segments
+func splitMessages(buf []byte) [][]byte {
+	var messages [][]byte
+	if bytes.IndexByte(buf, mllpStart) >= 0 {
+		for _, frame := range bytes.Split(buf, []byte{mllpStart}) {
+			if idx := bytes.IndexByte(frame, mllpEnd); idx >= 0 {
+				frame = frame[:idx]
+			}
+			if len(bytes.TrimSpace(frame)) > 0 {
+				messages = append(messages, frame)
+			}
+		}
+		return messages
+	}
+
+	var current []byte
+	for _, seg := range splitSegments(buf) {
+		if bytes.HasPrefix(seg, []byte("MSH")) && len(current) > 0 {
+			messages = append(messages, current)
+			current = nil
+		}
+		current = append(current, seg...)
+		current = append(current, '\r')
+	}
+	if len(current) > 0 {
+		messages = append(messages, current)
+	}
+	return messages
+}
+
+// splitSegments splits the message into segments, accepting line-feeds in
+// addition to the carriage-returns required by the standard
+func splitSegments(buf []byte) [][]byte {
+	var segments [][]byte
+	for _, seg := range bytes.FieldsFunc(buf, func(r rune) bool { return r == '\r' || r == '\n' }) {
+		if len(bytes.TrimSpace(seg)) > 0 {
+			segments = append(segments, seg)
+		}
+	}
+	return segments
+}
+
+func parseMessage(buf []byte) (*message, error) {
+	segments := splitSegments(buf)
+	if len(segments) == 0 || !bytes.HasPrefix(segments[0], []byte("MSH")) {
+		return nil, errors.New("message does not start with a MSH segment")
+	}
+
+	// The MSH segment defines the separators used in the message
+	header := segments[0]
+	if len(header) < 8 {
+		return nil, errors.New("MSH segment too short")
+	}
+	enc := encoding{
+		field:        header[3],
+		component:    header[4],
+		repetition:   header[5],
+		escape:       header[6],
+		subcomponent: header[7],
+	}
+
+	msg := &message{encoding: enc, segments: make([]*segment, 0, len(segments))}
+	for _, raw := range segments {
+		fields := strings.Split(string(raw), string(enc.field))
+		name := fields[0]
+		if name == "MSH" {
+			// MSH-1 is the field separator itself so shift the fields to keep
+			// the standard numbering
+			fields = append([]string{name, string(enc.field)}, fields[1:]...)
+		}
+		msg.segments = append(msg.segments, &segment{name: name, fields: fields})
+	}
+
+	return msg, nil
+}
+
+// first returns the first segment with the given name or nil
+func (m *message) first(name string) *segment {
+	for _, seg := range m.segments {
+		if seg.name == name {
+			return seg
+		}
+	}
+	return nil
+}
+
+// value returns the unescaped value at the given path of the segment using
+// the first repetition of the field. Without component the whole field is
+// returned.
+func (m *message) value(seg *segment, pth path) string {
+	if seg == nil || pth.field >= len(seg.fields) {
+		return ""
+	}
+
+	raw := seg.fields[pth.field]
+	if seg.name == "MSH" && pth.field <= 2 {
+		// Field separator and encoding characters are not escaped
+		return raw
+	}
+	raw, _, _ = strings.Cut(raw, string(m.encoding.repetition))
+
+	if pth.component > 0 {
+		components := strings.Split(raw, string(m.encoding.component))
+		if pth.component > len(components) {
+			return ""
+		}
+		raw = components[pth.component-1]
+
+		if pth.subcomponent > 0 {
+			subcomponents := strings.Split(raw, string(m.encoding.subcomponent))
+			if pth.subcomponent > len(subcomponents) {
+				return ""
+			}
+			raw = subcomponents[pth.subcomponent-1]
+		}
+	}
+
+	return m.unescape(raw)
+}
+
+// unescape replaces the escape sequences for the separators, hexadecimal data
+// and line breaks. Formatting sequences are removed.
+func (m *message) unescape(s string) string {
+	esc := string(m.encoding.escape)
+	if !strings.Contains(s, esc) {
+		return s
+	}
+
+	var sb strings.Builder
+	for {
+		start := strings.Index(s, esc)
+		if start < 0 {
+			sb.WriteString(s)
+			break
+		}
+		end := strings.Index(s[start+1:], esc)
+		if end < 0 {
+			sb.WriteString(s)
+			break
+		}
+		sb.WriteString(s[:start])
+		sequence := s[start+1 : start+1+end]
+		s = s[start+end+2:]
+
+		switch {
+		case sequence == "F":
+			sb.WriteByte(m.encoding.field)
+		case sequence == "S":
+			sb.WriteByte(m.encoding.component)
+		case sequence == "T":
+			sb.WriteByte(m.encoding.subcomponent)
+		case sequence == "R":
+			sb.WriteByte(m.encoding.repetition)
+		case sequence == "E":
+			sb.WriteByte(m.encoding.escape)
+		case sequence == ".br":
+			sb.WriteByte('\n')
+		case strings.HasPrefix(sequence, "X"):
+			if decoded, err := hex.DecodeString(sequence[1:]); err == nil {
+				sb.Write(decoded)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// parseTime parses HL7 timestamps of the form
+// YYYY[MM[DD[HH[MM[SS[.S[S[S[S]]]]]]]]][+/-ZZZZ] using the given location if
+// no offset is given
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	if len(s) < 4 {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+	}
+
+	// Split the timezone offset
+	if idx := strings.IndexAny(s[4:], "+-"); idx >= 0 {
+		offset := s[4+idx:]
+		s = s[:4+idx]
+		if len(offset) != 5 {
+			return time.Time{}, fmt.Errorf("invalid timezone offset %q", offset)
+		}
+		hours, err := strconv.Atoi(offset[1:3])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid timezone offset %q", offset)
+		}
+		minutes, err := strconv.Atoi(offset[3:5])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid timezone offset %q", offset)
+		}
+		seconds := hours*3600 + minutes*60
+		if offset[0] == '-' {
+			seconds = -seconds
+		}
+		loc = time.FixedZone(offset, seconds)
+	}
+
+	// Split the fractional seconds
+	var nanos int
+	if whole, fraction, found := strings.Cut(s, "."); found {
+		if len(fraction) == 0 || len(fraction) > 9 {
+			return time.Time{}, fmt.Errorf("invalid fractional seconds %q", fraction)
+		}
+		n, err := strconv.Atoi(fraction)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid fractional seconds %q", fraction)
+		}
+		for i := len(fraction); i < 9; i++ {
+			n *= 10
+		}
+		nanos = n
+		s = whole
+	}
+
+	// Components not given default to their minimum
+	if len(s)%2 != 0 || len(s) > 14 {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+	}
+	parts := []int{0, 1, 1, 0, 0, 0}
+	for i, pos := 0, 0; pos < len(s); i++ {
+		width := 2
+		if i == 0 {
+			width = 4
+		}
+		n, err := strconv.Atoi(s[pos : pos+width])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+		}
+		parts[i] = n
+		pos += width
+	}
+
+	return time.Date(parts[0], time.Month(parts[1]), parts[2], parts[3], parts[4], parts[5], nanos, loc), nil
+}
+
+func init() {
+	parsers.Add("hl7v2",
+		func(defaultMetricName string) telegraf.Parser {
+			return &Parser{MetricName: defaultMetricName}
+		},
+	)
+}
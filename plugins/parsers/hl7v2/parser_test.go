@@ -0,0 +1,262 @@
+package hl7v2
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const oruMessage = "MSH|^~\\&|MONITOR|ICU|LIS|HOSP|20240115103000||ORU^R01|MSG00001|P|2.5\r" +
+	"PID|1||123456^^^HOSP^MR||Doe^John\r" +
+	"OBR|1|||VITALS|||20240115102955\r" +
+	"OBX|1|NM|8867-4^Heart rate^LN||72|/min^beats per minute|60-100|N|||F|||20240115102950||||MON-7\r" +
+	"OBX|2|NM|2708-6^Oxygen saturation^LN||94|%|95-100|L|||F\r" +
+	"OBX|3|ST|NOTE^Comment||\r"
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Parser
+		expected string
+	}{
+		{
+			name:     "invalid timezone",
+			plugin:   &Parser{Timezone: "Mars/Olympus"},
+			expected: "invalid timezone",
+		},
+		{
+			name:     "invalid tag path",
+			plugin:   &Parser{Tags: map[string]string{"patient": "PID3"}},
+			expected: `invalid path for tag "patient"`,
+		},
+		{
+			name:     "invalid field path",
+			plugin:   &Parser{Fields: map[string]string{"room": "PV1-a.1"}},
+			expected: `invalid path for field "room"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	expected := []telegraf.Metric{
+		metric.New(
+			"hl7v2",
+			map[string]string{
+				"message_type":        "ORU^R01",
+				"sending_application": "MONITOR",
+				"sending_facility":    "ICU",
+				"observation_id":      "8867-4",
+				"observation_text":    "Heart rate",
+				"coding_system":       "LN",
+				"units":               "/min",
+				"abnormal_flag":       "N",
+				"status":              "F",
+				"equipment":           "MON-7",
+				"patient_id":          "123456",
+			},
+			map[string]interface{}{
+				"value":           72.0,
+				"reference_range": "60-100",
+				"control_id":      "MSG00001",
+				"last_name":       "Doe",
+			},
+			time.Date(2024, 1, 15, 10, 29, 50, 0, time.UTC),
+		),
+		metric.New(
+			"hl7v2",
+			map[string]string{
+				"message_type":        "ORU^R01",
+				"sending_application": "MONITOR",
+				"sending_facility":    "ICU",
+				"observation_id":      "2708-6",
+				"observation_text":    "Oxygen saturation",
+				"coding_system":       "LN",
+				"units":               "%",
+				"abnormal_flag":       "L",
+				"status":              "F",
+				"patient_id":          "123456",
+			},
+			map[string]interface{}{
+				"value":           94.0,
+				"reference_range": "95-100",
+				"control_id":      "MSG00001",
+				"last_name":       "Doe",
+			},
+			time.Date(2024, 1, 15, 10, 29, 55, 0, time.UTC),
+		),
+	}
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "plain",
+			input: oruMessage,
+		},
+		{
+			name:  "line-feed separated",
+			input: strings.ReplaceAll(oruMessage, "\r", "\n"),
+		},
+		{
+			name:  "mllp framed",
+			input: "\x0b" + oruMessage + "\x1c\r",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &Parser{
+				MetricName: "hl7v2",
+				Tags:       map[string]string{"patient_id": "PID-3.1"},
+				Fields:     map[string]string{"last_name": "PID-5.1"},
+			}
+			require.NoError(t, parser.Init())
+
+			actual, err := parser.Parse([]byte(tt.input))
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, expected, actual)
+		})
+	}
+}
+
+func TestParseMultipleMessages(t *testing.T) {
+	input := "\x0bMSH|^~\\&|A|F1|||20240115103000||ORU^R01|1|P|2.5\rOBX|1|NM|HR||70\r\x1c\r" +
+		"\x0bMSH|^~\\&|B|F2|||20240115103100||ORU^R01|2|P|2.5\rOBX|1|NM|HR||80\r\x1c\r"
+
+	parser := &Parser{MetricName: "hl7v2"}
+	require.NoError(t, parser.Init())
+
+	actual, err := parser.Parse([]byte(input))
+	require.NoError(t, err)
+	require.Len(t, actual, 2)
+	require.Equal(t, "A", actual[0].Tags()["sending_application"])
+	require.Equal(t, "B", actual[1].Tags()["sending_application"])
+	require.Equal(t, time.Date(2024, 1, 15, 10, 31, 0, 0, time.UTC), actual[1].Time())
+
+	// Unframed messages are split on the header segment
+	actual, err = parser.Parse([]byte(strings.NewReplacer("\x0b", "", "\x1c", "").Replace(input)))
+	require.NoError(t, err)
+	require.Len(t, actual, 2)
+}
+
+func TestValueTypes(t *testing.T) {
+	input := "MSH|^~\\&|MON|ICU|||20240115103000||ORU^R01|1|P|2.5\r" +
+		"OBX|1|SN|BP||^120|mm[Hg]\r" +
+		"OBX|2|SN|GLU||>^200|mg/dL\r" +
+		"OBX|3|CWE|RHYTHM||SR^Sinus rhythm^LN\r" +
+		"OBX|4|ST|NOTE||Line 1\\.br\\Pipe \\F\\ and caret \\S\\ \\X41\\\r" +
+		"OBX|5|NM|TEMP||37.5~38.0|Cel\r"
+
+	parser := &Parser{MetricName: "hl7v2"}
+	require.NoError(t, parser.Init())
+
+	actual, err := parser.Parse([]byte(input))
+	require.NoError(t, err)
+	require.Len(t, actual, 5)
+
+	values := make([]interface{}, 0, len(actual))
+	for _, m := range actual {
+		v, found := m.GetField("value")
+		require.True(t, found)
+		values = append(values, v)
+	}
+	require.Equal(t, []interface{}{120.0, ">^200", "SR", "Line 1\nPipe | and caret ^ A", 37.5}, values)
+}
+
+func TestParseInvalid(t *testing.T) {
+	parser := &Parser{MetricName: "hl7v2"}
+	require.NoError(t, parser.Init())
+
+	_, err := parser.Parse([]byte("PID|1||123456\rOBX|1|NM|HR||70\r"))
+	require.ErrorContains(t, err, "message does not start with a MSH segment")
+}
+
+func TestParseLine(t *testing.T) {
+	parser := &Parser{MetricName: "hl7v2"}
+	require.NoError(t, parser.Init())
+
+	m, err := parser.ParseLine("MSH|^~\\&|MON|ICU|||20240115103000||ORU^R01|1|P|2.5\rOBX|1|NM|HR||70\r")
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t, map[string]interface{}{"value": 70.0, "control_id": "1"}, m.Fields())
+
+	_, err = parser.ParseLine(oruMessage)
+	require.ErrorContains(t, err, "cannot parse line with multiple (2) metrics")
+}
+
+func TestParseTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	tests := []struct {
+		input    string
+		location *time.Location
+		expected time.Time
+	}{
+		{
+			input:    "2024",
+			location: time.UTC,
+			expected: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			input:    "202401151030",
+			location: berlin,
+			expected: time.Date(2024, 1, 15, 10, 30, 0, 0, berlin),
+		},
+		{
+			input:    "20240115103000.25",
+			location: time.UTC,
+			expected: time.Date(2024, 1, 15, 10, 30, 0, 250000000, time.UTC),
+		},
+		{
+			input:    "20240115103000-0500",
+			location: time.UTC,
+			expected: time.Date(2024, 1, 15, 15, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			actual, err := parseTime(tt.input, tt.location)
+			require.NoError(t, err)
+			require.True(t, tt.expected.Equal(actual), "expected %v but got %v", tt.expected, actual)
+		})
+	}
+
+	for _, input := range []string{"", "24", "2024011", "20240115103000+01", "2024011510300a"} {
+		_, err := parseTime(input, time.UTC)
+		require.Error(t, err, input)
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	parser := &Parser{MetricName: "hl7v2", Timezone: "America/New_York"}
+	require.NoError(t, parser.Init())
+
+	m, err := parser.ParseLine("MSH|^~\\&|MON|ICU|||20240115103000||ORU^R01|1|P|2.5\rOBX|1|NM|HR||70\r")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 1, 15, 15, 30, 0, 0, time.UTC), m.Time().UTC())
+}
+
+func TestDefaultTags(t *testing.T) {
+	parser := &Parser{MetricName: "hl7v2"}
+	require.NoError(t, parser.Init())
+	parser.SetDefaultTags(map[string]string{"ward": "icu"})
+
+	m, err := parser.ParseLine("MSH|^~\\&|MON|ICU|||20240115103000||ORU^R01|1|P|2.5\rOBX|1|NM|HR||70\r")
+	require.NoError(t, err)
+	require.Equal(t, "icu", m.Tags()["ward"])
+}
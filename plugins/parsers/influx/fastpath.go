@@ -0,0 +1,226 @@
+package influx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+const (
+	swarOnes  = 0x0101010101010101
+	swarHighs = 0x8080808080808080
+
+	maxInternedStrings = 4096
+)
+
+// needsMachine checks if the line contains characters requiring the full
+// state machine, i.e. control characters like tabs or carriage returns,
+// backslashes for escaping or quotes for string fields. Eight bytes are
+// checked at once using SWAR (SIMD within a register) arithmetic.
+func needsMachine(line []byte) bool {
+	for len(line) >= 8 {
+		x := binary.LittleEndian.Uint64(line)
+		if hasLess(x, ' ') || hasByte(x, '\\') || hasByte(x, '"') {
+			return true
+		}
+		line = line[8:]
+	}
+	for _, c := range line {
+		if c < ' ' || c == '\\' || c == '"' {
+			return true
+		}
+	}
+	return false
+}
+
+// hasLess reports if any byte of x is less than n with n <= 128
+func hasLess(x uint64, n byte) bool {
+	return (x-swarOnes*uint64(n))&^x&swarHighs != 0
+}
+
+// hasByte reports if any byte of x equals b
+func hasByte(x uint64, b byte) bool {
+	return hasLess(x^(swarOnes*uint64(b)), 1)
+}
+
+// parseLineFast parses a single line without the trailing newline using
+// vectorized byte searches instead of the per-byte state machine. Only lines
+// without escapes, string fields, comments and surplus whitespace are handled.
+// For all other lines, including invalid ones, nil is returned and the line
+// must be parsed by the state machine to get the exact result or error.
+func (p *Parser) parseLineFast(line []byte) telegraf.Metric {
+	if line[0] == '#' || line[0] == ' ' || needsMachine(line) {
+		return nil
+	}
+
+	// Split the line into the series, the fields and the optional timestamp
+	idx := bytes.IndexByte(line, ' ')
+	if idx <= 0 {
+		return nil
+	}
+	series, fields := line[:idx], line[idx+1:]
+	var timestamp []byte
+	if idx := bytes.IndexByte(fields, ' '); idx >= 0 {
+		fields, timestamp = fields[:idx], fields[idx+1:]
+		if !isTimestamp(timestamp) {
+			return nil
+		}
+	}
+
+	// Measurement and tags
+	name, tags, hasTags := cutByte(series, ',')
+	if len(name) == 0 {
+		return nil
+	}
+	m := metric.New(p.intern(name), nil, nil, time.Time{})
+	for hasTags {
+		var tag []byte
+		tag, tags, hasTags = cutByte(tags, ',')
+		key, value, found := cutByte(tag, '=')
+		if !found || len(key) == 0 || len(value) == 0 || bytes.IndexByte(value, '=') >= 0 {
+			return nil
+		}
+		m.AddTag(p.intern(key), p.intern(value))
+	}
+
+	// Fields
+	for hasFields := true; hasFields; {
+		var field []byte
+		field, fields, hasFields = cutByte(fields, ',')
+		key, value, found := cutByte(field, '=')
+		if !found || len(key) == 0 || len(value) == 0 {
+			return nil
+		}
+		v, ok := parseValueFast(value)
+		if !ok {
+			return nil
+		}
+		m.AddField(p.intern(key), v)
+	}
+
+	// Timestamp using the precision as unit, as for the state machine
+	if timestamp != nil {
+		ts, err := parseIntBytes(timestamp, 10, 64)
+		if err != nil {
+			return nil
+		}
+		m.SetTime(time.Unix(0, ts*int64(p.handler.timePrecision)))
+	} else {
+		m.SetTime(p.handler.timeFunc().Truncate(p.handler.timePrecision))
+	}
+	return m
+}
+
+// parseValueFast converts the field value according to its type and returns
+// false if the value is invalid
+func parseValueFast(value []byte) (interface{}, bool) {
+	switch value[len(value)-1] {
+	case 'i':
+		if !isInteger(value[:len(value)-1], true) {
+			return nil, false
+		}
+		v, err := parseIntBytes(value[:len(value)-1], 10, 64)
+		return v, err == nil
+	case 'u':
+		if !isInteger(value[:len(value)-1], false) {
+			return nil, false
+		}
+		v, err := parseUintBytes(value[:len(value)-1], 10, 64)
+		return v, err == nil
+	}
+
+	switch string(value) {
+	case "t", "T", "true", "True", "TRUE":
+		return true, true
+	case "f", "F", "false", "False", "FALSE":
+		return false, true
+	}
+
+	if !isFloat(value) {
+		return nil, false
+	}
+	v, err := parseFloatBytes(value, 64)
+	return v, err == nil
+}
+
+// intern returns a string for the given bytes, reusing the strings of
+// previously seen keys and values to avoid allocations for recurring ones
+func (p *Parser) intern(b []byte) string {
+	if s, found := p.interned[string(b)]; found {
+		return s
+	}
+
+	s := string(b)
+	if p.interned == nil || len(p.interned) >= maxInternedStrings {
+		p.interned = make(map[string]string, maxInternedStrings)
+	}
+	p.interned[s] = s
+	return s
+}
+
+// isInteger checks if the value is a decimal integer without leading zeros
+func isInteger(value []byte, signed bool) bool {
+	if signed && len(value) > 0 && value[0] == '-' {
+		value = value[1:]
+	}
+	if len(value) == 0 || (value[0] == '0' && len(value) > 1) {
+		return false
+	}
+	return isDigits(value)
+}
+
+// isFloat checks if the value is a decimal number with an optional fraction
+// and exponent
+func isFloat(value []byte) bool {
+	if value[0] == '-' {
+		value = value[1:]
+	}
+	mantissa, exponent, scientific := cutByte(value, 'e')
+	if !scientific {
+		mantissa, exponent, scientific = cutByte(value, 'E')
+	}
+
+	integer, fraction, found := cutByte(mantissa, '.')
+	if !isDigits(integer) || !isDigits(fraction) {
+		return false
+	}
+	if len(integer) == 0 && (!found || len(fraction) == 0) {
+		return false
+	}
+
+	if scientific {
+		if len(exponent) > 0 && (exponent[0] == '+' || exponent[0] == '-') {
+			exponent = exponent[1:]
+		}
+		return len(exponent) > 0 && isDigits(exponent)
+	}
+	return true
+}
+
+// isTimestamp checks if the value is an integer with at most 19 digits
+func isTimestamp(value []byte) bool {
+	if len(value) > 0 && value[0] == '-' {
+		value = value[1:]
+	}
+	return len(value) > 0 && len(value) <= 19 && isDigits(value)
+}
+
+// cutByte is a faster version of bytes.Cut for a single separator byte
+func cutByte(s []byte, sep byte) (before, after []byte, found bool) {
+	if i := bytes.IndexByte(s, sep); i >= 0 {
+		return s[:i], s[i+1:], true
+	}
+	return s, nil, false
+}
+
+func isDigits(value []byte) bool {
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
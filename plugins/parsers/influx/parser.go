@@ -1,6 +1,7 @@
 package influx
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -68,7 +69,9 @@ type Parser struct {
 
 	sync.Mutex
 	*machine
-	handler *MetricHandler
+	handler  *MetricHandler
+	fastPath bool
+	interned map[string]string
 }
 
 func (p *Parser) SetTimeFunc(f TimeFunc) {
@@ -83,9 +86,32 @@ func (p *Parser) Parse(input []byte) ([]telegraf.Metric, error) {
 	p.Lock()
 	defer p.Unlock()
 	metrics := make([]telegraf.Metric, 0)
-	p.machine.SetData(input)
 
-	for {
+	var offset, lineno int
+	for offset < len(input) {
+		// Try to parse the next line using the fast path and only use the
+		// state machine for lines not supported by the fast path
+		if p.fastPath {
+			line := input[offset:]
+			next := len(input)
+			if idx := bytes.IndexByte(line, '\n'); idx >= 0 {
+				line = line[:idx]
+				next = offset + idx + 1
+			}
+			if len(line) == 0 {
+				offset = next
+				lineno++
+				continue
+			}
+			if metric := p.parseLineFast(line); metric != nil {
+				metrics = append(metrics, metric)
+				offset = next
+				lineno++
+				continue
+			}
+		}
+
+		p.machine.SetData(input[offset:])
 		err := p.machine.Next()
 		if errors.Is(err, EOF) {
 			break
@@ -93,14 +119,16 @@ func (p *Parser) Parse(input []byte) ([]telegraf.Metric, error) {
 
 		if err != nil {
 			return nil, &ParseError{
-				Offset:     p.machine.Position(),
-				LineOffset: p.machine.LineOffset(),
-				LineNumber: p.machine.LineNumber(),
+				Offset:     offset + p.machine.Position(),
+				LineOffset: offset + p.machine.LineOffset(),
+				LineNumber: lineno + p.machine.LineNumber(),
 				Column:     p.machine.Column(),
 				msg:        err.Error(),
 				buf:        string(input),
 			}
 		}
+		offset += p.machine.Position()
+		lineno += p.machine.LineNumber() - 1
 
 		metric := p.handler.Metric()
 		if metric == nil {
@@ -155,6 +183,7 @@ func (p *Parser) Init() error {
 		p.machine = NewSeriesMachine(p.handler)
 	} else {
 		p.machine = NewMachine(p.handler)
+		p.fastPath = true
 	}
 
 	timeDuration := time.Duration(p.InfluxTimestampPrecision)
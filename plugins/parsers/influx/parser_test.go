@@ -710,6 +710,65 @@ func TestParserInvalidTimestampPrecision(t *testing.T) {
 	}
 }
 
+func TestParserFastPath(t *testing.T) {
+	inputs := [][]byte{
+		[]byte("cpu,host=a,cpu=cpu0 usage=1.5,count=2i,total=3u,ok=true 1700000000000000000"),
+		[]byte("cpu value=1\n\nmem value=2\n# comment\ndisk value=3\n"),
+		[]byte("cpu value=1\r\nmem value=2\r\n"),
+		[]byte("cpu value=\"a\nb\" 1\nmem value=2 2\nswap value=3"),
+		[]byte("cpu=x,a=b value=-1.5e-3,b=.5,c=1.,d=1E5,e=-0i 0"),
+		[]byte("cpu value=01i"),
+		[]byte("cpu value=1e"),
+		[]byte("cpu value=."),
+		[]byte("cpu value=-inf"),
+		[]byte("cpu value=0x10"),
+		[]byte("cpu value=1_000"),
+		[]byte("cpu value=1e400"),
+		[]byte("cpu value=9223372036854775808i"),
+		[]byte("cpu value=-1u"),
+		[]byte("cpu value=1 12345678901234567890"),
+		[]byte("cpu value=1 -1"),
+		[]byte("cpu value=1  1"),
+		[]byte("cpu value=1 1 "),
+		[]byte("cpu,a=b=c value=1"),
+		[]byte("cpu,a= value=1"),
+		[]byte("cpu,=b value=1"),
+		[]byte("cpu, value=1"),
+		[]byte("cpu value=1,"),
+		[]byte("cpu value=1,value=2"),
+		[]byte("cpu =1"),
+		[]byte("cpu\tvalue=1"),
+		[]byte("  cpu value=1"),
+		[]byte("#cpu value=1"),
+		[]byte("cpu"),
+		[]byte("cpu value=1\nmem\ndisk value=3"),
+		[]byte("cpu value=1\nmem value=\"\ninvalid"),
+		[]byte("c\\ pu,t\\,ag=v value=1"),
+		[]byte("cpu,host=ä value=1"),
+	}
+	for _, tt := range ptests {
+		inputs = append(inputs, tt.input)
+	}
+
+	for _, input := range inputs {
+		t.Run(string(input), func(t *testing.T) {
+			machine := Parser{}
+			require.NoError(t, machine.Init())
+			machine.SetTimeFunc(DefaultTime)
+			machine.fastPath = false
+			expected, expectedErr := machine.Parse(input)
+
+			parser := Parser{}
+			require.NoError(t, parser.Init())
+			parser.SetTimeFunc(DefaultTime)
+			actual, err := parser.Parse(input)
+
+			require.Equal(t, expectedErr, err)
+			testutil.RequireMetricsEqual(t, expected, actual)
+		})
+	}
+}
+
 func BenchmarkParser(b *testing.B) {
 	for _, tt := range ptests {
 		b.Run(tt.name, func(b *testing.B) {
@@ -1038,3 +1097,14 @@ func BenchmarkParsing(b *testing.B) {
 		plugin.Parse([]byte(benchmarkData))
 	}
 }
+
+func BenchmarkParsingMachine(b *testing.B) {
+	plugin := &Parser{}
+	require.NoError(b, plugin.Init())
+	plugin.fastPath = false
+
+	for n := 0; n < b.N; n++ {
+		//nolint:errcheck // Benchmarking so skip the error check to avoid the unnecessary operations
+		plugin.Parse([]byte(benchmarkData))
+	}
+}
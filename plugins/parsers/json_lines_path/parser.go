@@ -0,0 +1,272 @@
+package json_lines_path
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+// Use the full language to support comparisons in filter expressions
+var language = gval.Full(jsonpath.Language())
+
+type Parser struct {
+	MeasurementPath string            `toml:"json_lines_path_measurement"`
+	TimestampPath   string            `toml:"json_lines_path_timestamp"`
+	TimestampFormat string            `toml:"json_lines_path_timestamp_format"`
+	Timezone        string            `toml:"json_lines_path_timezone"`
+	Tags            map[string]string `toml:"json_lines_path_tags"`
+	Fields          map[string]string `toml:"json_lines_path_fields"`
+	Strict          bool              `toml:"json_lines_path_strict"`
+	MetricName      string            `toml:"-"`
+	DefaultTags     map[string]string `toml:"-"`
+
+	location    *time.Location
+	measurement gval.Evaluable
+	timestamp   gval.Evaluable
+	tags        map[string]gval.Evaluable
+	fields      map[string]gval.Evaluable
+}
+
+func (p *Parser) Init() error {
+	if len(p.Fields) == 0 {
+		return errors.New("no fields configured")
+	}
+
+	if p.TimestampPath != "" && p.TimestampFormat == "" {
+		return errors.New("use of 'json_lines_path_timestamp' requires 'json_lines_path_timestamp_format'")
+	}
+
+	p.location = time.UTC
+	if p.Timezone != "" {
+		loc, err := time.LoadLocation(p.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone: %w", err)
+		}
+		p.location = loc
+	}
+
+	var err error
+	if p.MeasurementPath != "" {
+		if p.measurement, err = language.NewEvaluable(p.MeasurementPath); err != nil {
+			return fmt.Errorf("invalid measurement path %q: %w", p.MeasurementPath, err)
+		}
+	}
+	if p.TimestampPath != "" {
+		if p.timestamp, err = language.NewEvaluable(p.TimestampPath); err != nil {
+			return fmt.Errorf("invalid timestamp path %q: %w", p.TimestampPath, err)
+		}
+	}
+
+	p.tags = make(map[string]gval.Evaluable, len(p.Tags))
+	for name, path := range p.Tags {
+		eval, err := language.NewEvaluable(path)
+		if err != nil {
+			return fmt.Errorf("invalid path %q for tag %q: %w", path, name, err)
+		}
+		p.tags[name] = eval
+	}
+
+	p.fields = make(map[string]gval.Evaluable, len(p.Fields))
+	for name, path := range p.Fields {
+		eval, err := language.NewEvaluable(path)
+		if err != nil {
+			return fmt.Errorf("invalid path %q for field %q: %w", path, name, err)
+		}
+		p.fields[name] = eval
+	}
+
+	return nil
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	var metrics []telegraf.Metric
+
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(buf)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		m, err := p.parseDocument(line)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	line = string(bytes.TrimSpace([]byte(line)))
+	if line == "" {
+		return nil, nil
+	}
+	return p.parseDocument([]byte(line))
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+// parseDocument creates a metric from a single JSON document. Documents
+// without any matching field are skipped.
+func (p *Parser) parseDocument(line []byte) (telegraf.Metric, error) {
+	var doc interface{}
+	if err := json.Unmarshal(line, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON document: %w", err)
+	}
+
+	name := p.MetricName
+	if p.measurement != nil {
+		v, found, err := p.lookup(p.measurement, p.MeasurementPath, doc)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			s, err := scalarString(v)
+			if err != nil {
+				return nil, fmt.Errorf("measurement path %q: %w", p.MeasurementPath, err)
+			}
+			if s != "" {
+				name = s
+			}
+		}
+	}
+
+	timestamp := time.Now()
+	if p.timestamp != nil {
+		v, found, err := p.lookup(p.timestamp, p.TimestampPath, doc)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			if _, multiple := v.([]interface{}); multiple {
+				return nil, fmt.Errorf("timestamp path %q matches multiple values", p.TimestampPath)
+			}
+			if timestamp, err = internal.ParseTimestamp(p.TimestampFormat, v, p.location); err != nil {
+				return nil, fmt.Errorf("unable to parse timestamp %v: %w", v, err)
+			}
+		}
+	}
+
+	tags := make(map[string]string, len(p.DefaultTags)+len(p.tags))
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	for key, eval := range p.tags {
+		v, found, err := p.lookup(eval, p.Tags[key], doc)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		s, err := scalarString(v)
+		if err != nil {
+			return nil, fmt.Errorf("tag %q: %w", key, err)
+		}
+		tags[key] = s
+	}
+
+	fields := make(map[string]interface{}, len(p.fields))
+	for key, eval := range p.fields {
+		v, found, err := p.lookup(eval, p.Fields[key], doc)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+
+		// Expressions matching multiple values, e.g. using wildcards, result
+		// in one field per value suffixed by the index of the match
+		if values, multiple := v.([]interface{}); multiple {
+			for i, x := range values {
+				if err := addField(fields, key+"_"+strconv.Itoa(i), x); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if err := addField(fields, key, v); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	return metric.New(name, tags, fields, timestamp), nil
+}
+
+// lookup evaluates the expression on the document. Missing values are
+// reported as not found unless running in strict mode.
+func (p *Parser) lookup(eval gval.Evaluable, path string, doc interface{}) (interface{}, bool, error) {
+	v, err := eval(context.Background(), doc)
+	if err == nil {
+		if values, ok := v.([]interface{}); ok && len(values) == 0 {
+			err = errors.New("no match")
+		} else if v != nil {
+			return v, true, nil
+		} else {
+			err = errors.New("null value")
+		}
+	}
+
+	if p.Strict {
+		return nil, false, fmt.Errorf("evaluating path %q failed: %w", path, err)
+	}
+	return nil, false, nil
+}
+
+func addField(fields map[string]interface{}, key string, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+	case float64, string, bool:
+		fields[key] = v
+	default:
+		return fmt.Errorf("field %q: value of type %T is not a scalar", key, value)
+	}
+	return nil
+}
+
+func scalarString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return "", fmt.Errorf("value of type %T is not a scalar", value)
+}
+
+func init() {
+	parsers.Add("json_lines_path",
+		func(defaultMetricName string) telegraf.Parser {
+			return &Parser{MetricName: defaultMetricName}
+		},
+	)
+}
@@ -0,0 +1,216 @@
+package json_lines_path
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const sensorLines = `
+{"type": "climate", "ts": 1705314600, "device": {"id": "dev-1", "site": {"name": "berlin"}}, "readings": [{"kind": "temperature", "value": 21.5}, {"kind": "humidity", "value": 40}], "online": true}
+{"type": "climate", "ts": 1705314660, "device": {"id": "dev-2"}, "readings": [{"kind": "temperature", "value": 19}], "online": false}
+
+{"type": "power", "ts": 1705314720, "device": {"id": "dev-3"}, "readings": []}
+`
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Parser
+		expected string
+	}{
+		{
+			name:     "no fields",
+			plugin:   &Parser{},
+			expected: "no fields configured",
+		},
+		{
+			name: "timestamp without format",
+			plugin: &Parser{
+				TimestampPath: "$.ts",
+				Fields:        map[string]string{"value": "$.value"},
+			},
+			expected: "use of 'json_lines_path_timestamp' requires 'json_lines_path_timestamp_format'",
+		},
+		{
+			name: "invalid timezone",
+			plugin: &Parser{
+				Timezone: "Mars/Olympus",
+				Fields:   map[string]string{"value": "$.value"},
+			},
+			expected: "invalid timezone",
+		},
+		{
+			name: "invalid field path",
+			plugin: &Parser{
+				Fields: map[string]string{"value": "$.value["},
+			},
+			expected: `invalid path "$.value[" for field "value"`,
+		},
+		{
+			name: "invalid tag path",
+			plugin: &Parser{
+				Tags:   map[string]string{"host": "$.[[host"},
+				Fields: map[string]string{"value": "$.value"},
+			},
+			expected: `invalid path "$.[[host" for tag "host"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	parser := &Parser{
+		MetricName:      "json_lines_path",
+		MeasurementPath: "$.type",
+		TimestampPath:   "$.ts",
+		TimestampFormat: "unix",
+		Tags: map[string]string{
+			"device": "$.device.id",
+			"site":   "$.device.site.name",
+		},
+		Fields: map[string]string{
+			"temperature": `$.readings[?(@.kind == "temperature")].value`,
+			"online":      "$.online",
+		},
+	}
+	require.NoError(t, parser.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"climate",
+			map[string]string{"device": "dev-1", "site": "berlin"},
+			map[string]interface{}{"temperature_0": 21.5, "online": true},
+			time.Unix(1705314600, 0),
+		),
+		metric.New(
+			"climate",
+			map[string]string{"device": "dev-2"},
+			map[string]interface{}{"temperature_0": 19.0, "online": false},
+			time.Unix(1705314660, 0),
+		),
+	}
+
+	actual, err := parser.Parse([]byte(sensorLines))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestParseWildcard(t *testing.T) {
+	parser := &Parser{
+		MetricName: "json_lines_path",
+		Fields:     map[string]string{"value": "$.readings[*].value"},
+	}
+	require.NoError(t, parser.Init())
+
+	m, err := parser.ParseLine(`{"readings": [{"value": 1}, {"value": 2.5}, {"value": "n/a"}]}`)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value_0": 1.0, "value_1": 2.5, "value_2": "n/a"}, m.Fields())
+}
+
+func TestParseStrict(t *testing.T) {
+	parser := &Parser{
+		MetricName: "json_lines_path",
+		Tags:       map[string]string{"site": "$.device.site.name"},
+		Fields:     map[string]string{"value": "$.value"},
+		Strict:     true,
+	}
+	require.NoError(t, parser.Init())
+
+	_, err := parser.ParseLine(`{"value": 1, "device": {"id": "dev-1"}}`)
+	require.ErrorContains(t, err, `evaluating path "$.device.site.name" failed`)
+
+	m, err := parser.ParseLine(`{"value": 1, "device": {"site": {"name": "berlin"}}}`)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"site": "berlin"}, m.Tags())
+}
+
+func TestParseTimestampFormat(t *testing.T) {
+	parser := &Parser{
+		MetricName:      "json_lines_path",
+		TimestampPath:   "$.meta.time",
+		TimestampFormat: "2006-01-02 15:04:05",
+		Timezone:        "Europe/Berlin",
+		Fields:          map[string]string{"value": "$.value"},
+	}
+	require.NoError(t, parser.Init())
+
+	m, err := parser.ParseLine(`{"meta": {"time": "2024-01-15 11:30:00"}, "value": 1}`)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), m.Time().UTC())
+
+	_, err = parser.ParseLine(`{"meta": {"time": "yesterday"}, "value": 1}`)
+	require.ErrorContains(t, err, "unable to parse timestamp")
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   map[string]string
+		tags     map[string]string
+		input    string
+		expected string
+	}{
+		{
+			name:     "invalid json",
+			fields:   map[string]string{"value": "$.value"},
+			input:    `{"value": 1`,
+			expected: "invalid JSON document",
+		},
+		{
+			name:     "object field",
+			fields:   map[string]string{"value": "$.device"},
+			input:    `{"device": {"id": "dev-1"}}`,
+			expected: `field "value": value of type map[string]interface {} is not a scalar`,
+		},
+		{
+			name:     "array tag",
+			fields:   map[string]string{"value": "$.value"},
+			tags:     map[string]string{"ids": "$.devices[*]"},
+			input:    `{"value": 1, "devices": ["a", "b"]}`,
+			expected: `tag "ids": value of type []interface {} is not a scalar`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &Parser{
+				MetricName: "json_lines_path",
+				Tags:       tt.tags,
+				Fields:     tt.fields,
+			}
+			require.NoError(t, parser.Init())
+
+			_, err := parser.Parse([]byte(tt.input))
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestParseLineDefaultTags(t *testing.T) {
+	parser := &Parser{
+		MetricName: "json_lines_path",
+		Tags:       map[string]string{"device": "$.device"},
+		Fields:     map[string]string{"value": "$.value"},
+	}
+	require.NoError(t, parser.Init())
+	parser.SetDefaultTags(map[string]string{"source": "test", "device": "unknown"})
+
+	m, err := parser.ParseLine(`{"device": 42, "value": 1}`)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"source": "test", "device": "42"}, m.Tags())
+
+	m, err = parser.ParseLine(`{"other": 1}`)
+	require.NoError(t, err)
+	require.Nil(t, m)
+}
@@ -5,12 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-logfmt/logfmt"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/parsers"
 )
@@ -19,58 +22,162 @@ var ErrNoMetric = errors.New("no metric in line")
 
 // Parser decodes logfmt formatted messages into metrics.
 type Parser struct {
-	TagKeys     []string          `toml:"logfmt_tag_keys"`
-	DefaultTags map[string]string `toml:"-"`
+	TagKeys         []string          `toml:"logfmt_tag_keys"`
+	DuplicateKeys   string            `toml:"logfmt_duplicate_keys"`
+	FieldTypes      map[string]string `toml:"logfmt_field_types"`
+	TimestampKey    string            `toml:"logfmt_timestamp_key"`
+	TimestampFormat string            `toml:"logfmt_timestamp_format"`
+	Timezone        string            `toml:"logfmt_timezone"`
+	Lenient         bool              `toml:"logfmt_lenient"`
+	DefaultTags     map[string]string `toml:"-"`
 
 	metricName string
 	tagFilter  filter.Filter
+	location   *time.Location
+}
+
+// keyval is a single key-value pair of a logfmt record
+type keyval struct {
+	key   string
+	value string
 }
 
 // Parse converts a slice of bytes in logfmt format to metrics.
 func (p *Parser) Parse(b []byte) ([]telegraf.Metric, error) {
-	reader := bytes.NewReader(b)
-	decoder := logfmt.NewDecoder(reader)
-	metrics := make([]telegraf.Metric, 0)
-	for {
-		ok := decoder.ScanRecord()
-		if !ok {
-			err := decoder.Err()
+	var records [][]keyval
+	var err error
+	if p.Lenient {
+		records = scanLenient(b)
+	} else {
+		records, err = scanStrict(b)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	metrics := make([]telegraf.Metric, 0, len(records))
+	for _, record := range records {
+		m, err := p.createMetric(record)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	p.applyDefaultTags(metrics)
+	return metrics, nil
+}
+
+func (p *Parser) createMetric(record []keyval) (telegraf.Metric, error) {
+	timestamp := time.Now()
+	fields := make(map[string]interface{})
+	tags := make(map[string]string)
+	for _, kv := range p.resolveDuplicates(record) {
+		if p.TimestampKey != "" && kv.key == p.TimestampKey {
+			ts, err := internal.ParseTimestamp(p.TimestampFormat, kv.value, p.location)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("parsing timestamp %q failed: %w", kv.value, err)
 			}
-			break
+			timestamp = ts
+			continue
 		}
-		fields := make(map[string]interface{})
-		tags := make(map[string]string)
-		for decoder.ScanKeyval() {
-			if len(decoder.Value()) == 0 {
-				continue
-			}
 
-			// type conversions
-			value := string(decoder.Value())
-			if p.tagFilter != nil && p.tagFilter.Match(string(decoder.Key())) {
-				tags[string(decoder.Key())] = value
-			} else if iValue, err := strconv.ParseInt(value, 10, 64); err == nil {
-				fields[string(decoder.Key())] = iValue
-			} else if fValue, err := strconv.ParseFloat(value, 64); err == nil {
-				fields[string(decoder.Key())] = fValue
-			} else if bValue, err := strconv.ParseBool(value); err == nil {
-				fields[string(decoder.Key())] = bValue
-			} else {
-				fields[string(decoder.Key())] = value
-			}
+		if p.tagFilter != nil && p.tagFilter.Match(kv.key) {
+			tags[kv.key] = kv.value
+			continue
+		}
+
+		value, err := p.convert(kv)
+		if err != nil {
+			return nil, err
 		}
-		if len(fields) == 0 && len(tags) == 0 {
+		fields[kv.key] = value
+	}
+	if len(fields) == 0 && len(tags) == 0 {
+		return nil, nil
+	}
+
+	return metric.New(p.metricName, tags, fields, timestamp), nil
+}
+
+// resolveDuplicates applies the duplicate-key policy to the record, dropping
+// empty values on the way.
+func (p *Parser) resolveDuplicates(record []keyval) []keyval {
+	result := make([]keyval, 0, len(record))
+	index := make(map[string]int, len(record))
+	count := make(map[string]int, len(record))
+	for _, kv := range record {
+		if kv.value == "" {
 			continue
 		}
+		count[kv.key]++
 
-		m := metric.New(p.metricName, tags, fields, time.Now())
+		idx, found := index[kv.key]
+		if !found {
+			index[kv.key] = len(result)
+			result = append(result, kv)
+			continue
+		}
 
-		metrics = append(metrics, m)
+		switch p.DuplicateKeys {
+		case "first":
+		case "array":
+			result = append(result, kv)
+		default:
+			result[idx].value = kv.value
+		}
 	}
-	p.applyDefaultTags(metrics)
-	return metrics, nil
+
+	if p.DuplicateKeys != "array" {
+		return result
+	}
+
+	// Suffix the keys occurring multiple times with their occurrence index
+	seen := make(map[string]int, len(count))
+	for i, kv := range result {
+		if count[kv.key] < 2 {
+			continue
+		}
+		result[i].key = kv.key + "_" + strconv.Itoa(seen[kv.key])
+		seen[kv.key]++
+	}
+	return result
+}
+
+// convert returns the value either using the configured type hint or by
+// detecting the type automatically.
+func (p *Parser) convert(kv keyval) (interface{}, error) {
+	hint, found := p.FieldTypes[kv.key]
+	if !found {
+		if v, err := strconv.ParseInt(kv.value, 10, 64); err == nil {
+			return v, nil
+		} else if v, err := strconv.ParseFloat(kv.value, 64); err == nil {
+			return v, nil
+		} else if v, err := strconv.ParseBool(kv.value); err == nil {
+			return v, nil
+		}
+		return kv.value, nil
+	}
+
+	var v interface{}
+	var err error
+	switch hint {
+	case "int":
+		v, err = strconv.ParseInt(kv.value, 10, 64)
+	case "uint":
+		v, err = strconv.ParseUint(kv.value, 10, 64)
+	case "float":
+		v, err = strconv.ParseFloat(kv.value, 64)
+	case "bool":
+		v, err = strconv.ParseBool(kv.value)
+	default:
+		v = kv.value
+	}
+	if err != nil {
+		return nil, fmt.Errorf("converting value %q of key %q to %s failed: %w", kv.value, kv.key, hint, err)
+	}
+	return v, nil
 }
 
 // ParseLine converts a single line of text in logfmt format to metrics.
@@ -113,9 +220,135 @@ func (p *Parser) Init() error {
 		return fmt.Errorf("error compiling tag pattern: %w", err)
 	}
 
+	switch p.DuplicateKeys {
+	case "":
+		p.DuplicateKeys = "last"
+	case "first", "last", "array":
+	default:
+		return fmt.Errorf("invalid duplicate key policy %q", p.DuplicateKeys)
+	}
+
+	for k, t := range p.FieldTypes {
+		if !choice.Contains(t, []string{"int", "uint", "float", "bool", "string"}) {
+			return fmt.Errorf("invalid type %q for key %q", t, k)
+		}
+	}
+
+	if p.TimestampFormat == "" {
+		p.TimestampFormat = "rfc3339"
+	}
+
+	if p.Timezone != "" {
+		loc, err := time.LoadLocation(p.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone: %w", err)
+		}
+		p.location = loc
+	}
+
 	return nil
 }
 
+// scanStrict decodes the data using the logfmt decoder which rejects
+// malformed records.
+func scanStrict(b []byte) ([][]keyval, error) {
+	decoder := logfmt.NewDecoder(bytes.NewReader(b))
+	var records [][]keyval
+	for decoder.ScanRecord() {
+		var record []keyval
+		for decoder.ScanKeyval() {
+			record = append(record, keyval{key: string(decoder.Key()), value: string(decoder.Value())})
+		}
+		records = append(records, record)
+	}
+	if err := decoder.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// scanLenient decodes the data line by line accepting unquoted values
+// containing special characters like '=' or '"', single-quoted values and
+// unterminated quotes which extend to the end of the line.
+func scanLenient(b []byte) [][]keyval {
+	var records [][]keyval
+	for _, line := range strings.Split(string(b), "\n") {
+		var record []keyval
+		for i := 0; i < len(line); {
+			// Skip whitespace between pairs
+			if line[i] == ' ' || line[i] == '\t' || line[i] == '\r' {
+				i++
+				continue
+			}
+
+			// The key ends at the first equal sign or whitespace
+			start := i
+			for i < len(line) && line[i] != '=' && line[i] != ' ' && line[i] != '\t' && line[i] != '\r' {
+				i++
+			}
+			key := strings.Trim(line[start:i], `"`)
+			if i >= len(line) || line[i] != '=' {
+				// Keys without value are ignored
+				continue
+			}
+			i++
+
+			var value string
+			if i < len(line) && (line[i] == '"' || line[i] == '\'') {
+				value, i = scanQuoted(line, i)
+			} else {
+				start := i
+				for i < len(line) && line[i] != ' ' && line[i] != '\t' && line[i] != '\r' {
+					i++
+				}
+				value = line[start:i]
+			}
+			if key != "" {
+				record = append(record, keyval{key: key, value: value})
+			}
+		}
+		if len(record) > 0 {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// scanQuoted reads the quoted value starting at the given offset and returns
+// the unescaped value and the offset after the closing quote. Quotes of the
+// other kind may be nested without escaping.
+func scanQuoted(line string, offset int) (string, int) {
+	quote := line[offset]
+
+	var value strings.Builder
+	i := offset + 1
+	for ; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == quote:
+			return value.String(), i + 1
+		case c == '\\' && i+1 < len(line):
+			i++
+			switch line[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			case 'r':
+				value.WriteByte('\r')
+			case '\\', '"', '\'':
+				value.WriteByte(line[i])
+			default:
+				value.WriteByte('\\')
+				value.WriteByte(line[i])
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return value.String(), i
+}
+
 func init() {
 	// Register parser
 	parsers.Add("logfmt",
@@ -279,6 +279,209 @@ func TestTags(t *testing.T) {
 	}
 }
 
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Parser
+		expected string
+	}{
+		{
+			name:     "invalid duplicate key policy",
+			plugin:   &Parser{DuplicateKeys: "merge"},
+			expected: `invalid duplicate key policy "merge"`,
+		},
+		{
+			name:     "invalid type hint",
+			plugin:   &Parser{FieldTypes: map[string]string{"status": "integer"}},
+			expected: `invalid type "integer" for key "status"`,
+		},
+		{
+			name:     "invalid timezone",
+			plugin:   &Parser{Timezone: "Mars/Olympus_Mons"},
+			expected: "invalid timezone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		policy   string
+		expected map[string]interface{}
+	}{
+		{
+			policy:   "",
+			expected: map[string]interface{}{"msg": "test", "id": int64(3)},
+		},
+		{
+			policy:   "first",
+			expected: map[string]interface{}{"msg": "test", "id": int64(1)},
+		},
+		{
+			policy:   "last",
+			expected: map[string]interface{}{"msg": "test", "id": int64(3)},
+		},
+		{
+			policy:   "array",
+			expected: map[string]interface{}{"msg": "test", "id_0": int64(1), "id_1": int64(2), "id_2": int64(3)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			plugin := &Parser{
+				metricName:    "testlog",
+				DuplicateKeys: tt.policy,
+			}
+			require.NoError(t, plugin.Init())
+
+			actual, err := plugin.ParseLine("id=1 msg=test id=2 id=3")
+			require.NoError(t, err)
+
+			expected := metric.New("testlog", map[string]string{}, tt.expected, time.Unix(0, 0))
+			testutil.RequireMetricEqual(t, expected, actual, testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestFieldTypes(t *testing.T) {
+	plugin := &Parser{
+		metricName: "testlog",
+		FieldTypes: map[string]string{
+			"code":    "string",
+			"bytes":   "uint",
+			"ratio":   "float",
+			"cached":  "bool",
+			"retries": "int",
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	actual, err := plugin.ParseLine("code=200 bytes=1653 ratio=1 cached=t retries=3 other=5")
+	require.NoError(t, err)
+
+	expected := metric.New(
+		"testlog",
+		map[string]string{},
+		map[string]interface{}{
+			"code":    "200",
+			"bytes":   uint64(1653),
+			"ratio":   float64(1),
+			"cached":  true,
+			"retries": int64(3),
+			"other":   int64(5),
+		},
+		time.Unix(0, 0),
+	)
+	testutil.RequireMetricEqual(t, expected, actual, testutil.IgnoreTime())
+
+	_, err = plugin.ParseLine("bytes=-1")
+	require.ErrorContains(t, err, `converting value "-1" of key "bytes" to uint failed`)
+}
+
+func TestTimestamp(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		timezone string
+		input    string
+		expected time.Time
+	}{
+		{
+			name:     "default format",
+			input:    "ts=2018-07-24T19:43:40.275Z msg=test",
+			expected: time.Date(2018, 7, 24, 19, 43, 40, 275000000, time.UTC),
+		},
+		{
+			name:     "unix milliseconds",
+			format:   "unix_ms",
+			input:    "ts=1532461420275 msg=test",
+			expected: time.Date(2018, 7, 24, 19, 43, 40, 275000000, time.UTC),
+		},
+		{
+			name:     "layout with timezone",
+			format:   "2006-01-02 15:04:05",
+			timezone: "Europe/Berlin",
+			input:    `ts="2018-07-24 21:43:40" msg=test`,
+			expected: time.Date(2018, 7, 24, 19, 43, 40, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Parser{
+				metricName:      "testlog",
+				TimestampKey:    "ts",
+				TimestampFormat: tt.format,
+				Timezone:        tt.timezone,
+			}
+			require.NoError(t, plugin.Init())
+
+			actual, err := plugin.ParseLine(tt.input)
+			require.NoError(t, err)
+
+			expected := metric.New("testlog", map[string]string{}, map[string]interface{}{"msg": "test"}, tt.expected)
+			testutil.RequireMetricEqual(t, expected, actual)
+		})
+	}
+
+	plugin := &Parser{TimestampKey: "ts"}
+	require.NoError(t, plugin.Init())
+	_, err := plugin.ParseLine("ts=yesterday msg=test")
+	require.ErrorContains(t, err, `parsing timestamp "yesterday" failed`)
+}
+
+func TestLenient(t *testing.T) {
+	input := `method=GET path=/search?q=a=b msg=it"s fine detail='said "hi"' quoted="escaped \"value\"" flag
+level=warn msg="unterminated quote
+`
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"testlog",
+			map[string]string{},
+			map[string]interface{}{
+				"method": "GET",
+				"path":   "/search?q=a=b",
+				"msg":    `it"s`,
+				"detail": `said "hi"`,
+				"quoted": `escaped "value"`,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"testlog",
+			map[string]string{},
+			map[string]interface{}{
+				"level": "warn",
+				"msg":   "unterminated quote",
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	// The strict decoder rejects the input
+	strict := &Parser{metricName: "testlog"}
+	require.NoError(t, strict.Init())
+	_, err := strict.Parse([]byte(input))
+	require.Error(t, err)
+
+	plugin := &Parser{
+		metricName: "testlog",
+		Lenient:    true,
+	}
+	require.NoError(t, plugin.Init())
+
+	actual, err := plugin.Parse([]byte(input))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
+
 const benchmarkData = `tags_host=myhost tags_platform=python tags_sdkver=3.11.5 value=5
 tags_host=myhost tags_platform=python tags_sdkver=3.11.4 value=4
 `
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.batchstats
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/batchstats" // register plugin
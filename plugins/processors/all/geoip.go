@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.geoip
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/geoip" // register plugin
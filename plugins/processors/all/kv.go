@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.kv
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/kv" // register plugin
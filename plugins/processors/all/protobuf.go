@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.protobuf
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/protobuf" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.remote_lookup
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/remote_lookup" // register plugin
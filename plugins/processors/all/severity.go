@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.severity
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/severity" // register plugin
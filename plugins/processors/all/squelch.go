@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.squelch
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/squelch" // register plugin
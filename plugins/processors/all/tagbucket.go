@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.tagbucket
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/tagbucket" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.time_window
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/time_window" // register plugin
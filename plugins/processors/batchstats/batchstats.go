@@ -0,0 +1,276 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package batchstats
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var availableStats = []string{"count", "mean", "stddev", "median", "zscore", "median_deviation"}
+
+type BatchStats struct {
+	Period   config.Duration `toml:"period"`
+	Fields   []string        `toml:"fields"`
+	GroupBy  []string        `toml:"group_by"`
+	Stats    []string        `toml:"stats"`
+	MinCount int             `toml:"min_count"`
+	Log      telegraf.Logger `toml:"-"`
+
+	fieldFilter filter.Filter
+
+	acc    telegraf.Accumulator
+	batch  []telegraf.Metric
+	mu     sync.Mutex
+	cancel chan struct{}
+	wg     sync.WaitGroup
+}
+
+// summary holds the statistics of a field within a group of the batch
+type summary struct {
+	count  int
+	mean   float64
+	stddev float64
+	median float64
+}
+
+func (*BatchStats) SampleConfig() string {
+	return sampleConfig
+}
+
+func (b *BatchStats) Init() error {
+	if b.Period <= 0 {
+		return errors.New("period must be positive")
+	}
+	if b.MinCount < 1 {
+		return errors.New("min_count must be at least one")
+	}
+
+	if len(b.Fields) == 0 {
+		b.Fields = []string{"*"}
+	}
+	f, err := filter.Compile(b.Fields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	b.fieldFilter = f
+
+	if len(b.Stats) == 0 {
+		b.Stats = []string{"zscore", "median_deviation"}
+	}
+	for _, s := range b.Stats {
+		if !slices.Contains(availableStats, s) {
+			return fmt.Errorf("invalid statistic %q", s)
+		}
+	}
+
+	return nil
+}
+
+func (b *BatchStats) Start(acc telegraf.Accumulator) error {
+	b.acc = acc
+	b.cancel = make(chan struct{})
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(b.Period))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-b.cancel:
+				return
+			case <-ticker.C:
+				b.flush()
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (b *BatchStats) Add(m telegraf.Metric, _ telegraf.Accumulator) error {
+	b.mu.Lock()
+	b.batch = append(b.batch, m)
+	b.mu.Unlock()
+
+	return nil
+}
+
+func (b *BatchStats) Stop() {
+	close(b.cancel)
+	b.wg.Wait()
+
+	// Annotate and forward the remaining metrics
+	b.flush()
+}
+
+// flush annotates the buffered metrics with the statistics of the batch
+// and passes them on
+func (b *BatchStats) flush() {
+	b.mu.Lock()
+	batch := b.batch
+	b.batch = nil
+	b.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	// Collect the values per group and field
+	values := make(map[string]map[string][]float64)
+	keys := make([]string, len(batch))
+	for i, m := range batch {
+		key := b.groupKey(m)
+		keys[i] = key
+		group, found := values[key]
+		if !found {
+			group = make(map[string][]float64)
+			values[key] = group
+		}
+		for _, field := range m.FieldList() {
+			if !b.fieldFilter.Match(field.Key) {
+				continue
+			}
+			if v, ok := numeric(field.Value); ok {
+				group[field.Key] = append(group[field.Key], v)
+			}
+		}
+	}
+
+	// Compute the statistics of each group and field
+	summaries := make(map[string]map[string]summary, len(values))
+	for key, group := range values {
+		summaries[key] = make(map[string]summary, len(group))
+		for field, vs := range group {
+			if len(vs) < b.MinCount {
+				continue
+			}
+			summaries[key][field] = summarize(vs)
+		}
+	}
+
+	// Annotate the metrics
+	for i, m := range batch {
+		group := summaries[keys[i]]
+		for _, field := range m.FieldList() {
+			s, found := group[field.Key]
+			if !found {
+				continue
+			}
+			// The field might not be numeric in all metrics of the group
+			if v, ok := numeric(field.Value); ok {
+				b.annotate(m, field.Key, v, s)
+			}
+		}
+		b.acc.AddMetric(m)
+	}
+}
+
+func (b *BatchStats) annotate(m telegraf.Metric, field string, v float64, s summary) {
+	for _, stat := range b.Stats {
+		switch stat {
+		case "count":
+			m.AddField(field+"_count", int64(s.count))
+		case "mean":
+			m.AddField(field+"_mean", s.mean)
+		case "stddev":
+			m.AddField(field+"_stddev", s.stddev)
+		case "median":
+			m.AddField(field+"_median", s.median)
+		case "zscore":
+			// All values are identical so none deviates
+			var z float64
+			if s.stddev > 0 {
+				z = (v - s.mean) / s.stddev
+			}
+			m.AddField(field+"_zscore", z)
+		case "median_deviation":
+			m.AddField(field+"_median_deviation", v-s.median)
+		}
+	}
+}
+
+// groupKey identifies the group of the metric by its name and the values of
+// the group_by tags
+func (b *BatchStats) groupKey(m telegraf.Metric) string {
+	var sb strings.Builder
+	sb.WriteString(m.Name())
+	for _, tag := range b.GroupBy {
+		v, _ := m.GetTag(tag)
+		sb.WriteString("\x00" + v)
+	}
+	return sb.String()
+}
+
+// numeric converts the field value to a float, skipping non-numeric values
+// like strings and booleans as well as invalid numbers
+func numeric(value interface{}) (float64, bool) {
+	var v float64
+	switch x := value.(type) {
+	case float64:
+		v = x
+	case int64:
+		v = float64(x)
+	case uint64:
+		v = float64(x)
+	default:
+		return 0, false
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+func summarize(values []float64) summary {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(values))
+
+	sorted := slices.Clone(values)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + median) / 2
+	}
+
+	return summary{
+		count:  len(values),
+		mean:   mean,
+		stddev: math.Sqrt(variance),
+		median: median,
+	}
+}
+
+func init() {
+	processors.AddStreaming("batchstats", func() telegraf.StreamingProcessor {
+		return &BatchStats{
+			Period:   config.Duration(10 * time.Second),
+			MinCount: 3,
+		}
+	})
+}
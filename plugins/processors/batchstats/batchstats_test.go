@@ -0,0 +1,182 @@
+package batchstats
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *BatchStats
+		expected string
+	}{
+		{
+			name:     "invalid period",
+			plugin:   &BatchStats{MinCount: 3},
+			expected: "period must be positive",
+		},
+		{
+			name:     "invalid min count",
+			plugin:   &BatchStats{Period: config.Duration(time.Second)},
+			expected: "min_count must be at least one",
+		},
+		{
+			name: "invalid statistic",
+			plugin: &BatchStats{
+				Period:   config.Duration(time.Second),
+				MinCount: 3,
+				Stats:    []string{"zscore", "p99"},
+			},
+			expected: `invalid statistic "p99"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a", "region": "eu"}, map[string]interface{}{"usage": 10.0, "state": "ok"}, now),
+		metric.New("cpu", map[string]string{"host": "b", "region": "eu"}, map[string]interface{}{"usage": 20.0, "state": "ok"}, now),
+		metric.New("cpu", map[string]string{"host": "c", "region": "eu"}, map[string]interface{}{"usage": int64(60), "state": "ok"}, now),
+		metric.New("cpu", map[string]string{"host": "d", "region": "us"}, map[string]interface{}{"usage": 50.0}, now),
+		metric.New("mem", map[string]string{"host": "a", "region": "eu"}, map[string]interface{}{"used": uint64(1)}, now),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a", "region": "eu"},
+			map[string]interface{}{
+				"usage":                  10.0,
+				"state":                  "ok",
+				"usage_count":            int64(3),
+				"usage_mean":             30.0,
+				"usage_median":           20.0,
+				"usage_median_deviation": -10.0,
+				"usage_zscore":           -0.9258200997725514,
+			},
+			now,
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "b", "region": "eu"},
+			map[string]interface{}{
+				"usage":                  20.0,
+				"state":                  "ok",
+				"usage_count":            int64(3),
+				"usage_mean":             30.0,
+				"usage_median":           20.0,
+				"usage_median_deviation": 0.0,
+				"usage_zscore":           -0.4629100498862757,
+			},
+			now,
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "c", "region": "eu"},
+			map[string]interface{}{
+				"usage":                  int64(60),
+				"state":                  "ok",
+				"usage_count":            int64(3),
+				"usage_mean":             30.0,
+				"usage_median":           20.0,
+				"usage_median_deviation": 40.0,
+				"usage_zscore":           1.3887301496588271,
+			},
+			now,
+		),
+		// Groups with too few metrics are passed on unmodified
+		metric.New("cpu", map[string]string{"host": "d", "region": "us"}, map[string]interface{}{"usage": 50.0}, now),
+		metric.New("mem", map[string]string{"host": "a", "region": "eu"}, map[string]interface{}{"used": uint64(1)}, now),
+	}
+
+	plugin := &BatchStats{
+		Period:   config.Duration(time.Hour),
+		GroupBy:  []string{"region"},
+		Stats:    []string{"count", "mean", "median", "median_deviation", "zscore"},
+		MinCount: 2,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	plugin.Stop()
+
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), cmpopts.EquateApprox(0, 1e-9))
+}
+
+func TestIdenticalValues(t *testing.T) {
+	now := time.Now()
+	plugin := &BatchStats{
+		Period:   config.Duration(time.Hour),
+		MinCount: 3,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for _, host := range []string{"a", "b", "c"} {
+		m := metric.New("load", map[string]string{"host": host}, map[string]interface{}{"value": 1.5}, now)
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	plugin.Stop()
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 3)
+	for _, m := range metrics {
+		z, found := m.GetField("value_zscore")
+		require.True(t, found)
+		require.InDelta(t, 0.0, z, 1e-9)
+		d, found := m.GetField("value_median_deviation")
+		require.True(t, found)
+		require.InDelta(t, 0.0, d, 1e-9)
+	}
+}
+
+func TestTracking(t *testing.T) {
+	var delivered atomic.Int32
+	notify := func(telegraf.DeliveryInfo) {
+		delivered.Add(1)
+	}
+
+	plugin := &BatchStats{
+		Period:   config.Duration(time.Hour),
+		MinCount: 1,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for i := range 3 {
+		m := metric.New("load", map[string]string{}, map[string]interface{}{"value": float64(i)}, time.Now())
+		tm, _ := metric.WithTracking(m, notify)
+		require.NoError(t, plugin.Add(tm, &acc))
+	}
+	plugin.Stop()
+
+	// The metrics are passed on tracked and delivered by the output
+	require.Zero(t, delivered.Load())
+	for _, m := range acc.GetTelegrafMetrics() {
+		m.Accept()
+	}
+	require.Eventually(t, func() bool { return delivered.Load() == 3 }, time.Second, 10*time.Millisecond)
+}
@@ -3,11 +3,13 @@ package dedup
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/processors"
 	serializers_influx "github.com/influxdata/telegraf/plugins/serializers/influx"
@@ -18,16 +20,57 @@ var sampleConfig string
 
 type Dedup struct {
 	DedupInterval config.Duration `toml:"dedup_interval"`
+	IncludeFields []string        `toml:"include_fields"`
+	ExcludeFields []string        `toml:"exclude_fields"`
+	SeriesTTL     []seriesTTL     `toml:"series_ttl"`
 	Log           telegraf.Logger `toml:"-"`
 
+	fields    filter.Filter
 	flushTime time.Time
 	cache     map[uint64]telegraf.Metric
 }
 
+// seriesTTL overrides the dedup interval for the series matching the
+// measurement and tag filters
+type seriesTTL struct {
+	Measurements []string            `toml:"measurements"`
+	Tags         map[string][]string `toml:"tags"`
+	TTL          config.Duration     `toml:"ttl"`
+
+	measurements filter.Filter
+	tags         map[string]filter.Filter
+}
+
 func (*Dedup) SampleConfig() string {
 	return sampleConfig
 }
 
+func (d *Dedup) Init() error {
+	fields, err := filter.NewIncludeExcludeFilter(d.IncludeFields, d.ExcludeFields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	d.fields = fields
+
+	for i, s := range d.SeriesTTL {
+		if s.TTL <= 0 {
+			return errors.New("ttl of series must be positive")
+		}
+		if s.measurements, err = filter.Compile(s.Measurements); err != nil {
+			return fmt.Errorf("creating measurement filter of series failed: %w", err)
+		}
+		s.tags = make(map[string]filter.Filter, len(s.Tags))
+		for key, values := range s.Tags {
+			if s.tags[key], err = filter.Compile(values); err != nil {
+				return fmt.Errorf("creating filter for tag %q of series failed: %w", key, err)
+			}
+		}
+		d.SeriesTTL[i] = s
+	}
+
+	return nil
+}
+
 func (d *Dedup) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
 	idx := 0
 	for _, metric := range metrics {
@@ -42,8 +85,9 @@ func (d *Dedup) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
 			continue
 		}
 
-		// If cache item has expired then refresh it
-		if time.Since(m.Time()) >= time.Duration(d.DedupInterval) {
+		// If cache item has expired then refresh it to emit the metric as
+		// heartbeat for the series
+		if time.Since(m.Time()) >= d.ttl(m) {
 			d.save(metric, id)
 			metrics[idx] = metric
 			idx++
@@ -55,6 +99,10 @@ func (d *Dedup) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
 		added := false
 		sametime := metric.Time() == m.Time()
 		for _, f := range metric.FieldList() {
+			// Ignore changes of the fields excluded from the comparison
+			if d.fields != nil && !d.fields.Match(f.Key) {
+				continue
+			}
 			if value, ok := m.GetField(f.Key); ok {
 				if value != f.Value {
 					changed = true
@@ -122,9 +170,19 @@ func (d *Dedup) SetState(state interface{}) error {
 		return fmt.Errorf("state has wrong type %T", state)
 	}
 	metrics, err := p.Parse(data)
-	if err == nil {
-		d.Apply(metrics...)
+	if err != nil {
+		return nil
+	}
+
+	// Skip the cached metrics already expired during the downtime
+	valid := make([]telegraf.Metric, 0, len(metrics))
+	for _, m := range metrics {
+		if time.Since(m.Time()) < d.ttl(m) {
+			valid = append(valid, m)
+		}
 	}
+	d.Apply(valid...)
+
 	return nil
 }
 
@@ -137,13 +195,36 @@ func (d *Dedup) cleanup() {
 	d.flushTime = time.Now()
 	keep := make(map[uint64]telegraf.Metric)
 	for id, metric := range d.cache {
-		if time.Since(metric.Time()) < time.Duration(d.DedupInterval) {
+		if time.Since(metric.Time()) < d.ttl(metric) {
 			keep[id] = metric
 		}
 	}
 	d.cache = keep
 }
 
+// Get the time to suppress the repetitions of the series of the metric
+func (d *Dedup) ttl(m telegraf.Metric) time.Duration {
+	for _, s := range d.SeriesTTL {
+		if s.matches(m) {
+			return time.Duration(s.TTL)
+		}
+	}
+	return time.Duration(d.DedupInterval)
+}
+
+func (s *seriesTTL) matches(m telegraf.Metric) bool {
+	if s.measurements != nil && !s.measurements.Match(m.Name()) {
+		return false
+	}
+	for key, f := range s.tags {
+		value, found := m.GetTag(key)
+		if !found || (f != nil && !f.Match(value)) {
+			return false
+		}
+	}
+	return true
+}
+
 // Save item to cache
 func (d *Dedup) save(metric telegraf.Metric, id uint64) {
 	d.cache[id] = metric.Copy()
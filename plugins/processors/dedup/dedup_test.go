@@ -530,3 +530,151 @@ func TestStatePersistence(t *testing.T) {
 	}
 	require.Len(t, actualState, expectedLen)
 }
+
+func TestFieldSubset(t *testing.T) {
+	now := time.Now()
+
+	input := []telegraf.Metric{
+		metric.New("metric",
+			map[string]string{"tag": "value"},
+			map[string]interface{}{"value": 1, "uptime": 10},
+			now.Add(-2*time.Second),
+		),
+		metric.New("metric",
+			map[string]string{"tag": "value"},
+			map[string]interface{}{"value": 1, "uptime": 11},
+			now.Add(-1*time.Second),
+		),
+		metric.New("metric",
+			map[string]string{"tag": "value"},
+			map[string]interface{}{"value": 2, "uptime": 12},
+			now,
+		),
+	}
+	expected := []telegraf.Metric{
+		metric.New("metric",
+			map[string]string{"tag": "value"},
+			map[string]interface{}{"value": 1, "uptime": 10},
+			now.Add(-2*time.Second),
+		),
+		metric.New("metric",
+			map[string]string{"tag": "value"},
+			map[string]interface{}{"value": 2, "uptime": 12},
+			now,
+		),
+	}
+
+	plugin := &Dedup{
+		DedupInterval: config.Duration(10 * time.Minute),
+		ExcludeFields: []string{"up*"},
+		flushTime:     now.Add(-1 * time.Second),
+		cache:         make(map[uint64]telegraf.Metric),
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestSeriesTTL(t *testing.T) {
+	now := time.Now()
+
+	input := []telegraf.Metric{
+		metric.New("cpu",
+			map[string]string{"cpu": "cpu-total"},
+			map[string]interface{}{"value": 1},
+			now.Add(-2*time.Minute),
+		),
+		metric.New("cpu",
+			map[string]string{"cpu": "cpu0"},
+			map[string]interface{}{"value": 1},
+			now.Add(-2*time.Minute),
+		),
+		metric.New("cpu",
+			map[string]string{"cpu": "cpu-total"},
+			map[string]interface{}{"value": 1},
+			now,
+		),
+		metric.New("cpu",
+			map[string]string{"cpu": "cpu0"},
+			map[string]interface{}{"value": 1},
+			now,
+		),
+	}
+	expected := []telegraf.Metric{
+		metric.New("cpu",
+			map[string]string{"cpu": "cpu-total"},
+			map[string]interface{}{"value": 1},
+			now.Add(-2*time.Minute),
+		),
+		metric.New("cpu",
+			map[string]string{"cpu": "cpu0"},
+			map[string]interface{}{"value": 1},
+			now.Add(-2*time.Minute),
+		),
+		// Heartbeat of the series with the shorter TTL
+		metric.New("cpu",
+			map[string]string{"cpu": "cpu-total"},
+			map[string]interface{}{"value": 1},
+			now,
+		),
+	}
+
+	plugin := &Dedup{
+		DedupInterval: config.Duration(10 * time.Minute),
+		SeriesTTL: []seriesTTL{
+			{
+				Measurements: []string{"cpu"},
+				Tags:         map[string][]string{"cpu": {"cpu-*"}},
+				TTL:          config.Duration(time.Minute),
+			},
+		},
+		flushTime: now.Add(-1 * time.Second),
+		cache:     make(map[uint64]telegraf.Metric),
+	}
+	require.NoError(t, plugin.Init())
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestStatePersistenceExpired(t *testing.T) {
+	now := time.Now()
+
+	// The state of the first series expired during the downtime
+	state := fmt.Sprintf("metric,tag=expired foo=1i %d\nmetric,tag=value foo=1i %d\n",
+		now.Add(-2*time.Hour).UnixNano(),
+		now.Add(-1*time.Minute).UnixNano(),
+	)
+
+	plugin := &Dedup{
+		DedupInterval: config.Duration(time.Hour),
+		flushTime:     now.Add(-1 * time.Second),
+		cache:         make(map[uint64]telegraf.Metric),
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.SetState([]byte(state)))
+	require.Len(t, plugin.cache, 1)
+
+	input := []telegraf.Metric{
+		metric.New("metric",
+			map[string]string{"tag": "expired"},
+			map[string]interface{}{"foo": 1},
+			now,
+		),
+		metric.New("metric",
+			map[string]string{"tag": "value"},
+			map[string]interface{}{"foo": 1},
+			now,
+		),
+	}
+	expected := []telegraf.Metric{
+		metric.New("metric",
+			map[string]string{"tag": "expired"},
+			map[string]interface{}{"foo": 1},
+			now,
+		),
+	}
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
@@ -0,0 +1,232 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package geoip
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type GeoIP struct {
+	Databases      []string        `toml:"databases"`
+	ReloadInterval config.Duration `toml:"reload_interval"`
+	Language       string          `toml:"language"`
+	Lookups        []lookup        `toml:"lookup"`
+	Log            telegraf.Logger `toml:"-"`
+
+	readers   []*database
+	lastCheck time.Time
+}
+
+type lookup struct {
+	Tag    string `toml:"tag"`
+	Field  string `toml:"field"`
+	Prefix string `toml:"prefix"`
+}
+
+// database is an opened database file remembering the modification state
+// of the file for detecting updates
+type database struct {
+	path    string
+	reader  *maxminddb.Reader
+	modTime time.Time
+	size    int64
+}
+
+// record contains the information used from City, Country and ASN databases
+type record struct {
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	ASN   uint   `maxminddb:"autonomous_system_number"`
+	ASOrg string `maxminddb:"autonomous_system_organization"`
+}
+
+func (*GeoIP) SampleConfig() string {
+	return sampleConfig
+}
+
+func (g *GeoIP) Init() error {
+	if len(g.Databases) == 0 {
+		return errors.New("no databases configured")
+	}
+	if len(g.Lookups) == 0 {
+		return errors.New("no lookups configured")
+	}
+	for i, l := range g.Lookups {
+		if (l.Tag == "") == (l.Field == "") {
+			return fmt.Errorf("lookup %d: exactly one of 'tag' or 'field' required", i+1)
+		}
+	}
+	if g.Language == "" {
+		g.Language = "en"
+	}
+
+	return nil
+}
+
+func (g *GeoIP) Start(telegraf.Accumulator) error {
+	g.readers = make([]*database, 0, len(g.Databases))
+	for _, path := range g.Databases {
+		db, err := openDatabase(path)
+		if err != nil {
+			g.Stop()
+			return err
+		}
+		g.readers = append(g.readers, db)
+	}
+	g.lastCheck = time.Now()
+
+	return nil
+}
+
+func (g *GeoIP) Add(metric telegraf.Metric, acc telegraf.Accumulator) error {
+	if g.ReloadInterval > 0 && time.Since(g.lastCheck) >= time.Duration(g.ReloadInterval) {
+		g.reload()
+		g.lastCheck = time.Now()
+	}
+
+	for _, l := range g.Lookups {
+		var value string
+		if l.Tag != "" {
+			value, _ = metric.GetTag(l.Tag)
+		} else if v, found := metric.GetField(l.Field); found {
+			value, _ = v.(string)
+		}
+		if value == "" {
+			continue
+		}
+
+		ip := net.ParseIP(value)
+		if ip == nil {
+			g.Log.Tracef("Ignoring invalid IP address %q", value)
+			continue
+		}
+		for k, v := range g.lookup(ip) {
+			metric.AddTag(l.Prefix+k, v)
+		}
+	}
+	acc.AddMetric(metric)
+
+	return nil
+}
+
+func (g *GeoIP) Stop() {
+	for _, db := range g.readers {
+		if err := db.reader.Close(); err != nil {
+			g.Log.Errorf("Closing database %q failed: %v", db.path, err)
+		}
+	}
+	g.readers = nil
+}
+
+// lookup queries all databases for the given address and returns the merged
+// tags
+func (g *GeoIP) lookup(ip net.IP) map[string]string {
+	tags := make(map[string]string)
+	for _, db := range g.readers {
+		var r record
+		if err := db.reader.Lookup(ip, &r); err != nil {
+			g.Log.Errorf("Looking up %q in %q failed: %v", ip, db.path, err)
+			continue
+		}
+		if r.Country.ISOCode != "" {
+			tags["country_code"] = r.Country.ISOCode
+		}
+		if name := g.localized(r.Country.Names); name != "" {
+			tags["country"] = name
+		}
+		if name := g.localized(r.City.Names); name != "" {
+			tags["city"] = name
+		}
+		if r.ASN != 0 {
+			tags["asn"] = strconv.FormatUint(uint64(r.ASN), 10)
+		}
+		if r.ASOrg != "" {
+			tags["as_org"] = r.ASOrg
+		}
+	}
+	return tags
+}
+
+func (g *GeoIP) localized(names map[string]string) string {
+	if name, found := names[g.Language]; found {
+		return name
+	}
+	return names["en"]
+}
+
+// reload replaces the readers of all databases modified since opening them.
+// On error the previous database is kept.
+func (g *GeoIP) reload() {
+	for i, db := range g.readers {
+		info, err := os.Stat(db.path)
+		if err != nil {
+			g.Log.Errorf("Checking database %q failed: %v", db.path, err)
+			continue
+		}
+		if info.ModTime().Equal(db.modTime) && info.Size() == db.size {
+			continue
+		}
+
+		updated, err := openDatabase(db.path)
+		if err != nil {
+			g.Log.Errorf("Reloading database failed: %v", err)
+			continue
+		}
+		if err := db.reader.Close(); err != nil {
+			g.Log.Errorf("Closing database %q failed: %v", db.path, err)
+		}
+		g.readers[i] = updated
+		g.Log.Infof("Reloaded database %q built at %s", db.path, updated.built())
+	}
+}
+
+func openDatabase(path string) (*database, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("checking database %q failed: %w", path, err)
+	}
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening database %q failed: %w", path, err)
+	}
+
+	return &database{
+		path:    path,
+		reader:  reader,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+	}, nil
+}
+
+func (db *database) built() string {
+	//nolint:gosec // G115: The build epoch is a unix timestamp which fits into int64
+	return time.Unix(int64(db.reader.Metadata.BuildEpoch), 0).UTC().Format(time.RFC3339)
+}
+
+func init() {
+	processors.AddStreaming("geoip", func() telegraf.StreamingProcessor {
+		return &GeoIP{
+			ReloadInterval: config.Duration(time.Minute),
+			Language:       "en",
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package geoip
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *GeoIP
+		expected string
+	}{
+		{
+			name:     "no databases",
+			plugin:   &GeoIP{Lookups: []lookup{{Tag: "ip"}}},
+			expected: "no databases configured",
+		},
+		{
+			name:     "no lookups",
+			plugin:   &GeoIP{Databases: []string{"testdata/city.mmdb"}},
+			expected: "no lookups configured",
+		},
+		{
+			name:     "no tag or field",
+			plugin:   &GeoIP{Databases: []string{"testdata/city.mmdb"}, Lookups: []lookup{{Prefix: "src_"}}},
+			expected: "exactly one of 'tag' or 'field' required",
+		},
+		{
+			name:     "tag and field",
+			plugin:   &GeoIP{Databases: []string{"testdata/city.mmdb"}, Lookups: []lookup{{Tag: "ip", Field: "ip"}}},
+			expected: "exactly one of 'tag' or 'field' required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestStartMissingDatabase(t *testing.T) {
+	plugin := &GeoIP{
+		Databases: []string{"testdata/city.mmdb", "testdata/nonexistent.mmdb"},
+		Lookups:   []lookup{{Tag: "ip"}},
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Start(&acc), "nonexistent.mmdb")
+	require.Empty(t, plugin.readers)
+}
+
+func TestLookup(t *testing.T) {
+	plugin := &GeoIP{
+		Databases: []string{"testdata/city.mmdb", "testdata/asn.mmdb"},
+		Lookups: []lookup{
+			{Tag: "src_ip", Prefix: "src_"},
+			{Field: "dst_ip", Prefix: "dst_"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New(
+			"netflow",
+			map[string]string{"src_ip": "81.2.69.142"},
+			map[string]interface{}{"dst_ip": "2001:db8::1", "bytes": 1024},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"netflow",
+			map[string]string{"src_ip": "89.160.20.112"},
+			map[string]interface{}{"dst_ip": "10.0.0.1", "bytes": 512},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"netflow",
+			map[string]string{"src_ip": "not an address"},
+			map[string]interface{}{"bytes": 256},
+			time.Unix(0, 0),
+		),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"netflow",
+			map[string]string{
+				"src_ip":           "81.2.69.142",
+				"src_country_code": "GB",
+				"src_country":      "United Kingdom",
+				"src_city":         "London",
+				"src_asn":          "20712",
+				"src_as_org":       "Andrews & Arnold Ltd",
+				"dst_country_code": "DE",
+				"dst_country":      "Germany",
+				"dst_city":         "Munich",
+				"dst_asn":          "64496",
+				"dst_as_org":       "Documentation AS",
+			},
+			map[string]interface{}{"dst_ip": "2001:db8::1", "bytes": 1024},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"netflow",
+			map[string]string{
+				"src_ip":           "89.160.20.112",
+				"src_country_code": "SE",
+				"src_country":      "Sweden",
+			},
+			map[string]interface{}{"dst_ip": "10.0.0.1", "bytes": 512},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"netflow",
+			map[string]string{"src_ip": "not an address"},
+			map[string]interface{}{"bytes": 256},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestLookupLanguage(t *testing.T) {
+	plugin := &GeoIP{
+		Databases: []string{"testdata/city.mmdb"},
+		Language:  "de",
+		Lookups:   []lookup{{Tag: "ip"}},
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	m := metric.New("test", map[string]string{"ip": "2001:db8::1"}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.NoError(t, plugin.Add(m, &acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{"ip": "2001:db8::1", "country_code": "DE", "country": "Deutschland", "city": "München"},
+			map[string]interface{}{"value": 1},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "city.mmdb")
+	copyFile(t, "testdata/city.mmdb", path)
+
+	plugin := &GeoIP{
+		Databases:      []string{path},
+		ReloadInterval: config.Duration(time.Minute),
+		Lookups:        []lookup{{Tag: "ip"}},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	m := metric.New("test", map[string]string{"ip": "81.2.69.142"}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.NoError(t, plugin.Add(m, &acc))
+
+	// Replace the database atomically as done by update tools and force
+	// the next check
+	tmp := path + ".tmp"
+	copyFile(t, "testdata/city_update.mmdb", tmp)
+	require.NoError(t, os.Chtimes(tmp, time.Now(), time.Now().Add(time.Hour)))
+	require.NoError(t, os.Rename(tmp, path))
+	plugin.lastCheck = time.Now().Add(-time.Hour)
+
+	m = metric.New("test", map[string]string{"ip": "81.2.69.142"}, map[string]interface{}{"value": 2}, time.Unix(0, 0))
+	require.NoError(t, plugin.Add(m, &acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{"ip": "81.2.69.142", "country_code": "GB", "country": "United Kingdom", "city": "London"},
+			map[string]interface{}{"value": 1},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"test",
+			map[string]string{"ip": "81.2.69.142", "country_code": "GB", "country": "United Kingdom", "city": "Manchester"},
+			map[string]interface{}{"value": 2},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	t.Helper()
+	buf, err := os.ReadFile(src)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(dst, buf, 0600))
+}
@@ -0,0 +1,233 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package kv
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type KV struct {
+	Field           string          `toml:"field"`
+	ValueSeparators []string        `toml:"value_separators"`
+	PairSeparators  []string        `toml:"pair_separators"`
+	Brackets        []string        `toml:"brackets"`
+	Quotes          string          `toml:"quotes"`
+	TrimKey         string          `toml:"trim_key"`
+	TrimValue       string          `toml:"trim_value"`
+	Target          string          `toml:"target"`
+	Prefix          string          `toml:"prefix"`
+	Overwrite       bool            `toml:"overwrite"`
+	DropField       bool            `toml:"drop_field"`
+	Log             telegraf.Logger `toml:"-"`
+
+	brackets map[byte]byte
+}
+
+type pair struct {
+	key   string
+	value string
+}
+
+func (*KV) SampleConfig() string {
+	return sampleConfig
+}
+
+func (kv *KV) Init() error {
+	if kv.Field == "" {
+		return errors.New("field must be set")
+	}
+
+	if len(kv.ValueSeparators) == 0 || len(kv.PairSeparators) == 0 {
+		return errors.New("value and pair separators must be set")
+	}
+	if slices.Contains(kv.ValueSeparators, "") || slices.Contains(kv.PairSeparators, "") {
+		return errors.New("separators must not be empty")
+	}
+
+	kv.brackets = make(map[byte]byte, len(kv.Brackets))
+	for _, b := range kv.Brackets {
+		if len(b) != 2 {
+			return fmt.Errorf("invalid brackets %q, expected opening and closing character", b)
+		}
+		kv.brackets[b[0]] = b[1]
+	}
+
+	switch kv.Target {
+	case "fields", "tags":
+	default:
+		return fmt.Errorf("invalid target %q", kv.Target)
+	}
+
+	return nil
+}
+
+func (kv *KV) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		raw, found := m.GetField(kv.Field)
+		if !found {
+			continue
+		}
+		text, ok := raw.(string)
+		if !ok {
+			kv.Log.Debugf("Field %q of metric %q is not a string but %T", kv.Field, m.Name(), raw)
+			continue
+		}
+
+		pairs := kv.extract(text)
+		if len(pairs) == 0 {
+			continue
+		}
+
+		if kv.DropField {
+			m.RemoveField(kv.Field)
+		}
+		for _, p := range pairs {
+			key := kv.Prefix + p.key
+			switch kv.Target {
+			case "tags":
+				if kv.Overwrite || !m.HasTag(key) {
+					m.AddTag(key, p.value)
+				}
+			case "fields":
+				if kv.Overwrite || !m.HasField(key) {
+					m.AddField(key, p.value)
+				}
+			}
+		}
+	}
+
+	return in
+}
+
+// extract scans the text for key-value pairs
+func (kv *KV) extract(text string) []pair {
+	var pairs []pair
+	for len(text) > 0 {
+		// Skip leading separators
+		if n := kv.prefixLen(text, kv.PairSeparators); n > 0 {
+			text = text[n:]
+			continue
+		}
+
+		// Bracketed pairs extend up to the closing bracket
+		if closing, found := kv.brackets[text[0]]; found {
+			if end := strings.IndexByte(text[1:], closing); end >= 0 {
+				if p, ok := kv.splitPair(text[1 : end+1]); ok {
+					pairs = append(pairs, p)
+				}
+				text = text[end+2:]
+				continue
+			}
+		}
+
+		// Read the key up to a value or pair separator
+		var n int
+		for n < len(text) && kv.prefixLen(text[n:], kv.ValueSeparators) == 0 && kv.prefixLen(text[n:], kv.PairSeparators) == 0 {
+			n++
+		}
+		key := text[:n]
+		text = text[n:]
+		sep := kv.prefixLen(text, kv.ValueSeparators)
+		if sep == 0 {
+			// Word without a value
+			continue
+		}
+		text = kv.skipSpace(text[sep:])
+
+		// Read the value either up to the closing quote or the next separator
+		var value string
+		if len(text) > 0 && strings.IndexByte(kv.Quotes, text[0]) >= 0 {
+			if end := strings.IndexByte(text[1:], text[0]); end >= 0 {
+				value = text[1 : end+1]
+				text = text[end+2:]
+				kv.addPair(&pairs, key, value)
+				continue
+			}
+		}
+		n = 0
+		for n < len(text) && kv.prefixLen(text[n:], kv.PairSeparators) == 0 {
+			n++
+		}
+		value = text[:n]
+		text = text[n:]
+		kv.addPair(&pairs, key, value)
+	}
+
+	return pairs
+}
+
+// skipSpace removes the whitespace following a value separator unless the
+// next word is a pair by itself, i.e. the value is empty
+func (kv *KV) skipSpace(text string) string {
+	trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+	if len(trimmed) == len(text) || kv.prefixLen(text, kv.PairSeparators) == 0 {
+		return trimmed
+	}
+	for n := 0; n < len(trimmed) && kv.prefixLen(trimmed[n:], kv.PairSeparators) == 0; n++ {
+		if kv.prefixLen(trimmed[n:], kv.ValueSeparators) > 0 {
+			return text
+		}
+	}
+	return trimmed
+}
+
+// splitPair splits the content of a bracketed pair at the first value separator
+func (kv *KV) splitPair(text string) (pair, bool) {
+	for i := range text {
+		if n := kv.prefixLen(text[i:], kv.ValueSeparators); n > 0 {
+			var pairs []pair
+			kv.addPair(&pairs, text[:i], strings.TrimLeftFunc(text[i+n:], unicode.IsSpace))
+			if len(pairs) == 0 {
+				return pair{}, false
+			}
+			return pairs[0], true
+		}
+	}
+	return pair{}, false
+}
+
+// addPair trims the key and value and adds the pair if the key is not empty
+func (kv *KV) addPair(pairs *[]pair, key, value string) {
+	key = strings.TrimSpace(key)
+	if kv.TrimKey != "" {
+		key = strings.Trim(key, kv.TrimKey)
+	}
+	if kv.TrimValue != "" {
+		value = strings.Trim(value, kv.TrimValue)
+	}
+	if key == "" || (value == "" && kv.Target == "tags") {
+		return
+	}
+	*pairs = append(*pairs, pair{key: key, value: value})
+}
+
+// prefixLen returns the length of the separator the text starts with or zero
+func (*KV) prefixLen(text string, separators []string) int {
+	for _, sep := range separators {
+		if strings.HasPrefix(text, sep) {
+			return len(sep)
+		}
+	}
+	return 0
+}
+
+func init() {
+	processors.Add("kv", func() telegraf.Processor {
+		return &KV{
+			ValueSeparators: []string{"="},
+			PairSeparators:  []string{" "},
+			Quotes:          "\"'",
+			Target:          "fields",
+		}
+	})
+}
@@ -0,0 +1,316 @@
+package kv
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newKV() *KV {
+	return processors.Processors["kv"]().(processors.HasUnwrap).Unwrap().(*KV)
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*KV)
+		expected string
+	}{
+		{
+			name:     "no field",
+			modify:   func(*KV) {},
+			expected: "field must be set",
+		},
+		{
+			name: "empty separator",
+			modify: func(kv *KV) {
+				kv.Field = "message"
+				kv.PairSeparators = []string{" ", ""}
+			},
+			expected: "separators must not be empty",
+		},
+		{
+			name: "invalid brackets",
+			modify: func(kv *KV) {
+				kv.Field = "message"
+				kv.Brackets = []string{"["}
+			},
+			expected: `invalid brackets "["`,
+		},
+		{
+			name: "invalid target",
+			modify: func(kv *KV) {
+				kv.Field = "message"
+				kv.Target = "name"
+			},
+			expected: `invalid target "name"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newKV()
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*KV)
+		text     string
+		expected map[string]interface{}
+	}{
+		{
+			name: "defaults",
+			text: `user=jane action=login msg="access granted" code='200' noise`,
+			expected: map[string]interface{}{
+				"user":   "jane",
+				"action": "login",
+				"msg":    "access granted",
+				"code":   "200",
+			},
+		},
+		{
+			name: "colon separator",
+			modify: func(kv *KV) {
+				kv.ValueSeparators = []string{":", "="}
+				kv.PairSeparators = []string{",", ";"}
+			},
+			text: "host: web01, status: ok;latency=12ms, time: 12:30:00",
+			expected: map[string]interface{}{
+				"host":    "web01",
+				"status":  "ok",
+				"latency": "12ms",
+				"time":    "12:30:00",
+			},
+		},
+		{
+			name: "empty value",
+			text: "a= b=2 c=",
+			expected: map[string]interface{}{
+				"a": "",
+				"b": "2",
+				"c": "",
+			},
+		},
+		{
+			name: "space after separator",
+			modify: func(kv *KV) {
+				kv.ValueSeparators = []string{":"}
+			},
+			text: "level: warn component: db",
+			expected: map[string]interface{}{
+				"level":     "warn",
+				"component": "db",
+			},
+		},
+		{
+			name: "brackets",
+			modify: func(kv *KV) {
+				kv.Brackets = []string{"[]", "()"}
+			},
+			text: "[INFO] [user=jane doe] (session = 42) result=ok [unterminated=x",
+			expected: map[string]interface{}{
+				"user":          "jane doe",
+				"session":       "42",
+				"result":        "ok",
+				"[unterminated": "x",
+			},
+		},
+		{
+			name: "trim",
+			modify: func(kv *KV) {
+				kv.TrimKey = "-"
+				kv.TrimValue = ".,"
+			},
+			text: "--retries=3, --mode=fast.",
+			expected: map[string]interface{}{
+				"retries": "3",
+				"mode":    "fast",
+			},
+		},
+		{
+			name: "no quotes",
+			modify: func(kv *KV) {
+				kv.Quotes = ""
+			},
+			text: `msg="hello world"`,
+			expected: map[string]interface{}{
+				"msg": `"hello`,
+			},
+		},
+		{
+			name: "multi-character separators",
+			modify: func(kv *KV) {
+				kv.ValueSeparators = []string{"=>"}
+				kv.PairSeparators = []string{" | "}
+			},
+			text: "a=>1 | b=>x y | c=>=",
+			expected: map[string]interface{}{
+				"a": "1",
+				"b": "x y",
+				"c": "=",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newKV()
+			plugin.Field = "message"
+			if tt.modify != nil {
+				tt.modify(plugin)
+			}
+			require.NoError(t, plugin.Init())
+
+			actual := make(map[string]interface{})
+			for _, p := range plugin.extract(tt.text) {
+				actual[p.key] = p.value
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	input := []telegraf.Metric{
+		metric.New(
+			"syslog",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"message": "host=b user=jane status=failed", "status": "new"},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"syslog",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"message": "nothing to extract"},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"syslog",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"message": 42},
+			time.Unix(0, 0),
+		),
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(*KV)
+		expected []telegraf.Metric
+	}{
+		{
+			name: "fields",
+			expected: []telegraf.Metric{
+				metric.New(
+					"syslog",
+					map[string]string{"host": "a"},
+					map[string]interface{}{
+						"message": "host=b user=jane status=failed",
+						"status":  "new",
+						"host":    "b",
+						"user":    "jane",
+					},
+					time.Unix(0, 0),
+				),
+				input[1],
+				input[2],
+			},
+		},
+		{
+			name: "tags with overwrite and drop",
+			modify: func(kv *KV) {
+				kv.Target = "tags"
+				kv.Overwrite = true
+				kv.DropField = true
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"syslog",
+					map[string]string{"host": "b", "user": "jane", "status": "failed"},
+					map[string]interface{}{"status": "new"},
+					time.Unix(0, 0),
+				),
+				input[1],
+				input[2],
+			},
+		},
+		{
+			name: "prefix",
+			modify: func(kv *KV) {
+				kv.Prefix = "kv_"
+				kv.DropField = true
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"syslog",
+					map[string]string{"host": "a"},
+					map[string]interface{}{
+						"status":    "new",
+						"kv_host":   "b",
+						"kv_user":   "jane",
+						"kv_status": "failed",
+					},
+					time.Unix(0, 0),
+				),
+				input[1],
+				input[2],
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newKV()
+			plugin.Field = "message"
+			plugin.Log = testutil.Logger{}
+			if tt.modify != nil {
+				tt.modify(plugin)
+			}
+			require.NoError(t, plugin.Init())
+
+			in := make([]telegraf.Metric, 0, len(input))
+			for _, m := range input {
+				in = append(in, m.Copy())
+			}
+			actual := plugin.Apply(in...)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestTracking(t *testing.T) {
+	var mu sync.Mutex
+	delivered := make([]telegraf.DeliveryInfo, 0, 1)
+	notify := func(di telegraf.DeliveryInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, di)
+	}
+
+	m := metric.New("syslog", nil, map[string]interface{}{"message": "a=1"}, time.Unix(0, 0))
+	tm, _ := metric.WithTracking(m, notify)
+
+	plugin := newKV()
+	plugin.Field = "message"
+	require.NoError(t, plugin.Init())
+
+	for _, m := range plugin.Apply(tm) {
+		m.Accept()
+	}
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(delivered) == 1
+	}, time.Second, 100*time.Millisecond)
+}
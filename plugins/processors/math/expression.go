@@ -0,0 +1,533 @@
+package math
+
+import (
+	"errors"
+	"fmt"
+	gomath "math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	errMissingField    = errors.New("missing field")
+	errNotNumeric      = errors.New("field is not numeric")
+	errDivisionByZero  = errors.New("division by zero")
+	errInvalidArgument = errors.New("invalid function argument")
+)
+
+// node is an element of a parsed expression evaluated on the metric's fields
+type node interface {
+	eval(fields map[string]interface{}) (float64, error)
+}
+
+type number float64
+
+func (n number) eval(map[string]interface{}) (float64, error) {
+	return float64(n), nil
+}
+
+type field string
+
+func (f field) eval(fields map[string]interface{}) (float64, error) {
+	v, found := fields[string(f)]
+	if !found {
+		return 0, fmt.Errorf("%w %q", errMissingField, string(f))
+	}
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case bool:
+		return boolToFloat(v), nil
+	}
+	return 0, fmt.Errorf("%w: %q", errNotNumeric, string(f))
+}
+
+type unary struct {
+	op      string
+	operand node
+}
+
+func (u *unary) eval(fields map[string]interface{}) (float64, error) {
+	v, err := u.operand.eval(fields)
+	if err != nil {
+		return 0, err
+	}
+	switch u.op {
+	case "-":
+		return -v, nil
+	case "!":
+		return boolToFloat(v == 0), nil
+	}
+	return v, nil
+}
+
+type binary struct {
+	op          string
+	left, right node
+}
+
+func (b *binary) eval(fields map[string]interface{}) (float64, error) {
+	left, err := b.left.eval(fields)
+	if err != nil {
+		return 0, err
+	}
+
+	// Logical operators short-circuit to allow guarding the right side
+	switch b.op {
+	case "&&":
+		if left == 0 {
+			return 0, nil
+		}
+	case "||":
+		if left != 0 {
+			return 1, nil
+		}
+	}
+
+	right, err := b.right.eval(fields)
+	if err != nil {
+		return 0, err
+	}
+
+	switch b.op {
+	case "&&", "||":
+		return boolToFloat(right != 0), nil
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/":
+		if right == 0 {
+			return 0, errDivisionByZero
+		}
+		return left / right, nil
+	case "%":
+		if right == 0 {
+			return 0, errDivisionByZero
+		}
+		return gomath.Mod(left, right), nil
+	case "^":
+		return gomath.Pow(left, right), nil
+	case "==":
+		return boolToFloat(left == right), nil
+	case "!=":
+		return boolToFloat(left != right), nil
+	case "<":
+		return boolToFloat(left < right), nil
+	case "<=":
+		return boolToFloat(left <= right), nil
+	case ">":
+		return boolToFloat(left > right), nil
+	case ">=":
+		return boolToFloat(left >= right), nil
+	}
+	return 0, fmt.Errorf("unknown operator %q", b.op)
+}
+
+type ternary struct {
+	condition, then, otherwise node
+}
+
+func (t *ternary) eval(fields map[string]interface{}) (float64, error) {
+	c, err := t.condition.eval(fields)
+	if err != nil {
+		return 0, err
+	}
+	if c != 0 {
+		return t.then.eval(fields)
+	}
+	return t.otherwise.eval(fields)
+}
+
+type call struct {
+	fn   *function
+	args []node
+}
+
+func (c *call) eval(fields map[string]interface{}) (float64, error) {
+	args := make([]float64, 0, len(c.args))
+	for _, arg := range c.args {
+		v, err := arg.eval(fields)
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, v)
+	}
+	return c.fn.eval(args)
+}
+
+type function struct {
+	minArgs, maxArgs int // a negative maximum allows any number of arguments
+	eval             func(args []float64) (float64, error)
+}
+
+func unaryFunction(fn func(float64) float64) *function {
+	return &function{
+		minArgs: 1,
+		maxArgs: 1,
+		eval: func(args []float64) (float64, error) {
+			return fn(args[0]), nil
+		},
+	}
+}
+
+var functions = map[string]*function{
+	"abs":   unaryFunction(gomath.Abs),
+	"ceil":  unaryFunction(gomath.Ceil),
+	"floor": unaryFunction(gomath.Floor),
+	"round": unaryFunction(gomath.Round),
+	"exp":   unaryFunction(gomath.Exp),
+	"sqrt": {
+		minArgs: 1,
+		maxArgs: 1,
+		eval: func(args []float64) (float64, error) {
+			if args[0] < 0 {
+				return 0, fmt.Errorf("%w: sqrt of negative value", errInvalidArgument)
+			}
+			return gomath.Sqrt(args[0]), nil
+		},
+	},
+	"log": {
+		minArgs: 1,
+		maxArgs: 1,
+		eval: func(args []float64) (float64, error) {
+			if args[0] <= 0 {
+				return 0, fmt.Errorf("%w: log of non-positive value", errInvalidArgument)
+			}
+			return gomath.Log(args[0]), nil
+		},
+	},
+	"log10": {
+		minArgs: 1,
+		maxArgs: 1,
+		eval: func(args []float64) (float64, error) {
+			if args[0] <= 0 {
+				return 0, fmt.Errorf("%w: log10 of non-positive value", errInvalidArgument)
+			}
+			return gomath.Log10(args[0]), nil
+		},
+	},
+	"min": {
+		minArgs: 1,
+		maxArgs: -1,
+		eval: func(args []float64) (float64, error) {
+			result := args[0]
+			for _, v := range args[1:] {
+				result = gomath.Min(result, v)
+			}
+			return result, nil
+		},
+	},
+	"max": {
+		minArgs: 1,
+		maxArgs: -1,
+		eval: func(args []float64) (float64, error) {
+			result := args[0]
+			for _, v := range args[1:] {
+				result = gomath.Max(result, v)
+			}
+			return result, nil
+		},
+	},
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// assignment is a parsed "<field> = <expression>" statement
+type assignment struct {
+	target string
+	expr   node
+}
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOperator
+)
+
+var operators = []string{
+	"==", "!=", "<=", ">=", "&&", "||",
+	"+", "-", "*", "/", "%", "^", "<", ">", "!", "?", ":", "(", ")", ",", "=",
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || c == '.':
+			start := i
+			for i < len(s) && (unicode.IsDigit(rune(s[i])) || s[i] == '.') {
+				i++
+			}
+			// Exponent notation such as 1e-6
+			if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+				j := i + 1
+				if j < len(s) && (s[j] == '+' || s[j] == '-') {
+					j++
+				}
+				if j < len(s) && unicode.IsDigit(rune(s[j])) {
+					i = j
+					for i < len(s) && unicode.IsDigit(rune(s[i])) {
+						i++
+					}
+				}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: s[start:i], pos: start})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(s) && (unicode.IsLetter(rune(s[i])) || unicode.IsDigit(rune(s[i])) || s[i] == '_' || s[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: s[start:i], pos: start})
+		case c == '`':
+			// Quoted field names can contain arbitrary characters
+			end := strings.IndexByte(s[i+1:], '`')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted field name at position %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: s[i+1 : i+1+end], pos: i})
+			i += end + 2
+		default:
+			var op string
+			for _, candidate := range operators {
+				if strings.HasPrefix(s[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, value: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(s)}), nil
+}
+
+// parser is a recursive-descent parser with the usual operator precedence,
+// from lowest to highest: ternary, ||, &&, comparison, +/-, */%, unary, ^
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parseAssignment(s string) (*assignment, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+
+	target := p.next()
+	if target.kind != tokenIdent {
+		return nil, fmt.Errorf("expected field name at position %d", target.pos)
+	}
+	if t := p.next(); t.kind != tokenOperator || t.value != "=" {
+		return nil, fmt.Errorf("expected '=' at position %d", t.pos)
+	}
+
+	expr, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+	}
+
+	return &assignment{target: target.value, expr: expr}, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is one of the given operators
+func (p *parser) accept(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.value == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) parseTernary() (node, error) {
+	condition, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept("?"); !ok {
+		return condition, nil
+	}
+
+	then, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept(":"); !ok {
+		t := p.peek()
+		return nil, fmt.Errorf("expected ':' at position %d", t.pos)
+	}
+	otherwise, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+	return &ternary{condition: condition, then: then, otherwise: otherwise}, nil
+}
+
+// precedence lists the left-associative binary operators by increasing
+// precedence
+var precedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) parseBinary(level int) (node, error) {
+	if level == len(precedence) {
+		return p.parseUnary()
+	}
+
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(precedence[level]...)
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if op, ok := p.accept("-", "+", "!"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{op: op, operand: operand}, nil
+	}
+	return p.parsePower()
+}
+
+func (p *parser) parsePower() (node, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept("^"); !ok {
+		return base, nil
+	}
+	// Exponentiation is right-associative
+	exponent, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &binary{op: "^", left: base, right: exponent}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		v, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.value, t.pos)
+		}
+		return number(v), nil
+	case tokenIdent:
+		if _, ok := p.accept("("); !ok {
+			return field(t.value), nil
+		}
+		return p.parseCall(t)
+	case tokenOperator:
+		if t.value == "(" {
+			expr, err := p.parseTernary()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := p.accept(")"); !ok {
+				return nil, fmt.Errorf("expected ')' at position %d", p.peek().pos)
+			}
+			return expr, nil
+		}
+		return nil, fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+	}
+	return nil, errors.New("unexpected end of expression")
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, found := functions[name.value]
+	if !found {
+		return nil, fmt.Errorf("unknown function %q at position %d", name.value, name.pos)
+	}
+
+	var args []node
+	if _, ok := p.accept(")"); !ok {
+		for {
+			arg, err := p.parseTernary()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if _, ok := p.accept(","); ok {
+				continue
+			}
+			if _, ok := p.accept(")"); ok {
+				break
+			}
+			return nil, fmt.Errorf("expected ',' or ')' at position %d", p.peek().pos)
+		}
+	}
+
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("invalid number of arguments for function %q at position %d", name.value, name.pos)
+	}
+	return &call{fn: fn, args: args}, nil
+}
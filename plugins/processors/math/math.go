@@ -0,0 +1,83 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package math
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	gomath "math"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Math struct {
+	Expressions    []string        `toml:"expressions"`
+	DivisionByZero string          `toml:"division_by_zero"`
+	Log            telegraf.Logger `toml:"-"`
+
+	assignments []*assignment
+}
+
+func (*Math) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Math) Init() error {
+	if len(p.Expressions) == 0 {
+		return errors.New("no expressions specified")
+	}
+
+	switch p.DivisionByZero {
+	case "":
+		p.DivisionByZero = "skip"
+	case "skip", "zero":
+	default:
+		return fmt.Errorf("invalid division_by_zero value %q", p.DivisionByZero)
+	}
+
+	p.assignments = make([]*assignment, 0, len(p.Expressions))
+	for _, expr := range p.Expressions {
+		a, err := parseAssignment(expr)
+		if err != nil {
+			return fmt.Errorf("parsing expression %q failed: %w", expr, err)
+		}
+		p.assignments = append(p.assignments, a)
+	}
+
+	return nil
+}
+
+func (p *Math) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range metrics {
+		// Keep the fields up to date so expressions can use the results of
+		// previous expressions
+		fields := m.Fields()
+		for _, a := range p.assignments {
+			v, err := a.expr.eval(fields)
+			if errors.Is(err, errDivisionByZero) && p.DivisionByZero == "zero" {
+				v, err = 0, nil
+			}
+			if err != nil {
+				p.Log.Tracef("Skipping field %q of metric %q: %v", a.target, m.Name(), err)
+				continue
+			}
+			if gomath.IsNaN(v) || gomath.IsInf(v, 0) {
+				p.Log.Tracef("Skipping field %q of metric %q: result %v is not a valid value", a.target, m.Name(), v)
+				continue
+			}
+			m.AddField(a.target, v)
+			fields[a.target] = v
+		}
+	}
+	return metrics
+}
+
+func init() {
+	processors.Add("math", func() telegraf.Processor {
+		return &Math{}
+	})
+}
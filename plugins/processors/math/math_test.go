@@ -0,0 +1,226 @@
+package math
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Math
+		expected string
+	}{
+		{
+			name:     "no expressions",
+			plugin:   &Math{},
+			expected: "no expressions specified",
+		},
+		{
+			name:     "invalid division handling",
+			plugin:   &Math{Expressions: []string{"a = b"}, DivisionByZero: "fail"},
+			expected: `invalid division_by_zero value "fail"`,
+		},
+		{
+			name:     "missing assignment",
+			plugin:   &Math{Expressions: []string{"a + b"}},
+			expected: "expected '=' at position 2",
+		},
+		{
+			name:     "unbalanced parentheses",
+			plugin:   &Math{Expressions: []string{"a = (b + c"}},
+			expected: "expected ')' at position 10",
+		},
+		{
+			name:     "incomplete ternary",
+			plugin:   &Math{Expressions: []string{"a = b ? c"}},
+			expected: "expected ':' at position 9",
+		},
+		{
+			name:     "unknown function",
+			plugin:   &Math{Expressions: []string{"a = foo(b)"}},
+			expected: `unknown function "foo" at position 4`,
+		},
+		{
+			name:     "wrong number of arguments",
+			plugin:   &Math{Expressions: []string{"a = sqrt(b, c)"}},
+			expected: `invalid number of arguments for function "sqrt"`,
+		},
+		{
+			name:     "invalid character",
+			plugin:   &Math{Expressions: []string{"a = b $ c"}},
+			expected: `unexpected character '$' at position 6`,
+		},
+		{
+			name:     "trailing tokens",
+			plugin:   &Math{Expressions: []string{"a = b c"}},
+			expected: `unexpected "c" at position 6`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestExpressions(t *testing.T) {
+	fields := map[string]interface{}{
+		"used":      int64(4096),
+		"total":     uint64(16384),
+		"load":      3.5,
+		"zero":      int64(0),
+		"active":    true,
+		"disk-used": 10.0,
+		"name":      "foo",
+	}
+
+	tests := []struct {
+		expr     string
+		expected float64
+	}{
+		{expr: "used / total * 100", expected: 25},
+		{expr: "1 + 2 * 3", expected: 7},
+		{expr: "(1 + 2) * 3", expected: 9},
+		{expr: "10 - 4 - 3", expected: 3},
+		{expr: "-2 ^ 2", expected: -4},
+		{expr: "2 ^ 3 ^ 2", expected: 512},
+		{expr: "7 % 4", expected: 3},
+		{expr: "1.5e3 / 1e-1", expected: 15000},
+		{expr: "load > 2 ? 1 : 0", expected: 1},
+		{expr: "load > 4 ? 1 : load > 3 ? 2 : 3", expected: 2},
+		{expr: "zero == 0 ? 0 : used / zero", expected: 0},
+		{expr: "zero != 0 && used / zero > 1", expected: 0},
+		{expr: "zero == 0 || missing > 1", expected: 1},
+		{expr: "!active", expected: 0},
+		{expr: "`disk-used` * 2", expected: 20},
+		{expr: "abs(-load) + floor(load) + ceil(load) + round(load)", expected: 14.5},
+		{expr: "min(used, total, 100) + max(1, 2)", expected: 102},
+		{expr: "sqrt(16) + log10(100) + log(exp(1))", expected: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			a, err := parseAssignment("result = " + tt.expr)
+			require.NoError(t, err)
+			actual, err := a.expr.eval(fields)
+			require.NoError(t, err)
+			require.InDelta(t, tt.expected, actual, 1e-9)
+		})
+	}
+}
+
+func TestExpressionErrors(t *testing.T) {
+	fields := map[string]interface{}{
+		"value": 1.0,
+		"zero":  int64(0),
+		"name":  "foo",
+	}
+
+	tests := []struct {
+		expr     string
+		expected error
+	}{
+		{expr: "value / zero", expected: errDivisionByZero},
+		{expr: "value % zero", expected: errDivisionByZero},
+		{expr: "value + missing", expected: errMissingField},
+		{expr: "value + name", expected: errNotNumeric},
+		{expr: "sqrt(-value)", expected: errInvalidArgument},
+		{expr: "log(zero)", expected: errInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			a, err := parseAssignment("result = " + tt.expr)
+			require.NoError(t, err)
+			_, err = a.expr.eval(fields)
+			require.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	plugin := &Math{
+		Expressions: []string{
+			"used_percent = used / total * 100",
+			"overloaded = load1 > 2 * n_cpus ? 1 : 0",
+			"free_percent = 100 - used_percent",
+			"ratio = used / reserved",
+			"missing = used + unknown",
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New(
+			"mem",
+			map[string]string{"host": "server01"},
+			map[string]interface{}{"used": int64(4096), "total": int64(16384), "reserved": int64(0), "load1": 5.0, "n_cpus": int64(2)},
+			time.Unix(0, 0),
+		),
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"mem",
+			map[string]string{"host": "server01"},
+			map[string]interface{}{
+				"used":         int64(4096),
+				"total":        int64(16384),
+				"reserved":     int64(0),
+				"load1":        5.0,
+				"n_cpus":       int64(2),
+				"used_percent": 25.0,
+				"overloaded":   1.0,
+				"free_percent": 75.0,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
+
+func TestApplyDivisionByZero(t *testing.T) {
+	plugin := &Math{
+		Expressions:    []string{"ratio = used / reserved", "big = 10 ^ 400"},
+		DivisionByZero: "zero",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := metric.New("mem", map[string]string{}, map[string]interface{}{"used": int64(1), "reserved": int64(0)}, time.Unix(0, 0))
+	expected := metric.New(
+		"mem",
+		map[string]string{},
+		map[string]interface{}{"used": int64(1), "reserved": int64(0), "ratio": 0.0},
+		time.Unix(0, 0),
+	)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, plugin.Apply(input))
+}
+
+func TestTracking(t *testing.T) {
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) {
+		delivered++
+	}
+
+	plugin := &Math{
+		Expressions: []string{"double = value * 2"},
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := metric.New("test", map[string]string{}, map[string]interface{}{"value": 21}, time.Unix(0, 0))
+	input, _ := metric.WithTracking(m, notify)
+	for _, m := range plugin.Apply(input) {
+		m.Accept()
+	}
+	require.Equal(t, 1, delivered)
+}
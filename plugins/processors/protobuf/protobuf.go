@@ -0,0 +1,256 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package protobuf
+
+import (
+	"context"
+	_ "embed"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Protobuf struct {
+	Field       string          `toml:"field"`
+	Encoding    string          `toml:"encoding"`
+	Files       []string        `toml:"protobuf_files"`
+	MessageType string          `toml:"protobuf_type"`
+	ImportPaths []string        `toml:"protobuf_import_paths"`
+	SkipBytes   int64           `toml:"skip_bytes"`
+	Fields      []string        `toml:"fields"`
+	Tags        []string        `toml:"tags"`
+	RemoveField bool            `toml:"remove_field"`
+	Log         telegraf.Logger `toml:"-"`
+
+	msg          *dynamicpb.Message
+	unmarshaller proto.UnmarshalOptions
+	fieldFilter  filter.Filter
+	tagFilter    filter.Filter
+}
+
+func (*Protobuf) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Protobuf) Init() error {
+	if p.Field == "" {
+		return errors.New("field not set")
+	}
+
+	switch p.Encoding {
+	case "":
+		p.Encoding = "binary"
+	case "binary", "base64", "hex":
+	default:
+		return fmt.Errorf("unknown encoding %q", p.Encoding)
+	}
+
+	if len(p.Files) == 0 {
+		return errors.New("protocol-buffer files not set")
+	}
+	if p.MessageType == "" {
+		return errors.New("protocol-buffer message-type not set")
+	}
+	if p.SkipBytes < 0 {
+		return errors.New("skip_bytes must not be negative")
+	}
+
+	// Load the file descriptors from the given protocol-buffer definition
+	resolver := &protocompile.SourceResolver{ImportPaths: p.ImportPaths}
+	compiler := &protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(resolver),
+	}
+	files, err := compiler.Compile(context.Background(), p.Files...)
+	if err != nil {
+		return fmt.Errorf("parsing protocol-buffer definition failed: %w", err)
+	}
+
+	var registry protoregistry.Files
+	for _, f := range files {
+		if err := registry.RegisterFile(f); err != nil {
+			return fmt.Errorf("adding file %q to registry failed: %w", f.Path(), err)
+		}
+	}
+	p.unmarshaller = proto.UnmarshalOptions{
+		RecursionLimit: protowire.DefaultRecursionLimit,
+		Resolver:       dynamicpb.NewTypes(&registry),
+	}
+
+	// Lookup the message type in the loaded file descriptors
+	name := protoreflect.FullName(p.MessageType)
+	descriptor, err := registry.FindDescriptorByName(name)
+	if err != nil {
+		return fmt.Errorf("looking up message type %q failed: %w", name, err)
+	}
+	msgDesc, ok := descriptor.(protoreflect.MessageDescriptor)
+	if !ok {
+		return fmt.Errorf("%q is not a message descriptor (%T)", name, descriptor)
+	}
+	p.msg = dynamicpb.NewMessage(msgDesc)
+
+	if p.fieldFilter, err = filter.Compile(p.Fields); err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	if p.tagFilter, err = filter.Compile(p.Tags); err != nil {
+		return fmt.Errorf("creating tag filter failed: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Protobuf) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range metrics {
+		raw, found := m.GetField(p.Field)
+		if !found {
+			continue
+		}
+
+		msg, err := p.decode(raw)
+		if err != nil {
+			p.Log.Errorf("Decoding field %q of metric %q failed: %v", p.Field, m.Name(), err)
+			continue
+		}
+
+		if p.RemoveField {
+			m.RemoveField(p.Field)
+		}
+		walk("", msg, func(path string, fd protoreflect.FieldDescriptor, v protoreflect.Value) {
+			switch {
+			case p.tagFilter != nil && p.tagFilter.Match(path):
+				m.AddTag(path, toString(convert(fd, v)))
+			case p.fieldFilter != nil && p.fieldFilter.Match(path):
+				m.AddField(path, convert(fd, v))
+			}
+		})
+	}
+	return metrics
+}
+
+func (p *Protobuf) decode(raw interface{}) (protoreflect.Message, error) {
+	var buf []byte
+	switch v := raw.(type) {
+	case []byte:
+		buf = v
+	case string:
+		buf = []byte(v)
+	default:
+		return nil, fmt.Errorf("unsupported field type %T", raw)
+	}
+
+	switch p.Encoding {
+	case "base64":
+		decoded, err := base64.StdEncoding.DecodeString(string(buf))
+		if err != nil {
+			return nil, fmt.Errorf("decoding base64 failed: %w", err)
+		}
+		buf = decoded
+	case "hex":
+		decoded, err := hex.DecodeString(string(buf))
+		if err != nil {
+			return nil, fmt.Errorf("decoding hex failed: %w", err)
+		}
+		buf = decoded
+	}
+
+	if int64(len(buf)) < p.SkipBytes {
+		return nil, fmt.Errorf("message too short to skip %d bytes", p.SkipBytes)
+	}
+
+	msg := p.msg.New()
+	if err := p.unmarshaller.Unmarshal(buf[p.SkipBytes:], msg.Interface()); err != nil {
+		p.Log.Debugf("raw data (hex): %q (skip %d bytes)", hex.EncodeToString(buf), p.SkipBytes)
+		return nil, err
+	}
+	return msg, nil
+}
+
+// walk calls the given function for all scalar values set in the message
+// using the dotted path of the value
+func walk(prefix string, msg protoreflect.Message, fn func(string, protoreflect.FieldDescriptor, protoreflect.Value)) {
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		path := prefix + string(fd.Name())
+		switch {
+		case fd.IsList():
+			list := v.List()
+			for i := range list.Len() {
+				walkValue(path+"."+strconv.Itoa(i), fd, list.Get(i), fn)
+			}
+		case fd.IsMap():
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				walkValue(path+"."+k.String(), fd.MapValue(), mv, fn)
+				return true
+			})
+		default:
+			walkValue(path, fd, v, fn)
+		}
+		return true
+	})
+}
+
+func walkValue(path string, fd protoreflect.FieldDescriptor, v protoreflect.Value, fn func(string, protoreflect.FieldDescriptor, protoreflect.Value)) {
+	if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+		walk(path+".", v.Message(), fn)
+		return
+	}
+	fn(path, fd, v)
+}
+
+func convert(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return v.Bool()
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int64(v.Enum())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return v.Int()
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return v.Uint()
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return v.Float()
+	case protoreflect.BytesKind:
+		return hex.EncodeToString(v.Bytes())
+	}
+	return v.String()
+}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func init() {
+	processors.Add("protobuf", func() telegraf.Processor {
+		return &Protobuf{Fields: []string{"*"}}
+	})
+}
@@ -0,0 +1,259 @@
+package protobuf
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const reading = `{
+	"deviceId": "sensor-17",
+	"temperature": 21.5,
+	"humidity": 48,
+	"location": {"building": "B2", "floor": "3"},
+	"samples": [4, 8],
+	"status": "OK",
+	"labels": {"site": "fra1"},
+	"ok": true,
+	"raw": "AQI="
+}`
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Protobuf
+		expected string
+	}{
+		{
+			name:     "no field",
+			plugin:   &Protobuf{},
+			expected: "field not set",
+		},
+		{
+			name:     "invalid encoding",
+			plugin:   &Protobuf{Field: "value", Encoding: "gzip"},
+			expected: `unknown encoding "gzip"`,
+		},
+		{
+			name:     "no files",
+			plugin:   &Protobuf{Field: "value"},
+			expected: "protocol-buffer files not set",
+		},
+		{
+			name:     "no message type",
+			plugin:   &Protobuf{Field: "value", Files: []string{"reading.proto"}},
+			expected: "protocol-buffer message-type not set",
+		},
+		{
+			name: "unknown message type",
+			plugin: &Protobuf{
+				Field:       "value",
+				Files:       []string{"reading.proto"},
+				ImportPaths: []string{"testdata"},
+				MessageType: "telegraf.test.Unknown",
+			},
+			expected: `looking up message type "telegraf.test.Unknown" failed`,
+		},
+		{
+			name: "enum instead of message",
+			plugin: &Protobuf{
+				Field:       "value",
+				Files:       []string{"reading.proto"},
+				ImportPaths: []string{"testdata"},
+				MessageType: "telegraf.test.Status",
+			},
+			expected: "is not a message descriptor",
+		},
+		{
+			name: "missing file",
+			plugin: &Protobuf{
+				Field:       "value",
+				Files:       []string{"missing.proto"},
+				ImportPaths: []string{"testdata"},
+				MessageType: "telegraf.test.Reading",
+			},
+			expected: "parsing protocol-buffer definition failed",
+		},
+		{
+			name: "invalid field filter",
+			plugin: &Protobuf{
+				Field:       "value",
+				Files:       []string{"reading.proto"},
+				ImportPaths: []string{"testdata"},
+				MessageType: "telegraf.test.Reading",
+				Fields:      []string{"a[b"},
+			},
+			expected: "creating field filter failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestAllFields(t *testing.T) {
+	plugin := newPlugin()
+	plugin.RemoveField = true
+	require.NoError(t, plugin.Init())
+
+	input := metric.New(
+		"kafka_consumer",
+		map[string]string{"topic": "readings"},
+		map[string]interface{}{"value": serialize(t, plugin, reading)},
+		time.Unix(0, 0),
+	)
+	expected := []telegraf.Metric{
+		metric.New(
+			"kafka_consumer",
+			map[string]string{"topic": "readings"},
+			map[string]interface{}{
+				"device_id":         "sensor-17",
+				"temperature":       21.5,
+				"humidity":          int64(48),
+				"location.building": "B2",
+				"location.floor":    int64(3),
+				"samples.0":         uint64(4),
+				"samples.1":         uint64(8),
+				"status":            "OK",
+				"labels.site":       "fra1",
+				"ok":                true,
+				"raw":               "0102",
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := plugin.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestSelectedFieldsAndTags(t *testing.T) {
+	plugin := newPlugin()
+	plugin.Encoding = "base64"
+	plugin.Fields = []string{"temperature", "humidity"}
+	plugin.Tags = []string{"device_id", "location.*"}
+	plugin.RemoveField = true
+	require.NoError(t, plugin.Init())
+
+	payload := base64.StdEncoding.EncodeToString(serialize(t, plugin, reading))
+	input := metric.New(
+		"kafka_consumer",
+		map[string]string{},
+		map[string]interface{}{"value": payload, "offset": int64(42)},
+		time.Unix(0, 0),
+	)
+	expected := []telegraf.Metric{
+		metric.New(
+			"kafka_consumer",
+			map[string]string{
+				"device_id":         "sensor-17",
+				"location.building": "B2",
+				"location.floor":    "3",
+			},
+			map[string]interface{}{
+				"offset":      int64(42),
+				"temperature": 21.5,
+				"humidity":    int64(48),
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := plugin.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestSkipBytes(t *testing.T) {
+	plugin := newPlugin()
+	plugin.SkipBytes = 5
+	plugin.Fields = []string{"device_id"}
+	require.NoError(t, plugin.Init())
+
+	// Prepend a framing header like the one used by schema registries
+	payload := append([]byte{0, 0, 0, 0, 1}, serialize(t, plugin, reading)...)
+	input := metric.New("kafka_consumer", map[string]string{}, map[string]interface{}{"value": payload}, time.Unix(0, 0))
+	expected := []telegraf.Metric{
+		metric.New(
+			"kafka_consumer",
+			map[string]string{},
+			map[string]interface{}{"value": payload, "device_id": "sensor-17"},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := plugin.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestInvalidMessagePassesUnchanged(t *testing.T) {
+	plugin := newPlugin()
+	plugin.Encoding = "hex"
+	plugin.RemoveField = true
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": "not hex"}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": "ffff"}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(23)}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"other": "0a01"}, time.Unix(0, 0)),
+	}
+	expected := make([]telegraf.Metric, 0, len(input))
+	for _, m := range input {
+		expected = append(expected, m.Copy())
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestTracking(t *testing.T) {
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) {
+		delivered++
+	}
+
+	plugin := newPlugin()
+	require.NoError(t, plugin.Init())
+
+	m := metric.New("test", map[string]string{}, map[string]interface{}{"value": serialize(t, plugin, reading)}, time.Unix(0, 0))
+	input, _ := metric.WithTracking(m, notify)
+
+	actual := plugin.Apply(input)
+	require.Len(t, actual, 1)
+	for _, m := range actual {
+		m.Accept()
+	}
+	require.Equal(t, 1, delivered)
+}
+
+func newPlugin() *Protobuf {
+	return &Protobuf{
+		Field:       "value",
+		Files:       []string{"reading.proto"},
+		ImportPaths: []string{"testdata"},
+		MessageType: "telegraf.test.Reading",
+		Fields:      []string{"*"},
+		Log:         testutil.Logger{},
+	}
+}
+
+func serialize(t *testing.T, plugin *Protobuf, content string) []byte {
+	t.Helper()
+
+	msg := plugin.msg.New()
+	require.NoError(t, protojson.Unmarshal([]byte(content), msg.Interface()))
+	buf, err := proto.Marshal(msg.Interface())
+	require.NoError(t, err)
+	return buf
+}
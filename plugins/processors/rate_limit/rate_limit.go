@@ -0,0 +1,282 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package rate_limit
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type RateLimit struct {
+	Rate       float64         `toml:"rate"`
+	Burst      int             `toml:"burst"`
+	Strategy   string          `toml:"strategy"`
+	Period     config.Duration `toml:"period"`
+	SeriesTags []string        `toml:"series_tags"`
+	Log        telegraf.Logger `toml:"-"`
+
+	allowance int
+	seed      maphash.Seed
+	series    map[uint64]*series
+	lastClean time.Time
+	now       func() time.Time
+
+	acc    telegraf.Accumulator
+	mu     sync.Mutex
+	cancel chan struct{}
+	wg     sync.WaitGroup
+}
+
+// series holds the limiter state of a single series
+type series struct {
+	// State of the token bucket for the "drop" strategy
+	tokens float64
+	last   time.Time
+
+	// State of the window for the "head" and "tail" strategies
+	start   time.Time
+	count   int
+	dropped int
+	held    []telegraf.Metric
+}
+
+func (*RateLimit) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *RateLimit) Init() error {
+	if r.Rate <= 0 {
+		return errors.New("rate must be positive")
+	}
+
+	switch r.Strategy {
+	case "":
+		r.Strategy = "drop"
+	case "drop", "head", "tail":
+	default:
+		return fmt.Errorf("invalid strategy %q", r.Strategy)
+	}
+
+	if r.Burst < 0 {
+		return errors.New("burst must not be negative")
+	}
+	if r.Burst == 0 {
+		r.Burst = int(math.Max(1, math.Ceil(r.Rate)))
+	}
+
+	if r.Period <= 0 {
+		return errors.New("period must be positive")
+	}
+	r.allowance = int(math.Max(1, math.Floor(r.Rate*time.Duration(r.Period).Seconds())))
+
+	r.seed = maphash.MakeSeed()
+	r.series = make(map[uint64]*series)
+	if r.now == nil {
+		r.now = time.Now
+	}
+	r.lastClean = r.now()
+
+	return nil
+}
+
+func (r *RateLimit) Start(acc telegraf.Accumulator) error {
+	r.acc = acc
+	r.cancel = make(chan struct{})
+
+	// Release the metrics held by the "tail" strategy even if no new metrics
+	// arrive for the series
+	if r.Strategy == "tail" {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+
+			ticker := time.NewTicker(time.Duration(r.Period))
+			defer ticker.Stop()
+			for {
+				select {
+				case <-r.cancel:
+					return
+				case <-ticker.C:
+					r.release(false)
+				}
+			}
+		}()
+	}
+
+	return nil
+}
+
+func (r *RateLimit) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	r.mu.Lock()
+	out := r.apply(r.now(), m)
+	r.mu.Unlock()
+
+	for _, m := range out {
+		acc.AddMetric(m)
+	}
+	return nil
+}
+
+func (r *RateLimit) Stop() {
+	close(r.cancel)
+	r.wg.Wait()
+
+	// Pass on all held metrics independent of their period
+	r.release(true)
+}
+
+// apply limits the given metric and returns the metrics to pass on
+func (r *RateLimit) apply(now time.Time, m telegraf.Metric) []telegraf.Metric {
+	defer r.cleanup(now)
+
+	id := r.seriesID(m)
+	s, found := r.series[id]
+	if !found {
+		s = &series{tokens: float64(r.Burst), last: now, start: now}
+		r.series[id] = s
+	}
+
+	switch r.Strategy {
+	case "drop":
+		// Refill the bucket according to the time passed
+		s.tokens = math.Min(float64(r.Burst), s.tokens+now.Sub(s.last).Seconds()*r.Rate)
+		s.last = now
+		if s.tokens < 1 {
+			m.Drop()
+			return nil
+		}
+		s.tokens--
+		return []telegraf.Metric{m}
+	case "head":
+		r.advance(s, now)
+		s.count++
+		if s.count > r.allowance {
+			m.Drop()
+			return nil
+		}
+		return []telegraf.Metric{m}
+	}
+
+	// Release the metrics of the expired period before starting a new one
+	var out []telegraf.Metric
+	if now.Sub(s.start) >= time.Duration(r.Period) {
+		out = r.take(s)
+		s.start = now
+	}
+	// Holding tracking metrics could deadlock inputs waiting for delivery,
+	// so treat them as delivered and keep an untracked copy similar to
+	// aggregators.
+	m.Accept()
+	if len(s.held) >= r.allowance {
+		s.held = s.held[1:]
+		s.dropped++
+	}
+	s.held = append(s.held, metric.New(m.Name(), m.Tags(), m.Fields(), m.Time(), m.Type()))
+	return out
+}
+
+// advance starts a new window for the series if the current one expired
+func (r *RateLimit) advance(s *series, now time.Time) {
+	if now.Sub(s.start) < time.Duration(r.Period) {
+		return
+	}
+	if s.count > r.allowance {
+		r.Log.Debugf("Dropped %d metric(s) exceeding the limit", s.count-r.allowance)
+	}
+	s.start = now
+	s.count = 0
+}
+
+// release passes on the metrics held for all series with an expired window
+// or for all series if requested
+func (r *RateLimit) release(all bool) {
+	r.mu.Lock()
+	now := r.now()
+	var out []telegraf.Metric
+	for _, s := range r.series {
+		if all || now.Sub(s.start) >= time.Duration(r.Period) {
+			out = append(out, r.take(s)...)
+		}
+	}
+	r.cleanup(now)
+	r.mu.Unlock()
+
+	for _, m := range out {
+		r.acc.AddMetric(m)
+	}
+}
+
+// take removes and returns the metrics held for the series
+func (r *RateLimit) take(s *series) []telegraf.Metric {
+	if s.dropped > 0 {
+		r.Log.Debugf("Dropped %d metric(s) exceeding the limit", s.dropped)
+	}
+	held := s.held
+	s.held = nil
+	s.dropped = 0
+	return held
+}
+
+// cleanup removes series without state worth keeping to bound memory usage
+func (r *RateLimit) cleanup(now time.Time) {
+	if now.Sub(r.lastClean) < time.Duration(r.Period) {
+		return
+	}
+	r.lastClean = now
+
+	for id, s := range r.series {
+		var idle bool
+		switch r.Strategy {
+		case "drop":
+			// A full bucket is equivalent to a new series
+			idle = s.tokens+now.Sub(s.last).Seconds()*r.Rate >= float64(r.Burst)
+		default:
+			idle = len(s.held) == 0 && now.Sub(s.start) >= time.Duration(r.Period)
+		}
+		if idle {
+			delete(r.series, id)
+		}
+	}
+}
+
+// seriesID computes the key of the series the metric belongs to using either
+// all tags or only the configured ones
+func (r *RateLimit) seriesID(m telegraf.Metric) uint64 {
+	if len(r.SeriesTags) == 0 {
+		return m.HashID()
+	}
+
+	var h maphash.Hash
+	h.SetSeed(r.seed)
+	h.WriteString(m.Name())
+	h.WriteByte(0)
+	for _, key := range r.SeriesTags {
+		h.WriteString(key)
+		h.WriteByte(0)
+		if v, found := m.GetTag(key); found {
+			h.WriteString(v)
+		}
+		h.WriteByte(0)
+	}
+	return h.Sum64()
+}
+
+func init() {
+	processors.AddStreaming("rate_limit", func() telegraf.StreamingProcessor {
+		return &RateLimit{
+			Period: config.Duration(time.Second),
+		}
+	})
+}
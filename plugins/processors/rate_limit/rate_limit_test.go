@@ -0,0 +1,252 @@
+package rate_limit
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *RateLimit
+		expected string
+	}{
+		{
+			name:     "no rate",
+			plugin:   &RateLimit{Period: config.Duration(time.Second)},
+			expected: "rate must be positive",
+		},
+		{
+			name:     "invalid strategy",
+			plugin:   &RateLimit{Rate: 1, Strategy: "random", Period: config.Duration(time.Second)},
+			expected: `invalid strategy "random"`,
+		},
+		{
+			name:     "negative burst",
+			plugin:   &RateLimit{Rate: 1, Burst: -1, Period: config.Duration(time.Second)},
+			expected: "burst must not be negative",
+		},
+		{
+			name:     "no period",
+			plugin:   &RateLimit{Rate: 1},
+			expected: "period must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestDrop(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	plugin := &RateLimit{
+		Rate:   2,
+		Period: config.Duration(time.Second),
+		Log:    testutil.Logger{},
+		now:    clock.Now,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// The burst allows two metrics per series immediately
+	for _, m := range []telegraf.Metric{newMetric("a", 1), newMetric("a", 2), newMetric("a", 3), newMetric("b", 1)} {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		newMetric("a", 1), newMetric("a", 2), newMetric("b", 1),
+	}, acc.GetTelegrafMetrics())
+
+	// After half a second one token is refilled
+	acc.ClearMetrics()
+	clock.advance(500 * time.Millisecond)
+	require.NoError(t, plugin.Add(newMetric("a", 4), &acc))
+	require.NoError(t, plugin.Add(newMetric("a", 5), &acc))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{newMetric("a", 4)}, acc.GetTelegrafMetrics())
+}
+
+func TestHead(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	plugin := &RateLimit{
+		Rate:     1,
+		Strategy: "head",
+		Period:   config.Duration(2 * time.Second),
+		Log:      testutil.Logger{},
+		now:      clock.Now,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	for _, m := range []telegraf.Metric{newMetric("a", 1), newMetric("a", 2), newMetric("a", 3)} {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{newMetric("a", 1), newMetric("a", 2)}, acc.GetTelegrafMetrics())
+
+	acc.ClearMetrics()
+	clock.advance(time.Second)
+	require.NoError(t, plugin.Add(newMetric("a", 4), &acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	clock.advance(time.Second)
+	require.NoError(t, plugin.Add(newMetric("a", 5), &acc))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{newMetric("a", 5)}, acc.GetTelegrafMetrics())
+}
+
+func TestTail(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	plugin := &RateLimit{
+		Rate:     2,
+		Strategy: "tail",
+		Period:   config.Duration(time.Second),
+		Log:      testutil.Logger{},
+		now:      clock.Now,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+
+	// Metrics are held back until the period expired
+	for _, m := range []telegraf.Metric{newMetric("a", 1), newMetric("a", 2), newMetric("a", 3), newMetric("b", 1)} {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// A new metric of the series releases the held metrics of the series
+	clock.advance(time.Second)
+	require.NoError(t, plugin.Add(newMetric("a", 4), &acc))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{newMetric("a", 2), newMetric("a", 3)}, acc.GetTelegrafMetrics())
+
+	// Series without new metrics are released periodically
+	acc.ClearMetrics()
+	plugin.release(false)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{newMetric("b", 1)}, acc.GetTelegrafMetrics())
+
+	// Stopping releases the metrics of the current period
+	acc.ClearMetrics()
+	plugin.Stop()
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{newMetric("a", 4)}, acc.GetTelegrafMetrics())
+}
+
+func TestTailWithoutNewMetrics(t *testing.T) {
+	plugin := &RateLimit{
+		Rate:     10,
+		Strategy: "tail",
+		Period:   config.Duration(100 * time.Millisecond),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.NoError(t, plugin.Add(newMetric("a", 1), &acc))
+	require.NoError(t, plugin.Add(newMetric("b", 1), &acc))
+
+	// The held metrics must be released without further metrics arriving
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSeriesTags(t *testing.T) {
+	plugin := &RateLimit{
+		Rate:       1,
+		Period:     config.Duration(time.Second),
+		SeriesTags: []string{"host"},
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	input := []telegraf.Metric{
+		metric.New("m", map[string]string{"host": "a", "cpu": "0"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("m", map[string]string{"host": "a", "cpu": "1"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		metric.New("m", map[string]string{"host": "b", "cpu": "0"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+	}
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{input[0], input[2]}, acc.GetTelegrafMetrics())
+}
+
+func TestTracking(t *testing.T) {
+	for _, strategy := range []string{"drop", "head", "tail"} {
+		t.Run(strategy, func(t *testing.T) {
+			clock := &fakeClock{now: time.Unix(1700000000, 0)}
+			plugin := &RateLimit{
+				Rate:     1,
+				Strategy: strategy,
+				Period:   config.Duration(time.Second),
+				Log:      testutil.Logger{},
+				now:      clock.Now,
+			}
+			require.NoError(t, plugin.Init())
+
+			var delivered atomic.Int32
+			notify := func(telegraf.DeliveryInfo) { delivered.Add(1) }
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Start(&acc))
+			for i := range 3 {
+				m, _ := metric.WithTracking(newMetric("a", i), notify)
+				require.NoError(t, plugin.Add(m, &acc))
+			}
+			plugin.Stop()
+
+			actual := acc.GetTelegrafMetrics()
+			require.Len(t, actual, 1)
+			for _, m := range actual {
+				m.Accept()
+			}
+			require.Equal(t, int32(3), delivered.Load())
+		})
+	}
+}
+
+type fakeClock struct {
+	now time.Time
+	sync.Mutex
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newMetric(tag string, value int) telegraf.Metric {
+	return metric.New(
+		"test",
+		map[string]string{"series": tag},
+		map[string]interface{}{"value": value},
+		time.Unix(0, 0),
+	)
+}
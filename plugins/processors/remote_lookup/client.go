@@ -0,0 +1,125 @@
+package remote_lookup
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+type httpClient struct {
+	url     string
+	headers map[string]*config.Secret
+	client  *http.Client
+}
+
+func (c *httpClient) lookup(ctx context.Context, key string) (map[string]interface{}, error) {
+	address := strings.ReplaceAll(c.url, "{key}", url.PathEscape(key))
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	for k, v := range c.headers {
+		secret, err := v.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting header %q failed: %w", k, err)
+		}
+		request.Header.Set(k, secret.String())
+		secret.Destroy()
+	}
+
+	resp, err := c.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, fmt.Errorf("received status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var values map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&values); err != nil {
+		return nil, fmt.Errorf("decoding response failed: %w", err)
+	}
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+
+	return values, nil
+}
+
+func (c *httpClient) close() {
+	c.client.CloseIdleConnections()
+}
+
+// grpcClient calls a unary method taking and returning a struct message to
+// avoid the need for a dedicated service definition
+type grpcClient struct {
+	method  string
+	headers map[string]*config.Secret
+	conn    *grpc.ClientConn
+}
+
+func newGRPCClient(address, method string, headers map[string]*config.Secret, tlsCfg *tls.Config) (*grpcClient, error) {
+	creds := insecure.NewCredentials()
+	if tlsCfg != nil {
+		creds = credentials.NewTLS(tlsCfg)
+	}
+
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+
+	return &grpcClient{method: method, headers: headers, conn: conn}, nil
+}
+
+func (c *grpcClient) lookup(ctx context.Context, key string) (map[string]interface{}, error) {
+	for k, v := range c.headers {
+		secret, err := v.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting header %q failed: %w", k, err)
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, strings.ToLower(k), secret.String())
+		secret.Destroy()
+	}
+
+	request, err := structpb.NewStruct(map[string]interface{}{"key": key})
+	if err != nil {
+		return nil, err
+	}
+	var response structpb.Struct
+	if err := c.conn.Invoke(ctx, c.method, request, &response); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return response.AsMap(), nil
+}
+
+func (c *grpcClient) close() {
+	c.conn.Close()
+}
@@ -0,0 +1,228 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package remote_lookup
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/common/parallel"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const defaultMaxOrderedQueueSize = 10_000
+
+type RemoteLookup struct {
+	URL                string                    `toml:"url"`
+	GRPCMethod         string                    `toml:"grpc_method"`
+	KeyTag             string                    `toml:"key_tag"`
+	Tags               []string                  `toml:"tags"`
+	Headers            map[string]*config.Secret `toml:"headers"`
+	CacheTTL           config.Duration           `toml:"cache_ttl"`
+	NegativeCacheTTL   config.Duration           `toml:"negative_cache_ttl"`
+	CacheSize          int                       `toml:"cache_size"`
+	MaxParallelLookups int                       `toml:"max_parallel_lookups"`
+	Ordered            bool                      `toml:"ordered"`
+	Log                telegraf.Logger           `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client   lookupClient
+	cache    *expirable.LRU[string, map[string]string]
+	negative *expirable.LRU[string, struct{}]
+	inflight singleflight.Group
+	parallel parallel.Parallel
+}
+
+// lookupClient queries the lookup service for a key and returns the values
+// of the response or nil if the key is not known to the service
+type lookupClient interface {
+	lookup(ctx context.Context, key string) (map[string]interface{}, error)
+	close()
+}
+
+func (*RemoteLookup) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *RemoteLookup) Init() error {
+	if r.URL == "" {
+		return errors.New("url required")
+	}
+	if r.KeyTag == "" {
+		return errors.New("key_tag required")
+	}
+	if r.CacheSize < 1 {
+		return errors.New("cache_size must be positive")
+	}
+	if r.MaxParallelLookups < 1 {
+		return errors.New("max_parallel_lookups must be positive")
+	}
+
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return fmt.Errorf("parsing url failed: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		client, err := r.HTTPClientConfig.CreateClient(context.Background(), r.Log)
+		if err != nil {
+			return fmt.Errorf("creating HTTP client failed: %w", err)
+		}
+		r.client = &httpClient{url: r.URL, headers: r.Headers, client: client}
+	case "grpc":
+		if r.GRPCMethod == "" {
+			return errors.New("grpc_method required for gRPC lookups")
+		}
+		tlsCfg, err := r.HTTPClientConfig.ClientConfig.TLSConfig()
+		if err != nil {
+			return fmt.Errorf("creating TLS config failed: %w", err)
+		}
+		client, err := newGRPCClient(u.Host, r.GRPCMethod, r.Headers, tlsCfg)
+		if err != nil {
+			return fmt.Errorf("creating gRPC client failed: %w", err)
+		}
+		r.client = client
+	default:
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	if r.CacheTTL > 0 {
+		r.cache = expirable.NewLRU[string, map[string]string](r.CacheSize, nil, time.Duration(r.CacheTTL))
+	}
+	if r.NegativeCacheTTL > 0 {
+		r.negative = expirable.NewLRU[string, struct{}](r.CacheSize, nil, time.Duration(r.NegativeCacheTTL))
+	}
+
+	return nil
+}
+
+func (r *RemoteLookup) Start(acc telegraf.Accumulator) error {
+	if r.Ordered {
+		r.parallel = parallel.NewOrdered(acc, r.asyncAdd, defaultMaxOrderedQueueSize, r.MaxParallelLookups)
+	} else {
+		r.parallel = parallel.NewUnordered(acc, r.asyncAdd, r.MaxParallelLookups)
+	}
+	return nil
+}
+
+func (r *RemoteLookup) Add(metric telegraf.Metric, _ telegraf.Accumulator) error {
+	r.parallel.Enqueue(metric)
+	return nil
+}
+
+func (r *RemoteLookup) Stop() {
+	if r.parallel != nil {
+		r.parallel.Stop()
+	}
+	if r.client != nil {
+		r.client.close()
+	}
+}
+
+func (r *RemoteLookup) asyncAdd(metric telegraf.Metric) []telegraf.Metric {
+	key, found := metric.GetTag(r.KeyTag)
+	if !found {
+		return []telegraf.Metric{metric}
+	}
+
+	tags, err := r.lookup(key)
+	if err != nil {
+		r.Log.Errorf("Looking up %q failed: %v", key, err)
+		return []telegraf.Metric{metric}
+	}
+	for k, v := range tags {
+		metric.AddTag(k, v)
+	}
+
+	return []telegraf.Metric{metric}
+}
+
+// lookup returns the tags for the given key either from the cache or by
+// querying the service. Concurrent lookups of the same key are only sent once.
+func (r *RemoteLookup) lookup(key string) (map[string]string, error) {
+	if r.cache != nil {
+		if tags, found := r.cache.Get(key); found {
+			return tags, nil
+		}
+	}
+	if r.negative != nil && r.negative.Contains(key) {
+		return nil, nil
+	}
+
+	result, err, _ := r.inflight.Do(key, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(r.Timeout))
+		defer cancel()
+
+		values, err := r.client.lookup(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if values == nil {
+			if r.negative != nil {
+				r.negative.Add(key, struct{}{})
+			}
+			return map[string]string(nil), nil
+		}
+
+		tags := r.convertTags(values)
+		if r.cache != nil {
+			r.cache.Add(key, tags)
+		}
+		return tags, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(map[string]string), nil
+}
+
+// convertTags converts the scalar values of the response to tags, only
+// keeping the configured keys
+func (r *RemoteLookup) convertTags(values map[string]interface{}) map[string]string {
+	tags := make(map[string]string, len(values))
+	for k, raw := range values {
+		if len(r.Tags) > 0 && !slices.Contains(r.Tags, k) {
+			continue
+		}
+		switch v := raw.(type) {
+		case string:
+			tags[k] = v
+		case float64:
+			tags[k] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			tags[k] = strconv.FormatBool(v)
+		default:
+			r.Log.Tracef("Ignoring key %q with non-scalar value of type %T", k, raw)
+		}
+	}
+	return tags
+}
+
+func init() {
+	processors.AddStreaming("remote_lookup", func() telegraf.StreamingProcessor {
+		return &RemoteLookup{
+			CacheTTL:           config.Duration(time.Hour),
+			NegativeCacheTTL:   config.Duration(5 * time.Minute),
+			CacheSize:          10000,
+			MaxParallelLookups: 10,
+			HTTPClientConfig: common_http.HTTPClientConfig{
+				Timeout: config.Duration(5 * time.Second),
+			},
+		}
+	})
+}
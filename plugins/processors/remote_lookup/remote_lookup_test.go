@@ -0,0 +1,250 @@
+package remote_lookup
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *RemoteLookup
+		expected string
+	}{
+		{
+			name:     "missing url",
+			plugin:   &RemoteLookup{KeyTag: "host"},
+			expected: "url required",
+		},
+		{
+			name:     "missing key tag",
+			plugin:   &RemoteLookup{URL: "http://localhost"},
+			expected: "key_tag required",
+		},
+		{
+			name:     "unsupported scheme",
+			plugin:   &RemoteLookup{URL: "ftp://localhost", KeyTag: "host"},
+			expected: `unsupported scheme "ftp"`,
+		},
+		{
+			name:     "missing grpc method",
+			plugin:   &RemoteLookup{URL: "grpc://localhost:50051", KeyTag: "host"},
+			expected: "grpc_method required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.CacheSize = 100
+			tt.plugin.MaxParallelLookups = 1
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestHTTPLookup(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/lookup/web01":
+			_, _ = w.Write([]byte(`{"datacenter": "fra1", "team": "infra", "rack": 12, "maintenance": false, "labels": {"a": "b"}}`))
+		case "/lookup/unknown":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	plugin := newTestPlugin(server.URL + "/lookup/{key}")
+	plugin.Headers = map[string]*config.Secret{"Authorization": secret("Bearer token")}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "web01"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "unknown"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "broken"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 4}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{"host": "web01"}, map[string]interface{}{"value": 5}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{"host": "unknown"}, map[string]interface{}{"value": 6}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{"host": "broken"}, map[string]interface{}{"value": 7}, time.Unix(0, 0)),
+	}
+
+	enriched := map[string]string{"host": "web01", "datacenter": "fra1", "team": "infra", "rack": "12", "maintenance": "false"}
+	expected := []telegraf.Metric{
+		metric.New("cpu", enriched, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "unknown"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "broken"}, map[string]interface{}{"value": 3}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 4}, time.Unix(0, 0)),
+		metric.New("mem", enriched, map[string]interface{}{"value": 5}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{"host": "unknown"}, map[string]interface{}{"value": 6}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{"host": "broken"}, map[string]interface{}{"value": 7}, time.Unix(0, 0)),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	plugin.Stop()
+
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Found and not-found keys are served from the cache while errors are
+	// retried on the next lookup
+	require.Equal(t, map[string]int{"/lookup/web01": 1, "/lookup/unknown": 1, "/lookup/broken": 2}, requests)
+}
+
+func TestHTTPLookupTagSelection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"datacenter": "fra1", "team": "infra"}`))
+	}))
+	defer server.Close()
+
+	plugin := newTestPlugin(server.URL + "/{key}")
+	plugin.Tags = []string{"team"}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Add(metric.New("cpu", map[string]string{"host": "web01"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)), &acc))
+	plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "web01", "team": "infra"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestCacheExpiry(t *testing.T) {
+	var mu sync.Mutex
+	var count int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		count++
+		mu.Unlock()
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	plugin := newTestPlugin(server.URL + "/{key}")
+	plugin.NegativeCacheTTL = config.Duration(50 * time.Millisecond)
+	require.NoError(t, plugin.Init())
+
+	_, err := plugin.lookup("web01")
+	require.NoError(t, err)
+	_, err = plugin.lookup("web01")
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	require.Eventually(t, func() bool {
+		_, err := plugin.lookup("web01")
+		require.NoError(t, err)
+		mu.Lock()
+		defer mu.Unlock()
+		return count == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestGRPCLookup(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var methods []string
+	server := grpc.NewServer(grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		methods = append(methods, method)
+
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		if v := md.Get("authorization"); len(v) != 1 || v[0] != "Bearer token" {
+			return status.Error(codes.Unauthenticated, "invalid token")
+		}
+
+		var request structpb.Struct
+		if err := stream.RecvMsg(&request); err != nil {
+			return err
+		}
+		if request.Fields["key"].GetStringValue() != "web01" {
+			return status.Error(codes.NotFound, "unknown host")
+		}
+		response, err := structpb.NewStruct(map[string]interface{}{"datacenter": "fra1", "team": "infra"})
+		if err != nil {
+			return err
+		}
+		return stream.SendMsg(response)
+	}))
+	go server.Serve(listener) //nolint:errcheck // Ignore the returned error as the tests will fail anyway
+	defer server.Stop()
+
+	plugin := newTestPlugin("grpc://" + listener.Addr().String())
+	plugin.GRPCMethod = "/telegraf.lookup.v1.Lookup/Lookup"
+	plugin.Headers = map[string]*config.Secret{"Authorization": secret("Bearer token")}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "web01"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "db01"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+	}
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "web01", "datacenter": "fra1", "team": "infra"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "db01"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	plugin.Stop()
+
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+	require.ElementsMatch(t, []string{"/telegraf.lookup.v1.Lookup/Lookup", "/telegraf.lookup.v1.Lookup/Lookup"}, methods)
+}
+
+func newTestPlugin(address string) *RemoteLookup {
+	return &RemoteLookup{
+		URL:                address,
+		KeyTag:             "host",
+		CacheTTL:           config.Duration(time.Hour),
+		NegativeCacheTTL:   config.Duration(time.Hour),
+		CacheSize:          100,
+		MaxParallelLookups: 1,
+		Ordered:            true,
+		HTTPClientConfig: common_http.HTTPClientConfig{
+			Timeout: config.Duration(5 * time.Second),
+		},
+		Log: testutil.Logger{},
+	}
+}
+
+func secret(value string) *config.Secret {
+	s := config.NewSecret([]byte(value))
+	return &s
+}
@@ -0,0 +1,119 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+)
+
+type fieldDefinition struct {
+	Key      string   `toml:"key"`
+	Required bool     `toml:"required"`
+	Type     string   `toml:"type"`
+	Min      *float64 `toml:"min"`
+	Max      *float64 `toml:"max"`
+}
+
+type rule struct {
+	Name         []string          `toml:"name"`
+	RequiredTags []string          `toml:"required_tags"`
+	Fields       []fieldDefinition `toml:"field"`
+
+	nameFilter filter.Filter
+}
+
+func (r *rule) init() error {
+	var err error
+	r.nameFilter, err = filter.Compile(r.Name)
+	if err != nil {
+		return fmt.Errorf("creating name filter failed: %w", err)
+	}
+
+	for i, f := range r.Fields {
+		if f.Key == "" {
+			return fmt.Errorf("field definition %d: empty key", i+1)
+		}
+		switch f.Type {
+		case "", "float", "integer", "unsigned", "string", "boolean", "number":
+		default:
+			return fmt.Errorf("field definition %q: invalid type %q", f.Key, f.Type)
+		}
+		if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+			return fmt.Errorf("field definition %q: minimum larger than maximum", f.Key)
+		}
+		if (f.Min != nil || f.Max != nil) && (f.Type == "string" || f.Type == "boolean") {
+			return fmt.Errorf("field definition %q: range not supported for type %q", f.Key, f.Type)
+		}
+	}
+
+	return nil
+}
+
+func (r *rule) matches(m telegraf.Metric) bool {
+	return r.nameFilter == nil || r.nameFilter.Match(m.Name())
+}
+
+// validate returns a short reason for the first violation found or an empty
+// string if the metric complies with the rule.
+func (r *rule) validate(m telegraf.Metric) string {
+	for _, key := range r.RequiredTags {
+		if !m.HasTag(key) {
+			return "missing_tag:" + key
+		}
+	}
+
+	for _, f := range r.Fields {
+		value, found := m.GetField(f.Key)
+		if !found {
+			if f.Required {
+				return "missing_field:" + f.Key
+			}
+			continue
+		}
+		if f.Type != "" && !matchesType(value, f.Type) {
+			return "invalid_type:" + f.Key
+		}
+		if f.Min == nil && f.Max == nil {
+			continue
+		}
+		v, err := toFloat(value)
+		if err != nil {
+			return "invalid_type:" + f.Key
+		}
+		if (f.Min != nil && v < *f.Min) || (f.Max != nil && v > *f.Max) {
+			return "out_of_range:" + f.Key
+		}
+	}
+
+	return ""
+}
+
+func matchesType(value interface{}, expected string) bool {
+	switch value.(type) {
+	case float64:
+		return expected == "float" || expected == "number"
+	case int64:
+		return expected == "integer" || expected == "number"
+	case uint64:
+		return expected == "unsigned" || expected == "number"
+	case string:
+		return expected == "string"
+	case bool:
+		return expected == "boolean"
+	}
+	return false
+}
+
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	}
+	return 0, errors.New("not a numeric value")
+}
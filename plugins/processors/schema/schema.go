@@ -0,0 +1,99 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package schema
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Schema struct {
+	Action                string          `toml:"action"`
+	ViolationTag          string          `toml:"violation_tag"`
+	DeadLetterMeasurement string          `toml:"dead_letter_measurement"`
+	Rules                 []rule          `toml:"rule"`
+	Log                   telegraf.Logger `toml:"-"`
+}
+
+func (*Schema) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Schema) Init() error {
+	switch s.Action {
+	case "":
+		s.Action = "drop"
+	case "drop", "tag":
+	case "route":
+		if s.DeadLetterMeasurement == "" {
+			return fmt.Errorf("%q action requires a dead-letter measurement", s.Action)
+		}
+	default:
+		return fmt.Errorf("invalid action %q", s.Action)
+	}
+
+	if s.ViolationTag == "" && s.Action != "drop" {
+		return fmt.Errorf("%q action requires a violation tag", s.Action)
+	}
+
+	if len(s.Rules) == 0 {
+		s.Log.Warn("no rules provided, passing all metrics")
+	}
+	for i := range s.Rules {
+		if err := s.Rules[i].init(); err != nil {
+			return fmt.Errorf("initialization of rule %d failed: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		reason := s.validate(m)
+		if reason == "" {
+			out = append(out, m)
+			continue
+		}
+
+		switch s.Action {
+		case "drop":
+			s.Log.Debugf("dropping metric %q violating schema: %s", m.Name(), reason)
+			m.Drop()
+			continue
+		case "route":
+			m.SetName(s.DeadLetterMeasurement)
+		}
+		m.AddTag(s.ViolationTag, reason)
+		out = append(out, m)
+	}
+	return out
+}
+
+func (s *Schema) validate(m telegraf.Metric) string {
+	for _, r := range s.Rules {
+		if !r.matches(m) {
+			continue
+		}
+		if reason := r.validate(m); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+func init() {
+	processors.Add("schema", func() telegraf.Processor {
+		return &Schema{
+			Action:                "drop",
+			ViolationTag:          "schema_violation",
+			DeadLetterMeasurement: "schema_violations",
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Schema
+		expected string
+	}{
+		{
+			name:     "invalid action",
+			plugin:   &Schema{Action: "foo"},
+			expected: `invalid action "foo"`,
+		},
+		{
+			name:     "route without measurement",
+			plugin:   &Schema{Action: "route", ViolationTag: "violation"},
+			expected: "requires a dead-letter measurement",
+		},
+		{
+			name:     "tag without tag key",
+			plugin:   &Schema{Action: "tag"},
+			expected: "requires a violation tag",
+		},
+		{
+			name: "invalid type",
+			plugin: &Schema{
+				Rules: []rule{{Fields: []fieldDefinition{{Key: "value", Type: "complex"}}}},
+			},
+			expected: `invalid type "complex"`,
+		},
+		{
+			name: "invalid range",
+			plugin: &Schema{
+				Rules: []rule{{Fields: []fieldDefinition{{Key: "value", Min: floatPtr(10), Max: floatPtr(1)}}}},
+			},
+			expected: "minimum larger than maximum",
+		},
+		{
+			name: "range for string",
+			plugin: &Schema{
+				Rules: []rule{{Fields: []fieldDefinition{{Key: "value", Type: "string", Min: floatPtr(0)}}}},
+			},
+			expected: "range not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestActions(t *testing.T) {
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage_idle": 42.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"usage_idle": 142.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage_idle": 12.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "c"}, map[string]interface{}{"usage_idle": int64(12)}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "d"}, map[string]interface{}{"usage_user": 12.0}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"free": int64(12)}, time.Unix(0, 0)),
+	}
+
+	rules := []rule{
+		{
+			Name:         []string{"cpu"},
+			RequiredTags: []string{"host"},
+			Fields: []fieldDefinition{
+				{Key: "usage_idle", Required: true, Type: "float", Min: floatPtr(0), Max: floatPtr(100)},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		action   string
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "drop",
+			action: "drop",
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage_idle": 42.0}, time.Unix(0, 0)),
+				metric.New("mem", map[string]string{}, map[string]interface{}{"free": int64(12)}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name:   "tag",
+			action: "tag",
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage_idle": 42.0}, time.Unix(0, 0)),
+				metric.New("cpu",
+					map[string]string{"host": "b", "schema_violation": "out_of_range:usage_idle"},
+					map[string]interface{}{"usage_idle": 142.0},
+					time.Unix(0, 0),
+				),
+				metric.New("cpu",
+					map[string]string{"schema_violation": "missing_tag:host"},
+					map[string]interface{}{"usage_idle": 12.0},
+					time.Unix(0, 0),
+				),
+				metric.New("cpu",
+					map[string]string{"host": "c", "schema_violation": "invalid_type:usage_idle"},
+					map[string]interface{}{"usage_idle": int64(12)},
+					time.Unix(0, 0),
+				),
+				metric.New("cpu",
+					map[string]string{"host": "d", "schema_violation": "missing_field:usage_idle"},
+					map[string]interface{}{"usage_user": 12.0},
+					time.Unix(0, 0),
+				),
+				metric.New("mem", map[string]string{}, map[string]interface{}{"free": int64(12)}, time.Unix(0, 0)),
+			},
+		},
+		{
+			name:   "route",
+			action: "route",
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage_idle": 42.0}, time.Unix(0, 0)),
+				metric.New("schema_violations",
+					map[string]string{"host": "b", "schema_violation": "out_of_range:usage_idle"},
+					map[string]interface{}{"usage_idle": 142.0},
+					time.Unix(0, 0),
+				),
+				metric.New("schema_violations",
+					map[string]string{"schema_violation": "missing_tag:host"},
+					map[string]interface{}{"usage_idle": 12.0},
+					time.Unix(0, 0),
+				),
+				metric.New("schema_violations",
+					map[string]string{"host": "c", "schema_violation": "invalid_type:usage_idle"},
+					map[string]interface{}{"usage_idle": int64(12)},
+					time.Unix(0, 0),
+				),
+				metric.New("schema_violations",
+					map[string]string{"host": "d", "schema_violation": "missing_field:usage_idle"},
+					map[string]interface{}{"usage_user": 12.0},
+					time.Unix(0, 0),
+				),
+				metric.New("mem", map[string]string{}, map[string]interface{}{"free": int64(12)}, time.Unix(0, 0)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Schema{
+				Action:                tt.action,
+				ViolationTag:          "schema_violation",
+				DeadLetterMeasurement: "schema_violations",
+				Rules:                 rules,
+				Log:                   testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			in := make([]telegraf.Metric, 0, len(input))
+			for _, m := range input {
+				in = append(in, m.Copy())
+			}
+			actual := plugin.Apply(in...)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestNumberType(t *testing.T) {
+	plugin := &Schema{
+		Rules: []rule{
+			{Fields: []fieldDefinition{{Key: "value", Type: "number", Max: floatPtr(10)}}},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(2)}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": uint64(3)}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": uint64(30)}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": "4"}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"other": true}, time.Unix(0, 0)),
+	}
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": int64(2)}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": uint64(3)}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"other": true}, time.Unix(0, 0)),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
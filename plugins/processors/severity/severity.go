@@ -0,0 +1,200 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package severity
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Scores of the severity levels with higher values being more severe
+var levels = map[string]int{
+	"debug":     0,
+	"info":      1,
+	"notice":    2,
+	"warning":   3,
+	"error":     4,
+	"critical":  5,
+	"alert":     6,
+	"emergency": 7,
+}
+
+// Built-in rules ordered from the most to the least severe level so messages
+// containing multiple keywords get the highest severity
+var builtinRules = []*rule{
+	{Level: "emergency", Keywords: []string{"emerg", "emergency"}},
+	{Level: "alert", Keywords: []string{"alert"}},
+	{Level: "critical", Keywords: []string{"crit", "critical", "fatal", "panic"}},
+	{Level: "error", Keywords: []string{"err", "error", "exception", "failed", "failure"}},
+	{Level: "warning", Keywords: []string{"warn", "warning"}},
+	{Level: "notice", Keywords: []string{"notice"}},
+	{Level: "info", Keywords: []string{"info", "information"}},
+	{Level: "debug", Keywords: []string{"debug", "trace"}},
+}
+
+type Severity struct {
+	Field        string          `toml:"field"`
+	Tag          string          `toml:"tag"`
+	ScoreField   string          `toml:"score_field"`
+	Default      string          `toml:"default"`
+	BuiltinRules bool            `toml:"builtin_rules"`
+	Rules        []*rule         `toml:"rule"`
+	Log          telegraf.Logger `toml:"-"`
+
+	rules []*rule
+}
+
+type rule struct {
+	Level    string   `toml:"level"`
+	Keywords []string `toml:"keywords"`
+	Pattern  string   `toml:"pattern"`
+	Field    string   `toml:"field"`
+	Min      *float64 `toml:"min"`
+	Max      *float64 `toml:"max"`
+
+	re *regexp.Regexp
+}
+
+func (*Severity) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Severity) Init() error {
+	if s.Field == "" {
+		return errors.New("field required")
+	}
+	if s.Tag == "" && s.ScoreField == "" {
+		return errors.New("either tag or score_field required")
+	}
+	if s.Default != "" {
+		if _, found := levels[s.Default]; !found {
+			return fmt.Errorf("invalid default level %q", s.Default)
+		}
+	}
+
+	s.rules = make([]*rule, 0, len(s.Rules)+len(builtinRules))
+	for i, r := range s.Rules {
+		if err := r.init(); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		s.rules = append(s.rules, r)
+	}
+	if s.BuiltinRules {
+		for _, r := range builtinRules {
+			builtin := *r
+			if err := builtin.init(); err != nil {
+				return fmt.Errorf("built-in rule for %q: %w", r.Level, err)
+			}
+			s.rules = append(s.rules, &builtin)
+		}
+	}
+
+	return nil
+}
+
+func (s *Severity) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		level := s.Default
+		for _, r := range s.rules {
+			field := s.Field
+			if r.Field != "" {
+				field = r.Field
+			}
+			value, found := m.GetField(field)
+			if !found {
+				continue
+			}
+			if r.matches(value) {
+				level = r.Level
+				break
+			}
+		}
+		if level == "" {
+			continue
+		}
+
+		if s.Tag != "" {
+			m.AddTag(s.Tag, level)
+		}
+		if s.ScoreField != "" {
+			m.AddField(s.ScoreField, int64(levels[level]))
+		}
+	}
+	return in
+}
+
+func (r *rule) init() error {
+	if _, found := levels[r.Level]; !found {
+		return fmt.Errorf("invalid level %q", r.Level)
+	}
+
+	var kinds int
+	if len(r.Keywords) > 0 {
+		kinds++
+	}
+	if r.Pattern != "" {
+		kinds++
+	}
+	if r.Min != nil || r.Max != nil {
+		kinds++
+	}
+	if kinds != 1 {
+		return errors.New("exactly one of 'keywords', 'pattern' or 'min'/'max' required")
+	}
+
+	switch {
+	case len(r.Keywords) > 0:
+		quoted := make([]string, 0, len(r.Keywords))
+		for _, k := range r.Keywords {
+			quoted = append(quoted, regexp.QuoteMeta(k))
+		}
+		r.re = regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+	case r.Pattern != "":
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("compiling pattern failed: %w", err)
+		}
+		r.re = re
+	}
+
+	return nil
+}
+
+func (r *rule) matches(value interface{}) bool {
+	if r.re != nil {
+		v, ok := value.(string)
+		return ok && r.re.MatchString(v)
+	}
+
+	v, err := internal.ToFloat64(value)
+	if err != nil {
+		return false
+	}
+	if r.Min != nil && v < *r.Min {
+		return false
+	}
+	if r.Max != nil && v > *r.Max {
+		return false
+	}
+	return true
+}
+
+func init() {
+	processors.Add("severity", func() telegraf.Processor {
+		return &Severity{
+			Field:        "message",
+			Tag:          "severity",
+			ScoreField:   "severity_score",
+			BuiltinRules: true,
+		}
+	})
+}
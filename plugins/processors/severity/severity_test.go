@@ -0,0 +1,195 @@
+package severity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	minimum := 500.0
+	tests := []struct {
+		name     string
+		plugin   *Severity
+		expected string
+	}{
+		{
+			name:     "no field",
+			plugin:   &Severity{Tag: "severity"},
+			expected: "field required",
+		},
+		{
+			name:     "no output",
+			plugin:   &Severity{Field: "message"},
+			expected: "either tag or score_field required",
+		},
+		{
+			name:     "invalid default",
+			plugin:   &Severity{Field: "message", Tag: "severity", Default: "fine"},
+			expected: `invalid default level "fine"`,
+		},
+		{
+			name: "invalid rule level",
+			plugin: &Severity{
+				Field: "message",
+				Tag:   "severity",
+				Rules: []*rule{{Level: "bad", Keywords: []string{"oops"}}},
+			},
+			expected: `rule 1: invalid level "bad"`,
+		},
+		{
+			name: "rule without condition",
+			plugin: &Severity{
+				Field: "message",
+				Tag:   "severity",
+				Rules: []*rule{{Level: "error"}},
+			},
+			expected: "exactly one of",
+		},
+		{
+			name: "rule with multiple conditions",
+			plugin: &Severity{
+				Field: "message",
+				Tag:   "severity",
+				Rules: []*rule{{Level: "error", Pattern: "oops", Min: &minimum}},
+			},
+			expected: "exactly one of",
+		},
+		{
+			name: "invalid pattern",
+			plugin: &Severity{
+				Field: "message",
+				Tag:   "severity",
+				Rules: []*rule{{Level: "error", Pattern: "oops("}},
+			},
+			expected: "compiling pattern failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestBuiltinRules(t *testing.T) {
+	plugin := &Severity{
+		Field:        "message",
+		Tag:          "severity",
+		ScoreField:   "severity_score",
+		BuiltinRules: true,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	tests := []struct {
+		message string
+		level   string
+		score   int64
+	}{
+		{"FATAL: cannot bind to port 80", "critical", 5},
+		{"[ERROR] connection refused", "error", 4},
+		{"Warning: disk almost full, error threshold near", "error", 4},
+		{"level=warn msg=\"slow query\"", "warning", 3},
+		{"2024-01-01 INFO started server", "info", 1},
+		{"debug: entering loop", "debug", 0},
+		{"panic: runtime error: index out of range", "critical", 5},
+		{"terrorist attacks in the game", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			m := metric.New("log", map[string]string{}, map[string]interface{}{"message": tt.message}, time.Unix(0, 0))
+			expected := metric.New("log", map[string]string{}, map[string]interface{}{"message": tt.message}, time.Unix(0, 0))
+			if tt.level != "" {
+				expected.AddTag("severity", tt.level)
+				expected.AddField("severity_score", tt.score)
+			}
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, plugin.Apply(m))
+		})
+	}
+}
+
+func TestCustomRules(t *testing.T) {
+	minimum, maximum := 500.0, 599.0
+	warning := 400.0
+	plugin := &Severity{
+		Field:        "message",
+		Tag:          "level",
+		ScoreField:   "score",
+		Default:      "notice",
+		BuiltinRules: true,
+		Rules: []*rule{
+			{Level: "alert", Keywords: []string{"out of memory"}},
+			{Level: "warning", Pattern: `took \d{4,}ms`},
+			{Level: "error", Field: "status", Min: &minimum, Max: &maximum},
+			{Level: "warning", Field: "status", Min: &warning},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("log", map[string]string{}, map[string]interface{}{"message": "Error: Out of Memory"}, time.Unix(0, 0)),
+		metric.New("log", map[string]string{}, map[string]interface{}{"message": "request took 12000ms"}, time.Unix(0, 0)),
+		metric.New("log", map[string]string{}, map[string]interface{}{"message": "GET /", "status": int64(503)}, time.Unix(0, 0)),
+		metric.New("log", map[string]string{}, map[string]interface{}{"message": "GET /", "status": "404"}, time.Unix(0, 0)),
+		metric.New("log", map[string]string{}, map[string]interface{}{"message": "GET /", "status": int64(200)}, time.Unix(0, 0)),
+		metric.New("log", map[string]string{}, map[string]interface{}{"message": "failed to GET /", "status": int64(200)}, time.Unix(0, 0)),
+		metric.New("log", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"log",
+			map[string]string{"level": "alert"},
+			map[string]interface{}{"message": "Error: Out of Memory", "score": int64(6)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"log",
+			map[string]string{"level": "warning"},
+			map[string]interface{}{"message": "request took 12000ms", "score": int64(3)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"log",
+			map[string]string{"level": "error"},
+			map[string]interface{}{"message": "GET /", "status": int64(503), "score": int64(4)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"log",
+			map[string]string{"level": "warning"},
+			map[string]interface{}{"message": "GET /", "status": "404", "score": int64(3)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"log",
+			map[string]string{"level": "notice"},
+			map[string]interface{}{"message": "GET /", "status": int64(200), "score": int64(2)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"log",
+			map[string]string{"level": "error"},
+			map[string]interface{}{"message": "failed to GET /", "status": int64(200), "score": int64(4)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"log",
+			map[string]string{"level": "notice"},
+			map[string]interface{}{"value": 42, "score": int64(2)},
+			time.Unix(0, 0),
+		),
+	}
+
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
@@ -0,0 +1,240 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package sql_lookup
+
+import (
+	"context"
+	gosql "database/sql"
+	_ "embed"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Aliases of the database types supported by the drivers
+var aliases = map[string]string{
+	"cockroach": "pgx",
+	"tidb":      "mysql",
+	"mssql":     "sqlserver",
+	"maria":     "mysql",
+	"postgres":  "pgx",
+}
+
+type SQLLookup struct {
+	Driver          string          `toml:"driver"`
+	Dsn             config.Secret   `toml:"dsn"`
+	Query           string          `toml:"query"`
+	KeyColumn       string          `toml:"key_column"`
+	KeyTag          string          `toml:"key_tag"`
+	TagColumns      []string        `toml:"tag_columns"`
+	RefreshInterval config.Duration `toml:"refresh_interval"`
+	Timeout         config.Duration `toml:"timeout"`
+	Log             telegraf.Logger `toml:"-"`
+
+	driverName string
+	db         *gosql.DB
+
+	mu    sync.RWMutex
+	table map[string][]telegraf.Tag
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (*SQLLookup) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SQLLookup) Init() error {
+	if s.Driver == "" {
+		return errors.New("missing SQL driver option")
+	}
+	if s.Dsn.Empty() {
+		return errors.New("missing data source name option")
+	}
+	if s.Query == "" {
+		return errors.New("missing query option")
+	}
+	if s.KeyColumn == "" {
+		return errors.New("missing key_column option")
+	}
+	if s.KeyTag == "" {
+		s.KeyTag = s.KeyColumn
+	}
+	if s.RefreshInterval < 0 {
+		return errors.New("refresh_interval must not be negative")
+	}
+
+	s.driverName = s.Driver
+	if driver, ok := aliases[s.Driver]; ok {
+		s.driverName = driver
+	}
+	available := gosql.Drivers()
+	if !slices.Contains(available, s.driverName) {
+		for alias, driver := range aliases {
+			if slices.Contains(available, driver) {
+				available = append(available, alias)
+			}
+		}
+		sort.Strings(available)
+		return fmt.Errorf("driver %q not supported use one of %v", s.Driver, available)
+	}
+
+	return nil
+}
+
+func (s *SQLLookup) Start(telegraf.Accumulator) error {
+	dsn, err := s.Dsn.Get()
+	if err != nil {
+		return fmt.Errorf("getting DSN failed: %w", err)
+	}
+	db, err := gosql.Open(s.driverName, dsn.String())
+	dsn.Destroy()
+	if err != nil {
+		return fmt.Errorf("opening database failed: %w", err)
+	}
+	s.db = db
+
+	// Metrics cannot be enriched without an initial table
+	if err := s.refresh(); err != nil {
+		s.db.Close()
+		return err
+	}
+
+	if s.RefreshInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.cancel = cancel
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			ticker := time.NewTicker(time.Duration(s.RefreshInterval))
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					// Keep the previous table on errors
+					if err := s.refresh(); err != nil {
+						s.Log.Errorf("Refreshing lookup table failed: %v", err)
+					}
+				}
+			}
+		}()
+	}
+
+	return nil
+}
+
+func (s *SQLLookup) Add(metric telegraf.Metric, acc telegraf.Accumulator) error {
+	if key, found := metric.GetTag(s.KeyTag); found {
+		s.mu.RLock()
+		tags := s.table[key]
+		s.mu.RUnlock()
+		for _, tag := range tags {
+			metric.AddTag(tag.Key, tag.Value)
+		}
+	}
+	acc.AddMetric(metric)
+	return nil
+}
+
+func (s *SQLLookup) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	if s.db != nil {
+		s.db.Close()
+	}
+}
+
+// refresh queries the lookup table and replaces the current table on success
+func (s *SQLLookup) refresh() error {
+	ctx := context.Background()
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(s.Timeout))
+		defer cancel()
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.Query)
+	if err != nil {
+		return fmt.Errorf("running query failed: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("getting columns failed: %w", err)
+	}
+	keyIdx := slices.Index(columns, s.KeyColumn)
+	if keyIdx < 0 {
+		return fmt.Errorf("key column %q not returned by query", s.KeyColumn)
+	}
+	for _, c := range s.TagColumns {
+		if !slices.Contains(columns, c) {
+			return fmt.Errorf("tag column %q not returned by query", c)
+		}
+	}
+
+	values := make([]gosql.NullString, len(columns))
+	ptrs := make([]interface{}, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+
+	table := make(map[string][]telegraf.Tag)
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return fmt.Errorf("scanning row failed: %w", err)
+		}
+		if !values[keyIdx].Valid {
+			continue
+		}
+		key := values[keyIdx].String
+
+		tags := make([]telegraf.Tag, 0, len(columns)-1)
+		for i, c := range columns {
+			if i == keyIdx || !values[i].Valid || values[i].String == "" {
+				continue
+			}
+			if len(s.TagColumns) > 0 && !slices.Contains(s.TagColumns, c) {
+				continue
+			}
+			tags = append(tags, telegraf.Tag{Key: c, Value: values[i].String})
+		}
+		if _, found := table[key]; found {
+			s.Log.Debugf("Duplicate key %q in lookup table, using the last row", key)
+		}
+		table[key] = tags
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("reading rows failed: %w", err)
+	}
+
+	s.mu.Lock()
+	s.table = table
+	s.mu.Unlock()
+	s.Log.Debugf("Loaded %d entries into lookup table", len(table))
+
+	return nil
+}
+
+func init() {
+	processors.AddStreaming("sql_lookup", func() telegraf.StreamingProcessor {
+		return &SQLLookup{
+			RefreshInterval: config.Duration(5 * time.Minute),
+			Timeout:         config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,210 @@
+//go:build !mips && !mipsle && !mips64 && !ppc64 && !riscv64 && !loong64 && !mips64le && !(windows && (386 || arm)) && !(freebsd && (386 || arm))
+
+package sql_lookup
+
+import (
+	gosql "database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func createDatabase(t *testing.T) (*gosql.DB, string) {
+	t.Helper()
+
+	dbfile := filepath.Join(t.TempDir(), "cmdb.db")
+	db, err := gosql.Open("sqlite", dbfile)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+		CREATE TABLE hosts (hostname TEXT, datacenter TEXT, rack TEXT, slot INTEGER, owner TEXT);
+		INSERT INTO hosts VALUES ('web01', 'fra1', 'r12', 4, 'web-team');
+		INSERT INTO hosts VALUES ('db01', 'fra1', 'r07', 12, NULL);
+	`)
+	require.NoError(t, err)
+
+	return db, dbfile
+}
+
+func TestLookup(t *testing.T) {
+	_, dbfile := createDatabase(t)
+
+	plugin := &SQLLookup{
+		Driver:     "sqlite",
+		Dsn:        config.NewSecret([]byte(dbfile)),
+		Query:      "SELECT hostname, datacenter, rack, slot, owner FROM hosts",
+		KeyColumn:  "hostname",
+		KeyTag:     "host",
+		TagColumns: []string{"datacenter", "rack", "owner"},
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "web01"}, map[string]interface{}{"usage_idle": 92.1}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "db01"}, map[string]interface{}{"usage_idle": 42.0}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{"host": "app01"}, map[string]interface{}{"usage_idle": 85.3}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage_idle": 12.0}, time.Unix(0, 0)),
+	}
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "web01", "datacenter": "fra1", "rack": "r12", "owner": "web-team"},
+			map[string]interface{}{"usage_idle": 92.1},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "db01", "datacenter": "fra1", "rack": "r07"},
+			map[string]interface{}{"usage_idle": 42.0},
+			time.Unix(0, 0),
+		),
+		metric.New("cpu", map[string]string{"host": "app01"}, map[string]interface{}{"usage_idle": 85.3}, time.Unix(0, 0)),
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"usage_idle": 12.0}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestLookupAllColumns(t *testing.T) {
+	_, dbfile := createDatabase(t)
+
+	plugin := &SQLLookup{
+		Driver:    "sqlite",
+		Dsn:       config.NewSecret([]byte(dbfile)),
+		Query:     "SELECT * FROM hosts",
+		KeyColumn: "hostname",
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	m := metric.New("cpu", map[string]string{"hostname": "web01"}, map[string]interface{}{"usage_idle": 92.1}, time.Unix(0, 0))
+	require.NoError(t, plugin.Add(m, &acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"hostname": "web01", "datacenter": "fra1", "rack": "r12", "slot": "4", "owner": "web-team"},
+			map[string]interface{}{"usage_idle": 92.1},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestRefresh(t *testing.T) {
+	db, dbfile := createDatabase(t)
+
+	plugin := &SQLLookup{
+		Driver:          "sqlite",
+		Dsn:             config.NewSecret([]byte(dbfile)),
+		Query:           "SELECT hostname, rack FROM hosts",
+		KeyColumn:       "hostname",
+		KeyTag:          "host",
+		RefreshInterval: config.Duration(50 * time.Millisecond),
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	_, err := db.Exec(`UPDATE hosts SET rack = 'r13' WHERE hostname = 'web01'`)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		m := metric.New("cpu", map[string]string{"host": "web01"}, map[string]interface{}{"usage_idle": 92.1}, time.Unix(0, 0))
+		require.NoError(t, plugin.Add(m, &acc))
+		rack, _ := m.GetTag("rack")
+		return rack == "r13"
+	}, 3*time.Second, 50*time.Millisecond)
+
+	// The previous table is kept if the refresh fails
+	_, err = db.Exec(`DROP TABLE hosts`)
+	require.NoError(t, err)
+	require.Error(t, plugin.refresh())
+
+	m := metric.New("cpu", map[string]string{"host": "web01"}, map[string]interface{}{"usage_idle": 92.1}, time.Unix(0, 0))
+	require.NoError(t, plugin.Add(m, &acc))
+	rack, _ := m.GetTag("rack")
+	require.Equal(t, "r13", rack)
+}
+
+func TestStartFailsWithoutTable(t *testing.T) {
+	_, dbfile := createDatabase(t)
+
+	plugin := &SQLLookup{
+		Driver:    "sqlite",
+		Dsn:       config.NewSecret([]byte(dbfile)),
+		Query:     "SELECT hostname, rack FROM hosts",
+		KeyColumn: "name",
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Start(&acc), `key column "name" not returned by query`)
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *SQLLookup
+		expected string
+	}{
+		{
+			name:     "no driver",
+			plugin:   &SQLLookup{},
+			expected: "missing SQL driver option",
+		},
+		{
+			name:     "no query",
+			plugin:   &SQLLookup{Driver: "sqlite", Dsn: config.NewSecret([]byte("test.db"))},
+			expected: "missing query option",
+		},
+		{
+			name: "no key column",
+			plugin: &SQLLookup{
+				Driver: "sqlite",
+				Dsn:    config.NewSecret([]byte("test.db")),
+				Query:  "SELECT * FROM hosts",
+			},
+			expected: "missing key_column option",
+		},
+		{
+			name: "unknown driver",
+			plugin: &SQLLookup{
+				Driver:    "foo",
+				Dsn:       config.NewSecret([]byte("test.db")),
+				Query:     "SELECT * FROM hosts",
+				KeyColumn: "hostname",
+			},
+			expected: `driver "foo" not supported`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,237 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package squelch
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Squelch struct {
+	Window        config.Duration `toml:"window"`
+	IncludeFields []string        `toml:"include_fields"`
+	ExcludeFields []string        `toml:"exclude_fields"`
+	CountField    string          `toml:"count_field"`
+	Log           telegraf.Logger `toml:"-"`
+
+	fields  filter.Filter
+	seed    maphash.Seed
+	entries map[uint64]*entry
+	now     func() time.Time
+
+	acc    telegraf.Accumulator
+	mu     sync.Mutex
+	cancel chan struct{}
+	wg     sync.WaitGroup
+}
+
+// entry holds the state of a squelched metric
+type entry struct {
+	start time.Time
+	count int64
+	last  telegraf.Metric
+}
+
+func (*Squelch) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Squelch) Init() error {
+	if s.Window <= 0 {
+		return errors.New("window must be positive")
+	}
+	if s.CountField == "" {
+		s.CountField = "squelched_count"
+	}
+
+	fields, err := filter.NewIncludeExcludeFilter(s.IncludeFields, s.ExcludeFields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+	s.fields = fields
+
+	s.seed = maphash.MakeSeed()
+	s.entries = make(map[uint64]*entry)
+	if s.now == nil {
+		s.now = time.Now
+	}
+
+	return nil
+}
+
+func (s *Squelch) Start(acc telegraf.Accumulator) error {
+	s.acc = acc
+	s.cancel = make(chan struct{})
+
+	// Emit the summaries even if no further metrics arrive
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(s.Window))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.cancel:
+				return
+			case <-ticker.C:
+				s.flush()
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (s *Squelch) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	id := s.id(m)
+
+	s.mu.Lock()
+	now := s.now()
+	var summary telegraf.Metric
+	e, found := s.entries[id]
+	if found && now.Sub(e.start) >= time.Duration(s.Window) {
+		summary, found = s.expire(id, e, now)
+	}
+	if found {
+		// Keep an untracked copy for the summary similar to aggregators
+		e.count++
+		e.last = metric.New(m.Name(), m.Tags(), m.Fields(), m.Time(), m.Type())
+	} else {
+		s.entries[id] = &entry{start: now}
+	}
+	s.mu.Unlock()
+
+	if summary != nil {
+		acc.AddMetric(summary)
+	}
+	if found {
+		m.Drop()
+	} else {
+		acc.AddMetric(m)
+	}
+	return nil
+}
+
+func (s *Squelch) Stop() {
+	close(s.cancel)
+	s.wg.Wait()
+
+	// Emit the summaries of the current windows
+	s.mu.Lock()
+	var out []telegraf.Metric
+	for _, e := range s.entries {
+		if e.count > 0 {
+			out = append(out, s.summarize(e))
+		}
+	}
+	s.entries = make(map[uint64]*entry)
+	s.mu.Unlock()
+
+	for _, m := range out {
+		s.acc.AddMetric(m)
+	}
+}
+
+// flush emits the summaries of all entries with an expired window
+func (s *Squelch) flush() {
+	s.mu.Lock()
+	now := s.now()
+	var out []telegraf.Metric
+	for id, e := range s.entries {
+		if now.Sub(e.start) < time.Duration(s.Window) {
+			continue
+		}
+		if summary, _ := s.expire(id, e, now); summary != nil {
+			out = append(out, summary)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, m := range out {
+		s.acc.AddMetric(m)
+	}
+}
+
+// expire ends the window of the given entry. Entries with squelched metrics
+// start a new window so a storm of identical metrics results in one summary
+// per window, all other entries are removed. The summary, if any, and
+// whether the entry was kept is returned.
+func (s *Squelch) expire(id uint64, e *entry, now time.Time) (telegraf.Metric, bool) {
+	if e.count == 0 {
+		delete(s.entries, id)
+		return nil, false
+	}
+
+	summary := s.summarize(e)
+	e.start = now
+	return summary, true
+}
+
+// summarize returns the last squelched metric with the number of
+// repetitions and resets the counter of the entry
+func (s *Squelch) summarize(e *entry) telegraf.Metric {
+	summary := e.last
+	summary.AddField(s.CountField, e.count)
+	s.Log.Tracef("Squelched %d repetition(s) of %q", e.count, summary.Name())
+
+	e.count = 0
+	e.last = nil
+	return summary
+}
+
+// id computes the key of a metric from the series and the values of the
+// compared fields
+func (s *Squelch) id(m telegraf.Metric) uint64 {
+	var h maphash.Hash
+	h.SetSeed(s.seed)
+
+	var buf [8]byte
+	id := m.HashID()
+	for i := range buf {
+		buf[i] = byte(id >> (8 * i))
+	}
+	h.Write(buf[:])
+
+	fields := m.FieldList()
+	keys := make([]string, 0, len(fields))
+	values := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		if s.fields != nil && !s.fields.Match(f.Key) {
+			continue
+		}
+		keys = append(keys, f.Key)
+		values[f.Key] = f.Value
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		h.WriteString(k)
+		h.WriteByte(0)
+		fmt.Fprintf(&h, "%T:%v", values[k], values[k])
+		h.WriteByte(0)
+	}
+
+	return h.Sum64()
+}
+
+func init() {
+	processors.AddStreaming("squelch", func() telegraf.StreamingProcessor {
+		return &Squelch{
+			Window:     config.Duration(time.Minute),
+			CountField: "squelched_count",
+		}
+	})
+}
@@ -0,0 +1,234 @@
+package squelch
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Squelch
+		expected string
+	}{
+		{
+			name:     "no window",
+			plugin:   &Squelch{},
+			expected: "window must be positive",
+		},
+		{
+			name:     "invalid field filter",
+			plugin:   &Squelch{Window: config.Duration(time.Second), IncludeFields: []string{"a[b"}},
+			expected: "creating field filter failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestSquelch(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	plugin := &Squelch{
+		Window: config.Duration(time.Minute),
+		Log:    testutil.Logger{},
+		now:    clock.Now,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Only the first of the identical metrics passes, other series and values
+	// are not affected
+	input := []telegraf.Metric{
+		newMetric("a", "link down", 1),
+		newMetric("a", "link down", 2),
+		newMetric("a", "link up", 3),
+		newMetric("b", "link down", 4),
+		newMetric("a", "link down", 5),
+	}
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		newMetric("a", "link down", 1),
+		newMetric("a", "link up", 3),
+		newMetric("b", "link down", 4),
+	}, acc.GetTelegrafMetrics())
+
+	// Repetitions within the window are squelched
+	acc.ClearMetrics()
+	clock.advance(30 * time.Second)
+	require.NoError(t, plugin.Add(newMetric("a", "link down", 6), &acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// After the window the summary is emitted with the last repetition and
+	// repetitions are squelched for another window
+	clock.advance(30 * time.Second)
+	require.NoError(t, plugin.Add(newMetric("a", "link down", 7), &acc))
+	summary := newMetric("a", "link down", 6)
+	summary.AddField("squelched_count", int64(3))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{summary}, acc.GetTelegrafMetrics())
+
+	// The repetition of the second window is summarized periodically
+	acc.ClearMetrics()
+	clock.advance(time.Minute)
+	plugin.flush()
+	summary = newMetric("a", "link down", 7)
+	summary.AddField("squelched_count", int64(1))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{summary}, acc.GetTelegrafMetrics())
+
+	// Without repetitions in the last window no summary is emitted and the
+	// next metric passes again
+	acc.ClearMetrics()
+	clock.advance(time.Minute)
+	plugin.flush()
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.NoError(t, plugin.Add(newMetric("a", "link down", 8), &acc))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{newMetric("a", "link down", 8)}, acc.GetTelegrafMetrics())
+	require.Len(t, plugin.entries, 1)
+}
+
+func TestSquelchFields(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	plugin := &Squelch{
+		Window:        config.Duration(time.Minute),
+		ExcludeFields: []string{"seq"},
+		CountField:    "repeated",
+		Log:           testutil.Logger{},
+		now:           clock.Now,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Metrics only differing in excluded fields are identical
+	first := newMetric("a", "link down", 1)
+	first.AddField("seq", 1)
+	second := newMetric("a", "link down", 2)
+	second.AddField("seq", 2)
+	require.NoError(t, plugin.Add(first.Copy(), &acc))
+	require.NoError(t, plugin.Add(second.Copy(), &acc))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{first}, acc.GetTelegrafMetrics())
+
+	acc.ClearMetrics()
+	clock.advance(time.Minute)
+	plugin.flush()
+	summary := second.Copy()
+	summary.AddField("repeated", int64(1))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{summary}, acc.GetTelegrafMetrics())
+}
+
+func TestSquelchStop(t *testing.T) {
+	plugin := &Squelch{
+		Window: config.Duration(time.Hour),
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Add(newMetric("a", "link down", 1), &acc))
+	require.NoError(t, plugin.Add(newMetric("a", "link down", 2), &acc))
+	require.NoError(t, plugin.Add(newMetric("b", "link down", 3), &acc))
+
+	// Pending summaries must be emitted on shutdown
+	plugin.Stop()
+	summary := newMetric("a", "link down", 2)
+	summary.AddField("squelched_count", int64(1))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{
+		newMetric("a", "link down", 1),
+		newMetric("b", "link down", 3),
+		summary,
+	}, acc.GetTelegrafMetrics())
+}
+
+func TestSquelchWithoutNewMetrics(t *testing.T) {
+	plugin := &Squelch{
+		Window: config.Duration(100 * time.Millisecond),
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.NoError(t, plugin.Add(newMetric("a", "link down", 1), &acc))
+	require.NoError(t, plugin.Add(newMetric("a", "link down", 2), &acc))
+
+	// The summary must be emitted without further metrics arriving
+	require.Eventually(t, func() bool {
+		return acc.NMetrics() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestTracking(t *testing.T) {
+	var delivered atomic.Int32
+	notify := func(telegraf.DeliveryInfo) {
+		delivered.Add(1)
+	}
+
+	plugin := &Squelch{
+		Window: config.Duration(time.Minute),
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	for range 3 {
+		m, _ := metric.WithTracking(newMetric("a", "link down", 1), notify)
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	actual := acc.GetTelegrafMetrics()
+	require.Len(t, actual, 1)
+	for _, m := range actual {
+		m.Accept()
+	}
+	require.Equal(t, int32(3), delivered.Load())
+}
+
+type fakeClock struct {
+	now time.Time
+	sync.Mutex
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newMetric(tag, message string, ts int64) telegraf.Metric {
+	return metric.New(
+		"event",
+		map[string]string{"device": tag},
+		map[string]interface{}{"message": message},
+		time.Unix(ts, 0),
+	)
+}
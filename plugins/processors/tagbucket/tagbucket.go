@@ -0,0 +1,205 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package tagbucket
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type TagBucket struct {
+	Tags             []string        `toml:"tags"`
+	TopN             int             `toml:"top_n"`
+	OtherValue       string          `toml:"other_value"`
+	MaxTrackedValues int             `toml:"max_tracked_values"`
+	DecayInterval    config.Duration `toml:"decay_interval"`
+	Warmup           int64           `toml:"warmup"`
+	Log              telegraf.Logger `toml:"-"`
+
+	counters  map[string]*counter
+	lastDecay time.Time
+}
+
+func (*TagBucket) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *TagBucket) Init() error {
+	if len(t.Tags) == 0 {
+		return errors.New("no tags specified")
+	}
+	if t.TopN < 1 {
+		return errors.New("'top_n' has to be larger than zero")
+	}
+	if t.MaxTrackedValues < t.TopN {
+		return fmt.Errorf("'max_tracked_values' (%d) has to be larger than or equal to 'top_n' (%d)", t.MaxTrackedValues, t.TopN)
+	}
+
+	t.counters = make(map[string]*counter, len(t.Tags))
+	for _, key := range t.Tags {
+		t.counters[key] = newCounter(t.MaxTrackedValues)
+	}
+	t.lastDecay = time.Now()
+
+	return nil
+}
+
+func (t *TagBucket) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	if t.DecayInterval > 0 && time.Since(t.lastDecay) >= time.Duration(t.DecayInterval) {
+		for _, c := range t.counters {
+			c.decay()
+		}
+		t.lastDecay = time.Now()
+	}
+
+	// Learn the frequencies of the current batch first to allow new heavy
+	// hitters to be kept immediately
+	for _, m := range in {
+		for key, c := range t.counters {
+			if value, found := m.GetTag(key); found {
+				c.add(value)
+			}
+		}
+	}
+
+	for key, c := range t.counters {
+		if c.observed < t.Warmup {
+			continue
+		}
+		keep := c.top(t.TopN)
+		for _, m := range in {
+			if value, found := m.GetTag(key); found {
+				if _, ok := keep[value]; !ok {
+					m.AddTag(key, t.OtherValue)
+				}
+			}
+		}
+	}
+
+	return in
+}
+
+type state struct {
+	Observed int64              `json:"observed"`
+	Counts   map[string]float64 `json:"counts"`
+}
+
+func (t *TagBucket) GetState() interface{} {
+	s := make(map[string]state, len(t.counters))
+	for key, c := range t.counters {
+		s[key] = state{Observed: c.observed, Counts: c.counts}
+	}
+	return s
+}
+
+func (t *TagBucket) SetState(s interface{}) error {
+	states, ok := s.(map[string]state)
+	if !ok {
+		return fmt.Errorf("invalid state type %T", s)
+	}
+	for key, st := range states {
+		// Ignore states of tags not configured anymore
+		if _, found := t.counters[key]; !found {
+			continue
+		}
+		if st.Counts == nil {
+			st.Counts = make(map[string]float64)
+		}
+		t.counters[key] = &counter{
+			capacity: t.MaxTrackedValues,
+			observed: st.Observed,
+			counts:   st.Counts,
+		}
+	}
+	return nil
+}
+
+// counter estimates the most frequent values using the space-saving
+// algorithm, i.e. when the capacity is exhausted the least frequent value is
+// replaced by the new value inheriting its count.
+type counter struct {
+	capacity int
+	observed int64
+	counts   map[string]float64
+}
+
+func newCounter(capacity int) *counter {
+	return &counter{
+		capacity: capacity,
+		counts:   make(map[string]float64, capacity),
+	}
+}
+
+func (c *counter) add(value string) {
+	c.observed++
+	if _, found := c.counts[value]; found || len(c.counts) < c.capacity {
+		c.counts[value]++
+		return
+	}
+
+	var minValue string
+	minCount := -1.0
+	for v, n := range c.counts {
+		if minCount < 0 || n < minCount {
+			minValue, minCount = v, n
+		}
+	}
+	delete(c.counts, minValue)
+	c.counts[value] = minCount + 1
+}
+
+func (c *counter) decay() {
+	for v, n := range c.counts {
+		n /= 2
+		if n < 0.5 {
+			delete(c.counts, v)
+			continue
+		}
+		c.counts[v] = n
+	}
+	c.observed /= 2
+}
+
+func (c *counter) top(n int) map[string]bool {
+	values := make([]string, 0, len(c.counts))
+	for v := range c.counts {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		ci, cj := c.counts[values[i]], c.counts[values[j]]
+		if ci == cj {
+			return values[i] < values[j]
+		}
+		return ci > cj
+	})
+	if len(values) > n {
+		values = values[:n]
+	}
+
+	keep := make(map[string]bool, len(values))
+	for _, v := range values {
+		keep[v] = true
+	}
+	return keep
+}
+
+func init() {
+	processors.Add("tagbucket", func() telegraf.Processor {
+		return &TagBucket{
+			TopN:             10,
+			OtherValue:       "other",
+			MaxTrackedValues: 1000,
+			DecayInterval:    config.Duration(time.Hour),
+			Warmup:           100,
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package tagbucket
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newRequest(path string) telegraf.Metric {
+	return metric.New(
+		"http",
+		map[string]string{"path": path, "method": "GET"},
+		map[string]interface{}{"duration": 1.0},
+		time.Unix(0, 0),
+	)
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *TagBucket
+		expected string
+	}{
+		{
+			name:     "no tags",
+			plugin:   &TagBucket{TopN: 1, MaxTrackedValues: 1},
+			expected: "no tags specified",
+		},
+		{
+			name:     "zero top_n",
+			plugin:   &TagBucket{Tags: []string{"path"}, MaxTrackedValues: 1},
+			expected: "'top_n' has to be larger than zero",
+		},
+		{
+			name:     "tracked values too small",
+			plugin:   &TagBucket{Tags: []string{"path"}, TopN: 10, MaxTrackedValues: 5},
+			expected: "'max_tracked_values' (5) has to be larger than or equal to 'top_n' (10)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestBucketing(t *testing.T) {
+	plugin := &TagBucket{
+		Tags:             []string{"path"},
+		TopN:             2,
+		OtherValue:       "other",
+		MaxTrackedValues: 10,
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		newRequest("/a"), newRequest("/a"), newRequest("/a"),
+		newRequest("/b"), newRequest("/b"),
+		newRequest("/c"),
+		newRequest("/d"),
+	}
+	expected := []telegraf.Metric{
+		newRequest("/a"), newRequest("/a"), newRequest("/a"),
+		newRequest("/b"), newRequest("/b"),
+		newRequest("other"),
+		newRequest("other"),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Make "/c" more frequent than "/b" so it replaces it in the top values
+	input = []telegraf.Metric{newRequest("/c"), newRequest("/c"), newRequest("/c"), newRequest("/b")}
+	expected = []telegraf.Metric{newRequest("/c"), newRequest("/c"), newRequest("/c"), newRequest("other")}
+
+	actual = plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestWarmup(t *testing.T) {
+	plugin := &TagBucket{
+		Tags:             []string{"path"},
+		TopN:             1,
+		OtherValue:       "other",
+		MaxTrackedValues: 10,
+		Warmup:           3,
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{newRequest("/a"), newRequest("/b")}
+	expected := []telegraf.Metric{newRequest("/a"), newRequest("/b")}
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	input = []telegraf.Metric{newRequest("/a"), newRequest("/b")}
+	expected = []telegraf.Metric{newRequest("/a"), newRequest("other")}
+	actual = plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestBoundedTracking(t *testing.T) {
+	c := newCounter(2)
+	c.add("a")
+	c.add("a")
+	c.add("b")
+	c.add("c")
+
+	// "c" replaces the least frequent value "b" and inherits its count
+	require.Equal(t, map[string]float64{"a": 2, "c": 2}, c.counts)
+	require.Equal(t, map[string]bool{"a": true}, c.top(1))
+}
+
+func TestDecay(t *testing.T) {
+	plugin := &TagBucket{
+		Tags:             []string{"path"},
+		TopN:             1,
+		OtherValue:       "other",
+		MaxTrackedValues: 10,
+		DecayInterval:    config.Duration(time.Hour),
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.Apply(newRequest("/a"), newRequest("/a"), newRequest("/a"), newRequest("/b"))
+	plugin.lastDecay = time.Now().Add(-2 * time.Hour)
+	plugin.Apply(newRequest("/a"))
+
+	// The counts are halved and values below a count of one half are removed
+	require.Equal(t, map[string]float64{"/a": 2.5, "/b": 0.5}, plugin.counters["path"].counts)
+}
+
+func TestState(t *testing.T) {
+	plugin := &TagBucket{
+		Tags:             []string{"path"},
+		TopN:             1,
+		OtherValue:       "other",
+		MaxTrackedValues: 10,
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.Apply(newRequest("/a"), newRequest("/a"), newRequest("/b"))
+
+	// Serialize and deserialize the state the same way the persister does
+	buf, err := json.Marshal(plugin.GetState())
+	require.NoError(t, err)
+	var s map[string]state
+	require.NoError(t, json.Unmarshal(buf, &s))
+
+	restored := &TagBucket{
+		Tags:             []string{"path"},
+		TopN:             1,
+		OtherValue:       "other",
+		MaxTrackedValues: 10,
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, restored.Init())
+	require.NoError(t, restored.SetState(s))
+
+	actual := restored.Apply(newRequest("/b"))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{newRequest("other")}, actual)
+}
@@ -0,0 +1,104 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package time_window
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type TimeWindow struct {
+	Past       config.Duration     `toml:"past"`
+	Future     config.Duration     `toml:"future"`
+	Action     string              `toml:"action"`
+	Statistics *selfstat.Collector `toml:"-"`
+	Log        telegraf.Logger     `toml:"-"`
+
+	dropped  selfstat.Stat
+	adjusted selfstat.Stat
+	now      func() time.Time
+}
+
+func (*TimeWindow) SampleConfig() string {
+	return sampleConfig
+}
+
+func (tw *TimeWindow) Init() error {
+	if tw.Past < 0 || tw.Future < 0 {
+		return errors.New("past and future must not be negative")
+	}
+	if tw.Past == 0 && tw.Future == 0 {
+		return errors.New("either past or future must be set")
+	}
+
+	switch tw.Action {
+	case "":
+		tw.Action = "drop"
+	case "drop", "now", "clamp":
+	default:
+		return fmt.Errorf("invalid action %q", tw.Action)
+	}
+
+	if tw.Statistics == nil {
+		tw.Statistics = selfstat.NewCollector(nil)
+	}
+	tw.dropped = tw.Statistics.Register("process", "metrics_dropped", nil)
+	tw.adjusted = tw.Statistics.Register("process", "metrics_adjusted", nil)
+
+	if tw.now == nil {
+		tw.now = time.Now
+	}
+
+	return nil
+}
+
+func (tw *TimeWindow) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := tw.now()
+
+	out := in[:0]
+	for _, m := range in {
+		ts := m.Time()
+
+		var boundary time.Time
+		switch {
+		case tw.Past > 0 && ts.Before(now.Add(-time.Duration(tw.Past))):
+			boundary = now.Add(-time.Duration(tw.Past))
+		case tw.Future > 0 && ts.After(now.Add(time.Duration(tw.Future))):
+			boundary = now.Add(time.Duration(tw.Future))
+		default:
+			out = append(out, m)
+			continue
+		}
+
+		switch tw.Action {
+		case "drop":
+			tw.Log.Tracef("Dropping metric %q with timestamp %v outside the window", m.Name(), ts)
+			tw.dropped.Incr(1)
+			m.Drop()
+			continue
+		case "now":
+			m.SetTime(now)
+		case "clamp":
+			m.SetTime(boundary)
+		}
+		tw.adjusted.Incr(1)
+		out = append(out, m)
+	}
+
+	return out
+}
+
+func init() {
+	processors.Add("time_window", func() telegraf.Processor {
+		return &TimeWindow{}
+	})
+}
@@ -0,0 +1,145 @@
+package time_window
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *TimeWindow
+		expected string
+	}{
+		{
+			name:     "no window",
+			plugin:   &TimeWindow{},
+			expected: "either past or future must be set",
+		},
+		{
+			name:     "negative past",
+			plugin:   &TimeWindow{Past: config.Duration(-time.Hour)},
+			expected: "past and future must not be negative",
+		},
+		{
+			name:     "invalid action",
+			plugin:   &TimeWindow{Past: config.Duration(time.Hour), Action: "shift"},
+			expected: `invalid action "shift"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	input := []telegraf.Metric{
+		metric.New("m", map[string]string{"id": "stale"}, map[string]interface{}{"value": 1}, now.Add(-2*time.Hour)),
+		metric.New("m", map[string]string{"id": "recent"}, map[string]interface{}{"value": 2}, now.Add(-time.Minute)),
+		metric.New("m", map[string]string{"id": "future"}, map[string]interface{}{"value": 3}, now.Add(time.Hour)),
+	}
+
+	tests := []struct {
+		action   string
+		expected []telegraf.Metric
+		dropped  int64
+		adjusted int64
+	}{
+		{
+			action: "drop",
+			expected: []telegraf.Metric{
+				metric.New("m", map[string]string{"id": "recent"}, map[string]interface{}{"value": 2}, now.Add(-time.Minute)),
+			},
+			dropped: 2,
+		},
+		{
+			action: "now",
+			expected: []telegraf.Metric{
+				metric.New("m", map[string]string{"id": "stale"}, map[string]interface{}{"value": 1}, now),
+				metric.New("m", map[string]string{"id": "recent"}, map[string]interface{}{"value": 2}, now.Add(-time.Minute)),
+				metric.New("m", map[string]string{"id": "future"}, map[string]interface{}{"value": 3}, now),
+			},
+			adjusted: 2,
+		},
+		{
+			action: "clamp",
+			expected: []telegraf.Metric{
+				metric.New("m", map[string]string{"id": "stale"}, map[string]interface{}{"value": 1}, now.Add(-time.Hour)),
+				metric.New("m", map[string]string{"id": "recent"}, map[string]interface{}{"value": 2}, now.Add(-time.Minute)),
+				metric.New("m", map[string]string{"id": "future"}, map[string]interface{}{"value": 3}, now.Add(5*time.Minute)),
+			},
+			adjusted: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			plugin := &TimeWindow{
+				Past:       config.Duration(time.Hour),
+				Future:     config.Duration(5 * time.Minute),
+				Action:     tt.action,
+				Statistics: selfstat.NewCollector(map[string]string{"action": tt.action}),
+				Log:        testutil.Logger{},
+				now:        func() time.Time { return now },
+			}
+			require.NoError(t, plugin.Init())
+
+			metrics := make([]telegraf.Metric, 0, len(input))
+			for _, m := range input {
+				metrics = append(metrics, m.Copy())
+			}
+			actual := plugin.Apply(metrics...)
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+			require.Equal(t, tt.dropped, plugin.dropped.Get())
+			require.Equal(t, tt.adjusted, plugin.adjusted.Get())
+		})
+	}
+}
+
+func TestFutureOnly(t *testing.T) {
+	now := time.Now()
+	plugin := &TimeWindow{
+		Future: config.Duration(time.Minute),
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	old := metric.New("m", map[string]string{}, map[string]interface{}{"value": 1}, now.Add(-24*time.Hour))
+	actual := plugin.Apply(old, metric.New("m", map[string]string{}, map[string]interface{}{"value": 2}, now.Add(time.Hour)))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{old}, actual)
+}
+
+func TestTracking(t *testing.T) {
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) { delivered++ }
+
+	now := time.Now()
+	plugin := &TimeWindow{
+		Past: config.Duration(time.Hour),
+		Log:  testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	stale, _ := metric.WithTracking(metric.New("m", map[string]string{}, map[string]interface{}{"value": 1}, now.Add(-2*time.Hour)), notify)
+	recent, _ := metric.WithTracking(metric.New("m", map[string]string{}, map[string]interface{}{"value": 2}, now), notify)
+
+	actual := plugin.Apply(stale, recent)
+	require.Len(t, actual, 1)
+	for _, m := range actual {
+		m.Accept()
+	}
+	require.Equal(t, 2, delivered)
+}
@@ -0,0 +1,5 @@
+//go:build !custom || secretstores || secretstores.tpm
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/secretstores/tpm" // register plugin
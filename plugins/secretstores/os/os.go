@@ -22,6 +22,7 @@ type OS struct {
 	ID         string        `toml:"id"`
 	Keyring    string        `toml:"keyring"`
 	Collection string        `toml:"collection"`
+	Backend    string        `toml:"backend"`
 	Dynamic    bool          `toml:"dynamic"`
 	Password   config.Secret `toml:"password"`
 
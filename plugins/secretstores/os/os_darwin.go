@@ -9,6 +9,10 @@ import (
 )
 
 func (o *OS) createKeyringConfig() (keyring.Config, error) {
+	if o.Backend != "" && o.Backend != "keychain" {
+		return keyring.Config{}, fmt.Errorf("invalid backend %q", o.Backend)
+	}
+
 	// Create the prompt-function in case we need it
 	promptFunc := keyring.TerminalPrompt
 	if !o.Password.Empty() {
@@ -3,6 +3,8 @@
 package os
 
 import (
+	"fmt"
+
 	"github.com/99designs/keyring"
 )
 
@@ -10,10 +12,21 @@ func (o *OS) createKeyringConfig() (keyring.Config, error) {
 	if o.Keyring == "" {
 		o.Keyring = "telegraf"
 	}
-	return keyring.Config{
-		ServiceName:     o.Keyring,
-		AllowedBackends: []keyring.BackendType{keyring.KeyCtlBackend},
-		KeyCtlScope:     "user",
-		KeyCtlPerm:      0x3f3f0000, // "alswrvalswrv------------"
-	}, nil
+
+	switch o.Backend {
+	case "", "keyctl":
+		return keyring.Config{
+			ServiceName:     o.Keyring,
+			AllowedBackends: []keyring.BackendType{keyring.KeyCtlBackend},
+			KeyCtlScope:     "user",
+			KeyCtlPerm:      0x3f3f0000, // "alswrvalswrv------------"
+		}, nil
+	case "secret-service":
+		return keyring.Config{
+			ServiceName:             o.Keyring,
+			AllowedBackends:         []keyring.BackendType{keyring.SecretServiceBackend},
+			LibSecretCollectionName: o.Collection,
+		}, nil
+	}
+	return keyring.Config{}, fmt.Errorf("invalid backend %q", o.Backend)
 }
@@ -31,6 +31,11 @@ func TestInitFail(t *testing.T) {
 			plugin:   &OS{},
 			expected: "id missing",
 		},
+		{
+			name:     "invalid backend",
+			plugin:   &OS{ID: "test", Backend: "foo"},
+			expected: `invalid backend "foo"`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
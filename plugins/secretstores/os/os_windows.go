@@ -3,10 +3,16 @@
 package os
 
 import (
+	"fmt"
+
 	"github.com/99designs/keyring"
 )
 
 func (o *OS) createKeyringConfig() (keyring.Config, error) {
+	if o.Backend != "" && o.Backend != "wincred" {
+		return keyring.Config{}, fmt.Errorf("invalid backend %q", o.Backend)
+	}
+
 	return keyring.Config{
 		ServiceName:     o.Keyring,
 		AllowedBackends: []keyring.BackendType{keyring.WinCredBackend},
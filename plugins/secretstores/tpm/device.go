@@ -0,0 +1,209 @@
+//go:build linux
+
+package tpm
+
+import (
+	"fmt"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpm2/transport"
+	"github.com/google/go-tpm/tpm2/transport/linuxtpm"
+)
+
+// device implements sealing and unsealing using a TPM 2.0 device
+type device struct {
+	path   string
+	parent tpm2.TPMHandle
+	pcrs   []uint
+}
+
+func (d *device) seal(data, auth []byte) (public, private []byte, err error) {
+	t, err := linuxtpm.Open(d.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening TPM failed: %w", err)
+	}
+	defer t.Close()
+
+	parent, flush, err := d.parentKey(t)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer flush()
+
+	template := tpm2.TPMTPublic{
+		Type:    tpm2.TPMAlgKeyedHash,
+		NameAlg: tpm2.TPMAlgSHA256,
+		ObjectAttributes: tpm2.TPMAObject{
+			FixedTPM:     true,
+			FixedParent:  true,
+			UserWithAuth: len(d.pcrs) == 0,
+			NoDA:         true,
+		},
+	}
+
+	// Bind the object to the PCR values by computing the policy digest
+	// using a trial session
+	if len(d.pcrs) > 0 {
+		policy, err := d.policyDigest(t, len(auth) > 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		template.AuthPolicy = policy
+	}
+
+	rsp, err := tpm2.Create{
+		ParentHandle: parent,
+		InSensitive: tpm2.TPM2BSensitiveCreate{
+			Sensitive: &tpm2.TPMSSensitiveCreate{
+				UserAuth: tpm2.TPM2BAuth{Buffer: auth},
+				Data:     tpm2.NewTPMUSensitiveCreate(&tpm2.TPM2BSensitiveData{Buffer: data}),
+			},
+		},
+		InPublic: tpm2.New2B(template),
+	}.Execute(t)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating sealed object failed: %w", err)
+	}
+
+	return tpm2.Marshal(rsp.OutPublic), tpm2.Marshal(rsp.OutPrivate), nil
+}
+
+func (d *device) unseal(public, private, auth []byte) ([]byte, error) {
+	pub, err := tpm2.Unmarshal[tpm2.TPM2BPublic](public)
+	if err != nil {
+		return nil, fmt.Errorf("decoding public part failed: %w", err)
+	}
+	priv, err := tpm2.Unmarshal[tpm2.TPM2BPrivate](private)
+	if err != nil {
+		return nil, fmt.Errorf("decoding private part failed: %w", err)
+	}
+
+	t, err := linuxtpm.Open(d.path)
+	if err != nil {
+		return nil, fmt.Errorf("opening TPM failed: %w", err)
+	}
+	defer t.Close()
+
+	parent, flush, err := d.parentKey(t)
+	if err != nil {
+		return nil, err
+	}
+	defer flush()
+
+	obj, err := tpm2.Load{
+		ParentHandle: parent,
+		InPrivate:    *priv,
+		InPublic:     *pub,
+	}.Execute(t)
+	if err != nil {
+		return nil, fmt.Errorf("loading sealed object failed: %w", err)
+	}
+	defer tpm2.FlushContext{FlushHandle: obj.ObjectHandle}.Execute(t) //nolint:errcheck // nothing we can do about it
+
+	session := tpm2.PasswordAuth(auth)
+	if len(d.pcrs) > 0 {
+		s, closer, err := tpm2.PolicySession(t, tpm2.TPMAlgSHA256, 16, tpm2.Auth(auth))
+		if err != nil {
+			return nil, fmt.Errorf("starting policy session failed: %w", err)
+		}
+		defer closer() //nolint:errcheck // nothing we can do about it
+
+		if err := d.applyPolicy(t, s, len(auth) > 0); err != nil {
+			return nil, err
+		}
+		session = s
+	}
+
+	rsp, err := tpm2.Unseal{
+		ItemHandle: tpm2.AuthHandle{
+			Handle: obj.ObjectHandle,
+			Name:   obj.Name,
+			Auth:   session,
+		},
+	}.Execute(t)
+	if err != nil {
+		return nil, err
+	}
+	return rsp.OutData.Buffer, nil
+}
+
+// parentKey returns the parent key for the sealed objects. Without a
+// persistent parent handle, the TCG reference ECC SRK is (re-)created. As the
+// key is derived from the owner hierarchy's seed, it is the same every time.
+// The returned function must be called to release the key.
+func (d *device) parentKey(t transport.TPM) (tpm2.AuthHandle, func(), error) {
+	if d.parent != 0 {
+		rsp, err := tpm2.ReadPublic{ObjectHandle: d.parent}.Execute(t)
+		if err != nil {
+			return tpm2.AuthHandle{}, nil, fmt.Errorf("reading parent key failed: %w", err)
+		}
+		parent := tpm2.AuthHandle{
+			Handle: d.parent,
+			Name:   rsp.Name,
+			Auth:   tpm2.PasswordAuth(nil),
+		}
+		return parent, func() {}, nil
+	}
+
+	rsp, err := tpm2.CreatePrimary{
+		PrimaryHandle: tpm2.TPMRHOwner,
+		InPublic:      tpm2.New2B(tpm2.ECCSRKTemplate),
+	}.Execute(t)
+	if err != nil {
+		return tpm2.AuthHandle{}, nil, fmt.Errorf("creating parent key failed: %w", err)
+	}
+	parent := tpm2.AuthHandle{
+		Handle: rsp.ObjectHandle,
+		Name:   rsp.Name,
+		Auth:   tpm2.PasswordAuth(nil),
+	}
+	flush := func() {
+		tpm2.FlushContext{FlushHandle: rsp.ObjectHandle}.Execute(t) //nolint:errcheck // nothing we can do about it
+	}
+	return parent, flush, nil
+}
+
+// policyDigest computes the digest of the policy binding the object to the
+// current PCR values and optionally to the password
+func (d *device) policyDigest(t transport.TPM, withAuth bool) (tpm2.TPM2BDigest, error) {
+	s, closer, err := tpm2.PolicySession(t, tpm2.TPMAlgSHA256, 16, tpm2.Trial())
+	if err != nil {
+		return tpm2.TPM2BDigest{}, fmt.Errorf("starting trial session failed: %w", err)
+	}
+	defer closer() //nolint:errcheck // nothing we can do about it
+
+	if err := d.applyPolicy(t, s, withAuth); err != nil {
+		return tpm2.TPM2BDigest{}, err
+	}
+
+	rsp, err := tpm2.PolicyGetDigest{PolicySession: s.Handle()}.Execute(t)
+	if err != nil {
+		return tpm2.TPM2BDigest{}, fmt.Errorf("getting policy digest failed: %w", err)
+	}
+	return rsp.PolicyDigest, nil
+}
+
+func (d *device) applyPolicy(t transport.TPM, s tpm2.Session, withAuth bool) error {
+	// An empty PCR digest makes the TPM use the current PCR values
+	_, err := tpm2.PolicyPCR{
+		PolicySession: s.Handle(),
+		Pcrs: tpm2.TPMLPCRSelection{
+			PCRSelections: []tpm2.TPMSPCRSelection{
+				{
+					Hash:      tpm2.TPMAlgSHA256,
+					PCRSelect: tpm2.PCClientCompatible.PCRs(d.pcrs...),
+				},
+			},
+		},
+	}.Execute(t)
+	if err != nil {
+		return fmt.Errorf("applying PCR policy failed: %w", err)
+	}
+
+	if withAuth {
+		if _, err := (tpm2.PolicyAuthValue{PolicySession: s.Handle()}).Execute(t); err != nil {
+			return fmt.Errorf("applying password policy failed: %w", err)
+		}
+	}
+	return nil
+}
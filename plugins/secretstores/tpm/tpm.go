@@ -0,0 +1,192 @@
+//go:build linux
+
+//go:generate ../../../tools/readme_config_includer/generator
+package tpm
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-tpm/tpm2"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type TPM struct {
+	ID           string          `toml:"id"`
+	Path         string          `toml:"path"`
+	Device       string          `toml:"device"`
+	ParentHandle string          `toml:"parent_handle"`
+	PCRs         []uint          `toml:"pcrs"`
+	Password     config.Secret   `toml:"password"`
+	Log          telegraf.Logger `toml:"-"`
+
+	sealer sealer
+}
+
+// sealer seals data to and unseals data from a TPM returning the public and
+// private parts of the sealed object as stored on disk
+type sealer interface {
+	seal(data, auth []byte) (public, private []byte, err error)
+	unseal(public, private, auth []byte) ([]byte, error)
+}
+
+func (*TPM) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *TPM) Init() error {
+	if t.ID == "" {
+		return errors.New("id missing")
+	}
+	if t.Path == "" {
+		return errors.New("path missing")
+	}
+	if t.Device == "" {
+		t.Device = "/dev/tpmrm0"
+	}
+
+	var parent tpm2.TPMHandle
+	if t.ParentHandle != "" {
+		h, err := strconv.ParseUint(t.ParentHandle, 0, 32)
+		if err != nil {
+			return fmt.Errorf("parsing parent handle %q failed: %w", t.ParentHandle, err)
+		}
+		// Persistent handles are in the range of 0x81000000 to 0x81ffffff
+		if h>>24 != 0x81 {
+			return fmt.Errorf("parent handle %q is not a persistent handle", t.ParentHandle)
+		}
+		parent = tpm2.TPMHandle(h)
+	}
+
+	for _, pcr := range t.PCRs {
+		if pcr > 23 {
+			return fmt.Errorf("invalid PCR %d", pcr)
+		}
+	}
+
+	t.sealer = &device{
+		path:   t.Device,
+		parent: parent,
+		pcrs:   t.PCRs,
+	}
+
+	return nil
+}
+
+func (t *TPM) Get(key string) ([]byte, error) {
+	fn, err := t.filename(key)
+	if err != nil {
+		return nil, err
+	}
+
+	public, err := os.ReadFile(fn + ".pub")
+	if err != nil {
+		return nil, fmt.Errorf("reading public part of %q failed: %w", key, err)
+	}
+	private, err := os.ReadFile(fn + ".priv")
+	if err != nil {
+		return nil, fmt.Errorf("reading private part of %q failed: %w", key, err)
+	}
+
+	auth, err := t.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := t.sealer.unseal(public, private, auth)
+	if err != nil {
+		return nil, fmt.Errorf("unsealing %q failed: %w", key, err)
+	}
+	return value, nil
+}
+
+func (t *TPM) Set(key, value string) error {
+	fn, err := t.filename(key)
+	if err != nil {
+		return err
+	}
+
+	auth, err := t.auth()
+	if err != nil {
+		return err
+	}
+
+	public, private, err := t.sealer.seal([]byte(value), auth)
+	if err != nil {
+		return fmt.Errorf("sealing %q failed: %w", key, err)
+	}
+
+	if err := os.MkdirAll(t.Path, 0700); err != nil {
+		return fmt.Errorf("creating directory failed: %w", err)
+	}
+	if err := os.WriteFile(fn+".pub", public, 0600); err != nil {
+		return fmt.Errorf("writing public part of %q failed: %w", key, err)
+	}
+	if err := os.WriteFile(fn+".priv", private, 0600); err != nil {
+		return fmt.Errorf("writing private part of %q failed: %w", key, err)
+	}
+	return nil
+}
+
+func (t *TPM) List() ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(t.Path, "*.pub"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(matches))
+	for _, fn := range matches {
+		base := strings.TrimSuffix(fn, ".pub")
+		if _, err := os.Stat(base + ".priv"); err != nil {
+			continue
+		}
+		keys = append(keys, filepath.Base(base))
+	}
+	return keys, nil
+}
+
+func (t *TPM) GetResolver(key string) (telegraf.ResolveFunc, error) {
+	resolver := func() ([]byte, bool, error) {
+		s, err := t.Get(key)
+		return s, false, err
+	}
+	return resolver, nil
+}
+
+func (t *TPM) filename(key string) (string, error) {
+	if key == "" || key != filepath.Base(key) || strings.HasPrefix(key, ".") {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	return filepath.Join(t.Path, key), nil
+}
+
+func (t *TPM) auth() ([]byte, error) {
+	if t.Password.Empty() {
+		return nil, nil
+	}
+
+	passwd, err := t.Password.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting password failed: %w", err)
+	}
+	defer passwd.Destroy()
+
+	return []byte(passwd.String()), nil
+}
+
+func init() {
+	secretstores.Add("tpm", func(id string) telegraf.SecretStore {
+		return &TPM{ID: id}
+	})
+}
@@ -0,0 +1,169 @@
+//go:build linux
+
+package tpm
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+func TestSampleConfig(t *testing.T) {
+	plugin := &TPM{}
+	require.NotEmpty(t, plugin.SampleConfig())
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *TPM
+		expected string
+	}{
+		{
+			name:     "no id",
+			plugin:   &TPM{},
+			expected: "id missing",
+		},
+		{
+			name:     "no path",
+			plugin:   &TPM{ID: "test"},
+			expected: "path missing",
+		},
+		{
+			name:     "invalid parent handle",
+			plugin:   &TPM{ID: "test", Path: "sealed", ParentHandle: "srk"},
+			expected: `parsing parent handle "srk" failed`,
+		},
+		{
+			name:     "transient parent handle",
+			plugin:   &TPM{ID: "test", Path: "sealed", ParentHandle: "0x80000001"},
+			expected: `parent handle "0x80000001" is not a persistent handle`,
+		},
+		{
+			name:     "invalid pcr",
+			plugin:   &TPM{ID: "test", Path: "sealed", PCRs: []uint{7, 24}},
+			expected: "invalid PCR 24",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInit(t *testing.T) {
+	plugin := &TPM{
+		ID:           "test",
+		Path:         "sealed",
+		ParentHandle: "0x81000001",
+		PCRs:         []uint{0, 7},
+	}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, "/dev/tpmrm0", plugin.Device)
+	require.Equal(t, &device{path: "/dev/tpmrm0", parent: 0x81000001, pcrs: []uint{0, 7}}, plugin.sealer)
+}
+
+func TestSetGetList(t *testing.T) {
+	plugin := &TPM{
+		ID:       "test",
+		Path:     filepath.Join(t.TempDir(), "sealed"),
+		Password: config.NewSecret([]byte("p4ssw0rd")),
+	}
+	require.NoError(t, plugin.Init())
+	plugin.sealer = &fakeSealer{}
+
+	require.NoError(t, plugin.Set("user", "john-doe"))
+	require.NoError(t, plugin.Set("password", "secret"))
+
+	// The secrets must not be stored in plain text
+	buf, err := os.ReadFile(filepath.Join(plugin.Path, "password.priv"))
+	require.NoError(t, err)
+	require.NotContains(t, string(buf), "secret")
+
+	keys, err := plugin.List()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"user", "password"}, keys)
+
+	value, err := plugin.Get("password")
+	require.NoError(t, err)
+	require.Equal(t, "secret", string(value))
+
+	resolver, err := plugin.GetResolver("user")
+	require.NoError(t, err)
+	value, dynamic, err := resolver()
+	require.NoError(t, err)
+	require.False(t, dynamic)
+	require.Equal(t, "john-doe", string(value))
+}
+
+func TestGetFail(t *testing.T) {
+	path := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(path, "incomplete.pub"), []byte("public"), 0600))
+
+	plugin := &TPM{
+		ID:   "test",
+		Path: path,
+	}
+	require.NoError(t, plugin.Init())
+	plugin.sealer = &fakeSealer{}
+
+	_, err := plugin.Get("../secret")
+	require.ErrorContains(t, err, `invalid key "../secret"`)
+
+	_, err = plugin.Get("missing")
+	require.ErrorContains(t, err, `reading public part of "missing" failed`)
+
+	_, err = plugin.Get("incomplete")
+	require.ErrorContains(t, err, `reading private part of "incomplete" failed`)
+
+	// Incomplete objects are not listed
+	keys, err := plugin.List()
+	require.NoError(t, err)
+	require.Empty(t, keys)
+}
+
+func TestGetWrongPassword(t *testing.T) {
+	plugin := &TPM{
+		ID:       "test",
+		Path:     t.TempDir(),
+		Password: config.NewSecret([]byte("p4ssw0rd")),
+	}
+	require.NoError(t, plugin.Init())
+	plugin.sealer = &fakeSealer{}
+	require.NoError(t, plugin.Set("password", "secret"))
+
+	plugin.Password = config.NewSecret([]byte("wrong"))
+	_, err := plugin.Get("password")
+	require.ErrorContains(t, err, `unsealing "password" failed`)
+}
+
+// fakeSealer mimics a TPM by "encrypting" the data using XOR with a fixed key
+// and storing the password in the public part
+type fakeSealer struct{}
+
+func (*fakeSealer) seal(data, auth []byte) (public, private []byte, err error) {
+	return append([]byte(nil), auth...), xor(data), nil
+}
+
+func (*fakeSealer) unseal(public, private, auth []byte) ([]byte, error) {
+	if !bytes.Equal(public, auth) {
+		return nil, errors.New("authorization failed")
+	}
+	return xor(private), nil
+}
+
+func xor(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[i] = b ^ 0x5a
+	}
+	return out
+}
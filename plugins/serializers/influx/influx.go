@@ -7,9 +7,11 @@ import (
 	"io"
 	"log"
 	"math"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/serializers"
@@ -22,12 +24,15 @@ const (
 )
 
 type Serializer struct {
-	MaxLineBytes  int  `toml:"influx_max_line_bytes"`
-	SortFields    bool `toml:"influx_sort_fields"`
-	UintSupport   bool `toml:"influx_uint_support"`
-	OmitTimestamp bool `toml:"influx_omit_timestamp"`
+	MaxLineBytes  int    `toml:"influx_max_line_bytes"`
+	SortFields    bool   `toml:"influx_sort_fields"`
+	UintSupport   bool   `toml:"influx_uint_support"`
+	OmitTimestamp bool   `toml:"influx_omit_timestamp"`
+	Precision     string `toml:"influx_precision"`
+	SortSeries    bool   `toml:"influx_sort_series"`
 
 	bytesWritten int
+	divisor      int64
 
 	buf    bytes.Buffer
 	header []byte
@@ -62,6 +67,19 @@ func (s *Serializer) Init() error {
 	s.footer = make([]byte, 0, 21)
 	s.pair = make([]byte, 0, 50)
 
+	switch s.Precision {
+	case "", "ns":
+		s.divisor = int64(time.Nanosecond)
+	case "us":
+		s.divisor = int64(time.Microsecond)
+	case "ms":
+		s.divisor = int64(time.Millisecond)
+	case "s":
+		s.divisor = int64(time.Second)
+	default:
+		return fmt.Errorf("invalid influx_precision %q", s.Precision)
+	}
+
 	return nil
 }
 
@@ -77,6 +95,10 @@ func (s *Serializer) Serialize(m telegraf.Metric) ([]byte, error) {
 }
 
 func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	if s.SortSeries {
+		metrics = sortSeries(metrics)
+	}
+
 	s.buf.Reset()
 	for _, m := range metrics {
 		err := s.write(&s.buf, m)
@@ -149,8 +171,13 @@ func (s *Serializer) buildHeader(m telegraf.Metric) error {
 func (s *Serializer) buildFooter(m telegraf.Metric) {
 	s.footer = s.footer[:0]
 	if !s.OmitTimestamp {
+		// Timestamps are truncated to the configured precision
+		ts := m.Time().UnixNano()
+		if s.divisor > 1 {
+			ts /= s.divisor
+		}
 		s.footer = append(s.footer, ' ')
-		s.footer = strconv.AppendInt(s.footer, m.Time().UnixNano(), 10)
+		s.footer = strconv.AppendInt(s.footer, ts, 10)
 	}
 	s.footer = append(s.footer, '\n')
 }
@@ -297,6 +324,39 @@ func (s *Serializer) appendFieldValue(buf []byte, value interface{}) ([]byte, er
 	}
 }
 
+// sortSeries orders the metrics by their series key, i.e. the name and tags,
+// and time without modifying the given batch. This groups the lines of each
+// series which allows for more efficient ingestion by InfluxDB 3.
+func sortSeries(metrics []telegraf.Metric) []telegraf.Metric {
+	type entry struct {
+		key    string
+		metric telegraf.Metric
+	}
+
+	entries := make([]entry, 0, len(metrics))
+	for _, m := range metrics {
+		var sb strings.Builder
+		sb.WriteString(m.Name())
+		for _, tag := range m.TagList() {
+			sb.WriteString("\x00" + tag.Key + "\x00" + tag.Value)
+		}
+		entries = append(entries, entry{key: sb.String(), metric: m})
+	}
+
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		if c := strings.Compare(a.key, b.key); c != 0 {
+			return c
+		}
+		return a.metric.Time().Compare(b.metric.Time())
+	})
+
+	sorted := make([]telegraf.Metric, 0, len(entries))
+	for _, e := range entries {
+		sorted = append(sorted, e.metric)
+	}
+	return sorted
+}
+
 func appendUintField(buf []byte, value uint64) []byte {
 	return append(strconv.AppendUint(buf, value, 10), 'u')
 }
@@ -326,4 +386,11 @@ func init() {
 			return &Serializer{}
 		},
 	)
+	// InfluxDB 3 natively supports unsigned integers and benefits from
+	// writes grouped by series
+	serializers.Add("influx_v3",
+		func() telegraf.Serializer {
+			return &Serializer{UintSupport: true, SortSeries: true}
+		},
+	)
 }
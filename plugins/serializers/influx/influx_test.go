@@ -573,3 +573,75 @@ func BenchmarkSerializeBatch(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+func TestPrecision(t *testing.T) {
+	m := metric.New(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(1519194109, 123456789),
+	)
+
+	tests := []struct {
+		precision string
+		expected  string
+	}{
+		{precision: "", expected: "cpu value=42 1519194109123456789\n"},
+		{precision: "ns", expected: "cpu value=42 1519194109123456789\n"},
+		{precision: "us", expected: "cpu value=42 1519194109123456\n"},
+		{precision: "ms", expected: "cpu value=42 1519194109123\n"},
+		{precision: "s", expected: "cpu value=42 1519194109\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.precision, func(t *testing.T) {
+			serializer := &Serializer{Precision: tt.precision}
+			require.NoError(t, serializer.Init())
+			output, err := serializer.Serialize(m)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(output))
+		})
+	}
+}
+
+func TestInvalidPrecision(t *testing.T) {
+	serializer := &Serializer{Precision: "m"}
+	require.ErrorContains(t, serializer.Init(), `invalid influx_precision "m"`)
+}
+
+func TestSortSeries(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New("mem", map[string]string{"host": "b"}, map[string]interface{}{"used": uint64(1)}, time.Unix(2, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 1.0}, time.Unix(2, 0)),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 2.0}, time.Unix(3, 0)),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 3.0}, time.Unix(1, 0)),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 4.0}, time.Unix(1, 0)),
+	}
+
+	serializer := &Serializer{SortSeries: true, UintSupport: true, Precision: "s"}
+	require.NoError(t, serializer.Init())
+	output, err := serializer.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	expected := "cpu,host=a value=4 1\n" +
+		"cpu,host=a value=2 3\n" +
+		"cpu,host=b value=3 1\n" +
+		"cpu,host=b value=1 2\n" +
+		"mem,host=b used=1u 2\n"
+	require.Equal(t, expected, string(output))
+
+	// The batch itself is not modified
+	require.Equal(t, "mem", metrics[0].Name())
+}
+
+func TestInfluxV3Defaults(t *testing.T) {
+	creator, found := serializers.Serializers["influx_v3"]
+	require.True(t, found)
+
+	serializer, ok := creator().(*Serializer)
+	require.True(t, ok)
+	require.True(t, serializer.UintSupport)
+	require.True(t, serializer.SortSeries)
+}
@@ -181,6 +181,24 @@ func TestSerializeBatch(t *testing.T) {
 	require.Equal(t, "0: cpu 42\n", string(singleBuf))
 }
 
+func TestSerializeBatchTracking(t *testing.T) {
+	m := metric.New(
+		"cpu",
+		map[string]string{"host": "server01"},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(0, 0),
+	)
+	tm, _ := metric.WithTracking(m, func(telegraf.DeliveryInfo) {})
+
+	s := &Serializer{Template: `{{ .Tag "host" }} {{ .Field "value" }}` + "\n"}
+	require.NoError(t, s.Init())
+	buf, err := s.SerializeBatch([]telegraf.Metric{tm, m})
+	require.NoError(t, err)
+	require.Equal(t, "server01 42\nserver01 42\n", string(buf))
+}
+
 func BenchmarkSerialize(b *testing.B) {
 	s := &Serializer{}
 	require.NoError(b, s.Init())
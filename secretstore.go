@@ -23,3 +23,12 @@ type SecretStore interface {
 // the secret will not change over time, or dynamic (true) to handle
 // secrets that change over time (e.g. TOTP).
 type ResolveFunc func() ([]byte, bool, error)
+
+// SecretRotationHandler is an interface for plugins holding long-lived
+// connections that need to be notified if secrets referenced in their
+// configuration changed in the secret-store.
+type SecretRotationHandler interface {
+	// SecretsRotated is called after the referenced secrets were updated.
+	// The plugin should re-read its secrets and reconnect if necessary.
+	SecretsRotated() error
+}